@@ -0,0 +1,687 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/widmogrod/mkunion/x/shape"
+	"go/parser"
+	"go/token"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BuildCacheEnv is name of environment variable with directory of build cache.
+// When it's not set, cache is kept in mkunion directory in user cache directory,
+// and when it's set to "off", cache is disabled.
+const BuildCacheEnv = "MKUNION_CACHE"
+
+// BuildCache keeps on disk, for each package, hash of inputs of last generation,
+// hashes of files that were generated, and shapes that were inferred from package.
+// Package, whose inputs and generated files didn't change since then, is not generated again,
+// and its shapes are restored from cache, so that packages that import it don't need to parse it.
+type BuildCache struct {
+	dir string
+}
+
+func NewBuildCache(dir string) *BuildCache {
+	return &BuildCache{dir: dir}
+}
+
+// DefaultBuildCache returns cache in directory set by BuildCacheEnv, or nil when cache is disabled.
+func DefaultBuildCache() *BuildCache {
+	dir := os.Getenv(BuildCacheEnv)
+	if dir == "off" {
+		return nil
+	}
+
+	if dir == "" {
+		userDir, err := os.UserCacheDir()
+		if err != nil {
+			log.Debugf("mkunion.DefaultBuildCache: build cache is disabled: %s", err)
+			return nil
+		}
+		dir = filepath.Join(userDir, "mkunion")
+	}
+
+	return NewBuildCache(dir)
+}
+
+type buildCacheEntry struct {
+	Hash   string            `json:"hash"`
+	Files  map[string]string `json:"files"`
+	Shapes []json.RawMessage `json:"shapes"`
+}
+
+func (c *BuildCache) fileName(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *BuildCache) load(key string) (*buildCacheEntry, bool) {
+	data, err := os.ReadFile(c.fileName(key))
+	if err != nil {
+		return nil, false
+	}
+
+	entry := &buildCacheEntry{}
+	err = json.Unmarshal(data, entry)
+	if err != nil {
+		log.Debugf("mkunion.BuildCache: ignoring invalid entry %s: %s", c.fileName(key), err)
+		return nil, false
+	}
+
+	return entry, true
+}
+
+func (c *BuildCache) save(key string, entry *buildCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("mkunion.BuildCache: %w", err)
+	}
+
+	err = os.MkdirAll(c.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("mkunion.BuildCache: %w", err)
+	}
+
+	// write to temporary file first, so that concurrent runs never read partially written entry
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("mkunion.BuildCache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("mkunion.BuildCache: failed to write %s: %w", tmp.Name(), err)
+	}
+
+	err = os.Rename(tmp.Name(), c.fileName(key))
+	if err != nil {
+		return fmt.Errorf("mkunion.BuildCache: %w", err)
+	}
+
+	return nil
+}
+
+// buildPackage is node of build graph. It represents go package in directory,
+// with source files to generate code from, and packages of the same module or of locally replaced modules, that it imports.
+// Packages that are only imported, don't have source files, and are used only to compute hash.
+type buildPackage struct {
+	dir     string
+	sources []string
+	files   []string
+	imports []*buildPackage
+	module  *buildModule
+	hash    string
+	done    chan struct{}
+
+	// inferModule is set, when shapes are inferred with go/packages,
+	// and returns shapes of all files of packages from the same module, by file name.
+	inferModule func() (map[string]*shape.InferredInfo, error)
+}
+
+// key identifies cache entry of package, generated from the same source files.
+func (p *buildPackage) key(typeRegistry bool) string {
+	h := sha256.New()
+	writeHashPart(h, p.dir)
+	for _, sourcePath := range p.sources {
+		writeHashPart(h, filepath.Base(sourcePath))
+	}
+	writeHashPart(h, strconv.FormatBool(typeRegistry))
+	writeHashPart(h, strconv.FormatBool(_inferWithGoPackages))
+	if _inferWithGoPackages {
+		// build tags change which source files are inferred
+		writeHashPart(h, os.Getenv("GOFLAGS"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// generatedFiles returns names of files, that can be generated from package, and exist on disk.
+func (p *buildPackage) generatedFiles() []string {
+	var candidates []string
+	for _, sourcePath := range p.sources {
+		for _, gen := range fileGenerators {
+			candidates = append(candidates, GeneratedFileName(sourcePath, gen.infix))
+		}
+	}
+	candidates = append(candidates, filepath.Join(p.dir, "types_reg_gen.go"))
+
+	var result []string
+	for _, fileName := range candidates {
+		abs, err := filepath.Abs(fileName)
+		if err == nil && isFile(abs) {
+			result = append(result, abs)
+		}
+	}
+
+	return result
+}
+
+type buildModule struct {
+	root     string
+	path     string
+	replaces []buildReplace
+	hash     string
+}
+
+// buildReplace is replace directive of go.mod, that points to directory on disk.
+type buildReplace struct {
+	path string
+	dir  string
+}
+
+// resolve returns directory of imported package, when it belongs to module or to locally replaced module.
+func (m *buildModule) resolve(importPath string) (string, bool) {
+	if importPath == m.path || strings.HasPrefix(importPath, m.path+"/") {
+		return filepath.Join(m.root, strings.TrimPrefix(importPath, m.path)), true
+	}
+
+	for _, r := range m.replaces {
+		if importPath == r.path || strings.HasPrefix(importPath, r.path+"/") {
+			return filepath.Join(r.dir, strings.TrimPrefix(importPath, r.path)), true
+		}
+	}
+
+	return "", false
+}
+
+// computeHash hashes go.mod and go.sum, so that change of version of any dependency changes hash of module packages.
+func (m *buildModule) computeHash() (string, error) {
+	if m.hash != "" {
+		return m.hash, nil
+	}
+
+	h := sha256.New()
+	for _, name := range []string{"go.mod", "go.sum"} {
+		data, err := os.ReadFile(filepath.Join(m.root, name))
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("mkunion.newBuildGraph: %w", err)
+		}
+		writeHashPart(h, name)
+		writeHashPart(h, string(data))
+	}
+
+	m.hash = hex.EncodeToString(h.Sum(nil))
+	return m.hash, nil
+}
+
+// buildGraph groups source files by package, and links packages with packages they import from the same module,
+// or from modules replaced by directory on disk. Other dependencies are identified by go.mod and go.sum of module.
+// Imports of test files are not followed, because external test package can import package, that imports tested package.
+type buildGraph struct {
+	packages map[string]*buildPackage
+	modules  map[string]*buildModule
+}
+
+func newBuildGraph(sourcePaths []string) (*buildGraph, error) {
+	g := &buildGraph{
+		packages: make(map[string]*buildPackage),
+		modules:  make(map[string]*buildModule),
+	}
+
+	for _, sourcePath := range sourcePaths {
+		abs, err := filepath.Abs(sourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("mkunion.newBuildGraph: %w", err)
+		}
+
+		pkg, err := g.add(filepath.Dir(abs))
+		if err != nil {
+			return nil, err
+		}
+		pkg.sources = append(pkg.sources, sourcePath)
+	}
+
+	for _, pkg := range g.packages {
+		_, err := g.computeHash(pkg, map[*buildPackage]bool{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _inferWithGoPackages {
+		g.inferWithGoPackages()
+	}
+
+	return g, nil
+}
+
+// inferWithGoPackages makes packages of the same module to be loaded with go/packages together, once,
+// when first of them is generated, because type checking of their dependencies from source is expensive.
+// Packages, that are not in module, are loaded on their own.
+func (g *buildGraph) inferWithGoPackages() {
+	type load struct {
+		root  string
+		dirs  []string
+		tests bool
+		pkgs  []*buildPackage
+	}
+
+	loads := make(map[string]*load)
+	for _, pkg := range g.packages {
+		if len(pkg.sources) == 0 {
+			continue
+		}
+
+		root := pkg.dir
+		if mod := g.module(pkg.dir); mod != nil {
+			root = mod.root
+		}
+
+		l, ok := loads[root]
+		if !ok {
+			l = &load{root: root}
+			loads[root] = l
+		}
+
+		l.dirs = append(l.dirs, pkg.dir)
+		l.pkgs = append(l.pkgs, pkg)
+		for _, sourcePath := range pkg.sources {
+			l.tests = l.tests || strings.HasSuffix(sourcePath, "_test.go")
+		}
+	}
+
+	for _, l := range loads {
+		sort.Strings(l.dirs)
+		inferModule := sync.OnceValues(func() (map[string]*shape.InferredInfo, error) {
+			inferred, err := shape.InferFromPackages(&packages.Config{
+				Dir:   l.root,
+				Tests: l.tests,
+			}, l.dirs...)
+			if err != nil {
+				return nil, fmt.Errorf("mkunion: failed loading packages in %s: %w", l.root, err)
+			}
+
+			result := make(map[string]*shape.InferredInfo, len(inferred))
+			for _, x := range inferred {
+				result[x.FileName()] = x
+			}
+			return result, nil
+		})
+
+		for _, pkg := range l.pkgs {
+			pkg.inferModule = inferModule
+		}
+	}
+}
+
+// infer returns shapes inferred from source files of package, by source path.
+// With go/packages, source files excluded by build constraints are not returned.
+func (p *buildPackage) infer() (map[string]*shape.InferredInfo, error) {
+	result := make(map[string]*shape.InferredInfo, len(p.sources))
+	if p.inferModule == nil {
+		for _, sourcePath := range p.sources {
+			inferred, err := shape.InferFromFile(sourcePath)
+			if err != nil {
+				return nil, err
+			}
+			result[sourcePath] = inferred
+		}
+		return result, nil
+	}
+
+	inferred, err := p.inferModule()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sourcePath := range p.sources {
+		abs, err := filepath.Abs(sourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("mkunion: %w", err)
+		}
+
+		if x, ok := inferred[abs]; ok {
+			result[sourcePath] = x
+		}
+	}
+
+	return result, nil
+}
+
+func (g *buildGraph) add(dir string) (*buildPackage, error) {
+	if pkg, ok := g.packages[dir]; ok {
+		return pkg, nil
+	}
+
+	pkg := &buildPackage{
+		dir:  dir,
+		done: make(chan struct{}),
+	}
+	g.packages[dir] = pkg
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.newBuildGraph: %w", err)
+	}
+
+	importPaths := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_gen.go") {
+			continue
+		}
+
+		fileName := filepath.Join(dir, name)
+		pkg.files = append(pkg.files, fileName)
+
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), fileName, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil, fmt.Errorf("mkunion.newBuildGraph: failed to parse imports of %s: %w", fileName, err)
+		}
+
+		for _, spec := range f.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err == nil {
+				importPaths[importPath] = true
+			}
+		}
+	}
+
+	mod := g.module(dir)
+	if mod == nil {
+		return pkg, nil
+	}
+	pkg.module = mod
+
+	for importPath := range importPaths {
+		importDir, ok := mod.resolve(importPath)
+		if !ok || importDir == dir {
+			continue
+		}
+
+		if info, err := os.Stat(importDir); err != nil || !info.IsDir() {
+			continue
+		}
+
+		imported, err := g.add(importDir)
+		if err != nil {
+			return nil, err
+		}
+		pkg.imports = append(pkg.imports, imported)
+	}
+
+	sort.Slice(pkg.imports, func(i, j int) bool {
+		return pkg.imports[i].dir < pkg.imports[j].dir
+	})
+
+	return pkg, nil
+}
+
+// module returns module, that directory belongs to, or nil, when there is no go.mod in directory or its parents.
+func (g *buildGraph) module(dir string) *buildModule {
+	if mod, ok := g.modules[dir]; ok {
+		return mod
+	}
+
+	var mod *buildModule
+	fileName := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(fileName)
+	if err == nil {
+		mod = &buildModule{
+			root: dir,
+			path: modfile.ModulePath(data),
+		}
+
+		if f, err := modfile.Parse(fileName, data, nil); err == nil {
+			for _, r := range f.Replace {
+				if modfile.IsDirectoryPath(r.New.Path) {
+					mod.replaces = append(mod.replaces, buildReplace{
+						path: r.Old.Path,
+						dir:  filepath.Join(dir, r.New.Path),
+					})
+				}
+			}
+		}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		mod = g.module(parent)
+	}
+
+	g.modules[dir] = mod
+	return mod
+}
+
+// computeHash hashes contents of package files, go.mod and go.sum of its module, and hashes of imported packages,
+// so that change in imported package or in version of dependency, changes hash of all packages that depend on it.
+func (g *buildGraph) computeHash(pkg *buildPackage, visiting map[*buildPackage]bool) (string, error) {
+	if pkg.hash != "" {
+		return pkg.hash, nil
+	}
+
+	if visiting[pkg] {
+		return "", fmt.Errorf("mkunion.newBuildGraph: import cycle through %s", pkg.dir)
+	}
+	visiting[pkg] = true
+	defer delete(visiting, pkg)
+
+	generator, err := generatorHash()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	writeHashPart(h, generator)
+	if pkg.module != nil {
+		moduleHash, err := pkg.module.computeHash()
+		if err != nil {
+			return "", err
+		}
+		writeHashPart(h, moduleHash)
+	}
+
+	for _, fileName := range pkg.files {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return "", fmt.Errorf("mkunion.newBuildGraph: %w", err)
+		}
+		writeHashPart(h, filepath.Base(fileName))
+		writeHashPart(h, string(data))
+	}
+
+	for _, imported := range pkg.imports {
+		importHash, err := g.computeHash(imported, visiting)
+		if err != nil {
+			return "", err
+		}
+		writeHashPart(h, importHash)
+	}
+
+	pkg.hash = hex.EncodeToString(h.Sum(nil))
+	return pkg.hash, nil
+}
+
+// generatorHash identifies version of generator, so that upgrade of mkunion invalidates cache.
+var generatorHash = sync.OnceValues(func() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("mkunion.generatorHash: %w", err)
+	}
+
+	return hashFile(executable)
+})
+
+func hashFile(fileName string) (string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return "", fmt.Errorf("mkunion.hashFile: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", fmt.Errorf("mkunion.hashFile: failed to read %s: %w", fileName, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeHashPart writes value with its length, so that different sequences of values never have the same hash.
+func writeHashPart(h hash.Hash, value string) {
+	_, _ = fmt.Fprintf(h, "%d:%s;", len(value), value)
+}
+
+// generateBuild generates packages in parallel, each after packages that it imports.
+// When cache is not nil, packages that didn't change since last generation are skipped.
+func generateBuild(sourcePaths []string, typeRegistry bool, save SaveFunc, cache *BuildCache) ([]string, error) {
+	// shapes are generated once per run, and not once per process,
+	// so that running generation again gives the same result
+	_generatedShapeMu.Lock()
+	_generatedShape = map[string]bool{}
+	_generatedShapeMu.Unlock()
+
+	graph, err := newBuildGraph(sourcePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		savedFiles []string
+		errs       []error
+	)
+
+	// save function, like in check command, is not required to be safe for concurrent use
+	saveSync := func(contents bytes.Buffer, sourcePath string, infix string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return save(contents, sourcePath, infix)
+	}
+
+	workers := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for _, pkg := range graph.packages {
+		if len(pkg.sources) == 0 {
+			close(pkg.done)
+			continue
+		}
+
+		wg.Add(1)
+		go func(pkg *buildPackage) {
+			defer wg.Done()
+			defer close(pkg.done)
+
+			for _, imported := range pkg.imports {
+				<-imported.done
+			}
+
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			saved, err := generateCachedPackage(pkg, typeRegistry, saveSync, cache)
+
+			mu.Lock()
+			defer mu.Unlock()
+			savedFiles = append(savedFiles, saved...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}(pkg)
+	}
+
+	wg.Wait()
+
+	sort.Strings(savedFiles)
+	return savedFiles, errors.Join(errs...)
+}
+
+func generateCachedPackage(pkg *buildPackage, typeRegistry bool, save SaveFunc, cache *BuildCache) ([]string, error) {
+	key := pkg.key(typeRegistry)
+	if cache != nil {
+		if entry, ok := cache.load(key); ok && entry.Hash == pkg.hash && isUpToDate(entry) {
+			if restoreShapes(entry) {
+				log.Debugf("mkunion: %s is up to date", pkg.dir)
+				return nil, nil
+			}
+		}
+	}
+
+	inferred, err := pkg.infer()
+	if err != nil {
+		return nil, err
+	}
+
+	savedFiles, shapes, err := generatePackage(pkg.sources, inferred, typeRegistry, save)
+	if err != nil || cache == nil {
+		return savedFiles, err
+	}
+
+	entry := &buildCacheEntry{
+		Hash:  pkg.hash,
+		Files: make(map[string]string),
+	}
+
+	for _, fileName := range pkg.generatedFiles() {
+		entry.Files[fileName], err = hashFile(fileName)
+		if err != nil {
+			log.Warnf("mkunion: not caching %s: %s", pkg.dir, err)
+			return savedFiles, nil
+		}
+	}
+
+	for _, x := range shapes {
+		data, err := shape.ShapeToJSON(x)
+		if err != nil {
+			log.Warnf("mkunion: not caching %s: failed to serialise %s: %s", pkg.dir, shape.ToGoTypeName(x), err)
+			return savedFiles, nil
+		}
+		entry.Shapes = append(entry.Shapes, data)
+	}
+
+	err = cache.save(key, entry)
+	if err != nil {
+		log.Warnf("mkunion: not caching %s: %s", pkg.dir, err)
+	}
+
+	return savedFiles, nil
+}
+
+// isUpToDate checks that files generated from package were not changed or removed since entry was saved.
+func isUpToDate(entry *buildCacheEntry) bool {
+	for fileName, expected := range entry.Files {
+		actual, err := hashFile(fileName)
+		if err != nil || actual != expected {
+			return false
+		}
+	}
+
+	return true
+}
+
+func restoreShapes(entry *buildCacheEntry) bool {
+	shapes := make([]shape.Shape, 0, len(entry.Shapes))
+	for _, data := range entry.Shapes {
+		x, err := shape.ShapeFromJSON(data)
+		if err != nil {
+			return false
+		}
+		shapes = append(shapes, x)
+	}
+
+	for _, x := range shapes {
+		shape.StoreShapeOnDisk(x)
+	}
+
+	return true
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateBuild_Cache(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module example.com/build\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(tempDir, "vehicle"), 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(tempDir, "garage"), 0755))
+
+	vehicle := filepath.Join(tempDir, "vehicle", "vehicle.go")
+	require.NoError(t, os.WriteFile(vehicle, []byte(`package vehicle
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car  struct{ Wheels int }
+	Boat struct{ Sails int }
+)
+`), 0644))
+
+	garage := filepath.Join(tempDir, "garage", "garage.go")
+	require.NoError(t, os.WriteFile(garage, []byte(`package garage
+
+import "example.com/build/vehicle"
+
+//go:tag serde:"json"
+type Garage struct {
+	Parked vehicle.Car
+}
+`), 0644))
+
+	cache := NewBuildCache(t.TempDir())
+	sourcePaths := []string{vehicle, garage}
+
+	// generate returns names of source files, that were generated
+	generate := func(t *testing.T) []string {
+		var generated []string
+		_, err := generateBuild(sourcePaths, true, func(contents bytes.Buffer, sourcePath string, infix string) (string, error) {
+			if len(contents.Bytes()) > 0 {
+				generated = append(generated, filepath.Base(sourcePath))
+			}
+			return saveFileIfChanged(contents, sourcePath, infix)
+		}, cache)
+		require.NoError(t, err)
+
+		generated = dedup(generated)
+		sort.Strings(generated)
+		return generated
+	}
+
+	t.Run("generates_all_packages_first", func(t *testing.T) {
+		assert.Equal(t, []string{"garage.go", "types.go", "vehicle.go"}, generate(t))
+		assert.FileExists(t, filepath.Join(tempDir, "vehicle", "vehicle_union_gen.go"))
+		assert.FileExists(t, filepath.Join(tempDir, "garage", "garage_serde_gen.go"))
+	})
+
+	t.Run("skips_packages_that_did_not_change", func(t *testing.T) {
+		assert.Empty(t, generate(t))
+	})
+
+	t.Run("generates_only_changed_package", func(t *testing.T) {
+		original, err := os.ReadFile(garage)
+		require.NoError(t, err)
+		changed := strings.Replace(string(original), "Parked vehicle.Car", "Parked vehicle.Car\n\tName   string", 1)
+		require.NoError(t, os.WriteFile(garage, []byte(changed), 0644))
+
+		assert.Equal(t, []string{"garage.go", "types.go"}, generate(t))
+	})
+
+	t.Run("generates_packages_that_import_changed_package", func(t *testing.T) {
+		original, err := os.ReadFile(vehicle)
+		require.NoError(t, err)
+		changed := strings.Replace(string(original), "Boat struct{ Sails int }", "Boat struct{ Sails int }\n\tPlane struct{ Wings int }", 1)
+		require.NoError(t, os.WriteFile(vehicle, []byte(changed), 0644))
+
+		assert.Equal(t, []string{"garage.go", "types.go", "vehicle.go"}, generate(t))
+	})
+
+	t.Run("generates_all_packages_when_dependencies_changed", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, "go.sum"), []byte("example.com/dep v1.0.0 h1:abc=\n"), 0644))
+
+		assert.Equal(t, []string{"garage.go", "types.go", "vehicle.go"}, generate(t))
+		assert.Empty(t, generate(t))
+	})
+
+	t.Run("generates_package_when_generated_file_was_removed", func(t *testing.T) {
+		unionFile := filepath.Join(tempDir, "vehicle", "vehicle_union_gen.go")
+		require.NoError(t, os.Remove(unionFile))
+
+		assert.Equal(t, []string{"types.go", "vehicle.go"}, generate(t))
+		assert.FileExists(t, unionFile)
+	})
+
+	t.Run("writes_only_files_that_changed", func(t *testing.T) {
+		serdeFile := filepath.Join(tempDir, "garage", "garage_serde_gen.go")
+		require.NoError(t, os.WriteFile(serdeFile, []byte("package garage\n"), 0644))
+
+		savedFiles, err := generateBuild(sourcePaths, true, saveFileIfChanged, cache)
+		require.NoError(t, err)
+		assert.Equal(t, []string{serdeFile}, savedFiles)
+	})
+}
+
+func TestNewBuildGraph_Replace(t *testing.T) {
+	tempDir := t.TempDir()
+	appDir := filepath.Join(tempDir, "app")
+	partsDir := filepath.Join(tempDir, "parts")
+	require.NoError(t, os.Mkdir(appDir, 0755))
+	require.NoError(t, os.Mkdir(partsDir, 0755))
+
+	require.NoError(t, os.WriteFile(filepath.Join(appDir, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n\nrequire example.com/parts v0.0.0\n\nreplace example.com/parts => ../parts\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(partsDir, "go.mod"), []byte("module example.com/parts\n\ngo 1.21\n"), 0644))
+
+	app := filepath.Join(appDir, "app.go")
+	require.NoError(t, os.WriteFile(app, []byte(`package app
+
+import (
+	"fmt"
+
+	"example.com/parts"
+)
+
+//go:tag serde:"json"
+type App struct {
+	Wheel parts.Wheel
+}
+
+var _ = fmt.Sprint
+`), 0644))
+
+	wheel := filepath.Join(partsDir, "wheel.go")
+	require.NoError(t, os.WriteFile(wheel, []byte("package parts\n\ntype Wheel struct{ Size int }\n"), 0644))
+
+	hash := func(t *testing.T) string {
+		g, err := newBuildGraph([]string{app})
+		require.NoError(t, err)
+
+		pkg := g.packages[appDir]
+		require.Len(t, pkg.imports, 1)
+		assert.Equal(t, partsDir, pkg.imports[0].dir)
+		return pkg.hash
+	}
+
+	original := hash(t)
+	assert.Equal(t, original, hash(t))
+
+	require.NoError(t, os.WriteFile(wheel, []byte("package parts\n\ntype Wheel struct{ Size, Width int }\n"), 0644))
+	assert.NotEqual(t, original, hash(t), "change in replaced module must change hash")
+}
+
+func TestDefaultBuildCache(t *testing.T) {
+	t.Run("uses_directory_from_environment", func(t *testing.T) {
+		t.Setenv(BuildCacheEnv, "/tmp/mkunion-cache")
+		assert.Equal(t, NewBuildCache("/tmp/mkunion-cache"), DefaultBuildCache())
+	})
+
+	t.Run("is_disabled_with_off", func(t *testing.T) {
+		t.Setenv(BuildCacheEnv, "off")
+		assert.Nil(t, DefaultBuildCache())
+	})
+}
+
+func TestGenerateBuild_GoPackages(t *testing.T) {
+	_inferWithGoPackages = true
+	defer func() { _inferWithGoPackages = false }()
+
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module example.com/build\n\ngo 1.21\n"), 0644))
+
+	vehicle := filepath.Join(tempDir, "vehicle.go")
+	require.NoError(t, os.WriteFile(vehicle, []byte(`package build
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car  struct{ Wheels int }
+	Boat struct{ Sails int }
+)
+`), 0644))
+
+	excluded := filepath.Join(tempDir, "engine.go")
+	require.NoError(t, os.WriteFile(excluded, []byte(`//go:build electric
+
+package build
+
+//go:tag mkunion:"Engine"
+type (
+	Battery struct{ Capacity int }
+	Motor   struct{ Power int }
+)
+`), 0644))
+
+	savedFiles, err := generateBuild([]string{vehicle, excluded}, false, saveFileIfChanged, nil)
+	require.NoError(t, err)
+	assert.Contains(t, savedFiles, filepath.Join(tempDir, "vehicle_union_gen.go"))
+	assert.NoFileExists(t, filepath.Join(tempDir, "engine_union_gen.go"), "file excluded by build constraint is not generated")
+}
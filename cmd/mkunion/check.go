@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pmezard/go-difflib/difflib"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CheckGeneratedFiles generates code for go files in dirs in memory, and compares it with generated files on disk.
+// It returns unified diff for each generated file that is missing, out of date,
+// or is no longer generated, because its source file or declarations were removed.
+// When all generated files are up to date, result is empty.
+func CheckGeneratedFiles(dirs []string, typeRegistry bool) ([]string, error) {
+	// type registry is generated in directory of inferred file, which is absolute,
+	// so source paths must be absolute as well, to compare the same file names
+	dirs, err := mapf(dirs, func(dir string) ([]string, error) {
+		abs, err := filepath.Abs(dir)
+		return []string{abs}, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.CheckGeneratedFiles: %w", err)
+	}
+
+	sourcePaths, err := goFilesFromDirs(dirs)
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.CheckGeneratedFiles: extracting source paths: %w", err)
+	}
+
+	_protoLockReadOnly = true
+	defer func() { _protoLockReadOnly = false }()
+
+	expected := make(map[string][]byte)
+	_, err = GenerateMainWith(sourcePaths, typeRegistry, func(contents bytes.Buffer, sourcePath string, infix string) (string, error) {
+		if len(contents.Bytes()) == 0 {
+			return "", nil
+		}
+
+		fileName := GeneratedFileName(sourcePath, infix)
+		expected[fileName] = formatGenerated(contents, fileName)
+		return fileName, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.CheckGeneratedFiles: %w", err)
+	}
+
+	fileNames := make([]string, 0, len(expected))
+	for fileName := range expected {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	var diffs []string
+	for _, fileName := range fileNames {
+		actual, err := os.ReadFile(fileName)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("mkunion.CheckGeneratedFiles: failed to read %s: %w", fileName, err)
+		}
+
+		if bytes.Equal(actual, expected[fileName]) {
+			continue
+		}
+
+		fromFile := fileName
+		if os.IsNotExist(err) {
+			fromFile = "/dev/null"
+		}
+
+		diff, err := unifiedDiff(actual, expected[fileName], fromFile, fileName)
+		if err != nil {
+			return nil, fmt.Errorf("mkunion.CheckGeneratedFiles: failed to diff %s: %w", fileName, err)
+		}
+		diffs = append(diffs, diff)
+	}
+
+	stale, err := findStaleGeneratedFiles(dirs, expected, typeRegistry)
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.CheckGeneratedFiles: %w", err)
+	}
+
+	for _, fileName := range stale {
+		actual, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("mkunion.CheckGeneratedFiles: failed to read %s: %w", fileName, err)
+		}
+
+		diff, err := unifiedDiff(actual, nil, fileName, "/dev/null")
+		if err != nil {
+			return nil, fmt.Errorf("mkunion.CheckGeneratedFiles: failed to diff %s: %w", fileName, err)
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+// findStaleGeneratedFiles returns generated files in dirs, without looking into subdirectories,
+// that mkunion would not generate anymore.
+func findStaleGeneratedFiles(dirs []string, expected map[string][]byte, typeRegistry bool) ([]string, error) {
+	var result []string
+	for _, dir := range dedup(dirs) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !isGeneratedFile(entry.Name()) {
+				continue
+			}
+
+			if !typeRegistry && entry.Name() == "types_reg_gen.go" {
+				// type registry is not generated, so it's not checked either
+				continue
+			}
+
+			fileName := filepath.Join(dir, entry.Name())
+			if _, ok := expected[fileName]; ok {
+				continue
+			}
+
+			result = append(result, fileName)
+		}
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+func unifiedDiff(actual, expected []byte, fromFile, toFile string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(actual),
+		B:        splitLines(expected),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// splitLines splits text into lines that end with new line, which difflib expects.
+// Unlike difflib.SplitLines, it doesn't add empty line at the end of text.
+func splitLines(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+
+	lines[len(lines)-1] += "\n"
+	return lines
+}
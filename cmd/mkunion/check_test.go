@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckGeneratedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module example.com/check\n\ngo 1.21\n"), 0644))
+
+	source := filepath.Join(tempDir, "vehicle.go")
+	require.NoError(t, os.WriteFile(source, []byte(`package check
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car  struct{ Wheels int }
+	Boat struct{ Sails int }
+)
+`), 0644))
+
+	t.Run("reports_missing_generated_files", func(t *testing.T) {
+		diffs, err := CheckGeneratedFiles([]string{tempDir}, true)
+		require.NoError(t, err)
+		require.NotEmpty(t, diffs)
+		assert.Contains(t, diffs[0], "--- /dev/null")
+	})
+
+	t.Setenv(BuildCacheEnv, t.TempDir())
+	_, err := GenerateMain([]string{source}, true)
+	require.NoError(t, err)
+
+	t.Run("reports_nothing_when_up_to_date", func(t *testing.T) {
+		diffs, err := CheckGeneratedFiles([]string{tempDir}, true)
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+	})
+
+	t.Run("reports_diff_when_union_changed", func(t *testing.T) {
+		original, err := os.ReadFile(source)
+		require.NoError(t, err)
+		defer os.WriteFile(source, original, 0644)
+
+		changed := strings.Replace(string(original), "Boat struct{ Sails int }", "Boat struct{ Sails int }\n\tPlane struct{ Wings int }", 1)
+		require.NoError(t, os.WriteFile(source, []byte(changed), 0644))
+
+		diffs, err := CheckGeneratedFiles([]string{tempDir}, true)
+		require.NoError(t, err)
+		require.NotEmpty(t, diffs)
+
+		unionDiff := ""
+		for _, diff := range diffs {
+			if strings.Contains(diff, "+++ "+filepath.Join(tempDir, "vehicle_union_gen.go")) {
+				unionDiff = diff
+			}
+		}
+		assert.Contains(t, unionDiff, "+\tVisitPlane(v *Plane) any")
+	})
+
+	t.Run("reports_generated_files_without_source", func(t *testing.T) {
+		stale := filepath.Join(tempDir, "removed_union_gen.go")
+		require.NoError(t, os.WriteFile(stale, []byte("package check\n"), 0644))
+		defer os.Remove(stale)
+
+		diffs, err := CheckGeneratedFiles([]string{tempDir}, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"--- " + stale + "\n+++ /dev/null\n@@ -1 +0,0 @@\n-package check\n",
+		}, diffs)
+	})
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CompatShapes returns shapes declared in go files in dirs, without looking into subdirectories.
+func CompatShapes(dirs []string) ([]shape.Shape, error) {
+	sourcePaths, err := goFilesFromDirs(dirs)
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.CompatShapes: extracting source paths: %w", err)
+	}
+
+	var result []shape.Shape
+	for _, sourcePath := range sourcePaths {
+		inferred, err := shape.InferFromFile(sourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("mkunion.CompatShapes: failed to infer shapes in %s: %w", sourcePath, err)
+		}
+
+		result = append(result, inferred.RetrieveShapes()...)
+	}
+
+	return result, nil
+}
+
+// CheckCompat compares shapes from base with shapes declared in dirs,
+// and returns changes, that affect values serialised with generated JSON serde.
+// Base is either snapshot file saved by SaveShapeSnapshot, or git reference, like main or HEAD~1.
+func CheckCompat(base string, dirs []string) ([]shape.CompatChange, error) {
+	current, err := CompatShapes(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous []shape.Shape
+	if isFile(base) {
+		previous, err = LoadShapeSnapshot(base)
+	} else {
+		previous, err = shapesAtGitRef(base, dirs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return shape.CompatJSON(previous, current), nil
+}
+
+// SaveShapeSnapshot writes shapes to file as JSON list, serialised with shape.ShapeToJSON,
+// so that they can be used later as base for compatibility check.
+func SaveShapeSnapshot(fileName string, shapes []shape.Shape) error {
+	list := make([]json.RawMessage, 0, len(shapes))
+	for _, x := range shapes {
+		data, err := shape.ShapeToJSON(x)
+		if err != nil {
+			return fmt.Errorf("mkunion.SaveShapeSnapshot: failed to serialise %s: %w", shape.ToGoTypeName(x), err)
+		}
+		list = append(list, data)
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("mkunion.SaveShapeSnapshot: %w", err)
+	}
+
+	err = os.WriteFile(fileName, append(data, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("mkunion.SaveShapeSnapshot: failed to write file %s: %w", fileName, err)
+	}
+
+	return nil
+}
+
+func LoadShapeSnapshot(fileName string) ([]shape.Shape, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.LoadShapeSnapshot: failed to read file %s: %w", fileName, err)
+	}
+
+	var list []json.RawMessage
+	err = json.Unmarshal(data, &list)
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.LoadShapeSnapshot: failed to parse %s: %w", fileName, err)
+	}
+
+	result := make([]shape.Shape, 0, len(list))
+	for i, item := range list {
+		x, err := shape.ShapeFromJSON(item)
+		if err != nil {
+			return nil, fmt.Errorf("mkunion.LoadShapeSnapshot: failed to parse shape at index %d in %s: %w", i, fileName, err)
+		}
+		result = append(result, x)
+	}
+
+	return result, nil
+}
+
+// shapesAtGitRef extracts go files at given git reference into temporary directory,
+// and returns shapes declared in the same dirs, as they were at that reference.
+func shapesAtGitRef(ref string, dirs []string) ([]shape.Shape, error) {
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+
+	root, err := gitOutput(dirs[0], "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.shapesAtGitRef: %s is neither snapshot file nor git reference: %w", ref, err)
+	}
+	root = strings.TrimSpace(root)
+
+	archive, err := gitOutput(root, "archive", "--format=tar", ref)
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.shapesAtGitRef: %s is neither snapshot file nor git reference: %w", ref, err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "mkunion-compat-")
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.shapesAtGitRef: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = extractGoFiles(strings.NewReader(archive), tmpDir)
+	if err != nil {
+		return nil, fmt.Errorf("mkunion.shapesAtGitRef: failed to extract %s: %w", ref, err)
+	}
+
+	var baseDirs []string
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("mkunion.shapesAtGitRef: %w", err)
+		}
+
+		rel, err := filepath.Rel(root, abs)
+		if err != nil {
+			return nil, fmt.Errorf("mkunion.shapesAtGitRef: %w", err)
+		}
+
+		baseDir := filepath.Join(tmpDir, rel)
+		if _, err := os.Stat(baseDir); errors.Is(err, os.ErrNotExist) {
+			// directory didn't exist at reference, so all its types are new
+			continue
+		}
+
+		baseDirs = append(baseDirs, baseDir)
+	}
+
+	return CompatShapes(baseDirs)
+}
+
+// extractGoFiles writes go files and go.mod files from tar archive to dir.
+// go.mod files are needed to infer import names of packages.
+func extractGoFiles(archive io.Reader, dir string) error {
+	reader := tar.NewReader(archive)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg || !filepath.IsLocal(header.Name) {
+			continue
+		}
+
+		if filepath.Ext(header.Name) != ".go" && filepath.Base(header.Name) != "go.mod" {
+			continue
+		}
+
+		fileName := filepath.Join(dir, header.Name)
+		err = os.MkdirAll(filepath.Dir(fileName), 0755)
+		if err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(fileName, data, 0644)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w; %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return string(output), nil
+}
+
+func isFile(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && !info.IsDir()
+}
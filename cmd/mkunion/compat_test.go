@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/widmogrod/mkunion/x/shape"
+)
+
+func TestCheckCompat(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module example.com/compat\n\ngo 1.21\n"), 0644))
+
+	source := filepath.Join(tempDir, "vehicle.go")
+	require.NoError(t, os.WriteFile(source, []byte(`package compat
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car  struct{ Wheels int }
+	Boat struct{ Sails int }
+)
+`), 0644))
+
+	git := func(args ...string) {
+		_, err := gitOutput(tempDir, args...)
+		require.NoError(t, err)
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+
+	snapshot := filepath.Join(tempDir, "shapes.json")
+	shapes, err := CompatShapes([]string{tempDir})
+	require.NoError(t, err)
+	require.NoError(t, SaveShapeSnapshot(snapshot, shapes))
+
+	require.NoError(t, os.WriteFile(source, []byte(`package compat
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car struct{ Wheels int }
+)
+`), 0644))
+
+	expected := []shape.CompatChange{
+		{Path: "compat.Vehicle", Reason: "variant compat.Boat removed", Backward: false, Forward: true},
+	}
+
+	t.Run("compares_with_git_reference", func(t *testing.T) {
+		changes, err := CheckCompat("HEAD", []string{tempDir})
+		require.NoError(t, err)
+		assert.Equal(t, expected, changes)
+	})
+
+	t.Run("compares_with_snapshot", func(t *testing.T) {
+		changes, err := CheckCompat(snapshot, []string{tempDir})
+		require.NoError(t, err)
+		assert.Equal(t, expected, changes)
+	})
+
+	t.Run("reports_unknown_base", func(t *testing.T) {
+		_, err := CheckCompat("does-not-exist", []string{tempDir})
+		assert.ErrorContains(t, err, "does-not-exist is neither snapshot file nor git reference")
+	})
+}
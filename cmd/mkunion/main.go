@@ -4,6 +4,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/fsnotify/fsnotify"
 	log "github.com/sirupsen/logrus"
@@ -59,12 +60,18 @@ func main() {
 				Name:  "type-registry",
 				Value: true,
 			},
+			&cli.BoolFlag{
+				Name:  "go-packages",
+				Usage: "Infer types with go/packages and go/types, which resolves imported, vendored and aliased types the way compiler does, and respects build tags set in GOFLAGS",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			if c.Bool("verbose") {
 				log.SetLevel(log.DebugLevel)
 			}
 
+			_inferWithGoPackages = c.Bool("go-packages")
+
 			sourcePaths := c.StringSlice("input-go-file")
 			if len(sourcePaths) == 0 && os.Getenv("GOFILE") != "" {
 				cwd, _ := syscall.Getwd()
@@ -93,12 +100,23 @@ func main() {
 		Commands: []*cli.Command{
 			{
 				Name:        "shape-export",
-				Description: "Generate typescript types from golang types, and enable end-to-end type safety.",
+				Description: "Generate typescript types, python pydantic models, rust serde types, protobuf schema, JSON Schema or OpenAPI document from golang types, and enable end-to-end type safety.",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:        "language",
 						Aliases:     []string{"lang"},
 						DefaultText: "typescript",
+						Usage:       "One of: typescript, python, rust, proto, jsonschema, openapi",
+					},
+					&cli.StringFlag{
+						Name:      "openapi-endpoints",
+						Usage:     "Location of JSON file that describes HTTP endpoints, with request and response types, used only with openapi language",
+						TakesFile: true,
+					},
+					&cli.StringFlag{
+						Name:      "proto-lock",
+						Usage:     "Location of file that keeps protobuf field numbers stable, when not provided, " + shape.ProtoLockFileName + " next to go.mod is used",
+						TakesFile: true,
 					},
 					&cli.StringFlag{
 						Name:    "output-dir",
@@ -136,7 +154,7 @@ func main() {
 						cli.ShowAppHelpAndExit(c, 1)
 					}
 
-					tsr := shape.NewTypeScriptRenderer()
+					var shapes []shape.Shape
 					for _, sourcePath := range sourcePaths {
 						// file name without extension
 						inferred, err := shape.InferFromFile(sourcePath)
@@ -144,16 +162,70 @@ func main() {
 							return err
 						}
 
-						for _, x := range inferred.RetrieveShapes() {
-							tsr.AddShape(x)
+						shapes = append(shapes, inferred.RetrieveShapes()...)
+					}
+
+					language := c.String("language")
+					if language == "" {
+						language = "typescript"
+					}
+
+					var renderer shape.Renderer
+					var lock *shape.ProtoLock
+					var lockFile string
+
+					switch language {
+					case "proto", "protobuf":
+						lockFile = c.String("proto-lock")
+						if lockFile == "" {
+							lockFile = shape.FindProtoLockFile(path.Dir(sourcePaths[0]))
+						}
+
+						var err error
+						lock, err = shape.LoadProtoLock(lockFile)
+						if err != nil {
+							return err
+						}
+
+						renderer = shape.NewProtobufRenderer(lock)
+
+					case "openapi":
+						endpoints, err := LoadOpenAPIEndpoints(c.String("openapi-endpoints"))
+						if err != nil {
+							return err
+						}
+
+						or := shape.NewOpenAPIRenderer(endpoints.Info.Title, endpoints.Info.Version)
+						for _, endpoint := range endpoints.Endpoints {
+							rendered, err := endpoint.Resolve(shapes)
+							if err != nil {
+								return err
+							}
+							or.AddEndpoint(rendered)
+						}
+
+						renderer = or
+
+					default:
+						var ok bool
+						renderer, ok = shape.NewRenderer(language)
+						if !ok {
+							return fmt.Errorf("unsupported language %q", language)
 						}
 					}
 
-					err := tsr.WriteToDir(c.String("output-dir"))
+					err := shape.RenderToDir(renderer, shapes, c.String("output-dir"))
 					if err != nil {
 						return fmt.Errorf("failed to write to dir %s: %w", c.String("output-dir"), err)
 					}
 
+					if lock != nil {
+						err = lock.Save(lockFile)
+						if err != nil {
+							return fmt.Errorf("failed to save proto lock %s: %w", lockFile, err)
+						}
+					}
+
 					return nil
 				},
 			},
@@ -165,6 +237,10 @@ func main() {
 						Name:  "type-registry",
 						Value: true,
 					},
+					&cli.BoolFlag{
+						Name:  "go-packages",
+						Usage: "Infer types with go/packages and go/types, which resolves imported, vendored and aliased types the way compiler does, and respects build tags set in GOFLAGS",
+					},
 					&cli.BoolFlag{
 						Name:     "verbose",
 						Aliases:  []string{"v"},
@@ -191,6 +267,8 @@ func main() {
 						log.SetLevel(log.DebugLevel)
 					}
 
+					_inferWithGoPackages = c.Bool("go-packages")
+
 					// Create a new watcher
 					watcher, err := fsnotify.NewWatcher()
 					if err != nil {
@@ -206,6 +284,18 @@ func main() {
 					justRemoved := sync.Map{}
 					dontRunGoGenerate := c.Bool("dont-run-go-generate")
 
+					paths := c.Args().Slice()
+					if len(paths) == 0 {
+						paths = []string{"."}
+					}
+
+					paths, err = mapf(paths, artToPath)
+					if err != nil {
+						return err
+					}
+
+					paths = dedup(paths)
+
 					// Start a goroutine to handle events
 					go func() {
 						defer close(done)
@@ -308,6 +398,18 @@ func main() {
 								})
 
 								if len(changedSourcePaths) > 0 {
+									// with build cache, all watched files are passed,
+									// so that packages that import changed package are generated as well,
+									// and packages that didn't change are skipped
+									if DefaultBuildCache() != nil {
+										sourcePaths, err := goFilesFromDirs(paths)
+										if err != nil {
+											log.Errorf("failed to extract source paths: %s", err)
+										} else {
+											changedSourcePaths = sourcePaths
+										}
+									}
+
 									prevLevel := log.GetLevel()
 									log.SetLevel(log.ErrorLevel)
 									savedFiles, err := GenerateMain(changedSourcePaths, c.Bool("type-registry"))
@@ -327,9 +429,9 @@ func main() {
 
 										// Run go generate if not disabled and there were changes
 										if !dontRunGoGenerate {
-											// Extract directories from changed files
+											// Extract directories from regenerated files
 											dirsMap := make(map[string]bool)
-											for _, file := range changedSourcePaths {
+											for _, file := range savedFiles {
 												dir := filepath.Dir(file)
 												dirsMap[dir] = true
 											}
@@ -350,17 +452,6 @@ func main() {
 						}
 					}()
 
-					paths := c.Args().Slice()
-					if len(paths) == 0 {
-						paths = []string{"."}
-					}
-
-					paths, err = mapf(paths, artToPath)
-					if err != nil {
-						return err
-					}
-
-					paths = dedup(paths)
 					// generate first before watching
 					sourcePaths, err := goFilesFromDirs(paths)
 					if err != nil {
@@ -406,6 +497,149 @@ func main() {
 					return nil
 				},
 			},
+			{
+				Name:        "check",
+				Description: "Check that generated files are up to date, and print diff of files that are not, without writing them",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "type-registry",
+						Value: true,
+					},
+					&cli.BoolFlag{
+						Name:  "go-packages",
+						Usage: "Infer types with go/packages and go/types, which resolves imported, vendored and aliased types the way compiler does, and respects build tags set in GOFLAGS",
+					},
+					&cli.BoolFlag{
+						Name:     "verbose",
+						Aliases:  []string{"v"},
+						Required: false,
+						Value:    false,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.Bool("verbose") {
+						log.SetLevel(log.DebugLevel)
+					}
+
+					_inferWithGoPackages = c.Bool("go-packages")
+
+					paths := c.Args().Slice()
+					if len(paths) == 0 {
+						paths = []string{"."}
+					}
+
+					paths, err := mapf(paths, artToPath)
+					if err != nil {
+						return err
+					}
+
+					paths = dedup(paths)
+
+					diffs, err := CheckGeneratedFiles(paths, c.Bool("type-registry"))
+					if err != nil {
+						return err
+					}
+
+					for _, diff := range diffs {
+						fmt.Println(diff)
+					}
+
+					if len(diffs) > 0 {
+						return fmt.Errorf("%d generated files are out of date, run: %s watch -g ./...", len(diffs), shared.Program)
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:        "compat",
+				Description: "Check that values serialised with generated JSON serde before changes to types can still be read",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "base",
+						Usage: "Git reference, like main or HEAD~1, or snapshot file saved with --dump, to compare with",
+					},
+					&cli.StringFlag{
+						Name:      "dump",
+						Usage:     "Save snapshot of current types to file, instead of comparing them",
+						TakesFile: true,
+					},
+					&cli.StringFlag{
+						Name:  "require",
+						Value: shape.CompatBackward,
+						Usage: "Fail when change is not: backward (old values can be read), forward (old code can read new values), or full (both)",
+					},
+					&cli.BoolFlag{
+						Name:     "verbose",
+						Aliases:  []string{"v"},
+						Required: false,
+						Value:    false,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.Bool("verbose") {
+						log.SetLevel(log.DebugLevel)
+					}
+
+					paths := c.Args().Slice()
+					if len(paths) == 0 {
+						paths = []string{"."}
+					}
+
+					paths, err := mapf(paths, artToPath)
+					if err != nil {
+						return err
+					}
+
+					paths = dedup(paths)
+
+					if c.String("dump") != "" {
+						shapes, err := CompatShapes(paths)
+						if err != nil {
+							return err
+						}
+
+						return SaveShapeSnapshot(c.String("dump"), shapes)
+					}
+
+					if c.String("base") == "" {
+						return fmt.Errorf("either --base or --dump must be provided")
+					}
+
+					changes, err := CheckCompat(c.String("base"), paths)
+					if err != nil {
+						return err
+					}
+
+					failed := 0
+					for _, change := range changes {
+						fmt.Println(change.String())
+
+						switch c.String("require") {
+						case shape.CompatBackward:
+							if !change.Backward {
+								failed++
+							}
+						case shape.CompatForward:
+							if !change.Forward {
+								failed++
+							}
+						case "full", shape.CompatFull:
+							if !change.Backward || !change.Forward {
+								failed++
+							}
+						default:
+							return fmt.Errorf("unsupported --require %q, expected one of: backward, forward, full", c.String("require"))
+						}
+					}
+
+					if failed > 0 {
+						return fmt.Errorf("%d changes are not %s compatible", failed, c.String("require"))
+					}
+
+					return nil
+				},
+			},
 			{
 				Name:        "clean",
 				Description: "Remove all generated files to have clean state for generation",
@@ -556,6 +790,11 @@ func artToPath(x string) ([]string, error) {
 					return filepath.SkipDir
 				}
 
+				// testdata holds fixtures, that go tool ignores as well
+				if d.Name() == "testdata" {
+					return filepath.SkipDir
+				}
+
 				result = append(result, path)
 				return nil
 			})
@@ -612,93 +851,94 @@ func goFilesFromDirs(dirs []string) ([]string, error) {
 
 }
 
+// GenerateMain generates code for go files in sourcePaths, and writes only generated files, that changed.
+// Packages are generated in parallel, and packages whose inputs didn't change since last run are skipped,
+// when build cache is enabled, see BuildCacheEnv.
 func GenerateMain(sourcePaths []string, typeRegistry bool) ([]string, error) {
-	packages := make(map[string]*shape.InferredInfo)
-	var savedFiles []string
-
-	for _, sourcePath := range sourcePaths {
-		// file name without extension
-		inferred, err := shape.InferFromFile(sourcePath)
-		if err != nil {
-			return savedFiles, err
-		}
+	return generateBuild(sourcePaths, typeRegistry, saveFileIfChanged, DefaultBuildCache())
+}
 
-		if _, ok := packages[inferred.PackageImportName()]; !ok {
-			packages[inferred.PackageImportName()] = inferred
-		}
+// SaveFunc saves generated contents next to source file, in file with given infix, and returns its name.
+type SaveFunc = func(contents bytes.Buffer, sourcePath string, infix string) (string, error)
 
-		contents, err := GenerateUnions(inferred)
-		if err != nil {
-			return savedFiles, fmt.Errorf("failed generating union in %s: %w", sourcePath, err)
-		}
-		savedFile, err := SaveFile(contents, sourcePath, "union_gen")
-		if err != nil {
-			return savedFiles, fmt.Errorf("failed saving union in %s: %w", sourcePath, err)
-		}
-		if len(savedFile) > 0 {
-			savedFiles = append(savedFiles, savedFile)
-		}
+// GenerateMainWith generates the same files as GenerateMain, but passes them to save,
+// so that they can be compared with files on disk without writing them.
+// Build cache is not used, so all files are always generated.
+func GenerateMainWith(sourcePaths []string, typeRegistry bool, save SaveFunc) ([]string, error) {
+	return generateBuild(sourcePaths, typeRegistry, save, nil)
+}
 
-		contents, err = GenerateSerde(inferred)
-		if err != nil {
-			return savedFiles, fmt.Errorf("failed generating serde in %s: %w", sourcePath, err)
-		}
-		savedFile, err = SaveFile(contents, sourcePath, "serde_gen")
-		if err != nil {
-			return savedFiles, fmt.Errorf("failed saving serde in %s: %w", sourcePath, err)
-		}
-		if len(savedFile) > 0 {
-			savedFiles = append(savedFiles, savedFile)
-		}
+// fileGenerators lists code generated for each source file, in order of generation.
+var fileGenerators = []struct {
+	name     string
+	infix    string
+	generate func(inferred *shape.InferredInfo) (bytes.Buffer, error)
+}{
+	{"union", "union_gen", GenerateUnions},
+	{"serde", "serde_gen", GenerateSerde},
+	{"shape", "shape_gen", GenerateShape},
+	{"match", "match_gen", GenerateMatch},
+	{"service", "service_gen", GenerateService},
+	{"validate", "validate_gen", GenerateValidate},
+}
 
-		contents, err = GenerateShape(inferred)
-		if err != nil {
-			return savedFiles, fmt.Errorf("failed generating shape in %s: %w", sourcePath, err)
-		}
-		savedFile, err = SaveFile(contents, sourcePath, "shape_gen")
-		if err != nil {
-			return savedFiles, fmt.Errorf("failed saving shape in %s: %w", sourcePath, err)
-		}
-		if len(savedFile) > 0 {
-			savedFiles = append(savedFiles, savedFile)
-		}
+// generatePackage generates code for source files of the same package, and type registry for package directory.
+// Source file, that is not in inferred, because it's excluded by build constraints, is skipped.
+// It returns saved files, and shapes inferred from source files.
+func generatePackage(sourcePaths []string, inferredFiles map[string]*shape.InferredInfo, typeRegistry bool, save SaveFunc) ([]string, []shape.Shape, error) {
+	var savedFiles []string
+	var shapes []shape.Shape
+	var dir string
 
-		contents, err = GenerateMatch(inferred)
-		if err != nil {
-			return savedFiles, fmt.Errorf("failed generating match in %s: %w", sourcePath, err)
-		}
-		savedFile, err = SaveFile(contents, sourcePath, "match_gen")
-		if err != nil {
-			return savedFiles, fmt.Errorf("failed saving match in %s: %w", sourcePath, err)
-		}
-		if len(savedFile) > 0 {
-			savedFiles = append(savedFiles, savedFile)
+	for _, sourcePath := range sourcePaths {
+		inferred, ok := inferredFiles[sourcePath]
+		if !ok {
+			continue
 		}
-	}
 
-	if typeRegistry {
-		uniqueDirs := make(map[string]bool)
-		for _, inferred := range packages {
-			dir := path.Dir(inferred.FileName())
-			uniqueDirs[dir] = true
+		dir = path.Dir(inferred.FileName())
+		for _, x := range inferred.RetrieveShapes() {
+			// shapes known from previous run, i.e. in watch mode, are replaced,
+			// so that type registry and packages that import this package, don't see previous shapes
+			shape.StoreShapeOnDisk(x)
+			shapes = append(shapes, x)
 		}
 
-		var dirs []string
-		for dir := range uniqueDirs {
-			dirs = append(dirs, dir)
+		for _, gen := range fileGenerators {
+			contents, err := gen.generate(inferred)
+			if err != nil {
+				return savedFiles, shapes, fmt.Errorf("failed generating %s in %s: %w", gen.name, sourcePath, err)
+			}
+			savedFile, err := save(contents, sourcePath, gen.infix)
+			if err != nil {
+				return savedFiles, shapes, fmt.Errorf("failed saving %s in %s: %w", gen.name, sourcePath, err)
+			}
+			if len(savedFile) > 0 {
+				savedFiles = append(savedFiles, savedFile)
+			}
 		}
+	}
 
-		savedFiles2, err := GenerateTypeRegistryForDir(dirs)
+	if typeRegistry && dir != "" {
+		savedFiles2, err := GenerateTypeRegistryForDirWith([]string{dir}, save)
 		if err != nil {
-			return savedFiles, err
+			return savedFiles, shapes, err
 		}
 		savedFiles = append(savedFiles, savedFiles2...)
 	}
 
-	return savedFiles, nil
+	return savedFiles, shapes, nil
 }
 
+// _inferWithGoPackages is set with --go-packages flag, to infer shapes with go/packages and go/types,
+// instead of parsing each source file on its own.
+var _inferWithGoPackages = false
+
 func GenerateTypeRegistryForDir(uniqueDirs []string) ([]string, error) {
+	return GenerateTypeRegistryForDirWith(uniqueDirs, SaveFile)
+}
+
+func GenerateTypeRegistryForDirWith(uniqueDirs []string, save SaveFunc) ([]string, error) {
 	var savedFiles []string
 	for _, dir := range uniqueDirs {
 		// walk through all *.go files in the same directory
@@ -724,7 +964,7 @@ func GenerateTypeRegistryForDir(uniqueDirs []string) ([]string, error) {
 		}
 
 		regPath := path.Join(dir, "types.go")
-		savedFile, err := SaveFile(contents, regPath, "reg_gen")
+		savedFile, err := save(contents, regPath, "reg_gen")
 		if err != nil {
 			return savedFiles, fmt.Errorf("mkunion: failed saving type registry in %s: %w", regPath, err)
 		}
@@ -762,6 +1002,47 @@ func GenerateUnions(inferred *shape.InferredInfo) (bytes.Buffer, error) {
 		}
 		shapesContents.Write(contents)
 
+		genRewrite := generators.NewRewriteGenerator(union)
+		genRewrite.SkipImportsAndPackage(true)
+
+		contents, err = genRewrite.Generate()
+		if err != nil {
+			return shapesContents, fmt.Errorf("mkunion.GenerateUnions: failed to generate rewrite for %s: %w", shape.ToGoTypeName(union), err)
+		}
+		shapesContents.Write(contents)
+
+		pkgMap = generators.MergePkgMaps(pkgMap,
+			genRewrite.ExtractImports(),
+		)
+
+		if shape.TagHasDerive(union.Tags, "functor") {
+			genFunctor := generators.NewFunctorUnion(union)
+			genFunctor.SkipImportsAndPackage(true)
+
+			functor, err := genFunctor.Generate()
+			if err != nil {
+				return shapesContents, fmt.Errorf("mkunion.GenerateUnions: failed to generate functor for %s: %w", shape.ToGoTypeName(union), err)
+			}
+			shapesContents.WriteString(functor)
+
+			pkgMap = generators.MergePkgMaps(pkgMap,
+				genFunctor.ExtractImports(),
+			)
+		}
+
+		genDerive := generators.NewDeriveUnion(union)
+		genDerive.SkipImportsAndPackage(true)
+
+		derive, err := genDerive.Generate()
+		if err != nil {
+			return shapesContents, fmt.Errorf("mkunion.GenerateUnions: failed to generate equal, hash and deep copy for %s: %w", shape.ToGoTypeName(union), err)
+		}
+		shapesContents.WriteString(derive)
+
+		pkgMap = generators.MergePkgMaps(pkgMap,
+			genDerive.ExtractImports(),
+		)
+
 		if shape.TagHasOption(union.Tags, "mkunion", "noserde") {
 			continue
 		}
@@ -779,6 +1060,42 @@ func GenerateUnions(inferred *shape.InferredInfo) (bytes.Buffer, error) {
 		pkgMap = generators.MergePkgMaps(pkgMap,
 			genSerde.ExtractImports(union),
 		)
+
+		if shape.TagHasSerde(union.Tags, "proto") {
+			err = withProtoLock(inferred, func(lock *shape.ProtoLock) error {
+				genProto := generators.NewSerdeProtoUnion(union, lock)
+				genProto.SkipImportsAndPackage(true)
+
+				contents, err := genProto.Generate()
+				if err != nil {
+					return fmt.Errorf("failed to generate proto serde for %s: %w", shape.ToGoTypeName(union), err)
+				}
+				shapesContents.Write(contents)
+
+				pkgMap = generators.MergePkgMaps(pkgMap,
+					genProto.ExtractImports(union),
+				)
+				return nil
+			})
+			if err != nil {
+				return shapesContents, fmt.Errorf("mkunion.GenerateUnions: %w", err)
+			}
+		}
+
+		if shape.TagHasSerde(union.Tags, "msgpack") {
+			genMsgpack := generators.NewSerdeMsgpackUnion(union)
+			genMsgpack.SkipImportsAndPackage(true)
+
+			contents, err = genMsgpack.Generate()
+			if err != nil {
+				return shapesContents, fmt.Errorf("mkunion.GenerateUnions: failed to generate msgpack serde for %s: %w", shape.ToGoTypeName(union), err)
+			}
+			shapesContents.Write(contents)
+
+			pkgMap = generators.MergePkgMaps(pkgMap,
+				genMsgpack.ExtractImports(union),
+			)
+		}
 	}
 
 	contents := bytes.Buffer{}
@@ -807,20 +1124,84 @@ func GenerateSerde(inferred *shape.InferredInfo) (bytes.Buffer, error) {
 	initFunc := make(generators.InitFuncs, 0, 0)
 
 	for _, x := range shapes {
+		if _, isUnion := x.(*shape.UnionLike); isUnion {
+			// unions have serde generated together with union
+			continue
+		}
+
 		packageName = shape.ToGoPkgName(x)
-		genSerde := generators.NewSerdeJSONTagged(x)
-		genSerde.SkipImportsAndPackage(true)
 
-		contents := "//shape:serde:json\n"
-		contents, err = genSerde.Generate()
-		if err != nil {
-			return shapesContents, fmt.Errorf("mkunion.GenerateSerde: failed to generate json serde for %s: %w", shape.ToGoTypeName(x), err)
+		if shape.TagHasSerde(shape.Tags(x), "json") {
+			genSerde := generators.NewSerdeJSONTagged(x)
+			genSerde.SkipImportsAndPackage(true)
+
+			contents := "//shape:serde:json\n"
+			contents, err = genSerde.Generate()
+			if err != nil {
+				return shapesContents, fmt.Errorf("mkunion.GenerateSerde: failed to generate json serde for %s: %w", shape.ToGoTypeName(x), err)
+			}
+			shapesContents.WriteString(contents)
+
+			pkgMap = generators.MergePkgMaps(pkgMap,
+				genSerde.ExtractImports(x),
+			)
 		}
-		shapesContents.WriteString(contents)
 
-		pkgMap = generators.MergePkgMaps(pkgMap,
-			genSerde.ExtractImports(x),
-		)
+		if shape.TagHasSerde(shape.Tags(x), "proto") {
+			err = withProtoLock(inferred, func(lock *shape.ProtoLock) error {
+				genProto := generators.NewSerdeProtoTagged(x, lock)
+				genProto.SkipImportsAndPackage(true)
+
+				contents, err := genProto.Generate()
+				if err != nil {
+					return fmt.Errorf("failed to generate proto serde for %s: %w", shape.ToGoTypeName(x), err)
+				}
+				shapesContents.WriteString(contents)
+
+				pkgMap = generators.MergePkgMaps(pkgMap,
+					genProto.ExtractImports(x),
+				)
+				return nil
+			})
+			if err != nil {
+				return shapesContents, fmt.Errorf("mkunion.GenerateSerde: %w", err)
+			}
+		}
+
+		if shape.TagHasSerde(shape.Tags(x), "msgpack") {
+			genMsgpack := generators.NewSerdeMsgpackTagged(x)
+			genMsgpack.SkipImportsAndPackage(true)
+
+			contents, err := genMsgpack.Generate()
+			if err != nil {
+				return shapesContents, fmt.Errorf("mkunion.GenerateSerde: failed to generate msgpack serde for %s: %w", shape.ToGoTypeName(x), err)
+			}
+			shapesContents.WriteString(contents)
+
+			pkgMap = generators.MergePkgMaps(pkgMap,
+				genMsgpack.ExtractImports(x),
+			)
+		}
+
+		if _, isVariant := shape.Tags(x)[shape.TagUnionName]; !isVariant {
+			// variants have equal, hash and deep copy generated together with union
+			genDerive := generators.NewDeriveTagged(x)
+			genDerive.SkipImportsAndPackage(true)
+
+			contents, err := genDerive.Generate()
+			if err != nil {
+				return shapesContents, fmt.Errorf("mkunion.GenerateSerde: failed to generate equal, hash and deep copy for %s: %w", shape.ToGoTypeName(x), err)
+			}
+			shapesContents.WriteString(contents)
+
+			pkgMap = generators.MergePkgMaps(pkgMap,
+				genDerive.ExtractImports(),
+			)
+		}
+	}
+
+	if shapesContents.Len() == 0 {
+		return shapesContents, nil
 	}
 
 	contents := bytes.Buffer{}
@@ -837,6 +1218,143 @@ func GenerateSerde(inferred *shape.InferredInfo) (bytes.Buffer, error) {
 	return contents, nil
 }
 
+// _protoLockMu serialises changes of proto lock, because packages are generated in parallel,
+// and lock file is shared by whole module.
+var _protoLockMu sync.Mutex
+
+// withProtoLock loads lock with protobuf field numbers, that is shared by whole module,
+// so that generated serde and exported schema agree on the wire format,
+// and saves it, when f assigned numbers to new fields.
+func withProtoLock(inferred *shape.InferredInfo, f func(lock *shape.ProtoLock) error) error {
+	_protoLockMu.Lock()
+	defer _protoLockMu.Unlock()
+
+	lock, lockFile, err := loadProtoLock(inferred)
+	if err != nil {
+		return err
+	}
+
+	err = f(lock)
+	if err != nil {
+		return err
+	}
+
+	err = saveProtoLock(lock, lockFile)
+	if err != nil {
+		return fmt.Errorf("failed to save proto lock: %w", err)
+	}
+
+	return nil
+}
+
+func loadProtoLock(inferred *shape.InferredInfo) (*shape.ProtoLock, string, error) {
+	lockFile := shape.FindProtoLockFile(path.Dir(inferred.FileName()))
+	lock, err := shape.LoadProtoLock(lockFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load proto lock: %w", err)
+	}
+
+	return lock, lockFile, nil
+}
+
+// _protoLockReadOnly is set while checking generated files, which must not write anything to disk.
+// Field numbers assigned to new fields are still used in generated code, so such change is reported as diff.
+var _protoLockReadOnly = false
+
+func saveProtoLock(lock *shape.ProtoLock, lockFile string) error {
+	if _protoLockReadOnly {
+		return nil
+	}
+
+	return lock.Save(lockFile)
+}
+
+// OpenAPIEndpoints is format of file passed with --openapi-endpoints flag.
+// Request and response are names of types, either declared in exported files, like "ChatCMD",
+// or fully qualified with import path, like "github.com/widmogrod/mkunion/x/workflow.Command".
+type OpenAPIEndpoints struct {
+	Info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Endpoints []OpenAPIEndpoint `json:"endpoints"`
+}
+
+type OpenAPIEndpoint struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Summary  string `json:"summary"`
+	Request  string `json:"request"`
+	Response string `json:"response"`
+}
+
+func LoadOpenAPIEndpoints(filename string) (*OpenAPIEndpoints, error) {
+	result := &OpenAPIEndpoints{}
+	result.Info.Title = shared.Program
+	result.Info.Version = "0.0.0"
+
+	if filename == "" {
+		return result, nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read openapi endpoints %s: %w", filename, err)
+	}
+
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse openapi endpoints %s: %w", filename, err)
+	}
+
+	return result, nil
+}
+
+func (e OpenAPIEndpoint) Resolve(shapes []shape.Shape) (shape.OpenAPIEndpoint, error) {
+	result := shape.OpenAPIEndpoint{
+		Method:  e.Method,
+		Path:    e.Path,
+		Summary: e.Summary,
+	}
+
+	var err error
+	result.Request, err = resolveOpenAPIType(e.Request, shapes)
+	if err != nil {
+		return result, fmt.Errorf("endpoint %s %s request: %w", e.Method, e.Path, err)
+	}
+
+	result.Response, err = resolveOpenAPIType(e.Response, shapes)
+	if err != nil {
+		return result, fmt.Errorf("endpoint %s %s response: %w", e.Method, e.Path, err)
+	}
+
+	return result, nil
+}
+
+func resolveOpenAPIType(name string, shapes []shape.Shape) (shape.Shape, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	for _, x := range shapes {
+		if shape.Name(x) == name {
+			return x, nil
+		}
+	}
+
+	idx := strings.LastIndex(name, ".")
+	if idx <= 0 {
+		return nil, fmt.Errorf("type %s is not declared in input files, use fully qualified name like github.com/org/pkg.%s", name, name)
+	}
+
+	pkgImportName := name[:idx]
+	return &shape.RefName{
+		Name:          name[idx+1:],
+		PkgName:       path.Base(pkgImportName),
+		PkgImportName: pkgImportName,
+	}, nil
+}
+
 func GenerateShape(inferred *shape.InferredInfo) (bytes.Buffer, error) {
 	shapesContents := bytes.Buffer{}
 	shapes := inferred.RetrieveShapes()
@@ -915,11 +1433,26 @@ func GenerateShapeFollow(x shape.Shape, pkgMap *generators.PkgMap, initFunc *[]s
 	return result, nil
 }
 
-var _generatedShape = map[string]bool{}
+var (
+	_generatedShape   = map[string]bool{}
+	_generatedShapeMu sync.Mutex
+)
+
+func isShapeGenerated(key string) bool {
+	_generatedShapeMu.Lock()
+	defer _generatedShapeMu.Unlock()
+	return _generatedShape[key]
+}
+
+func setShapeGenerated(key string) {
+	_generatedShapeMu.Lock()
+	defer _generatedShapeMu.Unlock()
+	_generatedShape[key] = true
+}
 
 func GenerateShapeOnce(x shape.Shape, pkgMap *generators.PkgMap, initFunc *[]string, inferred *shape.InferredInfo) (*bytes.Buffer, error) {
 	key := shape.ToGoTypeName(x, shape.WithPkgImportName())
-	if _generatedShape[key] {
+	if isShapeGenerated(key) {
 		log.Debugf("mkunion.GenerateShapeOnce: shape %s already generated", key)
 		return nil, nil
 	}
@@ -944,12 +1477,11 @@ func GenerateShapeOnce(x shape.Shape, pkgMap *generators.PkgMap, initFunc *[]str
 
 	case *shape.UnionLike:
 		for _, v := range x.Variant {
-			key := shape.ToGoTypeName(v, shape.WithPkgImportName())
-			_generatedShape[key] = true
+			setShapeGenerated(shape.ToGoTypeName(v, shape.WithPkgImportName()))
 		}
 	}
 
-	_generatedShape[key] = true
+	setShapeGenerated(key)
 
 	gen := generators.NewShapeTagged(x)
 	gen.SkipImportsAndPackage(true)
@@ -976,27 +1508,198 @@ func SaveFile(contents bytes.Buffer, sourcePath string, infix string) (string, e
 		return "", nil
 	}
 
+	fileName := GeneratedFileName(sourcePath, infix)
+	formatted := formatGenerated(contents, fileName)
+
+	log.Infof("writing %s", fileName)
+	err := os.WriteFile(fileName, formatted, 0644)
+	if err != nil {
+		return fileName, fmt.Errorf("mkunion.SaveFile: failed to write file %s: %w", sourcePath, err)
+	}
+	return fileName, nil
+}
+
+// saveFileIfChanged works like SaveFile, but doesn't write file, when its contents didn't change,
+// so that modification time of file is preserved, and watch doesn't see it as changed.
+func saveFileIfChanged(contents bytes.Buffer, sourcePath string, infix string) (string, error) {
+	if len(contents.Bytes()) == 0 {
+		return "", nil
+	}
+
+	fileName := GeneratedFileName(sourcePath, infix)
+	formatted := formatGenerated(contents, fileName)
+
+	existing, err := os.ReadFile(fileName)
+	if err == nil && bytes.Equal(existing, formatted) {
+		log.Debugf("unchanged %s", fileName)
+		return "", nil
+	}
+
+	log.Infof("writing %s", fileName)
+	err = os.WriteFile(fileName, formatted, 0644)
+	if err != nil {
+		return fileName, fmt.Errorf("mkunion.saveFileIfChanged: failed to write file %s: %w", sourcePath, err)
+	}
+	return fileName, nil
+}
+
+// GeneratedFileName returns name of file, that holds code generated from source file, like shape_union_gen.go for shape.go.
+func GeneratedFileName(sourcePath string, infix string) string {
 	sourceName := path.Base(sourcePath)
 	baseName := strings.TrimSuffix(sourceName, path.Ext(sourceName))
-	fileName := path.Join(
+	return path.Join(
 		path.Dir(sourcePath),
 		fmt.Sprintf("%s_%s.go", baseName, infix),
 	)
+}
 
+func formatGenerated(contents bytes.Buffer, fileName string) []byte {
 	// Format the generated Go code
 	formatted, err := format.Source(contents.Bytes())
 	if err != nil {
 		// Log warning but continue with unformatted code
 		log.Warnf("failed to format generated code for %s: %v", fileName, err)
-		formatted = contents.Bytes()
+		return contents.Bytes()
 	}
 
-	log.Infof("writing %s", fileName)
-	err = os.WriteFile(fileName, formatted, 0644)
+	return formatted
+}
+
+// GenerateValidate generates Validate method for types tagged with //go:tag validate:"true".
+// Union generates validation for all its variants.
+func GenerateValidate(inferred *shape.InferredInfo) (bytes.Buffer, error) {
+	shapesContents := bytes.Buffer{}
+	shapes := inferred.RetrieveShapesTaggedAs(shape.TagValidateName)
+	if len(shapes) == 0 {
+		return shapesContents, nil
+	}
+
+	var err error
+	packageName := "main"
+	pkgMap := make(generators.PkgMap)
+	initFunc := make(generators.InitFuncs, 0, 0)
+
+	// variants have Validate method generated together with union
+	variants := make(map[string]bool)
+	for _, x := range shapes {
+		if union, isUnion := x.(*shape.UnionLike); isUnion && shape.TagHasValidate(union.Tags) {
+			for _, variant := range union.Variant {
+				variants[shape.ToGoTypeName(variant)] = true
+			}
+		}
+	}
+
+	for _, x := range shapes {
+		if !shape.TagHasValidate(shape.Tags(x)) {
+			continue
+		}
+
+		packageName = shape.ToGoPkgName(x)
+
+		if union, isUnion := x.(*shape.UnionLike); isUnion {
+			genValidate := generators.NewValidateUnion(union)
+			genValidate.SkipImportsAndPackage(true)
+			genValidate.SkipInitFunc(true)
+
+			contents, err := genValidate.Generate()
+			if err != nil {
+				return shapesContents, fmt.Errorf("mkunion.GenerateValidate: failed to generate validate for %s: %w", shape.ToGoTypeName(x), err)
+			}
+			shapesContents.Write(contents)
+
+			pkgMap = generators.MergePkgMaps(pkgMap,
+				genValidate.ExtractImports(x),
+			)
+			initFunc = append(initFunc, genValidate.ExtractImportFuncs(x)...)
+			continue
+		}
+
+		if variants[shape.ToGoTypeName(x)] {
+			continue
+		}
+
+		genValidate := generators.NewValidateTagged(x)
+		genValidate.SkipImportsAndPackage(true)
+
+		contents, err := genValidate.Generate()
+		if err != nil {
+			return shapesContents, fmt.Errorf("mkunion.GenerateValidate: failed to generate validate for %s: %w", shape.ToGoTypeName(x), err)
+		}
+		shapesContents.WriteString(contents)
+
+		pkgMap = generators.MergePkgMaps(pkgMap,
+			genValidate.ExtractImports(x),
+		)
+	}
+
+	if shapesContents.Len() == 0 {
+		return shapesContents, nil
+	}
+
+	contents := bytes.Buffer{}
+	contents.WriteString("// Code generated by mkunion. DO NOT EDIT.\n")
+	contents.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+	contents.WriteString(generators.GenerateImports(pkgMap))
+	contents.WriteString(generators.GenerateInitFunc(initFunc))
+
+	_, err = shapesContents.WriteTo(&contents)
 	if err != nil {
-		return fileName, fmt.Errorf("mkunion.SaveFile: failed to write file %s: %w", sourcePath, err)
+		return shapesContents, fmt.Errorf("mkunion.GenerateValidate: failed to write shapes contents: %w", err)
 	}
-	return fileName, nil
+
+	return contents, nil
+}
+
+// GenerateService generates HTTP router and client for unions tagged with //go:tag service:"Response".
+func GenerateService(inferred *shape.InferredInfo) (bytes.Buffer, error) {
+	shapesContents := bytes.Buffer{}
+	shapes := inferred.RetrieveShapesTaggedAs(shape.TagServiceName)
+	if len(shapes) == 0 {
+		return shapesContents, nil
+	}
+
+	var err error
+	packageName := "main"
+	pkgMap := make(generators.PkgMap)
+
+	for _, x := range shapes {
+		// variants share tags with union, and are handled together with it
+		union, isUnion := x.(*shape.UnionLike)
+		if !isUnion {
+			continue
+		}
+
+		packageName = shape.ToGoPkgName(x)
+
+		genService := generators.NewServiceUnion(union)
+		genService.SkipImportsAndPackage(true)
+
+		contents, err := genService.Generate()
+		if err != nil {
+			return shapesContents, fmt.Errorf("mkunion.GenerateService: failed to generate service for %s: %w", shape.ToGoTypeName(x), err)
+		}
+		shapesContents.WriteString(contents)
+
+		pkgMap = generators.MergePkgMaps(pkgMap,
+			genService.ExtractImports(),
+		)
+	}
+
+	if shapesContents.Len() == 0 {
+		return shapesContents, nil
+	}
+
+	contents := bytes.Buffer{}
+	contents.WriteString("// Code generated by mkunion. DO NOT EDIT.\n")
+	contents.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+	contents.WriteString(generators.GenerateImports(pkgMap))
+
+	_, err = shapesContents.WriteTo(&contents)
+	if err != nil {
+		return shapesContents, fmt.Errorf("mkunion.GenerateService: failed to write shapes contents: %w", err)
+	}
+
+	return contents, nil
 }
 
 func GenerateMatch(inferred *shape.InferredInfo) (bytes.Buffer, error) {
@@ -1198,6 +1901,8 @@ func isGeneratedFile(path string) bool {
 		"_shape_gen.go",
 		"_serde_gen.go",
 		"_match_gen.go",
+		"_validate_gen.go",
+		"_service_gen.go",
 	}
 
 	for _, suffix := range generatedSuffixes {
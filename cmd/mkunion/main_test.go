@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/widmogrod/mkunion/x/shape"
 )
 
 func TestSaveFile_FormatsGeneratedGoCode(t *testing.T) {
@@ -396,6 +397,8 @@ func TestCleanGeneratedFiles(t *testing.T) {
 			"test_shape_gen.go",
 			"test_serde_gen.go",
 			"test_match_gen.go",
+			"test_validate_gen.go",
+			"test_service_gen.go",
 			"types_reg_gen.go",
 		}
 
@@ -591,6 +594,8 @@ func TestIsGeneratedFile(t *testing.T) {
 		{"test_shape_gen.go", true},
 		{"test_serde_gen.go", true},
 		{"test_match_gen.go", true},
+		{"test_validate_gen.go", true},
+		{"test_service_gen.go", true},
 		{"types_reg_gen.go", true},
 
 		// Non-generated files
@@ -710,3 +715,44 @@ func TestFindGeneratedFiles(t *testing.T) {
 		assert.NotContains(t, generatedFiles, hiddenFile)
 	})
 }
+
+func TestLoadOpenAPIEndpoints(t *testing.T) {
+	t.Run("defaults_when_file_is_not_provided", func(t *testing.T) {
+		endpoints, err := LoadOpenAPIEndpoints("")
+		require.NoError(t, err)
+		assert.Equal(t, "0.0.0", endpoints.Info.Version)
+		assert.Empty(t, endpoints.Endpoints)
+	})
+
+	t.Run("resolves_local_and_qualified_types", func(t *testing.T) {
+		tempDir := t.TempDir()
+		filename := filepath.Join(tempDir, "endpoints.json")
+		err := os.WriteFile(filename, []byte(`{
+			"info": {"title": "app", "version": "1.2.3"},
+			"endpoints": [
+				{"method": "POST", "path": "/run", "request": "Cmd", "response": "github.com/org/app/workflow.State"}
+			]
+		}`), 0644)
+		require.NoError(t, err)
+
+		endpoints, err := LoadOpenAPIEndpoints(filename)
+		require.NoError(t, err)
+		assert.Equal(t, "app", endpoints.Info.Title)
+		require.Len(t, endpoints.Endpoints, 1)
+
+		cmd := &shape.StructLike{Name: "Cmd", PkgName: "main"}
+		result, err := endpoints.Endpoints[0].Resolve([]shape.Shape{cmd})
+		require.NoError(t, err)
+		assert.Equal(t, cmd, result.Request)
+		assert.Equal(t, &shape.RefName{
+			Name:          "State",
+			PkgName:       "workflow",
+			PkgImportName: "github.com/org/app/workflow",
+		}, result.Response)
+	})
+
+	t.Run("fails_on_unknown_unqualified_type", func(t *testing.T) {
+		_, err := OpenAPIEndpoint{Method: "GET", Path: "/x", Response: "Missing"}.Resolve(nil)
+		assert.ErrorContains(t, err, "type Missing is not declared in input files")
+	})
+}
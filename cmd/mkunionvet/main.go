@@ -0,0 +1,20 @@
+// Command mkunionvet checks that type switches over mkunion unions handle all variants.
+//
+// It can be run directly, or by go vet:
+//
+//	go install github.com/widmogrod/mkunion/cmd/mkunionvet
+//	go vet -vettool=$(which mkunionvet) ./...
+package main
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/widmogrod/mkunion/x/exhaustive"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	// shape inference logs each inferred type, which would be mixed with diagnostics
+	log.SetLevel(log.ErrorLevel)
+
+	singlechecker.Main(exhaustive.Analyzer)
+}
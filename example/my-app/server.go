@@ -35,6 +35,7 @@ import (
 
 // this command make sure that all types that are imported will have generated typescript mapping
 //go:generate ../../cmd/mkunion/mkunion shape-export --language=typescript -o ./src/workflow
+//go:generate ../../cmd/mkunion/mkunion shape-export --language=openapi --openapi-endpoints=openapi.endpoints.json -o ./src/openapi
 
 // this lines defines all types that should have typescript mapping generated by above command
 type (
@@ -54,7 +55,7 @@ type (
 
 // --8<-- [end:ts-gen]
 
-//go:tag mkunion:"ChatCMD"
+//go:tag mkunion:"ChatCMD" service:"ChatResult"
 type (
 	UserMessage struct {
 		Message string
@@ -188,7 +189,7 @@ func main() {
 		AllowOrigins: []string{"*"},
 	}))
 
-	e.POST("/message", TypedJSONRequest(
+	chat := ChatCMDServiceFunc(
 		func(ctx context.Context, x ChatCMD) (ChatResult, error) {
 			model := openai.GPT3Dot5Turbo1106
 			tools := []openai.Tool{
@@ -307,7 +308,11 @@ func main() {
 			log.Infof("result: %+v", result)
 			return response, nil
 		},
-	))
+	)
+
+	e.POST("/message", TypedJSONRequest(chat))
+	// generated router serves each command of ChatCMD under own endpoint, like POST /chat/UserMessage
+	e.Any("/chat/*", echo.WrapHandler(http.StripPrefix("/chat", NewChatCMDHTTPHandler(chat))))
 
 	e.POST("/func", func(c echo.Context) error {
 		data, err := io.ReadAll(c.Request().Body)
@@ -662,8 +667,14 @@ func TypedJSONRequest[A, B any](handle func(ctx context.Context, x A) (B, error)
 			return err
 		}
 
-		in, err := shared.JSONUnmarshal[A](data)
+		in, err := schema.JSONUnmarshal[A](data)
 		if err != nil {
+			var errs schema.ValidationErrors
+			if errors.As(err, &errs) {
+				log.Errorf("TypedJSONRequest: invalid request body: %v", err)
+				return echo.NewHTTPError(http.StatusBadRequest, errs.Error())
+			}
+
 			log.Errorf("TypedJSONRequest: failed to parse request body: %v", err)
 			return err
 		}
@@ -70,6 +70,65 @@ func MatchChatCMDR0(
 		f1(v)
 	}
 }
+
+// ChatCMDEqual returns true, when a and b are the same variant of ChatCMD with equal fields.
+func ChatCMDEqual(a, b ChatCMD) bool {
+	switch x := a.(type) {
+	case *UserMessage:
+		y, ok := b.(*UserMessage)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// ChatCMDHash returns hash of x, that is the same for values equal according to ChatCMDEqual.
+func ChatCMDHash(x ChatCMD) uint64 {
+	switch y := x.(type) {
+	case *UserMessage:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// ChatCMDDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func ChatCMDDeepCopy(x ChatCMD) ChatCMD {
+	switch y := x.(type) {
+	case *UserMessage:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *UserMessage) Equal(other *UserMessage) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Message != other.Message {
+		return false
+	}
+	return true
+}
+
+func (r *UserMessage) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "main.UserMessage")
+	h = shared.HashString(h, r.Message)
+	return h
+}
+
+func (r *UserMessage) DeepCopy() *UserMessage {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/example/my-app.ChatCMD", ChatCMDFromJSON, ChatCMDToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/example/my-app.UserMessage", UserMessageFromJSON, UserMessageToJSON)
@@ -84,7 +143,7 @@ func ChatCMDFromJSON(x []byte) (ChatCMD, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data ChatCMDUnionJSON
@@ -293,6 +352,204 @@ func MatchChatResultR0(
 		f3(v)
 	}
 }
+
+// RewriteChatResultTopDown applies f to x, and then to each ChatResult nested in result of f (pre-order).
+// When f returns nil, nested values are not visited.
+func RewriteChatResultTopDown(x ChatResult, f func(ChatResult) ChatResult) ChatResult {
+	if x == nil {
+		return nil
+	}
+	return rewriteChatResultChildren(f(x), func(y ChatResult) ChatResult {
+		return RewriteChatResultTopDown(y, f)
+	})
+}
+
+// RewriteChatResultBottomUp applies f to each ChatResult nested in x, and then to x with already rewritten values (post-order).
+func RewriteChatResultBottomUp(x ChatResult, f func(ChatResult) ChatResult) ChatResult {
+	if x == nil {
+		return nil
+	}
+	return f(rewriteChatResultChildren(x, func(y ChatResult) ChatResult {
+		return RewriteChatResultBottomUp(y, f)
+	}))
+}
+
+// rewriteChatResultChildren returns shallow copy of x, where each ChatResult nested in x is replaced by result of f.
+// Variants without nested ChatResult are returned as they are, and x is never modified.
+func rewriteChatResultChildren(x ChatResult, f func(ChatResult) ChatResult) ChatResult {
+	switch v := x.(type) {
+	case *ChatResponses:
+		result := *v
+		if v.Responses != nil {
+			result.Responses = make([]ChatResult, len(v.Responses))
+			for i1 := range v.Responses {
+				result.Responses[i1] = f(v.Responses[i1])
+			}
+		}
+		return &result
+	}
+
+	return x
+}
+
+// ChatResultEqual returns true, when a and b are the same variant of ChatResult with equal fields.
+func ChatResultEqual(a, b ChatResult) bool {
+	switch x := a.(type) {
+	case *SystemResponse:
+		y, ok := b.(*SystemResponse)
+		return ok && x.Equal(y)
+	case *UserResponse:
+		y, ok := b.(*UserResponse)
+		return ok && x.Equal(y)
+	case *ChatResponses:
+		y, ok := b.(*ChatResponses)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// ChatResultHash returns hash of x, that is the same for values equal according to ChatResultEqual.
+func ChatResultHash(x ChatResult) uint64 {
+	switch y := x.(type) {
+	case *SystemResponse:
+		return y.Hash()
+	case *UserResponse:
+		return y.Hash()
+	case *ChatResponses:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// ChatResultDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func ChatResultDeepCopy(x ChatResult) ChatResult {
+	switch y := x.(type) {
+	case *SystemResponse:
+		return y.DeepCopy()
+	case *UserResponse:
+		return y.DeepCopy()
+	case *ChatResponses:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *SystemResponse) Equal(other *SystemResponse) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Message != other.Message {
+		return false
+	}
+	if len(r.ToolCalls) != len(other.ToolCalls) {
+		return false
+	}
+	for i1 := range r.ToolCalls {
+		if !shared.Equal(r.ToolCalls[i1], other.ToolCalls[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *SystemResponse) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "main.SystemResponse")
+	h = shared.HashString(h, r.Message)
+	h = shared.HashUint64(h, uint64(len(r.ToolCalls)))
+	for _, v1 := range r.ToolCalls {
+		h = shared.Hash(h, v1)
+	}
+	return h
+}
+
+func (r *SystemResponse) DeepCopy() *SystemResponse {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.ToolCalls != nil {
+		result.ToolCalls = make([]openai.ToolCall, len(r.ToolCalls))
+		for i1 := range r.ToolCalls {
+			result.ToolCalls[i1] = shared.DeepCopy(r.ToolCalls[i1])
+		}
+	}
+	return &result
+}
+
+func (r *UserResponse) Equal(other *UserResponse) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Message != other.Message {
+		return false
+	}
+	return true
+}
+
+func (r *UserResponse) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "main.UserResponse")
+	h = shared.HashString(h, r.Message)
+	return h
+}
+
+func (r *UserResponse) DeepCopy() *UserResponse {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *ChatResponses) Equal(other *ChatResponses) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.Responses) != len(other.Responses) {
+		return false
+	}
+	for i1 := range r.Responses {
+		if !ChatResultEqual(r.Responses[i1], other.Responses[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *ChatResponses) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "main.ChatResponses")
+	h = shared.HashUint64(h, uint64(len(r.Responses)))
+	for _, v1 := range r.Responses {
+		h = shared.HashUint64(h, ChatResultHash(v1))
+	}
+	return h
+}
+
+func (r *ChatResponses) DeepCopy() *ChatResponses {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Responses != nil {
+		result.Responses = make([]ChatResult, len(r.Responses))
+		for i1 := range r.Responses {
+			result.Responses[i1] = ChatResultDeepCopy(r.Responses[i1])
+		}
+	}
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/example/my-app.ChatResponses", ChatResponsesFromJSON, ChatResponsesToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/example/my-app.ChatResult", ChatResultFromJSON, ChatResultToJSON)
@@ -311,7 +568,7 @@ func ChatResultFromJSON(x []byte) (ChatResult, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data ChatResultUnionJSON
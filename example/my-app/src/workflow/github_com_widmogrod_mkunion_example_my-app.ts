@@ -4,8 +4,29 @@ export type ChatCMD = {
 	"main.UserMessage": UserMessage
 }
 
-export type UserMessage = {
-	Message?: string,
+export type ChatCMDService = {
+	UserMessage: (x: UserMessage) => Promise<ChatResult>,
+}
+
+export function ChatCMDClient(baseURL: string, init?: RequestInit): ChatCMDService {
+	const call = async (path: string, x: any): Promise<any> => {
+		const headers = new Headers(init?.headers)
+		headers.set("Content-Type", "application/json")
+		const response = await fetch(baseURL + path, {
+			...init,
+			method: "POST",
+			headers: headers,
+			body: JSON.stringify(x),
+		})
+		if (!response.ok) {
+			throw new Error(`${path}: ${response.status} ${await response.text()}`)
+		}
+		return response.json()
+	}
+
+	return {
+		UserMessage: (x) => call("/UserMessage", x),
+	}
 }
 
 export type ChatResult = {
@@ -32,6 +53,10 @@ export type ChatResponses = {
 	Responses?: ChatResult[],
 }
 
+export type UserMessage = {
+	Message?: string,
+}
+
 export type Command = workflow.Command
 
 export type Expr = workflow.Expr
@@ -50,7 +75,7 @@ export type GenerateImage = {
 export type ListWorkflowsFn = {
 	Count?: number,
 	Words?: string[],
-	EnumTest?: string,
+	EnumTest?: "hello" | "world",
 }
 
 export type PageResult = schemaless.PageResult<schemaless.Record<any>>
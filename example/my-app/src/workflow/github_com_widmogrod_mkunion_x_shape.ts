@@ -171,6 +171,7 @@ export type FieldLike = {
 	Desc?: string,
 	Guard?: Guard,
 	Tags?: {[key: string]: Tag},
+	Embedded?: boolean,
 }
 
 export type Guard = {
@@ -179,6 +180,24 @@ export type Guard = {
 } | {
 	"$type"?: "shape.Required",
 	"shape.Required": Required
+} | {
+	"$type"?: "shape.Regexp",
+	"shape.Regexp": Regexp
+} | {
+	"$type"?: "shape.Between",
+	"shape.Between": Between
+} | {
+	"$type"?: "shape.MinLength",
+	"shape.MinLength": MinLength
+} | {
+	"$type"?: "shape.MaxLength",
+	"shape.MaxLength": MaxLength
+} | {
+	"$type"?: "shape.MinItems",
+	"shape.MinItems": MinItems
+} | {
+	"$type"?: "shape.MaxItems",
+	"shape.MaxItems": MaxItems
 } | {
 	"$type"?: "shape.AndGuard",
 	"shape.AndGuard": AndGuard
@@ -190,6 +209,31 @@ export type Enum = {
 
 export type Required = {}
 
+export type Regexp = {
+	Regexp?: string,
+}
+
+export type Between = {
+	Min?: number,
+	Max?: number,
+}
+
+export type MinLength = {
+	Len?: number,
+}
+
+export type MaxLength = {
+	Len?: number,
+}
+
+export type MinItems = {
+	Len?: number,
+}
+
+export type MaxItems = {
+	Len?: number,
+}
+
 export type AndGuard = {
 	L?: Guard[],
 }
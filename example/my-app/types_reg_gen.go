@@ -9,9 +9,11 @@ import (
 	"github.com/widmogrod/mkunion/x/storage/schemaless/typedful"
 	"github.com/widmogrod/mkunion/x/taskqueue"
 	"github.com/widmogrod/mkunion/x/workflow"
+	"net/http"
 )
 
 func init() {
+	shared.TypeRegistryStore[ChatCMDHTTPClient]("github.com/widmogrod/mkunion/example/my-app.ChatCMDHTTPClient")
 	shared.TypeRegistryStore[ChatResponses]("github.com/widmogrod/mkunion/example/my-app.ChatResponses")
 	shared.TypeRegistryStore[SystemResponse]("github.com/widmogrod/mkunion/example/my-app.SystemResponse")
 	shared.TypeRegistryStore[UserMessage]("github.com/widmogrod/mkunion/example/my-app.UserMessage")
@@ -37,4 +39,5 @@ func init() {
 	shared.TypeRegistryStore[workflow.FlowRef]("github.com/widmogrod/mkunion/x/workflow.FlowRef")
 	shared.TypeRegistryStore[workflow.FunctionInput]("github.com/widmogrod/mkunion/x/workflow.FunctionInput")
 	shared.TypeRegistryStore[workflow.FunctionOutput]("github.com/widmogrod/mkunion/x/workflow.FunctionOutput")
+	shared.TypeRegistryStore[http.Client]("net/http.Client")
 }
@@ -32,6 +32,23 @@ func TestShapeJSON(t *testing.T) {
 
 // --8<-- [end:json]
 
+// --8<-- [start:equal]
+
+func TestShapeEqual(t *testing.T) {
+	a := &Rectangle{Width: 10, Height: 20}
+	b := ShapeDeepCopy(a)
+
+	if !ShapeEqual(a, b) || ShapeHash(a) != ShapeHash(b) {
+		t.Fatal("copy must be equal and have the same hash")
+	}
+
+	if ShapeEqual(a, &Square{Side: 10}) {
+		t.Fatal("different variants are never equal")
+	}
+}
+
+// --8<-- [end:equal]
+
 func TestCalculateArea(t *testing.T) {
 	tests := []struct {
 		name     string
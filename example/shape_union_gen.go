@@ -99,6 +99,137 @@ func MatchShapeR0(
 		f3(v)
 	}
 }
+
+// ShapeEqual returns true, when a and b are the same variant of Shape with equal fields.
+func ShapeEqual(a, b Shape) bool {
+	switch x := a.(type) {
+	case *Circle:
+		y, ok := b.(*Circle)
+		return ok && x.Equal(y)
+	case *Rectangle:
+		y, ok := b.(*Rectangle)
+		return ok && x.Equal(y)
+	case *Square:
+		y, ok := b.(*Square)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// ShapeHash returns hash of x, that is the same for values equal according to ShapeEqual.
+func ShapeHash(x Shape) uint64 {
+	switch y := x.(type) {
+	case *Circle:
+		return y.Hash()
+	case *Rectangle:
+		return y.Hash()
+	case *Square:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// ShapeDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func ShapeDeepCopy(x Shape) Shape {
+	switch y := x.(type) {
+	case *Circle:
+		return y.DeepCopy()
+	case *Rectangle:
+		return y.DeepCopy()
+	case *Square:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Circle) Equal(other *Circle) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Radius != other.Radius {
+		return false
+	}
+	return true
+}
+
+func (r *Circle) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "example.Circle")
+	h = shared.HashFloat64(h, r.Radius)
+	return h
+}
+
+func (r *Circle) DeepCopy() *Circle {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Rectangle) Equal(other *Rectangle) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Width != other.Width {
+		return false
+	}
+	if r.Height != other.Height {
+		return false
+	}
+	return true
+}
+
+func (r *Rectangle) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "example.Rectangle")
+	h = shared.HashFloat64(h, r.Width)
+	h = shared.HashFloat64(h, r.Height)
+	return h
+}
+
+func (r *Rectangle) DeepCopy() *Rectangle {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Square) Equal(other *Square) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Side != other.Side {
+		return false
+	}
+	return true
+}
+
+func (r *Square) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "example.Square")
+	h = shared.HashFloat64(h, r.Side)
+	return h
+}
+
+func (r *Square) DeepCopy() *Square {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/example.Circle", CircleFromJSON, CircleToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/example.Rectangle", RectangleFromJSON, RectangleToJSON)
@@ -117,7 +248,7 @@ func ShapeFromJSON(x []byte) (Shape, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data ShapeUnionJSON
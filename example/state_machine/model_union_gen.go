@@ -129,6 +129,215 @@ func MatchCommandR0(
 		f5(v)
 	}
 }
+
+// CommandEqual returns true, when a and b are the same variant of Command with equal fields.
+func CommandEqual(a, b Command) bool {
+	switch x := a.(type) {
+	case *CreateOrderCMD:
+		y, ok := b.(*CreateOrderCMD)
+		return ok && x.Equal(y)
+	case *StartProcessingCMD:
+		y, ok := b.(*StartProcessingCMD)
+		return ok && x.Equal(y)
+	case *CompleteOrderCMD:
+		y, ok := b.(*CompleteOrderCMD)
+		return ok && x.Equal(y)
+	case *CancelOrderCMD:
+		y, ok := b.(*CancelOrderCMD)
+		return ok && x.Equal(y)
+	case *ConfirmOrderCMD:
+		y, ok := b.(*ConfirmOrderCMD)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// CommandHash returns hash of x, that is the same for values equal according to CommandEqual.
+func CommandHash(x Command) uint64 {
+	switch y := x.(type) {
+	case *CreateOrderCMD:
+		return y.Hash()
+	case *StartProcessingCMD:
+		return y.Hash()
+	case *CompleteOrderCMD:
+		return y.Hash()
+	case *CancelOrderCMD:
+		return y.Hash()
+	case *ConfirmOrderCMD:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// CommandDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func CommandDeepCopy(x Command) Command {
+	switch y := x.(type) {
+	case *CreateOrderCMD:
+		return y.DeepCopy()
+	case *StartProcessingCMD:
+		return y.DeepCopy()
+	case *CompleteOrderCMD:
+		return y.DeepCopy()
+	case *CancelOrderCMD:
+		return y.DeepCopy()
+	case *ConfirmOrderCMD:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *CreateOrderCMD) Equal(other *CreateOrderCMD) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.OrderID != other.OrderID {
+		return false
+	}
+	if len(r.Items) != len(other.Items) {
+		return false
+	}
+	for i1 := range r.Items {
+		if !shared.Equal(r.Items[i1], other.Items[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *CreateOrderCMD) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "state_machine.CreateOrderCMD")
+	h = shared.HashString(h, r.OrderID)
+	h = shared.HashUint64(h, uint64(len(r.Items)))
+	for _, v1 := range r.Items {
+		h = shared.Hash(h, v1)
+	}
+	return h
+}
+
+func (r *CreateOrderCMD) DeepCopy() *CreateOrderCMD {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Items != nil {
+		result.Items = make([]OrderItem, len(r.Items))
+		for i1 := range r.Items {
+			result.Items[i1] = shared.DeepCopy(r.Items[i1])
+		}
+	}
+	return &result
+}
+
+func (r *StartProcessingCMD) Equal(other *StartProcessingCMD) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.WorkerID != other.WorkerID {
+		return false
+	}
+	return true
+}
+
+func (r *StartProcessingCMD) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "state_machine.StartProcessingCMD")
+	h = shared.HashString(h, r.WorkerID)
+	return h
+}
+
+func (r *StartProcessingCMD) DeepCopy() *StartProcessingCMD {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *CompleteOrderCMD) Equal(other *CompleteOrderCMD) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.TotalAmount != other.TotalAmount {
+		return false
+	}
+	return true
+}
+
+func (r *CompleteOrderCMD) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "state_machine.CompleteOrderCMD")
+	h = shared.HashFloat64(h, r.TotalAmount)
+	return h
+}
+
+func (r *CompleteOrderCMD) DeepCopy() *CompleteOrderCMD {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *CancelOrderCMD) Equal(other *CancelOrderCMD) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Reason != other.Reason {
+		return false
+	}
+	return true
+}
+
+func (r *CancelOrderCMD) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "state_machine.CancelOrderCMD")
+	h = shared.HashString(h, r.Reason)
+	return h
+}
+
+func (r *CancelOrderCMD) DeepCopy() *CancelOrderCMD {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *ConfirmOrderCMD) Equal(other *ConfirmOrderCMD) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *ConfirmOrderCMD) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "state_machine.ConfirmOrderCMD")
+	return h
+}
+
+func (r *ConfirmOrderCMD) DeepCopy() *ConfirmOrderCMD {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/example/state_machine.CancelOrderCMD", CancelOrderCMDFromJSON, CancelOrderCMDToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/example/state_machine.Command", CommandFromJSON, CommandToJSON)
@@ -151,7 +360,7 @@ func CommandFromJSON(x []byte) (Command, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data CommandUnionJSON
@@ -773,6 +982,233 @@ func MatchStateR0(
 		f4(v)
 	}
 }
+
+// StateEqual returns true, when a and b are the same variant of State with equal fields.
+func StateEqual(a, b State) bool {
+	switch x := a.(type) {
+	case *OrderPending:
+		y, ok := b.(*OrderPending)
+		return ok && x.Equal(y)
+	case *OrderProcessing:
+		y, ok := b.(*OrderProcessing)
+		return ok && x.Equal(y)
+	case *OrderCompleted:
+		y, ok := b.(*OrderCompleted)
+		return ok && x.Equal(y)
+	case *OrderCancelled:
+		y, ok := b.(*OrderCancelled)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// StateHash returns hash of x, that is the same for values equal according to StateEqual.
+func StateHash(x State) uint64 {
+	switch y := x.(type) {
+	case *OrderPending:
+		return y.Hash()
+	case *OrderProcessing:
+		return y.Hash()
+	case *OrderCompleted:
+		return y.Hash()
+	case *OrderCancelled:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// StateDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func StateDeepCopy(x State) State {
+	switch y := x.(type) {
+	case *OrderPending:
+		return y.DeepCopy()
+	case *OrderProcessing:
+		return y.DeepCopy()
+	case *OrderCompleted:
+		return y.DeepCopy()
+	case *OrderCancelled:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *OrderPending) Equal(other *OrderPending) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.OrderID != other.OrderID {
+		return false
+	}
+	if len(r.Items) != len(other.Items) {
+		return false
+	}
+	for i1 := range r.Items {
+		if !shared.Equal(r.Items[i1], other.Items[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *OrderPending) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "state_machine.OrderPending")
+	h = shared.HashString(h, r.OrderID)
+	h = shared.HashUint64(h, uint64(len(r.Items)))
+	for _, v1 := range r.Items {
+		h = shared.Hash(h, v1)
+	}
+	return h
+}
+
+func (r *OrderPending) DeepCopy() *OrderPending {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Items != nil {
+		result.Items = make([]OrderItem, len(r.Items))
+		for i1 := range r.Items {
+			result.Items[i1] = shared.DeepCopy(r.Items[i1])
+		}
+	}
+	return &result
+}
+
+func (r *OrderProcessing) Equal(other *OrderProcessing) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.OrderID != other.OrderID {
+		return false
+	}
+	if len(r.Items) != len(other.Items) {
+		return false
+	}
+	for i1 := range r.Items {
+		if !shared.Equal(r.Items[i1], other.Items[i1]) {
+			return false
+		}
+	}
+	if r.WorkerID != other.WorkerID {
+		return false
+	}
+	return true
+}
+
+func (r *OrderProcessing) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "state_machine.OrderProcessing")
+	h = shared.HashString(h, r.OrderID)
+	h = shared.HashUint64(h, uint64(len(r.Items)))
+	for _, v1 := range r.Items {
+		h = shared.Hash(h, v1)
+	}
+	h = shared.HashString(h, r.WorkerID)
+	return h
+}
+
+func (r *OrderProcessing) DeepCopy() *OrderProcessing {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Items != nil {
+		result.Items = make([]OrderItem, len(r.Items))
+		for i1 := range r.Items {
+			result.Items[i1] = shared.DeepCopy(r.Items[i1])
+		}
+	}
+	return &result
+}
+
+func (r *OrderCompleted) Equal(other *OrderCompleted) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.OrderID != other.OrderID {
+		return false
+	}
+	if len(r.Items) != len(other.Items) {
+		return false
+	}
+	for i1 := range r.Items {
+		if !shared.Equal(r.Items[i1], other.Items[i1]) {
+			return false
+		}
+	}
+	if r.TotalAmount != other.TotalAmount {
+		return false
+	}
+	return true
+}
+
+func (r *OrderCompleted) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "state_machine.OrderCompleted")
+	h = shared.HashString(h, r.OrderID)
+	h = shared.HashUint64(h, uint64(len(r.Items)))
+	for _, v1 := range r.Items {
+		h = shared.Hash(h, v1)
+	}
+	h = shared.HashFloat64(h, r.TotalAmount)
+	return h
+}
+
+func (r *OrderCompleted) DeepCopy() *OrderCompleted {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Items != nil {
+		result.Items = make([]OrderItem, len(r.Items))
+		for i1 := range r.Items {
+			result.Items[i1] = shared.DeepCopy(r.Items[i1])
+		}
+	}
+	return &result
+}
+
+func (r *OrderCancelled) Equal(other *OrderCancelled) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.OrderID != other.OrderID {
+		return false
+	}
+	if r.Reason != other.Reason {
+		return false
+	}
+	return true
+}
+
+func (r *OrderCancelled) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "state_machine.OrderCancelled")
+	h = shared.HashString(h, r.OrderID)
+	h = shared.HashString(h, r.Reason)
+	return h
+}
+
+func (r *OrderCancelled) DeepCopy() *OrderCancelled {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/example/state_machine.OrderCancelled", OrderCancelledFromJSON, OrderCancelledToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/example/state_machine.OrderCompleted", OrderCompletedFromJSON, OrderCompletedToJSON)
@@ -793,7 +1229,7 @@ func StateFromJSON(x []byte) (State, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data StateUnionJSON
@@ -2,7 +2,7 @@ package example
 
 // --8<-- [start:tree-def]
 
-//go:tag mkunion:"Tree[A]"
+//go:tag mkunion:"Tree[A],derive=functor"
 type (
 	Branch[A any] struct{ L, R Tree[A] }
 	Leaf[A any]   struct{ Value A }
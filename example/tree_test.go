@@ -67,6 +67,42 @@ func Example_treeCustomReduction() {
 
 // --8<-- [end:example-custom-agg]
 
+// --8<-- [start:example-derive-functor]
+
+func Example_treeDeriveFunctor() {
+	tree := &Branch[int]{
+		L: &Leaf[int]{Value: 1},
+		R: &Branch[int]{
+			L: &Leaf[int]{Value: 2},
+			R: &Leaf[int]{Value: 3},
+		},
+	}
+
+	labels := MapTree(tree, func(x int) string {
+		return fmt.Sprintf("#%d", x)
+	})
+	fmt.Println(FoldTree(labels, func(x string, agg string) string {
+		return agg + x
+	}, ""))
+
+	_, err := TraverseTree(tree, func(x int) (int, error) {
+		if x == 2 {
+			return 0, fmt.Errorf("unexpected %d", x)
+		}
+		return x, nil
+	})
+	fmt.Println(err)
+
+	clone := TreeDeepCopy[int](tree)
+	clone.(*Branch[int]).L.(*Leaf[int]).Value = 10
+	fmt.Println(TreeEqual[int](tree, clone))
+	// Output: #1#2#3
+	// unexpected 2
+	// false
+}
+
+// --8<-- [end:example-derive-functor]
+
 func TestTreeSchema(t *testing.T) {
 	tree := &Branch[int]{
 		L: &Leaf[int]{Value: 1},
@@ -84,6 +84,101 @@ func MatchEitherR0[A any, B any](
 		f2(v)
 	}
 }
+
+// EitherEqual returns true, when a and b are the same variant of Either with equal fields.
+func EitherEqual[A any, B any](a, b Either[A, B]) bool {
+	switch x := a.(type) {
+	case *Left[A, B]:
+		y, ok := b.(*Left[A, B])
+		return ok && x.Equal(y)
+	case *Right[A, B]:
+		y, ok := b.(*Right[A, B])
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// EitherHash returns hash of x, that is the same for values equal according to EitherEqual.
+func EitherHash[A any, B any](x Either[A, B]) uint64 {
+	switch y := x.(type) {
+	case *Left[A, B]:
+		return y.Hash()
+	case *Right[A, B]:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// EitherDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func EitherDeepCopy[A any, B any](x Either[A, B]) Either[A, B] {
+	switch y := x.(type) {
+	case *Left[A, B]:
+		return y.DeepCopy()
+	case *Right[A, B]:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Left[A, B]) Equal(other *Left[A, B]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Value, other.Value) {
+		return false
+	}
+	return true
+}
+
+func (r *Left[A, B]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "f.Left")
+	h = shared.Hash(h, r.Value)
+	return h
+}
+
+func (r *Left[A, B]) DeepCopy() *Left[A, B] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Value = shared.DeepCopy(r.Value)
+	return &result
+}
+
+func (r *Right[A, B]) Equal(other *Right[A, B]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Value, other.Value) {
+		return false
+	}
+	return true
+}
+
+func (r *Right[A, B]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "f.Right")
+	h = shared.Hash(h, r.Value)
+	return h
+}
+
+func (r *Right[A, B]) DeepCopy() *Right[A, B] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Value = shared.DeepCopy(r.Value)
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/f.Either[any,any]", EitherFromJSON[any, any], EitherToJSON[any, any])
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/f.Left[any,any]", LeftFromJSON[any, any], LeftToJSON[any, any])
@@ -100,7 +195,7 @@ func EitherFromJSON[A any, B any](x []byte) (Either[A, B], error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data EitherUnionJSON[A, B]
@@ -384,6 +479,96 @@ func MatchOptionR0[A any](
 		f2(v)
 	}
 }
+
+// OptionEqual returns true, when a and b are the same variant of Option with equal fields.
+func OptionEqual[A any](a, b Option[A]) bool {
+	switch x := a.(type) {
+	case *None[A]:
+		y, ok := b.(*None[A])
+		return ok && x.Equal(y)
+	case *Some[A]:
+		y, ok := b.(*Some[A])
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// OptionHash returns hash of x, that is the same for values equal according to OptionEqual.
+func OptionHash[A any](x Option[A]) uint64 {
+	switch y := x.(type) {
+	case *None[A]:
+		return y.Hash()
+	case *Some[A]:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// OptionDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func OptionDeepCopy[A any](x Option[A]) Option[A] {
+	switch y := x.(type) {
+	case *None[A]:
+		return y.DeepCopy()
+	case *Some[A]:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *None[A]) Equal(other *None[A]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *None[A]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "f.None")
+	return h
+}
+
+func (r *None[A]) DeepCopy() *None[A] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Some[A]) Equal(other *Some[A]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Value, other.Value) {
+		return false
+	}
+	return true
+}
+
+func (r *Some[A]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "f.Some")
+	h = shared.Hash(h, r.Value)
+	return h
+}
+
+func (r *Some[A]) DeepCopy() *Some[A] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Value = shared.DeepCopy(r.Value)
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/f.None[any]", NoneFromJSON[any], NoneToJSON[any])
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/f.Option[any]", OptionFromJSON[any], OptionToJSON[any])
@@ -400,7 +585,7 @@ func OptionFromJSON[A any](x []byte) (Option[A], error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data OptionUnionJSON[A]
@@ -658,6 +843,101 @@ func MatchResultR0[A any, E any](
 		f2(v)
 	}
 }
+
+// ResultEqual returns true, when a and b are the same variant of Result with equal fields.
+func ResultEqual[A any, E any](a, b Result[A, E]) bool {
+	switch x := a.(type) {
+	case *Ok[A, E]:
+		y, ok := b.(*Ok[A, E])
+		return ok && x.Equal(y)
+	case *Err[A, E]:
+		y, ok := b.(*Err[A, E])
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// ResultHash returns hash of x, that is the same for values equal according to ResultEqual.
+func ResultHash[A any, E any](x Result[A, E]) uint64 {
+	switch y := x.(type) {
+	case *Ok[A, E]:
+		return y.Hash()
+	case *Err[A, E]:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// ResultDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func ResultDeepCopy[A any, E any](x Result[A, E]) Result[A, E] {
+	switch y := x.(type) {
+	case *Ok[A, E]:
+		return y.DeepCopy()
+	case *Err[A, E]:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Ok[A, E]) Equal(other *Ok[A, E]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Value, other.Value) {
+		return false
+	}
+	return true
+}
+
+func (r *Ok[A, E]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "f.Ok")
+	h = shared.Hash(h, r.Value)
+	return h
+}
+
+func (r *Ok[A, E]) DeepCopy() *Ok[A, E] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Value = shared.DeepCopy(r.Value)
+	return &result
+}
+
+func (r *Err[A, E]) Equal(other *Err[A, E]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Error, other.Error) {
+		return false
+	}
+	return true
+}
+
+func (r *Err[A, E]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "f.Err")
+	h = shared.Hash(h, r.Error)
+	return h
+}
+
+func (r *Err[A, E]) DeepCopy() *Err[A, E] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Error = shared.DeepCopy(r.Error)
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/f.Err[any,any]", ErrFromJSON[any, any], ErrToJSON[any, any])
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/f.Ok[any,any]", OkFromJSON[any, any], OkToJSON[any, any])
@@ -674,7 +954,7 @@ func ResultFromJSON[A any, E any](x []byte) (Result[A, E], error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data ResultUnionJSON[A, E]
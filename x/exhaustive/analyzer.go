@@ -0,0 +1,325 @@
+package exhaustive
+
+import (
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+	"regexp"
+	"strings"
+)
+
+const doc = `check that type switches over mkunion unions handle all variants
+
+Type switch on union generated by mkunion must have case for each variant,
+or default clause that handles remaining variants.
+
+Calls to Match{Name}R* functions are reported, when order of handlers
+differs from order of variants in union declaration,
+which means that generated code is out of date, and mkunion must be run again.`
+
+// Analyzer reports type switches over unions that don't handle all variants,
+// and calls to generated match functions that are out of date with union declaration.
+//
+// Variants of union are read from //go:tag mkunion declaration with shape.InferFromFile,
+// and are shared with packages that import union as analysis facts.
+var Analyzer = &analysis.Analyzer{
+	Name:      "mkunionexhaustive",
+	Doc:       doc,
+	URL:       "https://github.com/widmogrod/mkunion",
+	Run:       run,
+	FactTypes: []analysis.Fact{new(unionFact)},
+}
+
+// unionFact holds names of union variants, in order of declaration.
+type unionFact struct {
+	Variants []string
+}
+
+func (*unionFact) AFact() {}
+
+func (f *unionFact) String() string {
+	return fmt.Sprintf("union(%s)", strings.Join(f.Variants, ", "))
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	exportUnionFacts(pass)
+
+	for _, file := range pass.Files {
+		if ast.IsGenerated(file) {
+			continue
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch x := node.(type) {
+			case *ast.TypeSwitchStmt:
+				checkTypeSwitch(pass, x)
+			case *ast.CallExpr:
+				checkMatchCall(pass, x)
+			}
+			return true
+		})
+	}
+
+	return nil, nil
+}
+
+func exportUnionFacts(pass *analysis.Pass) {
+	for _, file := range pass.Files {
+		if !hasUnionDeclaration(file) {
+			continue
+		}
+
+		inferred, err := shape.InferFromFile(pass.Fset.File(file.Pos()).Name())
+		if err != nil {
+			// mkunion reports invalid union declarations, when generating code
+			continue
+		}
+
+		for _, union := range inferred.RetrieveUnions() {
+			obj, ok := pass.Pkg.Scope().Lookup(union.Name).(*types.TypeName)
+			if !ok {
+				// union interface is not generated yet
+				continue
+			}
+
+			fact := &unionFact{}
+			for _, variant := range union.Variant {
+				fact.Variants = append(fact.Variants, shape.Name(variant))
+			}
+
+			pass.ExportObjectFact(obj, fact)
+		}
+	}
+}
+
+func hasUnionDeclaration(file *ast.File) bool {
+	for _, decl := range file.Decls {
+		x, ok := decl.(*ast.GenDecl)
+		if !ok || x.Tok != token.TYPE {
+			continue
+		}
+
+		if _, ok := shape.ExtractDocumentTags(x.Doc)[shape.TagUnionName]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// variant is type declared as part of union.
+type variant struct {
+	name string
+	obj  *types.TypeName
+}
+
+// lookupUnion returns variants of union, when t is union generated by mkunion.
+func lookupUnion(pass *analysis.Pass, t types.Type) (*types.TypeName, []variant, bool) {
+	obj := namedObj(t)
+	if obj == nil || obj.Pkg() == nil {
+		return nil, nil, false
+	}
+
+	fact := &unionFact{}
+	if !pass.ImportObjectFact(obj, fact) {
+		return nil, nil, false
+	}
+
+	var result []variant
+	for _, name := range fact.Variants {
+		found, ok := obj.Pkg().Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+
+		// variant can be alias of other type, like Tree2 = Branch
+		if target := namedObj(found.Type()); target != nil {
+			found = target
+		}
+
+		result = append(result, variant{name: name, obj: found})
+	}
+
+	return obj, result, true
+}
+
+// namedObj returns declaration of named type, or generic type when t is its instance.
+func namedObj(t types.Type) *types.TypeName {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return nil
+	}
+
+	return named.Origin().Obj()
+}
+
+// variantObj returns declaration of variant, that is handled by pointer to it.
+func variantObj(t types.Type) *types.TypeName {
+	ptr, ok := types.Unalias(t).(*types.Pointer)
+	if !ok {
+		return nil
+	}
+
+	return namedObj(ptr.Elem())
+}
+
+func checkTypeSwitch(pass *analysis.Pass, x *ast.TypeSwitchStmt) {
+	var assert *ast.TypeAssertExpr
+	switch y := x.Assign.(type) {
+	case *ast.AssignStmt:
+		assert, _ = y.Rhs[0].(*ast.TypeAssertExpr)
+	case *ast.ExprStmt:
+		assert, _ = y.X.(*ast.TypeAssertExpr)
+	}
+	if assert == nil {
+		return
+	}
+
+	subject := pass.TypesInfo.TypeOf(assert.X)
+	union, variants, ok := lookupUnion(pass, subject)
+	if !ok {
+		return
+	}
+
+	covered := make(map[*types.TypeName]bool)
+	for _, stmt := range x.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		if clause.List == nil {
+			// default clause handles remaining variants
+			return
+		}
+
+		for _, expr := range clause.List {
+			t := pass.TypesInfo.TypeOf(expr)
+			if t == nil {
+				continue
+			}
+
+			if obj := variantObj(t); obj != nil {
+				covered[obj] = true
+				continue
+			}
+
+			iface, ok := t.Underlying().(*types.Interface)
+			if !ok {
+				continue
+			}
+
+			for _, v := range variants {
+				// behaviour of types.Implements is unspecified for generic types,
+				// so case with interface is assumed to cover all variants of generic union
+				if isGeneric(v.obj) || types.Implements(types.NewPointer(v.obj.Type()), iface) {
+					covered[v.obj] = true
+				}
+			}
+		}
+	}
+
+	var missing []string
+	for _, v := range variants {
+		if !covered[v.obj] {
+			missing = append(missing, variantName(pass, v))
+		}
+	}
+
+	if len(missing) > 0 {
+		pass.Reportf(x.Pos(), "missing cases in type switch on %s: %s", objName(pass, union), strings.Join(missing, ", "))
+	}
+}
+
+var matchFuncName = regexp.MustCompile(`^Match(\w+)R[0-3]$`)
+
+func checkMatchCall(pass *analysis.Pass, call *ast.CallExpr) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return
+	}
+
+	found := matchFuncName.FindStringSubmatch(fn.Name())
+	if found == nil {
+		return
+	}
+
+	unionObj, ok := fn.Pkg().Scope().Lookup(found[1]).(*types.TypeName)
+	if !ok {
+		return
+	}
+
+	union, variants, ok := lookupUnion(pass, unionObj.Type())
+	if !ok {
+		return
+	}
+
+	sig := fn.Origin().Type().(*types.Signature)
+	var handlers []*types.TypeName
+	for i := 1; i < sig.Params().Len(); i++ {
+		handler, ok := sig.Params().At(i).Type().(*types.Signature)
+		if !ok || handler.Params().Len() != 1 {
+			return
+		}
+
+		handlers = append(handlers, variantObj(handler.Params().At(0).Type()))
+	}
+
+	if sameVariants(handlers, variants) {
+		return
+	}
+
+	var declared, handled []string
+	for _, v := range variants {
+		declared = append(declared, variantName(pass, v))
+	}
+	for _, obj := range handlers {
+		handled = append(handled, "*"+objName(pass, obj))
+	}
+
+	pass.Reportf(call.Fun.Pos(), "%s handles variants in order %s, but %s declares %s; generated code is out of date, run mkunion",
+		fn.Name(), strings.Join(handled, ", "), objName(pass, union), strings.Join(declared, ", "))
+}
+
+func sameVariants(handlers []*types.TypeName, variants []variant) bool {
+	if len(handlers) != len(variants) {
+		return false
+	}
+
+	for i, v := range variants {
+		if handlers[i] != v.obj {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isGeneric(obj *types.TypeName) bool {
+	named, ok := obj.Type().(*types.Named)
+	return ok && named.TypeParams().Len() > 0
+}
+
+func variantName(pass *analysis.Pass, v variant) string {
+	if v.obj.Name() == v.name {
+		return "*" + objName(pass, v.obj)
+	}
+
+	// alias variant is named as declared in union
+	if v.obj.Pkg() == pass.Pkg {
+		return "*" + v.name
+	}
+	return "*" + v.obj.Pkg().Name() + "." + v.name
+}
+
+func objName(pass *analysis.Pass, obj *types.TypeName) string {
+	if obj == nil {
+		return "?"
+	}
+
+	if obj.Pkg() == nil || obj.Pkg() == pass.Pkg {
+		return obj.Name()
+	}
+
+	return obj.Pkg().Name() + "." + obj.Name()
+}
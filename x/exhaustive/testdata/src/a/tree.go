@@ -0,0 +1,108 @@
+package a
+
+//go:tag mkunion:"Tree"
+type (
+	Leaf   struct{ Value int }
+	Branch struct{ L, R Tree }
+	Empty  struct{}
+)
+
+//go:tag mkunion:"Shape"
+type (
+	Circle   struct{ Radius float64 }
+	Square   struct{ Side float64 }
+	Triangle struct{ A, B, C float64 }
+)
+
+//go:tag mkunion:"Option[A]"
+type (
+	Some[A any] struct{ Value A }
+	None[A any] struct{}
+)
+
+type Named interface {
+	IsNamed()
+}
+
+func (*Leaf) IsNamed()  {}
+func (*Empty) IsNamed() {}
+
+func Exhaustive(x Tree) int {
+	switch y := x.(type) {
+	case *Leaf:
+		return y.Value
+	case *Branch:
+		return Exhaustive(y.L) + Exhaustive(y.R)
+	case *Empty:
+		return 0
+	}
+	return 0
+}
+
+func Missing(x Tree) bool {
+	switch x.(type) { // want `missing cases in type switch on Tree: \*Branch, \*Empty`
+	case *Leaf:
+		return true
+	}
+	return false
+}
+
+func MultipleInCase(x Tree) bool {
+	switch x.(type) { // want `missing cases in type switch on Tree: \*Empty`
+	case *Leaf, *Branch, nil:
+		return true
+	}
+	return false
+}
+
+func WithDefault(x Tree) bool {
+	switch x.(type) {
+	case *Leaf:
+		return true
+	default:
+		return false
+	}
+}
+
+func WithInterface(x Tree) bool {
+	switch x.(type) {
+	case Named:
+		return true
+	case *Branch:
+		return false
+	}
+	return false
+}
+
+func Generic[A any](x Option[A]) bool {
+	switch x.(type) { // want `missing cases in type switch on Option: \*None`
+	case *Some[A]:
+		return true
+	}
+	return false
+}
+
+func NotUnion(x any) bool {
+	switch x.(type) {
+	case *Leaf:
+		return true
+	}
+	return false
+}
+
+func Match(x Tree) int {
+	return MatchTreeR1(
+		x,
+		func(x *Leaf) int { return 1 },
+		func(x *Branch) int { return 2 },
+		func(x *Empty) int { return 3 },
+	)
+}
+
+func MatchOutOfDate(x Shape) float64 {
+	return MatchShapeR1( // want `MatchShapeR1 handles variants in order \*Square, \*Circle, but Shape declares \*Circle, \*Square, \*Triangle; generated code is out of date, run mkunion`
+		x,
+		func(x *Square) float64 { return x.Side * x.Side },
+		func(x *Circle) float64 { return 3 * x.Radius * x.Radius },
+	)
+}
@@ -0,0 +1,19 @@
+package b
+
+import "a"
+
+func Missing(x a.Tree) bool {
+	switch x.(type) { // want `missing cases in type switch on a.Tree: \*a.Empty`
+	case *a.Leaf, *a.Branch:
+		return true
+	}
+	return false
+}
+
+func Match(x a.Shape) float64 {
+	return a.MatchShapeR1( // want `MatchShapeR1 handles variants in order \*a.Square, \*a.Circle, but a.Shape declares \*a.Circle, \*a.Square, \*a.Triangle; generated code is out of date, run mkunion`
+		x,
+		func(x *a.Square) float64 { return 1 },
+		func(x *a.Circle) float64 { return 2 },
+	)
+}
@@ -0,0 +1,581 @@
+package generators
+
+import (
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"strings"
+)
+
+// NewDeriveTagged generates Equal, Hash and DeepCopy methods for struct or named type,
+// that is union variant or is tagged with //go:tag serde:"json".
+//
+// Fields that refer to unions, or to types with generated methods, use them directly.
+// Values of type parameters, any, and types without generated methods, use runtime helpers from x/shared.
+func NewDeriveTagged(x shape.Shape) *DeriveTagged {
+	return &DeriveTagged{
+		shape:                 x,
+		skipImportsAndPackage: false,
+		imports:               make(PkgMap),
+	}
+}
+
+type DeriveTagged struct {
+	shape                 shape.Shape
+	skipImportsAndPackage bool
+	imports               PkgMap
+	counter               int
+}
+
+func (g *DeriveTagged) SkipImportsAndPackage(flag bool) *DeriveTagged {
+	g.skipImportsAndPackage = flag
+	return g
+}
+
+func (g *DeriveTagged) Generate() (string, error) {
+	body, err := g.GenerateMethods()
+	if err != nil {
+		return "", fmt.Errorf("generators.DeriveTagged.Generate: %w", err)
+	}
+
+	if g.skipImportsAndPackage {
+		return body, nil
+	}
+
+	head := &strings.Builder{}
+	head.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.shape)))
+	head.WriteString(GenerateImports(g.ExtractImports()))
+	head.WriteString(body)
+	return head.String(), nil
+}
+
+// ExtractImports returns packages used by generated code, so it must be called after Generate.
+func (g *DeriveTagged) ExtractImports() PkgMap {
+	result := make(PkgMap)
+	for pkgName, pkgImportName := range g.imports {
+		if pkgImportName == shape.ToGoPkgImportName(g.shape) {
+			continue
+		}
+		result[pkgName] = pkgImportName
+	}
+
+	return result
+}
+
+func (g *DeriveTagged) GenerateMethods() (string, error) {
+	switch x := g.shape.(type) {
+	case *shape.StructLike:
+		return g.generateStruct(x), nil
+	case *shape.AliasLike:
+		if x.IsAlias {
+			// methods can't be declared on type alias, they belong to aliased type
+			return "", nil
+		}
+		return g.generateNamed(x), nil
+	}
+
+	return "", fmt.Errorf("generators.DeriveTagged.GenerateMethods: expected struct or named type, got %s", shape.ToGoTypeName(g.shape))
+}
+
+func (g *DeriveTagged) generateStruct(x *shape.StructLike) string {
+	typeName := g.selfTypeName()
+	result := &strings.Builder{}
+
+	g.counter = 0
+	body := &strings.Builder{}
+	for _, field := range x.Fields {
+		g.equalTo(body, 1, field.Type, "r."+field.Name, "other."+field.Name)
+	}
+
+	result.WriteString(fmt.Sprintf("func (r *%s) Equal(other *%s) bool {\n", typeName, typeName))
+	result.WriteString(fmt.Sprintf("\tif r == nil || other == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn r == other\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(body.String())
+	result.WriteString(fmt.Sprintf("\treturn true\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	g.counter = 0
+	body = &strings.Builder{}
+	for _, field := range x.Fields {
+		g.hashOf(body, 1, field.Type, "r."+field.Name, "h")
+	}
+
+	result.WriteString(fmt.Sprintf("func (r *%s) Hash() uint64 {\n", typeName))
+	result.WriteString(fmt.Sprintf("\tif r == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn 0\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\th := shared.HashString(shared.HashOffset, %q)\n", g.hashName()))
+	result.WriteString(body.String())
+	result.WriteString(fmt.Sprintf("\treturn h\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	g.counter = 0
+	body = &strings.Builder{}
+	for _, field := range x.Fields {
+		if g.needsCopy(field.Type) {
+			g.copyTo(body, 1, field.Type, "r."+field.Name, "result."+field.Name)
+		}
+	}
+
+	result.WriteString(fmt.Sprintf("func (r *%s) DeepCopy() *%s {\n", typeName, typeName))
+	result.WriteString(fmt.Sprintf("\tif r == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn nil\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\tresult := *r\n"))
+	result.WriteString(body.String())
+	result.WriteString(fmt.Sprintf("\treturn &result\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	g.imports["shared"] = "github.com/widmogrod/mkunion/x/shared"
+
+	return result.String()
+}
+
+func (g *DeriveTagged) generateNamed(x *shape.AliasLike) string {
+	typeName := g.selfTypeName()
+	result := &strings.Builder{}
+
+	// named type of other type with generated methods, like `type Other[A] Some[A]`,
+	// must use methods of underlying type through pointer conversion, since its own methods would recurse
+	if ref, ok := x.Type.(*shape.RefName); ok && g.resolve(ref) == deriveMethods {
+		underlying := g.typeName(ref)
+
+		result.WriteString(fmt.Sprintf("func (r *%s) Equal(other *%s) bool {\n", typeName, typeName))
+		result.WriteString(fmt.Sprintf("\treturn (*%s)(r).Equal((*%s)(other))\n", underlying, underlying))
+		result.WriteString(fmt.Sprintf("}\n\n"))
+
+		result.WriteString(fmt.Sprintf("func (r *%s) Hash() uint64 {\n", typeName))
+		result.WriteString(fmt.Sprintf("\tif r == nil {\n"))
+		result.WriteString(fmt.Sprintf("\t\treturn 0\n"))
+		result.WriteString(fmt.Sprintf("\t}\n"))
+		result.WriteString(fmt.Sprintf("\treturn shared.HashUint64(shared.HashString(shared.HashOffset, %q), (*%s)(r).Hash())\n", g.hashName(), underlying))
+		result.WriteString(fmt.Sprintf("}\n\n"))
+
+		result.WriteString(fmt.Sprintf("func (r *%s) DeepCopy() *%s {\n", typeName, typeName))
+		result.WriteString(fmt.Sprintf("\treturn (*%s)((*%s)(r).DeepCopy())\n", typeName, underlying))
+		result.WriteString(fmt.Sprintf("}\n\n"))
+
+		g.imports["shared"] = "github.com/widmogrod/mkunion/x/shared"
+
+		return result.String()
+	}
+
+	g.counter = 0
+	body := &strings.Builder{}
+	g.equalTo(body, 1, x.Type, "*r", "*other")
+
+	result.WriteString(fmt.Sprintf("func (r *%s) Equal(other *%s) bool {\n", typeName, typeName))
+	result.WriteString(fmt.Sprintf("\tif r == nil || other == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn r == other\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(body.String())
+	result.WriteString(fmt.Sprintf("\treturn true\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	g.counter = 0
+	body = &strings.Builder{}
+	if primitive, ok := x.Type.(*shape.PrimitiveLike); ok {
+		writeLine(body, 1, "h = %s", g.hashPrimitive(primitive, "*r", "h", true))
+	} else {
+		g.hashOf(body, 1, x.Type, "*r", "h")
+	}
+
+	result.WriteString(fmt.Sprintf("func (r *%s) Hash() uint64 {\n", typeName))
+	result.WriteString(fmt.Sprintf("\tif r == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn 0\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\th := shared.HashString(shared.HashOffset, %q)\n", g.hashName()))
+	result.WriteString(body.String())
+	result.WriteString(fmt.Sprintf("\treturn h\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	g.counter = 0
+	body = &strings.Builder{}
+	if g.needsCopy(x.Type) {
+		g.copyTo(body, 1, x.Type, "*r", "result")
+	}
+
+	result.WriteString(fmt.Sprintf("func (r *%s) DeepCopy() *%s {\n", typeName, typeName))
+	result.WriteString(fmt.Sprintf("\tif r == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn nil\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\tresult := *r\n"))
+	result.WriteString(body.String())
+	result.WriteString(fmt.Sprintf("\treturn &result\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	g.imports["shared"] = "github.com/widmogrod/mkunion/x/shared"
+
+	return result.String()
+}
+
+type deriveKind int
+
+const (
+	// deriveRuntime is for type parameters, any, and types without generated methods
+	deriveRuntime deriveKind = iota
+	deriveUnion
+	deriveMethods
+	derivePrimitive
+)
+
+// resolve returns how values of referenced type are compared, hashed and copied.
+func (g *DeriveTagged) resolve(x *shape.RefName) deriveKind {
+	if x.PkgName == "" {
+		return deriveRuntime
+	}
+
+	found, ok := shape.LookupShapeOnDisk(x)
+	if !ok {
+		return deriveRuntime
+	}
+
+	switch y := found.(type) {
+	case *shape.UnionLike:
+		return deriveUnion
+
+	case *shape.StructLike:
+		if HasDerivedMethods(y) {
+			return deriveMethods
+		}
+
+	case *shape.AliasLike:
+		if !y.IsAlias && HasDerivedMethods(y) {
+			return deriveMethods
+		}
+
+		if _, ok := y.Type.(*shape.PrimitiveLike); ok {
+			return derivePrimitive
+		}
+	}
+
+	return deriveRuntime
+}
+
+// HasDerivedMethods returns true, when mkunion generates Equal, Hash and DeepCopy methods for x,
+// which is the case for union variants, and types tagged with serde.
+func HasDerivedMethods(x shape.Shape) bool {
+	tags := shape.Tags(x)
+	if _, ok := tags[shape.TagUnionName]; ok {
+		return true
+	}
+
+	_, ok := tags[shape.TagSerdeName]
+	return ok
+}
+
+// equalTo writes statements that return false, when a is not equal to b.
+func (g *DeriveTagged) equalTo(result *strings.Builder, indent int, x shape.Shape, a, b string) {
+	switch y := x.(type) {
+	case *shape.PrimitiveLike:
+		writeLine(result, indent, "if %s != %s {", a, b)
+		writeLine(result, indent+1, "return false")
+		writeLine(result, indent, "}")
+		return
+
+	case *shape.RefName:
+		switch g.resolve(y) {
+		case deriveUnion:
+			writeLine(result, indent, "if !%s(%s, %s) {", g.unionFunc(y, "Equal"), a, b)
+		case deriveMethods:
+			writeLine(result, indent, "if !%s.Equal(&%s) {", a, b)
+		case derivePrimitive:
+			writeLine(result, indent, "if %s != %s {", a, b)
+		default:
+			g.useShared()
+			writeLine(result, indent, "if !shared.Equal(%s, %s) {", a, b)
+		}
+		writeLine(result, indent+1, "return false")
+		writeLine(result, indent, "}")
+		return
+
+	case *shape.PointerLike:
+		if g.isMethodsRef(y.Type) {
+			writeLine(result, indent, "if !%s.Equal(%s) {", a, b)
+			writeLine(result, indent+1, "return false")
+			writeLine(result, indent, "}")
+			return
+		}
+
+		writeLine(result, indent, "if (%s == nil) != (%s == nil) {", a, b)
+		writeLine(result, indent+1, "return false")
+		writeLine(result, indent, "}")
+		writeLine(result, indent, "if %s != nil {", a)
+		g.equalTo(result, indent+1, y.Type, "*"+a, "*"+b)
+		writeLine(result, indent, "}")
+		return
+
+	case *shape.ListLike:
+		if y.ArrayLen == nil {
+			writeLine(result, indent, "if len(%s) != len(%s) {", a, b)
+			writeLine(result, indent+1, "return false")
+			writeLine(result, indent, "}")
+		}
+		index := fmt.Sprintf("i%d", g.next())
+		writeLine(result, indent, "for %s := range %s {", index, a)
+		g.equalTo(result, indent+1, y.Element, indexOf(a, index), indexOf(b, index))
+		writeLine(result, indent, "}")
+		return
+
+	case *shape.MapLike:
+		n := g.next()
+		key, value, other := fmt.Sprintf("k%d", n), fmt.Sprintf("v%d", n), fmt.Sprintf("w%d", n)
+		writeLine(result, indent, "if len(%s) != len(%s) {", a, b)
+		writeLine(result, indent+1, "return false")
+		writeLine(result, indent, "}")
+		writeLine(result, indent, "for %s, %s := range %s {", key, value, a)
+		writeLine(result, indent+1, "%s, ok := %s", other, indexOf(b, key))
+		writeLine(result, indent+1, "if !ok {")
+		writeLine(result, indent+2, "return false")
+		writeLine(result, indent+1, "}")
+		g.equalTo(result, indent+1, y.Val, value, other)
+		writeLine(result, indent, "}")
+		return
+	}
+
+	g.useShared()
+	writeLine(result, indent, "if !shared.Equal(%s, %s) {", a, b)
+	writeLine(result, indent+1, "return false")
+	writeLine(result, indent, "}")
+}
+
+// hashOf writes statements that add hash of in to variable h.
+func (g *DeriveTagged) hashOf(result *strings.Builder, indent int, x shape.Shape, in, h string) {
+	switch y := x.(type) {
+	case *shape.PrimitiveLike:
+		writeLine(result, indent, "%s = %s", h, g.hashPrimitive(y, in, h, false))
+		return
+
+	case *shape.RefName:
+		switch g.resolve(y) {
+		case deriveUnion:
+			writeLine(result, indent, "%s = shared.HashUint64(%s, %s(%s))", h, h, g.unionFunc(y, "Hash"), in)
+		case deriveMethods:
+			writeLine(result, indent, "%s = shared.HashUint64(%s, %s.Hash())", h, h, in)
+		case derivePrimitive:
+			found, _ := shape.LookupShapeOnDisk(y)
+			primitive := found.(*shape.AliasLike).Type.(*shape.PrimitiveLike)
+			writeLine(result, indent, "%s = %s", h, g.hashPrimitive(primitive, in, h, true))
+		default:
+			writeLine(result, indent, "%s = shared.Hash(%s, %s)", h, h, in)
+		}
+		return
+
+	case *shape.PointerLike:
+		if g.isMethodsRef(y.Type) {
+			writeLine(result, indent, "%s = shared.HashUint64(%s, %s.Hash())", h, h, in)
+			return
+		}
+
+		writeLine(result, indent, "%s = shared.HashBool(%s, %s != nil)", h, h, in)
+		writeLine(result, indent, "if %s != nil {", in)
+		g.hashOf(result, indent+1, y.Type, "*"+in, h)
+		writeLine(result, indent, "}")
+		return
+
+	case *shape.ListLike:
+		if y.ArrayLen == nil {
+			writeLine(result, indent, "%s = shared.HashUint64(%s, uint64(len(%s)))", h, h, in)
+		}
+		value := fmt.Sprintf("v%d", g.next())
+		writeLine(result, indent, "for _, %s := range %s {", value, in)
+		g.hashOf(result, indent+1, y.Element, value, h)
+		writeLine(result, indent, "}")
+		return
+
+	case *shape.MapLike:
+		// entries are summed, so hash doesn't depend on iteration order
+		n := g.next()
+		key, value, sum, entry := fmt.Sprintf("k%d", n), fmt.Sprintf("v%d", n), fmt.Sprintf("s%d", n), fmt.Sprintf("e%d", n)
+		writeLine(result, indent, "%s = shared.HashUint64(%s, uint64(len(%s)))", h, h, in)
+		writeLine(result, indent, "var %s uint64", sum)
+		writeLine(result, indent, "for %s, %s := range %s {", key, value, in)
+		writeLine(result, indent+1, "%s := shared.HashOffset", entry)
+		g.hashOf(result, indent+1, y.Key, key, entry)
+		g.hashOf(result, indent+1, y.Val, value, entry)
+		writeLine(result, indent+1, "%s += %s", sum, entry)
+		writeLine(result, indent, "}")
+		writeLine(result, indent, "%s = shared.HashUint64(%s, %s)", h, h, sum)
+		return
+	}
+
+	writeLine(result, indent, "%s = shared.Hash(%s, %s)", h, h, in)
+}
+
+func (g *DeriveTagged) hashPrimitive(x *shape.PrimitiveLike, in, h string, named bool) string {
+	return shape.MatchPrimitiveKindR1(
+		x.Kind,
+		func(x *shape.BooleanLike) string {
+			if named {
+				in = fmt.Sprintf("bool(%s)", in)
+			}
+			return fmt.Sprintf("shared.HashBool(%s, %s)", h, in)
+		},
+		func(x *shape.StringLike) string {
+			if named {
+				in = fmt.Sprintf("string(%s)", in)
+			}
+			return fmt.Sprintf("shared.HashString(%s, %s)", h, in)
+		},
+		func(x *shape.NumberLike) string {
+			name := shape.NumberKindToGoName(x.Kind)
+			if strings.HasPrefix(name, "float") {
+				if name != "float64" || named {
+					in = fmt.Sprintf("float64(%s)", in)
+				}
+				return fmt.Sprintf("shared.HashFloat64(%s, %s)", h, in)
+			}
+
+			if name != "uint64" || named {
+				in = fmt.Sprintf("uint64(%s)", in)
+			}
+			return fmt.Sprintf("shared.HashUint64(%s, %s)", h, in)
+		},
+	)
+}
+
+// copyTo writes statements that assign to out deep copy of in.
+func (g *DeriveTagged) copyTo(result *strings.Builder, indent int, x shape.Shape, in, out string) {
+	if expr, ok := g.copyExpr(x, in); ok {
+		writeLine(result, indent, "%s = %s", out, expr)
+		return
+	}
+
+	switch y := x.(type) {
+	case *shape.PointerLike:
+		name := fmt.Sprintf("r%d", g.next())
+		writeLine(result, indent, "if %s != nil {", in)
+		writeLine(result, indent+1, "%s := *%s", name, in)
+		if g.needsCopy(y.Type) {
+			g.copyTo(result, indent+1, y.Type, "*"+in, name)
+		}
+		writeLine(result, indent+1, "%s = &%s", out, name)
+		writeLine(result, indent, "}")
+
+	case *shape.ListLike:
+		index := fmt.Sprintf("i%d", g.next())
+		if y.ArrayLen != nil {
+			writeLine(result, indent, "%s = %s", out, in)
+			writeLine(result, indent, "for %s := range %s {", index, in)
+			g.copyTo(result, indent+1, y.Element, indexOf(in, index), indexOf(out, index))
+			writeLine(result, indent, "}")
+			return
+		}
+
+		writeLine(result, indent, "if %s != nil {", in)
+		writeLine(result, indent+1, "%s = make(%s, len(%s))", out, g.typeName(y), in)
+		if g.needsCopy(y.Element) {
+			writeLine(result, indent+1, "for %s := range %s {", index, in)
+			g.copyTo(result, indent+2, y.Element, indexOf(in, index), indexOf(out, index))
+			writeLine(result, indent+1, "}")
+		} else {
+			writeLine(result, indent+1, "copy(%s, %s)", out, in)
+		}
+		writeLine(result, indent, "}")
+
+	case *shape.MapLike:
+		n := g.next()
+		key, value := fmt.Sprintf("k%d", n), fmt.Sprintf("v%d", n)
+		writeLine(result, indent, "if %s != nil {", in)
+		writeLine(result, indent+1, "%s = make(%s, len(%s))", out, g.typeName(y), in)
+		writeLine(result, indent+1, "for %s, %s := range %s {", key, value, in)
+		if expr, ok := g.copyExpr(y.Val, value); ok {
+			writeLine(result, indent+2, "%s[%s] = %s", out, key, expr)
+		} else {
+			name := fmt.Sprintf("r%d", n)
+			writeLine(result, indent+2, "%s := %s", name, value)
+			g.copyTo(result, indent+2, y.Val, value, name)
+			writeLine(result, indent+2, "%s[%s] = %s", out, key, name)
+		}
+		writeLine(result, indent+1, "}")
+		writeLine(result, indent, "}")
+	}
+}
+
+// copyExpr returns expression that deep copies in, when copying doesn't need statements.
+func (g *DeriveTagged) copyExpr(x shape.Shape, in string) (string, bool) {
+	if !g.needsCopy(x) {
+		return in, true
+	}
+
+	switch y := x.(type) {
+	case *shape.RefName:
+		switch g.resolve(y) {
+		case deriveUnion:
+			return fmt.Sprintf("%s(%s)", g.unionFunc(y, "DeepCopy"), in), true
+		case deriveMethods:
+			return fmt.Sprintf("*%s.DeepCopy()", in), true
+		}
+
+	case *shape.PointerLike:
+		if g.isMethodsRef(y.Type) {
+			return fmt.Sprintf("%s.DeepCopy()", in), true
+		}
+		return "", false
+
+	case *shape.ListLike, *shape.MapLike:
+		return "", false
+	}
+
+	g.useShared()
+	return fmt.Sprintf("shared.DeepCopy(%s)", in), true
+}
+
+// needsCopy returns true, when assignment of x would share memory with original value.
+func (g *DeriveTagged) needsCopy(x shape.Shape) bool {
+	switch y := x.(type) {
+	case *shape.PrimitiveLike:
+		return false
+	case *shape.RefName:
+		return g.resolve(y) != derivePrimitive
+	case *shape.ListLike:
+		if y.ArrayLen != nil {
+			return g.needsCopy(y.Element)
+		}
+	}
+
+	return true
+}
+
+func (g *DeriveTagged) isMethodsRef(x shape.Shape) bool {
+	ref, ok := x.(*shape.RefName)
+	return ok && g.resolve(ref) == deriveMethods
+}
+
+// unionFunc returns name of function generated for union, like TreeEqual, or schema.SchemaEqual.
+func (g *DeriveTagged) unionFunc(x *shape.RefName, suffix string) string {
+	if x.PkgImportName == shape.ToGoPkgImportName(g.shape) {
+		return x.Name + suffix
+	}
+
+	g.imports[x.PkgName] = x.PkgImportName
+	return fmt.Sprintf("%s.%s%s", x.PkgName, x.Name, suffix)
+}
+
+// hashName returns name, that distinguishes hashes of different types with the same values.
+func (g *DeriveTagged) hashName() string {
+	return fmt.Sprintf("%s.%s", shape.ToGoPkgName(g.shape), shape.Name(g.shape))
+}
+
+// selfTypeName returns name of type, that methods are generated for.
+// It doesn't track imports, since struct shape would bring imports of its fields.
+func (g *DeriveTagged) selfTypeName() string {
+	return shape.ToGoTypeName(g.shape, shape.WithRootPkgName(shape.ToGoPkgName(g.shape)))
+}
+
+func (g *DeriveTagged) typeName(x shape.Shape) string {
+	for pkgName, pkgImportName := range shape.ExtractPkgImportNames(x) {
+		g.imports[pkgName] = pkgImportName
+	}
+
+	return shape.ToGoTypeName(x, shape.WithRootPkgName(shape.ToGoPkgName(g.shape)))
+}
+
+func (g *DeriveTagged) useShared() {
+	g.imports["shared"] = "github.com/widmogrod/mkunion/x/shared"
+}
+
+func (g *DeriveTagged) next() int {
+	g.counter++
+	return g.counter
+}
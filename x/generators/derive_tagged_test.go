@@ -0,0 +1,126 @@
+package generators
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+)
+
+func TestNewDeriveTagged_ListOf2(t *testing.T) {
+	inferred, err := shape.InferFromFile("testutils/tree.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generator := NewDeriveTagged(
+		inferred.RetrieveShapeNamedAs("ListOf2"),
+	)
+
+	result, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, `package testutils
+
+import (
+	"github.com/widmogrod/mkunion/x/schema"
+	"github.com/widmogrod/mkunion/x/shared"
+)
+
+func (r *ListOf2[T1,T2]) Equal(other *ListOf2[T1,T2]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.ID != other.ID {
+		return false
+	}
+	if !shared.Equal(r.Data, other.Data) {
+		return false
+	}
+	if len(r.List) != len(other.List) {
+		return false
+	}
+	for i1 := range r.List {
+		if !shared.Equal(r.List[i1], other.List[i1]) {
+			return false
+		}
+	}
+	if len(r.Map) != len(other.Map) {
+		return false
+	}
+	for k2, v2 := range r.Map {
+		w2, ok := other.Map[k2]
+		if !ok {
+			return false
+		}
+		if !shared.Equal(v2, w2) {
+			return false
+		}
+	}
+	if !r.ListOf.Equal(&other.ListOf) {
+		return false
+	}
+	if !r.ListOfPtr.Equal(other.ListOfPtr) {
+		return false
+	}
+	if !shared.Equal(r.Time, other.Time) {
+		return false
+	}
+	if !schema.SchemaEqual(r.Value, other.Value) {
+		return false
+	}
+	return true
+}
+
+func (r *ListOf2[T1,T2]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "testutils.ListOf2")
+	h = shared.HashString(h, r.ID)
+	h = shared.Hash(h, r.Data)
+	h = shared.HashUint64(h, uint64(len(r.List)))
+	for _, v1 := range r.List {
+		h = shared.Hash(h, v1)
+	}
+	h = shared.HashUint64(h, uint64(len(r.Map)))
+	var s2 uint64
+	for k2, v2 := range r.Map {
+		e2 := shared.HashOffset
+		e2 = shared.Hash(e2, k2)
+		e2 = shared.Hash(e2, v2)
+		s2 += e2
+	}
+	h = shared.HashUint64(h, s2)
+	h = shared.HashUint64(h, r.ListOf.Hash())
+	h = shared.HashUint64(h, r.ListOfPtr.Hash())
+	h = shared.Hash(h, r.Time)
+	h = shared.HashUint64(h, schema.SchemaHash(r.Value))
+	return h
+}
+
+func (r *ListOf2[T1,T2]) DeepCopy() *ListOf2[T1,T2] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Data = shared.DeepCopy(r.Data)
+	if r.List != nil {
+		result.List = make([]T2, len(r.List))
+		for i1 := range r.List {
+			result.List[i1] = shared.DeepCopy(r.List[i1])
+		}
+	}
+	if r.Map != nil {
+		result.Map = make(map[T1]T2, len(r.Map))
+		for k2, v2 := range r.Map {
+			result.Map[k2] = shared.DeepCopy(v2)
+		}
+	}
+	result.ListOf = *r.ListOf.DeepCopy()
+	result.ListOfPtr = r.ListOfPtr.DeepCopy()
+	result.Time = shared.DeepCopy(r.Time)
+	result.Value = schema.SchemaDeepCopy(r.Value)
+	return &result
+}
+
+`, result)
+}
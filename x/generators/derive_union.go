@@ -0,0 +1,155 @@
+package generators
+
+import (
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"strings"
+)
+
+// NewDeriveUnion generates <Union>Equal, <Union>Hash and <Union>DeepCopy functions for union,
+// and Equal, Hash and DeepCopy methods for each of its variants.
+func NewDeriveUnion(union *shape.UnionLike) *DeriveUnion {
+	return &DeriveUnion{
+		union:                 union,
+		skipImportsAndPackage: false,
+		imports: PkgMap{
+			"shared": "github.com/widmogrod/mkunion/x/shared",
+		},
+	}
+}
+
+type DeriveUnion struct {
+	union                 *shape.UnionLike
+	skipImportsAndPackage bool
+	imports               PkgMap
+}
+
+func (g *DeriveUnion) SkipImportsAndPackage(flag bool) *DeriveUnion {
+	g.skipImportsAndPackage = flag
+	return g
+}
+
+func (g *DeriveUnion) Generate() (string, error) {
+	body, err := g.GenerateDerive()
+	if err != nil {
+		return "", fmt.Errorf("generators.DeriveUnion.Generate: %w", err)
+	}
+
+	if g.skipImportsAndPackage {
+		return body, nil
+	}
+
+	head := &strings.Builder{}
+	head.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.union)))
+	head.WriteString(GenerateImports(g.ExtractImports()))
+	head.WriteString(body)
+	return head.String(), nil
+}
+
+// ExtractImports returns packages used by generated code, so it must be called after Generate.
+func (g *DeriveUnion) ExtractImports() PkgMap {
+	result := make(PkgMap)
+	for pkgName, pkgImportName := range g.imports {
+		if pkgImportName == g.union.PkgImportName {
+			continue
+		}
+		result[pkgName] = pkgImportName
+	}
+
+	return result
+}
+
+func (g *DeriveUnion) GenerateDerive() (string, error) {
+	result := &strings.Builder{}
+	g.generateEqual(result)
+	g.generateHash(result)
+	g.generateDeepCopy(result)
+
+	for _, variant := range g.union.Variant {
+		generator := NewDeriveTagged(variant).SkipImportsAndPackage(true)
+		body, err := generator.Generate()
+		if err != nil {
+			return "", fmt.Errorf("generators.DeriveUnion.GenerateDerive: variant %s; %w", shape.Name(variant), err)
+		}
+
+		g.imports = MergePkgMaps(g.imports, generator.ExtractImports())
+		result.WriteString(body)
+	}
+
+	return result.String(), nil
+}
+
+func (g *DeriveUnion) generateEqual(result *strings.Builder) {
+	name := g.union.Name
+
+	result.WriteString(fmt.Sprintf("// %sEqual returns true, when a and b are the same variant of %s with equal fields.\n", name, name))
+	result.WriteString(fmt.Sprintf("func %sEqual%s(a, b %s) bool {\n", name, g.typeParamsDecl(), g.unionType()))
+	result.WriteString(fmt.Sprintf("\tswitch x := a.(type) {\n"))
+	for _, variant := range g.union.Variant {
+		result.WriteString(fmt.Sprintf("\tcase *%s:\n", g.variantType(variant)))
+		result.WriteString(fmt.Sprintf("\t\ty, ok := b.(*%s)\n", g.variantType(variant)))
+		result.WriteString(fmt.Sprintf("\t\treturn ok && x.Equal(y)\n"))
+	}
+	result.WriteString(fmt.Sprintf("\t}\n\n"))
+	result.WriteString(fmt.Sprintf("\treturn a == nil && b == nil\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+}
+
+func (g *DeriveUnion) generateHash(result *strings.Builder) {
+	name := g.union.Name
+
+	result.WriteString(fmt.Sprintf("// %sHash returns hash of x, that is the same for values equal according to %sEqual.\n", name, name))
+	result.WriteString(fmt.Sprintf("func %sHash%s(x %s) uint64 {\n", name, g.typeParamsDecl(), g.unionType()))
+	result.WriteString(fmt.Sprintf("\tswitch y := x.(type) {\n"))
+	for _, variant := range g.union.Variant {
+		result.WriteString(fmt.Sprintf("\tcase *%s:\n", g.variantType(variant)))
+		result.WriteString(fmt.Sprintf("\t\treturn y.Hash()\n"))
+	}
+	result.WriteString(fmt.Sprintf("\t}\n\n"))
+	result.WriteString(fmt.Sprintf("\treturn 0\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+}
+
+func (g *DeriveUnion) generateDeepCopy(result *strings.Builder) {
+	name := g.union.Name
+
+	result.WriteString(fmt.Sprintf("// %sDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.\n", name))
+	result.WriteString(fmt.Sprintf("func %sDeepCopy%s(x %s) %s {\n", name, g.typeParamsDecl(), g.unionType(), g.unionType()))
+	result.WriteString(fmt.Sprintf("\tswitch y := x.(type) {\n"))
+	for _, variant := range g.union.Variant {
+		result.WriteString(fmt.Sprintf("\tcase *%s:\n", g.variantType(variant)))
+		result.WriteString(fmt.Sprintf("\t\treturn y.DeepCopy()\n"))
+	}
+	result.WriteString(fmt.Sprintf("\t}\n\n"))
+	result.WriteString(fmt.Sprintf("\treturn nil\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+}
+
+func (g *DeriveUnion) typeParamsDecl() string {
+	if len(g.union.TypeParams) == 0 {
+		return ""
+	}
+
+	var result []string
+	for _, param := range g.union.TypeParams {
+		result = append(result, fmt.Sprintf("%s %s", param.Name, g.typeName(param.Type)))
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(result, ", "))
+}
+
+func (g *DeriveUnion) unionType() string {
+	return shape.ToGoTypeName(g.union, shape.WithRootPkgName(shape.ToGoPkgName(g.union)))
+}
+
+func (g *DeriveUnion) variantType(x shape.Shape) string {
+	return shape.ToGoTypeName(x, shape.WithRootPkgName(shape.ToGoPkgName(g.union)))
+}
+
+func (g *DeriveUnion) typeName(x shape.Shape) string {
+	for pkgName, pkgImportName := range shape.ExtractPkgImportNames(x) {
+		g.imports[pkgName] = pkgImportName
+	}
+
+	return shape.ToGoTypeName(x, shape.WithRootPkgName(shape.ToGoPkgName(g.union)))
+}
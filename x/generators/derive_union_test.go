@@ -0,0 +1,434 @@
+package generators
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+)
+
+func TestNewDeriveUnion(t *testing.T) {
+	inferred, err := shape.InferFromFile("testutils/tree.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generator := NewDeriveUnion(inferred.RetrieveUnion("Tree"))
+
+	result, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, `package testutils
+
+import (
+	"github.com/widmogrod/mkunion/x/shared"
+	"time"
+)
+
+// TreeEqual returns true, when a and b are the same variant of Tree with equal fields.
+func TreeEqual(a, b Tree) bool {
+	switch x := a.(type) {
+	case *Branch:
+		y, ok := b.(*Branch)
+		return ok && x.Equal(y)
+	case *Leaf:
+		y, ok := b.(*Leaf)
+		return ok && x.Equal(y)
+	case *K:
+		y, ok := b.(*K)
+		return ok && x.Equal(y)
+	case *P:
+		y, ok := b.(*P)
+		return ok && x.Equal(y)
+	case *Ma:
+		y, ok := b.(*Ma)
+		return ok && x.Equal(y)
+	case *La:
+		y, ok := b.(*La)
+		return ok && x.Equal(y)
+	case *Ka:
+		y, ok := b.(*Ka)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// TreeHash returns hash of x, that is the same for values equal according to TreeEqual.
+func TreeHash(x Tree) uint64 {
+	switch y := x.(type) {
+	case *Branch:
+		return y.Hash()
+	case *Leaf:
+		return y.Hash()
+	case *K:
+		return y.Hash()
+	case *P:
+		return y.Hash()
+	case *Ma:
+		return y.Hash()
+	case *La:
+		return y.Hash()
+	case *Ka:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// TreeDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func TreeDeepCopy(x Tree) Tree {
+	switch y := x.(type) {
+	case *Branch:
+		return y.DeepCopy()
+	case *Leaf:
+		return y.DeepCopy()
+	case *K:
+		return y.DeepCopy()
+	case *P:
+		return y.DeepCopy()
+	case *Ma:
+		return y.DeepCopy()
+	case *La:
+		return y.DeepCopy()
+	case *Ka:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Branch) Equal(other *Branch) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !TreeEqual(r.Lit, other.Lit) {
+		return false
+	}
+	if len(r.List) != len(other.List) {
+		return false
+	}
+	for i1 := range r.List {
+		if !TreeEqual(r.List[i1], other.List[i1]) {
+			return false
+		}
+	}
+	if len(r.Map) != len(other.Map) {
+		return false
+	}
+	for k2, v2 := range r.Map {
+		w2, ok := other.Map[k2]
+		if !ok {
+			return false
+		}
+		if !TreeEqual(v2, w2) {
+			return false
+		}
+	}
+	if !r.Of.Equal(other.Of) {
+		return false
+	}
+	if !r.L.Equal(other.L) {
+		return false
+	}
+	for i3 := range r.Kattr {
+		if !r.Kattr[i3].Equal(other.Kattr[i3]) {
+			return false
+		}
+	}
+	if (r.IntPtr == nil) != (other.IntPtr == nil) {
+		return false
+	}
+	if r.IntPtr != nil {
+		if *r.IntPtr != *other.IntPtr {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Branch) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "testutils.Branch")
+	h = shared.HashUint64(h, TreeHash(r.Lit))
+	h = shared.HashUint64(h, uint64(len(r.List)))
+	for _, v1 := range r.List {
+		h = shared.HashUint64(h, TreeHash(v1))
+	}
+	h = shared.HashUint64(h, uint64(len(r.Map)))
+	var s2 uint64
+	for k2, v2 := range r.Map {
+		e2 := shared.HashOffset
+		e2 = shared.HashString(e2, k2)
+		e2 = shared.HashUint64(e2, TreeHash(v2))
+		s2 += e2
+	}
+	h = shared.HashUint64(h, s2)
+	h = shared.HashUint64(h, r.Of.Hash())
+	h = shared.HashUint64(h, r.L.Hash())
+	for _, v3 := range r.Kattr {
+		h = shared.HashUint64(h, v3.Hash())
+	}
+	h = shared.HashBool(h, r.IntPtr != nil)
+	if r.IntPtr != nil {
+		h = shared.HashUint64(h, uint64(*r.IntPtr))
+	}
+	return h
+}
+
+func (r *Branch) DeepCopy() *Branch {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Lit = TreeDeepCopy(r.Lit)
+	if r.List != nil {
+		result.List = make([]Tree, len(r.List))
+		for i1 := range r.List {
+			result.List[i1] = TreeDeepCopy(r.List[i1])
+		}
+	}
+	if r.Map != nil {
+		result.Map = make(map[string]Tree, len(r.Map))
+		for k2, v2 := range r.Map {
+			result.Map[k2] = TreeDeepCopy(v2)
+		}
+	}
+	result.Of = r.Of.DeepCopy()
+	result.L = r.L.DeepCopy()
+	result.Kattr = r.Kattr
+	for i3 := range r.Kattr {
+		result.Kattr[i3] = r.Kattr[i3].DeepCopy()
+	}
+	if r.IntPtr != nil {
+		r4 := *r.IntPtr
+		result.IntPtr = &r4
+	}
+	return &result
+}
+
+func (r *Leaf) Equal(other *Leaf) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Value != other.Value {
+		return false
+	}
+	return true
+}
+
+func (r *Leaf) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "testutils.Leaf")
+	h = shared.HashUint64(h, uint64(r.Value))
+	return h
+}
+
+func (r *Leaf) DeepCopy() *Leaf {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *K) Equal(other *K) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if *r != *other {
+		return false
+	}
+	return true
+}
+
+func (r *K) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "testutils.K")
+	h = shared.HashString(h, string(*r))
+	return h
+}
+
+func (r *K) DeepCopy() *K {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *P) Equal(other *P) bool {
+	return (*ListOf2[ListOf[any],*ListOf2[int64,*time.Duration]])(r).Equal((*ListOf2[ListOf[any],*ListOf2[int64,*time.Duration]])(other))
+}
+
+func (r *P) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	return shared.HashUint64(shared.HashString(shared.HashOffset, "testutils.P"), (*ListOf2[ListOf[any],*ListOf2[int64,*time.Duration]])(r).Hash())
+}
+
+func (r *P) DeepCopy() *P {
+	return (*P)((*ListOf2[ListOf[any],*ListOf2[int64,*time.Duration]])(r).DeepCopy())
+}
+
+func (r *Ma) Equal(other *Ma) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(*r) != len(*other) {
+		return false
+	}
+	for k1, v1 := range *r {
+		w1, ok := (*other)[k1]
+		if !ok {
+			return false
+		}
+		if !TreeEqual(v1, w1) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Ma) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "testutils.Ma")
+	h = shared.HashUint64(h, uint64(len(*r)))
+	var s1 uint64
+	for k1, v1 := range *r {
+		e1 := shared.HashOffset
+		e1 = shared.HashString(e1, k1)
+		e1 = shared.HashUint64(e1, TreeHash(v1))
+		s1 += e1
+	}
+	h = shared.HashUint64(h, s1)
+	return h
+}
+
+func (r *Ma) DeepCopy() *Ma {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if *r != nil {
+		result = make(map[string]Tree, len(*r))
+		for k1, v1 := range *r {
+			result[k1] = TreeDeepCopy(v1)
+		}
+	}
+	return &result
+}
+
+func (r *La) Equal(other *La) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(*r) != len(*other) {
+		return false
+	}
+	for i1 := range *r {
+		if !TreeEqual((*r)[i1], (*other)[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *La) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "testutils.La")
+	h = shared.HashUint64(h, uint64(len(*r)))
+	for _, v1 := range *r {
+		h = shared.HashUint64(h, TreeHash(v1))
+	}
+	return h
+}
+
+func (r *La) DeepCopy() *La {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if *r != nil {
+		result = make([]Tree, len(*r))
+		for i1 := range *r {
+			result[i1] = TreeDeepCopy((*r)[i1])
+		}
+	}
+	return &result
+}
+
+func (r *Ka) Equal(other *Ka) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(*r) != len(*other) {
+		return false
+	}
+	for i1 := range *r {
+		if len((*r)[i1]) != len((*other)[i1]) {
+			return false
+		}
+		for k2, v2 := range (*r)[i1] {
+			w2, ok := (*other)[i1][k2]
+			if !ok {
+				return false
+			}
+			if !TreeEqual(v2, w2) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *Ka) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "testutils.Ka")
+	h = shared.HashUint64(h, uint64(len(*r)))
+	for _, v1 := range *r {
+		h = shared.HashUint64(h, uint64(len(v1)))
+		var s2 uint64
+		for k2, v2 := range v1 {
+			e2 := shared.HashOffset
+			e2 = shared.HashString(e2, k2)
+			e2 = shared.HashUint64(e2, TreeHash(v2))
+			s2 += e2
+		}
+		h = shared.HashUint64(h, s2)
+	}
+	return h
+}
+
+func (r *Ka) DeepCopy() *Ka {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if *r != nil {
+		result = make([]map[string]Tree, len(*r))
+		for i1 := range *r {
+			if (*r)[i1] != nil {
+				result[i1] = make(map[string]Tree, len((*r)[i1]))
+				for k2, v2 := range (*r)[i1] {
+					result[i1][k2] = TreeDeepCopy(v2)
+				}
+			}
+		}
+	}
+	return &result
+}
+
+`, result)
+}
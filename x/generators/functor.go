@@ -0,0 +1,537 @@
+package generators
+
+import (
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"strings"
+)
+
+// NewFunctorUnion generates Map, Fold and Traverse functions
+// for generic union tagged with //go:tag mkunion:"Tree[A],derive=functor".
+// Functions work over the last type parameter of union, and descend into fields that refer back to union,
+// also through pointers, lists and maps.
+func NewFunctorUnion(union *shape.UnionLike) *FunctorUnion {
+	return &FunctorUnion{
+		union:                 union,
+		skipImportsAndPackage: false,
+		imports:               make(PkgMap),
+	}
+}
+
+type FunctorUnion struct {
+	union                 *shape.UnionLike
+	skipImportsAndPackage bool
+	imports               PkgMap
+	counter               int
+}
+
+func (g *FunctorUnion) SkipImportsAndPackage(flag bool) *FunctorUnion {
+	g.skipImportsAndPackage = flag
+	return g
+}
+
+func (g *FunctorUnion) Generate() (string, error) {
+	body, err := g.GenerateFunctor()
+	if err != nil {
+		return "", fmt.Errorf("generators.FunctorUnion.Generate: %w", err)
+	}
+
+	if g.skipImportsAndPackage {
+		return body, nil
+	}
+
+	head := &strings.Builder{}
+	head.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.union)))
+	head.WriteString(GenerateImports(g.ExtractImports()))
+	head.WriteString(body)
+	return head.String(), nil
+}
+
+// ExtractImports returns packages used by generated code, so it must be called after Generate.
+func (g *FunctorUnion) ExtractImports() PkgMap {
+	result := make(PkgMap)
+	for pkgName, pkgImportName := range g.imports {
+		if pkgImportName == g.union.PkgImportName {
+			continue
+		}
+		result[pkgName] = pkgImportName
+	}
+
+	return result
+}
+
+func (g *FunctorUnion) GenerateFunctor() (string, error) {
+	if len(g.union.TypeParams) == 0 {
+		return "", fmt.Errorf("generators.FunctorUnion.GenerateFunctor: union %s must have type parameter to derive functor", g.union.Name)
+	}
+
+	for _, variant := range g.union.Variant {
+		x, ok := variant.(*shape.StructLike)
+		if !ok {
+			return "", fmt.Errorf("generators.FunctorUnion.GenerateFunctor: variant %s of union %s must be a struct to derive functor", shape.Name(variant), g.union.Name)
+		}
+
+		for _, field := range x.Fields {
+			if !g.canMap(field.Type) {
+				return "", fmt.Errorf("generators.FunctorUnion.GenerateFunctor: field %s.%s of type %s cannot be mapped over %s",
+					x.Name, field.Name, g.typeName(field.Type), g.param())
+			}
+		}
+	}
+
+	result := &strings.Builder{}
+	g.generateMap(result)
+	g.generateTraverse(result)
+	g.generateFold(result)
+
+	return result.String(), nil
+}
+
+func (g *FunctorUnion) generateMap(result *strings.Builder) {
+	name := g.union.Name
+	out := g.outParam()
+
+	result.WriteString(fmt.Sprintf("// Map%s applies f to every value of type %s in %s, and returns %s of the same structure.\n", name, g.param(), name, name))
+	result.WriteString(fmt.Sprintf("func Map%s[%s, %s %s](x %s, f func(%s) %s) %s {\n",
+		name, g.typeParamsDecl(), out, g.paramConstraint(), g.unionType(false), g.param(), out, g.unionType(true)))
+	result.WriteString(fmt.Sprintf("\treturn Match%sR1(\n", name))
+	result.WriteString(fmt.Sprintf("\t\tx,\n"))
+	for _, variant := range g.union.Variant {
+		g.counter = 0
+		result.WriteString(fmt.Sprintf("\t\tfunc(x *%s) %s {\n", g.variantType(variant, false), g.unionType(true)))
+		result.WriteString(fmt.Sprintf("\t\t\tresult := &%s{}\n", g.variantType(variant, true)))
+		for _, field := range variant.(*shape.StructLike).Fields {
+			g.mapTo(result, 3, field.Type, "x."+field.Name, "result."+field.Name, false)
+		}
+		result.WriteString(fmt.Sprintf("\t\t\treturn result\n"))
+		result.WriteString(fmt.Sprintf("\t\t},\n"))
+	}
+	result.WriteString(fmt.Sprintf("\t)\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+}
+
+func (g *FunctorUnion) generateTraverse(result *strings.Builder) {
+	name := g.union.Name
+	out := g.outParam()
+
+	result.WriteString(fmt.Sprintf("// Traverse%s works like Map%s, but stops on the first error returned by f.\n", name, name))
+	result.WriteString(fmt.Sprintf("func Traverse%s[%s, %s %s](x %s, f func(%s) (%s, error)) (%s, error) {\n",
+		name, g.typeParamsDecl(), out, g.paramConstraint(), g.unionType(false), g.param(), out, g.unionType(true)))
+	result.WriteString(fmt.Sprintf("\treturn Match%sR2(\n", name))
+	result.WriteString(fmt.Sprintf("\t\tx,\n"))
+	for _, variant := range g.union.Variant {
+		g.counter = 0
+		result.WriteString(fmt.Sprintf("\t\tfunc(x *%s) (%s, error) {\n", g.variantType(variant, false), g.unionType(true)))
+		result.WriteString(fmt.Sprintf("\t\t\tresult := &%s{}\n", g.variantType(variant, true)))
+		for _, field := range variant.(*shape.StructLike).Fields {
+			g.mapTo(result, 3, field.Type, "x."+field.Name, "result."+field.Name, true)
+		}
+		result.WriteString(fmt.Sprintf("\t\t\treturn result, nil\n"))
+		result.WriteString(fmt.Sprintf("\t\t},\n"))
+	}
+	result.WriteString(fmt.Sprintf("\t)\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+}
+
+func (g *FunctorUnion) generateFold(result *strings.Builder) {
+	name := g.union.Name
+	out := g.outParam()
+
+	result.WriteString(fmt.Sprintf("// Fold%s reduces every value of type %s in %s with f, in order of fields, starting with init.\n", name, g.param(), name))
+	result.WriteString(fmt.Sprintf("// Values in maps are visited in unspecified order.\n"))
+	result.WriteString(fmt.Sprintf("func Fold%s[%s, %s any](x %s, f func(%s, %s) %s, init %s) %s {\n",
+		name, g.typeParamsDecl(), out, g.unionType(false), g.param(), out, out, out, out))
+	result.WriteString(fmt.Sprintf("\tif x == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn init\n"))
+	result.WriteString(fmt.Sprintf("\t}\n\n"))
+	result.WriteString(fmt.Sprintf("\treturn Match%sR1(\n", name))
+	result.WriteString(fmt.Sprintf("\t\tx,\n"))
+	for _, variant := range g.union.Variant {
+		g.counter = 0
+		body := &strings.Builder{}
+		for _, field := range variant.(*shape.StructLike).Fields {
+			g.foldOver(body, 3, field.Type, "x."+field.Name)
+		}
+
+		result.WriteString(fmt.Sprintf("\t\tfunc(x *%s) %s {\n", g.variantType(variant, false), out))
+		if body.Len() == 0 {
+			result.WriteString(fmt.Sprintf("\t\t\treturn init\n"))
+		} else {
+			result.WriteString(fmt.Sprintf("\t\t\tresult := init\n"))
+			result.WriteString(body.String())
+			result.WriteString(fmt.Sprintf("\t\t\treturn result\n"))
+		}
+		result.WriteString(fmt.Sprintf("\t\t},\n"))
+	}
+	result.WriteString(fmt.Sprintf("\t)\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+}
+
+// mapTo writes statements that assign to out value of in, mapped with f.
+// When traverse is true, f returns error, that is returned from enclosing function.
+func (g *FunctorUnion) mapTo(result *strings.Builder, indent int, x shape.Shape, in, out string, traverse bool) {
+	if expr, ok := g.mapExpr(x, in, traverse); ok {
+		writeLine(result, indent, "%s = %s", out, expr)
+		return
+	}
+
+	switch y := x.(type) {
+	case *shape.RefName:
+		// only type parameter and union reach here, when traverse is true
+		name := fmt.Sprintf("r%d", g.next())
+		if g.isParam(y) {
+			writeLine(result, indent, "%s, err := f(%s)", name, in)
+		} else {
+			writeLine(result, indent, "%s, err := Traverse%s(%s, f)", name, g.union.Name, in)
+		}
+		writeLine(result, indent, "if err != nil {")
+		writeLine(result, indent+1, "return nil, err")
+		writeLine(result, indent, "}")
+		writeLine(result, indent, "%s = %s", out, name)
+
+	case *shape.PointerLike:
+		name := fmt.Sprintf("r%d", g.next())
+		writeLine(result, indent, "if %s != nil {", in)
+		if expr, ok := g.mapExpr(y.Type, "*"+in, traverse); ok {
+			writeLine(result, indent+1, "%s := %s", name, expr)
+		} else {
+			writeLine(result, indent+1, "var %s %s", name, g.outTypeName(y.Type))
+			g.mapTo(result, indent+1, y.Type, "*"+in, name, traverse)
+		}
+		writeLine(result, indent+1, "%s = &%s", out, name)
+		writeLine(result, indent, "}")
+
+	case *shape.ListLike:
+		index := fmt.Sprintf("i%d", g.next())
+		if y.ArrayLen != nil {
+			writeLine(result, indent, "for %s := range %s {", index, in)
+			g.mapTo(result, indent+1, y.Element, indexOf(in, index), indexOf(out, index), traverse)
+			writeLine(result, indent, "}")
+			return
+		}
+
+		writeLine(result, indent, "if %s != nil {", in)
+		writeLine(result, indent+1, "%s = make(%s, len(%s))", out, g.outTypeName(y), in)
+		writeLine(result, indent+1, "for %s := range %s {", index, in)
+		g.mapTo(result, indent+2, y.Element, indexOf(in, index), indexOf(out, index), traverse)
+		writeLine(result, indent+1, "}")
+		writeLine(result, indent, "}")
+
+	case *shape.MapLike:
+		n := g.next()
+		key, value := fmt.Sprintf("k%d", n), fmt.Sprintf("v%d", n)
+		writeLine(result, indent, "if %s != nil {", in)
+		writeLine(result, indent+1, "%s = make(%s, len(%s))", out, g.outTypeName(y), in)
+		writeLine(result, indent+1, "for %s, %s := range %s {", key, value, in)
+		if expr, ok := g.mapExpr(y.Val, value, traverse); ok {
+			writeLine(result, indent+2, "%s[%s] = %s", out, key, expr)
+		} else {
+			name := fmt.Sprintf("r%d", n)
+			writeLine(result, indent+2, "var %s %s", name, g.outTypeName(y.Val))
+			g.mapTo(result, indent+2, y.Val, value, name, traverse)
+			writeLine(result, indent+2, "%s[%s] = %s", out, key, name)
+		}
+		writeLine(result, indent+1, "}")
+		writeLine(result, indent, "}")
+	}
+}
+
+// mapExpr returns expression that maps in, when mapping doesn't need statements.
+func (g *FunctorUnion) mapExpr(x shape.Shape, in string, traverse bool) (string, bool) {
+	switch y := x.(type) {
+	case *shape.RefName:
+		if g.isParam(y) {
+			if traverse {
+				return "", false
+			}
+			return fmt.Sprintf("f(%s)", in), true
+		}
+
+		if g.isUnion(y) {
+			if traverse {
+				return "", false
+			}
+			return fmt.Sprintf("Map%s(%s, f)", g.union.Name, in), true
+		}
+
+	case *shape.PointerLike, *shape.ListLike, *shape.MapLike:
+		return "", false
+	}
+
+	return in, true
+}
+
+// foldOver writes statements that reduce values of in into result.
+func (g *FunctorUnion) foldOver(result *strings.Builder, indent int, x shape.Shape, in string) {
+	if !g.hasParamOrUnion(x) {
+		return
+	}
+
+	switch y := x.(type) {
+	case *shape.RefName:
+		if g.isParam(y) {
+			writeLine(result, indent, "result = f(%s, result)", in)
+		} else {
+			writeLine(result, indent, "result = Fold%s(%s, f, result)", g.union.Name, in)
+		}
+
+	case *shape.PointerLike:
+		writeLine(result, indent, "if %s != nil {", in)
+		g.foldOver(result, indent+1, y.Type, "*"+in)
+		writeLine(result, indent, "}")
+
+	case *shape.ListLike:
+		value := fmt.Sprintf("v%d", g.next())
+		writeLine(result, indent, "for _, %s := range %s {", value, in)
+		g.foldOver(result, indent+1, y.Element, value)
+		writeLine(result, indent, "}")
+
+	case *shape.MapLike:
+		value := fmt.Sprintf("v%d", g.next())
+		writeLine(result, indent, "for _, %s := range %s {", value, in)
+		g.foldOver(result, indent+1, y.Val, value)
+		writeLine(result, indent, "}")
+	}
+}
+
+// canMap returns true, when values of mapped type parameter in x can be reached,
+// or when x doesn't refer to mapped type parameter at all.
+func (g *FunctorUnion) canMap(x shape.Shape) bool {
+	switch y := x.(type) {
+	case *shape.RefName:
+		if g.isParam(y) || g.isUnion(y) {
+			return true
+		}
+	case *shape.PointerLike:
+		return g.canMap(y.Type)
+	case *shape.ListLike:
+		return g.canMap(y.Element)
+	case *shape.MapLike:
+		return !g.mentionsParam(y.Key) && g.canMap(y.Val)
+	}
+
+	return !g.mentionsParam(x)
+}
+
+func (g *FunctorUnion) hasParamOrUnion(x shape.Shape) bool {
+	switch y := x.(type) {
+	case *shape.RefName:
+		return g.isParam(y) || g.isUnion(y)
+	case *shape.PointerLike:
+		return g.hasParamOrUnion(y.Type)
+	case *shape.ListLike:
+		return g.hasParamOrUnion(y.Element)
+	case *shape.MapLike:
+		return g.hasParamOrUnion(y.Val)
+	}
+
+	return false
+}
+
+func (g *FunctorUnion) mentionsParam(x shape.Shape) bool {
+	return shape.MatchShapeR1(
+		x,
+		func(x *shape.Any) bool {
+			return false
+		},
+		func(x *shape.RefName) bool {
+			if g.isParam(x) {
+				return true
+			}
+			for _, y := range x.Indexed {
+				if g.mentionsParam(y) {
+					return true
+				}
+			}
+			return false
+		},
+		func(x *shape.PointerLike) bool {
+			return g.mentionsParam(x.Type)
+		},
+		func(x *shape.AliasLike) bool {
+			return g.mentionsParam(x.Type)
+		},
+		func(x *shape.PrimitiveLike) bool {
+			return false
+		},
+		func(x *shape.ListLike) bool {
+			return g.mentionsParam(x.Element)
+		},
+		func(x *shape.MapLike) bool {
+			return g.mentionsParam(x.Key) || g.mentionsParam(x.Val)
+		},
+		func(x *shape.StructLike) bool {
+			for _, field := range x.Fields {
+				if g.mentionsParam(field.Type) {
+					return true
+				}
+			}
+			return false
+		},
+		func(x *shape.UnionLike) bool {
+			return false
+		},
+	)
+}
+
+// isParam returns true, when x is the type parameter, that functions map over.
+func (g *FunctorUnion) isParam(x *shape.RefName) bool {
+	return x.PkgName == "" && x.Name == g.param()
+}
+
+func (g *FunctorUnion) isTypeParam(name string) bool {
+	for _, param := range g.union.TypeParams {
+		if param.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isUnion returns true, when x refers back to union with the same type parameters.
+func (g *FunctorUnion) isUnion(x *shape.RefName) bool {
+	if x.Name != g.union.Name || x.PkgImportName != g.union.PkgImportName {
+		return false
+	}
+
+	if len(x.Indexed) != len(g.union.TypeParams) {
+		return false
+	}
+
+	for i, y := range x.Indexed {
+		ref, ok := y.(*shape.RefName)
+		if !ok || ref.PkgName != "" || ref.Name != g.union.TypeParams[i].Name {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (g *FunctorUnion) param() string {
+	return g.union.TypeParams[len(g.union.TypeParams)-1].Name
+}
+
+func (g *FunctorUnion) paramConstraint() string {
+	return g.typeName(g.union.TypeParams[len(g.union.TypeParams)-1].Type)
+}
+
+// outParam returns name of type parameter, that is not used by union, for result of mapping or folding.
+func (g *FunctorUnion) outParam() string {
+	for c := 'B'; c <= 'Z'; c++ {
+		if !g.isTypeParam(string(c)) {
+			return string(c)
+		}
+	}
+
+	for i := 0; ; i++ {
+		if name := fmt.Sprintf("T%d", i); !g.isTypeParam(name) {
+			return name
+		}
+	}
+}
+
+func (g *FunctorUnion) typeParamsDecl() string {
+	var result []string
+	for _, param := range g.union.TypeParams {
+		result = append(result, fmt.Sprintf("%s %s", param.Name, g.typeName(param.Type)))
+	}
+
+	return strings.Join(result, ", ")
+}
+
+// instantiate returns name with type parameters of union, where mapped one is replaced by output parameter.
+func (g *FunctorUnion) instantiate(name string, mapped bool) string {
+	var params []string
+	for _, param := range g.union.TypeParams {
+		params = append(params, param.Name)
+	}
+
+	if mapped {
+		params[len(params)-1] = g.outParam()
+	}
+
+	return fmt.Sprintf("%s[%s]", name, strings.Join(params, ","))
+}
+
+func (g *FunctorUnion) unionType(mapped bool) string {
+	return g.instantiate(g.union.Name, mapped)
+}
+
+func (g *FunctorUnion) variantType(x shape.Shape, mapped bool) string {
+	return g.instantiate(shape.Name(x), mapped)
+}
+
+func (g *FunctorUnion) typeName(x shape.Shape) string {
+	for pkgName, pkgImportName := range shape.ExtractPkgImportNames(x) {
+		g.imports[pkgName] = pkgImportName
+	}
+
+	return shape.ToGoTypeName(x, shape.WithRootPkgName(shape.ToGoPkgName(g.union)))
+}
+
+// outTypeName returns name of type x, where mapped type parameter is replaced by output parameter.
+func (g *FunctorUnion) outTypeName(x shape.Shape) string {
+	return g.typeName(g.replaceParam(x))
+}
+
+func (g *FunctorUnion) replaceParam(x shape.Shape) shape.Shape {
+	switch y := x.(type) {
+	case *shape.RefName:
+		if g.isParam(y) {
+			return &shape.RefName{Name: g.outParam()}
+		}
+
+		if len(y.Indexed) > 0 {
+			indexed := make([]shape.Shape, len(y.Indexed))
+			for i, z := range y.Indexed {
+				indexed[i] = g.replaceParam(z)
+			}
+
+			return &shape.RefName{
+				Name:          y.Name,
+				PkgName:       y.PkgName,
+				PkgImportName: y.PkgImportName,
+				Indexed:       indexed,
+			}
+		}
+
+	case *shape.PointerLike:
+		return &shape.PointerLike{Type: g.replaceParam(y.Type)}
+
+	case *shape.ListLike:
+		return &shape.ListLike{
+			Element:  g.replaceParam(y.Element),
+			ArrayLen: y.ArrayLen,
+		}
+
+	case *shape.MapLike:
+		return &shape.MapLike{
+			Key: y.Key,
+			Val: g.replaceParam(y.Val),
+		}
+	}
+
+	return x
+}
+
+func (g *FunctorUnion) next() int {
+	g.counter++
+	return g.counter
+}
+
+// indexOf returns expression that indexes x, where dereference needs parenthesis, like (*x)[i].
+func indexOf(x, index string) string {
+	if strings.HasPrefix(x, "*") {
+		x = "(" + x + ")"
+	}
+
+	return x + "[" + index + "]"
+}
+
+func writeLine(result *strings.Builder, indent int, format string, args ...any) {
+	result.WriteString(strings.Repeat("\t", indent))
+	result.WriteString(fmt.Sprintf(format, args...))
+	result.WriteString("\n")
+}
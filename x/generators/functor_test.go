@@ -0,0 +1,253 @@
+package generators
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+)
+
+func TestNewFunctorUnion(t *testing.T) {
+	inferred, err := shape.InferFromFile("testutils/functor.go")
+	assert.NoError(t, err)
+
+	g := NewFunctorUnion(inferred.RetrieveUnion("Expr"))
+
+	result, err := g.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, `package testutils
+
+// MapExpr applies f to every value of type A in Expr, and returns Expr of the same structure.
+func MapExpr[K any, A any, B any](x Expr[K,A], f func(A) B) Expr[K,B] {
+	return MatchExprR1(
+		x,
+		func(x *Lit[K,A]) Expr[K,B] {
+			result := &Lit[K,B]{}
+			result.Value = f(x.Value)
+			if x.Note != nil {
+				r1 := *x.Note
+				result.Note = &r1
+			}
+			return result
+		},
+		func(x *Var[K,A]) Expr[K,B] {
+			result := &Var[K,B]{}
+			result.Name = x.Name
+			return result
+		},
+		func(x *Call[K,A]) Expr[K,B] {
+			result := &Call[K,B]{}
+			result.Fn = MapExpr(x.Fn, f)
+			if x.Args != nil {
+				result.Args = make([]Expr[K,B], len(x.Args))
+				for i1 := range x.Args {
+					result.Args[i1] = MapExpr(x.Args[i1], f)
+				}
+			}
+			if x.Opt != nil {
+				r2 := f(*x.Opt)
+				result.Opt = &r2
+			}
+			return result
+		},
+		func(x *Let[K,A]) Expr[K,B] {
+			result := &Let[K,B]{}
+			if x.Bind != nil {
+				result.Bind = make(map[string]Expr[K,B], len(x.Bind))
+				for k1, v1 := range x.Bind {
+					result.Bind[k1] = MapExpr(v1, f)
+				}
+			}
+			for i2 := range x.Pair {
+				result.Pair[i2] = f(x.Pair[i2])
+			}
+			if x.Meta != nil {
+				result.Meta = make(map[string][]B, len(x.Meta))
+				for k3, v3 := range x.Meta {
+					var r3 []B
+					if v3 != nil {
+						r3 = make([]B, len(v3))
+						for i4 := range v3 {
+							r3[i4] = f(v3[i4])
+						}
+					}
+					result.Meta[k3] = r3
+				}
+			}
+			result.In = MapExpr(x.In, f)
+			return result
+		},
+		func(x *Nop[K,A]) Expr[K,B] {
+			result := &Nop[K,B]{}
+			return result
+		},
+	)
+}
+
+// TraverseExpr works like MapExpr, but stops on the first error returned by f.
+func TraverseExpr[K any, A any, B any](x Expr[K,A], f func(A) (B, error)) (Expr[K,B], error) {
+	return MatchExprR2(
+		x,
+		func(x *Lit[K,A]) (Expr[K,B], error) {
+			result := &Lit[K,B]{}
+			r1, err := f(x.Value)
+			if err != nil {
+				return nil, err
+			}
+			result.Value = r1
+			if x.Note != nil {
+				r2 := *x.Note
+				result.Note = &r2
+			}
+			return result, nil
+		},
+		func(x *Var[K,A]) (Expr[K,B], error) {
+			result := &Var[K,B]{}
+			result.Name = x.Name
+			return result, nil
+		},
+		func(x *Call[K,A]) (Expr[K,B], error) {
+			result := &Call[K,B]{}
+			r1, err := TraverseExpr(x.Fn, f)
+			if err != nil {
+				return nil, err
+			}
+			result.Fn = r1
+			if x.Args != nil {
+				result.Args = make([]Expr[K,B], len(x.Args))
+				for i2 := range x.Args {
+					r3, err := TraverseExpr(x.Args[i2], f)
+					if err != nil {
+						return nil, err
+					}
+					result.Args[i2] = r3
+				}
+			}
+			if x.Opt != nil {
+				var r4 B
+				r5, err := f(*x.Opt)
+				if err != nil {
+					return nil, err
+				}
+				r4 = r5
+				result.Opt = &r4
+			}
+			return result, nil
+		},
+		func(x *Let[K,A]) (Expr[K,B], error) {
+			result := &Let[K,B]{}
+			if x.Bind != nil {
+				result.Bind = make(map[string]Expr[K,B], len(x.Bind))
+				for k1, v1 := range x.Bind {
+					var r1 Expr[K,B]
+					r2, err := TraverseExpr(v1, f)
+					if err != nil {
+						return nil, err
+					}
+					r1 = r2
+					result.Bind[k1] = r1
+				}
+			}
+			for i3 := range x.Pair {
+				r4, err := f(x.Pair[i3])
+				if err != nil {
+					return nil, err
+				}
+				result.Pair[i3] = r4
+			}
+			if x.Meta != nil {
+				result.Meta = make(map[string][]B, len(x.Meta))
+				for k5, v5 := range x.Meta {
+					var r5 []B
+					if v5 != nil {
+						r5 = make([]B, len(v5))
+						for i6 := range v5 {
+							r7, err := f(v5[i6])
+							if err != nil {
+								return nil, err
+							}
+							r5[i6] = r7
+						}
+					}
+					result.Meta[k5] = r5
+				}
+			}
+			r8, err := TraverseExpr(x.In, f)
+			if err != nil {
+				return nil, err
+			}
+			result.In = r8
+			return result, nil
+		},
+		func(x *Nop[K,A]) (Expr[K,B], error) {
+			result := &Nop[K,B]{}
+			return result, nil
+		},
+	)
+}
+
+// FoldExpr reduces every value of type A in Expr with f, in order of fields, starting with init.
+// Values in maps are visited in unspecified order.
+func FoldExpr[K any, A any, B any](x Expr[K,A], f func(A, B) B, init B) B {
+	if x == nil {
+		return init
+	}
+
+	return MatchExprR1(
+		x,
+		func(x *Lit[K,A]) B {
+			result := init
+			result = f(x.Value, result)
+			return result
+		},
+		func(x *Var[K,A]) B {
+			return init
+		},
+		func(x *Call[K,A]) B {
+			result := init
+			result = FoldExpr(x.Fn, f, result)
+			for _, v1 := range x.Args {
+				result = FoldExpr(v1, f, result)
+			}
+			if x.Opt != nil {
+				result = f(*x.Opt, result)
+			}
+			return result
+		},
+		func(x *Let[K,A]) B {
+			result := init
+			for _, v1 := range x.Bind {
+				result = FoldExpr(v1, f, result)
+			}
+			for _, v2 := range x.Pair {
+				result = f(v2, result)
+			}
+			for _, v3 := range x.Meta {
+				for _, v4 := range v3 {
+					result = f(v4, result)
+				}
+			}
+			result = FoldExpr(x.In, f, result)
+			return result
+		},
+		func(x *Nop[K,A]) B {
+			return init
+		},
+	)
+}
+
+`, result)
+}
+
+func TestNewFunctorUnion_Errors(t *testing.T) {
+	inferred, err := shape.InferFromFile("testutils/tree.go")
+	assert.NoError(t, err)
+
+	_, err = NewFunctorUnion(inferred.RetrieveUnion("Tree")).Generate()
+	assert.ErrorContains(t, err, "union Tree must have type parameter to derive functor")
+
+	inferred, err = shape.InferFromFile("testutils/generic.go")
+	assert.NoError(t, err)
+
+	_, err = NewFunctorUnion(inferred.RetrieveUnion("Record")).Generate()
+	assert.ErrorContains(t, err, "variant Other of union Record must be a struct to derive functor")
+}
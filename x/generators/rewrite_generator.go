@@ -0,0 +1,267 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"strings"
+)
+
+// NewRewriteGenerator generates Rewrite{Name}TopDown and Rewrite{Name}BottomUp functions for recursive union,
+// that replace each value of union nested in fields of variants, also through pointers, lists and maps.
+// Unions, that don't refer back to themselves, don't have anything to rewrite, so nothing is generated for them.
+func NewRewriteGenerator(union *shape.UnionLike) *RewriteGenerator {
+	return &RewriteGenerator{
+		union:   union,
+		imports: make(PkgMap),
+	}
+}
+
+type RewriteGenerator struct {
+	union                 *shape.UnionLike
+	skipImportsAndPackage bool
+	imports               PkgMap
+	counter               int
+}
+
+func (g *RewriteGenerator) SkipImportsAndPackage(flag bool) {
+	g.skipImportsAndPackage = flag
+}
+
+func (g *RewriteGenerator) Generate() ([]byte, error) {
+	body := &bytes.Buffer{}
+	if g.IsRecursive() {
+		body.WriteString(g.GenerateRewriteFunctions())
+	}
+
+	header := &bytes.Buffer{}
+	if !g.skipImportsAndPackage {
+		header.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.union)))
+		header.WriteString(GenerateImports(g.ExtractImports()))
+	}
+
+	if header.Len() > 0 {
+		return append(header.Bytes(), body.Bytes()...), nil
+	} else {
+		return body.Bytes(), nil
+	}
+}
+
+// ExtractImports returns packages used by generated code, so it must be called after Generate.
+func (g *RewriteGenerator) ExtractImports() PkgMap {
+	result := make(PkgMap)
+	for pkgName, pkgImportName := range g.imports {
+		if pkgImportName == g.union.PkgImportName {
+			continue
+		}
+		result[pkgName] = pkgImportName
+	}
+
+	return result
+}
+
+// IsRecursive returns true, when at least one variant of union has field that refers back to union.
+func (g *RewriteGenerator) IsRecursive() bool {
+	for _, variant := range g.union.Variant {
+		if len(g.rewrittenFields(variant)) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *RewriteGenerator) GenerateRewriteFunctions() string {
+	name := g.union.Name
+	unionType := g.typeName(g.union)
+	params := g.typeParamsDecl()
+	children := "rewrite" + name + "Children"
+
+	result := &strings.Builder{}
+
+	result.WriteString(fmt.Sprintf("// Rewrite%sTopDown applies f to x, and then to each %s nested in result of f (pre-order).\n", name, name))
+	result.WriteString(fmt.Sprintf("// When f returns nil, nested values are not visited.\n"))
+	result.WriteString(fmt.Sprintf("func Rewrite%sTopDown%s(x %s, f func(%s) %s) %s {\n", name, params, unionType, unionType, unionType, unionType))
+	result.WriteString(fmt.Sprintf("\tif x == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn nil\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\treturn %s(f(x), func(y %s) %s {\n", children, unionType, unionType))
+	result.WriteString(fmt.Sprintf("\t\treturn Rewrite%sTopDown(y, f)\n", name))
+	result.WriteString(fmt.Sprintf("\t})\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	result.WriteString(fmt.Sprintf("// Rewrite%sBottomUp applies f to each %s nested in x, and then to x with already rewritten values (post-order).\n", name, name))
+	result.WriteString(fmt.Sprintf("func Rewrite%sBottomUp%s(x %s, f func(%s) %s) %s {\n", name, params, unionType, unionType, unionType, unionType))
+	result.WriteString(fmt.Sprintf("\tif x == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn nil\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\treturn f(%s(x, func(y %s) %s {\n", children, unionType, unionType))
+	result.WriteString(fmt.Sprintf("\t\treturn Rewrite%sBottomUp(y, f)\n", name))
+	result.WriteString(fmt.Sprintf("\t}))\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	result.WriteString(fmt.Sprintf("// %s returns shallow copy of x, where each %s nested in x is replaced by result of f.\n", children, name))
+	result.WriteString(fmt.Sprintf("// Variants without nested %s are returned as they are, and x is never modified.\n", name))
+	result.WriteString(fmt.Sprintf("func %s%s(x %s, f func(%s) %s) %s {\n", children, params, unionType, unionType, unionType, unionType))
+	result.WriteString(fmt.Sprintf("\tswitch v := x.(type) {\n"))
+	for _, variant := range g.union.Variant {
+		g.counter = 0
+		fields := g.rewrittenFields(variant)
+		if len(fields) == 0 {
+			continue
+		}
+
+		result.WriteString(fmt.Sprintf("\tcase *%s:\n", g.typeName(variant)))
+		result.WriteString(fmt.Sprintf("\t\tresult := *v\n"))
+		switch y := variant.(type) {
+		case *shape.StructLike:
+			for _, field := range fields {
+				g.rewriteTo(result, 2, field.Type, "v."+field.Name, "result."+field.Name)
+			}
+		case *shape.AliasLike:
+			g.rewriteTo(result, 2, y.Type, "*v", "result")
+		}
+		result.WriteString(fmt.Sprintf("\t\treturn &result\n"))
+	}
+	result.WriteString(fmt.Sprintf("\t}\n\n"))
+	result.WriteString(fmt.Sprintf("\treturn x\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	return result.String()
+}
+
+// rewrittenFields returns fields of variant, that refer back to union.
+// Named type that isn't struct, like `type List []Expr`, is returned as single field.
+func (g *RewriteGenerator) rewrittenFields(variant shape.Shape) []*shape.FieldLike {
+	var result []*shape.FieldLike
+	switch y := variant.(type) {
+	case *shape.StructLike:
+		for _, field := range y.Fields {
+			if g.refersToUnion(field.Type) {
+				result = append(result, field)
+			}
+		}
+	case *shape.AliasLike:
+		if !y.IsAlias && g.refersToUnion(y.Type) {
+			result = append(result, &shape.FieldLike{Type: y.Type})
+		}
+	}
+
+	return result
+}
+
+// rewriteTo writes statements that assign to out value of in, where each nested union value is replaced by result of f.
+func (g *RewriteGenerator) rewriteTo(result *strings.Builder, indent int, x shape.Shape, in, out string) {
+	switch y := x.(type) {
+	case *shape.RefName:
+		writeLine(result, indent, "%s = f(%s)", out, in)
+
+	case *shape.PointerLike:
+		name := fmt.Sprintf("r%d", g.next())
+		writeLine(result, indent, "if %s != nil {", in)
+		writeLine(result, indent+1, "%s := *%s", name, in)
+		g.rewriteTo(result, indent+1, y.Type, "*"+in, name)
+		writeLine(result, indent+1, "%s = &%s", out, name)
+		writeLine(result, indent, "}")
+
+	case *shape.ListLike:
+		index := fmt.Sprintf("i%d", g.next())
+		if y.ArrayLen != nil {
+			writeLine(result, indent, "for %s := range %s {", index, in)
+			g.rewriteTo(result, indent+1, y.Element, indexOf(in, index), indexOf(out, index))
+			writeLine(result, indent, "}")
+			return
+		}
+
+		writeLine(result, indent, "if %s != nil {", in)
+		writeLine(result, indent+1, "%s = make(%s, len(%s))", out, g.typeName(y), in)
+		writeLine(result, indent+1, "for %s := range %s {", index, in)
+		g.rewriteTo(result, indent+2, y.Element, indexOf(in, index), indexOf(out, index))
+		writeLine(result, indent+1, "}")
+		writeLine(result, indent, "}")
+
+	case *shape.MapLike:
+		n := g.next()
+		key, value := fmt.Sprintf("k%d", n), fmt.Sprintf("v%d", n)
+		writeLine(result, indent, "if %s != nil {", in)
+		writeLine(result, indent+1, "%s = make(%s, len(%s))", out, g.typeName(y), in)
+		writeLine(result, indent+1, "for %s, %s := range %s {", key, value, in)
+		if _, ok := y.Val.(*shape.RefName); ok {
+			writeLine(result, indent+2, "%s[%s] = f(%s)", out, key, value)
+		} else {
+			name := fmt.Sprintf("r%d", n)
+			writeLine(result, indent+2, "%s := %s", name, value)
+			g.rewriteTo(result, indent+2, y.Val, value, name)
+			writeLine(result, indent+2, "%s[%s] = %s", out, key, name)
+		}
+		writeLine(result, indent+1, "}")
+		writeLine(result, indent, "}")
+	}
+}
+
+// refersToUnion returns true, when x is union, or pointer, list or map that holds union.
+func (g *RewriteGenerator) refersToUnion(x shape.Shape) bool {
+	switch y := x.(type) {
+	case *shape.RefName:
+		return g.isUnion(y)
+	case *shape.PointerLike:
+		return g.refersToUnion(y.Type)
+	case *shape.ListLike:
+		return g.refersToUnion(y.Element)
+	case *shape.MapLike:
+		return g.refersToUnion(y.Val)
+	}
+
+	return false
+}
+
+// isUnion returns true, when x refers back to union with the same type parameters.
+func (g *RewriteGenerator) isUnion(x *shape.RefName) bool {
+	if x.Name != g.union.Name || x.PkgImportName != g.union.PkgImportName {
+		return false
+	}
+
+	if len(x.Indexed) != len(g.union.TypeParams) {
+		return false
+	}
+
+	for i, y := range x.Indexed {
+		ref, ok := y.(*shape.RefName)
+		if !ok || ref.PkgName != "" || ref.Name != g.union.TypeParams[i].Name {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (g *RewriteGenerator) typeParamsDecl() string {
+	if len(g.union.TypeParams) == 0 {
+		return ""
+	}
+
+	var result []string
+	for _, param := range g.union.TypeParams {
+		result = append(result, fmt.Sprintf("%s %s", param.Name, g.typeName(param.Type)))
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(result, ", "))
+}
+
+func (g *RewriteGenerator) typeName(x shape.Shape) string {
+	switch x.(type) {
+	case *shape.UnionLike, *shape.StructLike:
+		// union and variants are declared in the same package, and struct shape would bring imports of its fields
+	default:
+		for pkgName, pkgImportName := range shape.ExtractPkgImportNames(x) {
+			g.imports[pkgName] = pkgImportName
+		}
+	}
+
+	return shape.ToGoTypeName(x, shape.WithRootPkgName(shape.ToGoPkgName(g.union)))
+}
+
+func (g *RewriteGenerator) next() int {
+	g.counter++
+	return g.counter
+}
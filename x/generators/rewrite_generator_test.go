@@ -0,0 +1,116 @@
+package generators
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+)
+
+func TestRewriteGenerator(t *testing.T) {
+	inferred, err := shape.InferFromFile("testutils/tree.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generator := NewRewriteGenerator(inferred.RetrieveUnion("Tree"))
+
+	result, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, `package testutils
+
+// RewriteTreeTopDown applies f to x, and then to each Tree nested in result of f (pre-order).
+// When f returns nil, nested values are not visited.
+func RewriteTreeTopDown(x Tree, f func(Tree) Tree) Tree {
+	if x == nil {
+		return nil
+	}
+	return rewriteTreeChildren(f(x), func(y Tree) Tree {
+		return RewriteTreeTopDown(y, f)
+	})
+}
+
+// RewriteTreeBottomUp applies f to each Tree nested in x, and then to x with already rewritten values (post-order).
+func RewriteTreeBottomUp(x Tree, f func(Tree) Tree) Tree {
+	if x == nil {
+		return nil
+	}
+	return f(rewriteTreeChildren(x, func(y Tree) Tree {
+		return RewriteTreeBottomUp(y, f)
+	}))
+}
+
+// rewriteTreeChildren returns shallow copy of x, where each Tree nested in x is replaced by result of f.
+// Variants without nested Tree are returned as they are, and x is never modified.
+func rewriteTreeChildren(x Tree, f func(Tree) Tree) Tree {
+	switch v := x.(type) {
+	case *Branch:
+		result := *v
+		result.Lit = f(v.Lit)
+		if v.List != nil {
+			result.List = make([]Tree, len(v.List))
+			for i1 := range v.List {
+				result.List[i1] = f(v.List[i1])
+			}
+		}
+		if v.Map != nil {
+			result.Map = make(map[string]Tree, len(v.Map))
+			for k2, v2 := range v.Map {
+				result.Map[k2] = f(v2)
+			}
+		}
+		return &result
+	case *Ma:
+		result := *v
+		if *v != nil {
+			result = make(map[string]Tree, len(*v))
+			for k1, v1 := range *v {
+				result[k1] = f(v1)
+			}
+		}
+		return &result
+	case *La:
+		result := *v
+		if *v != nil {
+			result = make([]Tree, len(*v))
+			for i1 := range *v {
+				result[i1] = f((*v)[i1])
+			}
+		}
+		return &result
+	case *Ka:
+		result := *v
+		if *v != nil {
+			result = make([]map[string]Tree, len(*v))
+			for i1 := range *v {
+				if (*v)[i1] != nil {
+					result[i1] = make(map[string]Tree, len((*v)[i1]))
+					for k2, v2 := range (*v)[i1] {
+						result[i1][k2] = f(v2)
+					}
+				}
+			}
+		}
+		return &result
+	}
+
+	return x
+}
+
+`, string(result))
+}
+
+func TestRewriteGenerator_NotRecursive(t *testing.T) {
+	inferred, err := shape.InferFromFile("testutils/tree.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generator := NewRewriteGenerator(inferred.RetrieveUnion("Forest"))
+	generator.SkipImportsAndPackage(true)
+
+	assert.False(t, generator.IsRecursive())
+
+	result, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Empty(t, string(result))
+}
@@ -11,9 +11,10 @@ const (
 	marshalJSONMethodPrefix   = "_marshalJSON"
 )
 
-func NewSerdeJSONTagged(shape shape.Shape) *SerdeJSONTagged {
+func NewSerdeJSONTagged(x shape.Shape) *SerdeJSONTagged {
 	return &SerdeJSONTagged{
-		shape:                          shape,
+		shape:                          x,
+		lookup:                         shape.LookupShapeOnDisk,
 		skipImportsAndPackage:          false,
 		didGenerateMarshalJSONMethod:   make(map[string]bool),
 		didGenerateUnmarshalJSONMethod: make(map[string]bool),
@@ -26,6 +27,7 @@ func NewSerdeJSONTagged(shape shape.Shape) *SerdeJSONTagged {
 
 type SerdeJSONTagged struct {
 	shape                 shape.Shape
+	lookup                func(*shape.RefName) (shape.Shape, bool)
 	skipImportsAndPackage bool
 
 	didGenerateMarshalJSONMethod   map[string]bool
@@ -115,6 +117,11 @@ func (g *SerdeJSONTagged) GenerateVarCasting(x shape.Shape) (string, error) {
 			panic("not implemented pointer var casting")
 		},
 		func(x *shape.AliasLike) (string, error) {
+			if _, isRef := x.Type.(*shape.RefName); x.IsAlias && !isRef {
+				// week alias of primitive, list or map is the same type as its target, which can't have methods
+				return "", nil
+			}
+
 			result := &strings.Builder{}
 			result.WriteString("var (\n")
 			result.WriteString("\t_ json.Unmarshaler = (*")
@@ -220,6 +227,126 @@ func (g *SerdeJSONTagged) rootTypeName() string {
 	)
 }
 
+// structFields returns fields of struct with fields of embedded structs promoted, like in encoding/json,
+// and records imports of packages, that types of promoted fields come from.
+func (g *SerdeJSONTagged) structFields(x *shape.StructLike) []*shape.StructField {
+	fields := shape.StructFields(x, g.lookup)
+	for _, field := range fields {
+		for _, embedded := range field.Embedded {
+			g.pkgUsed = MergePkgMaps(g.pkgUsed, shape.ExtractPkgImportNames(embedded.Type))
+		}
+		if len(field.Embedded) > 0 {
+			g.pkgUsed = MergePkgMaps(g.pkgUsed, shape.ExtractPkgImportNames(field.Type))
+		}
+	}
+
+	return fields
+}
+
+// funcName returns name of function, referenced by type mapper, as it's called from generated package.
+func (g *SerdeJSONTagged) funcName(x *shape.RefName) string {
+	return shape.ToGoTypeName(x, shape.WithRootPkgName(shape.ToGoPkgName(g.shape)))
+}
+
+// notOmitted returns conditions, that are true when field accessed by expr is serialised,
+// following encoding/json rules for omitempty and omitzero options.
+// Condition is checked on declared type, when it's known, and with shared.JSONIsEmpty or shared.JSONIsZero otherwise.
+func (g *SerdeJSONTagged) notOmitted(field *shape.StructField, expr string) []string {
+	var result []string
+	if field.OmitEmpty() {
+		switch y := g.underlying(field.Type).(type) {
+		case *shape.PrimitiveLike:
+			result = append(result, notZeroPrimitive(y, expr))
+		case *shape.ListLike, *shape.MapLike:
+			result = append(result, fmt.Sprintf("len(%s) > 0", expr))
+		case *shape.PointerLike, *shape.UnionLike, *shape.Any:
+			result = append(result, fmt.Sprintf("%s != nil", expr))
+		case *shape.StructLike:
+			// encoding/json never omits struct with omitempty option
+		default:
+			g.pkgUsed["shared"] = "github.com/widmogrod/mkunion/x/shared"
+			result = append(result, fmt.Sprintf("!shared.JSONIsEmpty(%s)", expr))
+		}
+	}
+
+	if field.OmitZero() {
+		// named type can have IsZero method, so only zero of unnamed types is checked directly
+		switch y := field.Type.(type) {
+		case *shape.PrimitiveLike:
+			result = append(result, notZeroPrimitive(y, expr))
+		case *shape.PointerLike, *shape.MapLike, *shape.Any:
+			result = append(result, fmt.Sprintf("%s != nil", expr))
+		case *shape.ListLike:
+			if y.ArrayLen == nil {
+				result = append(result, fmt.Sprintf("%s != nil", expr))
+				break
+			}
+			g.pkgUsed["shared"] = "github.com/widmogrod/mkunion/x/shared"
+			result = append(result, fmt.Sprintf("!shared.JSONIsZero(%s)", expr))
+		default:
+			g.pkgUsed["shared"] = "github.com/widmogrod/mkunion/x/shared"
+			result = append(result, fmt.Sprintf("!shared.JSONIsZero(%s)", expr))
+		}
+	}
+
+	return result
+}
+
+func notZeroPrimitive(x *shape.PrimitiveLike, expr string) string {
+	return shape.MatchPrimitiveKindR1(
+		x.Kind,
+		func(x *shape.BooleanLike) string {
+			return expr
+		},
+		func(x *shape.StringLike) string {
+			return fmt.Sprintf("%s != \"\"", expr)
+		},
+		func(x *shape.NumberLike) string {
+			return fmt.Sprintf("%s != 0", expr)
+		},
+	)
+}
+
+// isQuoted returns true, when field has `json:",string"` option, and it's string, number or boolean, or pointer to one of them,
+// since encoding/json ignores this option for fields of other types.
+func (g *SerdeJSONTagged) isQuoted(field *shape.StructField) bool {
+	if !field.Quoted() {
+		return false
+	}
+
+	typ := field.Type
+	if ptr, ok := typ.(*shape.PointerLike); ok {
+		typ = ptr.Type
+	}
+
+	_, ok := g.underlying(typ).(*shape.PrimitiveLike)
+	return ok
+}
+
+// underlying returns shape that type is declared as, like StringLike for `type ID string`,
+// or x, when declaration can't be found.
+func (g *SerdeJSONTagged) underlying(x shape.Shape) shape.Shape {
+	// limit depth, to not loop forever on invalid declarations, like `type A B; type B A`
+	for i := 0; i < 10; i++ {
+		switch y := x.(type) {
+		case *shape.RefName:
+			found, ok := g.lookup(y)
+			if !ok {
+				return x
+			}
+			x = shape.IndexWith(found, y)
+
+		case *shape.AliasLike:
+			x = y.Type
+
+		default:
+			return x
+		}
+	}
+
+	return x
+}
+
 func (g *SerdeJSONTagged) errorContext(name string) string {
 	return fmt.Sprintf(`%s: %s.%s:`, g.rootPkgName(), g.rootTypeName(), name)
 }
@@ -266,6 +393,22 @@ func (g *SerdeJSONTagged) GenerateMarshalJSONMethods(x shape.Shape) (string, err
 			return methodWrap(body)
 		},
 		func(y *shape.RefName) (string, error) {
+			if mapper, ok := shape.LookupTypeMapper(y); ok {
+				g.pkgUsed = MergePkgMaps(g.pkgUsed, shape.ExtractPkgImportNames(mapper.ToWire))
+
+				body := &strings.Builder{}
+				body.WriteString(fmt.Sprintf("wire, err := %s(x)\n", g.funcName(mapper.ToWire)))
+				body.WriteString(fmt.Sprintf("if err != nil {\n"))
+				body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s to wire; %%w\", err)\n", errorContext))
+				body.WriteString(fmt.Sprintf("}\n"))
+				body.WriteString(fmt.Sprintf("result, err := json.Marshal(wire)\n"))
+				body.WriteString(fmt.Sprintf("if err != nil {\n"))
+				body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+				body.WriteString(fmt.Sprintf("}\n"))
+				body.WriteString(fmt.Sprintf("return result, nil\n"))
+				return methodWrap(body)
+			}
+
 			g.pkgUsed["shared"] = "github.com/widmogrod/mkunion/x/shared"
 
 			body := &strings.Builder{}
@@ -434,23 +577,51 @@ func (g *SerdeJSONTagged) GenerateMarshalJSONMethods(x shape.Shape) (string, err
 		func(y *shape.StructLike) (string, error) {
 			body := &strings.Builder{}
 
+			fields := g.structFields(y)
+
 			body.WriteString(fmt.Sprintf("partial := make(map[string]json.RawMessage)\n"))
 			body.WriteString(fmt.Sprintf("var err error\n"))
-			for _, field := range y.Fields {
-				jsonFieldName := shape.TagGetValue(field.Tags, "json", field.Name)
-
-				body.WriteString(fmt.Sprintf("var field%s []byte\n", field.Name))
-				body.WriteString(fmt.Sprintf("field%s, err = r.%s(x.%s)\n", field.Name, g.methodNameWithPrefix(field.Type, marshalJSONMethodPrefix), field.Name))
-				body.WriteString(fmt.Sprintf("if err != nil {\n"))
-				body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s field name %s; %%w\", err)\n", errorContext, field.Name))
-				body.WriteString(fmt.Sprintf("}\n"))
+			for _, field := range fields {
+				jsonFieldName := field.JSONName()
+				varName := "field" + strings.Join(field.Path(), "_")
+
+				fieldBody := &strings.Builder{}
+				fieldBody.WriteString(fmt.Sprintf("var %s []byte\n", varName))
+				fieldBody.WriteString(fmt.Sprintf("%s, err = r.%s(x.%s)\n", varName, g.methodNameWithPrefix(field.Type, marshalJSONMethodPrefix), strings.Join(field.Path(), ".")))
+				fieldBody.WriteString(fmt.Sprintf("if err != nil {\n"))
+				fieldBody.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s field name %s; %%w\", err)\n", errorContext, strings.Join(field.Path(), ".")))
+				fieldBody.WriteString(fmt.Sprintf("}\n"))
+				if g.isQuoted(field) {
+					g.pkgUsed["shared"] = "github.com/widmogrod/mkunion/x/shared"
+					fieldBody.WriteString(fmt.Sprintf("%s, err = shared.JSONQuote(%s)\n", varName, varName))
+					fieldBody.WriteString(fmt.Sprintf("if err != nil {\n"))
+					fieldBody.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s field name %s; %%w\", err)\n", errorContext, strings.Join(field.Path(), ".")))
+					fieldBody.WriteString(fmt.Sprintf("}\n"))
+				}
 
 				if shape.IsPointer(field.Type) {
-					body.WriteString(fmt.Sprintf("if field%s != nil {\n", field.Name))
-					body.WriteString(fmt.Sprintf("\tpartial[\"%s\"] = field%s\n", jsonFieldName, field.Name))
+					fieldBody.WriteString(fmt.Sprintf("if %s != nil {\n", varName))
+					fieldBody.WriteString(fmt.Sprintf("\tpartial[\"%s\"] = %s\n", jsonFieldName, varName))
+					fieldBody.WriteString(fmt.Sprintf("}\n"))
+				} else {
+					fieldBody.WriteString(fmt.Sprintf("partial[\"%s\"] = %s\n", jsonFieldName, varName))
+				}
+
+				// field promoted from nil embedded pointer is not serialised, like in encoding/json
+				var notNil []string
+				for i, embedded := range field.Embedded {
+					if shape.IsPointer(embedded.Type) {
+						notNil = append(notNil, fmt.Sprintf("x.%s != nil", strings.Join(field.Path()[:i+1], ".")))
+					}
+				}
+				notNil = append(notNil, g.notOmitted(field, "x."+strings.Join(field.Path(), "."))...)
+
+				if len(notNil) > 0 {
+					body.WriteString(fmt.Sprintf("if %s {\n", strings.Join(notNil, " && ")))
+					body.WriteString(padLeftTabs(1, fieldBody.String()))
 					body.WriteString(fmt.Sprintf("}\n"))
 				} else {
-					body.WriteString(fmt.Sprintf("partial[\"%s\"] = field%s\n", jsonFieldName, field.Name))
+					body.WriteString(fieldBody.String())
 				}
 			}
 			body.WriteString(fmt.Sprintf("result, err := json.Marshal(partial)\n"))
@@ -465,7 +636,7 @@ func (g *SerdeJSONTagged) GenerateMarshalJSONMethods(x shape.Shape) (string, err
 			}
 
 			methods := ""
-			for _, field := range y.Fields {
+			for _, field := range fields {
 				fieldMethods, err := g.GenerateMarshalJSONMethods(field.Type)
 				if err != nil {
 					return "", fmt.Errorf("generators.SerdeJSONTagged.GenerateMarshalJSONMethods: field %s methods; %w", field.Name, err)
@@ -541,6 +712,27 @@ func (g *SerdeJSONTagged) GenerateUnmarshalJSONMethods(x shape.Shape) (string, e
 			return methodWrap(body)
 		},
 		func(y *shape.RefName) (string, error) {
+			if mapper, ok := shape.LookupTypeMapper(y); ok {
+				g.pkgUsed = MergePkgMaps(g.pkgUsed, shape.ExtractPkgImportNames(mapper.FromWire))
+
+				body := &strings.Builder{}
+				body.WriteString(fmt.Sprintf("var result %s\n", typeName))
+				body.WriteString(fmt.Sprintf("if string(data) == \"null\" {\n"))
+				body.WriteString(fmt.Sprintf("\treturn result, nil\n"))
+				body.WriteString(fmt.Sprintf("}\n"))
+				body.WriteString(fmt.Sprintf("var wire %s\n", shape.ToGoTypeName(mapper.Wire)))
+				body.WriteString(fmt.Sprintf("err := json.Unmarshal(data, &wire)\n"))
+				body.WriteString(fmt.Sprintf("if err != nil {\n"))
+				body.WriteString(fmt.Sprintf("\treturn result, fmt.Errorf(\"%s wire unwrap; %%w\", err)\n", errorContext))
+				body.WriteString(fmt.Sprintf("}\n"))
+				body.WriteString(fmt.Sprintf("result, err = %s(wire)\n", g.funcName(mapper.FromWire)))
+				body.WriteString(fmt.Sprintf("if err != nil {\n"))
+				body.WriteString(fmt.Sprintf("\treturn result, fmt.Errorf(\"%s from wire; %%w\", err)\n", errorContext))
+				body.WriteString(fmt.Sprintf("}\n"))
+				body.WriteString(fmt.Sprintf("return result, nil\n"))
+				return methodWrap(body)
+			}
+
 			g.pkgUsed["shared"] = "github.com/widmogrod/mkunion/x/shared"
 
 			body := &strings.Builder{}
@@ -750,13 +942,31 @@ func (g *SerdeJSONTagged) GenerateUnmarshalJSONMethods(x shape.Shape) (string, e
 			body.WriteString(fmt.Sprintf("if err != nil {\n"))
 			body.WriteString(fmt.Sprintf("\treturn result, fmt.Errorf(\"%s native struct unwrap; %%w\", err)\n", errorContext))
 			body.WriteString(fmt.Sprintf("}\n"))
-			for _, field := range y.Fields {
-				jsonFieldName := shape.TagGetValue(field.Tags, "json", field.Name)
-
-				body.WriteString(fmt.Sprintf("if field%s, ok := partial[\"%s\"]; ok {\n", field.Name, jsonFieldName))
-				body.WriteString(fmt.Sprintf("\tresult.%s, err = r.%s(field%s)\n", field.Name, g.methodNameWithPrefix(field.Type, unmarshalJSONMethodPrefix), field.Name))
+			fields := g.structFields(y)
+			for _, field := range fields {
+				jsonFieldName := field.JSONName()
+				varName := "field" + strings.Join(field.Path(), "_")
+
+				body.WriteString(fmt.Sprintf("if %s, ok := partial[\"%s\"]; ok {\n", varName, jsonFieldName))
+				// embedded pointer is allocated, when one of its fields is present, like in encoding/json
+				for i, embedded := range field.Embedded {
+					if ptr, ok := embedded.Type.(*shape.PointerLike); ok {
+						embeddedPath := strings.Join(field.Path()[:i+1], ".")
+						body.WriteString(fmt.Sprintf("\tif result.%s == nil {\n", embeddedPath))
+						body.WriteString(fmt.Sprintf("\t\tresult.%s = new(%s)\n", embeddedPath, shape.ToGoTypeName(ptr.Type, shape.WithRootPkgName(shape.ToGoPkgName(g.shape)))))
+						body.WriteString(fmt.Sprintf("\t}\n"))
+					}
+				}
+				if g.isQuoted(field) {
+					g.pkgUsed["shared"] = "github.com/widmogrod/mkunion/x/shared"
+					body.WriteString(fmt.Sprintf("\t%s, err = shared.JSONUnquote(%s)\n", varName, varName))
+					body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+					body.WriteString(fmt.Sprintf("\t\treturn result, fmt.Errorf(\"%s field %s; %%w\", err)\n", errorContext, strings.Join(field.Path(), ".")))
+					body.WriteString(fmt.Sprintf("\t}\n"))
+				}
+				body.WriteString(fmt.Sprintf("\tresult.%s, err = r.%s(%s)\n", strings.Join(field.Path(), "."), g.methodNameWithPrefix(field.Type, unmarshalJSONMethodPrefix), varName))
 				body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
-				body.WriteString(fmt.Sprintf("\t\treturn result, fmt.Errorf(\"%s field %s; %%w\", err)\n", errorContext, field.Name))
+				body.WriteString(fmt.Sprintf("\t\treturn result, fmt.Errorf(\"%s field %s; %%w\", err)\n", errorContext, strings.Join(field.Path(), ".")))
 				body.WriteString(fmt.Sprintf("\t}\n"))
 				body.WriteString(fmt.Sprintf("}\n"))
 			}
@@ -769,7 +979,7 @@ func (g *SerdeJSONTagged) GenerateUnmarshalJSONMethods(x shape.Shape) (string, e
 			}
 
 			methods := ""
-			for _, field := range y.Fields {
+			for _, field := range fields {
 				fieldMethods, err := g.GenerateUnmarshalJSONMethods(field.Type)
 				if err != nil {
 					return "", fmt.Errorf("generators.SerdeJSONTagged.GenerateUnmarshalJSONMethods: field %s methods; %w", field.Name, err)
@@ -182,7 +182,11 @@ func (r *ListOf2[T1,T2]) _marshalJSONListOfLb_T2_bL(x ListOf[T2]) ([]byte, error
 	return result, nil
 }
 func (r *ListOf2[T1,T2]) _marshalJSONtime_Time(x time.Time) ([]byte, error) {
-	result, err := shared.JSONMarshal[time.Time](x)
+	wire, err := shared.TimeToWire(x)
+	if err != nil {
+		return nil, fmt.Errorf("testutils: ListOf2[T1,T2]._marshalJSONtime_Time: to wire; %w", err)
+	}
+	result, err := json.Marshal(wire)
 	if err != nil {
 		return nil, fmt.Errorf("testutils: ListOf2[T1,T2]._marshalJSONtime_Time:; %w", err)
 	}
@@ -353,9 +357,18 @@ func (r *ListOf2[T1,T2]) _unmarshalJSONListOfLb_T2_bL(data []byte) (ListOf[T2],
 	return result, nil
 }
 func (r *ListOf2[T1,T2]) _unmarshalJSONtime_Time(data []byte) (time.Time, error) {
-	result, err := shared.JSONUnmarshal[time.Time](data)
+	var result time.Time
+	if string(data) == "null" {
+		return result, nil
+	}
+	var wire string
+	err := json.Unmarshal(data, &wire)
+	if err != nil {
+		return result, fmt.Errorf("testutils: ListOf2[T1,T2]._unmarshalJSONtime_Time: wire unwrap; %w", err)
+	}
+	result, err = shared.TimeFromWire(wire)
 	if err != nil {
-		return result, fmt.Errorf("testutils: ListOf2[T1,T2]._unmarshalJSONtime_Time: native ref unwrap; %w", err)
+		return result, fmt.Errorf("testutils: ListOf2[T1,T2]._unmarshalJSONtime_Time: from wire; %w", err)
 	}
 	return result, nil
 }
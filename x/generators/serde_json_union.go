@@ -18,6 +18,7 @@ func NewSerdeJSONUnion(union *shape.UnionLike) *SerdeJSONUnion {
 			"fmt":    "fmt",
 			"shared": "github.com/widmogrod/mkunion/x/shared",
 		},
+		lookup: shape.LookupShapeOnDisk,
 	}
 }
 
@@ -26,6 +27,8 @@ type SerdeJSONUnion struct {
 	skipImportsAndPackage bool
 	skipInitFunc          bool
 	pkgUsed               PkgMap
+	// lookup finds embedded structs, whose fields are promoted to struct variants of untagged union
+	lookup func(*shape.RefName) (shape.Shape, bool)
 }
 
 func (g *SerdeJSONUnion) SkipImportsAndPackage(x bool) {
@@ -72,7 +75,7 @@ func (g *SerdeJSONUnion) JSONVariantName(x shape.Shape) string {
 			return g.JSONVariantName(x.Type)
 		},
 		func(y *shape.AliasLike) string {
-			return fmt.Sprintf("%s.%s", y.PkgName, y.Name)
+			return shape.VariantDiscriminator(y)
 		},
 		func(y *shape.PrimitiveLike) string {
 			panic(fmt.Errorf("generators.JSONVariantName: must be named %T", y))
@@ -84,14 +87,24 @@ func (g *SerdeJSONUnion) JSONVariantName(x shape.Shape) string {
 			panic(fmt.Errorf("generators.JSONVariantName: must be named %T", y))
 		},
 		func(y *shape.StructLike) string {
-			return fmt.Sprintf("%s.%s", y.PkgName, y.Name)
+			return shape.VariantDiscriminator(y)
 		},
 		func(y *shape.UnionLike) string {
-			return fmt.Sprintf("%s.%s", y.PkgName, y.Name)
+			return shape.VariantDiscriminator(y)
 		},
 	)
 }
 
+// JSONVariantAliases returns previous names of variant, that are accepted when decoding,
+// together with names of fields in which payload under such name is decoded.
+func (g *SerdeJSONUnion) JSONVariantAliases(x shape.Shape) [][2]string {
+	var result [][2]string
+	for i, alias := range shape.VariantAliases(x) {
+		result = append(result, [2]string{alias, fmt.Sprintf("%sAlias%d", g.VariantName(x), i+1)})
+	}
+	return result
+}
+
 func (g *SerdeJSONUnion) Generate() ([]byte, error) {
 	body := &bytes.Buffer{}
 
@@ -288,16 +301,63 @@ func (g *SerdeJSONUnion) constructionf(x shape.Shape, template string) string {
 	return typeName
 }
 
+// unionEncoding returns encoding and version of union, versioned unions are supported only with external encoding.
+// Union, which variants declare the same name, is rejected, since its variants could not be decoded.
+func (g *SerdeJSONUnion) unionEncoding(union *shape.UnionLike) (*shape.JSONUnionEncoding, int, error) {
+	encoding, err := shape.UnionJSONEncoding(union)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, err = shape.VariantNames(union)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	version, err := shape.TagUnionVersion(union.Tags)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if version > 0 && encoding.Kind != shape.JSONUnionExternal {
+		return nil, 0, fmt.Errorf("union %s is versioned, which is supported only with %s encoding, given %s",
+			union.Name, shape.JSONUnionExternal, encoding.Kind)
+	}
+
+	return encoding, version, nil
+}
+
 func (g *SerdeJSONUnion) GenerateUnionType(union *shape.UnionLike) ([]byte, error) {
 	body := &bytes.Buffer{}
 
+	encoding, version, err := g.unionEncoding(union)
+	if err != nil {
+		return nil, fmt.Errorf("generators.SerdeJSONUnion.GenerateUnionType: %w", err)
+	}
+
+	if encoding.Kind == shape.JSONUnionUntagged {
+		// untagged union is decoded directly into variants
+		return nil, nil
+	}
+
 	body.WriteString(fmt.Sprintf("type %s struct {\n", g.constructionf(union, "%sUnionJSON")))
-	body.WriteString(fmt.Sprintf("\tType string `json:\"$type,omitempty\"`\n"))
-	for _, variant := range union.Variant {
-		body.WriteString(fmt.Sprintf("\t%s json.RawMessage `json:\"%s,omitempty\"`\n",
-			g.VariantName(variant),
-			g.JSONVariantName(variant),
-		))
+	body.WriteString(fmt.Sprintf("\tType string `json:\"%s,omitempty\"`\n", encoding.Tag))
+	if version > 0 {
+		body.WriteString(fmt.Sprintf("\tVersion int `json:\"$version,omitempty\"`\n"))
+	}
+	switch encoding.Kind {
+	case shape.JSONUnionExternal:
+		for _, variant := range union.Variant {
+			body.WriteString(fmt.Sprintf("\t%s json.RawMessage `json:\"%s,omitempty\"`\n",
+				g.VariantName(variant),
+				g.JSONVariantName(variant),
+			))
+			for _, alias := range g.JSONVariantAliases(variant) {
+				body.WriteString(fmt.Sprintf("\t%s json.RawMessage `json:\"%s,omitempty\"`\n", alias[1], alias[0]))
+			}
+		}
+	case shape.JSONUnionAdjacent:
+		body.WriteString(fmt.Sprintf("\tContent json.RawMessage `json:\"%s,omitempty\"`\n", encoding.Content))
 	}
 	body.WriteString(fmt.Sprintf("}\n\n"))
 
@@ -313,6 +373,15 @@ func (g *SerdeJSONUnion) GenerateUnionFromFunc(union *shape.UnionLike) ([]byte,
 
 	errorContext := g.errorFuncContext(g.parametrisedf(union, "%sFromJSON"))
 
+	encoding, version, err := g.unionEncoding(union)
+	if err != nil {
+		return nil, fmt.Errorf("generators.SerdeJSONUnion.GenerateUnionFromFunc: %w", err)
+	}
+
+	if version > 0 {
+		body.WriteString(g.GenerateUnionUpcastFunc(union, version))
+	}
+
 	body.WriteString(fmt.Sprintf("func %s(x []byte) (%s, error) {\n",
 		g.constructionf(union, "%sFromJSON"),
 		g.parametrisedf(union, "%s"),
@@ -320,39 +389,126 @@ func (g *SerdeJSONUnion) GenerateUnionFromFunc(union *shape.UnionLike) ([]byte,
 	body.WriteString(fmt.Sprintf("\tif x == nil || len(x) == 0 {\n"))
 	body.WriteString(fmt.Sprintf("\t\treturn nil, nil\n"))
 	body.WriteString(fmt.Sprintf("\t}\n"))
-	body.WriteString(fmt.Sprintf("\tif string(x[:4]) == \"null\" {\n"))
+	body.WriteString(fmt.Sprintf("\tif len(x) >= 4 && string(x[:4]) == \"null\" {\n"))
 	body.WriteString(fmt.Sprintf("\t\treturn nil, nil\n"))
 	body.WriteString(fmt.Sprintf("\t}\n"))
-	body.WriteString(fmt.Sprintf("\tvar data %s\n", g.parametrisedf(union, "%sUnionJSON")))
-	body.WriteString(fmt.Sprintf("\terr := json.Unmarshal(x, &data)\n"))
+
+	if encoding.Kind == shape.JSONUnionUntagged {
+		// unknown fields are ignored when struct is decoded, so JSON object is decoded into struct variant,
+		// which knows all keys of the object, instead of first struct variant that decodes without error
+		var structs, others []shape.Shape
+		for _, variant := range union.Variant {
+			if _, ok := variant.(*shape.StructLike); ok {
+				structs = append(structs, variant)
+			} else {
+				others = append(others, variant)
+			}
+		}
+
+		if len(structs) > 0 {
+			body.WriteString(fmt.Sprintf("\tif keys, ok := shared.JSONObjectKeys(x); ok {\n"))
+			body.WriteString(fmt.Sprintf("\t\tvar matched []string\n"))
+			for _, variant := range structs {
+				var known []string
+				for _, field := range shape.StructFields(variant.(*shape.StructLike), g.lookup) {
+					known = append(known, fmt.Sprintf("%q", field.JSONName()))
+				}
+				body.WriteString(fmt.Sprintf("\t\tif shared.JSONKeysKnown(keys%s) {\n", joinArgs(known)))
+				body.WriteString(fmt.Sprintf("\t\t\tmatched = append(matched, %q)\n", g.JSONVariantName(variant)))
+				body.WriteString(fmt.Sprintf("\t\t}\n"))
+			}
+			body.WriteString(fmt.Sprintf("\t\tif len(matched) > 1 {\n"))
+			body.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s value matches variants %%v: %%s\", matched, x)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t\t}\n"))
+			body.WriteString(fmt.Sprintf("\t\tif len(matched) == 1 {\n"))
+			body.WriteString(fmt.Sprintf("\t\t\tswitch matched[0] {\n"))
+			for _, variant := range structs {
+				body.WriteString(fmt.Sprintf("\t\t\tcase %q:\n", g.JSONVariantName(variant)))
+				body.WriteString(fmt.Sprintf("\t\t\t\tresult, err := %s(x)\n", g.FuncNameFromJSON(variant)))
+				body.WriteString(fmt.Sprintf("\t\t\t\tif err != nil {\n"))
+				body.WriteString(fmt.Sprintf("\t\t\t\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+				body.WriteString(fmt.Sprintf("\t\t\t\t}\n"))
+				body.WriteString(fmt.Sprintf("\t\t\t\treturn result, nil\n"))
+			}
+			body.WriteString(fmt.Sprintf("\t\t\t}\n"))
+			body.WriteString(fmt.Sprintf("\t\t}\n"))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+		}
+
+		for _, variant := range others {
+			body.WriteString(fmt.Sprintf("\tif result, err := %s(x); err == nil {\n", g.FuncNameFromJSON(variant)))
+			body.WriteString(fmt.Sprintf("\t\treturn result, nil\n"))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+		}
+		body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s value does not match any variant: %%s\", x)\n", errorContext))
+		body.WriteString(fmt.Sprintf("}\n\n"))
+
+		return body.Bytes(), nil
+	}
+
+	if version > 0 {
+		body.WriteString(fmt.Sprintf("\tx, err := %s(x)\n", upcastFuncName(union)))
+		body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("\t}\n"))
+		body.WriteString(fmt.Sprintf("\tvar data %s\n", g.parametrisedf(union, "%sUnionJSON")))
+		body.WriteString(fmt.Sprintf("\terr = json.Unmarshal(x, &data)\n"))
+	} else {
+		body.WriteString(fmt.Sprintf("\tvar data %s\n", g.parametrisedf(union, "%sUnionJSON")))
+		body.WriteString(fmt.Sprintf("\terr := json.Unmarshal(x, &data)\n"))
+	}
 	body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
 	body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
 	body.WriteString(fmt.Sprintf("\t}\n\n"))
+
+	// payload of variant is either under its name, whole value, or under content field
+	payload := func(field string) string {
+		switch encoding.Kind {
+		case shape.JSONUnionInternal:
+			return "x"
+		case shape.JSONUnionAdjacent:
+			return "data.Content"
+		}
+		return "data." + field
+	}
+
 	body.WriteString(fmt.Sprintf("\tswitch data.Type {\n"))
 	for _, variant := range union.Variant {
 		body.WriteString(fmt.Sprintf("\tcase %q:\n", g.JSONVariantName(variant)))
-		body.WriteString(fmt.Sprintf("\t\treturn %s(data.%s)\n",
+		body.WriteString(fmt.Sprintf("\t\treturn %s(%s)\n",
 			g.FuncNameFromJSON(variant),
-			g.VariantName(variant),
+			payload(g.VariantName(variant)),
 		))
+		for _, alias := range g.JSONVariantAliases(variant) {
+			body.WriteString(fmt.Sprintf("\tcase %q:\n", alias[0]))
+			body.WriteString(fmt.Sprintf("\t\treturn %s(%s)\n", g.FuncNameFromJSON(variant), payload(alias[1])))
+		}
 	}
 	body.WriteString(fmt.Sprintf("\t}\n\n"))
 
-	for i, variant := range union.Variant {
-		if i > 0 {
-			body.WriteString(fmt.Sprintf(" else "))
-		} else {
-			body.WriteString(fmt.Sprintf("\t"))
+	if encoding.Kind == shape.JSONUnionExternal {
+		// for backward compatibility, variant is recognised by name of field, when tag is missing
+		for i, variant := range union.Variant {
+			if i > 0 {
+				body.WriteString(fmt.Sprintf(" else "))
+			} else {
+				body.WriteString(fmt.Sprintf("\t"))
+			}
+
+			body.WriteString(fmt.Sprintf("if data.%s != nil {\n", g.VariantName(variant)))
+			body.WriteString(fmt.Sprintf("\t\treturn %s(data.%s)\n",
+				g.FuncNameFromJSON(variant),
+				g.VariantName(variant),
+			))
+			body.WriteString(fmt.Sprintf("\t}"))
+			for _, alias := range g.JSONVariantAliases(variant) {
+				body.WriteString(fmt.Sprintf(" else if data.%s != nil {\n", alias[1]))
+				body.WriteString(fmt.Sprintf("\t\treturn %s(data.%s)\n", g.FuncNameFromJSON(variant), alias[1]))
+				body.WriteString(fmt.Sprintf("\t}"))
+			}
 		}
-
-		body.WriteString(fmt.Sprintf("if data.%s != nil {\n", g.VariantName(variant)))
-		body.WriteString(fmt.Sprintf("\t\treturn %s(data.%s)\n",
-			g.FuncNameFromJSON(variant),
-			g.VariantName(variant),
-		))
-		body.WriteString(fmt.Sprintf("\t}"))
+		body.WriteString(fmt.Sprintf("\n"))
 	}
-	body.WriteString(fmt.Sprintf("\n"))
 
 	body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s unknown type: %%s\", data.Type)\n", errorContext))
 	body.WriteString(fmt.Sprintf("}\n\n"))
@@ -360,11 +516,55 @@ func (g *SerdeJSONUnion) GenerateUnionFromFunc(union *shape.UnionLike) ([]byte,
 	return body.Bytes(), nil
 }
 
+// GenerateUnionUpcastFunc generates function, that migrates value serialised with older version of union,
+// by calling {Name}UpcastFromV{N} functions, that must be declared next to union, for each version before current one.
+// Value without "$version" field is treated as version 1.
+func (g *SerdeJSONUnion) GenerateUnionUpcastFunc(union *shape.UnionLike, version int) string {
+	name := upcastFuncName(union)
+	errorContext := g.errorFuncContext(name)
+
+	body := &strings.Builder{}
+	body.WriteString(fmt.Sprintf("func %s(x []byte) ([]byte, error) {\n", name))
+	body.WriteString(fmt.Sprintf("\tversion, err := shared.JSONVersion(x)\n"))
+	body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+	body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+	body.WriteString(fmt.Sprintf("\t}\n"))
+	body.WriteString(fmt.Sprintf("\tif version > %d {\n", version))
+	body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s version %%d is newer than supported version %d\", version)\n", errorContext, version))
+	body.WriteString(fmt.Sprintf("\t}\n"))
+	if version > 1 {
+		body.WriteString(fmt.Sprintf("\tfor ; version < %d; version++ {\n", version))
+		body.WriteString(fmt.Sprintf("\t\tswitch version {\n"))
+		for v := 1; v < version; v++ {
+			body.WriteString(fmt.Sprintf("\t\tcase %d:\n", v))
+			body.WriteString(fmt.Sprintf("\t\t\tx, err = %sUpcastFromV%d(x)\n", union.Name, v))
+		}
+		body.WriteString(fmt.Sprintf("\t\t}\n"))
+		body.WriteString(fmt.Sprintf("\t\tif err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s upcast from version %%d; %%w\", version, err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("\t\t}\n"))
+		body.WriteString(fmt.Sprintf("\t}\n"))
+	}
+	body.WriteString(fmt.Sprintf("\treturn x, nil\n"))
+	body.WriteString(fmt.Sprintf("}\n\n"))
+
+	return body.String()
+}
+
+func upcastFuncName(union *shape.UnionLike) string {
+	return fmt.Sprintf("upcast%sJSON", union.Name)
+}
+
 func (g *SerdeJSONUnion) GenerateUnionToFunc(union *shape.UnionLike) ([]byte, error) {
 	body := &bytes.Buffer{}
 
 	errorContext := g.errorFuncContext(g.parametrisedf(union, "%sToJSON"))
 
+	encoding, version, err := g.unionEncoding(union)
+	if err != nil {
+		return nil, fmt.Errorf("generators.SerdeJSONUnion.GenerateUnionToFunc: %w", err)
+	}
+
 	body.WriteString(fmt.Sprintf("func %s(x %s) ([]byte, error) {\n",
 		g.constructionf(union, "%sToJSON"),
 		g.parametrisedf(union, "%s"),
@@ -382,10 +582,24 @@ func (g *SerdeJSONUnion) GenerateUnionToFunc(union *shape.UnionLike) ([]byte, er
 		body.WriteString(fmt.Sprintf("\t\t\tif err != nil {\n"))
 		body.WriteString(fmt.Sprintf("\t\t\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
 		body.WriteString(fmt.Sprintf("\t\t\t}\n"))
-		body.WriteString(fmt.Sprintf("\t\t\treturn json.Marshal(%s{\n", g.parametrisedf(union, "%sUnionJSON")))
-		body.WriteString(fmt.Sprintf("\t\t\t\tType: %q,\n", g.JSONVariantName(variant)))
-		body.WriteString(fmt.Sprintf("\t\t\t\t%s: body,\n", g.VariantName(variant)))
-		body.WriteString(fmt.Sprintf("\t\t\t})\n"))
+		switch encoding.Kind {
+		case shape.JSONUnionExternal, shape.JSONUnionAdjacent:
+			body.WriteString(fmt.Sprintf("\t\t\treturn json.Marshal(%s{\n", g.parametrisedf(union, "%sUnionJSON")))
+			body.WriteString(fmt.Sprintf("\t\t\t\tType: %q,\n", g.JSONVariantName(variant)))
+			if version > 0 {
+				body.WriteString(fmt.Sprintf("\t\t\t\tVersion: %d,\n", version))
+			}
+			if encoding.Kind == shape.JSONUnionAdjacent {
+				body.WriteString(fmt.Sprintf("\t\t\t\tContent: body,\n"))
+			} else {
+				body.WriteString(fmt.Sprintf("\t\t\t\t%s: body,\n", g.VariantName(variant)))
+			}
+			body.WriteString(fmt.Sprintf("\t\t\t})\n"))
+		case shape.JSONUnionInternal:
+			body.WriteString(fmt.Sprintf("\t\t\treturn shared.JSONPrependField(%q, %q, body)\n", encoding.Tag, g.JSONVariantName(variant)))
+		case shape.JSONUnionUntagged:
+			body.WriteString(fmt.Sprintf("\t\t\treturn body, nil\n"))
+		}
 		body.WriteString(fmt.Sprintf("\t\t},\n"))
 	}
 	body.WriteString(fmt.Sprintf("\t)\n"))
@@ -427,3 +641,12 @@ func (g *SerdeJSONUnion) GenerateVariantsFromToFunc(x *shape.UnionLike) ([]byte,
 
 	return body.Bytes(), nil
 }
+
+// joinArgs returns arguments, that follow other arguments of a call, like `, "a", "b"`.
+func joinArgs(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	return ", " + strings.Join(args, ", ")
+}
@@ -54,3 +54,145 @@ func TestSerdeJSONUnion_Generate_Generic(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, string(reference), string(result))
 }
+
+func TestSerdeJSONUnion_Generate_Versioned(t *testing.T) {
+	inferred, err := shape.InferFromFile("testutils/versioned.go")
+	assert.NoError(t, err)
+
+	union := inferred.RetrieveUnion("Article")
+	version, err := shape.TagUnionVersion(union.Tags)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, version)
+
+	g := NewSerdeJSONUnion(union)
+	assert.Equal(t, `func upcastArticleJSON(x []byte) ([]byte, error) {
+	version, err := shared.JSONVersion(x)
+	if err != nil {
+		return nil, fmt.Errorf("testutils.upcastArticleJSON: %w", err)
+	}
+	if version > 3 {
+		return nil, fmt.Errorf("testutils.upcastArticleJSON: version %d is newer than supported version 3", version)
+	}
+	for ; version < 3; version++ {
+		switch version {
+		case 1:
+			x, err = ArticleUpcastFromV1(x)
+		case 2:
+			x, err = ArticleUpcastFromV2(x)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("testutils.upcastArticleJSON: upcast from version %d; %w", version, err)
+		}
+	}
+	return x, nil
+}
+
+`, g.GenerateUnionUpcastFunc(union, version))
+
+	_, err = shape.TagUnionVersion(map[string]shape.Tag{
+		shape.TagUnionName: {Value: "Article", Options: []string{"version=0"}},
+	})
+	assert.ErrorContains(t, err, `version must be positive number, given "0"`)
+}
+
+func TestSerdeJSONUnion_Generate_Discriminator(t *testing.T) {
+	inferred, err := shape.InferFromFile("testutils/discriminator.go")
+	assert.NoError(t, err)
+
+	g := NewSerdeJSONUnion(inferred.RetrieveUnion("Shipment"))
+	result, err := g.GenerateUnionType(g.union)
+	assert.NoError(t, err)
+	assert.Equal(t, "type ShipmentUnionJSON struct {\n"+
+		"\tType string `json:\"$type,omitempty\"`\n"+
+		"\tDispatched json.RawMessage `json:\"shipment.dispatched,omitempty\"`\n"+
+		"\tDelivered json.RawMessage `json:\"testutils.Delivered,omitempty\"`\n"+
+		"\tDeliveredAlias1 json.RawMessage `json:\"testutils.Arrived,omitempty\"`\n"+
+		"\tDeliveredAlias2 json.RawMessage `json:\"logistics.Arrived,omitempty\"`\n"+
+		"}\n\n", string(result))
+}
+
+func TestSerdeJSONUnion_Generate_InvalidEncoding(t *testing.T) {
+	useCases := map[string]struct {
+		body string
+		err  string
+	}{
+		"unknown encoding": {
+			body: `package testutils
+
+//go:tag mkunion:"Figure" json:"nested"
+type (
+	Circle struct{}
+)
+`,
+			err: `union Figure has unknown encoding "nested"`,
+		},
+		"internally tagged variant that is not struct": {
+			body: `package testutils
+
+//go:tag mkunion:"Figure" json:"internal"
+type (
+	Circle struct{}
+	Label  string
+)
+`,
+			err: "union Figure is internally tagged, but variant Label is not a struct",
+		},
+		"internally tagged variant with field named as tag": {
+			body: `package testutils
+
+//go:tag mkunion:"Figure" json:"internal,tag=kind"
+type (
+	Circle struct {
+		Kind string ` + "`json:\"kind\"`" + `
+	}
+)
+`,
+			err: `union Figure is internally tagged, but field Circle.Kind has the same name as tag "kind"`,
+		},
+		"versioned union that is not externally tagged": {
+			body: `package testutils
+
+//go:tag mkunion:"Figure,version=2" json:"adjacent"
+type (
+	Circle struct{}
+)
+`,
+			err: "union Figure is versioned, which is supported only with external encoding, given adjacent",
+		},
+		"variants with the same name": {
+			body: `package testutils
+
+//go:tag mkunion:"Figure"
+type (
+	//go:tag variant:"shape"
+	Circle struct{}
+	//go:tag variant:"shape"
+	Square struct{}
+)
+`,
+			err: `union Figure has variants Circle and Square with the same name "shape"`,
+		},
+		"alias of other variant": {
+			body: `package testutils
+
+//go:tag mkunion:"Figure"
+type (
+	Circle struct{}
+	//go:tag alias:"Circle"
+	Square struct{}
+)
+`,
+			err: `union Figure has variants Circle and Square with the same name "testutils.Circle"`,
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			inferred, err := shape.InferFromFileWithContentBody(uc.body, "github.com/widmogrod/mkunion/x/generators/testutils")
+			assert.NoError(t, err)
+
+			g := NewSerdeJSONUnion(inferred.RetrieveUnion("Figure"))
+			_, err = g.Generate()
+			assert.ErrorContains(t, err, uc.err)
+		})
+	}
+}
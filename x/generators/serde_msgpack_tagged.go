@@ -0,0 +1,643 @@
+package generators
+
+import (
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"strings"
+)
+
+const (
+	unmarshalMsgpackMethodPrefix = "_unmarshalMsgpack"
+	marshalMsgpackMethodPrefix   = "_marshalMsgpack"
+)
+
+// NewSerdeMsgpackTagged generates MarshalMsgpack and UnmarshalMsgpack methods,
+// that don't use reflection. Structs are encoded as maps with the same keys as JSON serde.
+func NewSerdeMsgpackTagged(x shape.Shape) *SerdeMsgpackTagged {
+	return &SerdeMsgpackTagged{
+		shape:                 x,
+		skipImportsAndPackage: false,
+		didGenerateMethod:     make(map[string]bool),
+		pkgUsed: PkgMap{
+			"fmt":    "fmt",
+			"shared": "github.com/widmogrod/mkunion/x/shared",
+		},
+	}
+}
+
+type SerdeMsgpackTagged struct {
+	shape                 shape.Shape
+	skipImportsAndPackage bool
+
+	didGenerateMethod map[string]bool
+	pkgUsed           PkgMap
+}
+
+func (g *SerdeMsgpackTagged) SkipImportsAndPackage(flag bool) *SerdeMsgpackTagged {
+	g.skipImportsAndPackage = flag
+	return g
+}
+
+func (g *SerdeMsgpackTagged) Generate() (string, error) {
+	body := &strings.Builder{}
+
+	if !shape.IsWeekAlias(g.shape) {
+		body.WriteString(g.GenerateVarCasting())
+
+		marshalPart, err := g.GenerateMarshalMsgpack(g.shape)
+		if err != nil {
+			return "", fmt.Errorf("generators.SerdeMsgpackTagged.Generate: when generating marshal %w", err)
+		}
+		body.WriteString(marshalPart)
+
+		unmarshalPart, err := g.GenerateUnmarshalMsgpack(g.shape)
+		if err != nil {
+			return "", fmt.Errorf("generators.SerdeMsgpackTagged.Generate: when generating unmarshal %w", err)
+		}
+		body.WriteString(unmarshalPart)
+	}
+
+	head := &strings.Builder{}
+	if !g.skipImportsAndPackage {
+		head.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.shape)))
+		head.WriteString(GenerateImports(g.ExtractImports(g.shape)))
+	}
+
+	if head.Len() > 0 {
+		head.WriteString(body.String())
+		return head.String(), nil
+	} else {
+		return body.String(), nil
+	}
+}
+
+func (g *SerdeMsgpackTagged) ExtractImports(x shape.Shape) PkgMap {
+	pkgMap := shape.ExtractPkgImportNames(x)
+	if pkgMap == nil {
+		pkgMap = make(map[string]string)
+	}
+
+	// add default and necessary imports
+	pkgMap = MergePkgMaps(pkgMap, g.pkgUsed)
+
+	// remove self from importing
+	delete(pkgMap, shape.ToGoPkgName(x))
+	return pkgMap
+}
+
+func (g *SerdeMsgpackTagged) GenerateVarCasting() string {
+	typeName := shape.ToGoTypeName(g.shape,
+		shape.WithInstantiation(),
+		shape.WithRootPkgName(shape.ToGoPkgName(g.shape)),
+	)
+
+	result := &strings.Builder{}
+	result.WriteString("var (\n")
+	result.WriteString(fmt.Sprintf("\t_ shared.MsgpackUnmarshaler = (*%s)(nil)\n", typeName))
+	result.WriteString(fmt.Sprintf("\t_ shared.MsgpackMarshaler   = (*%s)(nil)\n", typeName))
+	result.WriteString(")\n\n")
+	return result.String()
+}
+
+func (g *SerdeMsgpackTagged) rootPkgName() string {
+	return shape.ToGoPkgName(g.shape)
+}
+
+func (g *SerdeMsgpackTagged) rootTypeName() string {
+	return shape.ToGoTypeName(g.shape,
+		shape.WithRootPkgName(shape.ToGoPkgName(g.shape)),
+	)
+}
+
+func (g *SerdeMsgpackTagged) typeName(x shape.Shape) string {
+	return shape.ToGoTypeName(x, shape.WithRootPkgName(g.rootPkgName()))
+}
+
+func (g *SerdeMsgpackTagged) errorContext(name string) string {
+	return fmt.Sprintf(`%s: %s.%s:`, g.rootPkgName(), g.rootTypeName(), name)
+}
+
+func (g *SerdeMsgpackTagged) methodNameWithPrefix(x shape.Shape, prefix string) string {
+	return fmt.Sprintf("%s%s", prefix, removeNonAlpha.Replace(g.typeName(x)))
+}
+
+// markGenerated prevents infinite recursion, and generation of the same method twice
+func (g *SerdeMsgpackTagged) markGenerated(methodName string) bool {
+	if g.didGenerateMethod[methodName] {
+		return true
+	}
+
+	g.didGenerateMethod[methodName] = true
+	return false
+}
+
+func (g *SerdeMsgpackTagged) GenerateMarshalMsgpack(x shape.Shape) (string, error) {
+	result := &strings.Builder{}
+	result.WriteString(fmt.Sprintf("func (r *%s) MarshalMsgpack() ([]byte, error) {\n", g.rootTypeName()))
+	result.WriteString(fmt.Sprintf("\tif r == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn shared.MsgpackAppendNil(nil), nil\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\treturn r.%s(nil, *r)\n", g.methodNameWithPrefix(x, marshalMsgpackMethodPrefix)))
+	result.WriteString("}\n")
+
+	methods, err := g.GenerateMarshalMsgpackMethods(x)
+	if err != nil {
+		return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateMarshalMsgpack: %w", err)
+	}
+	result.WriteString(methods)
+
+	return result.String(), nil
+}
+
+func (g *SerdeMsgpackTagged) GenerateUnmarshalMsgpack(x shape.Shape) (string, error) {
+	result := &strings.Builder{}
+	result.WriteString(fmt.Sprintf("func (r *%s) UnmarshalMsgpack(data []byte) error {\n", g.rootTypeName()))
+	result.WriteString(fmt.Sprintf("\tresult, err := r.%s(shared.NewMsgpackReader(data))\n", g.methodNameWithPrefix(x, unmarshalMsgpackMethodPrefix)))
+	result.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s %%w\", err)\n", g.errorContext("UnmarshalMsgpack")))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\t*r = result\n"))
+	result.WriteString(fmt.Sprintf("\treturn nil\n"))
+	result.WriteString(fmt.Sprintf("}\n"))
+
+	methods, err := g.GenerateUnmarshalMsgpackMethods(x)
+	if err != nil {
+		return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateUnmarshalMsgpack: %w", err)
+	}
+	result.WriteString(methods)
+
+	return result.String(), nil
+}
+
+func (g *SerdeMsgpackTagged) GenerateMarshalMsgpackMethods(x shape.Shape) (string, error) {
+	methodName := g.methodNameWithPrefix(x, marshalMsgpackMethodPrefix)
+	if g.markGenerated(methodName) {
+		return "", nil
+	}
+
+	if shape.IsWeekAlias(x) {
+		return "", nil
+	}
+
+	rootTypeName := g.rootTypeName()
+	typeName := g.typeName(x)
+	errorContext := g.errorContext(methodName)
+
+	methodWrap := func(body *strings.Builder) string {
+		result := &strings.Builder{}
+		result.WriteString(fmt.Sprintf("func (r *%s) %s(b []byte, x %s) ([]byte, error) {\n", rootTypeName, methodName, typeName))
+		result.WriteString(padLeftTabs(1, body.String()))
+		result.WriteString("}\n")
+		return result.String()
+	}
+
+	appendCall := func(body *strings.Builder, call string) {
+		body.WriteString(fmt.Sprintf("data, err := %s\n", call))
+		body.WriteString(fmt.Sprintf("if err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("}\n"))
+		body.WriteString(fmt.Sprintf("return append(b, data...), nil\n"))
+	}
+
+	return shape.MatchShapeR2(
+		x,
+		func(y *shape.Any) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("result, err := shared.MsgpackAppendAny(b, x)\n"))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return result, nil\n"))
+			return methodWrap(body), nil
+		},
+		func(y *shape.RefName) (string, error) {
+			body := &strings.Builder{}
+			appendCall(body, fmt.Sprintf("shared.MsgpackMarshal[%s](x)", typeName))
+			return methodWrap(body), nil
+		},
+		func(y *shape.PointerLike) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("if x == nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn shared.MsgpackAppendNil(b), nil\n"))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return r.%s(b, *x)\n", g.methodNameWithPrefix(y.Type, marshalMsgpackMethodPrefix)))
+
+			methods, err := g.GenerateMarshalMsgpackMethods(y.Type)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateMarshalMsgpackMethods: pointer methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.AliasLike) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("return r.%s(b, %s(x))\n",
+				g.methodNameWithPrefix(y.Type, marshalMsgpackMethodPrefix),
+				g.typeName(y.Type),
+			))
+
+			methods, err := g.GenerateMarshalMsgpackMethods(y.Type)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateMarshalMsgpackMethods: alias methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.PrimitiveLike) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(shape.MatchPrimitiveKindR1(
+				y.Kind,
+				func(z *shape.BooleanLike) string {
+					return "return shared.MsgpackAppendBool(b, bool(x)), nil\n"
+				},
+				func(z *shape.StringLike) string {
+					return "return shared.MsgpackAppendString(b, string(x)), nil\n"
+				},
+				func(z *shape.NumberLike) string {
+					switch z.Kind.(type) {
+					case *shape.Float32:
+						return "return shared.MsgpackAppendFloat32(b, float32(x)), nil\n"
+					case *shape.Float64, nil:
+						return "return shared.MsgpackAppendFloat64(b, float64(x)), nil\n"
+					case *shape.UInt, *shape.UInt8, *shape.UInt16, *shape.UInt32, *shape.UInt64:
+						return "return shared.MsgpackAppendUint(b, uint64(x)), nil\n"
+					}
+					return "return shared.MsgpackAppendInt(b, int64(x)), nil\n"
+				},
+			))
+			return methodWrap(body), nil
+		},
+		func(y *shape.ListLike) (string, error) {
+			body := &strings.Builder{}
+
+			if shape.IsBinary(y) && y.ArrayLen == nil {
+				body.WriteString(fmt.Sprintf("return shared.MsgpackAppendBytes(b, x), nil\n"))
+				return methodWrap(body), nil
+			}
+
+			if y.ArrayLen == nil {
+				body.WriteString(fmt.Sprintf("if x == nil {\n"))
+				body.WriteString(fmt.Sprintf("\treturn shared.MsgpackAppendNil(b), nil\n"))
+				body.WriteString(fmt.Sprintf("}\n"))
+			}
+			body.WriteString(fmt.Sprintf("b = shared.MsgpackAppendArrayHeader(b, len(x))\n"))
+			body.WriteString(fmt.Sprintf("var err error\n"))
+			body.WriteString(fmt.Sprintf("for i, v := range x {\n"))
+			body.WriteString(fmt.Sprintf("\tb, err = r.%s(b, v)\n", g.methodNameWithPrefix(y.Element, marshalMsgpackMethodPrefix)))
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s at index %%d; %%w\", i, err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return b, nil\n"))
+
+			methods, err := g.GenerateMarshalMsgpackMethods(y.Element)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateMarshalMsgpackMethods: list methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.MapLike) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("if x == nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn shared.MsgpackAppendNil(b), nil\n"))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("b = shared.MsgpackAppendMapHeader(b, len(x))\n"))
+			body.WriteString(fmt.Sprintf("var err error\n"))
+
+			if protoIsOrderedKey(y.Key) {
+				// keep encoding deterministic, so that the same value produces the same blob
+				g.pkgUsed["maps"] = "maps"
+				g.pkgUsed["slices"] = "slices"
+				body.WriteString(fmt.Sprintf("for _, k := range slices.Sorted(maps.Keys(x)) {\n"))
+				body.WriteString(fmt.Sprintf("\tv := x[k]\n"))
+			} else {
+				body.WriteString(fmt.Sprintf("for k, v := range x {\n"))
+			}
+
+			body.WriteString(fmt.Sprintf("\tb, err = r.%s(b, k)\n", g.methodNameWithPrefix(y.Key, marshalMsgpackMethodPrefix)))
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s key; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			body.WriteString(fmt.Sprintf("\tb, err = r.%s(b, v)\n", g.methodNameWithPrefix(y.Val, marshalMsgpackMethodPrefix)))
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s value; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return b, nil\n"))
+
+			keyMethods, err := g.GenerateMarshalMsgpackMethods(y.Key)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateMarshalMsgpackMethods: key methods; %w", err)
+			}
+
+			valMethods, err := g.GenerateMarshalMsgpackMethods(y.Val)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateMarshalMsgpackMethods: value methods; %w", err)
+			}
+
+			return methodWrap(body) + keyMethods + valMethods, nil
+		},
+		func(y *shape.StructLike) (string, error) {
+			body := &strings.Builder{}
+
+			if len(y.Fields) == 0 {
+				body.WriteString(fmt.Sprintf("return shared.MsgpackAppendMapHeader(b, 0), nil\n"))
+				return methodWrap(body), nil
+			}
+
+			// nil pointers are skipped like in JSON, so number of fields is known only at the end
+			body.WriteString(fmt.Sprintf("var fields []byte\n"))
+			body.WriteString(fmt.Sprintf("var count int\n"))
+			body.WriteString(fmt.Sprintf("var err error\n"))
+			for _, field := range y.Fields {
+				fieldName := shape.TagGetValue(field.Tags, "json", field.Name)
+
+				indent := ""
+				if shape.IsPointer(field.Type) {
+					body.WriteString(fmt.Sprintf("if x.%s != nil {\n", field.Name))
+					indent = "\t"
+				}
+				body.WriteString(fmt.Sprintf("%sfields = shared.MsgpackAppendString(fields, %q)\n", indent, fieldName))
+				body.WriteString(fmt.Sprintf("%sfields, err = r.%s(fields, x.%s)\n", indent, g.methodNameWithPrefix(field.Type, marshalMsgpackMethodPrefix), field.Name))
+				body.WriteString(fmt.Sprintf("%sif err != nil {\n", indent))
+				body.WriteString(fmt.Sprintf("%s\treturn nil, fmt.Errorf(\"%s field name %s; %%w\", err)\n", indent, errorContext, field.Name))
+				body.WriteString(fmt.Sprintf("%s}\n", indent))
+				body.WriteString(fmt.Sprintf("%scount++\n", indent))
+				if shape.IsPointer(field.Type) {
+					body.WriteString(fmt.Sprintf("}\n"))
+				}
+			}
+			body.WriteString(fmt.Sprintf("b = shared.MsgpackAppendMapHeader(b, count)\n"))
+			body.WriteString(fmt.Sprintf("return append(b, fields...), nil\n"))
+
+			methods := ""
+			for _, field := range y.Fields {
+				fieldMethods, err := g.GenerateMarshalMsgpackMethods(field.Type)
+				if err != nil {
+					return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateMarshalMsgpackMethods: field %s methods; %w", field.Name, err)
+				}
+				methods += fieldMethods
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.UnionLike) (string, error) {
+			body := &strings.Builder{}
+			appendCall(body, fmt.Sprintf("%sToMsgpack(x)", typeName))
+			return methodWrap(body), nil
+		},
+	)
+}
+
+func (g *SerdeMsgpackTagged) GenerateUnmarshalMsgpackMethods(x shape.Shape) (string, error) {
+	methodName := g.methodNameWithPrefix(x, unmarshalMsgpackMethodPrefix)
+	if g.markGenerated(methodName) {
+		return "", nil
+	}
+
+	if shape.IsWeekAlias(x) {
+		return "", nil
+	}
+
+	rootTypeName := g.rootTypeName()
+	typeName := g.typeName(x)
+	errorContext := g.errorContext(methodName)
+
+	methodWrap := func(body *strings.Builder) string {
+		result := &strings.Builder{}
+		result.WriteString(fmt.Sprintf("func (r *%s) %s(d *shared.MsgpackReader) (%s, error) {\n", rootTypeName, methodName, typeName))
+		result.WriteString(padLeftTabs(1, body.String()))
+		result.WriteString("}\n")
+		return result.String()
+	}
+
+	readRaw := func(body *strings.Builder) {
+		body.WriteString(fmt.Sprintf("data, err := d.ReadRaw()\n"))
+		body.WriteString(fmt.Sprintf("if err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("}\n"))
+	}
+
+	returnNilWhenNil := func(body *strings.Builder) {
+		body.WriteString(fmt.Sprintf("if d.IsNil() {\n"))
+		body.WriteString(fmt.Sprintf("\treturn nil, d.ReadNil()\n"))
+		body.WriteString(fmt.Sprintf("}\n"))
+	}
+
+	return shape.MatchShapeR2(
+		x,
+		func(y *shape.Any) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("result, err := d.ReadAny()\n"))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return result, nil\n"))
+			return methodWrap(body), nil
+		},
+		func(y *shape.RefName) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("var result %s\n", typeName))
+			body.WriteString(fmt.Sprintf("data, err := d.ReadRaw()\n"))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn result, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("result, err = shared.MsgpackUnmarshal[%s](data)\n", typeName))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn result, fmt.Errorf(\"%s native ref unwrap; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return result, nil\n"))
+			return methodWrap(body), nil
+		},
+		func(y *shape.PointerLike) (string, error) {
+			body := &strings.Builder{}
+			returnNilWhenNil(body)
+			body.WriteString(fmt.Sprintf("result, err := r.%s(d)\n", g.methodNameWithPrefix(y.Type, unmarshalMsgpackMethodPrefix)))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s pointer; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return &result, nil\n"))
+
+			methods, err := g.GenerateUnmarshalMsgpackMethods(y.Type)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateUnmarshalMsgpackMethods: pointer methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.AliasLike) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("result, err := r.%s(d)\n", g.methodNameWithPrefix(y.Type, unmarshalMsgpackMethodPrefix)))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn %s(result), fmt.Errorf(\"%s alias; %%w\", err)\n", typeName, errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return %s(result), nil\n", typeName))
+
+			methods, err := g.GenerateUnmarshalMsgpackMethods(y.Type)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateUnmarshalMsgpackMethods: alias methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.PrimitiveLike) (string, error) {
+			read := shape.MatchPrimitiveKindR1(
+				y.Kind,
+				func(z *shape.BooleanLike) string {
+					return "d.ReadBool()"
+				},
+				func(z *shape.StringLike) string {
+					return "d.ReadString()"
+				},
+				func(z *shape.NumberLike) string {
+					switch z.Kind.(type) {
+					case *shape.Float32:
+						return "d.ReadFloat32()"
+					case *shape.Float64, nil:
+						return "d.ReadFloat64()"
+					case *shape.UInt, *shape.UInt8, *shape.UInt16, *shape.UInt32, *shape.UInt64:
+						return "d.ReadUint64()"
+					}
+					return "d.ReadInt64()"
+				},
+			)
+
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("result, err := %s\n", read))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn %s(result), fmt.Errorf(\"%s; %%w\", err)\n", typeName, errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return %s(result), nil\n", typeName))
+			return methodWrap(body), nil
+		},
+		func(y *shape.ListLike) (string, error) {
+			body := &strings.Builder{}
+
+			if shape.IsBinary(y) && y.ArrayLen == nil {
+				body.WriteString(fmt.Sprintf("data, err := d.ReadBytes()\n"))
+				body.WriteString(fmt.Sprintf("if err != nil {\n"))
+				body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+				body.WriteString(fmt.Sprintf("}\n"))
+				body.WriteString(fmt.Sprintf("if data == nil {\n"))
+				body.WriteString(fmt.Sprintf("\treturn nil, nil\n"))
+				body.WriteString(fmt.Sprintf("}\n"))
+				body.WriteString(fmt.Sprintf("result := make(%s, len(data))\n", typeName))
+				body.WriteString(fmt.Sprintf("copy(result, data)\n"))
+				body.WriteString(fmt.Sprintf("return result, nil\n"))
+				return methodWrap(body), nil
+			}
+
+			if y.ArrayLen == nil {
+				returnNilWhenNil(body)
+			}
+			body.WriteString(fmt.Sprintf("var result %s\n", typeName))
+			body.WriteString(fmt.Sprintf("n, err := d.ReadArrayHeader()\n"))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn result, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			if y.ArrayLen == nil {
+				body.WriteString(fmt.Sprintf("result = make(%s, 0, n)\n", typeName))
+			}
+			body.WriteString(fmt.Sprintf("for i := 0; i < n; i++ {\n"))
+			body.WriteString(fmt.Sprintf("\titem, err := r.%s(d)\n", g.methodNameWithPrefix(y.Element, unmarshalMsgpackMethodPrefix)))
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn result, fmt.Errorf(\"%s at index %%d; %%w\", i, err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			if y.ArrayLen == nil {
+				body.WriteString(fmt.Sprintf("\tresult = append(result, item)\n"))
+			} else {
+				body.WriteString(fmt.Sprintf("\tif i < len(result) {\n"))
+				body.WriteString(fmt.Sprintf("\t\tresult[i] = item\n"))
+				body.WriteString(fmt.Sprintf("\t}\n"))
+			}
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return result, nil\n"))
+
+			methods, err := g.GenerateUnmarshalMsgpackMethods(y.Element)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateUnmarshalMsgpackMethods: list methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.MapLike) (string, error) {
+			body := &strings.Builder{}
+			returnNilWhenNil(body)
+			body.WriteString(fmt.Sprintf("n, err := d.ReadMapHeader()\n"))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("result := make(%s, n)\n", typeName))
+			body.WriteString(fmt.Sprintf("for i := 0; i < n; i++ {\n"))
+			body.WriteString(fmt.Sprintf("\tkey, err := r.%s(d)\n", g.methodNameWithPrefix(y.Key, unmarshalMsgpackMethodPrefix)))
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn result, fmt.Errorf(\"%s key; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			body.WriteString(fmt.Sprintf("\tvalue, err := r.%s(d)\n", g.methodNameWithPrefix(y.Val, unmarshalMsgpackMethodPrefix)))
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn result, fmt.Errorf(\"%s value; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			body.WriteString(fmt.Sprintf("\tresult[key] = value\n"))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return result, nil\n"))
+
+			keyMethods, err := g.GenerateUnmarshalMsgpackMethods(y.Key)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateUnmarshalMsgpackMethods: key methods; %w", err)
+			}
+
+			valMethods, err := g.GenerateUnmarshalMsgpackMethods(y.Val)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateUnmarshalMsgpackMethods: value methods; %w", err)
+			}
+
+			return methodWrap(body) + keyMethods + valMethods, nil
+		},
+		func(y *shape.StructLike) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("result := %s{}\n", typeName))
+			body.WriteString(fmt.Sprintf("n, err := d.ReadMapHeader()\n"))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn result, fmt.Errorf(\"%s map header; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("for i := 0; i < n; i++ {\n"))
+			body.WriteString(fmt.Sprintf("\tkey, err := d.ReadString()\n"))
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn result, fmt.Errorf(\"%s field key; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			body.WriteString(fmt.Sprintf("\tswitch key {\n"))
+			for _, field := range y.Fields {
+				fieldName := shape.TagGetValue(field.Tags, "json", field.Name)
+
+				body.WriteString(fmt.Sprintf("\tcase %q:\n", fieldName))
+				body.WriteString(fmt.Sprintf("\t\tresult.%s, err = r.%s(d)\n", field.Name, g.methodNameWithPrefix(field.Type, unmarshalMsgpackMethodPrefix)))
+			}
+			body.WriteString(fmt.Sprintf("\tdefault:\n"))
+			body.WriteString(fmt.Sprintf("\t\terr = d.Skip()\n"))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn result, fmt.Errorf(\"%s field %%s; %%w\", key, err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return result, nil\n"))
+
+			methods := ""
+			for _, field := range y.Fields {
+				fieldMethods, err := g.GenerateUnmarshalMsgpackMethods(field.Type)
+				if err != nil {
+					return "", fmt.Errorf("generators.SerdeMsgpackTagged.GenerateUnmarshalMsgpackMethods: field %s methods; %w", field.Name, err)
+				}
+				methods += fieldMethods
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.UnionLike) (string, error) {
+			body := &strings.Builder{}
+			readRaw(body)
+			body.WriteString(fmt.Sprintf("return %sFromMsgpack(data)\n", typeName))
+			return methodWrap(body), nil
+		},
+	)
+}
@@ -0,0 +1,138 @@
+package generators
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+)
+
+func TestNewSerdeMsgpackTagged_Struct(t *testing.T) {
+	generator := NewSerdeMsgpackTagged(&shape.StructLike{
+		Name:          "Deleted",
+		PkgName:       "pkg",
+		PkgImportName: "example.com/pkg",
+		Fields: []*shape.FieldLike{
+			{
+				Name: "ID",
+				Type: &shape.PrimitiveLike{Kind: &shape.StringLike{}},
+				Tags: map[string]shape.Tag{"json": {Value: "id"}},
+			},
+			{
+				Name: "At",
+				Type: &shape.PointerLike{Type: &shape.PrimitiveLike{Kind: &shape.NumberLike{Kind: &shape.Int64{}}}},
+			},
+		},
+	})
+
+	result, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, `package pkg
+
+import (
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shared"
+)
+
+var (
+	_ shared.MsgpackUnmarshaler = (*Deleted)(nil)
+	_ shared.MsgpackMarshaler   = (*Deleted)(nil)
+)
+
+func (r *Deleted) MarshalMsgpack() ([]byte, error) {
+	if r == nil {
+		return shared.MsgpackAppendNil(nil), nil
+	}
+	return r._marshalMsgpackDeleted(nil, *r)
+}
+func (r *Deleted) _marshalMsgpackDeleted(b []byte, x Deleted) ([]byte, error) {
+	var fields []byte
+	var count int
+	var err error
+	fields = shared.MsgpackAppendString(fields, "id")
+	fields, err = r._marshalMsgpackstring(fields, x.ID)
+	if err != nil {
+		return nil, fmt.Errorf("pkg: Deleted._marshalMsgpackDeleted: field name ID; %w", err)
+	}
+	count++
+	if x.At != nil {
+		fields = shared.MsgpackAppendString(fields, "At")
+		fields, err = r._marshalMsgpackPtrint64(fields, x.At)
+		if err != nil {
+			return nil, fmt.Errorf("pkg: Deleted._marshalMsgpackDeleted: field name At; %w", err)
+		}
+		count++
+	}
+	b = shared.MsgpackAppendMapHeader(b, count)
+	return append(b, fields...), nil
+}
+func (r *Deleted) _marshalMsgpackstring(b []byte, x string) ([]byte, error) {
+	return shared.MsgpackAppendString(b, string(x)), nil
+}
+func (r *Deleted) _marshalMsgpackPtrint64(b []byte, x *int64) ([]byte, error) {
+	if x == nil {
+		return shared.MsgpackAppendNil(b), nil
+	}
+	return r._marshalMsgpackint64(b, *x)
+}
+func (r *Deleted) _marshalMsgpackint64(b []byte, x int64) ([]byte, error) {
+	return shared.MsgpackAppendInt(b, int64(x)), nil
+}
+func (r *Deleted) UnmarshalMsgpack(data []byte) error {
+	result, err := r._unmarshalMsgpackDeleted(shared.NewMsgpackReader(data))
+	if err != nil {
+		return fmt.Errorf("pkg: Deleted.UnmarshalMsgpack: %w", err)
+	}
+	*r = result
+	return nil
+}
+func (r *Deleted) _unmarshalMsgpackDeleted(d *shared.MsgpackReader) (Deleted, error) {
+	result := Deleted{}
+	n, err := d.ReadMapHeader()
+	if err != nil {
+		return result, fmt.Errorf("pkg: Deleted._unmarshalMsgpackDeleted: map header; %w", err)
+	}
+	for i := 0; i < n; i++ {
+		key, err := d.ReadString()
+		if err != nil {
+			return result, fmt.Errorf("pkg: Deleted._unmarshalMsgpackDeleted: field key; %w", err)
+		}
+		switch key {
+		case "id":
+			result.ID, err = r._unmarshalMsgpackstring(d)
+		case "At":
+			result.At, err = r._unmarshalMsgpackPtrint64(d)
+		default:
+			err = d.Skip()
+		}
+		if err != nil {
+			return result, fmt.Errorf("pkg: Deleted._unmarshalMsgpackDeleted: field %s; %w", key, err)
+		}
+	}
+	return result, nil
+}
+func (r *Deleted) _unmarshalMsgpackstring(d *shared.MsgpackReader) (string, error) {
+	result, err := d.ReadString()
+	if err != nil {
+		return string(result), fmt.Errorf("pkg: Deleted._unmarshalMsgpackstring:; %w", err)
+	}
+	return string(result), nil
+}
+func (r *Deleted) _unmarshalMsgpackPtrint64(d *shared.MsgpackReader) (*int64, error) {
+	if d.IsNil() {
+		return nil, d.ReadNil()
+	}
+	result, err := r._unmarshalMsgpackint64(d)
+	if err != nil {
+		return nil, fmt.Errorf("pkg: Deleted._unmarshalMsgpackPtrint64: pointer; %w", err)
+	}
+	return &result, nil
+}
+func (r *Deleted) _unmarshalMsgpackint64(d *shared.MsgpackReader) (int64, error) {
+	result, err := d.ReadInt64()
+	if err != nil {
+		return int64(result), fmt.Errorf("pkg: Deleted._unmarshalMsgpackint64:; %w", err)
+	}
+	return int64(result), nil
+}
+`, result)
+}
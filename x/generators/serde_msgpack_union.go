@@ -0,0 +1,240 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+)
+
+// NewSerdeMsgpackUnion generates msgpack serialisation for union,
+// variant is discriminated the same way as in JSON serde, with "$type" key and variant payload.
+func NewSerdeMsgpackUnion(union *shape.UnionLike) *SerdeMsgpackUnion {
+	return &SerdeMsgpackUnion{
+		union:                 union,
+		skipImportsAndPackage: false,
+		skipInitFunc:          false,
+		pkgUsed: PkgMap{
+			"fmt":    "fmt",
+			"shared": "github.com/widmogrod/mkunion/x/shared",
+		},
+	}
+}
+
+type SerdeMsgpackUnion struct {
+	union                 *shape.UnionLike
+	skipImportsAndPackage bool
+	skipInitFunc          bool
+	pkgUsed               PkgMap
+}
+
+func (g *SerdeMsgpackUnion) SkipImportsAndPackage(x bool) {
+	g.skipImportsAndPackage = x
+}
+
+func (g *SerdeMsgpackUnion) SkipInitFunc(flag bool) *SerdeMsgpackUnion {
+	g.skipInitFunc = flag
+	return g
+}
+
+func (g *SerdeMsgpackUnion) ExtractImports(x shape.Shape) PkgMap {
+	pkgMap := shape.ExtractPkgImportNames(x)
+	if pkgMap == nil {
+		pkgMap = make(map[string]string)
+	}
+
+	// add default and necessary imports
+	pkgMap = MergePkgMaps(pkgMap, g.pkgUsed)
+
+	// remove self from importing
+	delete(pkgMap, shape.ToGoPkgName(x))
+	return pkgMap
+}
+
+func (g *SerdeMsgpackUnion) ExtractImportFuncs(s shape.Shape) []string {
+	return []string{
+		fmt.Sprintf("shared.MsgpackMarshallerRegister(%q, %s, %s)",
+			shape.ToGoTypeName(s,
+				shape.WithPkgImportName(),
+				shape.WithInstantiation(),
+			),
+			StrInstantiatef(g.union.PkgName, s, "%sFromMsgpack"),
+			StrInstantiatef(g.union.PkgName, s, "%sToMsgpack"),
+		),
+	}
+}
+
+func (g *SerdeMsgpackUnion) Generate() ([]byte, error) {
+	body := &bytes.Buffer{}
+
+	_, err := shape.VariantNames(g.union)
+	if err != nil {
+		return nil, fmt.Errorf("generators.SerdeMsgpackUnion.Generate: %w", err)
+	}
+
+	body.Write(g.GenerateUnionFromFunc())
+	body.Write(g.GenerateUnionToFunc())
+
+	variants, err := g.GenerateVariantsFromToFunc()
+	if err != nil {
+		return nil, fmt.Errorf("generators.SerdeMsgpackUnion.Generate: when generating funcs for variant; %w", err)
+	}
+	body.Write(variants)
+
+	head := &bytes.Buffer{}
+	if !g.skipImportsAndPackage {
+		head.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.union)))
+		head.WriteString(GenerateImports(g.ExtractImports(g.union)))
+	}
+
+	if !g.skipInitFunc {
+		head.WriteString(GenerateInitFunc(g.ExtractImportFuncs(g.union)))
+	}
+
+	if head.Len() > 0 {
+		head.Write(body.Bytes())
+		return head.Bytes(), nil
+	} else {
+		return body.Bytes(), nil
+	}
+}
+
+func (g *SerdeMsgpackUnion) parametrisedf(x shape.Shape, template string) string {
+	return (&SerdeJSONUnion{union: g.union}).parametrisedf(x, template)
+}
+
+func (g *SerdeMsgpackUnion) constructionf(x shape.Shape, template string) string {
+	return (&SerdeJSONUnion{union: g.union}).constructionf(x, template)
+}
+
+// variantName must be the same as in JSON serde, so that both formats discriminate variants alike
+func (g *SerdeMsgpackUnion) variantName(x shape.Shape) string {
+	return (&SerdeJSONUnion{union: g.union}).JSONVariantName(x)
+}
+
+func (g *SerdeMsgpackUnion) errorFuncContext(name string) string {
+	return fmt.Sprintf(`%s.%s:`, g.union.PkgName, name)
+}
+
+func (g *SerdeMsgpackUnion) GenerateUnionFromFunc() []byte {
+	body := &bytes.Buffer{}
+
+	errorContext := g.errorFuncContext(g.parametrisedf(g.union, "%sFromMsgpack"))
+
+	body.WriteString(fmt.Sprintf("func %s(x []byte) (%s, error) {\n",
+		g.constructionf(g.union, "%sFromMsgpack"),
+		g.parametrisedf(g.union, "%s"),
+	))
+	body.WriteString(fmt.Sprintf("\tif len(x) == 0 || shared.NewMsgpackReader(x).IsNil() {\n"))
+	body.WriteString(fmt.Sprintf("\t\treturn nil, nil\n"))
+	body.WriteString(fmt.Sprintf("\t}\n"))
+	body.WriteString(fmt.Sprintf("\tkind, payloads, err := shared.MsgpackDecodeUnion(x)\n"))
+	body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+	body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+	body.WriteString(fmt.Sprintf("\t}\n\n"))
+	body.WriteString(fmt.Sprintf("\tswitch kind {\n"))
+	for _, variant := range g.union.Variant {
+		name := g.variantName(variant)
+		body.WriteString(fmt.Sprintf("\tcase %q:\n", name))
+		body.WriteString(fmt.Sprintf("\t\treturn %s(payloads[%q])\n", g.parametrisedf(variant, "%sFromMsgpack"), name))
+		for _, alias := range shape.VariantAliases(variant) {
+			body.WriteString(fmt.Sprintf("\tcase %q:\n", alias))
+			body.WriteString(fmt.Sprintf("\t\treturn %s(payloads[%q])\n", g.parametrisedf(variant, "%sFromMsgpack"), alias))
+		}
+	}
+	body.WriteString(fmt.Sprintf("\t}\n\n"))
+
+	for i, variant := range g.union.Variant {
+		if i > 0 {
+			body.WriteString(fmt.Sprintf(" else "))
+		} else {
+			body.WriteString(fmt.Sprintf("\t"))
+		}
+
+		body.WriteString(fmt.Sprintf("if payload, ok := payloads[%q]; ok {\n", g.variantName(variant)))
+		body.WriteString(fmt.Sprintf("\t\treturn %s(payload)\n", g.parametrisedf(variant, "%sFromMsgpack")))
+		body.WriteString(fmt.Sprintf("\t}"))
+		for _, alias := range shape.VariantAliases(variant) {
+			body.WriteString(fmt.Sprintf(" else if payload, ok := payloads[%q]; ok {\n", alias))
+			body.WriteString(fmt.Sprintf("\t\treturn %s(payload)\n", g.parametrisedf(variant, "%sFromMsgpack")))
+			body.WriteString(fmt.Sprintf("\t}"))
+		}
+	}
+	body.WriteString(fmt.Sprintf("\n"))
+
+	body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s unknown type: %%s\", kind)\n", errorContext))
+	body.WriteString(fmt.Sprintf("}\n\n"))
+
+	return body.Bytes()
+}
+
+func (g *SerdeMsgpackUnion) GenerateUnionToFunc() []byte {
+	body := &bytes.Buffer{}
+
+	errorContext := g.errorFuncContext(g.parametrisedf(g.union, "%sToMsgpack"))
+
+	body.WriteString(fmt.Sprintf("func %s(x %s) ([]byte, error) {\n",
+		g.constructionf(g.union, "%sToMsgpack"),
+		g.parametrisedf(g.union, "%s"),
+	))
+	body.WriteString(fmt.Sprintf("\tif x == nil {\n"))
+	body.WriteString(fmt.Sprintf("\t\treturn shared.MsgpackAppendNil(nil), nil\n"))
+	body.WriteString(fmt.Sprintf("\t}\n"))
+
+	body.WriteString(fmt.Sprintf("\treturn %s(\n", MatchUnionFuncName(g.union, 2)))
+	body.WriteString(fmt.Sprintf("\t\tx,\n"))
+	for _, variant := range g.union.Variant {
+		body.WriteString(fmt.Sprintf("\t\tfunc (y *%s) ([]byte, error) {\n", g.parametrisedf(variant, "%s")))
+		body.WriteString(fmt.Sprintf("\t\t\tbody, err := %s(y)\n", g.parametrisedf(variant, "%sToMsgpack")))
+		body.WriteString(fmt.Sprintf("\t\t\tif err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\t\t\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("\t\t\t}\n"))
+		body.WriteString(fmt.Sprintf("\t\t\treturn shared.MsgpackEncodeUnion(%q, body), nil\n", g.variantName(variant)))
+		body.WriteString(fmt.Sprintf("\t\t},\n"))
+	}
+	body.WriteString(fmt.Sprintf("\t)\n"))
+	body.WriteString(fmt.Sprintf("}\n\n"))
+
+	return body.Bytes()
+}
+
+func (g *SerdeMsgpackUnion) GenerateVariantsFromToFunc() ([]byte, error) {
+	body := &bytes.Buffer{}
+
+	for _, variant := range g.union.Variant {
+		errorContext := g.errorFuncContext(g.parametrisedf(variant, "%sFromMsgpack"))
+
+		// from msgpack func
+		body.WriteString(fmt.Sprintf("func %s(x []byte) (*%s, error) {\n",
+			g.constructionf(variant, "%sFromMsgpack"),
+			g.parametrisedf(variant, "%s"),
+		))
+		body.WriteString(fmt.Sprintf("\tresult := new(%s)\n", g.parametrisedf(variant, "%s")))
+		body.WriteString(fmt.Sprintf("\terr := result.UnmarshalMsgpack(x)\n"))
+		body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("\t}\n"))
+		body.WriteString(fmt.Sprintf("\treturn result, nil\n"))
+		body.WriteString(fmt.Sprintf("}\n\n"))
+
+		// to msgpack func
+		body.WriteString(fmt.Sprintf("func %s(x *%s) ([]byte, error) {\n",
+			g.constructionf(variant, "%sToMsgpack"),
+			g.parametrisedf(variant, "%s"),
+		))
+		body.WriteString(fmt.Sprintf("\treturn x.MarshalMsgpack()\n"))
+		body.WriteString(fmt.Sprintf("}\n\n"))
+
+		serde := NewSerdeMsgpackTagged(variant)
+		serde.SkipImportsAndPackage(true)
+		result, err := serde.Generate()
+		if err != nil {
+			return nil, fmt.Errorf("generators.SerdeMsgpackUnion.GenerateVariantsFromToFunc: %s; %w", shape.Name(variant), err)
+		}
+		g.pkgUsed = MergePkgMaps(g.pkgUsed, serde.ExtractImports(variant))
+
+		body.WriteString(result)
+		body.WriteString("\n")
+	}
+
+	return body.Bytes(), nil
+}
@@ -0,0 +1,931 @@
+package generators
+
+import (
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"strings"
+)
+
+const (
+	unmarshalProtoMethodPrefix        = "_unmarshalProto"
+	marshalProtoMethodPrefix          = "_marshalProto"
+	unmarshalProtoMessageMethodPrefix = "_unmarshalProtoMessage"
+	marshalProtoMessageMethodPrefix   = "_marshalProtoMessage"
+)
+
+// NewSerdeProtoTagged generates MarshalProto and UnmarshalProto methods,
+// that produce the same wire format as schema exported by shape.ProtobufRenderer.
+// Field numbers are taken from lock, so both must share the same lock.
+func NewSerdeProtoTagged(x shape.Shape, lock *shape.ProtoLock) *SerdeProtoTagged {
+	if lock == nil {
+		lock = shape.NewProtoLock()
+	}
+
+	return &SerdeProtoTagged{
+		shape:                 x,
+		lock:                  lock,
+		skipImportsAndPackage: false,
+		didGenerateMethod:     make(map[string]bool),
+		pkgUsed: PkgMap{
+			"fmt":    "fmt",
+			"shared": "github.com/widmogrod/mkunion/x/shared",
+		},
+		lookup: shape.LookupShapeOnDisk,
+	}
+}
+
+type SerdeProtoTagged struct {
+	shape                 shape.Shape
+	lock                  *shape.ProtoLock
+	skipImportsAndPackage bool
+
+	didGenerateMethod map[string]bool
+	pkgUsed           PkgMap
+	// lookup finds week aliases, that are encoded as type they refer to
+	lookup func(*shape.RefName) (shape.Shape, bool)
+}
+
+func (g *SerdeProtoTagged) SkipImportsAndPackage(flag bool) *SerdeProtoTagged {
+	g.skipImportsAndPackage = flag
+	return g
+}
+
+func (g *SerdeProtoTagged) Generate() (string, error) {
+	body := &strings.Builder{}
+
+	if !shape.IsWeekAlias(g.shape) {
+		body.WriteString(g.GenerateVarCasting())
+
+		marshalPart, err := g.GenerateMarshalProto(g.shape)
+		if err != nil {
+			return "", fmt.Errorf("generators.SerdeProtoTagged.Generate: when generating marshal %w", err)
+		}
+		body.WriteString(marshalPart)
+
+		unmarshalPart, err := g.GenerateUnmarshalProto(g.shape)
+		if err != nil {
+			return "", fmt.Errorf("generators.SerdeProtoTagged.Generate: when generating unmarshal %w", err)
+		}
+		body.WriteString(unmarshalPart)
+	}
+
+	head := &strings.Builder{}
+	if !g.skipImportsAndPackage {
+		head.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.shape)))
+		head.WriteString(GenerateImports(g.ExtractImports(g.shape)))
+	}
+
+	if head.Len() > 0 {
+		head.WriteString(body.String())
+		return head.String(), nil
+	} else {
+		return body.String(), nil
+	}
+}
+
+func (g *SerdeProtoTagged) ExtractImports(x shape.Shape) PkgMap {
+	pkgMap := shape.ExtractPkgImportNames(x)
+	if pkgMap == nil {
+		pkgMap = make(map[string]string)
+	}
+
+	// add default and necessary imports
+	pkgMap = MergePkgMaps(pkgMap, g.pkgUsed)
+
+	// remove self from importing
+	delete(pkgMap, shape.ToGoPkgName(x))
+	return pkgMap
+}
+
+func (g *SerdeProtoTagged) GenerateVarCasting() string {
+	typeName := shape.ToGoTypeName(g.shape,
+		shape.WithInstantiation(),
+		shape.WithRootPkgName(shape.ToGoPkgName(g.shape)),
+	)
+
+	result := &strings.Builder{}
+	result.WriteString("var (\n")
+	result.WriteString(fmt.Sprintf("\t_ shared.ProtoUnmarshaler = (*%s)(nil)\n", typeName))
+	result.WriteString(fmt.Sprintf("\t_ shared.ProtoMarshaler   = (*%s)(nil)\n", typeName))
+	result.WriteString(")\n\n")
+	return result.String()
+}
+
+func (g *SerdeProtoTagged) rootPkgName() string {
+	return shape.ToGoPkgName(g.shape)
+}
+
+func (g *SerdeProtoTagged) rootTypeName() string {
+	return shape.ToGoTypeName(g.shape,
+		shape.WithRootPkgName(shape.ToGoPkgName(g.shape)),
+	)
+}
+
+func (g *SerdeProtoTagged) typeName(x shape.Shape) string {
+	return shape.ToGoTypeName(x, shape.WithRootPkgName(g.rootPkgName()))
+}
+
+func (g *SerdeProtoTagged) errorContext(name string) string {
+	return fmt.Sprintf(`%s: %s.%s:`, g.rootPkgName(), g.rootTypeName(), name)
+}
+
+func (g *SerdeProtoTagged) methodNameWithPrefix(x shape.Shape, prefix string) string {
+	return fmt.Sprintf("%s%s", prefix, removeNonAlpha.Replace(g.typeName(x)))
+}
+
+func (g *SerdeProtoTagged) lockKey(x shape.Shape) string {
+	return fmt.Sprintf("%s.%s", shape.ToGoPkgImportName(x), shape.Name(x))
+}
+
+// resolve replaces references to week aliases, also in pointers, lists and maps, with type they refer to,
+// since week alias don't have protobuf marshaller, and is the same Go type as its target.
+func (g *SerdeProtoTagged) resolve(x shape.Shape) shape.Shape {
+	switch y := shape.ResolveWeekAlias(x, g.lookup).(type) {
+	case *shape.PointerLike:
+		return &shape.PointerLike{Type: g.resolve(y.Type)}
+	case *shape.ListLike:
+		return &shape.ListLike{Element: g.resolve(y.Element), ArrayLen: y.ArrayLen}
+	case *shape.MapLike:
+		return &shape.MapLike{Key: g.resolve(y.Key), Val: g.resolve(y.Val)}
+	default:
+		return y
+	}
+}
+
+// markGenerated prevents infinite recursion, and generation of the same method twice
+func (g *SerdeProtoTagged) markGenerated(methodName string) bool {
+	if g.didGenerateMethod[methodName] {
+		return true
+	}
+
+	g.didGenerateMethod[methodName] = true
+	return false
+}
+
+func (g *SerdeProtoTagged) GenerateMarshalProto(x shape.Shape) (string, error) {
+	result := &strings.Builder{}
+	result.WriteString(fmt.Sprintf("func (r *%s) MarshalProto() ([]byte, error) {\n", g.rootTypeName()))
+	result.WriteString(fmt.Sprintf("\tif r == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn nil, nil\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\treturn r.%s(*r)\n", g.methodNameWithPrefix(x, marshalProtoMessageMethodPrefix)))
+	result.WriteString("}\n")
+
+	methods, err := g.GenerateMarshalProtoMessage(x)
+	if err != nil {
+		return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateMarshalProto: %w", err)
+	}
+	result.WriteString(methods)
+
+	return result.String(), nil
+}
+
+func (g *SerdeProtoTagged) GenerateUnmarshalProto(x shape.Shape) (string, error) {
+	result := &strings.Builder{}
+	result.WriteString(fmt.Sprintf("func (r *%s) UnmarshalProto(data []byte) error {\n", g.rootTypeName()))
+	result.WriteString(fmt.Sprintf("\tresult, err := r.%s(data)\n", g.methodNameWithPrefix(x, unmarshalProtoMessageMethodPrefix)))
+	result.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s %%w\", err)\n", g.errorContext("UnmarshalProto")))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\t*r = result\n"))
+	result.WriteString(fmt.Sprintf("\treturn nil\n"))
+	result.WriteString(fmt.Sprintf("}\n"))
+
+	methods, err := g.GenerateUnmarshalProtoMessage(x)
+	if err != nil {
+		return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateUnmarshalProto: %w", err)
+	}
+	result.WriteString(methods)
+
+	return result.String(), nil
+}
+
+// protoNeedsWrapper returns true, when shape cannot be repeated directly,
+// like list of lists, and must be wrapped in a message with single field
+func protoNeedsWrapper(x shape.Shape) bool {
+	switch y := x.(type) {
+	case *shape.ListLike:
+		return !shape.IsBinary(y)
+	case *shape.MapLike:
+		return true
+	}
+	return false
+}
+
+func protoIsTypeParam(x *shape.RefName) bool {
+	return x.PkgName == "" && x.PkgImportName == ""
+}
+
+// protoWireType returns wire type of repeated element,
+// numbers and booleans are packed, everything else is length delimited
+func protoWireType(x shape.Shape) string {
+	prim, ok := x.(*shape.PrimitiveLike)
+	if !ok {
+		return "shared.ProtoWireBytes"
+	}
+
+	return shape.MatchPrimitiveKindR1(
+		prim.Kind,
+		func(x *shape.BooleanLike) string {
+			return "shared.ProtoWireVarint"
+		},
+		func(x *shape.StringLike) string {
+			return "shared.ProtoWireBytes"
+		},
+		func(x *shape.NumberLike) string {
+			switch x.Kind.(type) {
+			case *shape.Float32:
+				return "shared.ProtoWireFixed32"
+			case *shape.Float64, nil:
+				return "shared.ProtoWireFixed64"
+			}
+			return "shared.ProtoWireVarint"
+		},
+	)
+}
+
+// protoZeroCheck returns condition that is true when value is not default,
+// since proto3 doesn't put scalars with default values on the wire
+func protoZeroCheck(x shape.Shape, expr string) (string, bool) {
+	switch y := x.(type) {
+	case *shape.PrimitiveLike:
+		return shape.MatchPrimitiveKindR1(
+			y.Kind,
+			func(x *shape.BooleanLike) string {
+				return expr
+			},
+			func(x *shape.StringLike) string {
+				return fmt.Sprintf("%s != \"\"", expr)
+			},
+			func(x *shape.NumberLike) string {
+				return fmt.Sprintf("%s != 0", expr)
+			},
+		), true
+	case *shape.ListLike:
+		if shape.IsBinary(y) {
+			return fmt.Sprintf("len(%s) > 0", expr), true
+		}
+	}
+
+	return "", false
+}
+
+func (g *SerdeProtoTagged) writeFieldMarshal(body *strings.Builder, x shape.Shape, num int, expr, errorContext string) {
+	condition, hasZero := protoZeroCheck(x, expr)
+	indent := ""
+	if hasZero {
+		body.WriteString(fmt.Sprintf("if %s {\n", condition))
+		indent = "\t"
+	}
+	body.WriteString(fmt.Sprintf("%sresult, err = r.%s(result, %d, %s)\n", indent, g.methodNameWithPrefix(x, marshalProtoMethodPrefix), num, expr))
+	body.WriteString(fmt.Sprintf("%sif err != nil {\n", indent))
+	body.WriteString(fmt.Sprintf("%s\treturn nil, fmt.Errorf(\"%s; %%w\", err)\n", indent, errorContext))
+	body.WriteString(fmt.Sprintf("%s}\n", indent))
+	if hasZero {
+		body.WriteString("}\n")
+	}
+}
+
+func (g *SerdeProtoTagged) GenerateMarshalProtoMessage(x shape.Shape) (string, error) {
+	methodName := g.methodNameWithPrefix(x, marshalProtoMessageMethodPrefix)
+	if g.markGenerated(methodName) {
+		return "", nil
+	}
+
+	rootTypeName := g.rootTypeName()
+	typeName := g.typeName(x)
+	errorContext := g.errorContext(methodName)
+
+	body := &strings.Builder{}
+	var fieldTypes []shape.Shape
+
+	switch y := x.(type) {
+	case *shape.StructLike:
+		if len(y.Fields) == 0 {
+			body.WriteString("return nil, nil\n")
+			break
+		}
+
+		names := make([]string, 0, len(y.Fields))
+		for _, field := range y.Fields {
+			names = append(names, field.Name)
+		}
+		numbers := g.lock.Assign(g.lockKey(y), names)
+
+		body.WriteString("var result []byte\n")
+		body.WriteString("var err error\n")
+		for _, field := range y.Fields {
+			fieldType := g.resolve(field.Type)
+			g.writeFieldMarshal(body, fieldType, numbers[field.Name], "x."+field.Name,
+				fmt.Sprintf("%s field name %s", errorContext, field.Name))
+			fieldTypes = append(fieldTypes, fieldType)
+		}
+		body.WriteString("return result, nil\n")
+
+	case *shape.AliasLike:
+		numbers := g.lock.Assign(g.lockKey(y), []string{"value"})
+
+		body.WriteString("var result []byte\n")
+		body.WriteString("var err error\n")
+		valueType := g.resolve(y.Type)
+		body.WriteString(fmt.Sprintf("value := %s(x)\n", g.typeName(valueType)))
+		g.writeFieldMarshal(body, valueType, numbers["value"], "value",
+			fmt.Sprintf("%s alias", errorContext))
+		body.WriteString("return result, nil\n")
+		fieldTypes = append(fieldTypes, valueType)
+
+	default:
+		return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateMarshalProtoMessage: %s is not a message", typeName)
+	}
+
+	result := &strings.Builder{}
+	result.WriteString(fmt.Sprintf("func (r *%s) %s(x %s) ([]byte, error) {\n", rootTypeName, methodName, typeName))
+	result.WriteString(padLeftTabs(1, body.String()))
+	result.WriteString("}\n")
+
+	for _, fieldType := range fieldTypes {
+		methods, err := g.GenerateMarshalProtoMethods(fieldType)
+		if err != nil {
+			return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateMarshalProtoMessage: %w", err)
+		}
+		result.WriteString(methods)
+	}
+
+	return result.String(), nil
+}
+
+func (g *SerdeProtoTagged) GenerateMarshalProtoMethods(x shape.Shape) (string, error) {
+	methodName := g.methodNameWithPrefix(x, marshalProtoMethodPrefix)
+	if g.markGenerated(methodName) {
+		return "", nil
+	}
+
+	if shape.IsWeekAlias(x) {
+		return "", nil
+	}
+
+	rootTypeName := g.rootTypeName()
+	typeName := g.typeName(x)
+	errorContext := g.errorContext(methodName)
+
+	methodWrap := func(body *strings.Builder) string {
+		result := &strings.Builder{}
+		result.WriteString(fmt.Sprintf("func (r *%s) %s(b []byte, num int, x %s) ([]byte, error) {\n", rootTypeName, methodName, typeName))
+		result.WriteString(padLeftTabs(1, body.String()))
+		result.WriteString("}\n")
+		return result.String()
+	}
+
+	writeBytes := func(body *strings.Builder, call string) {
+		body.WriteString(fmt.Sprintf("data, err := %s\n", call))
+		body.WriteString(fmt.Sprintf("if err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\treturn nil, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("}\n"))
+		body.WriteString(fmt.Sprintf("return shared.ProtoAppendBytesField(b, num, data), nil\n"))
+	}
+
+	return shape.MatchShapeR2(
+		x,
+		func(y *shape.Any) (string, error) {
+			g.pkgUsed["json"] = "encoding/json"
+
+			body := &strings.Builder{}
+			writeBytes(body, "json.Marshal(x)")
+			return methodWrap(body), nil
+		},
+		func(y *shape.RefName) (string, error) {
+			body := &strings.Builder{}
+			if protoIsTypeParam(y) {
+				// type parameters don't have protobuf schema, they travel as JSON
+				writeBytes(body, fmt.Sprintf("shared.JSONMarshal[%s](x)", typeName))
+			} else {
+				writeBytes(body, fmt.Sprintf("shared.ProtoMarshal[%s](x)", typeName))
+			}
+			return methodWrap(body), nil
+		},
+		func(y *shape.PointerLike) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("if x == nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn b, nil\n"))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return r.%s(b, num, *x)\n", g.methodNameWithPrefix(y.Type, marshalProtoMethodPrefix)))
+
+			methods, err := g.GenerateMarshalProtoMethods(y.Type)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateMarshalProtoMethods: pointer methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.AliasLike) (string, error) {
+			body := &strings.Builder{}
+			writeBytes(body, fmt.Sprintf("r.%s(x)", g.methodNameWithPrefix(y, marshalProtoMessageMethodPrefix)))
+
+			methods, err := g.GenerateMarshalProtoMessage(y)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateMarshalProtoMethods: alias methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.PrimitiveLike) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(shape.MatchPrimitiveKindR1(
+				y.Kind,
+				func(z *shape.BooleanLike) string {
+					return "return shared.ProtoAppendBoolField(b, num, bool(x)), nil\n"
+				},
+				func(z *shape.StringLike) string {
+					return "return shared.ProtoAppendStringField(b, num, string(x)), nil\n"
+				},
+				func(z *shape.NumberLike) string {
+					switch z.Kind.(type) {
+					case *shape.Float32:
+						return "return shared.ProtoAppendFloat32Field(b, num, float32(x)), nil\n"
+					case *shape.Float64, nil:
+						return "return shared.ProtoAppendFloat64Field(b, num, float64(x)), nil\n"
+					}
+					return "return shared.ProtoAppendVarintField(b, num, uint64(x)), nil\n"
+				},
+			))
+			return methodWrap(body), nil
+		},
+		func(y *shape.ListLike) (string, error) {
+			body := &strings.Builder{}
+
+			if shape.IsBinary(y) {
+				body.WriteString(fmt.Sprintf("return shared.ProtoAppendBytesField(b, num, x), nil\n"))
+				return methodWrap(body), nil
+			}
+
+			if y.ArrayLen != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateMarshalProtoMethods: %s fixed size arrays are not supported", typeName)
+			}
+
+			elementMethod := g.methodNameWithPrefix(y.Element, marshalProtoMethodPrefix)
+			switch wireType := protoWireType(y.Element); wireType {
+			case "shared.ProtoWireBytes":
+				body.WriteString(fmt.Sprintf("var err error\n"))
+				body.WriteString(fmt.Sprintf("for i, v := range x {\n"))
+				if protoNeedsWrapper(y.Element) {
+					body.WriteString(fmt.Sprintf("\tvar item []byte\n"))
+					body.WriteString(fmt.Sprintf("\titem, err = r.%s(nil, 1, v)\n", elementMethod))
+				} else {
+					body.WriteString(fmt.Sprintf("\tb, err = r.%s(b, num, v)\n", elementMethod))
+				}
+				body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+				body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s at index %%d; %%w\", i, err)\n", errorContext))
+				body.WriteString(fmt.Sprintf("\t}\n"))
+				if protoNeedsWrapper(y.Element) {
+					body.WriteString(fmt.Sprintf("\tb = shared.ProtoAppendBytesField(b, num, item)\n"))
+				}
+				body.WriteString(fmt.Sprintf("}\n"))
+				body.WriteString(fmt.Sprintf("return b, nil\n"))
+
+			default:
+				// proto3 packs repeated scalars
+				body.WriteString(fmt.Sprintf("if len(x) == 0 {\n"))
+				body.WriteString(fmt.Sprintf("\treturn b, nil\n"))
+				body.WriteString(fmt.Sprintf("}\n"))
+				body.WriteString(fmt.Sprintf("var packed []byte\n"))
+				body.WriteString(fmt.Sprintf("for _, v := range x {\n"))
+				switch wireType {
+				case "shared.ProtoWireFixed32":
+					g.pkgUsed["math"] = "math"
+					body.WriteString(fmt.Sprintf("\tpacked = shared.ProtoAppendFixed32(packed, math.Float32bits(float32(v)))\n"))
+				case "shared.ProtoWireFixed64":
+					g.pkgUsed["math"] = "math"
+					body.WriteString(fmt.Sprintf("\tpacked = shared.ProtoAppendFixed64(packed, math.Float64bits(float64(v)))\n"))
+				default:
+					if _, isBool := y.Element.(*shape.PrimitiveLike).Kind.(*shape.BooleanLike); isBool {
+						body.WriteString(fmt.Sprintf("\tif v {\n"))
+						body.WriteString(fmt.Sprintf("\t\tpacked = append(packed, 1)\n"))
+						body.WriteString(fmt.Sprintf("\t} else {\n"))
+						body.WriteString(fmt.Sprintf("\t\tpacked = append(packed, 0)\n"))
+						body.WriteString(fmt.Sprintf("\t}\n"))
+					} else {
+						body.WriteString(fmt.Sprintf("\tpacked = shared.ProtoAppendVarint(packed, uint64(v))\n"))
+					}
+				}
+				body.WriteString(fmt.Sprintf("}\n"))
+				body.WriteString(fmt.Sprintf("return shared.ProtoAppendBytesField(b, num, packed), nil\n"))
+			}
+
+			methods, err := g.GenerateMarshalProtoMethods(y.Element)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateMarshalProtoMethods: list methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.MapLike) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("var err error\n"))
+
+			if protoIsOrderedKey(y.Key) {
+				// keep encoding deterministic
+				g.pkgUsed["maps"] = "maps"
+				g.pkgUsed["slices"] = "slices"
+				body.WriteString(fmt.Sprintf("for _, k := range slices.Sorted(maps.Keys(x)) {\n"))
+				body.WriteString(fmt.Sprintf("\tv := x[k]\n"))
+			} else {
+				body.WriteString(fmt.Sprintf("for k, v := range x {\n"))
+			}
+
+			// map on the wire is a repeated entry message with key = 1 and value = 2
+			body.WriteString(fmt.Sprintf("\tvar entry []byte\n"))
+			body.WriteString(fmt.Sprintf("\tentry, err = r.%s(entry, 1, k)\n", g.methodNameWithPrefix(y.Key, marshalProtoMethodPrefix)))
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s key; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			if protoNeedsWrapper(y.Val) {
+				body.WriteString(fmt.Sprintf("\tvar value []byte\n"))
+				body.WriteString(fmt.Sprintf("\tvalue, err = r.%s(nil, 1, v)\n", g.methodNameWithPrefix(y.Val, marshalProtoMethodPrefix)))
+			} else {
+				body.WriteString(fmt.Sprintf("\tentry, err = r.%s(entry, 2, v)\n", g.methodNameWithPrefix(y.Val, marshalProtoMethodPrefix)))
+			}
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s value; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			if protoNeedsWrapper(y.Val) {
+				body.WriteString(fmt.Sprintf("\tentry = shared.ProtoAppendBytesField(entry, 2, value)\n"))
+			}
+			body.WriteString(fmt.Sprintf("\tb = shared.ProtoAppendBytesField(b, num, entry)\n"))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return b, nil\n"))
+
+			keyMethods, err := g.GenerateMarshalProtoMethods(y.Key)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateMarshalProtoMethods: key methods; %w", err)
+			}
+
+			valMethods, err := g.GenerateMarshalProtoMethods(y.Val)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateMarshalProtoMethods: value methods; %w", err)
+			}
+
+			return methodWrap(body) + keyMethods + valMethods, nil
+		},
+		func(y *shape.StructLike) (string, error) {
+			body := &strings.Builder{}
+			writeBytes(body, fmt.Sprintf("r.%s(x)", g.methodNameWithPrefix(y, marshalProtoMessageMethodPrefix)))
+
+			methods, err := g.GenerateMarshalProtoMessage(y)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateMarshalProtoMethods: struct methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.UnionLike) (string, error) {
+			body := &strings.Builder{}
+			writeBytes(body, fmt.Sprintf("%sToProto(x)", typeName))
+			return methodWrap(body), nil
+		},
+	)
+}
+
+func protoIsOrderedKey(x shape.Shape) bool {
+	prim, ok := x.(*shape.PrimitiveLike)
+	if !ok {
+		return false
+	}
+
+	_, isBool := prim.Kind.(*shape.BooleanLike)
+	return !isBool
+}
+
+func (g *SerdeProtoTagged) GenerateUnmarshalProtoMessage(x shape.Shape) (string, error) {
+	methodName := g.methodNameWithPrefix(x, unmarshalProtoMessageMethodPrefix)
+	if g.markGenerated(methodName) {
+		return "", nil
+	}
+
+	rootTypeName := g.rootTypeName()
+	typeName := g.typeName(x)
+	errorContext := g.errorContext(methodName)
+
+	body := &strings.Builder{}
+	var fieldTypes []shape.Shape
+
+	switch y := x.(type) {
+	case *shape.StructLike:
+		body.WriteString(fmt.Sprintf("result := %s{}\n", typeName))
+		if len(y.Fields) == 0 {
+			body.WriteString("return result, nil\n")
+			break
+		}
+
+		names := make([]string, 0, len(y.Fields))
+		for _, field := range y.Fields {
+			names = append(names, field.Name)
+		}
+		numbers := g.lock.Assign(g.lockKey(y), names)
+
+		body.WriteString(fmt.Sprintf("err := shared.ProtoDecodeFields(data, func(field shared.ProtoField) error {\n"))
+		body.WriteString(fmt.Sprintf("\tvar err error\n"))
+		body.WriteString(fmt.Sprintf("\tswitch field.Num {\n"))
+		for _, field := range y.Fields {
+			fieldType := g.resolve(field.Type)
+			body.WriteString(fmt.Sprintf("\tcase %d:\n", numbers[field.Name]))
+			body.WriteString(fmt.Sprintf("\t\tresult.%s, err = r.%s(field, result.%s)\n", field.Name, g.methodNameWithPrefix(fieldType, unmarshalProtoMethodPrefix), field.Name))
+			body.WriteString(fmt.Sprintf("\t\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"field %s; %%w\", err)\n", field.Name))
+			body.WriteString(fmt.Sprintf("\t\t}\n"))
+			fieldTypes = append(fieldTypes, fieldType)
+		}
+		body.WriteString(fmt.Sprintf("\t}\n"))
+		body.WriteString(fmt.Sprintf("\treturn nil\n"))
+		body.WriteString(fmt.Sprintf("})\n"))
+		body.WriteString(fmt.Sprintf("if err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\treturn result, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("}\n"))
+		body.WriteString(fmt.Sprintf("return result, nil\n"))
+
+	case *shape.AliasLike:
+		numbers := g.lock.Assign(g.lockKey(y), []string{"value"})
+
+		valueType := g.resolve(y.Type)
+		body.WriteString(fmt.Sprintf("var value %s\n", g.typeName(valueType)))
+		body.WriteString(fmt.Sprintf("err := shared.ProtoDecodeFields(data, func(field shared.ProtoField) error {\n"))
+		body.WriteString(fmt.Sprintf("\tvar err error\n"))
+		body.WriteString(fmt.Sprintf("\tif field.Num == %d {\n", numbers["value"]))
+		body.WriteString(fmt.Sprintf("\t\tvalue, err = r.%s(field, value)\n", g.methodNameWithPrefix(valueType, unmarshalProtoMethodPrefix)))
+		body.WriteString(fmt.Sprintf("\t}\n"))
+		body.WriteString(fmt.Sprintf("\treturn err\n"))
+		body.WriteString(fmt.Sprintf("})\n"))
+		body.WriteString(fmt.Sprintf("if err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\treturn %s(value), fmt.Errorf(\"%s alias; %%w\", err)\n", typeName, errorContext))
+		body.WriteString(fmt.Sprintf("}\n"))
+		body.WriteString(fmt.Sprintf("return %s(value), nil\n", typeName))
+		fieldTypes = append(fieldTypes, valueType)
+
+	default:
+		return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateUnmarshalProtoMessage: %s is not a message", typeName)
+	}
+
+	result := &strings.Builder{}
+	result.WriteString(fmt.Sprintf("func (r *%s) %s(data []byte) (%s, error) {\n", rootTypeName, methodName, typeName))
+	result.WriteString(padLeftTabs(1, body.String()))
+	result.WriteString("}\n")
+
+	for _, fieldType := range fieldTypes {
+		methods, err := g.GenerateUnmarshalProtoMethods(fieldType)
+		if err != nil {
+			return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateUnmarshalProtoMessage: %w", err)
+		}
+		result.WriteString(methods)
+	}
+
+	return result.String(), nil
+}
+
+func (g *SerdeProtoTagged) GenerateUnmarshalProtoMethods(x shape.Shape) (string, error) {
+	methodName := g.methodNameWithPrefix(x, unmarshalProtoMethodPrefix)
+	if g.markGenerated(methodName) {
+		return "", nil
+	}
+
+	if shape.IsWeekAlias(x) {
+		return "", nil
+	}
+
+	rootTypeName := g.rootTypeName()
+	typeName := g.typeName(x)
+	errorContext := g.errorContext(methodName)
+
+	methodWrap := func(body *strings.Builder) string {
+		result := &strings.Builder{}
+		result.WriteString(fmt.Sprintf("func (r *%s) %s(field shared.ProtoField, prev %s) (%s, error) {\n", rootTypeName, methodName, typeName, typeName))
+		result.WriteString(padLeftTabs(1, body.String()))
+		result.WriteString("}\n")
+		return result.String()
+	}
+
+	readBytes := func(body *strings.Builder) {
+		body.WriteString(fmt.Sprintf("data, err := field.AsBytes()\n"))
+		body.WriteString(fmt.Sprintf("if err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\treturn prev, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("}\n"))
+	}
+
+	returnCall := func(body *strings.Builder, call string) {
+		body.WriteString(fmt.Sprintf("result, err := %s\n", call))
+		body.WriteString(fmt.Sprintf("if err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\treturn prev, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("}\n"))
+		body.WriteString(fmt.Sprintf("return result, nil\n"))
+	}
+
+	return shape.MatchShapeR2(
+		x,
+		func(y *shape.Any) (string, error) {
+			g.pkgUsed["json"] = "encoding/json"
+
+			body := &strings.Builder{}
+			readBytes(body)
+			body.WriteString(fmt.Sprintf("var result %s\n", typeName))
+			body.WriteString(fmt.Sprintf("err = json.Unmarshal(data, &result)\n"))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn prev, fmt.Errorf(\"%s native any unwrap; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return result, nil\n"))
+			return methodWrap(body), nil
+		},
+		func(y *shape.RefName) (string, error) {
+			body := &strings.Builder{}
+			readBytes(body)
+			if protoIsTypeParam(y) {
+				returnCall(body, fmt.Sprintf("shared.JSONUnmarshal[%s](data)", typeName))
+			} else {
+				returnCall(body, fmt.Sprintf("shared.ProtoUnmarshal[%s](data)", typeName))
+			}
+			return methodWrap(body), nil
+		},
+		func(y *shape.PointerLike) (string, error) {
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("var value %s\n", g.typeName(y.Type)))
+			body.WriteString(fmt.Sprintf("if prev != nil {\n"))
+			body.WriteString(fmt.Sprintf("\tvalue = *prev\n"))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("result, err := r.%s(field, value)\n", g.methodNameWithPrefix(y.Type, unmarshalProtoMethodPrefix)))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn prev, fmt.Errorf(\"%s pointer; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return &result, nil\n"))
+
+			methods, err := g.GenerateUnmarshalProtoMethods(y.Type)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateUnmarshalProtoMethods: pointer methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.AliasLike) (string, error) {
+			body := &strings.Builder{}
+			readBytes(body)
+			returnCall(body, fmt.Sprintf("r.%s(data)", g.methodNameWithPrefix(y, unmarshalProtoMessageMethodPrefix)))
+
+			methods, err := g.GenerateUnmarshalProtoMessage(y)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateUnmarshalProtoMethods: alias methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.PrimitiveLike) (string, error) {
+			read := shape.MatchPrimitiveKindR1(
+				y.Kind,
+				func(z *shape.BooleanLike) string {
+					return "field.AsBool()"
+				},
+				func(z *shape.StringLike) string {
+					return "field.AsString()"
+				},
+				func(z *shape.NumberLike) string {
+					switch z.Kind.(type) {
+					case *shape.Float32:
+						return "field.AsFloat32()"
+					case *shape.Float64, nil:
+						return "field.AsFloat64()"
+					case *shape.UInt, *shape.UInt8, *shape.UInt16, *shape.UInt32, *shape.UInt64:
+						return "field.AsUint64()"
+					}
+					return "field.AsInt64()"
+				},
+			)
+
+			body := &strings.Builder{}
+			body.WriteString(fmt.Sprintf("result, err := %s\n", read))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn prev, fmt.Errorf(\"%s; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return %s(result), nil\n", typeName))
+			return methodWrap(body), nil
+		},
+		func(y *shape.ListLike) (string, error) {
+			body := &strings.Builder{}
+
+			if shape.IsBinary(y) {
+				readBytes(body)
+				body.WriteString(fmt.Sprintf("result := make(%s, len(data))\n", typeName))
+				body.WriteString(fmt.Sprintf("copy(result, data)\n"))
+				body.WriteString(fmt.Sprintf("return result, nil\n"))
+				return methodWrap(body), nil
+			}
+
+			if y.ArrayLen != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateUnmarshalProtoMethods: %s fixed size arrays are not supported", typeName)
+			}
+
+			elementTypeName := g.typeName(y.Element)
+			elementMethod := g.methodNameWithPrefix(y.Element, unmarshalProtoMethodPrefix)
+
+			body.WriteString(fmt.Sprintf("err := shared.ProtoUnpack(field, %s, func(item shared.ProtoField) error {\n", protoWireType(y.Element)))
+			body.WriteString(fmt.Sprintf("\tvar value %s\n", elementTypeName))
+			if protoNeedsWrapper(y.Element) {
+				body.WriteString(fmt.Sprintf("\tdata, err := item.AsBytes()\n"))
+				body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+				body.WriteString(fmt.Sprintf("\t\treturn err\n"))
+				body.WriteString(fmt.Sprintf("\t}\n"))
+				body.WriteString(fmt.Sprintf("\terr = shared.ProtoDecodeFields(data, func(inner shared.ProtoField) error {\n"))
+				body.WriteString(fmt.Sprintf("\t\tvar err error\n"))
+				body.WriteString(fmt.Sprintf("\t\tif inner.Num == 1 {\n"))
+				body.WriteString(fmt.Sprintf("\t\t\tvalue, err = r.%s(inner, value)\n", elementMethod))
+				body.WriteString(fmt.Sprintf("\t\t}\n"))
+				body.WriteString(fmt.Sprintf("\t\treturn err\n"))
+				body.WriteString(fmt.Sprintf("\t})\n"))
+			} else {
+				body.WriteString(fmt.Sprintf("\tvalue, err := r.%s(item, value)\n", elementMethod))
+			}
+			body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"at index %%d; %%w\", len(prev), err)\n"))
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			body.WriteString(fmt.Sprintf("\tprev = append(prev, value)\n"))
+			body.WriteString(fmt.Sprintf("\treturn nil\n"))
+			body.WriteString(fmt.Sprintf("})\n"))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn prev, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("return prev, nil\n"))
+
+			methods, err := g.GenerateUnmarshalProtoMethods(y.Element)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateUnmarshalProtoMethods: list methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.MapLike) (string, error) {
+			valMethod := g.methodNameWithPrefix(y.Val, unmarshalProtoMethodPrefix)
+
+			body := &strings.Builder{}
+			readBytes(body)
+			body.WriteString(fmt.Sprintf("var key %s\n", g.typeName(y.Key)))
+			body.WriteString(fmt.Sprintf("var value %s\n", g.typeName(y.Val)))
+			body.WriteString(fmt.Sprintf("err = shared.ProtoDecodeFields(data, func(entry shared.ProtoField) error {\n"))
+			body.WriteString(fmt.Sprintf("\tvar err error\n"))
+			body.WriteString(fmt.Sprintf("\tswitch entry.Num {\n"))
+			body.WriteString(fmt.Sprintf("\tcase 1:\n"))
+			body.WriteString(fmt.Sprintf("\t\tkey, err = r.%s(entry, key)\n", g.methodNameWithPrefix(y.Key, unmarshalProtoMethodPrefix)))
+			body.WriteString(fmt.Sprintf("\tcase 2:\n"))
+			if protoNeedsWrapper(y.Val) {
+				body.WriteString(fmt.Sprintf("\t\tvar data []byte\n"))
+				body.WriteString(fmt.Sprintf("\t\tdata, err = entry.AsBytes()\n"))
+				body.WriteString(fmt.Sprintf("\t\tif err != nil {\n"))
+				body.WriteString(fmt.Sprintf("\t\t\treturn err\n"))
+				body.WriteString(fmt.Sprintf("\t\t}\n"))
+				body.WriteString(fmt.Sprintf("\t\terr = shared.ProtoDecodeFields(data, func(inner shared.ProtoField) error {\n"))
+				body.WriteString(fmt.Sprintf("\t\t\tvar err error\n"))
+				body.WriteString(fmt.Sprintf("\t\t\tif inner.Num == 1 {\n"))
+				body.WriteString(fmt.Sprintf("\t\t\t\tvalue, err = r.%s(inner, value)\n", valMethod))
+				body.WriteString(fmt.Sprintf("\t\t\t}\n"))
+				body.WriteString(fmt.Sprintf("\t\t\treturn err\n"))
+				body.WriteString(fmt.Sprintf("\t\t})\n"))
+			} else {
+				body.WriteString(fmt.Sprintf("\t\tvalue, err = r.%s(entry, value)\n", valMethod))
+			}
+			body.WriteString(fmt.Sprintf("\t}\n"))
+			body.WriteString(fmt.Sprintf("\treturn err\n"))
+			body.WriteString(fmt.Sprintf("})\n"))
+			body.WriteString(fmt.Sprintf("if err != nil {\n"))
+			body.WriteString(fmt.Sprintf("\treturn prev, fmt.Errorf(\"%s entry; %%w\", err)\n", errorContext))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("if prev == nil {\n"))
+			body.WriteString(fmt.Sprintf("\tprev = make(%s)\n", typeName))
+			body.WriteString(fmt.Sprintf("}\n"))
+			body.WriteString(fmt.Sprintf("prev[key] = value\n"))
+			body.WriteString(fmt.Sprintf("return prev, nil\n"))
+
+			keyMethods, err := g.GenerateUnmarshalProtoMethods(y.Key)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateUnmarshalProtoMethods: key methods; %w", err)
+			}
+
+			valMethods, err := g.GenerateUnmarshalProtoMethods(y.Val)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateUnmarshalProtoMethods: value methods; %w", err)
+			}
+
+			return methodWrap(body) + keyMethods + valMethods, nil
+		},
+		func(y *shape.StructLike) (string, error) {
+			body := &strings.Builder{}
+			readBytes(body)
+			returnCall(body, fmt.Sprintf("r.%s(data)", g.methodNameWithPrefix(y, unmarshalProtoMessageMethodPrefix)))
+
+			methods, err := g.GenerateUnmarshalProtoMessage(y)
+			if err != nil {
+				return "", fmt.Errorf("generators.SerdeProtoTagged.GenerateUnmarshalProtoMethods: struct methods; %w", err)
+			}
+
+			return methodWrap(body) + methods, nil
+		},
+		func(y *shape.UnionLike) (string, error) {
+			body := &strings.Builder{}
+			readBytes(body)
+			returnCall(body, fmt.Sprintf("%sFromProto(data)", typeName))
+			return methodWrap(body), nil
+		},
+	)
+}
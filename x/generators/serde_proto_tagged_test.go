@@ -0,0 +1,128 @@
+package generators
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+)
+
+func TestNewSerdeProtoTagged_Struct(t *testing.T) {
+	lock := shape.NewProtoLock()
+	// field numbers are stable, even when fields are reordered
+	lock.Assign("example.com/pkg.Deleted", []string{"At", "ID"})
+
+	generator := NewSerdeProtoTagged(&shape.StructLike{
+		Name:          "Deleted",
+		PkgName:       "pkg",
+		PkgImportName: "example.com/pkg",
+		Fields: []*shape.FieldLike{
+			{Name: "ID", Type: &shape.PrimitiveLike{Kind: &shape.StringLike{}}},
+			{Name: "At", Type: &shape.PointerLike{Type: &shape.PrimitiveLike{Kind: &shape.NumberLike{Kind: &shape.Int64{}}}}},
+		},
+	}, lock)
+
+	result, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, `package pkg
+
+import (
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shared"
+)
+
+var (
+	_ shared.ProtoUnmarshaler = (*Deleted)(nil)
+	_ shared.ProtoMarshaler   = (*Deleted)(nil)
+)
+
+func (r *Deleted) MarshalProto() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return r._marshalProtoMessageDeleted(*r)
+}
+func (r *Deleted) _marshalProtoMessageDeleted(x Deleted) ([]byte, error) {
+	var result []byte
+	var err error
+	if x.ID != "" {
+		result, err = r._marshalProtostring(result, 2, x.ID)
+		if err != nil {
+			return nil, fmt.Errorf("pkg: Deleted._marshalProtoMessageDeleted: field name ID; %w", err)
+		}
+	}
+	result, err = r._marshalProtoPtrint64(result, 1, x.At)
+	if err != nil {
+		return nil, fmt.Errorf("pkg: Deleted._marshalProtoMessageDeleted: field name At; %w", err)
+	}
+	return result, nil
+}
+func (r *Deleted) _marshalProtostring(b []byte, num int, x string) ([]byte, error) {
+	return shared.ProtoAppendStringField(b, num, string(x)), nil
+}
+func (r *Deleted) _marshalProtoPtrint64(b []byte, num int, x *int64) ([]byte, error) {
+	if x == nil {
+		return b, nil
+	}
+	return r._marshalProtoint64(b, num, *x)
+}
+func (r *Deleted) _marshalProtoint64(b []byte, num int, x int64) ([]byte, error) {
+	return shared.ProtoAppendVarintField(b, num, uint64(x)), nil
+}
+func (r *Deleted) UnmarshalProto(data []byte) error {
+	result, err := r._unmarshalProtoMessageDeleted(data)
+	if err != nil {
+		return fmt.Errorf("pkg: Deleted.UnmarshalProto: %w", err)
+	}
+	*r = result
+	return nil
+}
+func (r *Deleted) _unmarshalProtoMessageDeleted(data []byte) (Deleted, error) {
+	result := Deleted{}
+	err := shared.ProtoDecodeFields(data, func(field shared.ProtoField) error {
+		var err error
+		switch field.Num {
+		case 2:
+			result.ID, err = r._unmarshalProtostring(field, result.ID)
+			if err != nil {
+				return fmt.Errorf("field ID; %w", err)
+			}
+		case 1:
+			result.At, err = r._unmarshalProtoPtrint64(field, result.At)
+			if err != nil {
+				return fmt.Errorf("field At; %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("pkg: Deleted._unmarshalProtoMessageDeleted: %w", err)
+	}
+	return result, nil
+}
+func (r *Deleted) _unmarshalProtostring(field shared.ProtoField, prev string) (string, error) {
+	result, err := field.AsString()
+	if err != nil {
+		return prev, fmt.Errorf("pkg: Deleted._unmarshalProtostring:; %w", err)
+	}
+	return string(result), nil
+}
+func (r *Deleted) _unmarshalProtoPtrint64(field shared.ProtoField, prev *int64) (*int64, error) {
+	var value int64
+	if prev != nil {
+		value = *prev
+	}
+	result, err := r._unmarshalProtoint64(field, value)
+	if err != nil {
+		return prev, fmt.Errorf("pkg: Deleted._unmarshalProtoPtrint64: pointer; %w", err)
+	}
+	return &result, nil
+}
+func (r *Deleted) _unmarshalProtoint64(field shared.ProtoField, prev int64) (int64, error) {
+	result, err := field.AsInt64()
+	if err != nil {
+		return prev, fmt.Errorf("pkg: Deleted._unmarshalProtoint64:; %w", err)
+	}
+	return int64(result), nil
+}
+`, result)
+}
@@ -0,0 +1,230 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+)
+
+// NewSerdeProtoUnion generates protobuf serialisation for union,
+// that is encoded as a message with single oneof field, one field per variant.
+func NewSerdeProtoUnion(union *shape.UnionLike, lock *shape.ProtoLock) *SerdeProtoUnion {
+	if lock == nil {
+		lock = shape.NewProtoLock()
+	}
+
+	return &SerdeProtoUnion{
+		union:                 union,
+		lock:                  lock,
+		skipImportsAndPackage: false,
+		skipInitFunc:          false,
+		pkgUsed: PkgMap{
+			"fmt":    "fmt",
+			"shared": "github.com/widmogrod/mkunion/x/shared",
+		},
+	}
+}
+
+type SerdeProtoUnion struct {
+	union                 *shape.UnionLike
+	lock                  *shape.ProtoLock
+	skipImportsAndPackage bool
+	skipInitFunc          bool
+	pkgUsed               PkgMap
+}
+
+func (g *SerdeProtoUnion) SkipImportsAndPackage(x bool) {
+	g.skipImportsAndPackage = x
+}
+
+func (g *SerdeProtoUnion) SkipInitFunc(flag bool) *SerdeProtoUnion {
+	g.skipInitFunc = flag
+	return g
+}
+
+func (g *SerdeProtoUnion) ExtractImports(x shape.Shape) PkgMap {
+	pkgMap := shape.ExtractPkgImportNames(x)
+	if pkgMap == nil {
+		pkgMap = make(map[string]string)
+	}
+
+	// add default and necessary imports
+	pkgMap = MergePkgMaps(pkgMap, g.pkgUsed)
+
+	// remove self from importing
+	delete(pkgMap, shape.ToGoPkgName(x))
+	return pkgMap
+}
+
+func (g *SerdeProtoUnion) ExtractImportFuncs(s shape.Shape) []string {
+	return []string{
+		fmt.Sprintf("shared.ProtoMarshallerRegister(%q, %s, %s)",
+			shape.ToGoTypeName(s,
+				shape.WithPkgImportName(),
+				shape.WithInstantiation(),
+			),
+			StrInstantiatef(g.union.PkgName, s, "%sFromProto"),
+			StrInstantiatef(g.union.PkgName, s, "%sToProto"),
+		),
+	}
+}
+
+func (g *SerdeProtoUnion) Generate() ([]byte, error) {
+	body := &bytes.Buffer{}
+
+	numbers := g.variantNumbers()
+	body.Write(g.GenerateUnionFromFunc(numbers))
+	body.Write(g.GenerateUnionToFunc(numbers))
+
+	variants, err := g.GenerateVariantsFromToFunc()
+	if err != nil {
+		return nil, fmt.Errorf("generators.SerdeProtoUnion.Generate: when generating funcs for variant; %w", err)
+	}
+	body.Write(variants)
+
+	head := &bytes.Buffer{}
+	if !g.skipImportsAndPackage {
+		head.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.union)))
+		head.WriteString(GenerateImports(g.ExtractImports(g.union)))
+	}
+
+	if !g.skipInitFunc {
+		head.WriteString(GenerateInitFunc(g.ExtractImportFuncs(g.union)))
+	}
+
+	if head.Len() > 0 {
+		head.Write(body.Bytes())
+		return head.Bytes(), nil
+	} else {
+		return body.Bytes(), nil
+	}
+}
+
+func (g *SerdeProtoUnion) variantNumbers() map[string]int {
+	names := make([]string, 0, len(g.union.Variant))
+	for _, variant := range g.union.Variant {
+		names = append(names, shape.Name(variant))
+	}
+
+	return g.lock.Assign(
+		fmt.Sprintf("%s.%s", g.union.PkgImportName, g.union.Name),
+		names,
+	)
+}
+
+func (g *SerdeProtoUnion) parametrisedf(x shape.Shape, template string) string {
+	return (&SerdeJSONUnion{union: g.union}).parametrisedf(x, template)
+}
+
+func (g *SerdeProtoUnion) constructionf(x shape.Shape, template string) string {
+	return (&SerdeJSONUnion{union: g.union}).constructionf(x, template)
+}
+
+func (g *SerdeProtoUnion) errorFuncContext(name string) string {
+	return fmt.Sprintf(`%s.%s:`, g.union.PkgName, name)
+}
+
+func (g *SerdeProtoUnion) GenerateUnionFromFunc(numbers map[string]int) []byte {
+	body := &bytes.Buffer{}
+
+	errorContext := g.errorFuncContext(g.parametrisedf(g.union, "%sFromProto"))
+
+	body.WriteString(fmt.Sprintf("func %s(x []byte) (%s, error) {\n",
+		g.constructionf(g.union, "%sFromProto"),
+		g.parametrisedf(g.union, "%s"),
+	))
+	body.WriteString(fmt.Sprintf("\tvar result %s\n", g.parametrisedf(g.union, "%s")))
+	body.WriteString(fmt.Sprintf("\terr := shared.ProtoDecodeFields(x, func(field shared.ProtoField) error {\n"))
+	body.WriteString(fmt.Sprintf("\t\tswitch field.Num {\n"))
+	for _, variant := range g.union.Variant {
+		body.WriteString(fmt.Sprintf("\t\tcase %d:\n", numbers[shape.Name(variant)]))
+		body.WriteString(fmt.Sprintf("\t\t\tdata, err := field.AsBytes()\n"))
+		body.WriteString(fmt.Sprintf("\t\t\tif err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\t\t\t\treturn err\n"))
+		body.WriteString(fmt.Sprintf("\t\t\t}\n"))
+		body.WriteString(fmt.Sprintf("\t\t\tresult, err = %s(data)\n", g.parametrisedf(variant, "%sFromProto")))
+		body.WriteString(fmt.Sprintf("\t\t\treturn err\n"))
+	}
+	body.WriteString(fmt.Sprintf("\t\t}\n"))
+	body.WriteString(fmt.Sprintf("\t\treturn nil\n"))
+	body.WriteString(fmt.Sprintf("\t})\n"))
+	body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+	body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+	body.WriteString(fmt.Sprintf("\t}\n"))
+	body.WriteString(fmt.Sprintf("\treturn result, nil\n"))
+	body.WriteString(fmt.Sprintf("}\n\n"))
+
+	return body.Bytes()
+}
+
+func (g *SerdeProtoUnion) GenerateUnionToFunc(numbers map[string]int) []byte {
+	body := &bytes.Buffer{}
+
+	errorContext := g.errorFuncContext(g.parametrisedf(g.union, "%sToProto"))
+
+	body.WriteString(fmt.Sprintf("func %s(x %s) ([]byte, error) {\n",
+		g.constructionf(g.union, "%sToProto"),
+		g.parametrisedf(g.union, "%s"),
+	))
+	body.WriteString(fmt.Sprintf("\tif x == nil {\n"))
+	body.WriteString(fmt.Sprintf("\t\treturn nil, nil\n"))
+	body.WriteString(fmt.Sprintf("\t}\n"))
+
+	body.WriteString(fmt.Sprintf("\treturn %s(\n", MatchUnionFuncName(g.union, 2)))
+	body.WriteString(fmt.Sprintf("\t\tx,\n"))
+	for _, variant := range g.union.Variant {
+		body.WriteString(fmt.Sprintf("\t\tfunc (y *%s) ([]byte, error) {\n", g.parametrisedf(variant, "%s")))
+		body.WriteString(fmt.Sprintf("\t\t\tbody, err := %s(y)\n", g.parametrisedf(variant, "%sToProto")))
+		body.WriteString(fmt.Sprintf("\t\t\tif err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\t\t\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("\t\t\t}\n"))
+		body.WriteString(fmt.Sprintf("\t\t\treturn shared.ProtoAppendBytesField(nil, %d, body), nil\n", numbers[shape.Name(variant)]))
+		body.WriteString(fmt.Sprintf("\t\t},\n"))
+	}
+	body.WriteString(fmt.Sprintf("\t)\n"))
+	body.WriteString(fmt.Sprintf("}\n\n"))
+
+	return body.Bytes()
+}
+
+func (g *SerdeProtoUnion) GenerateVariantsFromToFunc() ([]byte, error) {
+	body := &bytes.Buffer{}
+
+	for _, variant := range g.union.Variant {
+		errorContext := g.errorFuncContext(g.parametrisedf(variant, "%sFromProto"))
+
+		// from proto func
+		body.WriteString(fmt.Sprintf("func %s(x []byte) (*%s, error) {\n",
+			g.constructionf(variant, "%sFromProto"),
+			g.parametrisedf(variant, "%s"),
+		))
+		body.WriteString(fmt.Sprintf("\tresult := new(%s)\n", g.parametrisedf(variant, "%s")))
+		body.WriteString(fmt.Sprintf("\terr := result.UnmarshalProto(x)\n"))
+		body.WriteString(fmt.Sprintf("\tif err != nil {\n"))
+		body.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s %%w\", err)\n", errorContext))
+		body.WriteString(fmt.Sprintf("\t}\n"))
+		body.WriteString(fmt.Sprintf("\treturn result, nil\n"))
+		body.WriteString(fmt.Sprintf("}\n\n"))
+
+		// to proto func
+		body.WriteString(fmt.Sprintf("func %s(x *%s) ([]byte, error) {\n",
+			g.constructionf(variant, "%sToProto"),
+			g.parametrisedf(variant, "%s"),
+		))
+		body.WriteString(fmt.Sprintf("\treturn x.MarshalProto()\n"))
+		body.WriteString(fmt.Sprintf("}\n\n"))
+
+		serde := NewSerdeProtoTagged(variant, g.lock)
+		serde.SkipImportsAndPackage(true)
+		result, err := serde.Generate()
+		if err != nil {
+			return nil, fmt.Errorf("generators.SerdeProtoUnion.GenerateVariantsFromToFunc: %s; %w", shape.Name(variant), err)
+		}
+		g.pkgUsed = MergePkgMaps(g.pkgUsed, serde.ExtractImports(variant))
+
+		body.WriteString(result)
+		body.WriteString("\n")
+	}
+
+	return body.Bytes(), nil
+}
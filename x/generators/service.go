@@ -0,0 +1,118 @@
+package generators
+
+import (
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"strings"
+)
+
+// NewServiceUnion generates HTTP router and client for union of commands tagged with //go:tag service:"Response".
+// Each variant of union is a command with its own endpoint, that responds with the same response type.
+func NewServiceUnion(union *shape.UnionLike) *ServiceUnion {
+	return &ServiceUnion{
+		union:                 union,
+		skipImportsAndPackage: false,
+	}
+}
+
+type ServiceUnion struct {
+	union                 *shape.UnionLike
+	skipImportsAndPackage bool
+}
+
+func (g *ServiceUnion) SkipImportsAndPackage(flag bool) *ServiceUnion {
+	g.skipImportsAndPackage = flag
+	return g
+}
+
+func (g *ServiceUnion) Generate() (string, error) {
+	body, err := g.GenerateService()
+	if err != nil {
+		return "", fmt.Errorf("generators.ServiceUnion.Generate: %w", err)
+	}
+
+	if g.skipImportsAndPackage {
+		return body, nil
+	}
+
+	head := &strings.Builder{}
+	head.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.union)))
+	head.WriteString(GenerateImports(g.ExtractImports()))
+	head.WriteString(body)
+	return head.String(), nil
+}
+
+func (g *ServiceUnion) ExtractImports() PkgMap {
+	return PkgMap{
+		"context": "context",
+		"http":    "net/http",
+		"service": "github.com/widmogrod/mkunion/x/service",
+	}
+}
+
+func (g *ServiceUnion) GenerateService() (string, error) {
+	if len(g.union.TypeParams) > 0 {
+		return "", fmt.Errorf("generators.ServiceUnion.GenerateService: generic union %s cannot be a service", g.union.Name)
+	}
+
+	if len(g.union.Variant) == 0 {
+		return "", fmt.Errorf("generators.ServiceUnion.GenerateService: union %s has no commands", g.union.Name)
+	}
+
+	response, ok := shape.ServiceResponse(g.union)
+	if !ok {
+		return "", fmt.Errorf("generators.ServiceUnion.GenerateService: union %s has no %s tag", g.union.Name, shape.TagServiceName)
+	}
+
+	name := g.union.Name
+	responseName := response.Name
+
+	result := &strings.Builder{}
+	result.WriteString(fmt.Sprintf("// %sService handles commands of %s union, one method per variant.\n", name, name))
+	result.WriteString(fmt.Sprintf("// It's served over HTTP by New%sHTTPHandler, and called by %sHTTPClient.\n", name, name))
+	result.WriteString(fmt.Sprintf("type %sService interface {\n", name))
+	for _, variant := range g.union.Variant {
+		result.WriteString(fmt.Sprintf("\t%s(ctx context.Context, x *%s) (%s, error)\n", shape.Name(variant), shape.Name(variant), responseName))
+	}
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	result.WriteString(fmt.Sprintf("// %sServiceFunc handles all commands of %s union with a single function.\n", name, name))
+	result.WriteString(fmt.Sprintf("type %sServiceFunc func(ctx context.Context, x %s) (%s, error)\n\n", name, name, responseName))
+	result.WriteString(fmt.Sprintf("var _ %sService = %sServiceFunc(nil)\n\n", name, name))
+	for _, variant := range g.union.Variant {
+		result.WriteString(fmt.Sprintf("func (f %sServiceFunc) %s(ctx context.Context, x *%s) (%s, error) {\n", name, shape.Name(variant), shape.Name(variant), responseName))
+		result.WriteString(fmt.Sprintf("\treturn f(ctx, x)\n"))
+		result.WriteString(fmt.Sprintf("}\n\n"))
+	}
+
+	result.WriteString(fmt.Sprintf("// New%sHTTPHandler routes each command of %s union to its endpoint, like POST %s.\n", name, name, shape.ServicePath(g.union.Variant[0])))
+	result.WriteString(fmt.Sprintf("// Request and response bodies are encoded with JSON serde, and requests are validated before handling.\n"))
+	result.WriteString(fmt.Sprintf("func New%sHTTPHandler(s %sService) http.Handler {\n", name, name))
+	result.WriteString(fmt.Sprintf("\tmux := http.NewServeMux()\n"))
+	for _, variant := range g.union.Variant {
+		result.WriteString(fmt.Sprintf("\tmux.Handle(%q, service.HandleJSON(s.%s))\n", shape.ServicePath(variant), shape.Name(variant)))
+	}
+	result.WriteString(fmt.Sprintf("\treturn mux\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	result.WriteString(fmt.Sprintf("// New%sHTTPClient calls %sService served by New%sHTTPHandler under baseURL.\n", name, name, name))
+	result.WriteString(fmt.Sprintf("// When client is nil, http.DefaultClient is used.\n"))
+	result.WriteString(fmt.Sprintf("func New%sHTTPClient(baseURL string, client *http.Client) *%sHTTPClient {\n", name, name))
+	result.WriteString(fmt.Sprintf("\treturn &%sHTTPClient{\n", name))
+	result.WriteString(fmt.Sprintf("\t\tbaseURL: baseURL,\n"))
+	result.WriteString(fmt.Sprintf("\t\tclient:  client,\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+	result.WriteString(fmt.Sprintf("type %sHTTPClient struct {\n", name))
+	result.WriteString(fmt.Sprintf("\tbaseURL string\n"))
+	result.WriteString(fmt.Sprintf("\tclient  *http.Client\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+	result.WriteString(fmt.Sprintf("var _ %sService = (*%sHTTPClient)(nil)\n\n", name, name))
+	for _, variant := range g.union.Variant {
+		result.WriteString(fmt.Sprintf("func (c *%sHTTPClient) %s(ctx context.Context, x *%s) (%s, error) {\n", name, shape.Name(variant), shape.Name(variant), responseName))
+		result.WriteString(fmt.Sprintf("\treturn service.CallJSON[*%s, %s](ctx, c.client, c.baseURL+%q, x)\n", shape.Name(variant), responseName, shape.ServicePath(variant)))
+		result.WriteString(fmt.Sprintf("}\n\n"))
+	}
+
+	return result.String(), nil
+}
@@ -0,0 +1,104 @@
+package generators
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+)
+
+func TestNewServiceUnion(t *testing.T) {
+	generator := NewServiceUnion(&shape.UnionLike{
+		Name:          "Command",
+		PkgName:       "pkg",
+		PkgImportName: "example.com/pkg",
+		Variant: []shape.Shape{
+			&shape.StructLike{Name: "Create", PkgName: "pkg", PkgImportName: "example.com/pkg"},
+			&shape.StructLike{Name: "Delete", PkgName: "pkg", PkgImportName: "example.com/pkg"},
+		},
+		Tags: map[string]shape.Tag{
+			"mkunion": {Value: "Command"},
+			"service": {Value: "State"},
+		},
+	})
+
+	result, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, `package pkg
+
+import (
+	"context"
+	"github.com/widmogrod/mkunion/x/service"
+	"net/http"
+)
+
+// CommandService handles commands of Command union, one method per variant.
+// It's served over HTTP by NewCommandHTTPHandler, and called by CommandHTTPClient.
+type CommandService interface {
+	Create(ctx context.Context, x *Create) (State, error)
+	Delete(ctx context.Context, x *Delete) (State, error)
+}
+
+// CommandServiceFunc handles all commands of Command union with a single function.
+type CommandServiceFunc func(ctx context.Context, x Command) (State, error)
+
+var _ CommandService = CommandServiceFunc(nil)
+
+func (f CommandServiceFunc) Create(ctx context.Context, x *Create) (State, error) {
+	return f(ctx, x)
+}
+
+func (f CommandServiceFunc) Delete(ctx context.Context, x *Delete) (State, error) {
+	return f(ctx, x)
+}
+
+// NewCommandHTTPHandler routes each command of Command union to its endpoint, like POST /Create.
+// Request and response bodies are encoded with JSON serde, and requests are validated before handling.
+func NewCommandHTTPHandler(s CommandService) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/Create", service.HandleJSON(s.Create))
+	mux.Handle("/Delete", service.HandleJSON(s.Delete))
+	return mux
+}
+
+// NewCommandHTTPClient calls CommandService served by NewCommandHTTPHandler under baseURL.
+// When client is nil, http.DefaultClient is used.
+func NewCommandHTTPClient(baseURL string, client *http.Client) *CommandHTTPClient {
+	return &CommandHTTPClient{
+		baseURL: baseURL,
+		client:  client,
+	}
+}
+
+type CommandHTTPClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+var _ CommandService = (*CommandHTTPClient)(nil)
+
+func (c *CommandHTTPClient) Create(ctx context.Context, x *Create) (State, error) {
+	return service.CallJSON[*Create, State](ctx, c.client, c.baseURL+"/Create", x)
+}
+
+func (c *CommandHTTPClient) Delete(ctx context.Context, x *Delete) (State, error) {
+	return service.CallJSON[*Delete, State](ctx, c.client, c.baseURL+"/Delete", x)
+}
+
+`, result)
+}
+
+func TestNewServiceUnion_Errors(t *testing.T) {
+	_, err := NewServiceUnion(&shape.UnionLike{
+		Name:       "Result",
+		TypeParams: []shape.TypeParam{{Name: "T", Type: &shape.Any{}}},
+		Variant:    []shape.Shape{&shape.StructLike{Name: "Ok"}},
+		Tags:       map[string]shape.Tag{"service": {Value: "State"}},
+	}).Generate()
+	assert.ErrorContains(t, err, "generic union Result cannot be a service")
+
+	_, err = NewServiceUnion(&shape.UnionLike{
+		Name:    "Command",
+		Variant: []shape.Shape{&shape.StructLike{Name: "Create"}},
+	}).Generate()
+	assert.ErrorContains(t, err, "union Command has no service tag")
+}
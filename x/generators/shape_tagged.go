@@ -451,6 +451,9 @@ func FieldLikeToString(x *shape.FieldLike, removeTypeName bool) string {
 	if len(x.Tags) > 0 {
 		fmt.Fprintf(result, "\tTags: %s,\n", padLeftTabs2(1, TagsToStr(x.Tags)))
 	}
+	if x.Embedded {
+		fmt.Fprintf(result, "\tEmbedded: true,\n")
+	}
 	fmt.Fprintf(result, "}")
 
 	return result.String()
@@ -481,12 +484,30 @@ func GuardToString(x shape.Guard) string {
 		func(x *shape.Required) string {
 			return "&shape.Required{}"
 		},
+		func(x *shape.Regexp) string {
+			return fmt.Sprintf("&shape.Regexp{\n\tRegexp: %q,\n}", x.Regexp)
+		},
+		func(x *shape.Between) string {
+			return fmt.Sprintf("&shape.Between{\n\tMin: %v,\n\tMax: %v,\n}", x.Min, x.Max)
+		},
+		func(x *shape.MinLength) string {
+			return fmt.Sprintf("&shape.MinLength{\n\tLen: %d,\n}", x.Len)
+		},
+		func(x *shape.MaxLength) string {
+			return fmt.Sprintf("&shape.MaxLength{\n\tLen: %d,\n}", x.Len)
+		},
+		func(x *shape.MinItems) string {
+			return fmt.Sprintf("&shape.MinItems{\n\tLen: %d,\n}", x.Len)
+		},
+		func(x *shape.MaxItems) string {
+			return fmt.Sprintf("&shape.MaxItems{\n\tLen: %d,\n}", x.Len)
+		},
 		func(x *shape.AndGuard) string {
 			result := &bytes.Buffer{}
 
 			fmt.Fprintf(result, "&shape.AndGuard{\n")
 			if len(x.L) > 0 {
-				fmt.Fprintf(result, "\tGuards: []shape.Guard{\n")
+				fmt.Fprintf(result, "\tL: []shape.Guard{\n")
 				for _, guard := range x.L {
 					fmt.Fprintf(result, "%s,\n", padLeftTabs(2, GuardToString(guard)))
 				}
@@ -0,0 +1,92 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/schema"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+	"time"
+)
+
+func TestTree_EqualHashDeepCopy(t *testing.T) {
+	newTree := func() Tree {
+		return &Branch{
+			Lit: &Leaf{Value: 1},
+			List: []Tree{
+				shape.Ptr(K("alpha")),
+				&Ma{"op": &Leaf{Value: 2}},
+			},
+			Map: map[string]Tree{
+				"zp": &La{&Leaf{Value: 3}},
+				"ko": &Ka{{"lp": shape.Ptr(K("beta"))}},
+			},
+			Of:     &ListOf[Tree]{Data: &Leaf{Value: 4}},
+			L:      &Leaf{Value: 5},
+			Kattr:  [2]*Leaf{{Value: 6}, nil},
+			IntPtr: shape.Ptr(int64(7)),
+		}
+	}
+
+	subject := newTree()
+	assert.True(t, TreeEqual(subject, newTree()))
+	assert.Equal(t, TreeHash(subject), TreeHash(newTree()))
+
+	copied := TreeDeepCopy(subject)
+	assert.True(t, TreeEqual(subject, copied))
+
+	(*copied.(*Branch).Map["zp"].(*La))[0].(*Leaf).Value = 100
+	(*copied.(*Branch).List[1].(*Ma))["op"] = &Leaf{Value: 200}
+	*copied.(*Branch).IntPtr = 300
+	copied.(*Branch).Kattr[0].Value = 400
+	assert.True(t, TreeEqual(subject, newTree()), "deep copy must not share memory with original")
+	assert.False(t, TreeEqual(subject, copied))
+	assert.NotEqual(t, TreeHash(subject), TreeHash(copied))
+
+	assert.False(t, TreeEqual(&Leaf{Value: 1}, shape.Ptr(K("1"))))
+	assert.True(t, TreeEqual(nil, nil))
+	assert.Nil(t, TreeDeepCopy(nil))
+}
+
+func TestTree_EqualNilAndEmpty(t *testing.T) {
+	assert.True(t, TreeEqual(&Branch{Map: nil}, &Branch{Map: map[string]Tree{}}))
+	assert.Equal(t, TreeHash(&Branch{Map: nil}), TreeHash(&Branch{Map: map[string]Tree{}}))
+}
+
+func TestTree_HashMapOrder(t *testing.T) {
+	a, b := Ma{}, Ma{}
+	for i, key := range []string{"a", "b", "c", "d", "e"} {
+		a[key] = &Leaf{Value: int64(i)}
+	}
+	for i, key := range []string{"e", "d", "c", "b", "a"} {
+		b[key] = &Leaf{Value: int64(4 - i)}
+	}
+
+	assert.Equal(t, TreeHash(&a), TreeHash(&b))
+}
+
+func TestListOf2_EqualHashDeepCopy(t *testing.T) {
+	newList := func() *ListOf2[string, *Leaf] {
+		return &ListOf2[string, *Leaf]{
+			ID:        "id",
+			Data:      "data",
+			List:      []*Leaf{{Value: 1}},
+			Map:       map[string]*Leaf{"a": {Value: 2}},
+			ListOf:    ListOf[string]{Data: "of"},
+			ListOfPtr: &ListOf[*Leaf]{Data: &Leaf{Value: 3}},
+			Time:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Value:     schema.MkMap(schema.MkField("x", schema.MkList(schema.MkInt(1)))),
+		}
+	}
+
+	subject := newList()
+	assert.True(t, subject.Equal(newList()))
+	assert.Equal(t, subject.Hash(), newList().Hash())
+
+	copied := subject.DeepCopy()
+	copied.List[0].Value = 100
+	copied.Map["a"].Value = 200
+	copied.ListOfPtr.Data.Value = 300
+	(*(*copied.Value.(*schema.Map))["x"].(*schema.List))[0] = schema.MkInt(400)
+	assert.True(t, subject.Equal(newList()), "deep copy must not share memory with original")
+	assert.False(t, subject.Equal(copied))
+}
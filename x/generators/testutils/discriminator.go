@@ -0,0 +1,17 @@
+package testutils
+
+// Shipment variants are stored under explicit names, that don't depend on Go type names:
+//   - Dispatched has custom discriminator,
+//   - Delivered was renamed from Arrived, and before that it was declared in package logistics.
+//
+//go:tag mkunion:"Shipment" serde:"json,msgpack"
+type (
+	//go:tag variant:"shipment.dispatched"
+	Dispatched struct {
+		Carrier string
+	}
+	//go:tag alias:"Arrived,logistics.Arrived"
+	Delivered struct {
+		SignedBy string
+	}
+)
@@ -0,0 +1,61 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shared"
+	"testing"
+)
+
+func TestShipment_JSON(t *testing.T) {
+	useCases := map[string]struct {
+		data     string
+		expected Shipment
+	}{
+		"custom discriminator": {
+			data:     `{"$type": "shipment.dispatched", "shipment.dispatched": {"Carrier": "ups"}}`,
+			expected: &Dispatched{Carrier: "ups"},
+		},
+		"current name": {
+			data:     `{"$type": "testutils.Delivered", "testutils.Delivered": {"SignedBy": "bob"}}`,
+			expected: &Delivered{SignedBy: "bob"},
+		},
+		"alias in the same package": {
+			data:     `{"$type": "testutils.Arrived", "testutils.Arrived": {"SignedBy": "bob"}}`,
+			expected: &Delivered{SignedBy: "bob"},
+		},
+		"alias from other package": {
+			data:     `{"$type": "logistics.Arrived", "logistics.Arrived": {"SignedBy": "bob"}}`,
+			expected: &Delivered{SignedBy: "bob"},
+		},
+		"alias without $type": {
+			data:     `{"logistics.Arrived": {"SignedBy": "bob"}}`,
+			expected: &Delivered{SignedBy: "bob"},
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := shared.JSONUnmarshal[Shipment]([]byte(uc.data))
+			assert.NoError(t, err)
+			assert.Equal(t, uc.expected, result)
+		})
+	}
+}
+
+func TestShipment_MarshalWithCurrentName(t *testing.T) {
+	data, err := shared.JSONMarshal[Shipment](&Dispatched{Carrier: "ups"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"$type": "shipment.dispatched", "shipment.dispatched": {"Carrier": "ups"}}`, string(data))
+
+	data, err = shared.JSONMarshal[Shipment](&Delivered{SignedBy: "bob"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"$type": "testutils.Delivered", "testutils.Delivered": {"SignedBy": "bob"}}`, string(data))
+}
+
+func TestShipment_MsgpackAlias(t *testing.T) {
+	payload, err := (&Delivered{SignedBy: "bob"}).MarshalMsgpack()
+	assert.NoError(t, err)
+
+	result, err := shared.MsgpackUnmarshal[Shipment](shared.MsgpackEncodeUnion("logistics.Arrived", payload))
+	assert.NoError(t, err)
+	assert.Equal(t, &Delivered{SignedBy: "bob"}, result)
+}
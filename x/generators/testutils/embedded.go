@@ -0,0 +1,27 @@
+package testutils
+
+// TaskBase has fields shared by every Task variant,
+// that are promoted to variants, like in encoding/json.
+type TaskBase struct {
+	ID      string
+	Retries int `json:"retries"`
+}
+
+type TaskAudit struct {
+	CreatedBy string
+}
+
+//go:tag mkunion:"Task"
+type (
+	Queued struct {
+		TaskBase
+		*TaskAudit
+		Queue string
+	}
+	Done struct {
+		TaskBase
+		// ID shadows ID of TaskBase
+		ID     string
+		Result string
+	}
+)
@@ -0,0 +1,55 @@
+package testutils
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shared"
+	"testing"
+)
+
+func TestTask_EmbeddedFieldsLikeEncodingJSON(t *testing.T) {
+	// types without generated methods, that are serialised by encoding/json
+	type queued Queued
+	type done Done
+
+	useCases := map[string]struct {
+		in       Task
+		expected any
+		decoded  Task
+	}{
+		"promotes fields of embedded struct": {
+			in:       &Queued{TaskBase: TaskBase{ID: "1", Retries: 2}, Queue: "default"},
+			expected: queued{TaskBase: TaskBase{ID: "1", Retries: 2}, Queue: "default"},
+			decoded:  &Queued{TaskBase: TaskBase{ID: "1", Retries: 2}, Queue: "default"},
+		},
+		"promotes fields of embedded pointer": {
+			in:       &Queued{TaskBase: TaskBase{ID: "1"}, TaskAudit: &TaskAudit{CreatedBy: "bob"}},
+			expected: queued{TaskBase: TaskBase{ID: "1"}, TaskAudit: &TaskAudit{CreatedBy: "bob"}},
+			decoded:  &Queued{TaskBase: TaskBase{ID: "1"}, TaskAudit: &TaskAudit{CreatedBy: "bob"}},
+		},
+		"field declared in struct shadows promoted field": {
+			in:       &Done{TaskBase: TaskBase{ID: "1", Retries: 3}, ID: "2", Result: "ok"},
+			expected: done{TaskBase: TaskBase{ID: "1", Retries: 3}, ID: "2", Result: "ok"},
+			decoded:  &Done{TaskBase: TaskBase{Retries: 3}, ID: "2", Result: "ok"},
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := shared.JSONMarshal[Task](uc.in)
+			assert.NoError(t, err)
+
+			var envelope map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal(data, &envelope))
+			var variantName string
+			assert.NoError(t, json.Unmarshal(envelope["$type"], &variantName))
+
+			expected, err := json.Marshal(uc.expected)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(envelope[variantName]))
+
+			result, err := shared.JSONUnmarshal[Task](data)
+			assert.NoError(t, err)
+			assert.Equal(t, uc.decoded, result)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package testutils
+
+// Figure is internally tagged: {"kind": "circle", "radius": 1}
+//
+//go:tag mkunion:"Figure" json:"internal,tag=kind"
+type (
+	//go:tag variant:"circle"
+	Circle struct {
+		Radius float64 `json:"radius"`
+	}
+	//go:tag variant:"square"
+	Square struct {
+		Side float64 `json:"side"`
+	}
+)
+
+// Notice is adjacently tagged: {"t": "info", "c": {"Text": "hello"}}
+//
+//go:tag mkunion:"Notice" json:"adjacent,tag=t,content=c"
+type (
+	//go:tag variant:"info" alias:"notice.info"
+	Info struct {
+		Text string
+	}
+	//go:tag variant:"alert"
+	Alert struct {
+		Level int
+	}
+)
+
+// Scalar is untagged: 1 or "hello"
+//
+//go:tag mkunion:"Scalar" json:"untagged"
+type (
+	Num  float64
+	Word string
+)
+
+// Plate is untagged, and its variant is found by keys of JSON object: {"radius": 1} or {"side": 2}
+//
+//go:tag mkunion:"Plate" json:"untagged"
+type (
+	Disc struct {
+		Radius float64 `json:"radius"`
+	}
+	Tile struct {
+		Side  float64 `json:"side"`
+		Label string  `json:"label,omitempty"`
+	}
+)
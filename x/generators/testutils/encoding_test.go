@@ -0,0 +1,93 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"github.com/widmogrod/mkunion/x/shared"
+	"testing"
+)
+
+func TestFigure_InternallyTagged(t *testing.T) {
+	data, err := shared.JSONMarshal[Figure](&Circle{Radius: 1})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kind": "circle", "radius": 1}`, string(data))
+
+	data, err = shared.JSONMarshal[Figure](&Square{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kind": "square", "side": 0}`, string(data))
+
+	result, err := shared.JSONUnmarshal[Figure]([]byte(`{"radius": 2, "kind": "circle"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Circle{Radius: 2}, result)
+
+	_, err = shared.JSONUnmarshal[Figure]([]byte(`{"kind": "triangle"}`))
+	assert.ErrorContains(t, err, "unknown type: triangle")
+}
+
+func TestNotice_AdjacentlyTagged(t *testing.T) {
+	data, err := shared.JSONMarshal[Notice](&Info{Text: "hello"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"t": "info", "c": {"Text": "hello"}}`, string(data))
+
+	result, err := shared.JSONUnmarshal[Notice]([]byte(`{"t": "alert", "c": {"Level": 3}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Alert{Level: 3}, result)
+
+	result, err = shared.JSONUnmarshal[Notice]([]byte(`{"t": "notice.info", "c": {"Text": "old"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Info{Text: "old"}, result)
+}
+
+func TestScalar_Untagged(t *testing.T) {
+	data, err := shared.JSONMarshal[Scalar](shape.Ptr(Word("hello")))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `"hello"`, string(data))
+
+	result, err := shared.JSONUnmarshal[Scalar]([]byte(`1.5`))
+	assert.NoError(t, err)
+	assert.Equal(t, shape.Ptr(Num(1.5)), result)
+
+	result, err = shared.JSONUnmarshal[Scalar]([]byte(`"hello"`))
+	assert.NoError(t, err)
+	assert.Equal(t, shape.Ptr(Word("hello")), result)
+
+	_, err = shared.JSONUnmarshal[Scalar]([]byte(`true`))
+	assert.ErrorContains(t, err, "value does not match any variant: true")
+}
+
+func TestPlate_UntaggedStructs(t *testing.T) {
+	useCases := map[string]struct {
+		in   Plate
+		json string
+	}{
+		"disc": {
+			in:   &Disc{Radius: 1},
+			json: `{"radius": 1}`,
+		},
+		"tile": {
+			in:   &Tile{Side: 2},
+			json: `{"side": 2}`,
+		},
+		"tile with label": {
+			in:   &Tile{Side: 2, Label: "a"},
+			json: `{"side": 2, "label": "a"}`,
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := shared.JSONMarshal[Plate](uc.in)
+			assert.NoError(t, err)
+			assert.JSONEq(t, uc.json, string(data))
+
+			result, err := shared.JSONUnmarshal[Plate](data)
+			assert.NoError(t, err)
+			assert.Equal(t, uc.in, result)
+		})
+	}
+
+	_, err := shared.JSONUnmarshal[Plate]([]byte(`{}`))
+	assert.ErrorContains(t, err, "value matches variants [testutils.Disc testutils.Tile]: {}")
+
+	_, err = shared.JSONUnmarshal[Plate]([]byte(`{"radius": 1, "side": 2}`))
+	assert.ErrorContains(t, err, "value does not match any variant")
+}
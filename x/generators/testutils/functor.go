@@ -0,0 +1,24 @@
+package testutils
+
+//go:tag mkunion:"Expr[K, A],derive=functor"
+type (
+	Lit[K, A any] struct {
+		Value A
+		Note  *string
+	}
+	Var[K, A any] struct {
+		Name K
+	}
+	Call[K, A any] struct {
+		Fn   Expr[K, A]
+		Args []Expr[K, A]
+		Opt  *A
+	}
+	Let[K, A any] struct {
+		Bind map[string]Expr[K, A]
+		Pair [2]A
+		Meta map[string][]A
+		In   Expr[K, A]
+	}
+	Nop[K, A any] struct{}
+)
@@ -0,0 +1,102 @@
+package testutils
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func exprExample() Expr[string, int] {
+	note := "note"
+	opt := 4
+
+	return &Let[string, int]{
+		Bind: map[string]Expr[string, int]{
+			"x": &Lit[string, int]{Value: 1, Note: &note},
+		},
+		Pair: [2]int{2, 3},
+		Meta: map[string][]int{
+			"m": {5},
+		},
+		In: &Call[string, int]{
+			Fn: &Var[string, int]{Name: "f"},
+			Args: []Expr[string, int]{
+				&Lit[string, int]{Value: 6},
+				&Nop[string, int]{},
+			},
+			Opt: &opt,
+		},
+	}
+}
+
+func TestMapExpr(t *testing.T) {
+	note := "note"
+	opt := "4"
+
+	result := MapExpr(exprExample(), strconv.Itoa)
+	assert.Equal(t, &Let[string, string]{
+		Bind: map[string]Expr[string, string]{
+			"x": &Lit[string, string]{Value: "1", Note: &note},
+		},
+		Pair: [2]string{"2", "3"},
+		Meta: map[string][]string{
+			"m": {"5"},
+		},
+		In: &Call[string, string]{
+			Fn: &Var[string, string]{Name: "f"},
+			Args: []Expr[string, string]{
+				&Lit[string, string]{Value: "6"},
+				&Nop[string, string]{},
+			},
+			Opt: &opt,
+		},
+	}, result)
+
+	assert.Nil(t, MapExpr[string, int](nil, strconv.Itoa))
+}
+
+func TestTraverseExpr(t *testing.T) {
+	result, err := TraverseExpr(exprExample(), func(x int) (int, error) {
+		return x * 10, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 210, FoldExpr(result, func(x, agg int) int {
+		return x + agg
+	}, 0))
+
+	expected := errors.New("odd value")
+	_, err = TraverseExpr(exprExample(), func(x int) (int, error) {
+		if x == 3 {
+			return 0, expected
+		}
+		return x, nil
+	})
+	assert.ErrorIs(t, err, expected)
+}
+
+func TestFoldExpr(t *testing.T) {
+	result := FoldExpr(exprExample(), func(x int, agg []int) []int {
+		return append(agg, x)
+	}, nil)
+	assert.Equal(t, []int{1, 2, 3, 5, 6, 4}, result)
+
+	assert.Equal(t, 7, FoldExpr[string, int](nil, func(x, agg int) int {
+		return x + agg
+	}, 7))
+}
+
+func TestExprEqual_ExprDeepCopy(t *testing.T) {
+	x := exprExample()
+	y := ExprDeepCopy(x)
+	assert.True(t, ExprEqual(x, y))
+	assert.True(t, ExprEqual[string, int](nil, nil))
+	assert.False(t, ExprEqual(x, nil))
+
+	y.(*Let[string, int]).In.(*Call[string, int]).Args[0].(*Lit[string, int]).Value = 7
+	assert.False(t, ExprEqual(x, y))
+	assert.Equal(t, 6, x.(*Let[string, int]).In.(*Call[string, int]).Args[0].(*Lit[string, int]).Value)
+
+	*y.(*Let[string, int]).In.(*Call[string, int]).Opt = 8
+	assert.Equal(t, 4, *x.(*Let[string, int]).In.(*Call[string, int]).Opt)
+}
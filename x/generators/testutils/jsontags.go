@@ -0,0 +1,39 @@
+package testutils
+
+import (
+	"time"
+)
+
+type Quantity int
+
+type Dimensions struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+//go:tag serde:"json"
+type Parcel struct {
+	ID        string            `json:"id"`
+	Label     string            `json:"label,omitempty"`
+	Count     Quantity          `json:"count,omitempty"`
+	Fragile   bool              `json:"fragile,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	Meta      map[string]string `json:"meta,omitempty"`
+	Note      *string           `json:"note,omitempty"`
+	Shipped   time.Time         `json:"shipped,omitzero"`
+	Slots     []int             `json:"slots,omitzero"`
+	Weight    float64           `json:"weight,string"`
+	Insured   *bool             `json:"insured,string,omitempty"`
+	Internal  string            `json:"-"`
+	Dash      string            `json:"-,"`
+	Recipient Dimensions        `json:"recipient,omitempty"`
+}
+
+// Crate has fields of Dimensions inlined, like fields of embedded struct,
+// the way `inline` option of encoding/json/v2 does it.
+//
+//go:tag serde:"json"
+type Crate struct {
+	ID   string     `json:"id"`
+	Size Dimensions `json:",inline"`
+}
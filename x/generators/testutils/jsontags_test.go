@@ -0,0 +1,77 @@
+package testutils
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shared"
+	"testing"
+	"time"
+)
+
+func TestParcel_JSONTagsLikeEncodingJSON(t *testing.T) {
+	// parcel is serialised by encoding/json, since it has no generated methods
+	type parcel Parcel
+
+	note := "handle with care"
+	insured := true
+
+	useCases := map[string]struct {
+		in       Parcel
+		expected string
+	}{
+		"empty and zero fields are omitted": {
+			in:       Parcel{ID: "1", Slots: []int{}},
+			expected: `{"id":"1","slots":[],"weight":"0","-":"","recipient":{"width":0,"height":0}}`,
+		},
+		"all fields are set": {
+			in: Parcel{
+				ID:        "2",
+				Label:     "box",
+				Count:     3,
+				Fragile:   true,
+				Tags:      []string{"a"},
+				Meta:      map[string]string{"k": "v"},
+				Note:      &note,
+				Shipped:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Slots:     []int{1},
+				Weight:    1.5,
+				Insured:   &insured,
+				Internal:  "not serialised",
+				Dash:      "dash",
+				Recipient: Dimensions{Width: 6, Height: 7},
+			},
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			expected, err := json.Marshal(parcel(uc.in))
+			assert.NoError(t, err)
+			if uc.expected != "" {
+				assert.JSONEq(t, uc.expected, string(expected))
+			}
+
+			data, err := shared.JSONMarshal[Parcel](uc.in)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(data))
+
+			var stdlib parcel
+			assert.NoError(t, json.Unmarshal(expected, &stdlib))
+
+			result, err := shared.JSONUnmarshal[Parcel](data)
+			assert.NoError(t, err)
+			assert.Equal(t, Parcel(stdlib), result)
+		})
+	}
+}
+
+func TestCrate_JSONInline(t *testing.T) {
+	in := Crate{ID: "1", Size: Dimensions{Width: 4, Height: 5}}
+
+	data, err := shared.JSONMarshal[Crate](in)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1","width":4,"height":5}`, string(data))
+
+	result, err := shared.JSONUnmarshal[Crate](data)
+	assert.NoError(t, err)
+	assert.Equal(t, in, result)
+}
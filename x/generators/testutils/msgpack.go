@@ -0,0 +1,28 @@
+package testutils
+
+//go:tag mkunion:"Blob" serde:"json,msgpack"
+type (
+	Document struct {
+		ID       string `json:"id"`
+		Version  uint32
+		Size     int64
+		Ratio    float32
+		Tags     []string
+		Attrs    map[string]any
+		Children []Blob
+		Parent   *Document
+		Content  []byte
+		Checksum [4]byte
+	}
+	Tombstone struct{}
+	Link      string
+)
+
+//go:tag serde:"msgpack"
+type Catalog struct {
+	Items  map[string]Blob
+	Counts map[int8]uint64
+	Grid   [][]int
+	Flags  map[bool]string
+	Head   Blob
+}
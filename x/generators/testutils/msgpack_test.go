@@ -0,0 +1,68 @@
+package testutils
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"github.com/widmogrod/mkunion/x/shared"
+	"testing"
+)
+
+func TestBlob_Msgpack(t *testing.T) {
+	subject := Catalog{
+		Items: map[string]Blob{
+			"doc": &Document{
+				ID:       "d1",
+				Version:  300,
+				Size:     -70000,
+				Ratio:    0.5,
+				Tags:     []string{"a", ""},
+				Attrs:    map[string]any{"k": "v", "n": int64(-1), "l": []any{true, nil}},
+				Children: []Blob{&Tombstone{}, shape.Ptr(Link("to"))},
+				Parent:   &Document{ID: "root", Tags: []string{}},
+				Content:  []byte("bin"),
+				Checksum: [4]byte{1, 2, 3, 4},
+			},
+			"none": nil,
+		},
+		Counts: map[int8]uint64{-1: 1 << 40, 2: 0},
+		Grid:   [][]int{{1, 2}, nil, {}},
+		Flags:  map[bool]string{true: "yes"},
+		Head:   &Tombstone{},
+	}
+
+	data, err := shared.MsgpackMarshal[Catalog](subject)
+	assert.NoError(t, err)
+
+	result, err := shared.MsgpackUnmarshal[Catalog](data)
+	assert.NoError(t, err)
+	assert.Equal(t, subject, result)
+}
+
+func TestBlob_MsgpackDiscriminator(t *testing.T) {
+	// union is encoded like in JSON, as a map with $type and variant payload
+	data, err := shared.MsgpackMarshal[Blob](shape.Ptr(Link("x")))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{
+		0x82,
+		0xa5, '$', 't', 'y', 'p', 'e',
+		0xae, 't', 'e', 's', 't', 'u', 't', 'i', 'l', 's', '.', 'L', 'i', 'n', 'k',
+		0xae, 't', 'e', 's', 't', 'u', 't', 'i', 'l', 's', '.', 'L', 'i', 'n', 'k',
+		0xa1, 'x',
+	}, data)
+
+	jsonData, err := shared.JSONMarshal[Blob](shape.Ptr(Link("x")))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"$type":"testutils.Link","testutils.Link":"x"}`, string(jsonData))
+
+	// unknown fields are skipped, and struct keys are the same as in JSON
+	var doc map[string]any
+	err = json.Unmarshal([]byte(`{"id":"d1","Unknown":[1,{"a":2}]}`), &doc)
+	assert.NoError(t, err)
+	data, err = shared.MsgpackMarshal[map[string]any](doc)
+	assert.NoError(t, err)
+
+	result, err := DocumentFromMsgpack(data)
+	assert.NoError(t, err)
+	assert.Equal(t, &Document{ID: "d1"}, result)
+}
@@ -0,0 +1,43 @@
+package testutils
+
+//go:tag mkunion:"Event" serde:"proto"
+type (
+	Created struct {
+		ID     string
+		Tags   []string
+		Counts map[string]int
+		Matrix [][]float64
+		Meta   any
+		Parent *Created
+		Score  *float32
+		Data   []byte
+	}
+	Deleted struct {
+		ID string
+		At int64
+	}
+	Renamed Label
+)
+
+//go:tag serde:"json,proto"
+type Label string
+
+// EventID is week alias, that is encoded as string
+//
+//go:tag serde:"json,proto"
+type EventID = string
+
+type Weight = int32
+
+//go:tag serde:"proto"
+type Envelope struct {
+	Events  []Event
+	ByKey   map[int]Event
+	Label   Label
+	Flags   []bool
+	Nested  map[string][]int32
+	ID      EventID
+	Refs    []EventID
+	Weights map[EventID]Weight
+	Parent  *EventID
+}
@@ -0,0 +1,67 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"github.com/widmogrod/mkunion/x/shared"
+	"testing"
+)
+
+func TestEvent_Proto(t *testing.T) {
+	subject := Envelope{
+		Events: []Event{
+			&Created{
+				ID:     "c1",
+				Tags:   []string{"a", ""},
+				Counts: map[string]int{"x": 1, "y": -2},
+				Matrix: [][]float64{{1.5, 2}, nil, {3}},
+				Meta:   map[string]any{"k": "v"},
+				Parent: &Created{ID: "root"},
+				Score:  shape.Ptr(float32(0)),
+				Data:   []byte("bin"),
+			},
+			&Deleted{ID: "d1", At: 150},
+			shape.Ptr(Renamed("new")),
+		},
+		ByKey: map[int]Event{
+			-1: &Deleted{ID: "d2"},
+		},
+		Label:   "label",
+		Flags:   []bool{true, false, true},
+		Nested:  map[string][]int32{"n": {1, -1}},
+		ID:      "e1",
+		Refs:    []EventID{"c1", "d1"},
+		Weights: map[EventID]Weight{"c1": 2, "d1": -1},
+		Parent:  shape.Ptr(EventID("root")),
+	}
+
+	data, err := shared.ProtoMarshal[Envelope](subject)
+	assert.NoError(t, err)
+
+	result, err := shared.ProtoUnmarshal[Envelope](data)
+	assert.NoError(t, err)
+	assert.Equal(t, subject, result)
+}
+
+func TestEvent_ProtoWireFormat(t *testing.T) {
+	// Deleted{ID: "d1", At: 150} is encoded as protobuf message
+	//  field 1, bytes: 0x0a 0x02 'd' '1'
+	//  field 2, varint: 0x10 0x96 0x01
+	data, err := EventToProto(&Deleted{ID: "d1", At: 150})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{
+		// oneof field 2, bytes with length 7
+		0x12, 0x07,
+		0x0a, 0x02, 'd', '1',
+		0x10, 0x96, 0x01,
+	}, data)
+
+	result, err := EventFromProto(data)
+	assert.NoError(t, err)
+	assert.Equal(t, &Deleted{ID: "d1", At: 150}, result)
+
+	// default values are not put on the wire
+	data, err = EventToProto(&Deleted{})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x12, 0x00}, data)
+}
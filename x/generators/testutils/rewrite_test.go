@@ -0,0 +1,69 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+)
+
+func TestRewriteTree(t *testing.T) {
+	newTree := func() Tree {
+		return &Branch{
+			Lit: &Leaf{Value: 1},
+			List: []Tree{
+				&La{&Leaf{Value: 2}},
+			},
+			Map: map[string]Tree{
+				"a": &Ka{{"b": &Leaf{Value: 3}}},
+			},
+		}
+	}
+
+	var order []int64
+	visit := func(x Tree) Tree {
+		switch y := x.(type) {
+		case *Leaf:
+			order = append(order, y.Value)
+			return shape.Ptr(K("leaf"))
+		case *Branch:
+			order = append(order, 0)
+		}
+		return x
+	}
+
+	expected := &Branch{
+		Lit: shape.Ptr(K("leaf")),
+		List: []Tree{
+			&La{shape.Ptr(K("leaf"))},
+		},
+		Map: map[string]Tree{
+			"a": &Ka{{"b": shape.Ptr(K("leaf"))}},
+		},
+	}
+
+	subject := newTree()
+	assert.Equal(t, expected, RewriteTreeTopDown(subject, visit))
+	assert.Equal(t, []int64{0, 1, 2, 3}, order, "parent is visited before nested values")
+	assert.Equal(t, newTree(), subject, "rewrite must not modify original")
+
+	order = nil
+	assert.Equal(t, expected, RewriteTreeBottomUp(subject, visit))
+	assert.Equal(t, []int64{1, 2, 3, 0}, order, "parent is visited after nested values")
+	assert.Equal(t, newTree(), subject, "rewrite must not modify original")
+
+	assert.Nil(t, RewriteTreeTopDown(nil, visit))
+}
+
+func TestRewriteTree_TopDownSeesRewrittenParent(t *testing.T) {
+	var seen []Tree
+	result := RewriteTreeTopDown(&La{&Leaf{Value: 1}}, func(x Tree) Tree {
+		seen = append(seen, x)
+		if _, ok := x.(*La); ok {
+			return &Ma{"x": &Leaf{Value: 2}}
+		}
+		return x
+	})
+
+	assert.Equal(t, &Ma{"x": &Leaf{Value: 2}}, result)
+	assert.Equal(t, []Tree{&La{&Leaf{Value: 1}}, &Leaf{Value: 2}}, seen)
+}
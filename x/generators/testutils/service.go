@@ -0,0 +1,43 @@
+package testutils
+
+import (
+	"context"
+	"fmt"
+	"github.com/widmogrod/mkunion/x/service"
+	"net/http"
+)
+
+//go:tag mkunion:"Calc" service:"Answer" validate:"true"
+type (
+	Add struct {
+		A, B int
+	}
+	Div struct {
+		A int
+		B int `enum:"1,2,5,10"`
+	}
+)
+
+//go:tag mkunion:"Answer"
+type (
+	Value struct {
+		Result float64
+	}
+	Overflow struct{}
+)
+
+type calc struct{}
+
+func (calc) Add(ctx context.Context, x *Add) (Answer, error) {
+	if x.A > 1000 || x.B > 1000 {
+		return &Overflow{}, nil
+	}
+	return &Value{Result: float64(x.A + x.B)}, nil
+}
+
+func (calc) Div(ctx context.Context, x *Div) (Answer, error) {
+	if x.A < 0 {
+		return nil, &service.Error{Status: http.StatusUnprocessableEntity, Message: fmt.Sprintf("negative %d", x.A)}
+	}
+	return &Value{Result: float64(x.A) / float64(x.B)}, nil
+}
@@ -0,0 +1,74 @@
+package testutils
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCalcService(t *testing.T) {
+	server := httptest.NewServer(NewCalcHTTPHandler(calc{}))
+	defer server.Close()
+
+	client := NewCalcHTTPClient(server.URL, server.Client())
+
+	result, err := client.Add(context.Background(), &Add{A: 1, B: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, &Value{Result: 3}, result)
+
+	result, err = client.Add(context.Background(), &Add{A: 1001, B: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, &Overflow{}, result)
+
+	result, err = client.Div(context.Background(), &Div{A: 5, B: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, &Value{Result: 2.5}, result)
+
+	_, err = client.Div(context.Background(), &Div{A: 5, B: 3})
+	var serviceErr *service.Error
+	if assert.True(t, errors.As(err, &serviceErr)) {
+		assert.Equal(t, http.StatusBadRequest, serviceErr.Status)
+		assert.Equal(t, `B: value "3" is not one of ["1" "2" "5" "10"]`, serviceErr.Message)
+	}
+
+	_, err = client.Div(context.Background(), &Div{A: -1, B: 1})
+	if assert.True(t, errors.As(err, &serviceErr)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, serviceErr.Status)
+		assert.Equal(t, "negative -1", serviceErr.Message)
+	}
+}
+
+func TestCalcService_HTTP(t *testing.T) {
+	handler := NewCalcHTTPHandler(CalcServiceFunc(func(ctx context.Context, x Calc) (Answer, error) {
+		return MatchCalcR2(
+			x,
+			func(x *Add) (Answer, error) {
+				return &Value{Result: float64(x.A + x.B)}, nil
+			},
+			func(x *Div) (Answer, error) {
+				return &Overflow{}, nil
+			},
+		)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/Add", strings.NewReader(`{"A": 2, "B": 3}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"$type": "testutils.Value", "testutils.Value": {"Result": 5}}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/Add", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/Mul", strings.NewReader(`{}`))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,16 @@
+package testutils
+
+import (
+	"math/big"
+	"time"
+)
+
+//go:tag serde:"json"
+type Invoice struct {
+	ID      string        `json:"id"`
+	Issued  time.Time     `json:"issued"`
+	Due     *time.Time    `json:"due,omitempty"`
+	Term    time.Duration `json:"term"`
+	Total   big.Int       `json:"total"`
+	Balance *big.Int      `json:"balance"`
+}
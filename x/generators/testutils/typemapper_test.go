@@ -0,0 +1,55 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shared"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestInvoice_TypeMappers(t *testing.T) {
+	due := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	in := Invoice{
+		ID:      "1",
+		Issued:  time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		Due:     &due,
+		Term:    30 * time.Second,
+		Total:   *big.NewInt(0).Exp(big.NewInt(10), big.NewInt(20), nil),
+		Balance: big.NewInt(-42),
+	}
+
+	data, err := shared.JSONMarshal[Invoice](in)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": "1",
+		"issued": "2024-01-02T03:04:05.000000006Z",
+		"due": "2024-02-01T00:00:00Z",
+		"term": 30000000000,
+		"total": "100000000000000000000",
+		"balance": "-42"
+	}`, string(data))
+
+	result, err := shared.JSONUnmarshal[Invoice](data)
+	assert.NoError(t, err)
+	assert.True(t, in.Issued.Equal(result.Issued))
+	assert.True(t, in.Due.Equal(*result.Due))
+	assert.Equal(t, in.Term, result.Term)
+	assert.Equal(t, 0, in.Total.Cmp(&result.Total))
+	assert.Equal(t, 0, in.Balance.Cmp(result.Balance))
+
+	t.Run("null pointers are preserved", func(t *testing.T) {
+		data, err := shared.JSONMarshal[Invoice](Invoice{ID: "2"})
+		assert.NoError(t, err)
+
+		result, err := shared.JSONUnmarshal[Invoice](data)
+		assert.NoError(t, err)
+		assert.Nil(t, result.Due)
+		assert.Nil(t, result.Balance)
+	})
+
+	t.Run("invalid wire value is an error", func(t *testing.T) {
+		_, err := shared.JSONUnmarshal[Invoice]([]byte(`{"id":"3","total":"ten"}`))
+		assert.Error(t, err)
+	})
+}
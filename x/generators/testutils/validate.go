@@ -0,0 +1,30 @@
+package testutils
+
+//go:tag mkunion:"Payment" validate:"true"
+type (
+	Card struct {
+		Holder string `json:"holder" required:"true" minLength:"2"`
+		Brand  string `enum:"visa,mastercard"`
+		CVC    string `regexp:"^[0-9]{3}$"`
+	}
+	Transfer struct {
+		IBAN   string  `required:"true"`
+		Amount float64 `between:"0.01,1000"`
+	}
+	Split struct {
+		Parts []Payment `minItems:"2"`
+	}
+	Voucher Code
+)
+
+//go:tag validate:"true"
+type Code string
+
+//go:tag serde:"json" validate:"true"
+type Order struct {
+	ID      string `required:"true"`
+	Payment Payment
+	Backup  *Card
+	Notes   map[string]Card
+	Lines   [][]Card `maxItems:"3"`
+}
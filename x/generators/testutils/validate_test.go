@@ -0,0 +1,92 @@
+package testutils
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/schema"
+	"github.com/widmogrod/mkunion/x/shape"
+	"github.com/widmogrod/mkunion/x/shared"
+	"testing"
+)
+
+func TestOrder_Validate(t *testing.T) {
+	data := []byte(`{
+  "ID": "",
+  "Payment": {
+    "$type": "testutils.Split",
+    "testutils.Split": {
+      "Parts": [
+        {"$type": "testutils.Card", "testutils.Card": {"holder": "J", "Brand": "amex", "CVC": "12"}},
+        {"$type": "testutils.Transfer", "testutils.Transfer": {"IBAN": "PL61", "Amount": 5000}}
+      ]
+    }
+  },
+  "Backup": {"holder": "", "Brand": "visa", "CVC": "123"},
+  "Notes": {
+    "b": {"holder": "Jane", "Brand": "visa", "CVC": "123"},
+    "a": {"holder": "Jo", "Brand": "diners", "CVC": "123"}
+  },
+  "Lines": [[], [{"holder": "Jane", "Brand": "mastercard", "CVC": "1"}], [], []]
+}`)
+
+	order, err := shared.JSONUnmarshal[Order](data)
+	assert.NoError(t, err)
+
+	err = schema.Validate(order)
+	assert.Error(t, err)
+
+	var errs schema.ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+
+	var result []string
+	for _, e := range errs {
+		result = append(result, e.Error())
+	}
+
+	assert.Equal(t, []string{
+		`ID: value is required`,
+		`Payment["testutils.Split"].Parts[0]["testutils.Card"].Holder: length 1 is less than 2`,
+		`Payment["testutils.Split"].Parts[0]["testutils.Card"].Brand: value "amex" is not one of ["visa" "mastercard"]`,
+		`Payment["testutils.Split"].Parts[0]["testutils.Card"].CVC: value "12" does not match "^[0-9]{3}$"`,
+		`Payment["testutils.Split"].Parts[1]["testutils.Transfer"].Amount: value 5000 is not between 0.01 and 1000`,
+		`Backup.Holder: value is required`,
+		`Notes.a.Brand: value "diners" is not one of ["visa" "mastercard"]`,
+		`Lines: number of items 4 is greater than 3`,
+		`Lines[1][0].CVC: value "1" does not match "^[0-9]{3}$"`,
+	}, result)
+
+	var guardErr *shape.GuardError
+	assert.True(t, errors.As(err, &guardErr))
+	assert.Equal(t, &shape.Required{}, guardErr.Guard)
+}
+
+func TestPayment_Validate(t *testing.T) {
+	useCases := map[string]struct {
+		in  Payment
+		err string
+	}{
+		"nil union is valid": {
+			in: nil,
+		},
+		"valid variant": {
+			in: &Transfer{IBAN: "PL61", Amount: 10},
+		},
+		"variant without guards": {
+			in: shape.Ptr(Voucher("code")),
+		},
+		"union reports variant location": {
+			in:  &Split{Parts: []Payment{&Transfer{IBAN: "PL61", Amount: 1}}},
+			err: `["testutils.Split"].Parts: number of items 1 is less than 2`,
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			err := schema.Validate[Payment](uc.in)
+			if uc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, uc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package testutils
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Article is at version 3:
+//   - in version 2, field Text of both variants was renamed to Body,
+//   - in version 3, variant Final was renamed to Published.
+//
+//go:tag mkunion:"Article,version=3"
+type (
+	Draft struct {
+		Title string
+		Body  string
+	}
+	Published struct {
+		Title string
+		Body  string
+	}
+)
+
+// ArticleUpcastFromV1 renames field Text to Body.
+func ArticleUpcastFromV1(data []byte) ([]byte, error) {
+	var envelope map[string]json.RawMessage
+	err := json.Unmarshal(data, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("testutils.ArticleUpcastFromV1: %w", err)
+	}
+
+	for key, value := range envelope {
+		if key == "$type" || key == "$version" {
+			continue
+		}
+
+		var fields map[string]json.RawMessage
+		err = json.Unmarshal(value, &fields)
+		if err != nil {
+			return nil, fmt.Errorf("testutils.ArticleUpcastFromV1: variant %s; %w", key, err)
+		}
+
+		if text, ok := fields["Text"]; ok {
+			fields["Body"] = text
+			delete(fields, "Text")
+		}
+
+		envelope[key], err = json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("testutils.ArticleUpcastFromV1: variant %s; %w", key, err)
+		}
+	}
+
+	return json.Marshal(envelope)
+}
+
+// ArticleUpcastFromV2 renames variant Final to Published.
+func ArticleUpcastFromV2(data []byte) ([]byte, error) {
+	var envelope map[string]json.RawMessage
+	err := json.Unmarshal(data, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("testutils.ArticleUpcastFromV2: %w", err)
+	}
+
+	if final, ok := envelope["testutils.Final"]; ok {
+		envelope["$type"] = json.RawMessage(`"testutils.Published"`)
+		envelope["testutils.Published"] = final
+		delete(envelope, "testutils.Final")
+	}
+
+	return json.Marshal(envelope)
+}
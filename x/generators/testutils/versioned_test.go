@@ -0,0 +1,49 @@
+package testutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shared"
+	"testing"
+)
+
+func TestArticle_Upcast(t *testing.T) {
+	useCases := map[string]struct {
+		data     string
+		expected Article
+	}{
+		"version 1 without $version": {
+			data:     `{"$type": "testutils.Final", "testutils.Final": {"Title": "a", "Text": "b"}}`,
+			expected: &Published{Title: "a", Body: "b"},
+		},
+		"version 2": {
+			data:     `{"$type": "testutils.Final", "$version": 2, "testutils.Final": {"Title": "a", "Body": "b"}}`,
+			expected: &Published{Title: "a", Body: "b"},
+		},
+		"version 2 of variant that was not renamed": {
+			data:     `{"$type": "testutils.Draft", "$version": 2, "testutils.Draft": {"Title": "a", "Body": "b"}}`,
+			expected: &Draft{Title: "a", Body: "b"},
+		},
+		"current version": {
+			data:     `{"$type": "testutils.Published", "$version": 3, "testutils.Published": {"Title": "a", "Body": "b"}}`,
+			expected: &Published{Title: "a", Body: "b"},
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := shared.JSONUnmarshal[Article]([]byte(uc.data))
+			assert.NoError(t, err)
+			assert.Equal(t, uc.expected, result)
+		})
+	}
+}
+
+func TestArticle_NewerVersion(t *testing.T) {
+	_, err := ArticleFromJSON([]byte(`{"$type": "testutils.Draft", "$version": 4, "testutils.Draft": {}}`))
+	assert.ErrorContains(t, err, "version 4 is newer than supported version 3")
+}
+
+func TestArticle_MarshalWithVersion(t *testing.T) {
+	data, err := shared.JSONMarshal[Article](&Draft{Title: "a", Body: "b"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"$type": "testutils.Draft", "$version": 3, "testutils.Draft": {"Title": "a", "Body": "b"}}`, string(data))
+}
@@ -0,0 +1,352 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"strings"
+)
+
+// NewValidateTagged generates Validate method, that enforces guards declared in field tags,
+// and walks nested fields, so that every invalid field is reported with its schema.Location.
+func NewValidateTagged(x shape.Shape) *ValidateTagged {
+	return &ValidateTagged{
+		shape:                 x,
+		skipImportsAndPackage: false,
+		pkgUsed: PkgMap{
+			"schema": "github.com/widmogrod/mkunion/x/schema",
+		},
+	}
+}
+
+type ValidateTagged struct {
+	shape                 shape.Shape
+	skipImportsAndPackage bool
+	pkgUsed               PkgMap
+}
+
+func (g *ValidateTagged) SkipImportsAndPackage(flag bool) *ValidateTagged {
+	g.skipImportsAndPackage = flag
+	return g
+}
+
+func (g *ValidateTagged) Generate() (string, error) {
+	body := &strings.Builder{}
+
+	if !shape.IsWeekAlias(g.shape) {
+		method, err := g.GenerateValidate(g.shape)
+		if err != nil {
+			return "", fmt.Errorf("generators.ValidateTagged.Generate: %w", err)
+		}
+		body.WriteString(method)
+	}
+
+	head := &strings.Builder{}
+	if !g.skipImportsAndPackage {
+		head.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.shape)))
+		head.WriteString(GenerateImports(g.ExtractImports(g.shape)))
+	}
+
+	if head.Len() > 0 {
+		head.WriteString(body.String())
+		return head.String(), nil
+	} else {
+		return body.String(), nil
+	}
+}
+
+func (g *ValidateTagged) ExtractImports(x shape.Shape) PkgMap {
+	pkgMap := shape.ExtractPkgImportNames(x)
+	if pkgMap == nil {
+		pkgMap = make(map[string]string)
+	}
+
+	// add default and necessary imports
+	pkgMap = MergePkgMaps(pkgMap, g.pkgUsed)
+
+	// remove self from importing
+	delete(pkgMap, shape.ToGoPkgName(x))
+	return pkgMap
+}
+
+func (g *ValidateTagged) rootPkgName() string {
+	return shape.ToGoPkgName(g.shape)
+}
+
+func (g *ValidateTagged) typeName(x shape.Shape) string {
+	return shape.ToGoTypeName(x, shape.WithRootPkgName(g.rootPkgName()))
+}
+
+func (g *ValidateTagged) GenerateValidate(x shape.Shape) (string, error) {
+	body := &strings.Builder{}
+
+	switch y := x.(type) {
+	case *shape.StructLike:
+		for _, field := range y.Fields {
+			location := fmt.Sprintf("&schema.LocationField{Name: %q}", field.Name)
+			value := fmt.Sprintf("r.%s", field.Name)
+
+			if field.Guard != nil {
+				g.pkgUsed["shape"] = "github.com/widmogrod/mkunion/x/shape"
+				body.WriteString(fmt.Sprintf("errs = schema.ValidateField(errs, []schema.Location{%s}, %s, %s)\n",
+					location,
+					GuardToString(field.Guard),
+					value,
+				))
+			}
+
+			body.WriteString(g.nested(field.Type, value, location, 0))
+		}
+
+	case *shape.AliasLike:
+		value := fmt.Sprintf("%s(*r)", g.typeName(y.Type))
+		body.WriteString(g.nested(y.Type, value, "", 0))
+
+	default:
+		return "", fmt.Errorf("generators.ValidateTagged.GenerateValidate: expects struct or alias, given %T", x)
+	}
+
+	result := &strings.Builder{}
+	result.WriteString(fmt.Sprintf("var _ schema.Validator = (*%s)(nil)\n\n", shape.ToGoTypeName(x,
+		shape.WithInstantiation(),
+		shape.WithRootPkgName(g.rootPkgName()),
+	)))
+	result.WriteString(fmt.Sprintf("func (r *%s) Validate() error {\n", g.typeName(x)))
+	result.WriteString(fmt.Sprintf("\tif r == nil {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn nil\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	if body.Len() == 0 {
+		result.WriteString(fmt.Sprintf("\treturn nil\n"))
+		result.WriteString(fmt.Sprintf("}\n\n"))
+		return result.String(), nil
+	}
+
+	result.WriteString(fmt.Sprintf("\tvar errs schema.ValidationErrors\n"))
+	result.WriteString(padLeftTabs(1, body.String()))
+	result.WriteString(fmt.Sprintf("\tif len(errs) > 0 {\n"))
+	result.WriteString(fmt.Sprintf("\t\treturn errs\n"))
+	result.WriteString(fmt.Sprintf("\t}\n"))
+	result.WriteString(fmt.Sprintf("\treturn nil\n"))
+	result.WriteString(fmt.Sprintf("}\n\n"))
+
+	return result.String(), nil
+}
+
+// nested walks collections until it finds named types, that may have own Validate method.
+// Primitives don't have nested guards, and are skipped.
+func (g *ValidateTagged) nested(x shape.Shape, value, location string, depth int) string {
+	locations := func(last string) string {
+		return strings.Trim(location+", "+last, ", ")
+	}
+
+	return shape.MatchShapeR1(
+		x,
+		func(y *shape.Any) string {
+			return ""
+		},
+		func(y *shape.RefName) string {
+			return fmt.Sprintf("errs = schema.ValidateNested(errs, []schema.Location{%s}, %s)\n", location, value)
+		},
+		func(y *shape.PointerLike) string {
+			return g.nested(y.Type, value, location, depth)
+		},
+		func(y *shape.AliasLike) string {
+			return fmt.Sprintf("errs = schema.ValidateNested(errs, []schema.Location{%s}, %s)\n", location, value)
+		},
+		func(y *shape.PrimitiveLike) string {
+			return ""
+		},
+		func(y *shape.ListLike) string {
+			index := fmt.Sprintf("i%d", depth)
+			item := fmt.Sprintf("v%d", depth)
+			inner := g.nested(y.Element, item, locations(fmt.Sprintf("&schema.LocationIndex{Index: %s}", index)), depth+1)
+			if inner == "" {
+				return ""
+			}
+
+			result := &strings.Builder{}
+			result.WriteString(fmt.Sprintf("for %s, %s := range %s {\n", index, item, value))
+			result.WriteString(padLeftTabs(1, inner))
+			result.WriteString(fmt.Sprintf("}\n"))
+			return result.String()
+		},
+		func(y *shape.MapLike) string {
+			key := fmt.Sprintf("k%d", depth)
+			item := fmt.Sprintf("v%d", depth)
+
+			name := key
+			if !shape.IsString(y.Key) {
+				g.pkgUsed["fmt"] = "fmt"
+				name = fmt.Sprintf("fmt.Sprint(%s)", key)
+			}
+
+			inner := g.nested(y.Val, item, locations(fmt.Sprintf("&schema.LocationField{Name: %s}", name)), depth+1)
+			if inner == "" {
+				return ""
+			}
+
+			result := &strings.Builder{}
+			if protoIsOrderedKey(y.Key) {
+				// report errors in the same order, no matter how map is iterated
+				g.pkgUsed["maps"] = "maps"
+				g.pkgUsed["slices"] = "slices"
+				result.WriteString(fmt.Sprintf("for _, %s := range slices.Sorted(maps.Keys(%s)) {\n", key, value))
+				result.WriteString(fmt.Sprintf("\t%s := %s[%s]\n", item, value, key))
+			} else {
+				result.WriteString(fmt.Sprintf("for %s, %s := range %s {\n", key, item, value))
+			}
+			result.WriteString(padLeftTabs(1, inner))
+			result.WriteString(fmt.Sprintf("}\n"))
+			return result.String()
+		},
+		func(y *shape.StructLike) string {
+			return ""
+		},
+		func(y *shape.UnionLike) string {
+			return fmt.Sprintf("errs = schema.ValidateNested(errs, []schema.Location{%s}, %s)\n", location, value)
+		},
+	)
+}
+
+// NewValidateUnion generates validation function for union,
+// that reports errors of variant under location named like JSON discriminator, for example "pkg.Variant".
+func NewValidateUnion(union *shape.UnionLike) *ValidateUnion {
+	return &ValidateUnion{
+		union:                 union,
+		skipImportsAndPackage: false,
+		skipInitFunc:          false,
+		pkgUsed: PkgMap{
+			"schema": "github.com/widmogrod/mkunion/x/schema",
+		},
+	}
+}
+
+type ValidateUnion struct {
+	union                 *shape.UnionLike
+	skipImportsAndPackage bool
+	skipInitFunc          bool
+	pkgUsed               PkgMap
+}
+
+func (g *ValidateUnion) SkipImportsAndPackage(x bool) {
+	g.skipImportsAndPackage = x
+}
+
+func (g *ValidateUnion) SkipInitFunc(flag bool) *ValidateUnion {
+	g.skipInitFunc = flag
+	return g
+}
+
+func (g *ValidateUnion) ExtractImports(x shape.Shape) PkgMap {
+	pkgMap := shape.ExtractPkgImportNames(x)
+	if pkgMap == nil {
+		pkgMap = make(map[string]string)
+	}
+
+	// add default and necessary imports
+	pkgMap = MergePkgMaps(pkgMap, g.pkgUsed)
+
+	// remove self from importing
+	delete(pkgMap, shape.ToGoPkgName(x))
+	return pkgMap
+}
+
+func (g *ValidateUnion) ExtractImportFuncs(s shape.Shape) []string {
+	return []string{
+		fmt.Sprintf("schema.ValidatorRegister(%q, %s)",
+			shape.ToGoTypeName(s,
+				shape.WithPkgImportName(),
+				shape.WithInstantiation(),
+			),
+			StrInstantiatef(g.union.PkgName, s, "%sValidate"),
+		),
+	}
+}
+
+func (g *ValidateUnion) Generate() ([]byte, error) {
+	body := &bytes.Buffer{}
+
+	body.Write(g.GenerateUnionFunc())
+
+	variants, err := g.GenerateVariants()
+	if err != nil {
+		return nil, fmt.Errorf("generators.ValidateUnion.Generate: when generating variants; %w", err)
+	}
+	body.Write(variants)
+
+	head := &bytes.Buffer{}
+	if !g.skipImportsAndPackage {
+		head.WriteString(fmt.Sprintf("package %s\n\n", shape.ToGoPkgName(g.union)))
+		head.WriteString(GenerateImports(g.ExtractImports(g.union)))
+	}
+
+	if !g.skipInitFunc {
+		head.WriteString(GenerateInitFunc(g.ExtractImportFuncs(g.union)))
+	}
+
+	if head.Len() > 0 {
+		head.Write(body.Bytes())
+		return head.Bytes(), nil
+	} else {
+		return body.Bytes(), nil
+	}
+}
+
+func (g *ValidateUnion) parametrisedf(x shape.Shape, template string) string {
+	return (&SerdeJSONUnion{union: g.union}).parametrisedf(x, template)
+}
+
+func (g *ValidateUnion) constructionf(x shape.Shape, template string) string {
+	return (&SerdeJSONUnion{union: g.union}).constructionf(x, template)
+}
+
+// variantName is the same as in JSON serde, so that location points to the same place as in JSON document
+func (g *ValidateUnion) variantName(x shape.Shape) string {
+	return (&SerdeJSONUnion{union: g.union}).JSONVariantName(x)
+}
+
+func (g *ValidateUnion) GenerateUnionFunc() []byte {
+	body := &bytes.Buffer{}
+
+	body.WriteString(fmt.Sprintf("func %s(x %s) error {\n",
+		g.constructionf(g.union, "%sValidate"),
+		g.parametrisedf(g.union, "%s"),
+	))
+	body.WriteString(fmt.Sprintf("\tif x == nil {\n"))
+	body.WriteString(fmt.Sprintf("\t\treturn nil\n"))
+	body.WriteString(fmt.Sprintf("\t}\n"))
+	body.WriteString(fmt.Sprintf("\tvar errs schema.ValidationErrors\n"))
+	body.WriteString(fmt.Sprintf("\t%s(\n", MatchUnionFuncName(g.union, 0)))
+	body.WriteString(fmt.Sprintf("\t\tx,\n"))
+	for _, variant := range g.union.Variant {
+		body.WriteString(fmt.Sprintf("\t\tfunc(y *%s) {\n", g.parametrisedf(variant, "%s")))
+		body.WriteString(fmt.Sprintf("\t\t\terrs = schema.ValidateNested(errs, []schema.Location{&schema.LocationField{Name: %q}}, y)\n", g.variantName(variant)))
+		body.WriteString(fmt.Sprintf("\t\t},\n"))
+	}
+	body.WriteString(fmt.Sprintf("\t)\n"))
+	body.WriteString(fmt.Sprintf("\tif len(errs) > 0 {\n"))
+	body.WriteString(fmt.Sprintf("\t\treturn errs\n"))
+	body.WriteString(fmt.Sprintf("\t}\n"))
+	body.WriteString(fmt.Sprintf("\treturn nil\n"))
+	body.WriteString(fmt.Sprintf("}\n\n"))
+
+	return body.Bytes()
+}
+
+func (g *ValidateUnion) GenerateVariants() ([]byte, error) {
+	body := &bytes.Buffer{}
+
+	for _, variant := range g.union.Variant {
+		validate := NewValidateTagged(variant)
+		validate.SkipImportsAndPackage(true)
+		result, err := validate.Generate()
+		if err != nil {
+			return nil, fmt.Errorf("generators.ValidateUnion.GenerateVariants: %s; %w", shape.Name(variant), err)
+		}
+		g.pkgUsed = MergePkgMaps(g.pkgUsed, validate.ExtractImports(variant))
+
+		body.WriteString(result)
+	}
+
+	return body.Bytes(), nil
+}
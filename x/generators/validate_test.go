@@ -0,0 +1,179 @@
+package generators
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+)
+
+func TestNewValidateTagged_Struct(t *testing.T) {
+	generator := NewValidateTagged(&shape.StructLike{
+		Name:          "Order",
+		PkgName:       "pkg",
+		PkgImportName: "example.com/pkg",
+		Fields: []*shape.FieldLike{
+			{
+				Name:  "ID",
+				Type:  &shape.PrimitiveLike{Kind: &shape.StringLike{}},
+				Guard: &shape.Required{},
+			},
+			{
+				Name: "Lines",
+				Type: &shape.ListLike{Element: &shape.RefName{Name: "Line", PkgName: "pkg", PkgImportName: "example.com/pkg"}},
+				Guard: &shape.MinItems{
+					Len: 1,
+				},
+			},
+			{
+				Name: "ByID",
+				Type: &shape.MapLike{
+					Key: &shape.PrimitiveLike{Kind: &shape.NumberLike{Kind: &shape.Int{}}},
+					Val: &shape.PointerLike{Type: &shape.RefName{Name: "Line", PkgName: "pkg", PkgImportName: "example.com/pkg"}},
+				},
+			},
+			{
+				Name: "Tags",
+				Type: &shape.ListLike{Element: &shape.PrimitiveLike{Kind: &shape.StringLike{}}},
+			},
+		},
+	})
+
+	result, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, `package pkg
+
+import (
+	"fmt"
+	"github.com/widmogrod/mkunion/x/schema"
+	"github.com/widmogrod/mkunion/x/shape"
+	"maps"
+	"slices"
+)
+
+var _ schema.Validator = (*Order)(nil)
+
+func (r *Order) Validate() error {
+	if r == nil {
+		return nil
+	}
+	var errs schema.ValidationErrors
+	errs = schema.ValidateField(errs, []schema.Location{&schema.LocationField{Name: "ID"}}, &shape.Required{}, r.ID)
+	errs = schema.ValidateField(errs, []schema.Location{&schema.LocationField{Name: "Lines"}}, &shape.MinItems{
+		Len: 1,
+	}, r.Lines)
+	for i0, v0 := range r.Lines {
+		errs = schema.ValidateNested(errs, []schema.Location{&schema.LocationField{Name: "Lines"}, &schema.LocationIndex{Index: i0}}, v0)
+	}
+	for _, k0 := range slices.Sorted(maps.Keys(r.ByID)) {
+		v0 := r.ByID[k0]
+		errs = schema.ValidateNested(errs, []schema.Location{&schema.LocationField{Name: "ByID"}, &schema.LocationField{Name: fmt.Sprint(k0)}}, v0)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+`, result)
+}
+
+func TestNewValidateUnion_Generic(t *testing.T) {
+	generator := NewValidateUnion(&shape.UnionLike{
+		Name:          "Result",
+		PkgName:       "pkg",
+		PkgImportName: "example.com/pkg",
+		TypeParams:    []shape.TypeParam{{Name: "T", Type: &shape.Any{}}},
+		Variant: []shape.Shape{
+			&shape.StructLike{
+				Name:          "Ok",
+				PkgName:       "pkg",
+				PkgImportName: "example.com/pkg",
+				TypeParams:    []shape.TypeParam{{Name: "T", Type: &shape.Any{}}},
+				Fields: []*shape.FieldLike{
+					{Name: "Value", Type: &shape.RefName{Name: "T"}},
+				},
+			},
+			&shape.StructLike{
+				Name:          "Err",
+				PkgName:       "pkg",
+				PkgImportName: "example.com/pkg",
+				TypeParams:    []shape.TypeParam{{Name: "T", Type: &shape.Any{}}},
+				Fields: []*shape.FieldLike{
+					{
+						Name:  "Reason",
+						Type:  &shape.PrimitiveLike{Kind: &shape.StringLike{}},
+						Guard: &shape.Enum{Val: []string{"timeout", "invalid"}},
+					},
+				},
+			},
+		},
+	})
+
+	result, err := generator.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, `package pkg
+
+import (
+	"github.com/widmogrod/mkunion/x/schema"
+	"github.com/widmogrod/mkunion/x/shape"
+)
+
+func init() {
+	schema.ValidatorRegister("example.com/pkg.Result[any]", ResultValidate[any])
+}
+
+func ResultValidate[T any](x Result[T]) error {
+	if x == nil {
+		return nil
+	}
+	var errs schema.ValidationErrors
+	MatchResultR0(
+		x,
+		func(y *Ok[T]) {
+			errs = schema.ValidateNested(errs, []schema.Location{&schema.LocationField{Name: "pkg.Ok"}}, y)
+		},
+		func(y *Err[T]) {
+			errs = schema.ValidateNested(errs, []schema.Location{&schema.LocationField{Name: "pkg.Err"}}, y)
+		},
+	)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+var _ schema.Validator = (*Ok[any])(nil)
+
+func (r *Ok[T]) Validate() error {
+	if r == nil {
+		return nil
+	}
+	var errs schema.ValidationErrors
+	errs = schema.ValidateNested(errs, []schema.Location{&schema.LocationField{Name: "Value"}}, r.Value)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+var _ schema.Validator = (*Err[any])(nil)
+
+func (r *Err[T]) Validate() error {
+	if r == nil {
+		return nil
+	}
+	var errs schema.ValidationErrors
+	errs = schema.ValidateField(errs, []schema.Location{&schema.LocationField{Name: "Reason"}}, &shape.Enum{
+		Val: []string{
+			"timeout",
+			"invalid",
+		},
+	}, r.Reason)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+`, string(result))
+}
@@ -487,6 +487,11 @@ func (suitcase *Case[D, C, S]) run(t *testing.T) {
 }
 
 func (suitcase *Case[D, C, S]) deepCopy(state S) S {
+	// states with generated DeepCopy are copied without JSON round trip
+	if shared.CanDeepCopy(state) {
+		return shared.DeepCopy(state)
+	}
+
 	data, err := shared.JSONMarshal[S](state)
 	if err != nil {
 		panic(fmt.Errorf("failed deep copying state %T, reason: %w", state, err))
@@ -84,6 +84,108 @@ func MatchDataR0[A any](
 		f2(v)
 	}
 }
+
+// DataEqual returns true, when a and b are the same variant of Data with equal fields.
+func DataEqual[A any](a, b Data[A]) bool {
+	switch x := a.(type) {
+	case *Record[A]:
+		y, ok := b.(*Record[A])
+		return ok && x.Equal(y)
+	case *Watermark[A]:
+		y, ok := b.(*Watermark[A])
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// DataHash returns hash of x, that is the same for values equal according to DataEqual.
+func DataHash[A any](x Data[A]) uint64 {
+	switch y := x.(type) {
+	case *Record[A]:
+		return y.Hash()
+	case *Watermark[A]:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// DataDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func DataDeepCopy[A any](x Data[A]) Data[A] {
+	switch y := x.(type) {
+	case *Record[A]:
+		return y.DeepCopy()
+	case *Watermark[A]:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Record[A]) Equal(other *Record[A]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Key != other.Key {
+		return false
+	}
+	if !shared.Equal(r.Data, other.Data) {
+		return false
+	}
+	if r.EventTime != other.EventTime {
+		return false
+	}
+	return true
+}
+
+func (r *Record[A]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.Record")
+	h = shared.HashString(h, r.Key)
+	h = shared.Hash(h, r.Data)
+	h = shared.HashUint64(h, uint64(r.EventTime))
+	return h
+}
+
+func (r *Record[A]) DeepCopy() *Record[A] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Data = shared.DeepCopy(r.Data)
+	return &result
+}
+
+func (r *Watermark[A]) Equal(other *Watermark[A]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.EventTime != other.EventTime {
+		return false
+	}
+	return true
+}
+
+func (r *Watermark[A]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.Watermark")
+	h = shared.HashUint64(h, uint64(r.EventTime))
+	return h
+}
+
+func (r *Watermark[A]) DeepCopy() *Watermark[A] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.Data[any]", DataFromJSON[any], DataToJSON[any])
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.Record[any]", RecordFromJSON[any], RecordToJSON[any])
@@ -100,7 +202,7 @@ func DataFromJSON[A any](x []byte) (Data[A], error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data DataUnionJSON[A]
@@ -437,6 +539,101 @@ func MatchEitherR0[A any, B any](
 		f2(v)
 	}
 }
+
+// EitherEqual returns true, when a and b are the same variant of Either with equal fields.
+func EitherEqual[A any, B any](a, b Either[A, B]) bool {
+	switch x := a.(type) {
+	case *Left[A, B]:
+		y, ok := b.(*Left[A, B])
+		return ok && x.Equal(y)
+	case *Right[A, B]:
+		y, ok := b.(*Right[A, B])
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// EitherHash returns hash of x, that is the same for values equal according to EitherEqual.
+func EitherHash[A any, B any](x Either[A, B]) uint64 {
+	switch y := x.(type) {
+	case *Left[A, B]:
+		return y.Hash()
+	case *Right[A, B]:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// EitherDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func EitherDeepCopy[A any, B any](x Either[A, B]) Either[A, B] {
+	switch y := x.(type) {
+	case *Left[A, B]:
+		return y.DeepCopy()
+	case *Right[A, B]:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Left[A, B]) Equal(other *Left[A, B]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Left, other.Left) {
+		return false
+	}
+	return true
+}
+
+func (r *Left[A, B]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.Left")
+	h = shared.Hash(h, r.Left)
+	return h
+}
+
+func (r *Left[A, B]) DeepCopy() *Left[A, B] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Left = shared.DeepCopy(r.Left)
+	return &result
+}
+
+func (r *Right[A, B]) Equal(other *Right[A, B]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Right, other.Right) {
+		return false
+	}
+	return true
+}
+
+func (r *Right[A, B]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.Right")
+	h = shared.Hash(h, r.Right)
+	return h
+}
+
+func (r *Right[A, B]) DeepCopy() *Right[A, B] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Right = shared.DeepCopy(r.Right)
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.Either[any,any]", EitherFromJSON[any, any], EitherToJSON[any, any])
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.Left[any,any]", LeftFromJSON[any, any], LeftToJSON[any, any])
@@ -453,7 +650,7 @@ func EitherFromJSON[A any, B any](x []byte) (Either[A, B], error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data EitherUnionJSON[A, B]
@@ -89,6 +89,150 @@ func MatchSnapshotStateR0(
 		f2(v)
 	}
 }
+
+// SnapshotStateEqual returns true, when a and b are the same variant of SnapshotState with equal fields.
+func SnapshotStateEqual(a, b SnapshotState) bool {
+	switch x := a.(type) {
+	case *PullPushContextState:
+		y, ok := b.(*PullPushContextState)
+		return ok && x.Equal(y)
+	case *JoinContextState:
+		y, ok := b.(*JoinContextState)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// SnapshotStateHash returns hash of x, that is the same for values equal according to SnapshotStateEqual.
+func SnapshotStateHash(x SnapshotState) uint64 {
+	switch y := x.(type) {
+	case *PullPushContextState:
+		return y.Hash()
+	case *JoinContextState:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// SnapshotStateDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func SnapshotStateDeepCopy(x SnapshotState) SnapshotState {
+	switch y := x.(type) {
+	case *PullPushContextState:
+		return y.DeepCopy()
+	case *JoinContextState:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *PullPushContextState) Equal(other *PullPushContextState) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !r.Offset.Equal(other.Offset) {
+		return false
+	}
+	if (r.Watermark == nil) != (other.Watermark == nil) {
+		return false
+	}
+	if r.Watermark != nil {
+		if *r.Watermark != *other.Watermark {
+			return false
+		}
+	}
+	if r.PullTopic != other.PullTopic {
+		return false
+	}
+	if r.PushTopic != other.PushTopic {
+		return false
+	}
+	return true
+}
+
+func (r *PullPushContextState) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.PullPushContextState")
+	h = shared.HashUint64(h, r.Offset.Hash())
+	h = shared.HashBool(h, r.Watermark != nil)
+	if r.Watermark != nil {
+		h = shared.HashUint64(h, uint64(*r.Watermark))
+	}
+	h = shared.HashString(h, string(r.PullTopic))
+	h = shared.HashString(h, string(r.PushTopic))
+	return h
+}
+
+func (r *PullPushContextState) DeepCopy() *PullPushContextState {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Offset = r.Offset.DeepCopy()
+	if r.Watermark != nil {
+		r1 := *r.Watermark
+		result.Watermark = &r1
+	}
+	return &result
+}
+
+func (r *JoinContextState) Equal(other *JoinContextState) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !r.Offset1.Equal(other.Offset1) {
+		return false
+	}
+	if r.PullTopic1 != other.PullTopic1 {
+		return false
+	}
+	if !r.Offset2.Equal(other.Offset2) {
+		return false
+	}
+	if r.PullTopic2 != other.PullTopic2 {
+		return false
+	}
+	if r.LeftOrRight != other.LeftOrRight {
+		return false
+	}
+	if r.PushTopic != other.PushTopic {
+		return false
+	}
+	if r.Watermark != other.Watermark {
+		return false
+	}
+	return true
+}
+
+func (r *JoinContextState) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.JoinContextState")
+	h = shared.HashUint64(h, r.Offset1.Hash())
+	h = shared.HashString(h, string(r.PullTopic1))
+	h = shared.HashUint64(h, r.Offset2.Hash())
+	h = shared.HashString(h, string(r.PullTopic2))
+	h = shared.HashBool(h, r.LeftOrRight)
+	h = shared.HashString(h, string(r.PushTopic))
+	h = shared.HashUint64(h, uint64(r.Watermark))
+	return h
+}
+
+func (r *JoinContextState) DeepCopy() *JoinContextState {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Offset1 = r.Offset1.DeepCopy()
+	result.Offset2 = r.Offset2.DeepCopy()
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.JoinContextState", JoinContextStateFromJSON, JoinContextStateToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.PullPushContextState", PullPushContextStateFromJSON, PullPushContextStateToJSON)
@@ -105,7 +249,7 @@ func SnapshotStateFromJSON(x []byte) (SnapshotState, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data SnapshotStateUnionJSON
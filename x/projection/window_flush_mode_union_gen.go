@@ -69,6 +69,61 @@ func MatchWindowFlushModeR0(
 		f1(v)
 	}
 }
+
+// WindowFlushModeEqual returns true, when a and b are the same variant of WindowFlushMode with equal fields.
+func WindowFlushModeEqual(a, b WindowFlushMode) bool {
+	switch x := a.(type) {
+	case *Discard:
+		y, ok := b.(*Discard)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// WindowFlushModeHash returns hash of x, that is the same for values equal according to WindowFlushModeEqual.
+func WindowFlushModeHash(x WindowFlushMode) uint64 {
+	switch y := x.(type) {
+	case *Discard:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// WindowFlushModeDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func WindowFlushModeDeepCopy(x WindowFlushMode) WindowFlushMode {
+	switch y := x.(type) {
+	case *Discard:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Discard) Equal(other *Discard) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Discard) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.Discard")
+	return h
+}
+
+func (r *Discard) DeepCopy() *Discard {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.Discard", DiscardFromJSON, DiscardToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.WindowFlushMode", WindowFlushModeFromJSON, WindowFlushModeToJSON)
@@ -83,7 +138,7 @@ func WindowFlushModeFromJSON(x []byte) (WindowFlushMode, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data WindowFlushModeUnionJSON
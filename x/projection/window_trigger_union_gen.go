@@ -71,6 +71,61 @@ func MatchTriggerDescriptionR0(
 		f1(v)
 	}
 }
+
+// TriggerDescriptionEqual returns true, when a and b are the same variant of TriggerDescription with equal fields.
+func TriggerDescriptionEqual(a, b TriggerDescription) bool {
+	switch x := a.(type) {
+	case *AtWatermark:
+		y, ok := b.(*AtWatermark)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// TriggerDescriptionHash returns hash of x, that is the same for values equal according to TriggerDescriptionEqual.
+func TriggerDescriptionHash(x TriggerDescription) uint64 {
+	switch y := x.(type) {
+	case *AtWatermark:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// TriggerDescriptionDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func TriggerDescriptionDeepCopy(x TriggerDescription) TriggerDescription {
+	switch y := x.(type) {
+	case *AtWatermark:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *AtWatermark) Equal(other *AtWatermark) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *AtWatermark) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.AtWatermark")
+	return h
+}
+
+func (r *AtWatermark) DeepCopy() *AtWatermark {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.AtWatermark", AtWatermarkFromJSON, AtWatermarkToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.TriggerDescription", TriggerDescriptionFromJSON, TriggerDescriptionToJSON)
@@ -85,7 +140,7 @@ func TriggerDescriptionFromJSON(x []byte) (TriggerDescription, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data TriggerDescriptionUnionJSON
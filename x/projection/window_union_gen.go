@@ -106,6 +106,141 @@ func MatchWindowDescriptionR0(
 		f3(v)
 	}
 }
+
+// WindowDescriptionEqual returns true, when a and b are the same variant of WindowDescription with equal fields.
+func WindowDescriptionEqual(a, b WindowDescription) bool {
+	switch x := a.(type) {
+	case *SessionWindow:
+		y, ok := b.(*SessionWindow)
+		return ok && x.Equal(y)
+	case *SlidingWindow:
+		y, ok := b.(*SlidingWindow)
+		return ok && x.Equal(y)
+	case *FixedWindow:
+		y, ok := b.(*FixedWindow)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// WindowDescriptionHash returns hash of x, that is the same for values equal according to WindowDescriptionEqual.
+func WindowDescriptionHash(x WindowDescription) uint64 {
+	switch y := x.(type) {
+	case *SessionWindow:
+		return y.Hash()
+	case *SlidingWindow:
+		return y.Hash()
+	case *FixedWindow:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// WindowDescriptionDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func WindowDescriptionDeepCopy(x WindowDescription) WindowDescription {
+	switch y := x.(type) {
+	case *SessionWindow:
+		return y.DeepCopy()
+	case *SlidingWindow:
+		return y.DeepCopy()
+	case *FixedWindow:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *SessionWindow) Equal(other *SessionWindow) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.GapDuration, other.GapDuration) {
+		return false
+	}
+	return true
+}
+
+func (r *SessionWindow) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.SessionWindow")
+	h = shared.Hash(h, r.GapDuration)
+	return h
+}
+
+func (r *SessionWindow) DeepCopy() *SessionWindow {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.GapDuration = shared.DeepCopy(r.GapDuration)
+	return &result
+}
+
+func (r *SlidingWindow) Equal(other *SlidingWindow) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Width, other.Width) {
+		return false
+	}
+	if !shared.Equal(r.Period, other.Period) {
+		return false
+	}
+	return true
+}
+
+func (r *SlidingWindow) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.SlidingWindow")
+	h = shared.Hash(h, r.Width)
+	h = shared.Hash(h, r.Period)
+	return h
+}
+
+func (r *SlidingWindow) DeepCopy() *SlidingWindow {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Width = shared.DeepCopy(r.Width)
+	result.Period = shared.DeepCopy(r.Period)
+	return &result
+}
+
+func (r *FixedWindow) Equal(other *FixedWindow) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Width, other.Width) {
+		return false
+	}
+	return true
+}
+
+func (r *FixedWindow) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.FixedWindow")
+	h = shared.Hash(h, r.Width)
+	return h
+}
+
+func (r *FixedWindow) DeepCopy() *FixedWindow {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Width = shared.DeepCopy(r.Width)
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.FixedWindow", FixedWindowFromJSON, FixedWindowToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/projection.SessionWindow", SessionWindowFromJSON, SessionWindowToJSON)
@@ -124,7 +259,7 @@ func WindowDescriptionFromJSON(x []byte) (WindowDescription, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data WindowDescriptionUnionJSON
@@ -231,7 +366,11 @@ func (r *SessionWindow) _marshalJSONSessionWindow(x SessionWindow) ([]byte, erro
 	return result, nil
 }
 func (r *SessionWindow) _marshalJSONtime_Duration(x time.Duration) ([]byte, error) {
-	result, err := shared.JSONMarshal[time.Duration](x)
+	wire, err := shared.DurationToWire(x)
+	if err != nil {
+		return nil, fmt.Errorf("projection: SessionWindow._marshalJSONtime_Duration: to wire; %w", err)
+	}
+	result, err := json.Marshal(wire)
 	if err != nil {
 		return nil, fmt.Errorf("projection: SessionWindow._marshalJSONtime_Duration:; %w", err)
 	}
@@ -261,9 +400,18 @@ func (r *SessionWindow) _unmarshalJSONSessionWindow(data []byte) (SessionWindow,
 	return result, nil
 }
 func (r *SessionWindow) _unmarshalJSONtime_Duration(data []byte) (time.Duration, error) {
-	result, err := shared.JSONUnmarshal[time.Duration](data)
+	var result time.Duration
+	if string(data) == "null" {
+		return result, nil
+	}
+	var wire int64
+	err := json.Unmarshal(data, &wire)
+	if err != nil {
+		return result, fmt.Errorf("projection: SessionWindow._unmarshalJSONtime_Duration: wire unwrap; %w", err)
+	}
+	result, err = shared.DurationFromWire(wire)
 	if err != nil {
-		return result, fmt.Errorf("projection: SessionWindow._unmarshalJSONtime_Duration: native ref unwrap; %w", err)
+		return result, fmt.Errorf("projection: SessionWindow._unmarshalJSONtime_Duration: from wire; %w", err)
 	}
 	return result, nil
 }
@@ -314,7 +462,11 @@ func (r *SlidingWindow) _marshalJSONSlidingWindow(x SlidingWindow) ([]byte, erro
 	return result, nil
 }
 func (r *SlidingWindow) _marshalJSONtime_Duration(x time.Duration) ([]byte, error) {
-	result, err := shared.JSONMarshal[time.Duration](x)
+	wire, err := shared.DurationToWire(x)
+	if err != nil {
+		return nil, fmt.Errorf("projection: SlidingWindow._marshalJSONtime_Duration: to wire; %w", err)
+	}
+	result, err := json.Marshal(wire)
 	if err != nil {
 		return nil, fmt.Errorf("projection: SlidingWindow._marshalJSONtime_Duration:; %w", err)
 	}
@@ -350,9 +502,18 @@ func (r *SlidingWindow) _unmarshalJSONSlidingWindow(data []byte) (SlidingWindow,
 	return result, nil
 }
 func (r *SlidingWindow) _unmarshalJSONtime_Duration(data []byte) (time.Duration, error) {
-	result, err := shared.JSONUnmarshal[time.Duration](data)
+	var result time.Duration
+	if string(data) == "null" {
+		return result, nil
+	}
+	var wire int64
+	err := json.Unmarshal(data, &wire)
 	if err != nil {
-		return result, fmt.Errorf("projection: SlidingWindow._unmarshalJSONtime_Duration: native ref unwrap; %w", err)
+		return result, fmt.Errorf("projection: SlidingWindow._unmarshalJSONtime_Duration: wire unwrap; %w", err)
+	}
+	result, err = shared.DurationFromWire(wire)
+	if err != nil {
+		return result, fmt.Errorf("projection: SlidingWindow._unmarshalJSONtime_Duration: from wire; %w", err)
 	}
 	return result, nil
 }
@@ -397,7 +558,11 @@ func (r *FixedWindow) _marshalJSONFixedWindow(x FixedWindow) ([]byte, error) {
 	return result, nil
 }
 func (r *FixedWindow) _marshalJSONtime_Duration(x time.Duration) ([]byte, error) {
-	result, err := shared.JSONMarshal[time.Duration](x)
+	wire, err := shared.DurationToWire(x)
+	if err != nil {
+		return nil, fmt.Errorf("projection: FixedWindow._marshalJSONtime_Duration: to wire; %w", err)
+	}
+	result, err := json.Marshal(wire)
 	if err != nil {
 		return nil, fmt.Errorf("projection: FixedWindow._marshalJSONtime_Duration:; %w", err)
 	}
@@ -427,9 +592,18 @@ func (r *FixedWindow) _unmarshalJSONFixedWindow(data []byte) (FixedWindow, error
 	return result, nil
 }
 func (r *FixedWindow) _unmarshalJSONtime_Duration(data []byte) (time.Duration, error) {
-	result, err := shared.JSONUnmarshal[time.Duration](data)
+	var result time.Duration
+	if string(data) == "null" {
+		return result, nil
+	}
+	var wire int64
+	err := json.Unmarshal(data, &wire)
+	if err != nil {
+		return result, fmt.Errorf("projection: FixedWindow._unmarshalJSONtime_Duration: wire unwrap; %w", err)
+	}
+	result, err = shared.DurationFromWire(wire)
 	if err != nil {
-		return result, fmt.Errorf("projection: FixedWindow._unmarshalJSONtime_Duration: native ref unwrap; %w", err)
+		return result, fmt.Errorf("projection: FixedWindow._unmarshalJSONtime_Duration: from wire; %w", err)
 	}
 	return result, nil
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/widmogrod/mkunion/x/shape"
 	"github.com/widmogrod/mkunion/x/shared"
 	"reflect"
+	"strings"
 )
 
 func IsPrimitive(x any) bool {
@@ -468,9 +469,9 @@ func FromGoReflect(xschema shape.Shape, yreflect reflect.Value) Schema {
 			}
 
 			result := Map{}
-			for _, field := range x.Fields {
-				fieldReflect := yreflect.FieldByName(field.Name)
-				if !fieldReflect.IsValid() {
+			for _, field := range shape.StructFields(x, reflectLookup(yreflect.Type())) {
+				fieldReflect, ok := structFieldByPath(yreflect, field.Path(), false)
+				if !ok {
 					continue
 				}
 
@@ -719,23 +720,23 @@ func ToGoReflect(xshape shape.Shape, ydata Schema, zreflect reflect.Type) (refle
 			}
 
 			result := reflect.New(zreflect).Elem()
-			for _, field := range x.Fields {
+			for _, field := range shape.StructFields(x, reflectLookup(zreflect)) {
 				value, ok := (*data)[field.Name]
 				if !ok {
 					continue
 				}
 
-				fieldValue, ok := zreflect.FieldByName(field.Name)
+				fieldValue, ok := structFieldByPath(result, field.Path(), true)
 				if !ok {
-					return reflect.Value{}, fmt.Errorf("schema.ToGoReflect: field %s not found", field.Name)
+					return reflect.Value{}, fmt.Errorf("schema.ToGoReflect: field %s not found", strings.Join(field.Path(), "."))
 				}
 
-				dest, err := ToGoReflect(field.Type, value, fieldValue.Type)
+				dest, err := ToGoReflect(field.Type, value, fieldValue.Type())
 				if err != nil {
 					return reflect.Value{}, fmt.Errorf("schema.ToGoReflect: field %s; %w", field.Name, err)
 				}
 
-				result.FieldByName(field.Name).Set(dest)
+				fieldValue.Set(dest)
 			}
 
 			if wasPointer {
@@ -781,3 +782,80 @@ func ToGoReflect(xshape shape.Shape, ydata Schema, zreflect reflect.Type) (refle
 		},
 	)
 }
+
+// reflectLookup finds shapes of structs embedded in struct of type t, so that their fields are promoted,
+// like in generated serde. Shapes are taken from registry,
+// and shapes of structs that are not registered, are inferred from their reflect.Type.
+func reflectLookup(t reflect.Type) func(*shape.RefName) (shape.Shape, bool) {
+	var embedded map[string]shape.Shape
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.Anonymous {
+				continue
+			}
+
+			typ := field.Type
+			if typ.Kind() == reflect.Ptr {
+				typ = typ.Elem()
+			}
+
+			key := shape.ToGoTypeName(shape.MkRefNameFromReflect(typ), shape.WithPkgImportName(), shape.WithInstantiation())
+			if _, ok := embedded[key]; ok {
+				continue
+			}
+
+			embedded[key] = shape.FromGoReflect(typ, make(map[string]shape.Shape))
+			collect(typ)
+		}
+	}
+
+	return func(x *shape.RefName) (shape.Shape, bool) {
+		if result, ok := shape.LookupShape(x); ok {
+			return result, true
+		}
+
+		if embedded == nil {
+			embedded = make(map[string]shape.Shape)
+			collect(t)
+		}
+
+		result, ok := embedded[shape.ToGoTypeName(x, shape.WithPkgImportName(), shape.WithInstantiation())]
+		return result, ok
+	}
+}
+
+// structFieldByPath returns field of struct x selected by path, like [Base ID] for field ID promoted from embedded field Base.
+// Nil embedded pointer on the path is allocated when allocate is true, otherwise field is not found.
+func structFieldByPath(x reflect.Value, path []string, allocate bool) (reflect.Value, bool) {
+	for i, name := range path {
+		if i > 0 && x.Kind() == reflect.Ptr {
+			if x.IsNil() {
+				if !allocate || !x.CanSet() {
+					return reflect.Value{}, false
+				}
+				x.Set(reflect.New(x.Type().Elem()))
+			}
+			x = x.Elem()
+		}
+
+		if x.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+
+		x = x.FieldByName(name)
+		if !x.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+
+	return x, true
+}
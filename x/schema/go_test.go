@@ -3,6 +3,8 @@ package schema
 import (
 	"encoding/json"
 	"github.com/google/go-cmp/cmp"
+	"github.com/widmogrod/mkunion/x/shape"
+	"reflect"
 	"testing"
 	"testing/quick"
 )
@@ -131,6 +133,68 @@ func TestNonNative(t *testing.T) {
 	})
 }
 
+type embeddedBase struct {
+	ID string
+}
+
+type EmbeddedAudit struct {
+	Author string
+}
+
+type embeddingState struct {
+	embeddedBase
+	*EmbeddedAudit
+	Name string
+}
+
+func TestEmbeddedStruct(t *testing.T) {
+	s := shape.FromGoReflect(reflect.TypeOf(embeddingState{}), make(map[string]shape.Shape))
+
+	t.Run("fields of embedded struct are promoted", func(t *testing.T) {
+		value := embeddingState{
+			embeddedBase: embeddedBase{ID: "1"},
+			Name:         "state",
+		}
+
+		schemed := FromGoReflect(s, reflect.ValueOf(value))
+		expected := &Map{
+			"ID":   MkString("1"),
+			"Name": MkString("state"),
+		}
+		if diff := cmp.Diff(expected, schemed); diff != "" {
+			t.Error(diff)
+		}
+
+		result, err := ToGoReflect(s, schemed, reflect.TypeOf(value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(value, result.Interface(), cmp.AllowUnexported(embeddingState{})); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("embedded pointer is allocated, when its fields are present", func(t *testing.T) {
+		value := embeddingState{
+			embeddedBase:  embeddedBase{ID: "1"},
+			EmbeddedAudit: &EmbeddedAudit{Author: "author"},
+		}
+
+		schemed := FromGoReflect(s, reflect.ValueOf(value))
+		if _, ok := (*schemed.(*Map))["Author"]; !ok {
+			t.Errorf("expected Author in %#v", schemed)
+		}
+
+		result, err := ToGoReflect(s, schemed, reflect.TypeOf(value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(value, result.Interface(), cmp.AllowUnexported(embeddingState{})); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
 func assertTypeConversion[A any](t *testing.T, value A) {
 	expected := value
 	t.Logf("expected = %+#v", expected)
@@ -99,6 +99,129 @@ func MatchLocationR0(
 		f3(v)
 	}
 }
+
+// LocationEqual returns true, when a and b are the same variant of Location with equal fields.
+func LocationEqual(a, b Location) bool {
+	switch x := a.(type) {
+	case *LocationField:
+		y, ok := b.(*LocationField)
+		return ok && x.Equal(y)
+	case *LocationIndex:
+		y, ok := b.(*LocationIndex)
+		return ok && x.Equal(y)
+	case *LocationAnything:
+		y, ok := b.(*LocationAnything)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// LocationHash returns hash of x, that is the same for values equal according to LocationEqual.
+func LocationHash(x Location) uint64 {
+	switch y := x.(type) {
+	case *LocationField:
+		return y.Hash()
+	case *LocationIndex:
+		return y.Hash()
+	case *LocationAnything:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// LocationDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func LocationDeepCopy(x Location) Location {
+	switch y := x.(type) {
+	case *LocationField:
+		return y.DeepCopy()
+	case *LocationIndex:
+		return y.DeepCopy()
+	case *LocationAnything:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *LocationField) Equal(other *LocationField) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	return true
+}
+
+func (r *LocationField) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.LocationField")
+	h = shared.HashString(h, r.Name)
+	return h
+}
+
+func (r *LocationField) DeepCopy() *LocationField {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *LocationIndex) Equal(other *LocationIndex) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Index != other.Index {
+		return false
+	}
+	return true
+}
+
+func (r *LocationIndex) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.LocationIndex")
+	h = shared.HashUint64(h, uint64(r.Index))
+	return h
+}
+
+func (r *LocationIndex) DeepCopy() *LocationIndex {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *LocationAnything) Equal(other *LocationAnything) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *LocationAnything) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.LocationAnything")
+	return h
+}
+
+func (r *LocationAnything) DeepCopy() *LocationAnything {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/schema.Location", LocationFromJSON, LocationToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/schema.LocationAnything", LocationAnythingFromJSON, LocationAnythingToJSON)
@@ -117,7 +240,7 @@ func LocationFromJSON(x []byte) (Location, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data LocationUnionJSON
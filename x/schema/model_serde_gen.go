@@ -97,3 +97,34 @@ func (r *Field) _unmarshalJSONSchema(data []byte) (Schema, error) {
 	}
 	return result, nil
 }
+func (r *Field) Equal(other *Field) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if !SchemaEqual(r.Value, other.Value) {
+		return false
+	}
+	return true
+}
+
+func (r *Field) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.Field")
+	h = shared.HashString(h, r.Name)
+	h = shared.HashUint64(h, SchemaHash(r.Value))
+	return h
+}
+
+func (r *Field) DeepCopy() *Field {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Value = SchemaDeepCopy(r.Value)
+	return &result
+}
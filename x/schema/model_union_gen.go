@@ -159,6 +159,362 @@ func MatchSchemaR0(
 		f7(v)
 	}
 }
+
+// RewriteSchemaTopDown applies f to x, and then to each Schema nested in result of f (pre-order).
+// When f returns nil, nested values are not visited.
+func RewriteSchemaTopDown(x Schema, f func(Schema) Schema) Schema {
+	if x == nil {
+		return nil
+	}
+	return rewriteSchemaChildren(f(x), func(y Schema) Schema {
+		return RewriteSchemaTopDown(y, f)
+	})
+}
+
+// RewriteSchemaBottomUp applies f to each Schema nested in x, and then to x with already rewritten values (post-order).
+func RewriteSchemaBottomUp(x Schema, f func(Schema) Schema) Schema {
+	if x == nil {
+		return nil
+	}
+	return f(rewriteSchemaChildren(x, func(y Schema) Schema {
+		return RewriteSchemaBottomUp(y, f)
+	}))
+}
+
+// rewriteSchemaChildren returns shallow copy of x, where each Schema nested in x is replaced by result of f.
+// Variants without nested Schema are returned as they are, and x is never modified.
+func rewriteSchemaChildren(x Schema, f func(Schema) Schema) Schema {
+	switch v := x.(type) {
+	case *List:
+		result := *v
+		if *v != nil {
+			result = make([]Schema, len(*v))
+			for i1 := range *v {
+				result[i1] = f((*v)[i1])
+			}
+		}
+		return &result
+	case *Map:
+		result := *v
+		if *v != nil {
+			result = make(map[string]Schema, len(*v))
+			for k1, v1 := range *v {
+				result[k1] = f(v1)
+			}
+		}
+		return &result
+	}
+
+	return x
+}
+
+// SchemaEqual returns true, when a and b are the same variant of Schema with equal fields.
+func SchemaEqual(a, b Schema) bool {
+	switch x := a.(type) {
+	case *None:
+		y, ok := b.(*None)
+		return ok && x.Equal(y)
+	case *Bool:
+		y, ok := b.(*Bool)
+		return ok && x.Equal(y)
+	case *Number:
+		y, ok := b.(*Number)
+		return ok && x.Equal(y)
+	case *String:
+		y, ok := b.(*String)
+		return ok && x.Equal(y)
+	case *Binary:
+		y, ok := b.(*Binary)
+		return ok && x.Equal(y)
+	case *List:
+		y, ok := b.(*List)
+		return ok && x.Equal(y)
+	case *Map:
+		y, ok := b.(*Map)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// SchemaHash returns hash of x, that is the same for values equal according to SchemaEqual.
+func SchemaHash(x Schema) uint64 {
+	switch y := x.(type) {
+	case *None:
+		return y.Hash()
+	case *Bool:
+		return y.Hash()
+	case *Number:
+		return y.Hash()
+	case *String:
+		return y.Hash()
+	case *Binary:
+		return y.Hash()
+	case *List:
+		return y.Hash()
+	case *Map:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// SchemaDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func SchemaDeepCopy(x Schema) Schema {
+	switch y := x.(type) {
+	case *None:
+		return y.DeepCopy()
+	case *Bool:
+		return y.DeepCopy()
+	case *Number:
+		return y.DeepCopy()
+	case *String:
+		return y.DeepCopy()
+	case *Binary:
+		return y.DeepCopy()
+	case *List:
+		return y.DeepCopy()
+	case *Map:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *None) Equal(other *None) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *None) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.None")
+	return h
+}
+
+func (r *None) DeepCopy() *None {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Bool) Equal(other *Bool) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if *r != *other {
+		return false
+	}
+	return true
+}
+
+func (r *Bool) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.Bool")
+	h = shared.HashBool(h, bool(*r))
+	return h
+}
+
+func (r *Bool) DeepCopy() *Bool {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Number) Equal(other *Number) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if *r != *other {
+		return false
+	}
+	return true
+}
+
+func (r *Number) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.Number")
+	h = shared.HashFloat64(h, float64(*r))
+	return h
+}
+
+func (r *Number) DeepCopy() *Number {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *String) Equal(other *String) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if *r != *other {
+		return false
+	}
+	return true
+}
+
+func (r *String) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.String")
+	h = shared.HashString(h, string(*r))
+	return h
+}
+
+func (r *String) DeepCopy() *String {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Binary) Equal(other *Binary) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(*r) != len(*other) {
+		return false
+	}
+	for i1 := range *r {
+		if (*r)[i1] != (*other)[i1] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Binary) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.Binary")
+	h = shared.HashUint64(h, uint64(len(*r)))
+	for _, v1 := range *r {
+		h = shared.HashUint64(h, uint64(v1))
+	}
+	return h
+}
+
+func (r *Binary) DeepCopy() *Binary {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if *r != nil {
+		result = make([]uint8, len(*r))
+		copy(result, *r)
+	}
+	return &result
+}
+
+func (r *List) Equal(other *List) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(*r) != len(*other) {
+		return false
+	}
+	for i1 := range *r {
+		if !SchemaEqual((*r)[i1], (*other)[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *List) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.List")
+	h = shared.HashUint64(h, uint64(len(*r)))
+	for _, v1 := range *r {
+		h = shared.HashUint64(h, SchemaHash(v1))
+	}
+	return h
+}
+
+func (r *List) DeepCopy() *List {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if *r != nil {
+		result = make([]Schema, len(*r))
+		for i1 := range *r {
+			result[i1] = SchemaDeepCopy((*r)[i1])
+		}
+	}
+	return &result
+}
+
+func (r *Map) Equal(other *Map) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(*r) != len(*other) {
+		return false
+	}
+	for k1, v1 := range *r {
+		w1, ok := (*other)[k1]
+		if !ok {
+			return false
+		}
+		if !SchemaEqual(v1, w1) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Map) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schema.Map")
+	h = shared.HashUint64(h, uint64(len(*r)))
+	var s1 uint64
+	for k1, v1 := range *r {
+		e1 := shared.HashOffset
+		e1 = shared.HashString(e1, k1)
+		e1 = shared.HashUint64(e1, SchemaHash(v1))
+		s1 += e1
+	}
+	h = shared.HashUint64(h, s1)
+	return h
+}
+
+func (r *Map) DeepCopy() *Map {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if *r != nil {
+		result = make(map[string]Schema, len(*r))
+		for k1, v1 := range *r {
+			result[k1] = SchemaDeepCopy(v1)
+		}
+	}
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/schema.Binary", BinaryFromJSON, BinaryToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/schema.Bool", BoolFromJSON, BoolToJSON)
@@ -185,7 +541,7 @@ func SchemaFromJSON(x []byte) (Schema, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data SchemaUnionJSON
@@ -0,0 +1,146 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"github.com/widmogrod/mkunion/x/shape"
+	"github.com/widmogrod/mkunion/x/shared"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Validator is implemented by types tagged with //go:tag validate:"true",
+// generated Validate method enforces guards declared in field tags, like required, enum or between.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError describes field that doesn't satisfy its guard.
+// Location is the path to the field, the same as used by Get and LocationToStr.
+type ValidationError struct {
+	Location []Location
+	Err      error
+}
+
+func (e *ValidationError) Error() string {
+	return LocationToStr(e.Location) + ": " + e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors collects all invalid fields of a value, not only the first one.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	result := make([]string, len(e))
+	for i, err := range e {
+		result[i] = err.Error()
+	}
+	return strings.Join(result, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	result := make([]error, len(e))
+	for i, err := range e {
+		result[i] = err
+	}
+	return result
+}
+
+var registerValidator = sync.Map{}
+
+// ValidatorRegister registers validation function for type that cannot have methods, like union interface.
+func ValidatorRegister[A any](fullName string, validate func(A) error) {
+	registerValidator.Store(fullName, func(a any) error {
+		if x, ok := a.(A); ok {
+			return validate(x)
+		}
+		return nil
+	})
+}
+
+// Validate checks value against guards declared in tags.
+// It's the place to call after decoding, since JSON unmarshalling accepts any structurally valid document.
+// Types without generated Validate method are always valid.
+func Validate[A any](x A) error {
+	key := shared.FullTypeName(reflect.TypeOf(new(A)))
+	if validate, ok := registerValidator.Load(key); ok {
+		return validate.(func(any) error)(x)
+	}
+
+	if v, ok := any(x).(Validator); ok {
+		if isNilValidator(v) {
+			return nil
+		}
+		return v.Validate()
+	}
+
+	if v, ok := any(&x).(Validator); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
+
+// JSONUnmarshal decodes data with shared.JSONUnmarshal, and checks decoded value with Validate.
+// Nested values are not validated while they are decoded, but once by the decoded value,
+// so that every invalid field is reported with its full Location.
+func JSONUnmarshal[A any](data []byte) (A, error) {
+	result, err := shared.JSONUnmarshal[A](data)
+	if err != nil {
+		return result, fmt.Errorf("schema.JSONUnmarshal: %w", err)
+	}
+
+	err = Validate[A](result)
+	if err != nil {
+		return result, fmt.Errorf("schema.JSONUnmarshal: %w", err)
+	}
+
+	return result, nil
+}
+
+func isNilValidator(v Validator) bool {
+	value := reflect.ValueOf(v)
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
+
+// ValidateField is used by generated Validate methods, and appends error when value doesn't satisfy guard.
+func ValidateField(errs ValidationErrors, location []Location, guard shape.Guard, value any) ValidationErrors {
+	err := shape.ValidateGuard(guard, value)
+	if err == nil {
+		return errs
+	}
+
+	return append(errs, &ValidationError{
+		Location: location,
+		Err:      err,
+	})
+}
+
+// ValidateNested is used by generated Validate methods, and appends errors of nested value,
+// with location of nested value as prefix.
+func ValidateNested[A any](errs ValidationErrors, location []Location, value A) ValidationErrors {
+	err := Validate[A](value)
+	if err == nil {
+		return errs
+	}
+
+	var nested ValidationErrors
+	if errors.As(err, &nested) {
+		for _, e := range nested {
+			errs = append(errs, &ValidationError{
+				Location: append(append([]Location{}, location...), e.Location...),
+				Err:      e.Err,
+			})
+		}
+		return errs
+	}
+
+	return append(errs, &ValidationError{
+		Location: location,
+		Err:      err,
+	})
+}
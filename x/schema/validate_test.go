@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/widmogrod/mkunion/x/shape"
+	"testing"
+)
+
+type validateUser struct {
+	Name string
+}
+
+func (r *validateUser) Validate() error {
+	var errs ValidationErrors
+	errs = ValidateField(errs, []Location{&LocationField{Name: "Name"}}, &shape.Required{}, r.Name)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(1))
+	assert.NoError(t, Validate[*validateUser](nil))
+	assert.NoError(t, Validate(validateUser{Name: "Jane"}))
+	assert.EqualError(t, Validate(validateUser{}), "Name: value is required")
+	assert.EqualError(t, Validate(&validateUser{}), "Name: value is required")
+}
+
+func TestJSONUnmarshal(t *testing.T) {
+	result, err := JSONUnmarshal[*validateUser]([]byte(`{"Name": "Jane"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &validateUser{Name: "Jane"}, result)
+
+	_, err = JSONUnmarshal[validateUser]([]byte(`{"Name": ""}`))
+	assert.EqualError(t, err, "schema.JSONUnmarshal: Name: value is required")
+
+	var errs ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+
+	_, err = JSONUnmarshal[validateUser]([]byte(`[]`))
+	assert.ErrorContains(t, err, "schema.JSONUnmarshal: shared.JSONUnmarshal")
+}
+
+func TestValidateNested(t *testing.T) {
+	var errs ValidationErrors
+	errs = ValidateNested(errs, []Location{&LocationField{Name: "Users"}, &LocationIndex{Index: 1}}, validateUser{})
+	errs = ValidateNested(errs, []Location{&LocationField{Name: "Owner"}}, &validateUser{Name: "Jane"})
+	errs = ValidateField(errs, []Location{&LocationField{Name: "Role"}}, &shape.Enum{Val: []string{"admin"}}, "guest")
+
+	assert.EqualError(t, errs, `Users[1].Name: value is required; Role: value "guest" is not one of ["admin"]`)
+	assert.Equal(t, []Location{&LocationField{Name: "Users"}, &LocationIndex{Index: 1}, &LocationField{Name: "Name"}}, errs[0].Location)
+
+	var guardErr *shape.GuardError
+	assert.True(t, errors.As(error(errs), &guardErr))
+	assert.Equal(t, &shape.Required{}, guardErr.Guard)
+}
@@ -84,6 +84,99 @@ func MatchSearchCMDR0(
 		f2(v)
 	}
 }
+
+// SearchCMDEqual returns true, when a and b are the same variant of SearchCMD with equal fields.
+func SearchCMDEqual(a, b SearchCMD) bool {
+	switch x := a.(type) {
+	case *Term:
+		y, ok := b.(*Term)
+		return ok && x.Equal(y)
+	case *Fulltext:
+		y, ok := b.(*Fulltext)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// SearchCMDHash returns hash of x, that is the same for values equal according to SearchCMDEqual.
+func SearchCMDHash(x SearchCMD) uint64 {
+	switch y := x.(type) {
+	case *Term:
+		return y.Hash()
+	case *Fulltext:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// SearchCMDDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func SearchCMDDeepCopy(x SearchCMD) SearchCMD {
+	switch y := x.(type) {
+	case *Term:
+		return y.DeepCopy()
+	case *Fulltext:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Term) Equal(other *Term) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Term != other.Term {
+		return false
+	}
+	return true
+}
+
+func (r *Term) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "search.Term")
+	h = shared.HashString(h, r.Term)
+	return h
+}
+
+func (r *Term) DeepCopy() *Term {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Fulltext) Equal(other *Fulltext) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Query != other.Query {
+		return false
+	}
+	return true
+}
+
+func (r *Fulltext) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "search.Fulltext")
+	h = shared.HashString(h, r.Query)
+	return h
+}
+
+func (r *Fulltext) DeepCopy() *Fulltext {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/search.Fulltext", FulltextFromJSON, FulltextToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/search.SearchCMD", SearchCMDFromJSON, SearchCMDToJSON)
@@ -100,7 +193,7 @@ func SearchCMDFromJSON(x []byte) (SearchCMD, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data SearchCMDUnionJSON
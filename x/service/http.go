@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/widmogrod/mkunion/x/schema"
+	"github.com/widmogrod/mkunion/x/shared"
+	"io"
+	"net/http"
+)
+
+// Error is returned by HTTP client, when service responds with status other than 200.
+// Handler can return Error to respond with a specific status, otherwise 500 is used.
+type Error struct {
+	Status  int    `json:"-"`
+	Message string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("service: status %d: %s", e.Status, e.Message)
+}
+
+// HandleJSON decodes request body with JSON serde, validates it with schema.Validate,
+// and responds with result of handle encoded with JSON serde.
+// Generated service routers use it for every command of a union.
+func HandleJSON[A, B any](handle func(ctx context.Context, x A) (B, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, &Error{Status: http.StatusMethodNotAllowed, Message: "method not allowed"})
+			return
+		}
+
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, &Error{Status: http.StatusBadRequest, Message: err.Error()})
+			return
+		}
+
+		in, err := shared.JSONUnmarshal[A](data)
+		if err != nil {
+			writeError(w, &Error{Status: http.StatusBadRequest, Message: err.Error()})
+			return
+		}
+
+		err = schema.Validate[A](in)
+		if err != nil {
+			writeError(w, &Error{Status: http.StatusBadRequest, Message: err.Error()})
+			return
+		}
+
+		out, err := handle(r.Context(), in)
+		if err != nil {
+			var serviceErr *Error
+			if errors.As(err, &serviceErr) {
+				writeError(w, serviceErr)
+				return
+			}
+
+			writeError(w, &Error{Status: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+
+		result, err := shared.JSONMarshal[B](out)
+		if err != nil {
+			writeError(w, &Error{Status: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(result)
+	}
+}
+
+func writeError(w http.ResponseWriter, err *Error) {
+	result, _ := json.Marshal(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.Status)
+	_, _ = w.Write(result)
+}
+
+// CallJSON posts x encoded with JSON serde to url, and decodes response with JSON serde.
+// Generated service clients use it for every command of a union.
+func CallJSON[A, B any](ctx context.Context, client *http.Client, url string, x A) (B, error) {
+	var result B
+
+	data, err := shared.JSONMarshal[A](x)
+	if err != nil {
+		return result, fmt.Errorf("service.CallJSON: failed to marshal request; %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return result, fmt.Errorf("service.CallJSON: failed to create request; %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, fmt.Errorf("service.CallJSON: failed to send request; %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, fmt.Errorf("service.CallJSON: failed to read response; %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		serviceErr := &Error{Status: resp.StatusCode}
+		if json.Unmarshal(body, serviceErr) != nil || serviceErr.Message == "" {
+			serviceErr.Message = string(body)
+		}
+		return result, serviceErr
+	}
+
+	result, err = shared.JSONUnmarshal[B](body)
+	if err != nil {
+		return result, fmt.Errorf("service.CallJSON: failed to unmarshal response; %w", err)
+	}
+
+	return result, nil
+}
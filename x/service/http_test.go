@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleJSON_CallJSON(t *testing.T) {
+	server := httptest.NewServer(HandleJSON(func(ctx context.Context, x string) (int, error) {
+		switch x {
+		case "":
+			return 0, &Error{Status: http.StatusConflict, Message: "empty"}
+		case "fail":
+			return 0, errors.New("failed")
+		}
+		return len(x), nil
+	}))
+	defer server.Close()
+
+	result, err := CallJSON[string, int](context.Background(), server.Client(), server.URL, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result)
+
+	_, err = CallJSON[string, int](context.Background(), server.Client(), server.URL, "")
+	assert.Equal(t, &Error{Status: http.StatusConflict, Message: "empty"}, err)
+
+	_, err = CallJSON[string, int](context.Background(), server.Client(), server.URL, "fail")
+	assert.Equal(t, &Error{Status: http.StatusInternalServerError, Message: "failed"}, err)
+
+	_, err = CallJSON[int, int](context.Background(), server.Client(), server.URL, 1)
+	var serviceErr *Error
+	assert.True(t, errors.As(err, &serviceErr))
+	assert.Equal(t, http.StatusBadRequest, serviceErr.Status)
+
+	resp, err := server.Client().Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, http.MethodPost, resp.Header.Get("Allow"))
+}
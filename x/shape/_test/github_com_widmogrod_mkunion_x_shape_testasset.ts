@@ -0,0 +1,94 @@
+//generated by mkunion
+export type Example = {
+	"$type"?: "testasset.A",
+	"testasset.A": A
+} | {
+	"$type"?: "testasset.B",
+	"testasset.B": B
+} | {
+	"$type"?: "testasset.C",
+	"testasset.C": C
+} | {
+	"$type"?: "testasset.D",
+	"testasset.D": D
+} | {
+	"$type"?: "testasset.E",
+	"testasset.E": E
+} | {
+	"$type"?: "testasset.F",
+	"testasset.F": F
+} | {
+	"$type"?: "testasset.H",
+	"testasset.H": H
+} | {
+	"$type"?: "testasset.I",
+	"testasset.I": I
+} | {
+	"$type"?: "testasset.J",
+	"testasset.J": J
+} | {
+	"$type"?: "testasset.K",
+	"testasset.K": K
+} | {
+	"$type"?: "testasset.L",
+	"testasset.L": L
+} | {
+	"$type"?: "testasset.M",
+	"testasset.M": M
+} | {
+	"$type"?: "testasset.N",
+	"testasset.N": N
+} | {
+	"$type"?: "testasset.O",
+	"testasset.O": O
+} | {
+	"$type"?: "testasset.P",
+	"testasset.P": P
+}
+
+export type A = {
+	Name?: string,
+}
+
+export type B = {
+	Age?: number,
+	A?: A,
+	T?: string,
+}
+
+export type C = string
+
+export type D = number
+
+export type E = number
+
+export type F = boolean
+
+export type H = {[key: string]: Example}
+
+export type I = Example[]
+
+export type J = string[]
+
+export type K = A
+
+export type L = List
+
+export type List = {}
+
+export type M = List
+
+export type N = number
+
+export type O = ListOf<number>
+
+export type ListOf<T> = {}
+
+export type P = ListOf2<ListOf<any>, ListOf2<number, number>>
+
+export type ListOf2<T1, T2> = {
+	Data?: T1,
+	ListOf?: ListOf<T1>,
+}
+
+
@@ -0,0 +1,4 @@
+//generated by mkunion
+export type SomeStruct = {}
+
+
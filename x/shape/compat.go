@@ -0,0 +1,405 @@
+package shape
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CompatChange describes change between two versions of a type,
+// and whether values serialised with generated JSON serde can still be read.
+type CompatChange struct {
+	// Path locates change, like "example.Shape.Circle.Radius", where "[]" denotes element of list,
+	// and "[key]" or "[value]" denotes key or value of map.
+	Path   string
+	Reason string
+	// Backward is true, when values serialised before change can be read after it.
+	Backward bool
+	// Forward is true, when values serialised after change can be read by code from before it.
+	Forward bool
+}
+
+const (
+	CompatFull     = "compatible"
+	CompatBackward = "backward"
+	CompatForward  = "forward"
+	CompatBreaking = "breaking"
+)
+
+// Level returns one of CompatFull, CompatBackward, CompatForward or CompatBreaking.
+func (c CompatChange) Level() string {
+	switch {
+	case c.Backward && c.Forward:
+		return CompatFull
+	case c.Backward:
+		return CompatBackward
+	case c.Forward:
+		return CompatForward
+	default:
+		return CompatBreaking
+	}
+}
+
+func (c CompatChange) String() string {
+	return fmt.Sprintf("%s\t%s: %s", c.Level(), c.Path, c.Reason)
+}
+
+// CompatJSON compares two snapshots of shapes, and returns changes ordered by path,
+// that affect values serialised with generated JSON serde.
+//
+// Only unions and types tagged with serde:"json" are compared, together with types they refer to in the same snapshot.
+// Types are matched by package import name and name.
+// Names of structs and their fields order don't matter in JSON, but names of variants do,
+// since they are stored in "$type" field, so renamed variant is reported as removed and added,
+// unless it declares its previous name as alias.
+func CompatJSON(base, current []Shape) []CompatChange {
+	c := &compat{
+		base:    indexShapes(base),
+		current: indexShapes(current),
+	}
+	c.differ = &differ{
+		unwrap:    unwrapCompat,
+		fieldName: compatFieldName,
+		refs:      c.compareRefs,
+		report:    c.classify,
+		visited:   make(map[string]bool),
+	}
+
+	for _, key := range sortedKeys(c.base) {
+		x := c.base[key]
+		if !isSerialisedJSON(x) {
+			continue
+		}
+
+		y, ok := c.current[key]
+		if !ok {
+			c.report(compatRootPath(x), "type removed", false, true)
+			continue
+		}
+
+		c.compareNamed(compatRootPath(x), x, y)
+	}
+
+	for _, key := range sortedKeys(c.current) {
+		y := c.current[key]
+		if _, ok := c.base[key]; !ok && isSerialisedJSON(y) {
+			c.report(compatRootPath(y), "type added", true, true)
+		}
+	}
+
+	sort.SliceStable(c.changes, func(i, j int) bool {
+		return c.changes[i].Path < c.changes[j].Path
+	})
+
+	return c.changes
+}
+
+// compat classifies changes found by differ, which compares types the way generated JSON serde sees them:
+// fields are matched by JSON name, pointers and named types are compared as their underlying types,
+// and references to types from compared snapshots are followed.
+type compat struct {
+	base    map[string]Shape
+	current map[string]Shape
+	differ  *differ
+	changes []CompatChange
+}
+
+func (c *compat) report(path, reason string, backward, forward bool) {
+	c.changes = append(c.changes, CompatChange{
+		Path:     path,
+		Reason:   reason,
+		Backward: backward,
+		Forward:  forward,
+	})
+}
+
+func (c *compat) compareNamed(path string, x, y Shape) {
+	if !c.differ.visit(x, y) {
+		return
+	}
+
+	if a, ok := x.(*UnionLike); ok {
+		if b, ok := y.(*UnionLike); ok {
+			if TagHasOption(b.Tags, TagUnionName, "noserde") {
+				return
+			}
+
+			encodingA, errA := UnionJSONEncoding(a)
+			encodingB, errB := UnionJSONEncoding(b)
+			if errA == nil && errB == nil && *encodingA != *encodingB {
+				c.report(path, fmt.Sprintf("encoding changed from %s to %s", compatEncodingName(encodingA), compatEncodingName(encodingB)), false, false)
+			}
+		}
+	}
+
+	c.differ.diff(path, x, y)
+}
+
+func (c *compat) compareRefs(path string, a, b *RefName) {
+	x, okx := c.base[compatKey(a)]
+	y, oky := c.current[compatKey(b)]
+	if okx && oky {
+		c.compareNamed(compatRootPath(y), x, y)
+		return
+	}
+
+	// types declared outside of compared packages, like time.Time, are compared by name
+	if compatKey(a) != compatKey(b) {
+		c.report(path, fmt.Sprintf("type changed from %s to %s", compatTypeName(a), compatTypeName(b)), false, false)
+	}
+}
+
+func (c *compat) classify(x Change) {
+	MatchChangeR0(
+		x,
+		func(x *TypeChanged) {
+			c.typeChanged(x.Path, x.From, x.To)
+		},
+		func(x *VariantAdded) {
+			c.report(x.Path, fmt.Sprintf("variant %s added", compatVariantName(x.Variant)), true, false)
+		},
+		func(x *VariantRemoved) {
+			c.report(x.Path, fmt.Sprintf("variant %s removed", compatVariantName(x.Variant)), false, true)
+		},
+		func(x *VariantRenamed) {
+			// old name is still accepted when reading, but code from before the change doesn't know the new one
+			c.report(x.Path, fmt.Sprintf("variant %s renamed to %s", compatVariantName(x.From), compatVariantName(x.To)), true, false)
+		},
+		func(x *FieldAdded) {
+			if IsRequired(x.Field.Guard) {
+				c.report(x.Path, "required field added", false, true)
+			} else {
+				c.report(x.Path, "field added", true, true)
+			}
+		},
+		func(x *FieldRemoved) {
+			// field is ignored when reading old values, and old code reads zero value, unless it was required
+			c.report(x.Path, "field removed", true, !IsRequired(x.Field.Guard))
+		},
+		func(x *TagChanged) {
+			// tags that matter, like JSON name of field or union encoding, are compared separately
+		},
+		func(x *GuardChanged) {
+			switch {
+			case !IsRequired(x.From) && IsRequired(x.To):
+				c.report(x.Path, "field became required", false, true)
+			case IsRequired(x.From) && !IsRequired(x.To):
+				c.report(x.Path, "field is no longer required", true, false)
+			}
+		},
+		func(x *TypeParamsChanged) {
+			// type parameters are not serialised, only type arguments are
+		},
+	)
+}
+
+func (c *compat) typeChanged(path string, x, y Shape) {
+	a, isRefA := x.(*RefName)
+	b, isRefB := y.(*RefName)
+	if isRefA && isRefB {
+		if len(a.Indexed) != len(b.Indexed) {
+			c.report(path, fmt.Sprintf("type changed from %s to %s", compatTypeName(a), compatTypeName(b)), false, false)
+			return
+		}
+
+		for i := range a.Indexed {
+			c.differ.diff(fmt.Sprintf("%s[%d]", path, i), a.Indexed[i], b.Indexed[i])
+		}
+
+		c.compareRefs(path, a, b)
+		return
+	}
+
+	if isRefA {
+		if resolved, ok := c.base[compatKey(a)]; ok {
+			c.differ.diff(path, resolved, y)
+			return
+		}
+	}
+
+	if isRefB {
+		if resolved, ok := c.current[compatKey(b)]; ok {
+			c.differ.diff(path, x, resolved)
+			return
+		}
+	}
+
+	if _, ok := y.(*Any); ok {
+		c.report(path, fmt.Sprintf("type changed from %s to any", compatTypeName(x)), true, false)
+		return
+	}
+
+	if a, ok := x.(*PrimitiveLike); ok {
+		if b, ok := y.(*PrimitiveLike); ok {
+			c.comparePrimitives(path, a, b)
+			return
+		}
+	}
+
+	if a, ok := x.(*ListLike); ok {
+		if b, ok := y.(*ListLike); ok {
+			c.compareLists(path, a, b)
+			return
+		}
+	}
+
+	c.report(path, fmt.Sprintf("type changed from %s to %s", compatTypeName(x), compatTypeName(y)), false, false)
+}
+
+func (c *compat) comparePrimitives(path string, a, b *PrimitiveLike) {
+	x, okx := a.Kind.(*NumberLike)
+	y, oky := b.Kind.(*NumberLike)
+	if okx && oky {
+		backward, forward := numberFits(x.Kind, y.Kind), numberFits(y.Kind, x.Kind)
+		if !backward || !forward {
+			c.report(path, fmt.Sprintf("type changed from %s to %s", compatTypeName(a), compatTypeName(b)), backward, forward)
+		}
+		return
+	}
+
+	c.report(path, fmt.Sprintf("type changed from %s to %s", compatTypeName(a), compatTypeName(b)), false, false)
+}
+
+func (c *compat) compareLists(path string, a, b *ListLike) {
+	if IsBinary(a) != IsBinary(b) {
+		// binary is serialised as base64 string
+		c.report(path, fmt.Sprintf("type changed from %s to %s", compatTypeName(a), compatTypeName(b)), false, false)
+		return
+	}
+
+	switch {
+	case a.ArrayLen == nil && b.ArrayLen != nil:
+		c.report(path, fmt.Sprintf("slice changed to array of length %d", *b.ArrayLen), false, true)
+	case a.ArrayLen != nil && b.ArrayLen == nil:
+		c.report(path, fmt.Sprintf("array of length %d changed to slice", *a.ArrayLen), true, false)
+	case a.ArrayLen != nil && b.ArrayLen != nil && *a.ArrayLen != *b.ArrayLen:
+		c.report(path, fmt.Sprintf("array length changed from %d to %d", *a.ArrayLen, *b.ArrayLen), *a.ArrayLen < *b.ArrayLen, *a.ArrayLen > *b.ArrayLen)
+	}
+
+	c.differ.diff(path+"[]", a.Element, b.Element)
+}
+
+// numberFits returns true, when every value of kind from can be read as kind to.
+func numberFits(from, to NumberKind) bool {
+	fromFloat, fromSigned, fromBits := numberRange(from)
+	toFloat, toSigned, toBits := numberRange(to)
+
+	switch {
+	case toFloat && fromFloat:
+		return fromBits <= toBits
+	case toFloat:
+		// integers are exact in float mantissa
+		return (toBits == 64 && fromBits <= 53) || (toBits == 32 && fromBits <= 24)
+	case fromFloat:
+		return false
+	case fromSigned && !toSigned:
+		return false
+	case !fromSigned && toSigned:
+		return fromBits < toBits
+	default:
+		return fromBits <= toBits
+	}
+}
+
+func numberRange(x NumberKind) (float, signed bool, bits int) {
+	switch x.(type) {
+	case *UInt8:
+		return false, false, 8
+	case *UInt16:
+		return false, false, 16
+	case *UInt32:
+		return false, false, 32
+	case *UInt, *UInt64:
+		return false, false, 64
+	case *Int8:
+		return false, true, 8
+	case *Int16:
+		return false, true, 16
+	case *Int32:
+		return false, true, 32
+	case *Int, *Int64:
+		return false, true, 64
+	case *Float32:
+		return true, true, 32
+	case *Float64:
+		return true, true, 64
+	}
+
+	return true, true, 64
+}
+
+// unwrapCompat returns shape, that has the same JSON representation as x.
+// Pointers are serialised as their value, or null, and named types as their underlying type.
+func unwrapCompat(x Shape) Shape {
+	switch y := x.(type) {
+	case *PointerLike:
+		return unwrapCompat(y.Type)
+	case *AliasLike:
+		return unwrapCompat(y.Type)
+	}
+
+	return x
+}
+
+func isSerialisedJSON(x Shape) bool {
+	tags := Tags(x)
+	if _, isUnion := x.(*UnionLike); isUnion {
+		return !TagHasOption(tags, TagUnionName, "noserde")
+	}
+
+	if _, isVariant := tags[TagUnionName]; isVariant {
+		// variants are compared together with union
+		return false
+	}
+
+	return TagHasSerde(tags, "json")
+}
+
+func indexShapes(shapes []Shape) map[string]Shape {
+	result := make(map[string]Shape)
+	for _, x := range shapes {
+		result[compatKey(x)] = x
+	}
+
+	return result
+}
+
+func sortedKeys(x map[string]Shape) []string {
+	result := make([]string, 0, len(x))
+	for key := range x {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func compatKey(x Shape) string {
+	return ToGoPkgImportName(x) + "." + Name(x)
+}
+
+func compatRootPath(x Shape) string {
+	return ToGoPkgName(x) + "." + Name(x)
+}
+
+// compatVariantName returns name of variant stored in "$type" field by generated JSON serde.
+func compatVariantName(x Shape) string {
+	return VariantDiscriminator(x)
+}
+
+func compatEncodingName(x *JSONUnionEncoding) string {
+	switch x.Kind {
+	case JSONUnionUntagged:
+		return x.Kind
+	case JSONUnionAdjacent:
+		return fmt.Sprintf("%s,tag=%s,content=%s", x.Kind, x.Tag, x.Content)
+	}
+	return fmt.Sprintf("%s,tag=%s", x.Kind, x.Tag)
+}
+
+func compatFieldName(x *FieldLike) string {
+	return TagGetValue(x.Tags, "json", x.Name)
+}
+
+func compatTypeName(x Shape) string {
+	return ToGoTypeName(x, WithPkgImportName())
+}
@@ -0,0 +1,172 @@
+package shape
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func inferCompatShapes(t *testing.T, body string) []Shape {
+	t.Helper()
+
+	inferred, err := InferFromFileWithContentBody(body, "github.com/widmogrod/mkunion/x/shape/compat")
+	require.NoError(t, err)
+
+	return inferred.RetrieveShapes()
+}
+
+func TestCompatJSON(t *testing.T) {
+	base := inferCompatShapes(t, `package compat
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car struct {
+		Wheels int32
+		Owner  *Person
+	}
+	Boat struct {
+		Sails uint8
+		Name  string
+	}
+	Bike struct{}
+)
+
+//go:tag serde:"json"
+type Person struct {
+	Name string
+	Age  int
+	Tags []string
+}
+
+type NotSerialised struct {
+	Value string
+}
+`)
+
+	current := inferCompatShapes(t, `package compat
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car struct {
+		Wheels int64
+		Owner  *Person
+	}
+	Boat struct {
+		Sails uint8
+		Title string `+"`json:\"Name\"`"+`
+		Crew  int
+		Flag  string `+"`required:\"true\"`"+`
+	}
+	Plane struct{}
+)
+
+//go:tag serde:"json"
+type Person struct {
+	Name Name
+	Age  float32
+	Tags map[string]string
+}
+
+type Name string
+
+type NotSerialised struct {
+	Value int
+}
+`)
+
+	changes := CompatJSON(base, current)
+	assert.Equal(t, []CompatChange{
+		{Path: "compat.Person.Age", Reason: "type changed from int to float32", Backward: false, Forward: false},
+		{Path: "compat.Person.Tags", Reason: "type changed from []string to map[string]string", Backward: false, Forward: false},
+		{Path: "compat.Vehicle", Reason: "variant compat.Bike removed", Backward: false, Forward: true},
+		{Path: "compat.Vehicle", Reason: "variant compat.Plane added", Backward: true, Forward: false},
+		{Path: "compat.Vehicle.Boat.Crew", Reason: "field added", Backward: true, Forward: true},
+		{Path: "compat.Vehicle.Boat.Flag", Reason: "required field added", Backward: false, Forward: true},
+		{Path: "compat.Vehicle.Car.Wheels", Reason: "type changed from int32 to int64", Backward: true, Forward: false},
+	}, changes)
+}
+
+func TestCompatJSON_Unchanged(t *testing.T) {
+	shapes := inferCompatShapes(t, `package compat
+
+//go:tag mkunion:"Tree"
+type (
+	Branch struct {
+		L, R Tree
+		Meta map[string]Tree
+	}
+	Leaf struct{ Value int }
+)
+`)
+
+	assert.Empty(t, CompatJSON(shapes, shapes))
+}
+
+func TestCompatChange_Level(t *testing.T) {
+	assert.Equal(t, CompatFull, CompatChange{Backward: true, Forward: true}.Level())
+	assert.Equal(t, CompatBackward, CompatChange{Backward: true}.Level())
+	assert.Equal(t, CompatForward, CompatChange{Forward: true}.Level())
+	assert.Equal(t, CompatBreaking, CompatChange{}.Level())
+}
+
+func TestNumberFits(t *testing.T) {
+	assert.True(t, numberFits(&Int8{}, &Int64{}))
+	assert.False(t, numberFits(&Int64{}, &Int8{}))
+	assert.True(t, numberFits(&UInt8{}, &Int16{}))
+	assert.False(t, numberFits(&UInt16{}, &Int16{}))
+	assert.False(t, numberFits(&Int8{}, &UInt64{}))
+	assert.True(t, numberFits(&Int32{}, &Float64{}))
+	assert.False(t, numberFits(&Int64{}, &Float64{}))
+	assert.False(t, numberFits(&Float32{}, &Int64{}))
+	assert.True(t, numberFits(&Float32{}, &Float64{}))
+}
+
+func TestCompatJSON_VariantAlias(t *testing.T) {
+	base := inferCompatShapes(t, `package compat
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car  struct{ Wheels int }
+	Boat struct{ Sails int }
+)
+`)
+
+	current := inferCompatShapes(t, `package compat
+
+//go:tag mkunion:"Vehicle"
+type (
+	//go:tag variant:"compat.Car"
+	Automobile struct{ Wheels int }
+	//go:tag alias:"Boat"
+	Ship struct{ Sails int }
+)
+`)
+
+	changes := CompatJSON(base, current)
+	assert.Equal(t, []CompatChange{
+		{Path: "compat.Vehicle", Reason: "variant compat.Boat renamed to compat.Ship", Backward: true, Forward: false},
+	}, changes)
+}
+
+func TestCompatJSON_EncodingChanged(t *testing.T) {
+	base := inferCompatShapes(t, `package compat
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car struct{ Wheels int }
+)
+`)
+
+	current := inferCompatShapes(t, `package compat
+
+//go:tag mkunion:"Vehicle" json:"internal,tag=type"
+type (
+	Car struct{ Wheels int }
+)
+`)
+
+	assert.Equal(t, []CompatChange{
+		{Path: "compat.Vehicle", Reason: "encoding changed from external,tag=$type to internal,tag=type", Backward: false, Forward: false},
+	}, CompatJSON(base, current))
+}
@@ -0,0 +1,338 @@
+package shape
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Change describes single difference between two versions of a type, found by Diff.
+// Path locates change, like "example.Shape.Circle.Radius", where "[]" denotes element of list,
+// "[key]" or "[value]" denotes key or value of map, and "[0]" denotes first type argument of a reference.
+//
+//go:tag mkunion:"Change"
+type (
+	// TypeChanged reports type replaced by type of different kind, name, or array length.
+	// Types nested in From and To are not compared.
+	TypeChanged struct {
+		Path string
+		From Shape
+		To   Shape
+	}
+	VariantAdded struct {
+		Path    string
+		Variant Shape
+	}
+	VariantRemoved struct {
+		Path    string
+		Variant Shape
+	}
+	// VariantRenamed reports variant, which declares name of removed variant as its alias.
+	VariantRenamed struct {
+		Path string
+		From Shape
+		To   Shape
+	}
+	FieldAdded struct {
+		Path  string
+		Field *FieldLike
+	}
+	FieldRemoved struct {
+		Path  string
+		Field *FieldLike
+	}
+	// TagChanged reports tag, that was added when From is nil, removed when To is nil, or changed.
+	TagChanged struct {
+		Path string
+		Name string
+		From *Tag
+		To   *Tag
+	}
+	GuardChanged struct {
+		Path string
+		From Guard
+		To   Guard
+	}
+	TypeParamsChanged struct {
+		Path string
+		From []TypeParam
+		To   []TypeParam
+	}
+)
+
+// ChangePath returns path of type, field or tag, that changed.
+func ChangePath(x Change) string {
+	return MatchChangeR1(
+		x,
+		func(x *TypeChanged) string { return x.Path },
+		func(x *VariantAdded) string { return x.Path },
+		func(x *VariantRemoved) string { return x.Path },
+		func(x *VariantRenamed) string { return x.Path },
+		func(x *FieldAdded) string { return x.Path },
+		func(x *FieldRemoved) string { return x.Path },
+		func(x *TagChanged) string { return x.Path },
+		func(x *GuardChanged) string { return x.Path },
+		func(x *TypeParamsChanged) string { return x.Path },
+	)
+}
+
+// Diff compares two versions of a type, and returns changes, that turn a into b, in order in which they were found.
+//
+// Names of a and b are not compared, so that renamed type can be compared with its previous version.
+// Fields are matched by name, and variants by name stored in "$type" field, or by alias declared with `alias` tag.
+// Types, that a and b refer to, are compared by name and type arguments, without following references.
+func Diff(a, b Shape) []Change {
+	d := &differ{
+		unwrap: func(x Shape) Shape {
+			return x
+		},
+		fieldName: func(x *FieldLike) string {
+			return x.Name
+		},
+		visited: make(map[string]bool),
+	}
+	d.report = func(x Change) {
+		d.changes = append(d.changes, x)
+	}
+
+	d.diff(diffPath(a), a, b)
+
+	return d.changes
+}
+
+// differ walks two versions of a type, and reports changes between them.
+// Compatibility checks use it with functions, that compare types the way they are serialised.
+type differ struct {
+	// unwrap returns shape, that is compared in place of x
+	unwrap func(x Shape) Shape
+	// fieldName returns name, that identifies field in both versions of struct
+	fieldName func(x *FieldLike) string
+	// refs, when set, is called for references to the same type, after their type arguments were compared
+	refs    func(path string, a, b *RefName)
+	report  func(x Change)
+	visited map[string]bool
+	changes []Change
+}
+
+// visit returns true, when named types a and b are compared for the first time.
+func (d *differ) visit(a, b Shape) bool {
+	key := ToGoPkgImportName(a) + "." + Name(a) + "->" + ToGoPkgImportName(b) + "." + Name(b)
+	if d.visited[key] {
+		return false
+	}
+	d.visited[key] = true
+
+	return true
+}
+
+// diffNamed compares named types once, so that variant, that is also referred to by name, is not reported twice.
+func (d *differ) diffNamed(path string, a, b Shape) {
+	if d.visit(a, b) {
+		d.diff(path, a, b)
+	}
+}
+
+func (d *differ) diff(path string, a, b Shape) {
+	a, b = d.unwrap(a), d.unwrap(b)
+
+	switch x := a.(type) {
+	case *Any:
+		if _, ok := b.(*Any); ok {
+			return
+		}
+
+	case *RefName:
+		if y, ok := b.(*RefName); ok && x.PkgImportName == y.PkgImportName && x.Name == y.Name && len(x.Indexed) == len(y.Indexed) {
+			for i := range x.Indexed {
+				d.diff(fmt.Sprintf("%s[%d]", path, i), x.Indexed[i], y.Indexed[i])
+			}
+			if d.refs != nil {
+				d.refs(path, x, y)
+			}
+			return
+		}
+
+	case *PointerLike:
+		if y, ok := b.(*PointerLike); ok {
+			d.diff(path, x.Type, y.Type)
+			return
+		}
+
+	case *AliasLike:
+		if y, ok := b.(*AliasLike); ok && x.IsAlias == y.IsAlias {
+			d.diffTags(path, x.Tags, y.Tags)
+			d.diffTypeParams(path, x.TypeParams, y.TypeParams)
+			d.diff(path, x.Type, y.Type)
+			return
+		}
+
+	case *PrimitiveLike:
+		if y, ok := b.(*PrimitiveLike); ok && ToGoTypeName(x) == ToGoTypeName(y) {
+			return
+		}
+
+	case *ListLike:
+		if y, ok := b.(*ListLike); ok && IsBinary(x) == IsBinary(y) && sameArrayLen(x.ArrayLen, y.ArrayLen) {
+			d.diff(path+"[]", x.Element, y.Element)
+			return
+		}
+
+	case *MapLike:
+		if y, ok := b.(*MapLike); ok {
+			d.diff(path+"[key]", x.Key, y.Key)
+			d.diff(path+"[value]", x.Val, y.Val)
+			return
+		}
+
+	case *StructLike:
+		if y, ok := b.(*StructLike); ok {
+			d.diffStructs(path, x, y)
+			return
+		}
+
+	case *UnionLike:
+		if y, ok := b.(*UnionLike); ok {
+			d.diffUnions(path, x, y)
+			return
+		}
+	}
+
+	d.report(&TypeChanged{Path: path, From: a, To: b})
+}
+
+func (d *differ) diffStructs(path string, a, b *StructLike) {
+	d.diffTags(path, a.Tags, b.Tags)
+	d.diffTypeParams(path, a.TypeParams, b.TypeParams)
+
+	fields := make(map[string]*FieldLike)
+	for _, field := range b.Fields {
+		fields[d.fieldName(field)] = field
+	}
+
+	previous := make(map[string]bool)
+	for _, x := range a.Fields {
+		name := d.fieldName(x)
+		previous[name] = true
+
+		y, ok := fields[name]
+		if !ok {
+			d.report(&FieldRemoved{Path: path + "." + name, Field: x})
+			continue
+		}
+
+		d.diffTags(path+"."+name, x.Tags, y.Tags)
+		if !GuardEqual(x.Guard, y.Guard) {
+			d.report(&GuardChanged{Path: path + "." + name, From: x.Guard, To: y.Guard})
+		}
+
+		d.diff(path+"."+name, x.Type, y.Type)
+	}
+
+	for _, y := range b.Fields {
+		name := d.fieldName(y)
+		if !previous[name] {
+			d.report(&FieldAdded{Path: path + "." + name, Field: y})
+		}
+	}
+}
+
+func (d *differ) diffUnions(path string, a, b *UnionLike) {
+	d.diffTags(path, a.Tags, b.Tags)
+	d.diffTypeParams(path, a.TypeParams, b.TypeParams)
+
+	// when more than one variant declares the same name, first one is matched,
+	// and removed variant is not reported as renamed, when its alias is ambiguous,
+	// or declared by variant, that kept its name
+	variants := make(map[string]Shape)
+	aliases := make(map[string]Shape)
+	ambiguous := make(map[string]bool)
+	for _, variant := range b.Variant {
+		if _, ok := variants[VariantDiscriminator(variant)]; !ok {
+			variants[VariantDiscriminator(variant)] = variant
+		}
+		for _, alias := range VariantAliases(variant) {
+			if _, ok := aliases[alias]; ok {
+				ambiguous[alias] = true
+			}
+			aliases[alias] = variant
+		}
+	}
+
+	kept := make(map[string]bool)
+	for _, x := range a.Variant {
+		kept[VariantDiscriminator(x)] = true
+	}
+
+	matched := make(map[Shape]bool)
+	for _, x := range a.Variant {
+		name := VariantDiscriminator(x)
+
+		y, ok := variants[name]
+		if !ok {
+			y, ok = aliases[name]
+			if !ok || ambiguous[name] || kept[VariantDiscriminator(y)] {
+				d.report(&VariantRemoved{Path: path, Variant: x})
+				continue
+			}
+
+			d.report(&VariantRenamed{Path: path, From: x, To: y})
+		}
+
+		matched[y] = true
+		d.diffNamed(path+"."+Name(x), x, y)
+	}
+
+	for _, y := range b.Variant {
+		if !matched[y] {
+			d.report(&VariantAdded{Path: path, Variant: y})
+		}
+	}
+}
+
+func (d *differ) diffTags(path string, a, b map[string]Tag) {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		x, okx := a[name]
+		y, oky := b[name]
+		switch {
+		case !okx:
+			d.report(&TagChanged{Path: path, Name: name, To: &y})
+		case !oky:
+			d.report(&TagChanged{Path: path, Name: name, From: &x})
+		case x.Value != y.Value || !slices.Equal(x.Options, y.Options):
+			d.report(&TagChanged{Path: path, Name: name, From: &x, To: &y})
+		}
+	}
+}
+
+func (d *differ) diffTypeParams(path string, a, b []TypeParam) {
+	if !slices.EqualFunc(a, b, func(x, y TypeParam) bool { return x.Equal(&y) }) {
+		d.report(&TypeParamsChanged{Path: path, From: a, To: b})
+	}
+}
+
+func sameArrayLen(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return *a == *b
+}
+
+func diffPath(x Shape) string {
+	if pkgName := PkgName(x); pkgName != "" {
+		return pkgName + "." + Name(x)
+	}
+
+	return Name(x)
+}
@@ -0,0 +1,317 @@
+package shape
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func inferDiffShape(t *testing.T, body, name string) Shape {
+	t.Helper()
+
+	inferred, err := InferFromFileWithContentBody(body, "github.com/widmogrod/mkunion/x/shape/diff")
+	require.NoError(t, err)
+
+	for _, x := range inferred.RetrieveShapes() {
+		if Name(x) == name {
+			return x
+		}
+	}
+
+	require.Failf(t, "shape not found", "shape %s not found", name)
+	return nil
+}
+
+func TestDiff(t *testing.T) {
+	base := inferDiffShape(t, `package diff
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car struct {
+		Wheels int32
+		Owner  *Person
+	}
+	Boat struct {
+		Name  string `+"`json:\"name\"`"+`
+		Sails uint8
+	}
+	Bike struct{}
+	Ship struct{}
+)
+`, "Vehicle")
+
+	current := inferDiffShape(t, `package diff
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car struct {
+		Wheels int64
+		Owner  *Person
+	}
+	Boat struct {
+		Name string `+"`json:\"title\" required:\"true\"`"+`
+		Crew []string
+	}
+	//go:tag alias:"Ship"
+	Vessel struct{}
+	Plane struct{}
+)
+`, "Vehicle")
+
+	changes := Diff(base, current)
+	assert.Equal(t, []Change{
+		&TypeChanged{
+			Path: "diff.Vehicle.Car.Wheels",
+			From: &PrimitiveLike{Kind: &NumberLike{Kind: &Int32{}}},
+			To:   &PrimitiveLike{Kind: &NumberLike{Kind: &Int64{}}},
+		},
+		&TagChanged{
+			Path: "diff.Vehicle.Boat.Name",
+			Name: "json",
+			From: &Tag{Value: "name"},
+			To:   &Tag{Value: "title"},
+		},
+		&TagChanged{
+			Path: "diff.Vehicle.Boat.Name",
+			Name: "required",
+			To:   &Tag{Value: "true"},
+		},
+		&GuardChanged{
+			Path: "diff.Vehicle.Boat.Name",
+			To:   &Required{},
+		},
+		&FieldRemoved{
+			Path: "diff.Vehicle.Boat.Sails",
+			Field: &FieldLike{
+				Name: "Sails",
+				Type: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt8{}}},
+			},
+		},
+		&FieldAdded{
+			Path: "diff.Vehicle.Boat.Crew",
+			Field: &FieldLike{
+				Name: "Crew",
+				Type: &ListLike{Element: &PrimitiveLike{Kind: &StringLike{}}},
+			},
+		},
+		&VariantRemoved{
+			Path:    "diff.Vehicle",
+			Variant: base.(*UnionLike).Variant[2],
+		},
+		&VariantRenamed{
+			Path: "diff.Vehicle",
+			From: base.(*UnionLike).Variant[3],
+			To:   current.(*UnionLike).Variant[2],
+		},
+		&TagChanged{
+			Path: "diff.Vehicle.Ship",
+			Name: "alias",
+			To:   &Tag{Value: "Ship"},
+		},
+		&VariantAdded{
+			Path:    "diff.Vehicle",
+			Variant: current.(*UnionLike).Variant[3],
+		},
+	}, changes)
+}
+
+func TestDiff_TypeParams(t *testing.T) {
+	base := inferDiffShape(t, `package diff
+
+type Page[T any] struct {
+	Items []T
+	Next  Cursor[T]
+}
+`, "Page")
+
+	current := inferDiffShape(t, `package diff
+
+type Page[T, K any] struct {
+	Items []T
+	Next  Cursor[K]
+}
+`, "Page")
+
+	changes := Diff(base, current)
+	assert.Equal(t, []string{
+		"diff.Page: type parameters changed from [T any] to [T any, K any]",
+		"diff.Page.Next[0]: type changed from T to K",
+	}, changesToStr(changes))
+}
+
+func TestDiff_AmbiguousAlias(t *testing.T) {
+	base := inferDiffShape(t, `package diff
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car  struct{}
+	Ship struct{}
+)
+`, "Vehicle")
+
+	current := inferDiffShape(t, `package diff
+
+//go:tag mkunion:"Vehicle"
+type (
+	//go:tag alias:"Ship"
+	Car struct{}
+	//go:tag alias:"Ship"
+	Vessel struct{}
+	//go:tag alias:"Ship"
+	Boat struct{}
+)
+`, "Vehicle")
+
+	changes := Diff(base, current)
+	assert.Equal(t, []string{
+		"diff.Vehicle.Car: tag alias:\"Ship\" added",
+		"diff.Vehicle: variant diff.Ship removed",
+		"diff.Vehicle: variant diff.Vessel added",
+		"diff.Vehicle: variant diff.Boat added",
+	}, changesToStr(changes))
+}
+
+func TestVariantNames(t *testing.T) {
+	union := inferDiffShape(t, `package diff
+
+//go:tag mkunion:"Vehicle"
+type (
+	//go:tag variant:"vehicle.car" alias:"Auto"
+	Car struct{}
+	//go:tag alias:"vehicle.car"
+	Vessel struct{}
+)
+`, "Vehicle").(*UnionLike)
+
+	_, err := VariantNames(union)
+	assert.EqualError(t, err, `shape.VariantNames: union Vehicle has variants Car and Vessel with the same name "vehicle.car"`)
+
+	union.Variant = union.Variant[:1]
+	names, err := VariantNames(union)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Shape{"vehicle.car": union.Variant[0], "diff.Auto": union.Variant[0]}, names)
+}
+
+func TestDiff_Unchanged(t *testing.T) {
+	x := inferDiffShape(t, `package diff
+
+//go:tag mkunion:"Tree"
+type (
+	Branch struct {
+		L, R Tree
+		Meta map[string]Tree
+	}
+	Leaf struct{ Value [3]byte }
+)
+`, "Tree")
+
+	assert.Empty(t, Diff(x, x))
+}
+
+func TestDiff_SerialisedWithGeneratedSerde(t *testing.T) {
+	base := inferDiffShape(t, `package diff
+
+//go:tag serde:"json"
+type Order[T any] struct {
+	ID    string `+"`json:\"id\"`"+`
+	Items [2]T
+	Note  string
+}
+`, "Order")
+
+	current := inferDiffShape(t, `package diff
+
+//go:tag serde:"json"
+type Order struct {
+	ID    string `+"`json:\"id\" minLength:\"3\"`"+`
+	Items []int
+	Total float64
+}
+`, "Order")
+
+	changes := Diff(base, current)
+	require.Len(t, changes, 6)
+
+	for _, change := range changes {
+		data, err := ChangeToJSON(change)
+		require.NoError(t, err)
+
+		result, err := ChangeFromJSON(data)
+		require.NoError(t, err)
+		assert.True(t, ChangeEqual(change, result), ChangeToStr(change))
+	}
+}
+
+func TestChangeToStr(t *testing.T) {
+	useCases := map[string]struct {
+		in       Change
+		expected string
+	}{
+		"type changed": {
+			in: &TypeChanged{
+				Path: "app.Order.Items",
+				From: &ListLike{Element: &PrimitiveLike{Kind: &StringLike{}}},
+				To:   &MapLike{Key: &PrimitiveLike{Kind: &StringLike{}}, Val: &RefName{Name: "Item", PkgName: "app", PkgImportName: "example.com/app"}},
+			},
+			expected: "app.Order.Items: type changed from []string to map[string]app.Item",
+		},
+		"variant renamed": {
+			in: &VariantRenamed{
+				Path: "app.Vehicle",
+				From: &StructLike{Name: "Ship", PkgName: "app"},
+				To:   &StructLike{Name: "Vessel", PkgName: "app"},
+			},
+			expected: "app.Vehicle: variant app.Ship renamed to app.Vessel",
+		},
+		"field added": {
+			in: &FieldAdded{
+				Path: "app.Order.Total",
+				Field: &FieldLike{
+					Name: "Total",
+					Type: &PrimitiveLike{Kind: &NumberLike{Kind: &Float64{}}},
+					Tags: map[string]Tag{"json": {Value: "total", Options: []string{"omitempty"}}},
+				},
+			},
+			expected: "app.Order.Total: field added, Total float64 `json:\"total,omitempty\"`",
+		},
+		"tag changed": {
+			in: &TagChanged{
+				Path: "app.Order.ID",
+				Name: "json",
+				From: &Tag{Value: "id"},
+				To:   &Tag{Value: "order_id"},
+			},
+			expected: "app.Order.ID: tag changed from json:\"id\" to json:\"order_id\"",
+		},
+		"guard changed": {
+			in: &GuardChanged{
+				Path: "app.Order.ID",
+				From: &Required{},
+				To:   &AndGuard{L: []Guard{&Required{}, &MinLength{Len: 3}}},
+			},
+			expected: "app.Order.ID: guard changed from required to required and minLength(3)",
+		},
+		"type parameters removed": {
+			in: &TypeParamsChanged{
+				Path: "app.Order",
+				From: []TypeParam{{Name: "T", Type: &Any{}}},
+			},
+			expected: "app.Order: type parameters changed from [T any] to none",
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, uc.expected, ChangeToStr(uc.in))
+		})
+	}
+}
+
+func changesToStr(changes []Change) []string {
+	result := make([]string, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, ChangeToStr(change))
+	}
+
+	return result
+}
@@ -521,85 +521,7 @@ func (f *InferredInfo) Visit(n ast.Node) ast.Visitor {
 	opt := f.optionAST()
 	switch t := n.(type) {
 	case *ast.GenDecl:
-		if t.Tok != token.TYPE {
-			return f
-		}
-
-		// detect declaration of union type
-		// either as comment
-		// //go:generate mkunion -name=Example
-		// //go:tag mkunion:"Example"
-		tags := ExtractDocumentTags(t.Doc)
-
-		for tname, tvalue := range tags {
-			f.taggedNodes[tname] = append(f.taggedNodes[tname], &NodeAndTag{
-				Name: tname,
-				Node: t,
-				Tag:  tvalue,
-			})
-		}
-
-		// detect single declaration of type with a comment block
-		// // some comment
-		// type A struct {}
-		if t.Lparen == 0 && t.Rparen == 0 && len(t.Specs) == 1 {
-			switch s := t.Specs[0].(type) {
-			case *ast.TypeSpec:
-				// extract individual tags for each of variant
-				f.possibleTaggedTypes[s.Name.Name] = tags
-				return f
-			}
-		}
-
-		// when there are more than one spec block,
-		// it means that we are dealing with union (by convention)
-
-		// register tags for specific type inside block:
-		// type (
-		//   ...
-		// )
-		for _, spec := range t.Specs {
-			switch s := spec.(type) {
-			case *ast.TypeSpec:
-				// extract individual tags for each of variant
-				f.possibleTaggedTypes[s.Name.Name] = ExtractDocumentTags(s.Doc)
-			}
-		}
-
-		unionName := ""
-		if unionTag, ok := tags["mkunion"]; ok {
-			unionName = removeTypeParams(unionTag.Value)
-		} else {
-			comment := shared.Comment(t.Doc)
-			names := matchGoGenerateExtractUnionName.FindStringSubmatch(comment)
-			if len(names) < 2 {
-				return f
-			}
-			unionName = names[1]
-		}
-
-		// It's impossible to have type Option interface{} and type Option[A] interface{} in same package
-		// Register union tags under the base name (without type params)
-		f.possibleTaggedTypes[unionName] = tags
-
-		// start capturing possible variants
-		if _, ok := f.possibleVariantTypes[unionName]; !ok {
-			f.possibleVariantTypes[unionName] = make([]string, 0)
-		}
-
-		for _, spec := range t.Specs {
-			switch s := spec.(type) {
-			case *ast.TypeSpec:
-				// register possible variant for union
-				// NOTE: this is only convention that unions must be declared as type group specification:
-				// type (
-				// 	Variant2 struct {}
-				//	Variant2 int
-				//)
-				f.possibleVariantTypes[unionName] = append(f.possibleVariantTypes[unionName], s.Name.Name)
-			}
-		}
-
+		f.visitGenDecl(t)
 		return f
 
 	case *ast.File:
@@ -810,17 +732,43 @@ func (f *InferredInfo) Visit(n ast.Node) ast.Visitor {
 			// this happens when field is embedded in struct
 			// something like `type A struct { B }`
 			if len(field.Names) == 0 {
-				switch typ := field.Type.(type) {
-				case *ast.Ident:
-					structShape.Fields = append(structShape.Fields, &FieldLike{
-						Name: typ.Name,
-						Type: FromAST(typ, opt...),
-					})
-					break
-				default:
-					log.Warnf("shape.InferFromFile: unknown ast type embedded in struct: %T\n", typ)
+				name, ok := embeddedFieldName(field.Type)
+				if !ok {
+					log.Warnf("shape.InferFromFile: unknown ast type embedded in struct: %T\n", field.Type)
 					continue
 				}
+
+				var typ Shape
+				switch ttt := field.Type.(type) {
+				case *ast.SelectorExpr:
+					typ = f.selectExrToShape(ttt)
+				case *ast.StarExpr:
+					if selector, ok := ttt.X.(*ast.SelectorExpr); ok {
+						typ = &PointerLike{
+							Type: f.selectExrToShape(selector),
+						}
+					} else {
+						typ = FromAST(ttt, opt...)
+					}
+				default:
+					typ = FromAST(ttt, opt...)
+				}
+
+				tag := ""
+				if field.Tag != nil {
+					tag = field.Tag.Value
+				}
+
+				tags := ExtractTags(tag)
+				structShape.Fields = append(structShape.Fields, &FieldLike{
+					Name:     name,
+					Type:     CleanTypeThatAreOvershadowByTypeParam(typ, structShape.TypeParams),
+					Desc:     TagsToDesc(tags),
+					Guard:    TagsToGuard(tags),
+					Tags:     tags,
+					Embedded: true,
+				})
+				continue
 			}
 
 			for _, fieldName := range field.Names {
@@ -844,7 +792,7 @@ func (f *InferredInfo) Visit(n ast.Node) ast.Visitor {
 						typ = FromAST(ttt, opt...)
 					}
 
-				case *ast.IndexExpr, *ast.Ident, *ast.ArrayType, *ast.MapType, *ast.StructType:
+				case *ast.IndexExpr, *ast.IndexListExpr, *ast.Ident, *ast.ArrayType, *ast.MapType, *ast.StructType:
 					typ = FromAST(ttt, opt...)
 
 				default:
@@ -880,6 +828,107 @@ func (f *InferredInfo) Visit(n ast.Node) ast.Visitor {
 	return f
 }
 
+// visitGenDecl registers tags of declared types, and variants of union declared in type (...) block.
+func (f *InferredInfo) visitGenDecl(t *ast.GenDecl) {
+	if t.Tok != token.TYPE {
+		return
+	}
+
+	// detect declaration of union type
+	// either as comment
+	// //go:generate mkunion -name=Example
+	// //go:tag mkunion:"Example"
+	tags := ExtractDocumentTags(t.Doc)
+
+	for tname, tvalue := range tags {
+		f.taggedNodes[tname] = append(f.taggedNodes[tname], &NodeAndTag{
+			Name: tname,
+			Node: t,
+			Tag:  tvalue,
+		})
+	}
+
+	// detect single declaration of type with a comment block
+	// // some comment
+	// type A struct {}
+	if t.Lparen == 0 && t.Rparen == 0 && len(t.Specs) == 1 {
+		switch s := t.Specs[0].(type) {
+		case *ast.TypeSpec:
+			// extract individual tags for each of variant
+			f.possibleTaggedTypes[s.Name.Name] = tags
+			return
+		}
+	}
+
+	// when there are more than one spec block,
+	// it means that we are dealing with union (by convention)
+
+	// register tags for specific type inside block:
+	// type (
+	//   ...
+	// )
+	for _, spec := range t.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			// extract individual tags for each of variant
+			f.possibleTaggedTypes[s.Name.Name] = ExtractDocumentTags(s.Doc)
+		}
+	}
+
+	unionName := ""
+	if unionTag, ok := tags["mkunion"]; ok {
+		unionName = removeTypeParams(unionTag.Value)
+	} else {
+		comment := shared.Comment(t.Doc)
+		names := matchGoGenerateExtractUnionName.FindStringSubmatch(comment)
+		if len(names) < 2 {
+			return
+		}
+		unionName = names[1]
+	}
+
+	// It's impossible to have type Option interface{} and type Option[A] interface{} in same package
+	// Register union tags under the base name (without type params)
+	f.possibleTaggedTypes[unionName] = tags
+
+	// start capturing possible variants
+	if _, ok := f.possibleVariantTypes[unionName]; !ok {
+		f.possibleVariantTypes[unionName] = make([]string, 0)
+	}
+
+	for _, spec := range t.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			// register possible variant for union
+			// NOTE: this is only convention that unions must be declared as type group specification:
+			// type (
+			// 	Variant2 struct {}
+			//	Variant2 int
+			//)
+			f.possibleVariantTypes[unionName] = append(f.possibleVariantTypes[unionName], s.Name.Name)
+		}
+	}
+}
+
+// embeddedFieldName returns name of embedded field, which is name of its type without package, pointer and type arguments,
+// like B for `B`, `*B`, `pkg.B` or `B[int]`.
+func embeddedFieldName(expr ast.Expr) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name, true
+	case *ast.SelectorExpr:
+		return x.Sel.Name, true
+	case *ast.StarExpr:
+		return embeddedFieldName(x.X)
+	case *ast.IndexExpr:
+		return embeddedFieldName(x.X)
+	case *ast.IndexListExpr:
+		return embeddedFieldName(x.X)
+	}
+
+	return "", false
+}
+
 func CleanTypeThatAreOvershadowByTypeParam(typ Shape, params []TypeParam) Shape {
 	return MatchShapeR1(
 		typ,
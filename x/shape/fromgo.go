@@ -122,11 +122,12 @@ func FromGoReflect(x reflect.Type, infiniteRecursionFix map[string]Shape) Shape
 			guard = TagsToGuard(tags)
 
 			fields = append(fields, &FieldLike{
-				Name:  field.Name,
-				Type:  FromGoReflect(field.Type, infiniteRecursionFix),
-				Desc:  desc,
-				Guard: guard,
-				Tags:  tags,
+				Name:     field.Name,
+				Type:     FromGoReflect(field.Type, infiniteRecursionFix),
+				Desc:     desc,
+				Guard:    guard,
+				Tags:     tags,
+				Embedded: field.Anonymous,
 			})
 		}
 
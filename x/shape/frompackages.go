@@ -0,0 +1,364 @@
+package shape
+
+import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/packages"
+	"path/filepath"
+	"strings"
+)
+
+// InferFromPackages loads packages that match patterns with golang.org/x/tools/go/packages,
+// and infers shapes declared in each of their files, the same way as InferFromFile does.
+// Unlike InferFromFile, types are resolved with go/types, the way compiler does:
+// imported types have package name and import path of package that declares them, also when it's vendored or replaced,
+// unexported type aliases, that can't be referenced, are resolved to types that they stand for,
+// and files excluded by build constraints are skipped.
+// Type, that can't be resolved, because i.e. it's declared in file that is not generated yet, is inferred from AST.
+//
+// Directories of imported packages are registered with RegisterPackageDir,
+// so that LookupShapeOnDisk finds shapes declared in them.
+//
+// Dir, Env, BuildFlags and Tests of cfg are passed to go/packages, and cfg can be nil.
+func InferFromPackages(cfg *packages.Config, patterns ...string) ([]*InferredInfo, error) {
+	load := &packages.Config{}
+	if cfg != nil {
+		load.Dir = cfg.Dir
+		load.Env = cfg.Env
+		load.BuildFlags = cfg.BuildFlags
+		load.Tests = cfg.Tests
+	}
+
+	// dependencies are type checked from source, and not read from export data,
+	// which format depends on version of go toolchain
+	load.Mode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+		packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+	pkgs, err := packages.Load(load, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("shape.InferFromPackages: %w", err)
+	}
+
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if len(pkg.GoFiles) > 0 {
+			RegisterPackageDir(pkg.PkgPath, filepath.Dir(pkg.GoFiles[0]))
+		}
+	})
+
+	var result []*InferredInfo
+	inferred := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			// code that is not generated yet, is expected to have type errors
+			log.Debugf("shape.InferFromPackages: %s: %s", pkg.ID, e)
+		}
+
+		if pkg.Types == nil || pkg.TypesInfo == nil {
+			continue
+		}
+
+		goFiles := make(map[string]bool, len(pkg.GoFiles))
+		for _, fileName := range pkg.GoFiles {
+			goFiles[fileName] = true
+		}
+
+		for _, file := range pkg.Syntax {
+			fileName := pkg.Fset.File(file.Pos()).Name()
+			// skip files generated by cgo, and files that are part of other variant of the same package, like test variant
+			if !goFiles[fileName] || inferred[fileName] {
+				continue
+			}
+			inferred[fileName] = true
+
+			result = append(result, inferFromTypedFile(pkg, file, fileName))
+		}
+	}
+
+	return result, nil
+}
+
+func inferFromTypedFile(pkg *packages.Package, file *ast.File, fileName string) *InferredInfo {
+	pkgImportName := pkg.PkgPath
+	if strings.HasSuffix(pkg.Name, "_test") {
+		// InferFromFile names external test package by directory, and not by package clause
+		pkgImportName = strings.TrimSuffix(pkgImportName, "_test")
+	}
+
+	f := &InferredInfo{
+		fileName:             fileName,
+		pkgName:              pkg.Name,
+		pkgImportName:        pkgImportName,
+		possibleVariantTypes: map[string][]string{},
+		possibleTaggedTypes:  map[string]map[string]Tag{},
+		shapes:               make(map[string]Shape),
+		taggedNodes:          make(map[string][]*NodeAndTag),
+		dotImports:           []string{},
+		packageNameToPackageImport: map[string]string{
+			pkg.Name: pkgImportName,
+		},
+	}
+
+	for _, imp := range file.Imports {
+		name := pkg.TypesInfo.PkgNameOf(imp)
+		if name == nil {
+			continue
+		}
+
+		if imp.Name != nil && imp.Name.Name == "." {
+			f.dotImports = append(f.dotImports, name.Imported().Path())
+			continue
+		}
+
+		f.packageNameToPackageImport[name.Name()] = name.Imported().Path()
+	}
+
+	typed := &typedFile{info: f, types: pkg.TypesInfo}
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		f.visitGenDecl(genDecl)
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+
+			f.currentType = typeSpec.Name.Name
+			if x := typed.typeSpec(typeSpec); x != nil {
+				f.shapes[typeSpec.Name.Name] = x
+			}
+		}
+	}
+
+	return f
+}
+
+// typedFile infers shapes from declarations in file, with structure of declarations taken from AST,
+// and types of expressions resolved with go/types.
+type typedFile struct {
+	info  *InferredInfo
+	types *types.Info
+}
+
+func (t *typedFile) typeSpec(spec *ast.TypeSpec) Shape {
+	f := t.info
+	name := spec.Name.Name
+
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		result := &StructLike{
+			Name:          name,
+			PkgName:       f.pkgName,
+			PkgImportName: f.pkgImportName,
+			TypeParams:    f.extractTypeParams(spec.TypeParams),
+			Tags:          f.possibleTaggedTypes[name],
+		}
+
+		for _, field := range typ.Fields.List {
+			result.Fields = append(result.Fields, t.fields(field)...)
+		}
+
+		return result
+
+	case *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		// interfaces are unions, when they have variants
+		return nil
+	}
+
+	return &AliasLike{
+		Name:          name,
+		PkgName:       f.pkgName,
+		PkgImportName: f.pkgImportName,
+		TypeParams:    f.extractTypeParams(spec.TypeParams),
+		IsAlias:       IsASTAlias(spec),
+		Type:          t.expr(spec.Type),
+		Tags:          f.possibleTaggedTypes[name],
+	}
+}
+
+func (t *typedFile) fields(field *ast.Field) []*FieldLike {
+	tag := ""
+	if field.Tag != nil {
+		tag = field.Tag.Value
+	}
+
+	typ := t.expr(field.Type)
+	tags := ExtractTags(tag)
+
+	// embedded field is named after its type, like `B`, `*B`, `pkg.B` or `B[int]`
+	if len(field.Names) == 0 {
+		name := ""
+		switch x := t.unpointer(field.Type).(type) {
+		case *types.Named:
+			name = x.Obj().Name()
+		case *types.Alias:
+			name = x.Obj().Name()
+		default:
+			log.Warnf("shape.InferFromPackages: unknown type embedded in struct %s: %s", t.info.currentType, types.ExprString(field.Type))
+			return nil
+		}
+
+		return []*FieldLike{{
+			Name:     name,
+			Type:     typ,
+			Desc:     TagsToDesc(tags),
+			Guard:    TagsToGuard(tags),
+			Tags:     tags,
+			Embedded: true,
+		}}
+	}
+
+	var result []*FieldLike
+	for _, fieldName := range field.Names {
+		if !fieldName.IsExported() {
+			continue
+		}
+
+		result = append(result, &FieldLike{
+			Name:  fieldName.Name,
+			Type:  typ,
+			Desc:  TagsToDesc(tags),
+			Guard: TagsToGuard(tags),
+			Tags:  tags,
+		})
+	}
+
+	return result
+}
+
+// unpointer returns type of expression, without pointer, and without resolving alias,
+// because embedded field is named after alias, and not after type it stands for.
+func (t *typedFile) unpointer(expr ast.Expr) types.Type {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	typ := t.types.TypeOf(expr)
+	if ptr, ok := typ.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+
+	return typ
+}
+
+// expr returns shape of type expression, and falls back to AST, when go/types could not resolve it.
+func (t *typedFile) expr(expr ast.Expr) Shape {
+	typ := t.types.TypeOf(expr)
+	if typ != nil {
+		if result, ok := fromGoType(typ); ok {
+			return result
+		}
+	}
+
+	log.Debugf("shape.InferFromPackages: could not resolve type %s in %s, inferring it from AST", types.ExprString(expr), t.info.currentType)
+	switch x := expr.(type) {
+	case *ast.SelectorExpr:
+		return t.info.selectExrToShape(x)
+	case *ast.StarExpr:
+		if selector, ok := x.X.(*ast.SelectorExpr); ok {
+			return &PointerLike{Type: t.info.selectExrToShape(selector)}
+		}
+	}
+
+	return FromAST(expr, t.info.optionAST()...)
+}
+
+// FromGoType returns shape of type resolved by go/types.
+// Named types and exported aliases are returned as references, types that can't be represented as shape are returned as Any.
+func FromGoType(x types.Type) Shape {
+	result, _ := fromGoType(x)
+	return result
+}
+
+// fromGoType returns false, when type, or one of its parts, is invalid, because type checker could not resolve it.
+func fromGoType(x types.Type) (Shape, bool) {
+	// exported alias is referenced by name, like in InferFromFile, because its declaration has shape,
+	// other aliases are resolved to types that they stand for
+	if alias, ok := x.(*types.Alias); ok && isDeclaredInPackage(alias.Obj()) {
+		return refNameFromGoType(alias.Obj(), alias.TypeArgs())
+	}
+
+	switch y := types.Unalias(x).(type) {
+	case *types.Basic:
+		if y.Kind() == types.Invalid {
+			return &Any{}, false
+		}
+
+		if primitive := NameToPrimitiveShape(y.Name()); primitive != nil {
+			return primitive, true
+		}
+
+		return &Any{}, true
+
+	case *types.Named:
+		if !isDeclaredInPackage(y.Obj()) {
+			return &Any{}, true
+		}
+
+		return refNameFromGoType(y.Obj(), y.TypeArgs())
+
+	case *types.TypeParam:
+		return &RefName{
+			Name: y.Obj().Name(),
+		}, true
+
+	case *types.Pointer:
+		elem, ok := fromGoType(y.Elem())
+		return &PointerLike{
+			Type: elem,
+		}, ok
+
+	case *types.Slice:
+		elem, ok := fromGoType(y.Elem())
+		return &ListLike{
+			Element: elem,
+		}, ok
+
+	case *types.Array:
+		elem, ok := fromGoType(y.Elem())
+		arrayLen := int(y.Len())
+		return &ListLike{
+			Element:  elem,
+			ArrayLen: &arrayLen,
+		}, ok
+
+	case *types.Map:
+		key, keyOk := fromGoType(y.Key())
+		val, valOk := fromGoType(y.Elem())
+		return &MapLike{
+			Key: key,
+			Val: val,
+		}, keyOk && valOk
+	}
+
+	// interfaces, anonymous structs, functions and channels
+	return &Any{}, true
+}
+
+// isDeclaredInPackage reports whether type is exported from package scope, and can be referenced by other packages.
+func isDeclaredInPackage(obj *types.TypeName) bool {
+	return obj.Exported() && obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope()
+}
+
+func refNameFromGoType(obj *types.TypeName, args *types.TypeList) (Shape, bool) {
+	result := &RefName{
+		Name:          obj.Name(),
+		PkgName:       obj.Pkg().Name(),
+		PkgImportName: obj.Pkg().Path(),
+	}
+
+	for i := 0; i < args.Len(); i++ {
+		arg, ok := fromGoType(args.At(i))
+		if !ok {
+			return result, false
+		}
+		result.Indexed = append(result.Indexed, arg)
+	}
+
+	return result, true
+}
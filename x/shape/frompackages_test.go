@@ -0,0 +1,167 @@
+package shape
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInferFromPackages(t *testing.T) {
+	tempDir := t.TempDir()
+	libDir := filepath.Join(tempDir, "lib")
+	appDir := filepath.Join(tempDir, "app")
+	require.NoError(t, os.Mkdir(libDir, 0755))
+	require.NoError(t, os.Mkdir(appDir, 0755))
+
+	writeFile := func(name, contents string) {
+		require.NoError(t, os.WriteFile(name, []byte(contents), 0644))
+	}
+
+	// lib is replaced module, that can't be found by import path in module cache
+	writeFile(filepath.Join(libDir, "go.mod"), "module example.com/lib\n\ngo 1.21\n")
+	writeFile(filepath.Join(libDir, "lib.go"), `package lib
+
+type Money struct{ Amount int }
+
+type Base struct{ ID string }
+`)
+
+	writeFile(filepath.Join(appDir, "go.mod"), `module example.com/app
+
+go 1.21
+
+require example.com/lib v0.0.0
+
+replace example.com/lib => ../lib
+`)
+	writeFile(filepath.Join(appDir, "app.go"), `package app
+
+import money "example.com/lib"
+
+type Price = money.Money
+
+type price = money.Money
+
+//go:tag serde:"json"
+type Order struct {
+	money.Base
+	Total Price
+	Tax   price
+	Items []money.Money `+"`json:\"items\"`"+`
+	notes string
+}
+
+//go:tag mkunion:"Payment"
+type (
+	Card struct{ Number string }
+	Cash struct{ Amount money.Money }
+)
+`)
+	writeFile(filepath.Join(appDir, "app_other.go"), `//go:build other
+
+package app
+
+type Hidden struct{}
+`)
+
+	inferred, err := InferFromPackages(&packages.Config{
+		Dir: appDir,
+		Env: append(os.Environ(), "GOWORK=off", "GOFLAGS="),
+	}, ".")
+	require.NoError(t, err)
+	require.Len(t, inferred, 1, "file excluded by build constraint is not inferred")
+
+	info := inferred[0]
+	assert.Equal(t, filepath.Join(appDir, "app.go"), info.FileName())
+	assert.Equal(t, "app", info.PackageName())
+	assert.Equal(t, "example.com/app", info.PackageImportName())
+
+	money := &RefName{
+		Name:          "Money",
+		PkgName:       "lib",
+		PkgImportName: "example.com/lib",
+	}
+
+	t.Run("resolves imported, embedded and aliased types", func(t *testing.T) {
+		order := info.RetrieveStruct("Order")
+		require.NotNil(t, order)
+		assert.Equal(t, &StructLike{
+			Name:          "Order",
+			PkgName:       "app",
+			PkgImportName: "example.com/app",
+			Fields: []*FieldLike{
+				{
+					Name: "Base",
+					Type: &RefName{
+						Name:          "Base",
+						PkgName:       "lib",
+						PkgImportName: "example.com/lib",
+					},
+					Embedded: true,
+				},
+				{
+					Name: "Total",
+					Type: &RefName{
+						Name:          "Price",
+						PkgName:       "app",
+						PkgImportName: "example.com/app",
+					},
+				},
+				{
+					Name: "Tax",
+					Type: money,
+				},
+				{
+					Name: "Items",
+					Type: &ListLike{Element: money},
+					Tags: map[string]Tag{
+						"json": {Value: "items"},
+					},
+				},
+			},
+			Tags: map[string]Tag{
+				"serde": {Value: "json"},
+			},
+		}, order)
+	})
+
+	t.Run("infers unions", func(t *testing.T) {
+		union := info.RetrieveUnion("Payment")
+		require.NotNil(t, union)
+		require.Len(t, union.Variant, 2)
+		assert.Equal(t, "Card", Name(union.Variant[0]))
+		assert.Equal(t, "Cash", Name(union.Variant[1]))
+		assert.Equal(t, money, union.Variant[1].(*StructLike).Fields[0].Type)
+	})
+
+	t.Run("registers directories of imported packages", func(t *testing.T) {
+		dir, err := findPackagePath("example.com/lib")
+		require.NoError(t, err)
+		assert.Equal(t, libDir, dir)
+
+		found, ok := LookupShapeOnDisk(money)
+		require.True(t, ok)
+		assert.Equal(t, "Money", Name(found))
+	})
+}
+
+func TestInferFromPackages_SameAsInferFromFile(t *testing.T) {
+	fromFile, err := InferFromFile("testasset/type_example.go")
+	require.NoError(t, err)
+
+	inferred, err := InferFromPackages(nil, "./testasset")
+	require.NoError(t, err)
+
+	var fromPackages *InferredInfo
+	for _, x := range inferred {
+		if x.FileName() == fromFile.FileName() {
+			fromPackages = x
+		}
+	}
+	require.NotNil(t, fromPackages)
+
+	assert.Equal(t, fromFile.RetrieveUnion("Example"), fromPackages.RetrieveUnion("Example"))
+}
@@ -0,0 +1,198 @@
+package shape
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sync"
+	"unicode/utf8"
+)
+
+// GuardError describes value that doesn't satisfy guard declared in tags.
+type GuardError struct {
+	Guard  Guard
+	Reason string
+}
+
+func (e *GuardError) Error() string {
+	return e.Reason
+}
+
+var guardRegexpCache = sync.Map{}
+
+func guardRegexp(pattern string) (*regexp.Regexp, error) {
+	if v, ok := guardRegexpCache.Load(pattern); ok {
+		return v.(*regexp.Regexp), nil
+	}
+
+	result, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	guardRegexpCache.Store(pattern, result)
+	return result, nil
+}
+
+// ValidateGuard checks if value of a field satisfies guard.
+// Pointers are dereferenced, and nil pointer satisfies every guard except Required.
+// Required is satisfied by non nil pointer or union, even when it holds zero value, like variant without fields,
+// and otherwise is not satisfied by zero value, since after decoding, absent field and zero value look the same.
+// Returned error is *GuardError, or nil when value is valid.
+func ValidateGuard(guard Guard, value any) error {
+	if guard == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	reference := v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface)
+	missing := !v.IsValid() || (reference && v.IsNil())
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+
+	absent := !v.IsValid() || ((v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil())
+
+	return MatchGuardR1(
+		guard,
+		func(x *Enum) error {
+			if absent {
+				return nil
+			}
+
+			switch v.Kind() {
+			case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Func, reflect.Chan:
+				return guardErrorf(x, "enum cannot be applied to %s", v.Type())
+			}
+
+			given := fmt.Sprint(v.Interface())
+			if !slices.Contains(x.Val, given) {
+				return guardErrorf(x, "value %q is not one of %q", given, x.Val)
+			}
+			return nil
+		},
+		func(x *Required) error {
+			if missing || (!reference && v.IsZero()) {
+				return guardErrorf(x, "value is required")
+			}
+			return nil
+		},
+		func(x *Regexp) error {
+			if absent {
+				return nil
+			}
+			if v.Kind() != reflect.String {
+				return guardErrorf(x, "regexp cannot be applied to %s", v.Type())
+			}
+
+			re, err := guardRegexp(x.Regexp)
+			if err != nil {
+				return guardErrorf(x, "regexp %q is invalid; %s", x.Regexp, err)
+			}
+			if !re.MatchString(v.String()) {
+				return guardErrorf(x, "value %q does not match %q", v.String(), x.Regexp)
+			}
+			return nil
+		},
+		func(x *Between) error {
+			if absent {
+				return nil
+			}
+
+			var given float64
+			switch {
+			case v.CanInt():
+				given = float64(v.Int())
+			case v.CanUint():
+				given = float64(v.Uint())
+			case v.CanFloat():
+				given = v.Float()
+			default:
+				return guardErrorf(x, "between cannot be applied to %s", v.Type())
+			}
+
+			if given < x.Min || given > x.Max {
+				return guardErrorf(x, "value %v is not between %v and %v", given, x.Min, x.Max)
+			}
+			return nil
+		},
+		func(x *MinLength) error {
+			if absent {
+				return nil
+			}
+			if v.Kind() != reflect.String {
+				return guardErrorf(x, "minLength cannot be applied to %s", v.Type())
+			}
+			if n := utf8.RuneCountInString(v.String()); n < x.Len {
+				return guardErrorf(x, "length %d is less than %d", n, x.Len)
+			}
+			return nil
+		},
+		func(x *MaxLength) error {
+			if absent {
+				return nil
+			}
+			if v.Kind() != reflect.String {
+				return guardErrorf(x, "maxLength cannot be applied to %s", v.Type())
+			}
+			if n := utf8.RuneCountInString(v.String()); n > x.Len {
+				return guardErrorf(x, "length %d is greater than %d", n, x.Len)
+			}
+			return nil
+		},
+		func(x *MinItems) error {
+			if absent {
+				return nil
+			}
+			n, ok := guardItems(v)
+			if !ok {
+				return guardErrorf(x, "minItems cannot be applied to %s", v.Type())
+			}
+			if n < x.Len {
+				return guardErrorf(x, "number of items %d is less than %d", n, x.Len)
+			}
+			return nil
+		},
+		func(x *MaxItems) error {
+			if absent {
+				return nil
+			}
+			n, ok := guardItems(v)
+			if !ok {
+				return guardErrorf(x, "maxItems cannot be applied to %s", v.Type())
+			}
+			if n > x.Len {
+				return guardErrorf(x, "number of items %d is greater than %d", n, x.Len)
+			}
+			return nil
+		},
+		func(x *AndGuard) error {
+			for _, guard := range x.L {
+				if err := ValidateGuard(guard, value); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+func guardItems(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	}
+
+	return 0, false
+}
+
+func guardErrorf(guard Guard, format string, args ...any) *GuardError {
+	return &GuardError{
+		Guard:  guard,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
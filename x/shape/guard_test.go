@@ -0,0 +1,66 @@
+package shape
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateGuard(t *testing.T) {
+	name := "mkunion"
+	empty := ""
+
+	tests := []struct {
+		name   string
+		guard  Guard
+		value  any
+		reason string
+	}{
+		{name: "nil guard", guard: nil, value: 1},
+		{name: "required string", guard: &Required{}, value: "x"},
+		{name: "required zero string", guard: &Required{}, value: "", reason: "value is required"},
+		{name: "required nil pointer", guard: &Required{}, value: (*string)(nil), reason: "value is required"},
+		{name: "required empty slice", guard: &Required{}, value: []int(nil), reason: "value is required"},
+		{name: "required pointer to zero string", guard: &Required{}, value: &empty},
+		{name: "required variant without fields", guard: &Required{}, value: Guard(&Required{})},
+		{name: "required nil union", guard: &Required{}, value: Guard(nil), reason: "value is required"},
+		{name: "enum", guard: &Enum{Val: []string{"c", "f"}}, value: "c"},
+		{name: "enum number", guard: &Enum{Val: []string{"1", "2"}}, value: int8(2)},
+		{name: "enum not matching", guard: &Enum{Val: []string{"c", "f"}}, value: "k", reason: `value "k" is not one of ["c" "f"]`},
+		{name: "enum nil pointer", guard: &Enum{Val: []string{"c"}}, value: (*string)(nil)},
+		{name: "regexp", guard: &Regexp{Regexp: "^[a-z]+$"}, value: &name},
+		{name: "regexp not matching", guard: &Regexp{Regexp: "^[0-9]+$"}, value: name, reason: `value "mkunion" does not match "^[0-9]+$"`},
+		{name: "regexp on number", guard: &Regexp{Regexp: "^1$"}, value: 1, reason: "regexp cannot be applied to int"},
+		{name: "between", guard: &Between{Min: 1, Max: 10}, value: uint8(10)},
+		{name: "between float", guard: &Between{Min: 0, Max: 1}, value: 1.5, reason: "value 1.5 is not between 0 and 1"},
+		{name: "min length counts runes", guard: &MinLength{Len: 2}, value: "ąę"},
+		{name: "min length", guard: &MinLength{Len: 1}, value: &empty, reason: "length 0 is less than 1"},
+		{name: "max length", guard: &MaxLength{Len: 3}, value: name, reason: "length 7 is greater than 3"},
+		{name: "min items", guard: &MinItems{Len: 1}, value: map[string]int{}, reason: "number of items 0 is less than 1"},
+		{name: "max items", guard: &MaxItems{Len: 2}, value: [3]int{}, reason: "number of items 3 is greater than 2"},
+		{name: "max items on string", guard: &MaxItems{Len: 2}, value: "ab", reason: "maxItems cannot be applied to string"},
+		{
+			name: "and guard reports first violation",
+			guard: &AndGuard{L: []Guard{
+				&Required{},
+				&MinLength{Len: 10},
+				&Regexp{Regexp: "^[0-9]+$"},
+			}},
+			value:  name,
+			reason: "length 7 is less than 10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGuard(tt.guard, tt.value)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.IsType(t, &GuardError{}, err) {
+				assert.Equal(t, tt.reason, err.Error())
+			}
+		})
+	}
+}
@@ -59,6 +59,19 @@ func LookupShape(x *RefName) (Shape, bool) {
 
 var onDiskCache = sync.Map{}
 
+// StoreShapeOnDisk adds shape, and variants of union, to cache used by LookupShapeOnDisk.
+// It's suited for generators, that already inferred shapes of package, or restored them from own cache,
+// so that package files are not scanned again.
+func StoreShapeOnDisk(x Shape) {
+	onDiskCache.Store(shapeFullName(x), x)
+	switch y := x.(type) {
+	case *UnionLike:
+		for _, v := range y.Variant {
+			onDiskCache.Store(shapeFullName(v), v)
+		}
+	}
+}
+
 // LookupShapeOnDisk scans filesystem for shapes.
 // it's suited for generators, that parse AST
 func LookupShapeOnDisk(x *RefName) (Shape, bool) {
@@ -99,13 +112,7 @@ func LookupShapeOnDisk(x *RefName) (Shape, bool) {
 			}
 
 			for _, y := range inferred.RetrieveShapes() {
-				onDiskCache.Store(shapeFullName(y), y)
-				switch z := y.(type) {
-				case *UnionLike:
-					for _, v := range z.Variant {
-						onDiskCache.Store(shapeFullName(v), v)
-					}
-				}
+				StoreShapeOnDisk(y)
 			}
 
 			// continue scanning
@@ -163,13 +170,7 @@ func LookupPkgShapeOnDisk(pkgImportName string) []Shape {
 
 			for _, y := range inferred.RetrieveShapes() {
 				result = append(result, y)
-				onDiskCache.Store(shapeFullName(y), y)
-				switch z := y.(type) {
-				case *UnionLike:
-					for _, v := range z.Variant {
-						onDiskCache.Store(shapeFullName(v), v)
-					}
-				}
+				StoreShapeOnDisk(y)
 			}
 
 			// continue scanning
@@ -184,11 +185,24 @@ func LookupPkgShapeOnDisk(pkgImportName string) []Shape {
 	return result
 }
 
+var packageDirs = sync.Map{}
+
+// RegisterPackageDir sets directory of package, that LookupShapeOnDisk and LookupPkgShapeOnDisk scan for shapes.
+// It's suited for packages, that can't be found from go.mod, like vendored packages or replaced modules,
+// and is used by InferFromPackages, that knows location of all imported packages.
+func RegisterPackageDir(pkgImportName, dir string) {
+	packageDirs.Store(pkgImportName, dir)
+}
+
 func findPackagePath(pkgImportName string) (string, error) {
 	if strings.Trim(pkgImportName, " ") == "" {
 		return "", fmt.Errorf("shape.findPackagePath: empty package name")
 	}
 
+	if dir, ok := packageDirs.Load(pkgImportName); ok {
+		return dir.(string), nil
+	}
+
 	// optimisation: assumption that packages like fmt, context, etc
 	// are standard library packages
 	if len(strings.Split(pkgImportName, "/")) == 1 {
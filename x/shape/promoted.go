@@ -0,0 +1,204 @@
+package shape
+
+import (
+	log "github.com/sirupsen/logrus"
+	"go/token"
+	"sort"
+)
+
+// StructField is field of struct, as it's seen by encoding/json,
+// either declared in struct, or promoted from embedded struct.
+type StructField struct {
+	*FieldLike
+	// Embedded are embedded fields, from the outermost, through which field is promoted.
+	// It's empty for field declared in struct.
+	Embedded []*FieldLike
+}
+
+// Path returns names of fields, that select field in Go, like [Base ID], for field ID promoted from embedded field Base.
+func (f *StructField) Path() []string {
+	result := make([]string, 0, len(f.Embedded)+1)
+	for _, embedded := range f.Embedded {
+		result = append(result, embedded.Name)
+	}
+
+	return append(result, f.Name)
+}
+
+// JSONName returns name of field in JSON, taken from json tag, or Go name of field.
+func (f *StructField) JSONName() string {
+	return TagGetValue(f.Tags, "json", f.Name)
+}
+
+// OmitEmpty reports whether field has `json:",omitempty"` option,
+// and is omitted when it's false, 0, nil, or empty string, slice or map.
+func (f *StructField) OmitEmpty() bool {
+	return TagHasOption(f.Tags, "json", "omitempty")
+}
+
+// OmitZero reports whether field has `json:",omitzero"` option,
+// and is omitted when it has zero value, or when its IsZero() method returns true.
+func (f *StructField) OmitZero() bool {
+	return TagHasOption(f.Tags, "json", "omitzero")
+}
+
+// Quoted reports whether field has `json:",string"` option,
+// and its value is encoded inside JSON string, like "1" for number 1.
+// Like in encoding/json, option applies only to fields of string, number and boolean types.
+func (f *StructField) Quoted() bool {
+	return TagHasOption(f.Tags, "json", "string")
+}
+
+// MayBeOmitted reports whether field can be absent in JSON, when it's set,
+// because it's promoted through embedded pointer, or has omitempty or omitzero option.
+// Nil pointers are omitted too, see IsPointer.
+func (f *StructField) MayBeOmitted() bool {
+	return f.EmbeddedByPointer() || f.OmitEmpty() || f.OmitZero()
+}
+
+// EmbeddedByPointer reports whether field is promoted through embedded pointer,
+// and is absent in JSON, when that pointer is nil.
+func (f *StructField) EmbeddedByPointer() bool {
+	for _, embedded := range f.Embedded {
+		if IsPointer(embedded.Type) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StructFields returns fields of struct, with fields of embedded structs promoted, the way encoding/json does it:
+//   - embedded struct, or pointer to struct, without name in json tag, is replaced by its fields,
+//     and so is struct field with `json:",inline"` option,
+//   - field with json tag "-" is skipped, and so is embedded field of unexported type, that is not struct,
+//   - when fields have the same JSON name, field that is embedded less deeply wins,
+//     then field with name in json tag, and when it's still ambiguous, none of them is returned.
+//
+// Fields are returned in order of declaration, with promoted fields in place of embedded field.
+// Embedded types are found with lookup, and when lookup can't find one, embedded field is returned as any other field.
+// When struct doesn't embed anything, result has the same fields as x.Fields.
+func StructFields(x *StructLike, lookup func(*RefName) (Shape, bool)) []*StructField {
+	visited := map[string]bool{ToGoTypeName(x, WithPkgImportName(), WithInstantiation()): true}
+	candidates := collectStructFields(x, nil, visited, lookup, nil)
+
+	byName := make(map[string][]*structFieldCandidate)
+	for _, candidate := range candidates {
+		name := candidate.field.JSONName()
+		byName[name] = append(byName[name], candidate)
+	}
+
+	var result []*StructField
+	for _, candidate := range candidates {
+		if dominantStructField(byName[candidate.field.JSONName()]) == candidate {
+			result = append(result, candidate.field)
+		}
+	}
+
+	return result
+}
+
+type structFieldCandidate struct {
+	field  *StructField
+	tagged bool
+}
+
+func collectStructFields(x *StructLike, embedded []*FieldLike, visited map[string]bool, lookup func(*RefName) (Shape, bool), result []*structFieldCandidate) []*structFieldCandidate {
+	for _, field := range x.Fields {
+		if IsJSONSkipped(field.Tags) {
+			continue
+		}
+
+		name := TagGetValue(field.Tags, "json", "")
+		if field.Embedded && name == "" || TagHasOption(field.Tags, "json", "inline") {
+			if promoted, ok := embeddedStruct(field.Type, lookup); ok {
+				key := ToGoTypeName(promoted, WithPkgImportName(), WithInstantiation())
+				if visited[key] {
+					// encoding/json stops at type that is already embedded, to not loop forever
+					continue
+				}
+
+				visited[key] = true
+				result = collectStructFields(promoted, append(embedded[:len(embedded):len(embedded)], field), visited, lookup, result)
+				delete(visited, key)
+				continue
+			}
+
+			if field.Embedded && !token.IsExported(field.Name) {
+				continue
+			}
+		}
+
+		result = append(result, &structFieldCandidate{
+			field: &StructField{
+				FieldLike: field,
+				Embedded:  embedded,
+			},
+			tagged: name != "",
+		})
+	}
+
+	return result
+}
+
+// IsJSONSkipped reports whether field is skipped by encoding/json, because it has tag `json:"-"`.
+// Field with tag `json:"-,"` is not skipped, and it's named "-".
+func IsJSONSkipped(tags map[string]Tag) bool {
+	tag, ok := tags["json"]
+	return ok && tag.Value == "-" && len(tag.Options) == 0
+}
+
+// embeddedStruct returns struct, that embedded field of type x stands for,
+// following pointer, references and named types that are declared as other types, like `type B A`.
+func embeddedStruct(x Shape, lookup func(*RefName) (Shape, bool)) (*StructLike, bool) {
+	if ptr, ok := x.(*PointerLike); ok {
+		x = ptr.Type
+	}
+
+	// limit depth, to not loop forever on invalid declarations, like `type A B; type B A`
+	for i := 0; i < 10; i++ {
+		switch y := x.(type) {
+		case *StructLike:
+			return y, true
+
+		case *AliasLike:
+			x = y.Type
+
+		case *RefName:
+			found, ok := lookup(y)
+			if !ok {
+				log.Debugf("shape.StructFields: embedded type %s not found, it's not promoted", ToGoTypeName(y, WithPkgImportName()))
+				return nil, false
+			}
+
+			x = IndexWith(found, y)
+
+		default:
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+func dominantStructField(candidates []*structFieldCandidate) *structFieldCandidate {
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	sorted := append([]*structFieldCandidate(nil), candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if len(sorted[i].field.Embedded) != len(sorted[j].field.Embedded) {
+			return len(sorted[i].field.Embedded) < len(sorted[j].field.Embedded)
+		}
+
+		return sorted[i].tagged && !sorted[j].tagged
+	})
+
+	first, second := sorted[0], sorted[1]
+	if len(first.field.Embedded) == len(second.field.Embedded) && first.tagged == second.tagged {
+		return nil
+	}
+
+	return first
+}
@@ -0,0 +1,134 @@
+package shape
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructFields(t *testing.T) {
+	inferred, err := InferFromFileWithContentBody(`package promoted
+
+import "example.com/other"
+
+type Base struct {
+	ID      string
+	Version int `+"`json:\"version\"`"+`
+}
+
+type Audit struct {
+	ID      string
+	Created int64
+	Version int
+}
+
+type Named Base
+
+type (
+	Flat struct {
+		Base
+		Name string
+	}
+	Pointer struct {
+		*Base
+	}
+	Shadowed struct {
+		Base
+		ID string
+	}
+	Ambiguous struct {
+		Base
+		Audit
+	}
+	Tagged struct {
+		Base `+"`json:\"base\"`"+`
+		Skipped string `+"`json:\"-\"`"+`
+	}
+	Unknown struct {
+		other.Base
+	}
+	Declared struct {
+		Named
+	}
+	Cycle struct {
+		*Cycle
+		Name string
+	}
+)
+`, "example.com/promoted")
+	require.NoError(t, err)
+
+	shapes := make(map[string]Shape)
+	for _, x := range inferred.RetrieveShapes() {
+		shapes[Name(x)] = x
+	}
+
+	lookup := func(x *RefName) (Shape, bool) {
+		if x.PkgImportName != "example.com/promoted" {
+			return nil, false
+		}
+		result, ok := shapes[x.Name]
+		return result, ok
+	}
+
+	fields := func(name string) []string {
+		var result []string
+		for _, field := range StructFields(shapes[name].(*StructLike), lookup) {
+			result = append(result, field.JSONName()+"="+ToGoTypeName(field.Type)+"@"+strings.Join(field.Path(), "."))
+		}
+		return result
+	}
+
+	t.Run("embedded field has name of its type", func(t *testing.T) {
+		field := shapes["Unknown"].(*StructLike).Fields[0]
+		assert.Equal(t, "Base", field.Name)
+		assert.True(t, field.Embedded)
+		assert.Equal(t, "other.Base", ToGoTypeName(field.Type))
+	})
+
+	t.Run("promotes fields of embedded struct in its place", func(t *testing.T) {
+		assert.Equal(t, []string{"ID=string@Base.ID", "version=int@Base.Version", "Name=string@Name"}, fields("Flat"))
+	})
+
+	t.Run("promotes fields of embedded pointer", func(t *testing.T) {
+		result := StructFields(shapes["Pointer"].(*StructLike), lookup)
+		require.Len(t, result, 2)
+		assert.True(t, IsPointer(result[0].Embedded[0].Type))
+	})
+
+	t.Run("field embedded less deeply wins", func(t *testing.T) {
+		assert.Equal(t, []string{"version=int@Base.Version", "ID=string@ID"}, fields("Shadowed"))
+	})
+
+	t.Run("field with json name wins, and ambiguous fields are dropped", func(t *testing.T) {
+		assert.Equal(t, []string{"version=int@Base.Version", "Created=int64@Audit.Created", "Version=int@Audit.Version"}, fields("Ambiguous"))
+	})
+
+	t.Run("embedded field with json name is not promoted", func(t *testing.T) {
+		assert.Equal(t, []string{"base=promoted.Base@Base"}, fields("Tagged"))
+	})
+
+	t.Run("embedded field of unknown type is not promoted", func(t *testing.T) {
+		assert.Equal(t, []string{"Base=other.Base@Base"}, fields("Unknown"))
+	})
+
+	t.Run("promotes fields of named type declared as struct", func(t *testing.T) {
+		assert.Equal(t, []string{"ID=string@Named.ID", "version=int@Named.Version"}, fields("Declared"))
+	})
+
+	t.Run("does not promote fields of embedding struct again", func(t *testing.T) {
+		assert.Equal(t, []string{"Name=string@Name"}, fields("Cycle"))
+	})
+
+	t.Run("struct without embedded fields has the same fields", func(t *testing.T) {
+		base := shapes["Base"].(*StructLike)
+		result := StructFields(base, lookup)
+		require.Len(t, result, len(base.Fields))
+		for i, field := range result {
+			assert.Same(t, base.Fields[i], field.FieldLike)
+			assert.Empty(t, field.Embedded)
+		}
+	})
+}
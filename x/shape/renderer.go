@@ -0,0 +1,70 @@
+package shape
+
+import (
+	"sort"
+)
+
+// Renderer exports shapes as types of other language, or as schema.
+// Renderer follows references of added shapes, so written files are self-contained.
+type Renderer interface {
+	AddShape(x Shape)
+	WriteToDir(dir string) error
+}
+
+var (
+	_ Renderer = (*TypeScriptRenderer)(nil)
+	_ Renderer = (*PythonRenderer)(nil)
+	_ Renderer = (*RustRenderer)(nil)
+	_ Renderer = (*ProtobufRenderer)(nil)
+	_ Renderer = (*JSONSchemaRenderer)(nil)
+	_ Renderer = (*OpenAPIRenderer)(nil)
+)
+
+var renderers = map[string]func() Renderer{}
+
+// RegisterRenderer makes renderer available under language name, and its aliases, like "typescript" and "ts".
+// It should be called from init function, since registry is not safe for concurrent use.
+func RegisterRenderer(newRenderer func() Renderer, language string, aliases ...string) {
+	renderers[language] = newRenderer
+	for _, alias := range aliases {
+		renderers[alias] = newRenderer
+	}
+}
+
+// NewRenderer returns renderer registered under language name.
+// Renderers that need options, like protobuf lock file or OpenAPI endpoints, are constructed directly.
+func NewRenderer(language string) (Renderer, bool) {
+	newRenderer, ok := renderers[language]
+	if !ok {
+		return nil, false
+	}
+
+	return newRenderer(), true
+}
+
+// RendererLanguages returns sorted names of registered languages, including aliases.
+func RendererLanguages() []string {
+	result := make([]string, 0, len(renderers))
+	for language := range renderers {
+		result = append(result, language)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// RenderToDir adds shapes to renderer, and writes its output to dir.
+func RenderToDir(r Renderer, shapes []Shape, dir string) error {
+	for _, x := range shapes {
+		r.AddShape(x)
+	}
+
+	return r.WriteToDir(dir)
+}
+
+func init() {
+	RegisterRenderer(func() Renderer { return NewTypeScriptRenderer() }, "typescript", "ts")
+	RegisterRenderer(func() Renderer { return NewPythonRenderer() }, "python", "py")
+	RegisterRenderer(func() Renderer { return NewRustRenderer() }, "rust", "rs")
+	RegisterRenderer(func() Renderer { return NewJSONSchemaRenderer() }, "jsonschema", "json-schema")
+}
@@ -1,5 +1,11 @@
 package shape
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 //go:tag mkunion:"Shape"
 type (
 	Any     struct{}
@@ -171,18 +177,29 @@ func NumberKindToGoName(x NumberKind) string {
 	)
 }
 
+//go:tag serde:"json"
 type FieldLike struct {
 	Name  string
 	Type  Shape
 	Desc  *string
 	Guard Guard
 	Tags  map[string]Tag
+	// Embedded is set for field declared only with type, like `type A struct { B }`, which is named after its type.
+	// Fields of embedded struct are promoted, see StructFields.
+	Embedded bool
 }
 
 const (
 	TagUnionName             = "mkunion"
 	TagUnionOptionNoRegistry = "no-type-registry"
+	TagUnionOptionDerive     = "derive"
+	TagUnionOptionVersion    = "version"
 	TagShapeName             = "shape"
+	TagSerdeName             = "serde"
+	TagValidateName          = "validate"
+	TagServiceName           = "service"
+	TagVariantName           = "variant"
+	TagVariantAliasName      = "alias"
 )
 
 type Tag struct {
@@ -226,27 +243,249 @@ func TagHasOption(x map[string]Tag, tag, option string) bool {
 	return false
 }
 
+// TagHasSerde returns true when serde tag lists given format,
+// like `//go:tag serde:"json,proto"` for formats "json" and "proto"
+func TagHasSerde(x map[string]Tag, format string) bool {
+	if x == nil {
+		return false
+	}
+
+	t, ok := x[TagSerdeName]
+	if !ok {
+		return false
+	}
+
+	return t.Value == format || TagHasOption(x, TagSerdeName, format)
+}
+
+// TagHasValidate returns true when type should have generated Validate method,
+// like `//go:tag validate:"true"`
+func TagHasValidate(x map[string]Tag) bool {
+	return TagGetValue(x, TagValidateName, "") == "true"
+}
+
+// TagHasDerive returns true when union tag lists derived behaviour,
+// like `//go:tag mkunion:"Tree[A],derive=functor"` for behaviour "functor"
+func TagHasDerive(x map[string]Tag, behaviour string) bool {
+	return TagHasOption(x, TagUnionName, TagUnionOptionDerive+"="+behaviour)
+}
+
+// TagUnionVersion returns version of union declared in tag, like `//go:tag mkunion:"State,version=3"`,
+// or 0 when union doesn't declare version.
+func TagUnionVersion(x map[string]Tag) (int, error) {
+	t, ok := x[TagUnionName]
+	if !ok {
+		return 0, nil
+	}
+
+	for _, option := range t.Options {
+		value, found := strings.CutPrefix(option, TagUnionOptionVersion+"=")
+		if !found {
+			continue
+		}
+
+		version, err := strconv.Atoi(value)
+		if err != nil || version < 1 {
+			return 0, fmt.Errorf("shape.TagUnionVersion: version must be positive number, given %q", value)
+		}
+
+		return version, nil
+	}
+
+	return 0, nil
+}
+
+const (
+	TagUnionJSONName = "json"
+
+	// JSONUnionExternal encodes variant as {"$type": "pkg.Variant", "pkg.Variant": {...}}
+	JSONUnionExternal = "external"
+	// JSONUnionInternal encodes variant as {"$type": "pkg.Variant", ...fields of variant}
+	JSONUnionInternal = "internal"
+	// JSONUnionAdjacent encodes variant as {"$type": "pkg.Variant", "$value": {...}}
+	JSONUnionAdjacent = "adjacent"
+	// JSONUnionUntagged encodes variant as is, decodes object as struct variant that declares all its keys,
+	// and other values as first variant that accepts JSON
+	JSONUnionUntagged = "untagged"
+)
+
+// JSONUnionEncoding describes how union is encoded in JSON,
+// declared with tag like `//go:tag json:"internal,tag=type"` or `//go:tag json:"adjacent,tag=t,content=c"`.
+type JSONUnionEncoding struct {
+	Kind    string
+	Tag     string
+	Content string
+}
+
+// UnionJSONEncoding returns encoding of union in JSON, which by default is external with "$type" tag.
+func UnionJSONEncoding(x *UnionLike) (*JSONUnionEncoding, error) {
+	result := &JSONUnionEncoding{
+		Kind:    JSONUnionExternal,
+		Tag:     "$type",
+		Content: "$value",
+	}
+
+	t, ok := x.Tags[TagUnionJSONName]
+	if !ok {
+		return result, nil
+	}
+
+	switch t.Value {
+	case "":
+	case JSONUnionExternal, JSONUnionInternal, JSONUnionAdjacent, JSONUnionUntagged:
+		result.Kind = t.Value
+	default:
+		return nil, fmt.Errorf("shape.UnionJSONEncoding: union %s has unknown encoding %q, expected one of: %s, %s, %s, %s",
+			x.Name, t.Value, JSONUnionExternal, JSONUnionInternal, JSONUnionAdjacent, JSONUnionUntagged)
+	}
+
+	for _, option := range t.Options {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch key {
+		case "tag":
+			result.Tag = value
+		case "content":
+			result.Content = value
+		default:
+			return nil, fmt.Errorf("shape.UnionJSONEncoding: union %s has unknown option %q", x.Name, option)
+		}
+	}
+
+	if result.Tag == "" || result.Content == "" || result.Tag == result.Content {
+		return nil, fmt.Errorf("shape.UnionJSONEncoding: union %s must have non empty and distinct tag and content names", x.Name)
+	}
+
+	if result.Kind == JSONUnionInternal {
+		for _, variant := range x.Variant {
+			structLike, ok := variant.(*StructLike)
+			if !ok {
+				return nil, fmt.Errorf("shape.UnionJSONEncoding: union %s is internally tagged, but variant %s is not a struct", x.Name, Name(variant))
+			}
+
+			for _, field := range structLike.Fields {
+				if TagGetValue(field.Tags, "json", field.Name) == result.Tag {
+					return nil, fmt.Errorf("shape.UnionJSONEncoding: union %s is internally tagged, but field %s.%s has the same name as tag %q",
+						x.Name, structLike.Name, field.Name, result.Tag)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// VariantDiscriminator returns name of variant stored in "$type" field by generated serde,
+// which is declared with tag like `//go:tag variant:"order.placed"`, and defaults to "pkg.Name".
+func VariantDiscriminator(x Shape) string {
+	return TagGetValue(Tags(x), TagVariantName, PkgName(x)+"."+Name(x))
+}
+
+// VariantAliases returns previous names of variant, that generated serde still accepts when decoding,
+// declared with tag like `//go:tag alias:"OldName,oldpkg.OldName"`.
+// Alias without package name refers to type in the same package.
+func VariantAliases(x Shape) []string {
+	t, ok := Tags(x)[TagVariantAliasName]
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, alias := range append([]string{t.Value}, t.Options...) {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			continue
+		}
+
+		if !strings.Contains(alias, ".") {
+			alias = PkgName(x) + "." + alias
+		}
+
+		result = append(result, alias)
+	}
+
+	return result
+}
+
+// VariantNames returns variants of union by their names and aliases,
+// or error, when two variants declare the same name, since generated serde could not tell them apart.
+func VariantNames(x *UnionLike) (map[string]Shape, error) {
+	result := make(map[string]Shape)
+	for _, variant := range x.Variant {
+		for _, name := range append([]string{VariantDiscriminator(variant)}, VariantAliases(variant)...) {
+			if other, ok := result[name]; ok {
+				return nil, fmt.Errorf("shape.VariantNames: union %s has variants %s and %s with the same name %q",
+					x.Name, Name(other), Name(variant), name)
+			}
+			result[name] = variant
+		}
+	}
+
+	return result, nil
+}
+
+// ServiceResponse returns type of response of union tagged as service,
+// like `//go:tag mkunion:"Command" service:"State"`, where response type is declared in the same package.
+func ServiceResponse(x *UnionLike) (*RefName, bool) {
+	name := TagGetValue(x.Tags, TagServiceName, "")
+	if name == "" {
+		return nil, false
+	}
+
+	return &RefName{
+		Name:          name,
+		PkgName:       x.PkgName,
+		PkgImportName: x.PkgImportName,
+	}, true
+}
+
+// ServicePath returns path of endpoint, that handles variant of union tagged as service.
+func ServicePath(variant Shape) string {
+	return "/" + Name(variant)
+}
+
 //go:tag mkunion:"Guard"
 type (
 	Enum struct {
 		Val []string
 	}
 	Required struct{}
-	//Regexp   struct {
-	//	Regexp string
-	//}
-	//Between struct {
-	//	Min int
-	//	Max int
-	//}
+	Regexp   struct {
+		Regexp string
+	}
+	Between struct {
+		Min float64
+		Max float64
+	}
+	MinLength struct {
+		Len int
+	}
+	MaxLength struct {
+		Len int
+	}
+	MinItems struct {
+		Len int
+	}
+	MaxItems struct {
+		Len int
+	}
 	AndGuard struct {
 		L []Guard
 	}
 )
 
 func IsRequired(x Guard) bool {
-	_, isRequired := x.(*Required)
-	return isRequired
+	switch y := x.(type) {
+	case *Required:
+		return true
+	case *AndGuard:
+		for _, guard := range y.L {
+			if IsRequired(guard) {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 func ConcatGuard(a, b Guard) Guard {
@@ -408,6 +647,25 @@ func IsWeekAlias(x Shape) bool {
 	return alias.IsAlias
 }
 
+// ResolveWeekAlias returns type, that reference to week alias refers to,
+// or x, when x doesn't refer to week alias.
+// example:
+//
+//	type ID = string // reference to ID resolves to string
+func ResolveWeekAlias(x Shape, lookup func(*RefName) (Shape, bool)) Shape {
+	ref, ok := x.(*RefName)
+	if !ok || len(ref.Indexed) > 0 || (ref.PkgName == "" && ref.PkgImportName == "") {
+		return x
+	}
+
+	found, ok := lookup(ref)
+	if !ok || !IsWeekAlias(found) {
+		return x
+	}
+
+	return ResolveWeekAlias(found.(*AliasLike).Type, lookup)
+}
+
 func IsString(x Shape) bool {
 	prim, isPrimitive := x.(*PrimitiveLike)
 	if !isPrimitive {
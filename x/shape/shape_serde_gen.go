@@ -7,6 +7,325 @@ import (
 	"github.com/widmogrod/mkunion/x/shared"
 )
 
+var (
+	_ json.Unmarshaler = (*FieldLike)(nil)
+	_ json.Marshaler   = (*FieldLike)(nil)
+)
+
+func (r *FieldLike) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return r._marshalJSONFieldLike(*r)
+}
+func (r *FieldLike) _marshalJSONFieldLike(x FieldLike) ([]byte, error) {
+	partial := make(map[string]json.RawMessage)
+	var err error
+	var fieldName []byte
+	fieldName, err = r._marshalJSONstring(x.Name)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONFieldLike: field name Name; %w", err)
+	}
+	partial["Name"] = fieldName
+	var fieldType []byte
+	fieldType, err = r._marshalJSONShape(x.Type)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONFieldLike: field name Type; %w", err)
+	}
+	partial["Type"] = fieldType
+	var fieldDesc []byte
+	fieldDesc, err = r._marshalJSONPtrstring(x.Desc)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONFieldLike: field name Desc; %w", err)
+	}
+	if fieldDesc != nil {
+		partial["Desc"] = fieldDesc
+	}
+	var fieldGuard []byte
+	fieldGuard, err = r._marshalJSONGuard(x.Guard)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONFieldLike: field name Guard; %w", err)
+	}
+	partial["Guard"] = fieldGuard
+	var fieldTags []byte
+	fieldTags, err = r._marshalJSONmapLb_string_bLTag(x.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONFieldLike: field name Tags; %w", err)
+	}
+	partial["Tags"] = fieldTags
+	var fieldEmbedded []byte
+	fieldEmbedded, err = r._marshalJSONbool(x.Embedded)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONFieldLike: field name Embedded; %w", err)
+	}
+	partial["Embedded"] = fieldEmbedded
+	result, err := json.Marshal(partial)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONFieldLike: struct; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) _marshalJSONstring(x string) ([]byte, error) {
+	result, err := json.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONstring:; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) _marshalJSONShape(x Shape) ([]byte, error) {
+	result, err := shared.JSONMarshal[Shape](x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONShape:; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) _marshalJSONPtrstring(x *string) ([]byte, error) {
+	if x == nil {
+		return nil, nil
+	}
+	return r._marshalJSONstring(*x)
+}
+func (r *FieldLike) _marshalJSONGuard(x Guard) ([]byte, error) {
+	result, err := shared.JSONMarshal[Guard](x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONGuard:; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) _marshalJSONmapLb_string_bLTag(x map[string]Tag) ([]byte, error) {
+	partial := make(map[string]json.RawMessage)
+	for k, v := range x {
+		key := string(k)
+		value, err := r._marshalJSONTag(v)
+		if err != nil {
+			return nil, fmt.Errorf("shape: FieldLike._marshalJSONmapLb_string_bLTag: value; %w", err)
+		}
+		partial[string(key)] = value
+	}
+	result, err := json.Marshal(partial)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONmapLb_string_bLTag:; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) _marshalJSONTag(x Tag) ([]byte, error) {
+	result, err := shared.JSONMarshal[Tag](x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONTag:; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) _marshalJSONbool(x bool) ([]byte, error) {
+	result, err := json.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._marshalJSONbool:; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) UnmarshalJSON(data []byte) error {
+	result, err := r._unmarshalJSONFieldLike(data)
+	if err != nil {
+		return fmt.Errorf("shape: FieldLike.UnmarshalJSON: %w", err)
+	}
+	*r = result
+	return nil
+}
+func (r *FieldLike) _unmarshalJSONFieldLike(data []byte) (FieldLike, error) {
+	result := FieldLike{}
+	var partial map[string]json.RawMessage
+	err := json.Unmarshal(data, &partial)
+	if err != nil {
+		return result, fmt.Errorf("shape: FieldLike._unmarshalJSONFieldLike: native struct unwrap; %w", err)
+	}
+	if fieldName, ok := partial["Name"]; ok {
+		result.Name, err = r._unmarshalJSONstring(fieldName)
+		if err != nil {
+			return result, fmt.Errorf("shape: FieldLike._unmarshalJSONFieldLike: field Name; %w", err)
+		}
+	}
+	if fieldType, ok := partial["Type"]; ok {
+		result.Type, err = r._unmarshalJSONShape(fieldType)
+		if err != nil {
+			return result, fmt.Errorf("shape: FieldLike._unmarshalJSONFieldLike: field Type; %w", err)
+		}
+	}
+	if fieldDesc, ok := partial["Desc"]; ok {
+		result.Desc, err = r._unmarshalJSONPtrstring(fieldDesc)
+		if err != nil {
+			return result, fmt.Errorf("shape: FieldLike._unmarshalJSONFieldLike: field Desc; %w", err)
+		}
+	}
+	if fieldGuard, ok := partial["Guard"]; ok {
+		result.Guard, err = r._unmarshalJSONGuard(fieldGuard)
+		if err != nil {
+			return result, fmt.Errorf("shape: FieldLike._unmarshalJSONFieldLike: field Guard; %w", err)
+		}
+	}
+	if fieldTags, ok := partial["Tags"]; ok {
+		result.Tags, err = r._unmarshalJSONmapLb_string_bLTag(fieldTags)
+		if err != nil {
+			return result, fmt.Errorf("shape: FieldLike._unmarshalJSONFieldLike: field Tags; %w", err)
+		}
+	}
+	if fieldEmbedded, ok := partial["Embedded"]; ok {
+		result.Embedded, err = r._unmarshalJSONbool(fieldEmbedded)
+		if err != nil {
+			return result, fmt.Errorf("shape: FieldLike._unmarshalJSONFieldLike: field Embedded; %w", err)
+		}
+	}
+	return result, nil
+}
+func (r *FieldLike) _unmarshalJSONstring(data []byte) (string, error) {
+	var result string
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return result, fmt.Errorf("shape: FieldLike._unmarshalJSONstring: native primitive unwrap; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) _unmarshalJSONShape(data []byte) (Shape, error) {
+	result, err := shared.JSONUnmarshal[Shape](data)
+	if err != nil {
+		return result, fmt.Errorf("shape: FieldLike._unmarshalJSONShape: native ref unwrap; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) _unmarshalJSONPtrstring(data []byte) (*string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if string(data[:4]) == "null" {
+		return nil, nil
+	}
+	result, err := r._unmarshalJSONstring(data)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._unmarshalJSONPtrstring: pointer; %w", err)
+	}
+	return &result, nil
+}
+func (r *FieldLike) _unmarshalJSONGuard(data []byte) (Guard, error) {
+	result, err := shared.JSONUnmarshal[Guard](data)
+	if err != nil {
+		return result, fmt.Errorf("shape: FieldLike._unmarshalJSONGuard: native ref unwrap; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) _unmarshalJSONmapLb_string_bLTag(data []byte) (map[string]Tag, error) {
+	var partial map[string]json.RawMessage
+	err := json.Unmarshal(data, &partial)
+	if err != nil {
+		return nil, fmt.Errorf("shape: FieldLike._unmarshalJSONmapLb_string_bLTag: native map unwrap; %w", err)
+	}
+	result := make(map[string]Tag)
+	for k, v := range partial {
+		key := string(k)
+		value, err := r._unmarshalJSONTag(v)
+		if err != nil {
+			return nil, fmt.Errorf("shape: FieldLike._unmarshalJSONmapLb_string_bLTag: value; %w", err)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+func (r *FieldLike) _unmarshalJSONTag(data []byte) (Tag, error) {
+	result, err := shared.JSONUnmarshal[Tag](data)
+	if err != nil {
+		return result, fmt.Errorf("shape: FieldLike._unmarshalJSONTag: native ref unwrap; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) _unmarshalJSONbool(data []byte) (bool, error) {
+	var result bool
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return result, fmt.Errorf("shape: FieldLike._unmarshalJSONbool: native primitive unwrap; %w", err)
+	}
+	return result, nil
+}
+func (r *FieldLike) Equal(other *FieldLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if !ShapeEqual(r.Type, other.Type) {
+		return false
+	}
+	if (r.Desc == nil) != (other.Desc == nil) {
+		return false
+	}
+	if r.Desc != nil {
+		if *r.Desc != *other.Desc {
+			return false
+		}
+	}
+	if !GuardEqual(r.Guard, other.Guard) {
+		return false
+	}
+	if len(r.Tags) != len(other.Tags) {
+		return false
+	}
+	for k1, v1 := range r.Tags {
+		w1, ok := other.Tags[k1]
+		if !ok {
+			return false
+		}
+		if !shared.Equal(v1, w1) {
+			return false
+		}
+	}
+	if r.Embedded != other.Embedded {
+		return false
+	}
+	return true
+}
+
+func (r *FieldLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.FieldLike")
+	h = shared.HashString(h, r.Name)
+	h = shared.HashUint64(h, ShapeHash(r.Type))
+	h = shared.HashBool(h, r.Desc != nil)
+	if r.Desc != nil {
+		h = shared.HashString(h, *r.Desc)
+	}
+	h = shared.HashUint64(h, GuardHash(r.Guard))
+	h = shared.HashUint64(h, uint64(len(r.Tags)))
+	var s1 uint64
+	for k1, v1 := range r.Tags {
+		e1 := shared.HashOffset
+		e1 = shared.HashString(e1, k1)
+		e1 = shared.Hash(e1, v1)
+		s1 += e1
+	}
+	h = shared.HashUint64(h, s1)
+	h = shared.HashBool(h, r.Embedded)
+	return h
+}
+
+func (r *FieldLike) DeepCopy() *FieldLike {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Type = ShapeDeepCopy(r.Type)
+	if r.Desc != nil {
+		r1 := *r.Desc
+		result.Desc = &r1
+	}
+	result.Guard = GuardDeepCopy(r.Guard)
+	if r.Tags != nil {
+		result.Tags = make(map[string]Tag, len(r.Tags))
+		for k2, v2 := range r.Tags {
+			result.Tags[k2] = shared.DeepCopy(v2)
+		}
+	}
+	return &result
+}
+
 var (
 	_ json.Unmarshaler = (*TypeParam)(nil)
 	_ json.Marshaler   = (*TypeParam)(nil)
@@ -97,3 +416,34 @@ func (r *TypeParam) _unmarshalJSONShape(data []byte) (Shape, error) {
 	}
 	return result, nil
 }
+func (r *TypeParam) Equal(other *TypeParam) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if !ShapeEqual(r.Type, other.Type) {
+		return false
+	}
+	return true
+}
+
+func (r *TypeParam) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.TypeParam")
+	h = shared.HashString(h, r.Name)
+	h = shared.HashUint64(h, ShapeHash(r.Type))
+	return h
+}
+
+func (r *TypeParam) DeepCopy() *TypeParam {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Type = ShapeDeepCopy(r.Type)
+	return &result
+}
@@ -5,6 +5,7 @@ func init() {
 	Register(AliasLikeShape())
 	Register(AndGuardShape())
 	Register(AnyShape())
+	Register(BetweenShape())
 	Register(BooleanLikeShape())
 	Register(EnumShape())
 	Register(FieldLikeShape())
@@ -16,14 +17,20 @@ func init() {
 	Register(Int64Shape())
 	Register(Int8Shape())
 	Register(IntShape())
+	Register(JSONUnionEncodingShape())
 	Register(ListLikeShape())
 	Register(MapLikeShape())
+	Register(MaxItemsShape())
+	Register(MaxLengthShape())
+	Register(MinItemsShape())
+	Register(MinLengthShape())
 	Register(NumberKindShape())
 	Register(NumberLikeShape())
 	Register(PointerLikeShape())
 	Register(PrimitiveKindShape())
 	Register(PrimitiveLikeShape())
 	Register(RefNameShape())
+	Register(RegexpShape())
 	Register(RequiredShape())
 	Register(ShapeShape())
 	Register(StringLikeShape())
@@ -48,6 +55,12 @@ func GuardShape() Shape {
 		Variant: []Shape{
 			EnumShape(),
 			RequiredShape(),
+			RegexpShape(),
+			BetweenShape(),
+			MinLengthShape(),
+			MaxLengthShape(),
+			MinItemsShape(),
+			MaxItemsShape(),
 			AndGuardShape(),
 		},
 	}
@@ -87,6 +100,148 @@ func RequiredShape() Shape {
 	}
 }
 
+func RegexpShape() Shape {
+	return &StructLike{
+		Name:          "Regexp",
+		PkgName:       "shape",
+		PkgImportName: "github.com/widmogrod/mkunion/x/shape",
+		Fields: []*FieldLike{
+			{
+				Name: "Regexp",
+				Type: &PrimitiveLike{Kind: &StringLike{}},
+			},
+		},
+		Tags: map[string]Tag{
+			"mkunion": {
+				Value: "Guard",
+			},
+		},
+	}
+}
+
+func BetweenShape() Shape {
+	return &StructLike{
+		Name:          "Between",
+		PkgName:       "shape",
+		PkgImportName: "github.com/widmogrod/mkunion/x/shape",
+		Fields: []*FieldLike{
+			{
+				Name: "Min",
+				Type: &PrimitiveLike{
+					Kind: &NumberLike{
+						Kind: &Float64{},
+					},
+				},
+			},
+			{
+				Name: "Max",
+				Type: &PrimitiveLike{
+					Kind: &NumberLike{
+						Kind: &Float64{},
+					},
+				},
+			},
+		},
+		Tags: map[string]Tag{
+			"mkunion": {
+				Value: "Guard",
+			},
+		},
+	}
+}
+
+func MinLengthShape() Shape {
+	return &StructLike{
+		Name:          "MinLength",
+		PkgName:       "shape",
+		PkgImportName: "github.com/widmogrod/mkunion/x/shape",
+		Fields: []*FieldLike{
+			{
+				Name: "Len",
+				Type: &PrimitiveLike{
+					Kind: &NumberLike{
+						Kind: &Int{},
+					},
+				},
+			},
+		},
+		Tags: map[string]Tag{
+			"mkunion": {
+				Value: "Guard",
+			},
+		},
+	}
+}
+
+func MaxLengthShape() Shape {
+	return &StructLike{
+		Name:          "MaxLength",
+		PkgName:       "shape",
+		PkgImportName: "github.com/widmogrod/mkunion/x/shape",
+		Fields: []*FieldLike{
+			{
+				Name: "Len",
+				Type: &PrimitiveLike{
+					Kind: &NumberLike{
+						Kind: &Int{},
+					},
+				},
+			},
+		},
+		Tags: map[string]Tag{
+			"mkunion": {
+				Value: "Guard",
+			},
+		},
+	}
+}
+
+func MinItemsShape() Shape {
+	return &StructLike{
+		Name:          "MinItems",
+		PkgName:       "shape",
+		PkgImportName: "github.com/widmogrod/mkunion/x/shape",
+		Fields: []*FieldLike{
+			{
+				Name: "Len",
+				Type: &PrimitiveLike{
+					Kind: &NumberLike{
+						Kind: &Int{},
+					},
+				},
+			},
+		},
+		Tags: map[string]Tag{
+			"mkunion": {
+				Value: "Guard",
+			},
+		},
+	}
+}
+
+func MaxItemsShape() Shape {
+	return &StructLike{
+		Name:          "MaxItems",
+		PkgName:       "shape",
+		PkgImportName: "github.com/widmogrod/mkunion/x/shape",
+		Fields: []*FieldLike{
+			{
+				Name: "Len",
+				Type: &PrimitiveLike{
+					Kind: &NumberLike{
+						Kind: &Int{},
+					},
+				},
+			},
+		},
+		Tags: map[string]Tag{
+			"mkunion": {
+				Value: "Guard",
+			},
+		},
+	}
+}
+
 func AndGuardShape() Shape {
 	return &StructLike{
 		Name:          "AndGuard",
@@ -808,6 +963,38 @@ func FieldLikeShape() Shape {
 					},
 				},
 			},
+			{
+				Name: "Embedded",
+				Type: &PrimitiveLike{Kind: &BooleanLike{}},
+			},
+		},
+		Tags: map[string]Tag{
+			"serde": {
+				Value: "json",
+			},
+		},
+	}
+}
+
+//shape:shape
+func JSONUnionEncodingShape() Shape {
+	return &StructLike{
+		Name:          "JSONUnionEncoding",
+		PkgName:       "shape",
+		PkgImportName: "github.com/widmogrod/mkunion/x/shape",
+		Fields: []*FieldLike{
+			{
+				Name: "Kind",
+				Type: &PrimitiveLike{Kind: &StringLike{}},
+			},
+			{
+				Name: "Tag",
+				Type: &PrimitiveLike{Kind: &StringLike{}},
+			},
+			{
+				Name: "Content",
+				Type: &PrimitiveLike{Kind: &StringLike{}},
+			},
 		},
 	}
 }
@@ -10,6 +10,12 @@ import (
 type GuardVisitor interface {
 	VisitEnum(v *Enum) any
 	VisitRequired(v *Required) any
+	VisitRegexp(v *Regexp) any
+	VisitBetween(v *Between) any
+	VisitMinLength(v *MinLength) any
+	VisitMaxLength(v *MaxLength) any
+	VisitMinItems(v *MinItems) any
+	VisitMaxItems(v *MaxItems) any
 	VisitAndGuard(v *AndGuard) any
 }
 
@@ -20,26 +26,56 @@ type Guard interface {
 var (
 	_ Guard = (*Enum)(nil)
 	_ Guard = (*Required)(nil)
+	_ Guard = (*Regexp)(nil)
+	_ Guard = (*Between)(nil)
+	_ Guard = (*MinLength)(nil)
+	_ Guard = (*MaxLength)(nil)
+	_ Guard = (*MinItems)(nil)
+	_ Guard = (*MaxItems)(nil)
 	_ Guard = (*AndGuard)(nil)
 )
 
-func (r *Enum) AcceptGuard(v GuardVisitor) any     { return v.VisitEnum(r) }
-func (r *Required) AcceptGuard(v GuardVisitor) any { return v.VisitRequired(r) }
-func (r *AndGuard) AcceptGuard(v GuardVisitor) any { return v.VisitAndGuard(r) }
+func (r *Enum) AcceptGuard(v GuardVisitor) any      { return v.VisitEnum(r) }
+func (r *Required) AcceptGuard(v GuardVisitor) any  { return v.VisitRequired(r) }
+func (r *Regexp) AcceptGuard(v GuardVisitor) any    { return v.VisitRegexp(r) }
+func (r *Between) AcceptGuard(v GuardVisitor) any   { return v.VisitBetween(r) }
+func (r *MinLength) AcceptGuard(v GuardVisitor) any { return v.VisitMinLength(r) }
+func (r *MaxLength) AcceptGuard(v GuardVisitor) any { return v.VisitMaxLength(r) }
+func (r *MinItems) AcceptGuard(v GuardVisitor) any  { return v.VisitMinItems(r) }
+func (r *MaxItems) AcceptGuard(v GuardVisitor) any  { return v.VisitMaxItems(r) }
+func (r *AndGuard) AcceptGuard(v GuardVisitor) any  { return v.VisitAndGuard(r) }
 
 func MatchGuardR3[T0, T1, T2 any](
 	x Guard,
 	f1 func(x *Enum) (T0, T1, T2),
 	f2 func(x *Required) (T0, T1, T2),
-	f3 func(x *AndGuard) (T0, T1, T2),
+	f3 func(x *Regexp) (T0, T1, T2),
+	f4 func(x *Between) (T0, T1, T2),
+	f5 func(x *MinLength) (T0, T1, T2),
+	f6 func(x *MaxLength) (T0, T1, T2),
+	f7 func(x *MinItems) (T0, T1, T2),
+	f8 func(x *MaxItems) (T0, T1, T2),
+	f9 func(x *AndGuard) (T0, T1, T2),
 ) (T0, T1, T2) {
 	switch v := x.(type) {
 	case *Enum:
 		return f1(v)
 	case *Required:
 		return f2(v)
-	case *AndGuard:
+	case *Regexp:
 		return f3(v)
+	case *Between:
+		return f4(v)
+	case *MinLength:
+		return f5(v)
+	case *MaxLength:
+		return f6(v)
+	case *MinItems:
+		return f7(v)
+	case *MaxItems:
+		return f8(v)
+	case *AndGuard:
+		return f9(v)
 	}
 	var result1 T0
 	var result2 T1
@@ -51,15 +87,33 @@ func MatchGuardR2[T0, T1 any](
 	x Guard,
 	f1 func(x *Enum) (T0, T1),
 	f2 func(x *Required) (T0, T1),
-	f3 func(x *AndGuard) (T0, T1),
+	f3 func(x *Regexp) (T0, T1),
+	f4 func(x *Between) (T0, T1),
+	f5 func(x *MinLength) (T0, T1),
+	f6 func(x *MaxLength) (T0, T1),
+	f7 func(x *MinItems) (T0, T1),
+	f8 func(x *MaxItems) (T0, T1),
+	f9 func(x *AndGuard) (T0, T1),
 ) (T0, T1) {
 	switch v := x.(type) {
 	case *Enum:
 		return f1(v)
 	case *Required:
 		return f2(v)
-	case *AndGuard:
+	case *Regexp:
 		return f3(v)
+	case *Between:
+		return f4(v)
+	case *MinLength:
+		return f5(v)
+	case *MaxLength:
+		return f6(v)
+	case *MinItems:
+		return f7(v)
+	case *MaxItems:
+		return f8(v)
+	case *AndGuard:
+		return f9(v)
 	}
 	var result1 T0
 	var result2 T1
@@ -70,15 +124,33 @@ func MatchGuardR1[T0 any](
 	x Guard,
 	f1 func(x *Enum) T0,
 	f2 func(x *Required) T0,
-	f3 func(x *AndGuard) T0,
+	f3 func(x *Regexp) T0,
+	f4 func(x *Between) T0,
+	f5 func(x *MinLength) T0,
+	f6 func(x *MaxLength) T0,
+	f7 func(x *MinItems) T0,
+	f8 func(x *MaxItems) T0,
+	f9 func(x *AndGuard) T0,
 ) T0 {
 	switch v := x.(type) {
 	case *Enum:
 		return f1(v)
 	case *Required:
 		return f2(v)
-	case *AndGuard:
+	case *Regexp:
 		return f3(v)
+	case *Between:
+		return f4(v)
+	case *MinLength:
+		return f5(v)
+	case *MaxLength:
+		return f6(v)
+	case *MinItems:
+		return f7(v)
+	case *MaxItems:
+		return f8(v)
+	case *AndGuard:
+		return f9(v)
 	}
 	var result1 T0
 	return result1
@@ -88,36 +160,462 @@ func MatchGuardR0(
 	x Guard,
 	f1 func(x *Enum),
 	f2 func(x *Required),
-	f3 func(x *AndGuard),
+	f3 func(x *Regexp),
+	f4 func(x *Between),
+	f5 func(x *MinLength),
+	f6 func(x *MaxLength),
+	f7 func(x *MinItems),
+	f8 func(x *MaxItems),
+	f9 func(x *AndGuard),
 ) {
 	switch v := x.(type) {
 	case *Enum:
 		f1(v)
 	case *Required:
 		f2(v)
-	case *AndGuard:
+	case *Regexp:
 		f3(v)
+	case *Between:
+		f4(v)
+	case *MinLength:
+		f5(v)
+	case *MaxLength:
+		f6(v)
+	case *MinItems:
+		f7(v)
+	case *MaxItems:
+		f8(v)
+	case *AndGuard:
+		f9(v)
+	}
+}
+
+// RewriteGuardTopDown applies f to x, and then to each Guard nested in result of f (pre-order).
+// When f returns nil, nested values are not visited.
+func RewriteGuardTopDown(x Guard, f func(Guard) Guard) Guard {
+	if x == nil {
+		return nil
+	}
+	return rewriteGuardChildren(f(x), func(y Guard) Guard {
+		return RewriteGuardTopDown(y, f)
+	})
+}
+
+// RewriteGuardBottomUp applies f to each Guard nested in x, and then to x with already rewritten values (post-order).
+func RewriteGuardBottomUp(x Guard, f func(Guard) Guard) Guard {
+	if x == nil {
+		return nil
+	}
+	return f(rewriteGuardChildren(x, func(y Guard) Guard {
+		return RewriteGuardBottomUp(y, f)
+	}))
+}
+
+// rewriteGuardChildren returns shallow copy of x, where each Guard nested in x is replaced by result of f.
+// Variants without nested Guard are returned as they are, and x is never modified.
+func rewriteGuardChildren(x Guard, f func(Guard) Guard) Guard {
+	switch v := x.(type) {
+	case *AndGuard:
+		result := *v
+		if v.L != nil {
+			result.L = make([]Guard, len(v.L))
+			for i1 := range v.L {
+				result.L[i1] = f(v.L[i1])
+			}
+		}
+		return &result
+	}
+
+	return x
+}
+
+// GuardEqual returns true, when a and b are the same variant of Guard with equal fields.
+func GuardEqual(a, b Guard) bool {
+	switch x := a.(type) {
+	case *Enum:
+		y, ok := b.(*Enum)
+		return ok && x.Equal(y)
+	case *Required:
+		y, ok := b.(*Required)
+		return ok && x.Equal(y)
+	case *Regexp:
+		y, ok := b.(*Regexp)
+		return ok && x.Equal(y)
+	case *Between:
+		y, ok := b.(*Between)
+		return ok && x.Equal(y)
+	case *MinLength:
+		y, ok := b.(*MinLength)
+		return ok && x.Equal(y)
+	case *MaxLength:
+		y, ok := b.(*MaxLength)
+		return ok && x.Equal(y)
+	case *MinItems:
+		y, ok := b.(*MinItems)
+		return ok && x.Equal(y)
+	case *MaxItems:
+		y, ok := b.(*MaxItems)
+		return ok && x.Equal(y)
+	case *AndGuard:
+		y, ok := b.(*AndGuard)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// GuardHash returns hash of x, that is the same for values equal according to GuardEqual.
+func GuardHash(x Guard) uint64 {
+	switch y := x.(type) {
+	case *Enum:
+		return y.Hash()
+	case *Required:
+		return y.Hash()
+	case *Regexp:
+		return y.Hash()
+	case *Between:
+		return y.Hash()
+	case *MinLength:
+		return y.Hash()
+	case *MaxLength:
+		return y.Hash()
+	case *MinItems:
+		return y.Hash()
+	case *MaxItems:
+		return y.Hash()
+	case *AndGuard:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// GuardDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func GuardDeepCopy(x Guard) Guard {
+	switch y := x.(type) {
+	case *Enum:
+		return y.DeepCopy()
+	case *Required:
+		return y.DeepCopy()
+	case *Regexp:
+		return y.DeepCopy()
+	case *Between:
+		return y.DeepCopy()
+	case *MinLength:
+		return y.DeepCopy()
+	case *MaxLength:
+		return y.DeepCopy()
+	case *MinItems:
+		return y.DeepCopy()
+	case *MaxItems:
+		return y.DeepCopy()
+	case *AndGuard:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Enum) Equal(other *Enum) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.Val) != len(other.Val) {
+		return false
+	}
+	for i1 := range r.Val {
+		if r.Val[i1] != other.Val[i1] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Enum) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Enum")
+	h = shared.HashUint64(h, uint64(len(r.Val)))
+	for _, v1 := range r.Val {
+		h = shared.HashString(h, v1)
+	}
+	return h
+}
+
+func (r *Enum) DeepCopy() *Enum {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Val != nil {
+		result.Val = make([]string, len(r.Val))
+		copy(result.Val, r.Val)
+	}
+	return &result
+}
+
+func (r *Required) Equal(other *Required) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Required) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Required")
+	return h
+}
+
+func (r *Required) DeepCopy() *Required {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Regexp) Equal(other *Regexp) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Regexp != other.Regexp {
+		return false
+	}
+	return true
+}
+
+func (r *Regexp) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Regexp")
+	h = shared.HashString(h, r.Regexp)
+	return h
+}
+
+func (r *Regexp) DeepCopy() *Regexp {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Between) Equal(other *Between) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Min != other.Min {
+		return false
+	}
+	if r.Max != other.Max {
+		return false
+	}
+	return true
+}
+
+func (r *Between) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Between")
+	h = shared.HashFloat64(h, r.Min)
+	h = shared.HashFloat64(h, r.Max)
+	return h
+}
+
+func (r *Between) DeepCopy() *Between {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *MinLength) Equal(other *MinLength) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Len != other.Len {
+		return false
+	}
+	return true
+}
+
+func (r *MinLength) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.MinLength")
+	h = shared.HashUint64(h, uint64(r.Len))
+	return h
+}
+
+func (r *MinLength) DeepCopy() *MinLength {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *MaxLength) Equal(other *MaxLength) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Len != other.Len {
+		return false
+	}
+	return true
+}
+
+func (r *MaxLength) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.MaxLength")
+	h = shared.HashUint64(h, uint64(r.Len))
+	return h
+}
+
+func (r *MaxLength) DeepCopy() *MaxLength {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *MinItems) Equal(other *MinItems) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Len != other.Len {
+		return false
+	}
+	return true
+}
+
+func (r *MinItems) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.MinItems")
+	h = shared.HashUint64(h, uint64(r.Len))
+	return h
+}
+
+func (r *MinItems) DeepCopy() *MinItems {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *MaxItems) Equal(other *MaxItems) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Len != other.Len {
+		return false
+	}
+	return true
+}
+
+func (r *MaxItems) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.MaxItems")
+	h = shared.HashUint64(h, uint64(r.Len))
+	return h
+}
+
+func (r *MaxItems) DeepCopy() *MaxItems {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *AndGuard) Equal(other *AndGuard) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.L) != len(other.L) {
+		return false
+	}
+	for i1 := range r.L {
+		if !GuardEqual(r.L[i1], other.L[i1]) {
+			return false
+		}
 	}
+	return true
 }
+
+func (r *AndGuard) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.AndGuard")
+	h = shared.HashUint64(h, uint64(len(r.L)))
+	for _, v1 := range r.L {
+		h = shared.HashUint64(h, GuardHash(v1))
+	}
+	return h
+}
+
+func (r *AndGuard) DeepCopy() *AndGuard {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.L != nil {
+		result.L = make([]Guard, len(r.L))
+		for i1 := range r.L {
+			result.L[i1] = GuardDeepCopy(r.L[i1])
+		}
+	}
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.AndGuard", AndGuardFromJSON, AndGuardToJSON)
+	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.Between", BetweenFromJSON, BetweenToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.Enum", EnumFromJSON, EnumToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.Guard", GuardFromJSON, GuardToJSON)
+	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.MaxItems", MaxItemsFromJSON, MaxItemsToJSON)
+	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.MaxLength", MaxLengthFromJSON, MaxLengthToJSON)
+	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.MinItems", MinItemsFromJSON, MinItemsToJSON)
+	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.MinLength", MinLengthFromJSON, MinLengthToJSON)
+	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.Regexp", RegexpFromJSON, RegexpToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.Required", RequiredFromJSON, RequiredToJSON)
 }
 
 type GuardUnionJSON struct {
-	Type     string          `json:"$type,omitempty"`
-	Enum     json.RawMessage `json:"shape.Enum,omitempty"`
-	Required json.RawMessage `json:"shape.Required,omitempty"`
-	AndGuard json.RawMessage `json:"shape.AndGuard,omitempty"`
+	Type      string          `json:"$type,omitempty"`
+	Enum      json.RawMessage `json:"shape.Enum,omitempty"`
+	Required  json.RawMessage `json:"shape.Required,omitempty"`
+	Regexp    json.RawMessage `json:"shape.Regexp,omitempty"`
+	Between   json.RawMessage `json:"shape.Between,omitempty"`
+	MinLength json.RawMessage `json:"shape.MinLength,omitempty"`
+	MaxLength json.RawMessage `json:"shape.MaxLength,omitempty"`
+	MinItems  json.RawMessage `json:"shape.MinItems,omitempty"`
+	MaxItems  json.RawMessage `json:"shape.MaxItems,omitempty"`
+	AndGuard  json.RawMessage `json:"shape.AndGuard,omitempty"`
 }
 
 func GuardFromJSON(x []byte) (Guard, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data GuardUnionJSON
@@ -131,6 +629,18 @@ func GuardFromJSON(x []byte) (Guard, error) {
 		return EnumFromJSON(data.Enum)
 	case "shape.Required":
 		return RequiredFromJSON(data.Required)
+	case "shape.Regexp":
+		return RegexpFromJSON(data.Regexp)
+	case "shape.Between":
+		return BetweenFromJSON(data.Between)
+	case "shape.MinLength":
+		return MinLengthFromJSON(data.MinLength)
+	case "shape.MaxLength":
+		return MaxLengthFromJSON(data.MaxLength)
+	case "shape.MinItems":
+		return MinItemsFromJSON(data.MinItems)
+	case "shape.MaxItems":
+		return MaxItemsFromJSON(data.MaxItems)
 	case "shape.AndGuard":
 		return AndGuardFromJSON(data.AndGuard)
 	}
@@ -139,6 +649,18 @@ func GuardFromJSON(x []byte) (Guard, error) {
 		return EnumFromJSON(data.Enum)
 	} else if data.Required != nil {
 		return RequiredFromJSON(data.Required)
+	} else if data.Regexp != nil {
+		return RegexpFromJSON(data.Regexp)
+	} else if data.Between != nil {
+		return BetweenFromJSON(data.Between)
+	} else if data.MinLength != nil {
+		return MinLengthFromJSON(data.MinLength)
+	} else if data.MaxLength != nil {
+		return MaxLengthFromJSON(data.MaxLength)
+	} else if data.MinItems != nil {
+		return MinItemsFromJSON(data.MinItems)
+	} else if data.MaxItems != nil {
+		return MaxItemsFromJSON(data.MaxItems)
 	} else if data.AndGuard != nil {
 		return AndGuardFromJSON(data.AndGuard)
 	}
@@ -171,6 +693,66 @@ func GuardToJSON(x Guard) ([]byte, error) {
 				Required: body,
 			})
 		},
+		func(y *Regexp) ([]byte, error) {
+			body, err := RegexpToJSON(y)
+			if err != nil {
+				return nil, fmt.Errorf("shape.GuardToJSON: %w", err)
+			}
+			return json.Marshal(GuardUnionJSON{
+				Type:   "shape.Regexp",
+				Regexp: body,
+			})
+		},
+		func(y *Between) ([]byte, error) {
+			body, err := BetweenToJSON(y)
+			if err != nil {
+				return nil, fmt.Errorf("shape.GuardToJSON: %w", err)
+			}
+			return json.Marshal(GuardUnionJSON{
+				Type:    "shape.Between",
+				Between: body,
+			})
+		},
+		func(y *MinLength) ([]byte, error) {
+			body, err := MinLengthToJSON(y)
+			if err != nil {
+				return nil, fmt.Errorf("shape.GuardToJSON: %w", err)
+			}
+			return json.Marshal(GuardUnionJSON{
+				Type:      "shape.MinLength",
+				MinLength: body,
+			})
+		},
+		func(y *MaxLength) ([]byte, error) {
+			body, err := MaxLengthToJSON(y)
+			if err != nil {
+				return nil, fmt.Errorf("shape.GuardToJSON: %w", err)
+			}
+			return json.Marshal(GuardUnionJSON{
+				Type:      "shape.MaxLength",
+				MaxLength: body,
+			})
+		},
+		func(y *MinItems) ([]byte, error) {
+			body, err := MinItemsToJSON(y)
+			if err != nil {
+				return nil, fmt.Errorf("shape.GuardToJSON: %w", err)
+			}
+			return json.Marshal(GuardUnionJSON{
+				Type:     "shape.MinItems",
+				MinItems: body,
+			})
+		},
+		func(y *MaxItems) ([]byte, error) {
+			body, err := MaxItemsToJSON(y)
+			if err != nil {
+				return nil, fmt.Errorf("shape.GuardToJSON: %w", err)
+			}
+			return json.Marshal(GuardUnionJSON{
+				Type:     "shape.MaxItems",
+				MaxItems: body,
+			})
+		},
 		func(y *AndGuard) ([]byte, error) {
 			body, err := AndGuardToJSON(y)
 			if err != nil {
@@ -339,7 +921,487 @@ func (r *Required) _unmarshalJSONRequired(data []byte) (Required, error) {
 	var partial map[string]json.RawMessage
 	err := json.Unmarshal(data, &partial)
 	if err != nil {
-		return result, fmt.Errorf("shape: Required._unmarshalJSONRequired: native struct unwrap; %w", err)
+		return result, fmt.Errorf("shape: Required._unmarshalJSONRequired: native struct unwrap; %w", err)
+	}
+	return result, nil
+}
+
+func RegexpFromJSON(x []byte) (*Regexp, error) {
+	result := new(Regexp)
+	err := result.UnmarshalJSON(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape.RegexpFromJSON: %w", err)
+	}
+	return result, nil
+}
+
+func RegexpToJSON(x *Regexp) ([]byte, error) {
+	return x.MarshalJSON()
+}
+
+var (
+	_ json.Unmarshaler = (*Regexp)(nil)
+	_ json.Marshaler   = (*Regexp)(nil)
+)
+
+func (r *Regexp) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return r._marshalJSONRegexp(*r)
+}
+func (r *Regexp) _marshalJSONRegexp(x Regexp) ([]byte, error) {
+	partial := make(map[string]json.RawMessage)
+	var err error
+	var fieldRegexp []byte
+	fieldRegexp, err = r._marshalJSONstring(x.Regexp)
+	if err != nil {
+		return nil, fmt.Errorf("shape: Regexp._marshalJSONRegexp: field name Regexp; %w", err)
+	}
+	partial["Regexp"] = fieldRegexp
+	result, err := json.Marshal(partial)
+	if err != nil {
+		return nil, fmt.Errorf("shape: Regexp._marshalJSONRegexp: struct; %w", err)
+	}
+	return result, nil
+}
+func (r *Regexp) _marshalJSONstring(x string) ([]byte, error) {
+	result, err := json.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: Regexp._marshalJSONstring:; %w", err)
+	}
+	return result, nil
+}
+func (r *Regexp) UnmarshalJSON(data []byte) error {
+	result, err := r._unmarshalJSONRegexp(data)
+	if err != nil {
+		return fmt.Errorf("shape: Regexp.UnmarshalJSON: %w", err)
+	}
+	*r = result
+	return nil
+}
+func (r *Regexp) _unmarshalJSONRegexp(data []byte) (Regexp, error) {
+	result := Regexp{}
+	var partial map[string]json.RawMessage
+	err := json.Unmarshal(data, &partial)
+	if err != nil {
+		return result, fmt.Errorf("shape: Regexp._unmarshalJSONRegexp: native struct unwrap; %w", err)
+	}
+	if fieldRegexp, ok := partial["Regexp"]; ok {
+		result.Regexp, err = r._unmarshalJSONstring(fieldRegexp)
+		if err != nil {
+			return result, fmt.Errorf("shape: Regexp._unmarshalJSONRegexp: field Regexp; %w", err)
+		}
+	}
+	return result, nil
+}
+func (r *Regexp) _unmarshalJSONstring(data []byte) (string, error) {
+	var result string
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return result, fmt.Errorf("shape: Regexp._unmarshalJSONstring: native primitive unwrap; %w", err)
+	}
+	return result, nil
+}
+
+func BetweenFromJSON(x []byte) (*Between, error) {
+	result := new(Between)
+	err := result.UnmarshalJSON(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape.BetweenFromJSON: %w", err)
+	}
+	return result, nil
+}
+
+func BetweenToJSON(x *Between) ([]byte, error) {
+	return x.MarshalJSON()
+}
+
+var (
+	_ json.Unmarshaler = (*Between)(nil)
+	_ json.Marshaler   = (*Between)(nil)
+)
+
+func (r *Between) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return r._marshalJSONBetween(*r)
+}
+func (r *Between) _marshalJSONBetween(x Between) ([]byte, error) {
+	partial := make(map[string]json.RawMessage)
+	var err error
+	var fieldMin []byte
+	fieldMin, err = r._marshalJSONfloat64(x.Min)
+	if err != nil {
+		return nil, fmt.Errorf("shape: Between._marshalJSONBetween: field name Min; %w", err)
+	}
+	partial["Min"] = fieldMin
+	var fieldMax []byte
+	fieldMax, err = r._marshalJSONfloat64(x.Max)
+	if err != nil {
+		return nil, fmt.Errorf("shape: Between._marshalJSONBetween: field name Max; %w", err)
+	}
+	partial["Max"] = fieldMax
+	result, err := json.Marshal(partial)
+	if err != nil {
+		return nil, fmt.Errorf("shape: Between._marshalJSONBetween: struct; %w", err)
+	}
+	return result, nil
+}
+func (r *Between) _marshalJSONfloat64(x float64) ([]byte, error) {
+	result, err := json.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: Between._marshalJSONfloat64:; %w", err)
+	}
+	return result, nil
+}
+func (r *Between) UnmarshalJSON(data []byte) error {
+	result, err := r._unmarshalJSONBetween(data)
+	if err != nil {
+		return fmt.Errorf("shape: Between.UnmarshalJSON: %w", err)
+	}
+	*r = result
+	return nil
+}
+func (r *Between) _unmarshalJSONBetween(data []byte) (Between, error) {
+	result := Between{}
+	var partial map[string]json.RawMessage
+	err := json.Unmarshal(data, &partial)
+	if err != nil {
+		return result, fmt.Errorf("shape: Between._unmarshalJSONBetween: native struct unwrap; %w", err)
+	}
+	if fieldMin, ok := partial["Min"]; ok {
+		result.Min, err = r._unmarshalJSONfloat64(fieldMin)
+		if err != nil {
+			return result, fmt.Errorf("shape: Between._unmarshalJSONBetween: field Min; %w", err)
+		}
+	}
+	if fieldMax, ok := partial["Max"]; ok {
+		result.Max, err = r._unmarshalJSONfloat64(fieldMax)
+		if err != nil {
+			return result, fmt.Errorf("shape: Between._unmarshalJSONBetween: field Max; %w", err)
+		}
+	}
+	return result, nil
+}
+func (r *Between) _unmarshalJSONfloat64(data []byte) (float64, error) {
+	var result float64
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return result, fmt.Errorf("shape: Between._unmarshalJSONfloat64: native primitive unwrap; %w", err)
+	}
+	return result, nil
+}
+
+func MinLengthFromJSON(x []byte) (*MinLength, error) {
+	result := new(MinLength)
+	err := result.UnmarshalJSON(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape.MinLengthFromJSON: %w", err)
+	}
+	return result, nil
+}
+
+func MinLengthToJSON(x *MinLength) ([]byte, error) {
+	return x.MarshalJSON()
+}
+
+var (
+	_ json.Unmarshaler = (*MinLength)(nil)
+	_ json.Marshaler   = (*MinLength)(nil)
+)
+
+func (r *MinLength) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return r._marshalJSONMinLength(*r)
+}
+func (r *MinLength) _marshalJSONMinLength(x MinLength) ([]byte, error) {
+	partial := make(map[string]json.RawMessage)
+	var err error
+	var fieldLen []byte
+	fieldLen, err = r._marshalJSONint(x.Len)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MinLength._marshalJSONMinLength: field name Len; %w", err)
+	}
+	partial["Len"] = fieldLen
+	result, err := json.Marshal(partial)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MinLength._marshalJSONMinLength: struct; %w", err)
+	}
+	return result, nil
+}
+func (r *MinLength) _marshalJSONint(x int) ([]byte, error) {
+	result, err := json.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MinLength._marshalJSONint:; %w", err)
+	}
+	return result, nil
+}
+func (r *MinLength) UnmarshalJSON(data []byte) error {
+	result, err := r._unmarshalJSONMinLength(data)
+	if err != nil {
+		return fmt.Errorf("shape: MinLength.UnmarshalJSON: %w", err)
+	}
+	*r = result
+	return nil
+}
+func (r *MinLength) _unmarshalJSONMinLength(data []byte) (MinLength, error) {
+	result := MinLength{}
+	var partial map[string]json.RawMessage
+	err := json.Unmarshal(data, &partial)
+	if err != nil {
+		return result, fmt.Errorf("shape: MinLength._unmarshalJSONMinLength: native struct unwrap; %w", err)
+	}
+	if fieldLen, ok := partial["Len"]; ok {
+		result.Len, err = r._unmarshalJSONint(fieldLen)
+		if err != nil {
+			return result, fmt.Errorf("shape: MinLength._unmarshalJSONMinLength: field Len; %w", err)
+		}
+	}
+	return result, nil
+}
+func (r *MinLength) _unmarshalJSONint(data []byte) (int, error) {
+	var result int
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return result, fmt.Errorf("shape: MinLength._unmarshalJSONint: native primitive unwrap; %w", err)
+	}
+	return result, nil
+}
+
+func MaxLengthFromJSON(x []byte) (*MaxLength, error) {
+	result := new(MaxLength)
+	err := result.UnmarshalJSON(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape.MaxLengthFromJSON: %w", err)
+	}
+	return result, nil
+}
+
+func MaxLengthToJSON(x *MaxLength) ([]byte, error) {
+	return x.MarshalJSON()
+}
+
+var (
+	_ json.Unmarshaler = (*MaxLength)(nil)
+	_ json.Marshaler   = (*MaxLength)(nil)
+)
+
+func (r *MaxLength) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return r._marshalJSONMaxLength(*r)
+}
+func (r *MaxLength) _marshalJSONMaxLength(x MaxLength) ([]byte, error) {
+	partial := make(map[string]json.RawMessage)
+	var err error
+	var fieldLen []byte
+	fieldLen, err = r._marshalJSONint(x.Len)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MaxLength._marshalJSONMaxLength: field name Len; %w", err)
+	}
+	partial["Len"] = fieldLen
+	result, err := json.Marshal(partial)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MaxLength._marshalJSONMaxLength: struct; %w", err)
+	}
+	return result, nil
+}
+func (r *MaxLength) _marshalJSONint(x int) ([]byte, error) {
+	result, err := json.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MaxLength._marshalJSONint:; %w", err)
+	}
+	return result, nil
+}
+func (r *MaxLength) UnmarshalJSON(data []byte) error {
+	result, err := r._unmarshalJSONMaxLength(data)
+	if err != nil {
+		return fmt.Errorf("shape: MaxLength.UnmarshalJSON: %w", err)
+	}
+	*r = result
+	return nil
+}
+func (r *MaxLength) _unmarshalJSONMaxLength(data []byte) (MaxLength, error) {
+	result := MaxLength{}
+	var partial map[string]json.RawMessage
+	err := json.Unmarshal(data, &partial)
+	if err != nil {
+		return result, fmt.Errorf("shape: MaxLength._unmarshalJSONMaxLength: native struct unwrap; %w", err)
+	}
+	if fieldLen, ok := partial["Len"]; ok {
+		result.Len, err = r._unmarshalJSONint(fieldLen)
+		if err != nil {
+			return result, fmt.Errorf("shape: MaxLength._unmarshalJSONMaxLength: field Len; %w", err)
+		}
+	}
+	return result, nil
+}
+func (r *MaxLength) _unmarshalJSONint(data []byte) (int, error) {
+	var result int
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return result, fmt.Errorf("shape: MaxLength._unmarshalJSONint: native primitive unwrap; %w", err)
+	}
+	return result, nil
+}
+
+func MinItemsFromJSON(x []byte) (*MinItems, error) {
+	result := new(MinItems)
+	err := result.UnmarshalJSON(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape.MinItemsFromJSON: %w", err)
+	}
+	return result, nil
+}
+
+func MinItemsToJSON(x *MinItems) ([]byte, error) {
+	return x.MarshalJSON()
+}
+
+var (
+	_ json.Unmarshaler = (*MinItems)(nil)
+	_ json.Marshaler   = (*MinItems)(nil)
+)
+
+func (r *MinItems) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return r._marshalJSONMinItems(*r)
+}
+func (r *MinItems) _marshalJSONMinItems(x MinItems) ([]byte, error) {
+	partial := make(map[string]json.RawMessage)
+	var err error
+	var fieldLen []byte
+	fieldLen, err = r._marshalJSONint(x.Len)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MinItems._marshalJSONMinItems: field name Len; %w", err)
+	}
+	partial["Len"] = fieldLen
+	result, err := json.Marshal(partial)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MinItems._marshalJSONMinItems: struct; %w", err)
+	}
+	return result, nil
+}
+func (r *MinItems) _marshalJSONint(x int) ([]byte, error) {
+	result, err := json.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MinItems._marshalJSONint:; %w", err)
+	}
+	return result, nil
+}
+func (r *MinItems) UnmarshalJSON(data []byte) error {
+	result, err := r._unmarshalJSONMinItems(data)
+	if err != nil {
+		return fmt.Errorf("shape: MinItems.UnmarshalJSON: %w", err)
+	}
+	*r = result
+	return nil
+}
+func (r *MinItems) _unmarshalJSONMinItems(data []byte) (MinItems, error) {
+	result := MinItems{}
+	var partial map[string]json.RawMessage
+	err := json.Unmarshal(data, &partial)
+	if err != nil {
+		return result, fmt.Errorf("shape: MinItems._unmarshalJSONMinItems: native struct unwrap; %w", err)
+	}
+	if fieldLen, ok := partial["Len"]; ok {
+		result.Len, err = r._unmarshalJSONint(fieldLen)
+		if err != nil {
+			return result, fmt.Errorf("shape: MinItems._unmarshalJSONMinItems: field Len; %w", err)
+		}
+	}
+	return result, nil
+}
+func (r *MinItems) _unmarshalJSONint(data []byte) (int, error) {
+	var result int
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return result, fmt.Errorf("shape: MinItems._unmarshalJSONint: native primitive unwrap; %w", err)
+	}
+	return result, nil
+}
+
+func MaxItemsFromJSON(x []byte) (*MaxItems, error) {
+	result := new(MaxItems)
+	err := result.UnmarshalJSON(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape.MaxItemsFromJSON: %w", err)
+	}
+	return result, nil
+}
+
+func MaxItemsToJSON(x *MaxItems) ([]byte, error) {
+	return x.MarshalJSON()
+}
+
+var (
+	_ json.Unmarshaler = (*MaxItems)(nil)
+	_ json.Marshaler   = (*MaxItems)(nil)
+)
+
+func (r *MaxItems) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return r._marshalJSONMaxItems(*r)
+}
+func (r *MaxItems) _marshalJSONMaxItems(x MaxItems) ([]byte, error) {
+	partial := make(map[string]json.RawMessage)
+	var err error
+	var fieldLen []byte
+	fieldLen, err = r._marshalJSONint(x.Len)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MaxItems._marshalJSONMaxItems: field name Len; %w", err)
+	}
+	partial["Len"] = fieldLen
+	result, err := json.Marshal(partial)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MaxItems._marshalJSONMaxItems: struct; %w", err)
+	}
+	return result, nil
+}
+func (r *MaxItems) _marshalJSONint(x int) ([]byte, error) {
+	result, err := json.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("shape: MaxItems._marshalJSONint:; %w", err)
+	}
+	return result, nil
+}
+func (r *MaxItems) UnmarshalJSON(data []byte) error {
+	result, err := r._unmarshalJSONMaxItems(data)
+	if err != nil {
+		return fmt.Errorf("shape: MaxItems.UnmarshalJSON: %w", err)
+	}
+	*r = result
+	return nil
+}
+func (r *MaxItems) _unmarshalJSONMaxItems(data []byte) (MaxItems, error) {
+	result := MaxItems{}
+	var partial map[string]json.RawMessage
+	err := json.Unmarshal(data, &partial)
+	if err != nil {
+		return result, fmt.Errorf("shape: MaxItems._unmarshalJSONMaxItems: native struct unwrap; %w", err)
+	}
+	if fieldLen, ok := partial["Len"]; ok {
+		result.Len, err = r._unmarshalJSONint(fieldLen)
+		if err != nil {
+			return result, fmt.Errorf("shape: MaxItems._unmarshalJSONMaxItems: field Len; %w", err)
+		}
+	}
+	return result, nil
+}
+func (r *MaxItems) _unmarshalJSONint(data []byte) (int, error) {
+	var result int
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return result, fmt.Errorf("shape: MaxItems._unmarshalJSONint: native primitive unwrap; %w", err)
 	}
 	return result, nil
 }
@@ -679,6 +1741,391 @@ func MatchNumberKindR0(
 		f12(v)
 	}
 }
+
+// NumberKindEqual returns true, when a and b are the same variant of NumberKind with equal fields.
+func NumberKindEqual(a, b NumberKind) bool {
+	switch x := a.(type) {
+	case *UInt:
+		y, ok := b.(*UInt)
+		return ok && x.Equal(y)
+	case *UInt8:
+		y, ok := b.(*UInt8)
+		return ok && x.Equal(y)
+	case *UInt16:
+		y, ok := b.(*UInt16)
+		return ok && x.Equal(y)
+	case *UInt32:
+		y, ok := b.(*UInt32)
+		return ok && x.Equal(y)
+	case *UInt64:
+		y, ok := b.(*UInt64)
+		return ok && x.Equal(y)
+	case *Int:
+		y, ok := b.(*Int)
+		return ok && x.Equal(y)
+	case *Int8:
+		y, ok := b.(*Int8)
+		return ok && x.Equal(y)
+	case *Int16:
+		y, ok := b.(*Int16)
+		return ok && x.Equal(y)
+	case *Int32:
+		y, ok := b.(*Int32)
+		return ok && x.Equal(y)
+	case *Int64:
+		y, ok := b.(*Int64)
+		return ok && x.Equal(y)
+	case *Float32:
+		y, ok := b.(*Float32)
+		return ok && x.Equal(y)
+	case *Float64:
+		y, ok := b.(*Float64)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// NumberKindHash returns hash of x, that is the same for values equal according to NumberKindEqual.
+func NumberKindHash(x NumberKind) uint64 {
+	switch y := x.(type) {
+	case *UInt:
+		return y.Hash()
+	case *UInt8:
+		return y.Hash()
+	case *UInt16:
+		return y.Hash()
+	case *UInt32:
+		return y.Hash()
+	case *UInt64:
+		return y.Hash()
+	case *Int:
+		return y.Hash()
+	case *Int8:
+		return y.Hash()
+	case *Int16:
+		return y.Hash()
+	case *Int32:
+		return y.Hash()
+	case *Int64:
+		return y.Hash()
+	case *Float32:
+		return y.Hash()
+	case *Float64:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// NumberKindDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func NumberKindDeepCopy(x NumberKind) NumberKind {
+	switch y := x.(type) {
+	case *UInt:
+		return y.DeepCopy()
+	case *UInt8:
+		return y.DeepCopy()
+	case *UInt16:
+		return y.DeepCopy()
+	case *UInt32:
+		return y.DeepCopy()
+	case *UInt64:
+		return y.DeepCopy()
+	case *Int:
+		return y.DeepCopy()
+	case *Int8:
+		return y.DeepCopy()
+	case *Int16:
+		return y.DeepCopy()
+	case *Int32:
+		return y.DeepCopy()
+	case *Int64:
+		return y.DeepCopy()
+	case *Float32:
+		return y.DeepCopy()
+	case *Float64:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *UInt) Equal(other *UInt) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *UInt) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.UInt")
+	return h
+}
+
+func (r *UInt) DeepCopy() *UInt {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *UInt8) Equal(other *UInt8) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *UInt8) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.UInt8")
+	return h
+}
+
+func (r *UInt8) DeepCopy() *UInt8 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *UInt16) Equal(other *UInt16) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *UInt16) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.UInt16")
+	return h
+}
+
+func (r *UInt16) DeepCopy() *UInt16 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *UInt32) Equal(other *UInt32) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *UInt32) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.UInt32")
+	return h
+}
+
+func (r *UInt32) DeepCopy() *UInt32 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *UInt64) Equal(other *UInt64) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *UInt64) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.UInt64")
+	return h
+}
+
+func (r *UInt64) DeepCopy() *UInt64 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Int) Equal(other *Int) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Int) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Int")
+	return h
+}
+
+func (r *Int) DeepCopy() *Int {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Int8) Equal(other *Int8) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Int8) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Int8")
+	return h
+}
+
+func (r *Int8) DeepCopy() *Int8 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Int16) Equal(other *Int16) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Int16) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Int16")
+	return h
+}
+
+func (r *Int16) DeepCopy() *Int16 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Int32) Equal(other *Int32) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Int32) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Int32")
+	return h
+}
+
+func (r *Int32) DeepCopy() *Int32 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Int64) Equal(other *Int64) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Int64) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Int64")
+	return h
+}
+
+func (r *Int64) DeepCopy() *Int64 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Float32) Equal(other *Float32) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Float32) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Float32")
+	return h
+}
+
+func (r *Float32) DeepCopy() *Float32 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Float64) Equal(other *Float64) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Float64) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Float64")
+	return h
+}
+
+func (r *Float64) DeepCopy() *Float64 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.Float32", Float32FromJSON, Float32ToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.Float64", Float64FromJSON, Float64ToJSON)
@@ -715,7 +2162,7 @@ func NumberKindFromJSON(x []byte) (NumberKind, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data NumberKindUnionJSON
@@ -1593,25 +3040,145 @@ func MatchPrimitiveKindR1[T0 any](
 	case *NumberLike:
 		return f3(v)
 	}
-	var result1 T0
-	return result1
+	var result1 T0
+	return result1
+}
+
+func MatchPrimitiveKindR0(
+	x PrimitiveKind,
+	f1 func(x *BooleanLike),
+	f2 func(x *StringLike),
+	f3 func(x *NumberLike),
+) {
+	switch v := x.(type) {
+	case *BooleanLike:
+		f1(v)
+	case *StringLike:
+		f2(v)
+	case *NumberLike:
+		f3(v)
+	}
+}
+
+// PrimitiveKindEqual returns true, when a and b are the same variant of PrimitiveKind with equal fields.
+func PrimitiveKindEqual(a, b PrimitiveKind) bool {
+	switch x := a.(type) {
+	case *BooleanLike:
+		y, ok := b.(*BooleanLike)
+		return ok && x.Equal(y)
+	case *StringLike:
+		y, ok := b.(*StringLike)
+		return ok && x.Equal(y)
+	case *NumberLike:
+		y, ok := b.(*NumberLike)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// PrimitiveKindHash returns hash of x, that is the same for values equal according to PrimitiveKindEqual.
+func PrimitiveKindHash(x PrimitiveKind) uint64 {
+	switch y := x.(type) {
+	case *BooleanLike:
+		return y.Hash()
+	case *StringLike:
+		return y.Hash()
+	case *NumberLike:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// PrimitiveKindDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func PrimitiveKindDeepCopy(x PrimitiveKind) PrimitiveKind {
+	switch y := x.(type) {
+	case *BooleanLike:
+		return y.DeepCopy()
+	case *StringLike:
+		return y.DeepCopy()
+	case *NumberLike:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *BooleanLike) Equal(other *BooleanLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *BooleanLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.BooleanLike")
+	return h
+}
+
+func (r *BooleanLike) DeepCopy() *BooleanLike {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *StringLike) Equal(other *StringLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *StringLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.StringLike")
+	return h
+}
+
+func (r *StringLike) DeepCopy() *StringLike {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *NumberLike) Equal(other *NumberLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !NumberKindEqual(r.Kind, other.Kind) {
+		return false
+	}
+	return true
+}
+
+func (r *NumberLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.NumberLike")
+	h = shared.HashUint64(h, NumberKindHash(r.Kind))
+	return h
 }
 
-func MatchPrimitiveKindR0(
-	x PrimitiveKind,
-	f1 func(x *BooleanLike),
-	f2 func(x *StringLike),
-	f3 func(x *NumberLike),
-) {
-	switch v := x.(type) {
-	case *BooleanLike:
-		f1(v)
-	case *StringLike:
-		f2(v)
-	case *NumberLike:
-		f3(v)
+func (r *NumberLike) DeepCopy() *NumberLike {
+	if r == nil {
+		return nil
 	}
+	result := *r
+	result.Kind = NumberKindDeepCopy(r.Kind)
+	return &result
 }
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.BooleanLike", BooleanLikeFromJSON, BooleanLikeToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.NumberLike", NumberLikeFromJSON, NumberLikeToJSON)
@@ -1630,7 +3197,7 @@ func PrimitiveKindFromJSON(x []byte) (PrimitiveKind, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data PrimitiveKindUnionJSON
@@ -2058,6 +3625,653 @@ func MatchShapeR0(
 		f9(v)
 	}
 }
+
+// RewriteShapeTopDown applies f to x, and then to each Shape nested in result of f (pre-order).
+// When f returns nil, nested values are not visited.
+func RewriteShapeTopDown(x Shape, f func(Shape) Shape) Shape {
+	if x == nil {
+		return nil
+	}
+	return rewriteShapeChildren(f(x), func(y Shape) Shape {
+		return RewriteShapeTopDown(y, f)
+	})
+}
+
+// RewriteShapeBottomUp applies f to each Shape nested in x, and then to x with already rewritten values (post-order).
+func RewriteShapeBottomUp(x Shape, f func(Shape) Shape) Shape {
+	if x == nil {
+		return nil
+	}
+	return f(rewriteShapeChildren(x, func(y Shape) Shape {
+		return RewriteShapeBottomUp(y, f)
+	}))
+}
+
+// rewriteShapeChildren returns shallow copy of x, where each Shape nested in x is replaced by result of f.
+// Variants without nested Shape are returned as they are, and x is never modified.
+func rewriteShapeChildren(x Shape, f func(Shape) Shape) Shape {
+	switch v := x.(type) {
+	case *RefName:
+		result := *v
+		if v.Indexed != nil {
+			result.Indexed = make([]Shape, len(v.Indexed))
+			for i1 := range v.Indexed {
+				result.Indexed[i1] = f(v.Indexed[i1])
+			}
+		}
+		return &result
+	case *PointerLike:
+		result := *v
+		result.Type = f(v.Type)
+		return &result
+	case *AliasLike:
+		result := *v
+		result.Type = f(v.Type)
+		return &result
+	case *ListLike:
+		result := *v
+		result.Element = f(v.Element)
+		return &result
+	case *MapLike:
+		result := *v
+		result.Key = f(v.Key)
+		result.Val = f(v.Val)
+		return &result
+	case *UnionLike:
+		result := *v
+		if v.Variant != nil {
+			result.Variant = make([]Shape, len(v.Variant))
+			for i1 := range v.Variant {
+				result.Variant[i1] = f(v.Variant[i1])
+			}
+		}
+		return &result
+	}
+
+	return x
+}
+
+// ShapeEqual returns true, when a and b are the same variant of Shape with equal fields.
+func ShapeEqual(a, b Shape) bool {
+	switch x := a.(type) {
+	case *Any:
+		y, ok := b.(*Any)
+		return ok && x.Equal(y)
+	case *RefName:
+		y, ok := b.(*RefName)
+		return ok && x.Equal(y)
+	case *PointerLike:
+		y, ok := b.(*PointerLike)
+		return ok && x.Equal(y)
+	case *AliasLike:
+		y, ok := b.(*AliasLike)
+		return ok && x.Equal(y)
+	case *PrimitiveLike:
+		y, ok := b.(*PrimitiveLike)
+		return ok && x.Equal(y)
+	case *ListLike:
+		y, ok := b.(*ListLike)
+		return ok && x.Equal(y)
+	case *MapLike:
+		y, ok := b.(*MapLike)
+		return ok && x.Equal(y)
+	case *StructLike:
+		y, ok := b.(*StructLike)
+		return ok && x.Equal(y)
+	case *UnionLike:
+		y, ok := b.(*UnionLike)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// ShapeHash returns hash of x, that is the same for values equal according to ShapeEqual.
+func ShapeHash(x Shape) uint64 {
+	switch y := x.(type) {
+	case *Any:
+		return y.Hash()
+	case *RefName:
+		return y.Hash()
+	case *PointerLike:
+		return y.Hash()
+	case *AliasLike:
+		return y.Hash()
+	case *PrimitiveLike:
+		return y.Hash()
+	case *ListLike:
+		return y.Hash()
+	case *MapLike:
+		return y.Hash()
+	case *StructLike:
+		return y.Hash()
+	case *UnionLike:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// ShapeDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func ShapeDeepCopy(x Shape) Shape {
+	switch y := x.(type) {
+	case *Any:
+		return y.DeepCopy()
+	case *RefName:
+		return y.DeepCopy()
+	case *PointerLike:
+		return y.DeepCopy()
+	case *AliasLike:
+		return y.DeepCopy()
+	case *PrimitiveLike:
+		return y.DeepCopy()
+	case *ListLike:
+		return y.DeepCopy()
+	case *MapLike:
+		return y.DeepCopy()
+	case *StructLike:
+		return y.DeepCopy()
+	case *UnionLike:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Any) Equal(other *Any) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Any) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.Any")
+	return h
+}
+
+func (r *Any) DeepCopy() *Any {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *RefName) Equal(other *RefName) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if r.PkgName != other.PkgName {
+		return false
+	}
+	if r.PkgImportName != other.PkgImportName {
+		return false
+	}
+	if len(r.Indexed) != len(other.Indexed) {
+		return false
+	}
+	for i1 := range r.Indexed {
+		if !ShapeEqual(r.Indexed[i1], other.Indexed[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *RefName) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.RefName")
+	h = shared.HashString(h, r.Name)
+	h = shared.HashString(h, r.PkgName)
+	h = shared.HashString(h, r.PkgImportName)
+	h = shared.HashUint64(h, uint64(len(r.Indexed)))
+	for _, v1 := range r.Indexed {
+		h = shared.HashUint64(h, ShapeHash(v1))
+	}
+	return h
+}
+
+func (r *RefName) DeepCopy() *RefName {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Indexed != nil {
+		result.Indexed = make([]Shape, len(r.Indexed))
+		for i1 := range r.Indexed {
+			result.Indexed[i1] = ShapeDeepCopy(r.Indexed[i1])
+		}
+	}
+	return &result
+}
+
+func (r *PointerLike) Equal(other *PointerLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !ShapeEqual(r.Type, other.Type) {
+		return false
+	}
+	return true
+}
+
+func (r *PointerLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.PointerLike")
+	h = shared.HashUint64(h, ShapeHash(r.Type))
+	return h
+}
+
+func (r *PointerLike) DeepCopy() *PointerLike {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Type = ShapeDeepCopy(r.Type)
+	return &result
+}
+
+func (r *AliasLike) Equal(other *AliasLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if r.PkgName != other.PkgName {
+		return false
+	}
+	if r.PkgImportName != other.PkgImportName {
+		return false
+	}
+	if len(r.TypeParams) != len(other.TypeParams) {
+		return false
+	}
+	for i1 := range r.TypeParams {
+		if !r.TypeParams[i1].Equal(&other.TypeParams[i1]) {
+			return false
+		}
+	}
+	if r.IsAlias != other.IsAlias {
+		return false
+	}
+	if !ShapeEqual(r.Type, other.Type) {
+		return false
+	}
+	if len(r.Tags) != len(other.Tags) {
+		return false
+	}
+	for k2, v2 := range r.Tags {
+		w2, ok := other.Tags[k2]
+		if !ok {
+			return false
+		}
+		if !shared.Equal(v2, w2) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *AliasLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.AliasLike")
+	h = shared.HashString(h, r.Name)
+	h = shared.HashString(h, r.PkgName)
+	h = shared.HashString(h, r.PkgImportName)
+	h = shared.HashUint64(h, uint64(len(r.TypeParams)))
+	for _, v1 := range r.TypeParams {
+		h = shared.HashUint64(h, v1.Hash())
+	}
+	h = shared.HashBool(h, r.IsAlias)
+	h = shared.HashUint64(h, ShapeHash(r.Type))
+	h = shared.HashUint64(h, uint64(len(r.Tags)))
+	var s2 uint64
+	for k2, v2 := range r.Tags {
+		e2 := shared.HashOffset
+		e2 = shared.HashString(e2, k2)
+		e2 = shared.Hash(e2, v2)
+		s2 += e2
+	}
+	h = shared.HashUint64(h, s2)
+	return h
+}
+
+func (r *AliasLike) DeepCopy() *AliasLike {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.TypeParams != nil {
+		result.TypeParams = make([]TypeParam, len(r.TypeParams))
+		for i1 := range r.TypeParams {
+			result.TypeParams[i1] = *r.TypeParams[i1].DeepCopy()
+		}
+	}
+	result.Type = ShapeDeepCopy(r.Type)
+	if r.Tags != nil {
+		result.Tags = make(map[string]Tag, len(r.Tags))
+		for k2, v2 := range r.Tags {
+			result.Tags[k2] = shared.DeepCopy(v2)
+		}
+	}
+	return &result
+}
+
+func (r *PrimitiveLike) Equal(other *PrimitiveLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !PrimitiveKindEqual(r.Kind, other.Kind) {
+		return false
+	}
+	return true
+}
+
+func (r *PrimitiveLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.PrimitiveLike")
+	h = shared.HashUint64(h, PrimitiveKindHash(r.Kind))
+	return h
+}
+
+func (r *PrimitiveLike) DeepCopy() *PrimitiveLike {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Kind = PrimitiveKindDeepCopy(r.Kind)
+	return &result
+}
+
+func (r *ListLike) Equal(other *ListLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !ShapeEqual(r.Element, other.Element) {
+		return false
+	}
+	if (r.ArrayLen == nil) != (other.ArrayLen == nil) {
+		return false
+	}
+	if r.ArrayLen != nil {
+		if *r.ArrayLen != *other.ArrayLen {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *ListLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.ListLike")
+	h = shared.HashUint64(h, ShapeHash(r.Element))
+	h = shared.HashBool(h, r.ArrayLen != nil)
+	if r.ArrayLen != nil {
+		h = shared.HashUint64(h, uint64(*r.ArrayLen))
+	}
+	return h
+}
+
+func (r *ListLike) DeepCopy() *ListLike {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Element = ShapeDeepCopy(r.Element)
+	if r.ArrayLen != nil {
+		r1 := *r.ArrayLen
+		result.ArrayLen = &r1
+	}
+	return &result
+}
+
+func (r *MapLike) Equal(other *MapLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !ShapeEqual(r.Key, other.Key) {
+		return false
+	}
+	if !ShapeEqual(r.Val, other.Val) {
+		return false
+	}
+	return true
+}
+
+func (r *MapLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.MapLike")
+	h = shared.HashUint64(h, ShapeHash(r.Key))
+	h = shared.HashUint64(h, ShapeHash(r.Val))
+	return h
+}
+
+func (r *MapLike) DeepCopy() *MapLike {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Key = ShapeDeepCopy(r.Key)
+	result.Val = ShapeDeepCopy(r.Val)
+	return &result
+}
+
+func (r *StructLike) Equal(other *StructLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if r.PkgName != other.PkgName {
+		return false
+	}
+	if r.PkgImportName != other.PkgImportName {
+		return false
+	}
+	if len(r.TypeParams) != len(other.TypeParams) {
+		return false
+	}
+	for i1 := range r.TypeParams {
+		if !r.TypeParams[i1].Equal(&other.TypeParams[i1]) {
+			return false
+		}
+	}
+	if len(r.Fields) != len(other.Fields) {
+		return false
+	}
+	for i2 := range r.Fields {
+		if !r.Fields[i2].Equal(other.Fields[i2]) {
+			return false
+		}
+	}
+	if len(r.Tags) != len(other.Tags) {
+		return false
+	}
+	for k3, v3 := range r.Tags {
+		w3, ok := other.Tags[k3]
+		if !ok {
+			return false
+		}
+		if !shared.Equal(v3, w3) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *StructLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.StructLike")
+	h = shared.HashString(h, r.Name)
+	h = shared.HashString(h, r.PkgName)
+	h = shared.HashString(h, r.PkgImportName)
+	h = shared.HashUint64(h, uint64(len(r.TypeParams)))
+	for _, v1 := range r.TypeParams {
+		h = shared.HashUint64(h, v1.Hash())
+	}
+	h = shared.HashUint64(h, uint64(len(r.Fields)))
+	for _, v2 := range r.Fields {
+		h = shared.HashUint64(h, v2.Hash())
+	}
+	h = shared.HashUint64(h, uint64(len(r.Tags)))
+	var s3 uint64
+	for k3, v3 := range r.Tags {
+		e3 := shared.HashOffset
+		e3 = shared.HashString(e3, k3)
+		e3 = shared.Hash(e3, v3)
+		s3 += e3
+	}
+	h = shared.HashUint64(h, s3)
+	return h
+}
+
+func (r *StructLike) DeepCopy() *StructLike {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.TypeParams != nil {
+		result.TypeParams = make([]TypeParam, len(r.TypeParams))
+		for i1 := range r.TypeParams {
+			result.TypeParams[i1] = *r.TypeParams[i1].DeepCopy()
+		}
+	}
+	if r.Fields != nil {
+		result.Fields = make([]*FieldLike, len(r.Fields))
+		for i2 := range r.Fields {
+			result.Fields[i2] = r.Fields[i2].DeepCopy()
+		}
+	}
+	if r.Tags != nil {
+		result.Tags = make(map[string]Tag, len(r.Tags))
+		for k3, v3 := range r.Tags {
+			result.Tags[k3] = shared.DeepCopy(v3)
+		}
+	}
+	return &result
+}
+
+func (r *UnionLike) Equal(other *UnionLike) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if r.PkgName != other.PkgName {
+		return false
+	}
+	if r.PkgImportName != other.PkgImportName {
+		return false
+	}
+	if len(r.TypeParams) != len(other.TypeParams) {
+		return false
+	}
+	for i1 := range r.TypeParams {
+		if !r.TypeParams[i1].Equal(&other.TypeParams[i1]) {
+			return false
+		}
+	}
+	if len(r.Variant) != len(other.Variant) {
+		return false
+	}
+	for i2 := range r.Variant {
+		if !ShapeEqual(r.Variant[i2], other.Variant[i2]) {
+			return false
+		}
+	}
+	if len(r.Tags) != len(other.Tags) {
+		return false
+	}
+	for k3, v3 := range r.Tags {
+		w3, ok := other.Tags[k3]
+		if !ok {
+			return false
+		}
+		if !shared.Equal(v3, w3) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *UnionLike) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "shape.UnionLike")
+	h = shared.HashString(h, r.Name)
+	h = shared.HashString(h, r.PkgName)
+	h = shared.HashString(h, r.PkgImportName)
+	h = shared.HashUint64(h, uint64(len(r.TypeParams)))
+	for _, v1 := range r.TypeParams {
+		h = shared.HashUint64(h, v1.Hash())
+	}
+	h = shared.HashUint64(h, uint64(len(r.Variant)))
+	for _, v2 := range r.Variant {
+		h = shared.HashUint64(h, ShapeHash(v2))
+	}
+	h = shared.HashUint64(h, uint64(len(r.Tags)))
+	var s3 uint64
+	for k3, v3 := range r.Tags {
+		e3 := shared.HashOffset
+		e3 = shared.HashString(e3, k3)
+		e3 = shared.Hash(e3, v3)
+		s3 += e3
+	}
+	h = shared.HashUint64(h, s3)
+	return h
+}
+
+func (r *UnionLike) DeepCopy() *UnionLike {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.TypeParams != nil {
+		result.TypeParams = make([]TypeParam, len(r.TypeParams))
+		for i1 := range r.TypeParams {
+			result.TypeParams[i1] = *r.TypeParams[i1].DeepCopy()
+		}
+	}
+	if r.Variant != nil {
+		result.Variant = make([]Shape, len(r.Variant))
+		for i2 := range r.Variant {
+			result.Variant[i2] = ShapeDeepCopy(r.Variant[i2])
+		}
+	}
+	if r.Tags != nil {
+		result.Tags = make(map[string]Tag, len(r.Tags))
+		for k3, v3 := range r.Tags {
+			result.Tags[k3] = shared.DeepCopy(v3)
+		}
+	}
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.AliasLike", AliasLikeFromJSON, AliasLikeToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/shape.Any", AnyFromJSON, AnyToJSON)
@@ -2088,7 +4302,7 @@ func ShapeFromJSON(x []byte) (Shape, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data ShapeUnionJSON
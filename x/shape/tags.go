@@ -1,7 +1,11 @@
 package shape
 
 import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
 	"go/ast"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/widmogrod/mkunion/x/shared"
@@ -108,6 +112,12 @@ func ExtractTags(tag string) map[string]Tag {
 		remaining = nextRemaining
 	}
 
+	// regular expression is taken as is, since splitting it into options would drop spaces and empty parts,
+	// like in "^a, b$" or "a{1,}"
+	if pattern, ok := reflect.StructTag(tag).Lookup("regexp"); ok && pattern != "" {
+		result["regexp"] = Tag{Value: pattern}
+	}
+
 	if len(result) == 0 {
 		return nil
 	}
@@ -153,7 +163,10 @@ func extractTagWithBrackets(key, valueString string) (Tag, string) {
 			optEnd := strings.Index(valueString[endIdx:], "\"")
 			if optEnd != -1 {
 				optStr := valueString[endIdx+1 : endIdx+optEnd]
-				if optStr != "" {
+				if optStr == "" {
+					// trailing comma is kept as empty option, because `json:"-,"` names field "-", and `json:"-"` skips it
+					options = []string{""}
+				} else {
 					opts := strings.Split(optStr, ",")
 					for _, opt := range opts {
 						opt = strings.TrimSpace(opt)
@@ -290,10 +303,72 @@ func TagsToGuard(tags map[string]Tag) Guard {
 	if required, ok := tags["required"]; ok && required.Value == "true" {
 		result = ConcatGuard(result, &Required{})
 	}
+	if regexp, ok := tags["regexp"]; ok {
+		result = ConcatGuard(result, &Regexp{
+			Regexp: regexp.Value,
+		})
+	}
+	if between, ok := tags["between"]; ok {
+		if guard, err := tagToBetween(between); err != nil {
+			log.Warnf("shape.TagsToGuard: between %q is skipped; %s", between.Value, err)
+		} else {
+			result = ConcatGuard(result, guard)
+		}
+	}
+
+	for _, name := range []string{"minLength", "maxLength", "minItems", "maxItems"} {
+		tag, ok := tags[name]
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.Atoi(tag.Value)
+		if err != nil || n < 0 {
+			log.Warnf("shape.TagsToGuard: %s %q is skipped, expected non negative integer", name, tag.Value)
+			continue
+		}
+
+		switch name {
+		case "minLength":
+			result = ConcatGuard(result, &MinLength{Len: n})
+		case "maxLength":
+			result = ConcatGuard(result, &MaxLength{Len: n})
+		case "minItems":
+			result = ConcatGuard(result, &MinItems{Len: n})
+		case "maxItems":
+			result = ConcatGuard(result, &MaxItems{Len: n})
+		}
+	}
 
 	return result
 }
 
+// tagToBetween parses tag in format between:"min,max", where both ends are inclusive.
+func tagToBetween(tag Tag) (*Between, error) {
+	if len(tag.Options) != 1 {
+		return nil, fmt.Errorf("expected format min,max")
+	}
+
+	minimum, err := strconv.ParseFloat(tag.Value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("min; %w", err)
+	}
+
+	maximum, err := strconv.ParseFloat(tag.Options[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("max; %w", err)
+	}
+
+	if minimum > maximum {
+		return nil, fmt.Errorf("min %v is greater than max %v", minimum, maximum)
+	}
+
+	return &Between{
+		Min: minimum,
+		Max: maximum,
+	}, nil
+}
+
 func TagsToDesc(tags map[string]Tag) *string {
 	if desc, ok := tags["desc"]; ok {
 		// because tags are parsed according to the spec, we need to normalize options
@@ -35,6 +35,13 @@ func TestExtractTags(t *testing.T) {
 				"mkmatch": {Value: "-", Options: nil},
 			},
 		},
+		{
+			name:  "json with dash and trailing comma",
+			input: `json:"-,"`,
+			expected: map[string]Tag{
+				"json": {Value: "-", Options: []string{""}},
+			},
+		},
 		{
 			name:  "mkunion without value",
 			input: "mkunion",
@@ -236,3 +243,63 @@ func TestFindTagValueEndUnified(t *testing.T) {
 		})
 	}
 }
+
+func TestTagsToGuard(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Guard
+	}{
+		{
+			name:     "no guards",
+			input:    `json:"name"`,
+			expected: nil,
+		},
+		{
+			name:  "enum and required",
+			input: `enum:"a,b" required:"true"`,
+			expected: &AndGuard{L: []Guard{
+				&Enum{Val: []string{"a", "b"}},
+				&Required{},
+			}},
+		},
+		{
+			name:     "regexp with commas inside is not split into options",
+			input:    `regexp:"^[a-z]{1,3}$"`,
+			expected: &Regexp{Regexp: "^[a-z]{1,3}$"},
+		},
+		{
+			name:  "regexp with spaces and empty parts is taken as is",
+			input: `json:"name" regexp:"^a, b,,c{1,}$" required:"true"`,
+			expected: &AndGuard{L: []Guard{
+				&Required{},
+				&Regexp{Regexp: "^a, b,,c{1,}$"},
+			}},
+		},
+		{
+			name:     "between",
+			input:    `between:"-1.5,10"`,
+			expected: &Between{Min: -1.5, Max: 10},
+		},
+		{
+			name:     "between with min greater than max is skipped",
+			input:    `between:"10,1"`,
+			expected: nil,
+		},
+		{
+			name:  "lengths and items",
+			input: `minLength:"1" maxLength:"20" minItems:"2" maxItems:"x"`,
+			expected: &AndGuard{L: []Guard{
+				&MinLength{Len: 1},
+				&MaxLength{Len: 20},
+				&MinItems{Len: 2},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TagsToGuard(ExtractTags(tt.input)))
+		})
+	}
+}
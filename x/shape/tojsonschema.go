@@ -1,106 +1,532 @@
 package shape
 
 import (
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"math"
+	"os"
+	"path"
+	"strconv"
 	"strings"
 )
 
+const (
+	JSONSchemaFileName = "schema.json"
+	JSONSchemaDialect  = "https://json-schema.org/draft/2020-12/schema"
+)
+
 // ToJsonSchema converts a Shape to a JSON Schema
 // following specification https://json-schema.org/specification
+// Named shapes are placed in $defs, and unions are described the same way as JSON serde encodes them,
+// as object with "$type" discriminator and variant payload under key of the same name.
 func ToJsonSchema(s Shape) string {
-	return toJsonSchema(s, map[string]string{}, 0, nil)
+	b := newJSONSchemaBuilder("#/$defs/", false)
+
+	result := b.Schema(s)
+	if len(b.definitions) > 0 {
+		result = jsonSchemaCopy(result)
+		result["$defs"] = b.definitions
+	}
+	result["$schema"] = JSONSchemaDialect
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		// schema is build only from maps, slices and basic types, that always can be marshalled
+		panic(fmt.Errorf("shape.ToJsonSchema: %w", err))
+	}
+
+	return string(out)
 }
 
-func toJsonSchema(s Shape, definitions map[string]string, depth int, desc *string) string {
+func NewJSONSchemaRenderer() *JSONSchemaRenderer {
+	return &JSONSchemaRenderer{
+		schemas: newJSONSchemaBuilder("#/$defs/", false),
+	}
+}
+
+// JSONSchemaRenderer renders all added shapes as single JSON Schema document,
+// where every named shape is available in $defs.
+type JSONSchemaRenderer struct {
+	schemas *jsonSchemaBuilder
+	shapes  []Shape
+}
+
+func (r *JSONSchemaRenderer) AddShape(x Shape) {
+	if x == nil {
+		return
+	}
+
+	r.schemas.register(x)
+	r.shapes = append(r.shapes, x)
+}
+
+func (r *JSONSchemaRenderer) Render() ([]byte, error) {
+	for _, x := range r.shapes {
+		r.schemas.Schema(x)
+	}
+
+	doc := map[string]any{
+		"$schema": JSONSchemaDialect,
+		"$defs":   r.schemas.definitions,
+	}
+
+	result, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("tojsonschema: Render; %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *JSONSchemaRenderer) WriteToDir(dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("tojsonschema: WriteToDir failed to create dir %s: %w", dir, err)
+	}
+
+	content, err := r.Render()
+	if err != nil {
+		return err
+	}
+
+	filename := path.Join(dir, JSONSchemaFileName)
+	err = os.WriteFile(filename, content, 0644)
+	if err != nil {
+		return fmt.Errorf("tojsonschema: WriteToDir failed to write file %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+func newJSONSchemaBuilder(refPrefix string, openAPI bool) *jsonSchemaBuilder {
+	return &jsonSchemaBuilder{
+		refPrefix:   refPrefix,
+		openAPI:     openAPI,
+		definitions: make(map[string]map[string]any),
+		known:       make(map[string]Shape),
+	}
+}
+
+// jsonSchemaBuilder converts shapes to JSON Schema nodes.
+// Named shapes are put into definitions and referenced with refPrefix,
+// so the same builder works for OpenAPI components and for JSON Schema $defs.
+// When openAPI is set, keywords from OpenAPI vocabulary, like format of numbers and discriminator, are added.
+type jsonSchemaBuilder struct {
+	refPrefix   string
+	openAPI     bool
+	definitions map[string]map[string]any
+	known       map[string]Shape
+}
+
+// register makes shape resolvable without scanning filesystem,
+// which matters for shapes that are not saved on disk yet.
+func (b *jsonSchemaBuilder) register(x Shape) {
+	b.known[shapeFullName(x)] = x
+}
+
+func (b *jsonSchemaBuilder) lookup(x *RefName) (Shape, bool) {
+	if y, ok := b.known[shapeFullName(x)]; ok {
+		return y, true
+	}
+
+	if y, ok := LookupShape(x); ok {
+		return y, true
+	}
+
+	return LookupShapeOnDisk(x)
+}
+
+func (b *jsonSchemaBuilder) ref(name string) map[string]any {
+	return map[string]any{
+		"$ref": b.refPrefix + name,
+	}
+}
+
+// Schema returns JSON Schema of a shape, named shapes are returned as references to definitions.
+func (b *jsonSchemaBuilder) Schema(x Shape) map[string]any {
 	return MatchShapeR1(
-		s,
-		func(x *Any) string {
-			return `{"type": "any"` + toDescription(desc) + `}`
-		},
-		func(x *RefName) string {
-			return `{"$ref": "#/$defs/` + x.Name + `"` + toDescription(desc) + `}`
+		x,
+		func(x *Any) map[string]any {
+			return map[string]any{}
 		},
-		func(x *PointerLike) string {
-			return toJsonSchema(x.Type, definitions, depth, desc)
+		func(x *RefName) map[string]any {
+			if mapper, ok := LookupTypeMapper(x); ok {
+				return jsonSchemaCopy(mapper.JSONSchema)
+			}
+
+			if x.PkgName == "" {
+				if primitive := NameToPrimitiveShape(x.Name); primitive != nil {
+					return b.Schema(primitive)
+				}
+
+				// not instantiated type parameter, can hold any value
+				return map[string]any{}
+			}
+
+			name := jsonSchemaDefinitionName(x.PkgName, x.Name, x.Indexed)
+			if _, ok := b.definitions[name]; ok {
+				return b.ref(name)
+			}
+
+			y, found := b.lookup(x)
+			if !found {
+				log.Warnf("tojsonschema: shape %s not found, any value is accepted", ToGoTypeName(x, WithPkgImportName()))
+				return map[string]any{}
+			}
+
+			y = IndexWith(y, x)
+			if IsWeekAlias(y) {
+				return b.Schema(y.(*AliasLike).Type)
+			}
+
+			return b.named(y, x.Indexed)
 		},
-		func(x *AliasLike) string {
-			panic("not implemented")
+		func(x *PointerLike) map[string]any {
+			return b.Schema(x.Type)
 		},
-		func(x *PrimitiveLike) string {
-			return MatchPrimitiveKindR1(
-				x.Kind,
-				func(x *BooleanLike) string {
-					return `{"type": "boolean"` + toDescription(desc) + `}`
-				},
-				func(x *StringLike) string {
-					return `{"type": "string"` + toDescription(desc) + `}`
-				},
-				func(x *NumberLike) string {
-					return `{"type": "number"` + toDescription(desc) + `}`
-				},
-			)
+		func(x *AliasLike) map[string]any {
+			if x.IsAlias {
+				return b.Schema(x.Type)
+			}
+
+			return b.named(x, nil)
 		},
-		func(x *ListLike) string {
-			return `{"type": "array", "items": ` + toJsonSchema(x.Element, definitions, depth+1, nil) + toDefinitions(definitions, depth) + toDescription(desc) + `}`
+		func(x *PrimitiveLike) map[string]any {
+			return b.primitive(x)
 		},
-		func(x *MapLike) string {
-			return `{"type": "object", "additionalProperties": ` + toJsonSchema(x.Val, definitions, depth+1, nil) + toDefinitions(definitions, depth) + toDescription(desc) + `}`
+		func(x *ListLike) map[string]any {
+			if x.ArrayLen == nil && IsBinary(x) {
+				return map[string]any{
+					"type":            "string",
+					"contentEncoding": "base64",
+				}
+			}
+
+			result := map[string]any{
+				"type":  "array",
+				"items": b.Schema(x.Element),
+			}
+			if x.ArrayLen != nil {
+				result["minItems"] = *x.ArrayLen
+				result["maxItems"] = *x.ArrayLen
+			}
+
+			return result
 		},
-		func(x *StructLike) string {
-			var properties []string
-			for _, field := range x.Fields {
-				properties = append(properties, `"`+field.Name+`": `+toJsonSchema(field.Type, definitions, depth+1, field.Desc))
+		func(x *MapLike) map[string]any {
+			return map[string]any{
+				"type":                 "object",
+				"additionalProperties": b.Schema(x.Val),
 			}
+		},
+		func(x *StructLike) map[string]any {
+			return b.named(x, nil)
+		},
+		func(x *UnionLike) map[string]any {
+			return b.named(x, nil)
+		},
+	)
+}
+
+// named puts shape into definitions, and returns reference to it.
+// Definition is reserved before it's built, so that recursive shapes terminate.
+func (b *jsonSchemaBuilder) named(x Shape, indexed []Shape) map[string]any {
+	name := jsonSchemaDefinitionName(PkgName(x), Name(x), indexed)
+	if _, ok := b.definitions[name]; ok {
+		return b.ref(name)
+	}
+
+	b.definitions[name] = map[string]any{}
+	b.definitions[name] = b.definition(x, name, indexed)
+
+	return b.ref(name)
+}
+
+func (b *jsonSchemaBuilder) definition(x Shape, name string, indexed []Shape) map[string]any {
+	switch y := x.(type) {
+	case *AliasLike:
+		return b.Schema(y.Type)
+
+	case *StructLike:
+		return b.object(y)
+
+	case *UnionLike:
+		encoding, err := UnionJSONEncoding(y)
+		if err != nil {
+			panic(fmt.Errorf("shape.ToJsonSchema: %w", err))
+		}
 
-			if _, ok := definitions[x.Name]; !ok {
-				definitions[x.Name] = `{"type": "object", "properties": {` + strings.Join(properties, ", ") + `}}`
+		if encoding.Kind == JSONUnionUntagged {
+			// untagged value is decoded as the first variant that accepts it, so more than one can match
+			var anyOf []any
+			for _, variant := range y.Variant {
+				anyOf = append(anyOf, b.named(variant, indexed))
 			}
 
-			if depth > 0 {
-				return `{"$ref": "#/$defs/` + x.Name + `"` + toDescription(desc) + `}`
+			return map[string]any{
+				"anyOf": anyOf,
 			}
+		}
 
-			return `{"$ref": "#/$defs/` + x.Name + `"` + toDefinitions(definitions, depth) + toDescription(desc) + `}`
-		},
-		func(x *UnionLike) string {
-			var oneOf []string
-			for _, variant := range x.Variant {
-				oneOf = append(oneOf, toJsonSchema(variant, definitions, depth+1, nil))
+		var oneOf []any
+		mapping := make(map[string]any)
+		for _, variant := range y.Variant {
+			variantName := jsonSchemaVariantName(variant)
+			envelopeName := name + "." + Name(variant)
+			tag := map[string]any{
+				"const": variantName,
 			}
 
-			if _, ok := definitions[x.Name]; !ok {
-				definitions[x.Name] = `{"oneOf": [` + strings.Join(oneOf, ", ") + `]` + toDescription(desc) + `}`
+			switch encoding.Kind {
+			case JSONUnionExternal:
+				b.definitions[envelopeName] = map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						encoding.Tag: tag,
+						variantName:  b.named(variant, indexed),
+					},
+					"required": []string{encoding.Tag, variantName},
+				}
+			case JSONUnionInternal:
+				b.definitions[envelopeName] = map[string]any{
+					"allOf": []any{
+						map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								encoding.Tag: tag,
+							},
+							"required": []string{encoding.Tag},
+						},
+						b.named(variant, indexed),
+					},
+				}
+			case JSONUnionAdjacent:
+				b.definitions[envelopeName] = map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						encoding.Tag:     tag,
+						encoding.Content: b.named(variant, indexed),
+					},
+					"required": []string{encoding.Tag, encoding.Content},
+				}
 			}
 
-			if depth > 0 {
-				return `{"$ref": "#/$defs/` + x.Name + `"` + toDescription(desc) + `}`
+			ref := b.ref(envelopeName)
+			oneOf = append(oneOf, ref)
+			mapping[variantName] = ref["$ref"]
+		}
+
+		result := map[string]any{
+			"oneOf": oneOf,
+		}
+		if b.openAPI {
+			result["discriminator"] = map[string]any{
+				"propertyName": encoding.Tag,
+				"mapping":      mapping,
 			}
+		}
+
+		return result
+	}
+
+	return b.Schema(x)
+}
+
+func (b *jsonSchemaBuilder) object(x *StructLike) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	for _, field := range StructFields(x, LookupShapeOnDisk) {
+		name := field.JSONName()
+
+		property := b.Schema(field.Type)
+		if field.Desc != nil && *field.Desc != "" || field.Guard != nil {
+			// $ref node must not be modified, since it can be shared
+			property = jsonSchemaCopy(property)
+		}
+		if field.Desc != nil && *field.Desc != "" {
+			property["description"] = *field.Desc
+		}
+		if field.Guard != nil && jsonSchemaGuard(field.Guard, property) && !field.MayBeOmitted() {
+			required = append(required, name)
+		}
 
-			return `{"$ref": "#/$defs/` + x.Name + `"` + toDefinitions(definitions, depth) + toDescription(desc) + `}`
+		properties[name] = property
+	}
+
+	result := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+
+	return result
+}
+
+// jsonSchemaGuard adds guard constraints to property schema, and returns true when property is required.
+func jsonSchemaGuard(guard Guard, property map[string]any) bool {
+	return MatchGuardR1(
+		guard,
+		func(x *Enum) bool {
+			var enum []any
+			for _, val := range x.Val {
+				enum = append(enum, jsonSchemaEnumValue(property["type"], val))
+			}
+			property["enum"] = enum
+			return false
+		},
+		func(x *Required) bool {
+			return true
+		},
+		func(x *Regexp) bool {
+			property["pattern"] = x.Regexp
+			return false
+		},
+		func(x *Between) bool {
+			property["minimum"] = x.Min
+			property["maximum"] = x.Max
+			return false
+		},
+		func(x *MinLength) bool {
+			property["minLength"] = x.Len
+			return false
+		},
+		func(x *MaxLength) bool {
+			property["maxLength"] = x.Len
+			return false
+		},
+		func(x *MinItems) bool {
+			property["minItems"] = x.Len
+			return false
+		},
+		func(x *MaxItems) bool {
+			property["maxItems"] = x.Len
+			return false
+		},
+		func(x *AndGuard) bool {
+			required := false
+			for _, guard := range x.L {
+				if jsonSchemaGuard(guard, property) {
+					required = true
+				}
+			}
+			return required
 		},
 	)
 }
 
-func toDescription(desc *string) string {
-	if desc == nil {
-		return ""
+// jsonSchemaEnumValue converts enum value from tag to type of property, so that numbers are not compared with strings.
+func jsonSchemaEnumValue(typ any, val string) any {
+	switch typ {
+	case "integer":
+		if v, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return v
+		}
+	case "number":
+		if v, err := strconv.ParseFloat(val, 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(val); err == nil {
+			return v
+		}
+	}
+
+	return val
+}
+
+func jsonSchemaCopy(x map[string]any) map[string]any {
+	result := make(map[string]any, len(x)+1)
+	for k, v := range x {
+		result[k] = v
 	}
+	return result
+}
+
+func (b *jsonSchemaBuilder) primitive(x *PrimitiveLike) map[string]any {
+	return MatchPrimitiveKindR1(
+		x.Kind,
+		func(x *BooleanLike) map[string]any {
+			return map[string]any{"type": "boolean"}
+		},
+		func(x *StringLike) map[string]any {
+			return map[string]any{"type": "string"}
+		},
+		func(x *NumberLike) map[string]any {
+			if x.Kind == nil {
+				return map[string]any{"type": "number"}
+			}
+
+			return MatchNumberKindR1(
+				x.Kind,
+				func(*UInt) map[string]any { return b.integer("int64", uint64(0), uint64(math.MaxUint)) },
+				func(*UInt8) map[string]any { return b.integer("int32", uint64(0), uint64(math.MaxUint8)) },
+				func(*UInt16) map[string]any { return b.integer("int32", uint64(0), uint64(math.MaxUint16)) },
+				func(*UInt32) map[string]any { return b.integer("int64", uint64(0), uint64(math.MaxUint32)) },
+				func(*UInt64) map[string]any { return b.integer("int64", uint64(0), uint64(math.MaxUint64)) },
+				func(*Int) map[string]any { return b.integer("int64", int64(math.MinInt), int64(math.MaxInt)) },
+				func(*Int8) map[string]any { return b.integer("int32", int64(math.MinInt8), int64(math.MaxInt8)) },
+				func(*Int16) map[string]any { return b.integer("int32", int64(math.MinInt16), int64(math.MaxInt16)) },
+				func(*Int32) map[string]any { return b.integer("int32", int64(math.MinInt32), int64(math.MaxInt32)) },
+				func(*Int64) map[string]any { return b.integer("int64", int64(math.MinInt64), int64(math.MaxInt64)) },
+				func(*Float32) map[string]any { return b.number("float") },
+				func(*Float64) map[string]any { return b.number("double") },
+			)
+		},
+	)
+}
 
-	return `, "description": "` + *desc + `"`
+func (b *jsonSchemaBuilder) integer(format string, min, max any) map[string]any {
+	result := map[string]any{
+		"type":    "integer",
+		"minimum": min,
+		"maximum": max,
+	}
+	if b.openAPI {
+		result["format"] = format
+	}
+	return result
 }
 
-func toDefinitions(definitions map[string]string, depth int) string {
-	if depth != 0 {
-		return ""
+func (b *jsonSchemaBuilder) number(format string) map[string]any {
+	result := map[string]any{
+		"type": "number",
 	}
+	if b.openAPI {
+		result["format"] = format
+	}
+	return result
+}
+
+// jsonSchemaVariantName must be the same as in JSON serde, since it's value of "$type" discriminator
+func jsonSchemaVariantName(x Shape) string {
+	return VariantDiscriminator(x)
+}
+
+var jsonSchemaNameReplacer = strings.NewReplacer(
+	"[]", "List",
+	"[", "_",
+	"]", "",
+	",", "_",
+	"*", "Ptr",
+	" ", "",
+)
 
-	if len(definitions) == 0 {
-		return ""
+// jsonSchemaDefinitionName returns name of definition, instantiated generic types get type arguments as suffix.
+// example:
+//
+//	example.Result[string, example.Err] -> example.Result_string_example.Err
+func jsonSchemaDefinitionName(pkgName, name string, indexed []Shape) string {
+	result := name
+	if pkgName != "" {
+		result = pkgName + "." + name
 	}
 
-	var result []string
-	for name, schema := range definitions {
-		result = append(result, `"`+name+`": `+schema)
+	for _, x := range indexed {
+		result += "_" + jsonSchemaNameReplacer.Replace(ToGoTypeName(x))
 	}
 
-	return `, "$defs": {` + strings.Join(result, ", ") + `}, "$schema": "https://json-schema.org/draft/2020-12/schema"`
+	return result
 }
@@ -18,9 +18,9 @@ func TestToJsonSchema(t *testing.T) {
 	t.Logf("schema: %s", schema)
 
 	expected := `{
-  "$ref": "#/$defs/structB",
+  "$ref": "#/$defs/shape.structB",
   "$defs": {
-    "structB": {
+    "shape.structB": {
       "type": "object",
       "properties": {
         "Count": {
@@ -41,3 +41,296 @@ func TestToJsonSchema(t *testing.T) {
 }`
 	assert.JSONEq(t, expected, schema)
 }
+
+func TestToJsonSchema_UnionsGuardsAndGenerics(t *testing.T) {
+	result := &RefName{Name: "Result", PkgName: "ev", PkgImportName: "example.com/ev", Indexed: []Shape{
+		&PrimitiveLike{Kind: &StringLike{}},
+	}}
+
+	r := NewJSONSchemaRenderer()
+	r.AddShape(&StructLike{
+		Name:          "Result",
+		PkgName:       "ev",
+		PkgImportName: "example.com/ev",
+		TypeParams:    []TypeParam{{Name: "T", Type: &Any{}}},
+		Fields: []*FieldLike{
+			{Name: "Value", Type: &RefName{Name: "T"}},
+		},
+	})
+	r.AddShape(&AliasLike{
+		Name:          "Status",
+		PkgName:       "ev",
+		PkgImportName: "example.com/ev",
+		Type:          &PrimitiveLike{Kind: &StringLike{}},
+	})
+	r.AddShape(&AliasLike{
+		Name:          "Labels",
+		PkgName:       "ev",
+		PkgImportName: "example.com/ev",
+		IsAlias:       true,
+		Type:          &MapLike{Key: &PrimitiveLike{Kind: &StringLike{}}, Val: &PrimitiveLike{Kind: &StringLike{}}},
+	})
+	r.AddShape(&UnionLike{
+		Name:          "Event",
+		PkgName:       "ev",
+		PkgImportName: "example.com/ev",
+		Variant: []Shape{
+			&StructLike{
+				Name:          "Created",
+				PkgName:       "ev",
+				PkgImportName: "example.com/ev",
+				Fields: []*FieldLike{
+					{Name: "ID", Type: &PrimitiveLike{Kind: &StringLike{}}, Tags: map[string]Tag{"json": {Value: "id"}}, Guard: &Required{}},
+					{Name: "Status", Type: &RefName{Name: "Status", PkgName: "ev", PkgImportName: "example.com/ev"}, Guard: &AndGuard{L: []Guard{
+						&Enum{Val: []string{"new", "done"}},
+						&Required{},
+					}}},
+					{Name: "Priority", Type: &PrimitiveLike{Kind: &NumberLike{Kind: &Int8{}}}, Guard: &Enum{Val: []string{"1", "2"}}},
+					{Name: "Attempts", Type: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt32{}}}},
+					{Name: "Score", Type: &PrimitiveLike{Kind: &NumberLike{Kind: &Float32{}}}},
+					{Name: "Payload", Type: &Any{}},
+					{Name: "Labels", Type: &RefName{Name: "Labels", PkgName: "ev", PkgImportName: "example.com/ev"}},
+					{Name: "Result", Type: result},
+				},
+			},
+			&StructLike{
+				Name:          "Deleted",
+				PkgName:       "ev",
+				PkgImportName: "example.com/ev",
+			},
+		},
+	})
+
+	schema, err := r.Render()
+	assert.NoError(t, err)
+
+	expected := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$defs": {
+    "ev.Result": {
+      "type": "object",
+      "properties": {"Value": {}}
+    },
+    "ev.Result_string": {
+      "type": "object",
+      "properties": {"Value": {"type": "string"}}
+    },
+    "ev.Status": {"type": "string"},
+    "ev.Event": {
+      "oneOf": [
+        {"$ref": "#/$defs/ev.Event.Created"},
+        {"$ref": "#/$defs/ev.Event.Deleted"}
+      ]
+    },
+    "ev.Event.Created": {
+      "type": "object",
+      "properties": {
+        "$type": {"const": "ev.Created"},
+        "ev.Created": {"$ref": "#/$defs/ev.Created"}
+      },
+      "required": ["$type", "ev.Created"]
+    },
+    "ev.Event.Deleted": {
+      "type": "object",
+      "properties": {
+        "$type": {"const": "ev.Deleted"},
+        "ev.Deleted": {"$ref": "#/$defs/ev.Deleted"}
+      },
+      "required": ["$type", "ev.Deleted"]
+    },
+    "ev.Created": {
+      "type": "object",
+      "properties": {
+        "id": {"type": "string"},
+        "Status": {"$ref": "#/$defs/ev.Status", "enum": ["new", "done"]},
+        "Priority": {"type": "integer", "minimum": -128, "maximum": 127, "enum": [1, 2]},
+        "Attempts": {"type": "integer", "minimum": 0, "maximum": 4294967295},
+        "Score": {"type": "number"},
+        "Payload": {},
+        "Labels": {"type": "object", "additionalProperties": {"type": "string"}},
+        "Result": {"$ref": "#/$defs/ev.Result_string"}
+      },
+      "required": ["id", "Status"]
+    },
+    "ev.Deleted": {
+      "type": "object",
+      "properties": {}
+    }
+  }
+}`
+	assert.JSONEq(t, expected, string(schema))
+}
+
+func TestToJsonSchema_Int64Range(t *testing.T) {
+	schema := ToJsonSchema(&ListLike{Element: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt64{}}}})
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "array",
+  "items": {"type": "integer", "minimum": 0, "maximum": 18446744073709551615}
+}`, schema)
+	assert.Contains(t, schema, "18446744073709551615")
+}
+
+func TestToJsonSchema_ValidationGuards(t *testing.T) {
+	schema := ToJsonSchema(&StructLike{
+		Name:    "User",
+		PkgName: "app",
+		Fields: []*FieldLike{
+			{Name: "Login", Type: &PrimitiveLike{Kind: &StringLike{}}, Guard: &AndGuard{L: []Guard{
+				&Regexp{Regexp: "^[a-z]+$"},
+				&MinLength{Len: 3},
+				&MaxLength{Len: 16},
+			}}},
+			{Name: "Age", Type: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt8{}}}, Guard: &Between{Min: 18, Max: 120}},
+			{Name: "Roles", Type: &ListLike{Element: &PrimitiveLike{Kind: &StringLike{}}}, Guard: &AndGuard{L: []Guard{
+				&MinItems{Len: 1},
+				&MaxItems{Len: 3},
+				&Required{},
+			}}},
+		},
+	})
+
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$ref": "#/$defs/app.User",
+  "$defs": {
+    "app.User": {
+      "type": "object",
+      "properties": {
+        "Login": {"type": "string", "pattern": "^[a-z]+$", "minLength": 3, "maxLength": 16},
+        "Age": {"type": "integer", "minimum": 18, "maximum": 120},
+        "Roles": {"type": "array", "items": {"type": "string"}, "minItems": 1, "maxItems": 3}
+      },
+      "required": ["Roles"]
+    }
+  }
+}`, schema)
+}
+
+func TestToJsonSchema_EmbeddedStruct(t *testing.T) {
+	schema := ToJsonSchema(&StructLike{
+		Name:    "Order",
+		PkgName: "app",
+		Fields: []*FieldLike{
+			{Name: "Base", Embedded: true, Type: &StructLike{
+				Name:    "Base",
+				PkgName: "app",
+				Fields: []*FieldLike{
+					{Name: "ID", Type: &PrimitiveLike{Kind: &StringLike{}}, Guard: &Required{}},
+				},
+			}},
+			{Name: "Audit", Embedded: true, Type: &PointerLike{Type: &StructLike{
+				Name:    "Audit",
+				PkgName: "app",
+				Fields: []*FieldLike{
+					{Name: "CreatedBy", Type: &PrimitiveLike{Kind: &StringLike{}}, Guard: &Required{}},
+				},
+			}}},
+			{Name: "Note", Type: &PrimitiveLike{Kind: &StringLike{}}, Tags: map[string]Tag{"json": {Value: "note"}}},
+		},
+	})
+
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$ref": "#/$defs/app.Order",
+  "$defs": {
+    "app.Order": {
+      "type": "object",
+      "properties": {
+        "ID": {"type": "string"},
+        "CreatedBy": {"type": "string"},
+        "note": {"type": "string"}
+      },
+      "required": ["ID"]
+    }
+  }
+}`, schema)
+}
+
+func TestToJsonSchema_TypeMapper(t *testing.T) {
+	schema := ToJsonSchema(&StructLike{
+		Name:    "Invoice",
+		PkgName: "app",
+		Fields: []*FieldLike{
+			{Name: "Issued", Type: &RefName{Name: "Time", PkgName: "time", PkgImportName: "time"}, Guard: &Required{}},
+			{Name: "ID", Type: &RefName{Name: "UUID", PkgName: "uuid", PkgImportName: "github.com/google/uuid"}, Guard: &Required{}},
+		},
+	})
+
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$ref": "#/$defs/app.Invoice",
+  "$defs": {
+    "app.Invoice": {
+      "type": "object",
+      "properties": {
+        "Issued": {"type": "string", "format": "date-time"},
+        "ID": {"type": "string", "format": "uuid"}
+      },
+      "required": ["Issued", "ID"]
+    }
+  }
+}`, schema)
+}
+
+func TestToJsonSchema_UnionEncoding(t *testing.T) {
+	union := func(encoding Tag) *UnionLike {
+		return &UnionLike{
+			Name:          "Figure",
+			PkgName:       "app",
+			PkgImportName: "example.com/app",
+			Variant: []Shape{
+				&StructLike{Name: "Circle", PkgName: "app", PkgImportName: "example.com/app", Tags: map[string]Tag{
+					TagVariantName: {Value: "circle"},
+				}},
+			},
+			Tags: map[string]Tag{
+				TagUnionName:     {Value: "Figure"},
+				TagUnionJSONName: encoding,
+			},
+		}
+	}
+
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$ref": "#/$defs/app.Figure",
+  "$defs": {
+    "app.Figure": {"oneOf": [{"$ref": "#/$defs/app.Figure.Circle"}]},
+    "app.Figure.Circle": {
+      "allOf": [
+        {"type": "object", "properties": {"kind": {"const": "circle"}}, "required": ["kind"]},
+        {"$ref": "#/$defs/app.Circle"}
+      ]
+    },
+    "app.Circle": {"type": "object", "properties": {}}
+  }
+}`, ToJsonSchema(union(Tag{Value: JSONUnionInternal, Options: []string{"tag=kind"}})))
+
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$ref": "#/$defs/app.Figure",
+  "$defs": {
+    "app.Figure": {
+      "oneOf": [{"$ref": "#/$defs/app.Figure.Circle"}]
+    },
+    "app.Figure.Circle": {
+      "type": "object",
+      "properties": {
+        "t": {"const": "circle"},
+        "c": {"$ref": "#/$defs/app.Circle"}
+      },
+      "required": ["t", "c"]
+    },
+    "app.Circle": {"type": "object", "properties": {}}
+  }
+}`, ToJsonSchema(union(Tag{Value: JSONUnionAdjacent, Options: []string{"tag=t", "content=c"}})))
+
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$ref": "#/$defs/app.Figure",
+  "$defs": {
+    "app.Figure": {"anyOf": [{"$ref": "#/$defs/app.Circle"}]},
+    "app.Circle": {"type": "object", "properties": {}}
+  }
+}`, ToJsonSchema(union(Tag{Value: JSONUnionUntagged})))
+}
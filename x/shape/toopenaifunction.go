@@ -78,19 +78,13 @@ func toFunctionParameters(in Shape) *jsonschema.Definition {
 		func(x *StructLike) *jsonschema.Definition {
 			properties := map[string]jsonschema.Definition{}
 			for _, field := range x.Fields {
-				def := toOpenAIFieldName(field.Guard, toFunctionParameters(field.Type))
+				def := toFunctionParameters(field.Type)
 				if field.Desc != nil {
 					def.Description = *field.Desc
 				}
+				def = toOpenAIFieldName(field.Guard, def)
 
-				name := field.Name
-				if field.Tags != nil {
-					if v, ok := field.Tags["name"]; ok {
-						name = v.Value
-					}
-				}
-
-				properties[name] = *def
+				properties[toOpenAIPropertyName(field)] = *def
 			}
 
 			return &jsonschema.Definition{
@@ -167,11 +161,18 @@ func toVariantName(x Shape) string {
 
 }
 
+func toOpenAIPropertyName(field *FieldLike) string {
+	if v, ok := field.Tags["name"]; ok {
+		return v.Value
+	}
+	return field.Name
+}
+
 func requireFields(fields []*FieldLike) []string {
 	var result []string
 	for _, field := range fields {
-		if _, ok := field.Guard.(*Required); ok {
-			result = append(result, field.Name)
+		if IsRequired(field.Guard) {
+			result = append(result, toOpenAIPropertyName(field))
 		}
 	}
 	return result
@@ -191,6 +192,24 @@ func toOpenAIFieldName(guard Guard, field *jsonschema.Definition) *jsonschema.De
 		func(y *Required) *jsonschema.Definition {
 			return field
 		},
+		func(y *Regexp) *jsonschema.Definition {
+			return withOpenAIConstraint(field, fmt.Sprintf("Must match regular expression %s.", y.Regexp))
+		},
+		func(y *Between) *jsonschema.Definition {
+			return withOpenAIConstraint(field, fmt.Sprintf("Must be between %v and %v.", y.Min, y.Max))
+		},
+		func(y *MinLength) *jsonschema.Definition {
+			return withOpenAIConstraint(field, fmt.Sprintf("Must have at least %d characters.", y.Len))
+		},
+		func(y *MaxLength) *jsonschema.Definition {
+			return withOpenAIConstraint(field, fmt.Sprintf("Must have at most %d characters.", y.Len))
+		},
+		func(y *MinItems) *jsonschema.Definition {
+			return withOpenAIConstraint(field, fmt.Sprintf("Must have at least %d items.", y.Len))
+		},
+		func(y *MaxItems) *jsonschema.Definition {
+			return withOpenAIConstraint(field, fmt.Sprintf("Must have at most %d items.", y.Len))
+		},
 		func(y *AndGuard) *jsonschema.Definition {
 			for _, guard := range y.L {
 				field = toOpenAIFieldName(guard, field)
@@ -199,3 +218,14 @@ func toOpenAIFieldName(guard Guard, field *jsonschema.Definition) *jsonschema.De
 		},
 	)
 }
+
+// withOpenAIConstraint describes constraint in field description,
+// since function definition doesn't support validation keywords like pattern or minimum.
+func withOpenAIConstraint(field *jsonschema.Definition, constraint string) *jsonschema.Definition {
+	if field.Description == "" {
+		field.Description = constraint
+	} else {
+		field.Description = field.Description + " " + constraint
+	}
+	return field
+}
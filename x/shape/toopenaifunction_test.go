@@ -42,3 +42,41 @@ func TestToOpenAIFunctionDefinition(t *testing.T) {
 }`
 	assert.JSONEq(t, expected, string(defJSON))
 }
+
+type searchInput struct {
+	Query string   `desc:"Search phrase" name:"query" minLength:"3" required:"true"`
+	Limit int      `name:"limit" between:"1,50"`
+	Tags  []string `name:"tags" maxItems:"5"`
+}
+
+func TestToOpenAIFunctionDefinition_Guards(t *testing.T) {
+	in := FromGo(searchInput{})
+	def := ToOpenAIFunctionDefinition("search", "Search documents", in)
+	defJSON, err := json.Marshal(def)
+	assert.NoError(t, err)
+
+	expected := `{
+  "name": "search",
+  "description": "Search documents",
+  "parameters": {
+    "type": "object",
+    "properties": {
+      "query": {
+        "type": "string",
+        "description": "Search phrase Must have at least 3 characters."
+      },
+      "limit": {
+        "type": "number",
+        "description": "Must be between 1 and 50."
+      },
+      "tags": {
+        "type": "array",
+        "items": {"type": "string"},
+        "description": "Must have at most 5 items."
+      }
+    },
+    "required": ["query"]
+  }
+}`
+	assert.JSONEq(t, expected, string(defJSON))
+}
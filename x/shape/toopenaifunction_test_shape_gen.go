@@ -2,9 +2,88 @@
 package shape
 
 func init() {
+	Register(searchInputShape())
 	Register(weatherInputShape())
 }
 
+//shape:shape
+func searchInputShape() Shape {
+	return &StructLike{
+		Name:          "searchInput",
+		PkgName:       "shape",
+		PkgImportName: "github.com/widmogrod/mkunion/x/shape",
+		Fields: []*FieldLike{
+			{
+				Name: "Query",
+				Type: &PrimitiveLike{Kind: &StringLike{}},
+				Desc: Ptr("Search phrase"),
+				Guard: &AndGuard{
+					L: []Guard{
+						&Required{},
+						&MinLength{
+							Len: 3,
+						},
+					},
+				},
+				Tags: map[string]Tag{
+					"desc": {
+						Value: "Search phrase",
+					},
+					"minLength": {
+						Value: "3",
+					},
+					"name": {
+						Value: "query",
+					},
+					"required": {
+						Value: "true",
+					},
+				},
+			},
+			{
+				Name: "Limit",
+				Type: &PrimitiveLike{
+					Kind: &NumberLike{
+						Kind: &Int{},
+					},
+				},
+				Guard: &Between{
+					Min: 1,
+					Max: 50,
+				},
+				Tags: map[string]Tag{
+					"between": {
+						Value: "1",
+						Options: []string{
+							"50",
+						},
+					},
+					"name": {
+						Value: "limit",
+					},
+				},
+			},
+			{
+				Name: "Tags",
+				Type: &ListLike{
+					Element: &PrimitiveLike{Kind: &StringLike{}},
+				},
+				Guard: &MaxItems{
+					Len: 5,
+				},
+				Tags: map[string]Tag{
+					"maxItems": {
+						Value: "5",
+					},
+					"name": {
+						Value: "tags",
+					},
+				},
+			},
+		},
+	}
+}
+
 //shape:shape
 func weatherInputShape() Shape {
 	return &StructLike{
@@ -0,0 +1,153 @@
+package shape
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+const OpenAPIFileName = "openapi.json"
+
+// OpenAPIEndpoint describes single HTTP operation, that accepts JSON request and responds with JSON.
+// Request and Response are optional, when nil, operation has no body.
+type OpenAPIEndpoint struct {
+	Method   string
+	Path     string
+	Summary  string
+	Request  Shape
+	Response Shape
+}
+
+func NewOpenAPIRenderer(title, version string) *OpenAPIRenderer {
+	return &OpenAPIRenderer{
+		title:   title,
+		version: version,
+		schemas: newJSONSchemaBuilder("#/components/schemas/", true),
+	}
+}
+
+// OpenAPIRenderer renders shapes as OpenAPI 3.1 document.
+// Named shapes are placed in components/schemas, and unions become oneOf with discriminator on "$type",
+// that matches how JSON serde encodes union variants.
+type OpenAPIRenderer struct {
+	title     string
+	version   string
+	schemas   *jsonSchemaBuilder
+	shapes    []Shape
+	endpoints []OpenAPIEndpoint
+}
+
+func (r *OpenAPIRenderer) AddShape(x Shape) {
+	if x == nil {
+		return
+	}
+
+	r.schemas.register(x)
+	r.shapes = append(r.shapes, x)
+}
+
+func (r *OpenAPIRenderer) AddEndpoint(endpoint OpenAPIEndpoint) {
+	r.endpoints = append(r.endpoints, endpoint)
+}
+
+func (r *OpenAPIRenderer) Render() ([]byte, error) {
+	for _, x := range r.shapes {
+		r.schemas.Schema(x)
+	}
+
+	paths := make(map[string]map[string]any)
+	for _, endpoint := range r.endpoints {
+		if endpoint.Path == "" {
+			return nil, fmt.Errorf("toopenapi: Render endpoint %s without path", endpoint.Method)
+		}
+
+		method := strings.ToLower(endpoint.Method)
+		if method == "" {
+			method = "post"
+		}
+
+		if _, ok := paths[endpoint.Path]; !ok {
+			paths[endpoint.Path] = make(map[string]any)
+		}
+		if _, ok := paths[endpoint.Path][method]; ok {
+			return nil, fmt.Errorf("toopenapi: Render endpoint %s %s is defined twice", method, endpoint.Path)
+		}
+
+		paths[endpoint.Path][method] = r.operation(endpoint)
+	}
+
+	doc := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   r.title,
+			"version": r.version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": r.schemas.definitions,
+		},
+	}
+
+	result, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("toopenapi: Render; %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *OpenAPIRenderer) operation(endpoint OpenAPIEndpoint) map[string]any {
+	response := map[string]any{
+		"description": "OK",
+	}
+	if endpoint.Response != nil {
+		response["content"] = openAPIJSONContent(r.schemas.Schema(endpoint.Response))
+	}
+
+	result := map[string]any{
+		"responses": map[string]any{
+			"200": response,
+		},
+	}
+	if endpoint.Summary != "" {
+		result["summary"] = endpoint.Summary
+	}
+	if endpoint.Request != nil {
+		result["requestBody"] = map[string]any{
+			"required": true,
+			"content":  openAPIJSONContent(r.schemas.Schema(endpoint.Request)),
+		}
+	}
+
+	return result
+}
+
+func (r *OpenAPIRenderer) WriteToDir(dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("toopenapi: WriteToDir failed to create dir %s: %w", dir, err)
+	}
+
+	content, err := r.Render()
+	if err != nil {
+		return err
+	}
+
+	filename := path.Join(dir, OpenAPIFileName)
+	err = os.WriteFile(filename, content, 0644)
+	if err != nil {
+		return fmt.Errorf("toopenapi: WriteToDir failed to write file %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+func openAPIJSONContent(schema map[string]any) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{
+			"schema": schema,
+		},
+	}
+}
@@ -0,0 +1,201 @@
+package shape
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestOpenAPIGeneration(t *testing.T) {
+	desc := "radius in meters"
+	circle := &RefName{Name: "Circle", PkgName: "geo", PkgImportName: "example.com/geo"}
+
+	r := NewOpenAPIRenderer("geo", "1.0.0")
+	r.AddShape(&UnionLike{
+		Name:          "Shape",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		Variant: []Shape{
+			&StructLike{
+				Name:          "Circle",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Fields: []*FieldLike{
+					{
+						Name: "Radius",
+						Type: &PrimitiveLike{Kind: &NumberLike{Kind: &Float64{}}},
+						Desc: &desc,
+						Tags: map[string]Tag{"json": {Value: "radius"}},
+					},
+					{Name: "Hidden", Type: &PrimitiveLike{Kind: &BooleanLike{}}, Tags: map[string]Tag{"json": {Value: "-"}}},
+				},
+			},
+			&StructLike{
+				Name:          "Group",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Fields: []*FieldLike{
+					{Name: "Items", Type: &ListLike{Element: &RefName{Name: "Shape", PkgName: "geo", PkgImportName: "example.com/geo"}}},
+					{Name: "Data", Type: &ListLike{Element: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt8{}}}}},
+					{Name: "Meta", Type: &MapLike{Key: &PrimitiveLike{Kind: &StringLike{}}, Val: &Any{}}},
+					{Name: "Count", Type: &PointerLike{Type: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt16{}}}}},
+				},
+			},
+			&AliasLike{
+				Name:          "Tag",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Type:          &PrimitiveLike{Kind: &StringLike{}},
+			},
+		},
+	})
+	r.AddShape(&StructLike{
+		Name:          "Page",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		TypeParams:    []TypeParam{{Name: "T", Type: &Any{}}},
+		Fields: []*FieldLike{
+			{Name: "Items", Type: &ListLike{Element: &RefName{Name: "T"}}},
+			{Name: "Next", Type: &PrimitiveLike{Kind: &StringLike{}}},
+		},
+	})
+	r.AddEndpoint(OpenAPIEndpoint{
+		Method:   "POST",
+		Path:     "/shapes",
+		Summary:  "Store shape",
+		Request:  &RefName{Name: "Shape", PkgName: "geo", PkgImportName: "example.com/geo"},
+		Response: &RefName{Name: "Page", PkgName: "geo", PkgImportName: "example.com/geo", Indexed: []Shape{circle}},
+	})
+	r.AddEndpoint(OpenAPIEndpoint{
+		Method: "GET",
+		Path:   "/health",
+	})
+
+	result, err := r.Render()
+	assert.NoError(t, err)
+
+	expected := `{
+  "openapi": "3.1.0",
+  "info": {"title": "geo", "version": "1.0.0"},
+  "paths": {
+    "/shapes": {
+      "post": {
+        "summary": "Store shape",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/geo.Shape"}}}
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/geo.Page_geo.Circle"}}}
+          }
+        }
+      }
+    },
+    "/health": {
+      "get": {
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "geo.Shape": {
+        "oneOf": [
+          {"$ref": "#/components/schemas/geo.Shape.Circle"},
+          {"$ref": "#/components/schemas/geo.Shape.Group"},
+          {"$ref": "#/components/schemas/geo.Shape.Tag"}
+        ],
+        "discriminator": {
+          "propertyName": "$type",
+          "mapping": {
+            "geo.Circle": "#/components/schemas/geo.Shape.Circle",
+            "geo.Group": "#/components/schemas/geo.Shape.Group",
+            "geo.Tag": "#/components/schemas/geo.Shape.Tag"
+          }
+        }
+      },
+      "geo.Shape.Circle": {
+        "type": "object",
+        "properties": {
+          "$type": {"const": "geo.Circle"},
+          "geo.Circle": {"$ref": "#/components/schemas/geo.Circle"}
+        },
+        "required": ["$type", "geo.Circle"]
+      },
+      "geo.Shape.Group": {
+        "type": "object",
+        "properties": {
+          "$type": {"const": "geo.Group"},
+          "geo.Group": {"$ref": "#/components/schemas/geo.Group"}
+        },
+        "required": ["$type", "geo.Group"]
+      },
+      "geo.Shape.Tag": {
+        "type": "object",
+        "properties": {
+          "$type": {"const": "geo.Tag"},
+          "geo.Tag": {"$ref": "#/components/schemas/geo.Tag"}
+        },
+        "required": ["$type", "geo.Tag"]
+      },
+      "geo.Circle": {
+        "type": "object",
+        "properties": {
+          "radius": {"type": "number", "format": "double", "description": "radius in meters"}
+        }
+      },
+      "geo.Group": {
+        "type": "object",
+        "properties": {
+          "Items": {"type": "array", "items": {"$ref": "#/components/schemas/geo.Shape"}},
+          "Data": {"type": "string", "contentEncoding": "base64"},
+          "Meta": {"type": "object", "additionalProperties": {}},
+          "Count": {"type": "integer", "format": "int32", "minimum": 0, "maximum": 65535}
+        }
+      },
+      "geo.Tag": {"type": "string"},
+      "geo.Page": {
+        "type": "object",
+        "properties": {
+          "Items": {"type": "array", "items": {}},
+          "Next": {"type": "string"}
+        }
+      },
+      "geo.Page_geo.Circle": {
+        "type": "object",
+        "properties": {
+          "Items": {"type": "array", "items": {"$ref": "#/components/schemas/geo.Circle"}},
+          "Next": {"type": "string"}
+        }
+      }
+    }
+  }
+}`
+	assert.JSONEq(t, expected, string(result))
+
+	t.Run("endpoint defined twice is an error", func(t *testing.T) {
+		r.AddEndpoint(OpenAPIEndpoint{Method: "get", Path: "/health"})
+		_, err := r.Render()
+		assert.ErrorContains(t, err, "get /health is defined twice")
+	})
+}
+
+func TestOpenAPIWriteToDir(t *testing.T) {
+	dir := t.TempDir()
+
+	r := NewOpenAPIRenderer("empty", "0.0.1")
+	err := r.WriteToDir(dir)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path.Join(dir, OpenAPIFileName))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "openapi": "3.1.0",
+  "info": {"title": "empty", "version": "0.0.1"},
+  "paths": {},
+  "components": {"schemas": {}}
+}`, string(content))
+}
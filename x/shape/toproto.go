@@ -0,0 +1,720 @@
+package shape
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const ProtoLockFileName = "mkunion.proto.lock"
+
+// ProtoLock keeps protobuf field numbers stable between exports.
+// Once field got a number, it keeps it, even when fields are reordered,
+// and when field is removed, its number is reserved and never used again.
+type ProtoLock struct {
+	Messages map[string]*ProtoLockMessage `json:"messages"`
+}
+
+type ProtoLockMessage struct {
+	Fields        map[string]int `json:"fields"`
+	Reserved      []int          `json:"reserved,omitempty"`
+	ReservedNames []string       `json:"reserved_names,omitempty"`
+}
+
+func NewProtoLock() *ProtoLock {
+	return &ProtoLock{
+		Messages: make(map[string]*ProtoLockMessage),
+	}
+}
+
+// LoadProtoLock reads lock file, when file doesn't exist, it returns empty lock.
+func LoadProtoLock(filename string) (*ProtoLock, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewProtoLock(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("shape.LoadProtoLock: %w", err)
+	}
+
+	result := NewProtoLock()
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return nil, fmt.Errorf("shape.LoadProtoLock: %s; %w", filename, err)
+	}
+
+	if result.Messages == nil {
+		result.Messages = make(map[string]*ProtoLockMessage)
+	}
+
+	return result, nil
+}
+
+// FindProtoLockFile returns location of the lock file for the module that contains dir.
+// Lock file lives next to go.mod, so that every tool in the module shares the same field numbers.
+func FindProtoLockFile(dir string) string {
+	if !path.IsAbs(dir) {
+		cwd, _ := os.Getwd()
+		dir = path.Join(cwd, dir)
+	}
+
+	for current := dir; current != "/" && current != "."; current = path.Dir(current) {
+		if _, err := os.Stat(path.Join(current, "go.mod")); err == nil {
+			return path.Join(current, ProtoLockFileName)
+		}
+	}
+
+	return path.Join(dir, ProtoLockFileName)
+}
+
+func (l *ProtoLock) Save(filename string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("shape.ProtoLock.Save: %w", err)
+	}
+
+	err = os.WriteFile(filename, append(data, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("shape.ProtoLock.Save: %w", err)
+	}
+
+	return nil
+}
+
+// Assign returns field numbers for given message fields.
+// Fields already present in lock keep their numbers, new fields get next free number,
+// and fields that are no longer present are moved to reserved.
+// Field that is added again gets next free number, and its name is no longer reserved,
+// while its previous number stays reserved.
+func (l *ProtoLock) Assign(message string, names []string) map[string]int {
+	msg, ok := l.Messages[message]
+	if !ok {
+		msg = &ProtoLockMessage{
+			Fields: make(map[string]int),
+		}
+		l.Messages[message] = msg
+	}
+	if msg.Fields == nil {
+		msg.Fields = make(map[string]int)
+	}
+
+	next := 0
+	for _, num := range msg.Fields {
+		next = max(next, num)
+	}
+	for _, num := range msg.Reserved {
+		next = max(next, num)
+	}
+
+	present := make(map[string]bool, len(names))
+	for _, name := range names {
+		present[name] = true
+		if _, ok := msg.Fields[name]; ok {
+			continue
+		}
+
+		next++
+		msg.Fields[name] = next
+		msg.ReservedNames = slices.DeleteFunc(msg.ReservedNames, func(x string) bool {
+			return x == name
+		})
+	}
+
+	for name, num := range msg.Fields {
+		if present[name] {
+			continue
+		}
+
+		delete(msg.Fields, name)
+		msg.Reserved = append(msg.Reserved, num)
+		msg.ReservedNames = append(msg.ReservedNames, name)
+	}
+	sort.Ints(msg.Reserved)
+	sort.Strings(msg.ReservedNames)
+
+	result := make(map[string]int, len(names))
+	for _, name := range names {
+		result[name] = msg.Fields[name]
+	}
+
+	return result
+}
+
+func (l *ProtoLock) reserved(message string) ([]int, []string) {
+	msg, ok := l.Messages[message]
+	if !ok {
+		return nil, nil
+	}
+
+	return msg.Reserved, msg.ReservedNames
+}
+
+type (
+	protoMessage struct {
+		name          string
+		comment       string
+		fields        []protoField
+		oneOf         []protoField
+		nested        []*protoMessage
+		reserved      []int
+		reservedNames []string
+	}
+	protoField struct {
+		label   string
+		typ     string
+		name    string
+		number  int
+		comment string
+	}
+)
+
+// ProtoOptions holds state of a single .proto file that is being rendered.
+type ProtoOptions struct {
+	currentPkgName       string
+	currentPkgImportName packageImportName
+	imports              map[packageImportName]bool
+}
+
+func (o *ProtoOptions) typeName(name, pkgName, pkgImportName string) string {
+	if pkgImportName == "" || pkgImportName == o.currentPkgImportName {
+		return name
+	}
+
+	o.imports[pkgImportName] = true
+	return fmt.Sprintf(".%s.%s", ProtoPackageName(pkgImportName), name)
+}
+
+func NewProtobufRenderer(lock *ProtoLock) *ProtobufRenderer {
+	if lock == nil {
+		lock = NewProtoLock()
+	}
+
+	return &ProtobufRenderer{
+		lock:       lock,
+		options:    make(map[packageImportName]*ProtoOptions),
+		messages:   make(map[packageImportName][]*protoMessage),
+		shapeAdded: make(map[shapeName]bool),
+	}
+}
+
+// ProtobufRenderer renders shapes as proto3 messages.
+// Unions become messages with single oneof, structs, lists and maps become messages and fields.
+// Fields that don't have protobuf counterpart, like type parameters or any, are encoded as JSON bytes.
+type ProtobufRenderer struct {
+	lock       *ProtoLock
+	options    map[packageImportName]*ProtoOptions
+	messages   map[packageImportName][]*protoMessage
+	shapeAdded map[shapeName]bool
+}
+
+func (r *ProtobufRenderer) Lock() *ProtoLock {
+	return r.lock
+}
+
+func (r *ProtobufRenderer) AddShape(x Shape) {
+	if x == nil {
+		return
+	}
+
+	// don't add shape twice
+	key := ToGoTypeName(x, WithPkgImportName())
+	if r.shapeAdded[key] {
+		return
+	}
+	r.shapeAdded[key] = true
+
+	MatchShapeR0(
+		x,
+		func(x *Any) {
+			log.Infof("toproto: AddShape Any is not supported")
+		},
+		func(x *RefName) {
+			log.Debugf("toproto: AddShape RefName %s is resolved by FollowRef", ToGoTypeName(x))
+		},
+		func(x *PointerLike) {
+			r.AddShape(x.Type)
+		},
+		func(x *AliasLike) {
+			if x.IsAlias {
+				// week alias, like `type A = B` don't introduce new type on the wire
+				return
+			}
+
+			options := r.initOptionsFor(x.PkgName, x.PkgImportName)
+			msg := &protoMessage{
+				name:    x.Name,
+				comment: fmt.Sprintf("%s is generated from %s.%s", x.Name, x.PkgImportName, x.Name),
+			}
+
+			numbers := r.lock.Assign(protoLockKey(x), []string{"value"})
+			label, typ := r.fieldType(x.Type, options, msg, "Value")
+			msg.fields = append(msg.fields, protoField{
+				label:  label,
+				typ:    typ,
+				name:   "value",
+				number: numbers["value"],
+			})
+			msg.reserved, msg.reservedNames = r.lock.reserved(protoLockKey(x))
+
+			r.messages[x.PkgImportName] = append(r.messages[x.PkgImportName], msg)
+		},
+		func(x *PrimitiveLike) {
+			log.Infof("toproto: AddShape PrimitiveLike is not supported")
+		},
+		func(x *ListLike) {
+			log.Infof("toproto: AddShape ListLike is not supported")
+		},
+		func(x *MapLike) {
+			log.Infof("toproto: AddShape MapLike is not supported")
+		},
+		func(x *StructLike) {
+			options := r.initOptionsFor(x.PkgName, x.PkgImportName)
+			msg := &protoMessage{
+				name:    x.Name,
+				comment: fmt.Sprintf("%s is generated from %s.%s", x.Name, x.PkgImportName, x.Name),
+			}
+
+			names := make([]string, 0, len(x.Fields))
+			for _, field := range x.Fields {
+				names = append(names, field.Name)
+			}
+
+			numbers := r.lock.Assign(protoLockKey(x), names)
+			for _, field := range x.Fields {
+				label, typ := r.fieldType(field.Type, options, msg, field.Name)
+				msg.fields = append(msg.fields, protoField{
+					label:   label,
+					typ:     typ,
+					name:    ToProtoFieldName(field.Name),
+					number:  numbers[field.Name],
+					comment: protoFieldComment(field.Type),
+				})
+			}
+			msg.reserved, msg.reservedNames = r.lock.reserved(protoLockKey(x))
+
+			r.messages[x.PkgImportName] = append(r.messages[x.PkgImportName], msg)
+		},
+		func(x *UnionLike) {
+			options := r.initOptionsFor(x.PkgName, x.PkgImportName)
+			msg := &protoMessage{
+				name:    x.Name,
+				comment: fmt.Sprintf("%s is generated from union %s.%s", x.Name, x.PkgImportName, x.Name),
+			}
+
+			names := make([]string, 0, len(x.Variant))
+			for _, variant := range x.Variant {
+				names = append(names, Name(variant))
+			}
+
+			numbers := r.lock.Assign(protoLockKey(x), names)
+			for _, variant := range x.Variant {
+				msg.oneOf = append(msg.oneOf, protoField{
+					typ:    r.variantType(variant, options),
+					name:   ToProtoFieldName(Name(variant)),
+					number: numbers[Name(variant)],
+				})
+			}
+			msg.reserved, msg.reservedNames = r.lock.reserved(protoLockKey(x))
+
+			r.messages[x.PkgImportName] = append(r.messages[x.PkgImportName], msg)
+
+			for _, variant := range x.Variant {
+				r.AddShape(variant)
+			}
+		},
+	)
+
+	r.FollowRef(x)
+}
+
+func (r *ProtobufRenderer) FollowRef(x Shape) {
+	refs := ExtractRefs(x)
+	for _, ref := range refs {
+		log.Debugf("toproto: FollowRef %s", ToGoTypeName(ref))
+		x, found := LookupShapeOnDisk(ref)
+		if found {
+			r.AddShape(x)
+		}
+	}
+}
+
+func (r *ProtobufRenderer) variantType(x Shape, options *ProtoOptions) string {
+	if alias, ok := x.(*AliasLike); ok && alias.IsAlias {
+		_, typ := r.fieldType(alias.Type, options, nil, alias.Name)
+		return typ
+	}
+
+	return options.typeName(Name(x), PkgName(x), ToGoPkgImportName(x))
+}
+
+// fieldType returns protobuf label and type of field,
+// when shape cannot be expressed directly, like list of lists, nested message is added to parent
+func (r *ProtobufRenderer) fieldType(x Shape, options *ProtoOptions, parent *protoMessage, fieldName string) (string, string) {
+	return MatchShapeR2(
+		x,
+		func(x *Any) (string, string) {
+			return "", "bytes"
+		},
+		func(x *RefName) (string, string) {
+			if x.PkgName == "" && x.PkgImportName == "" {
+				// type parameter
+				return "", "bytes"
+			}
+
+			if target := ResolveWeekAlias(x, LookupShapeOnDisk); target != Shape(x) {
+				// week alias don't have message, it's encoded as type it refers to
+				return r.fieldType(target, options, parent, fieldName)
+			}
+
+			return "", options.typeName(x.Name, x.PkgName, x.PkgImportName)
+		},
+		func(x *PointerLike) (string, string) {
+			label, typ := r.fieldType(x.Type, options, parent, fieldName)
+			if label == "" && isProtoScalar(typ) {
+				return "optional", typ
+			}
+			return label, typ
+		},
+		func(x *AliasLike) (string, string) {
+			if x.IsAlias {
+				return r.fieldType(x.Type, options, parent, fieldName)
+			}
+			return "", options.typeName(x.Name, x.PkgName, x.PkgImportName)
+		},
+		func(x *PrimitiveLike) (string, string) {
+			return "", protoScalar(x)
+		},
+		func(x *ListLike) (string, string) {
+			if IsBinary(x) {
+				return "", "bytes"
+			}
+
+			label, typ := r.fieldType(x.Element, options, parent, fieldName+"Item")
+			if label == "repeated" || strings.HasPrefix(typ, "map<") {
+				typ = r.nestedWrapper(parent, fieldName+"Item", label, typ)
+			}
+
+			return "repeated", typ
+		},
+		func(x *MapLike) (string, string) {
+			_, keyType := r.fieldType(x.Key, options, parent, fieldName+"Key")
+			valLabel, valType := r.fieldType(x.Val, options, parent, fieldName+"Value")
+			if valLabel == "repeated" || strings.HasPrefix(valType, "map<") {
+				valType = r.nestedWrapper(parent, fieldName+"Value", valLabel, valType)
+			}
+
+			if !isProtoMapKey(keyType) {
+				// protobuf allows only integral and string keys,
+				// other keys are represented as list of entries
+				entry := &protoMessage{
+					name: fieldName + "Entry",
+					fields: []protoField{
+						{typ: keyType, name: "key", number: 1},
+						{typ: valType, name: "value", number: 2},
+					},
+				}
+				if parent != nil {
+					parent.nested = append(parent.nested, entry)
+				}
+				return "repeated", entry.name
+			}
+
+			return "", fmt.Sprintf("map<%s, %s>", keyType, valType)
+		},
+		func(x *StructLike) (string, string) {
+			return "", options.typeName(x.Name, x.PkgName, x.PkgImportName)
+		},
+		func(x *UnionLike) (string, string) {
+			return "", options.typeName(x.Name, x.PkgName, x.PkgImportName)
+		},
+	)
+}
+
+func (r *ProtobufRenderer) nestedWrapper(parent *protoMessage, name, label, typ string) string {
+	wrapper := &protoMessage{
+		name: name,
+		fields: []protoField{
+			{label: label, typ: typ, name: "value", number: 1},
+		},
+	}
+	if parent != nil {
+		parent.nested = append(parent.nested, wrapper)
+	}
+
+	return wrapper.name
+}
+
+func (r *ProtobufRenderer) initOptionsFor(pkgName, pkgImportName string) *ProtoOptions {
+	if _, ok := r.options[pkgImportName]; !ok {
+		r.options[pkgImportName] = &ProtoOptions{
+			currentPkgName:       pkgName,
+			currentPkgImportName: pkgImportName,
+			imports:              make(map[packageImportName]bool),
+		}
+	}
+
+	return r.options[pkgImportName]
+}
+
+// Render returns contents of .proto files indexed by package import name.
+func (r *ProtobufRenderer) Render() map[packageImportName]string {
+	result := make(map[packageImportName]string, len(r.messages))
+	for pkgImportName, messages := range r.messages {
+		options := r.options[pkgImportName]
+		if options == nil {
+			continue
+		}
+
+		content := &strings.Builder{}
+		content.WriteString("// Code generated by mkunion. DO NOT EDIT.\n")
+		content.WriteString("syntax = \"proto3\";\n\n")
+		_, _ = fmt.Fprintf(content, "package %s;\n", ProtoPackageName(pkgImportName))
+
+		sortedImports := make([]string, 0, len(options.imports))
+		for imp := range options.imports {
+			sortedImports = append(sortedImports, imp)
+		}
+		sort.Strings(sortedImports)
+
+		if len(sortedImports) > 0 {
+			content.WriteString("\n")
+		}
+		for _, imp := range sortedImports {
+			_, _ = fmt.Fprintf(content, "import %q;\n", ProtoFileName(imp))
+		}
+
+		for _, msg := range messages {
+			content.WriteString("\n")
+			writeProtoMessage(content, msg, 0)
+		}
+
+		result[pkgImportName] = content.String()
+	}
+
+	return result
+}
+
+func (r *ProtobufRenderer) WriteToDir(dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("toproto: WriteToDir failed to create dir %s: %w", dir, err)
+	}
+
+	for pkgImportName, content := range r.Render() {
+		filename := path.Join(dir, ProtoFileName(pkgImportName))
+		err = os.WriteFile(filename, []byte(content), 0644)
+		if err != nil {
+			return fmt.Errorf("toproto: WriteToDir failed to write file %s: %w", filename, err)
+		}
+	}
+
+	return nil
+}
+
+func writeProtoMessage(result *strings.Builder, msg *protoMessage, depth int) {
+	indent := strings.Repeat("  ", depth)
+	if msg.comment != "" {
+		_, _ = fmt.Fprintf(result, "%s// %s\n", indent, msg.comment)
+	}
+	_, _ = fmt.Fprintf(result, "%smessage %s {\n", indent, msg.name)
+
+	for _, nested := range msg.nested {
+		writeProtoMessage(result, nested, depth+1)
+	}
+
+	if len(msg.reserved) > 0 {
+		numbers := make([]string, 0, len(msg.reserved))
+		for _, num := range msg.reserved {
+			numbers = append(numbers, fmt.Sprintf("%d", num))
+		}
+		_, _ = fmt.Fprintf(result, "%s  reserved %s;\n", indent, strings.Join(numbers, ", "))
+	}
+	if len(msg.reservedNames) > 0 {
+		names := make([]string, 0, len(msg.reservedNames))
+		for _, name := range msg.reservedNames {
+			names = append(names, fmt.Sprintf("%q", ToProtoFieldName(name)))
+		}
+		_, _ = fmt.Fprintf(result, "%s  reserved %s;\n", indent, strings.Join(names, ", "))
+	}
+
+	for _, field := range msg.fields {
+		writeProtoField(result, field, depth+1)
+	}
+
+	if len(msg.oneOf) > 0 {
+		_, _ = fmt.Fprintf(result, "%s  oneof value {\n", indent)
+		for _, field := range msg.oneOf {
+			writeProtoField(result, field, depth+2)
+		}
+		_, _ = fmt.Fprintf(result, "%s  }\n", indent)
+	}
+
+	_, _ = fmt.Fprintf(result, "%s}\n", indent)
+}
+
+func writeProtoField(result *strings.Builder, field protoField, depth int) {
+	indent := strings.Repeat("  ", depth)
+	label := ""
+	if field.label != "" {
+		label = field.label + " "
+	}
+
+	comment := ""
+	if field.comment != "" {
+		comment = " // " + field.comment
+	}
+
+	_, _ = fmt.Fprintf(result, "%s%s%s %s = %d;%s\n", indent, label, field.typ, field.name, field.number, comment)
+}
+
+func protoFieldComment(x Shape) string {
+	switch y := x.(type) {
+	case *Any:
+		return "JSON encoded any"
+	case *RefName:
+		if y.PkgName == "" && y.PkgImportName == "" {
+			return fmt.Sprintf("JSON encoded type parameter %s", y.Name)
+		}
+	case *PointerLike:
+		return protoFieldComment(y.Type)
+	}
+
+	return ""
+}
+
+func protoLockKey(x Shape) string {
+	return fmt.Sprintf("%s.%s", ToGoPkgImportName(x), Name(x))
+}
+
+func protoScalar(x *PrimitiveLike) string {
+	return MatchPrimitiveKindR1(
+		x.Kind,
+		func(x *BooleanLike) string {
+			return "bool"
+		},
+		func(x *StringLike) string {
+			return "string"
+		},
+		func(x *NumberLike) string {
+			return ProtoNumberType(x.Kind)
+		},
+	)
+}
+
+// ProtoNumberType maps go number kinds to protobuf scalar types.
+// When kind is not known, it's assumed to be float64, since that's how JSON treats numbers.
+func ProtoNumberType(x NumberKind) string {
+	if x == nil {
+		return "double"
+	}
+
+	return MatchNumberKindR1(
+		x,
+		func(x *UInt) string {
+			return "uint64"
+		},
+		func(x *UInt8) string {
+			return "uint32"
+		},
+		func(x *UInt16) string {
+			return "uint32"
+		},
+		func(x *UInt32) string {
+			return "uint32"
+		},
+		func(x *UInt64) string {
+			return "uint64"
+		},
+		func(x *Int) string {
+			return "int64"
+		},
+		func(x *Int8) string {
+			return "int32"
+		},
+		func(x *Int16) string {
+			return "int32"
+		},
+		func(x *Int32) string {
+			return "int32"
+		},
+		func(x *Int64) string {
+			return "int64"
+		},
+		func(x *Float32) string {
+			return "float"
+		},
+		func(x *Float64) string {
+			return "double"
+		},
+	)
+}
+
+func isProtoScalar(typ string) bool {
+	switch typ {
+	case "bool", "string", "bytes",
+		"int32", "int64", "uint32", "uint64",
+		"float", "double":
+		return true
+	}
+
+	return false
+}
+
+func isProtoMapKey(typ string) bool {
+	switch typ {
+	case "bool", "string",
+		"int32", "int64", "uint32", "uint64":
+		return true
+	}
+
+	return false
+}
+
+// ProtoPackageName converts go import path to protobuf package name.
+// example:
+//
+//	github.com/widmogrod/mkunion/x/shape -> github_com.widmogrod.mkunion.x.shape
+func ProtoPackageName(pkgImportName string) string {
+	replace := strings.NewReplacer(".", "_", "-", "_")
+	parts := strings.Split(pkgImportName, "/")
+	for i, part := range parts {
+		parts[i] = replace.Replace(part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// ProtoFileName returns name of .proto file for given go import path
+// and follows the same convention as TypeScript renderer.
+func ProtoFileName(pkgImportName string) string {
+	replace := strings.NewReplacer("/", "_", ".", "_", "-", "_")
+	return replace.Replace(pkgImportName) + ".proto"
+}
+
+// ToProtoFieldName converts go field name to snake_case protobuf field name.
+func ToProtoFieldName(name string) string {
+	result := &strings.Builder{}
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			isNextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			isPrevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			if i > 0 && (isPrevLower || isNextLower) && runes[i-1] != '_' {
+				result.WriteRune('_')
+			}
+			result.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		result.WriteRune(r)
+	}
+
+	return result.String()
+}
@@ -0,0 +1,190 @@
+package shape
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestProtobufSchemaGeneration(t *testing.T) {
+	pr := NewProtobufRenderer(nil)
+	pr.AddShape(&UnionLike{
+		Name:          "Shape",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		Variant: []Shape{
+			&StructLike{
+				Name:          "Circle",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Fields: []*FieldLike{
+					{Name: "Radius", Type: &PrimitiveLike{Kind: &NumberLike{Kind: &Float64{}}}},
+					{Name: "Label", Type: &PointerLike{Type: &PrimitiveLike{Kind: &StringLike{}}}},
+				},
+			},
+			&StructLike{
+				Name:          "Group",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Fields: []*FieldLike{
+					{Name: "Items", Type: &ListLike{Element: &RefName{Name: "Shape", PkgName: "geo", PkgImportName: "example.com/geo"}}},
+					{Name: "Matrix", Type: &ListLike{Element: &ListLike{Element: &PrimitiveLike{Kind: &NumberLike{Kind: &Int{}}}}}},
+					{Name: "ByName", Type: &MapLike{Key: &PrimitiveLike{Kind: &StringLike{}}, Val: &RefName{Name: "Shape", PkgName: "geo", PkgImportName: "example.com/geo"}}},
+					{Name: "Data", Type: &ListLike{Element: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt8{}}}}},
+					{Name: "Meta", Type: &Any{}},
+					{Name: "Owner", Type: &RefName{Name: "User", PkgName: "auth", PkgImportName: "example.com/auth"}},
+				},
+			},
+			&AliasLike{
+				Name:          "Tag",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Type:          &PrimitiveLike{Kind: &StringLike{}},
+			},
+		},
+	})
+
+	result := pr.Render()
+	assert.Len(t, result, 1)
+
+	expected := `// Code generated by mkunion. DO NOT EDIT.
+syntax = "proto3";
+
+package example_com.geo;
+
+import "example_com_auth.proto";
+
+// Shape is generated from union example.com/geo.Shape
+message Shape {
+  oneof value {
+    Circle circle = 1;
+    Group group = 2;
+    Tag tag = 3;
+  }
+}
+
+// Circle is generated from example.com/geo.Circle
+message Circle {
+  double radius = 1;
+  optional string label = 2;
+}
+
+// Group is generated from example.com/geo.Group
+message Group {
+  message MatrixItem {
+    repeated int64 value = 1;
+  }
+  repeated Shape items = 1;
+  repeated MatrixItem matrix = 2;
+  map<string, Shape> by_name = 3;
+  bytes data = 4;
+  bytes meta = 5; // JSON encoded any
+  .example_com.auth.User owner = 6;
+}
+
+// Tag is generated from example.com/geo.Tag
+message Tag {
+  string value = 1;
+}
+`
+	assert.Equal(t, expected, result["example.com/geo"])
+
+	dir := t.TempDir()
+	err := pr.WriteToDir(dir)
+	assert.NoError(t, err)
+	assert.FileExists(t, path.Join(dir, "example_com_geo.proto"))
+}
+
+func TestProtobufSchemaGeneration_WeekAlias(t *testing.T) {
+	StoreShapeOnDisk(&AliasLike{
+		Name:          "StepID",
+		PkgName:       "flow",
+		PkgImportName: "example.com/flow",
+		IsAlias:       true,
+		Type:          &PrimitiveLike{Kind: &StringLike{}},
+	})
+
+	stepID := &RefName{Name: "StepID", PkgName: "flow", PkgImportName: "example.com/flow"}
+
+	pr := NewProtobufRenderer(nil)
+	pr.AddShape(&StructLike{
+		Name:          "Step",
+		PkgName:       "flow",
+		PkgImportName: "example.com/flow",
+		Fields: []*FieldLike{
+			{Name: "ID", Type: stepID},
+			{Name: "Next", Type: &ListLike{Element: stepID}},
+			{Name: "Prev", Type: &PointerLike{Type: stepID}},
+		},
+	})
+
+	expected := `// Code generated by mkunion. DO NOT EDIT.
+syntax = "proto3";
+
+package example_com.flow;
+
+// Step is generated from example.com/flow.Step
+message Step {
+  string id = 1;
+  repeated string next = 2;
+  optional string prev = 3;
+}
+`
+	assert.Equal(t, expected, pr.Render()["example.com/flow"])
+}
+
+func TestProtoLock_Assign(t *testing.T) {
+	lock := NewProtoLock()
+
+	numbers := lock.Assign("pkg.A", []string{"Name", "Age"})
+	assert.Equal(t, map[string]int{"Name": 1, "Age": 2}, numbers)
+
+	// reordering fields don't change numbers, and new fields get next number
+	numbers = lock.Assign("pkg.A", []string{"Email", "Age", "Name"})
+	assert.Equal(t, map[string]int{"Name": 1, "Age": 2, "Email": 3}, numbers)
+
+	// removed fields are reserved, and never reused
+	numbers = lock.Assign("pkg.A", []string{"Email", "Phone"})
+	assert.Equal(t, map[string]int{"Email": 3, "Phone": 4}, numbers)
+	assert.Equal(t, []int{1, 2}, lock.Messages["pkg.A"].Reserved)
+	assert.Equal(t, []string{"Age", "Name"}, lock.Messages["pkg.A"].ReservedNames)
+
+	filename := path.Join(t.TempDir(), ProtoLockFileName)
+	err := lock.Save(filename)
+	assert.NoError(t, err)
+
+	loaded, err := LoadProtoLock(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, lock, loaded)
+
+	// re-added field gets new number, and only its previous number stays reserved
+	numbers = loaded.Assign("pkg.A", []string{"Email", "Phone", "Name"})
+	assert.Equal(t, map[string]int{"Email": 3, "Phone": 4, "Name": 5}, numbers)
+	assert.Equal(t, []int{1, 2}, loaded.Messages["pkg.A"].Reserved)
+	assert.Equal(t, []string{"Age"}, loaded.Messages["pkg.A"].ReservedNames)
+}
+
+func TestLoadProtoLock_MissingFile(t *testing.T) {
+	lock, err := LoadProtoLock(path.Join(t.TempDir(), "missing.lock"))
+	assert.NoError(t, err)
+	assert.Equal(t, NewProtoLock(), lock)
+}
+
+func TestProtoLock_FindProtoLockFile(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(path.Join(dir, "a", "b"), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(path.Join(dir, "go.mod"), []byte("module example.com/a\n"), 0644)
+	assert.NoError(t, err)
+
+	assert.Equal(t, path.Join(dir, ProtoLockFileName), FindProtoLockFile(path.Join(dir, "a", "b")))
+}
+
+func TestToProtoFieldName(t *testing.T) {
+	assert.Equal(t, "name", ToProtoFieldName("Name"))
+	assert.Equal(t, "by_name", ToProtoFieldName("ByName"))
+	assert.Equal(t, "http_server_id", ToProtoFieldName("HTTPServerID"))
+	assert.Equal(t, "list_of2", ToProtoFieldName("ListOf2"))
+	assert.Equal(t, "already_snake", ToProtoFieldName("already_snake"))
+}
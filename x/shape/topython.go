@@ -0,0 +1,670 @@
+package shape
+
+import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PythonInitFileName makes output directory a Python package, so that modules can import each other.
+const PythonInitFileName = "__init__.py"
+
+type PythonOptions struct {
+	currentPkgName       string
+	currentPkgImportName packageImportName
+	imports              map[packageName]packageImportName
+}
+
+func (o *PythonOptions) IsCurrentPkgName(pkgName string) bool {
+	if pkgName == "" {
+		return true
+	}
+
+	return o.currentPkgName == pkgName
+}
+
+func (o *PythonOptions) NeedsToImportPkgName(pkg packageName, imp packageImportName) {
+	if o.imports == nil {
+		o.imports = make(map[packageName]packageImportName)
+	}
+
+	o.imports[pkg] = imp
+}
+
+// pythonAlias is module level assignment, like union or named type.
+// Unlike class annotations, assignment is evaluated on import, so aliases that depend on each other must be ordered.
+type pythonAlias struct {
+	name    string
+	content string
+	deps    []string
+}
+
+// NewPythonRenderer renders pydantic models, one module per Go package.
+// Unions are discriminated on "$type" field, the same way as generated JSON serde.
+func NewPythonRenderer() *PythonRenderer {
+	return &PythonRenderer{
+		imports:    make(map[packageImportName]*PythonOptions),
+		classes:    make(map[packageImportName]*strings.Builder),
+		aliases:    make(map[packageImportName][]pythonAlias),
+		typeVars:   make(map[packageImportName]map[string]bool),
+		shapeAdded: make(map[shapeName]bool),
+	}
+}
+
+type PythonRenderer struct {
+	imports    map[packageImportName]*PythonOptions
+	classes    map[packageImportName]*strings.Builder
+	aliases    map[packageImportName][]pythonAlias
+	typeVars   map[packageImportName]map[string]bool
+	shapeAdded map[shapeName]bool
+}
+
+func (r *PythonRenderer) AddShape(x Shape) {
+	if x == nil {
+		return
+	}
+
+	// don't add shape twice
+	key := ToGoTypeName(x, WithPkgImportName())
+	if r.shapeAdded[key] {
+		return
+	}
+	r.shapeAdded[key] = true
+
+	MatchShapeR0(
+		x,
+		func(x *Any) {
+			log.Infof("topython: AddShape Any is not supported")
+		},
+		func(x *RefName) {
+			// reference is rendered, when followed to its definition
+		},
+		func(x *PointerLike) {
+			r.AddShape(x.Type)
+		},
+		func(x *AliasLike) {
+			options := r.initImportsFor(x.PkgName, x.PkgImportName)
+			r.addAlias(x.PkgImportName, pythonAlias{
+				name:    toPythonName(x.Name),
+				content: fmt.Sprintf("%s = %s\n", toPythonName(x.Name), ToPython(x.Type, options)),
+				deps:    toPythonDeps(x.Type, options),
+			})
+		},
+		func(x *PrimitiveLike) {
+			log.Infof("topython: AddShape PrimitiveLike is not supported")
+		},
+		func(x *ListLike) {
+			log.Infof("topython: AddShape ListLike is not supported")
+		},
+		func(x *MapLike) {
+			log.Infof("topython: AddShape MapLike is not supported")
+		},
+		func(x *StructLike) {
+			options := r.initImportsFor(x.PkgName, x.PkgImportName)
+			r.addTypeVars(x.PkgImportName, x.TypeParams)
+
+			contents := r.initClassesFor(x.PkgImportName)
+			contents.WriteString(toPythonClass(x, options))
+			contents.WriteString("\n\n")
+		},
+		func(x *UnionLike) {
+			options := r.initImportsFor(x.PkgName, x.PkgImportName)
+			r.addTypeVars(x.PkgImportName, x.TypeParams)
+
+			encoding, err := UnionJSONEncoding(x)
+			if err != nil {
+				panic(fmt.Errorf("shape.ToPython: %w", err))
+			}
+
+			contents := r.initClassesFor(x.PkgImportName)
+			var envelopes []string
+			for _, variant := range x.Variant {
+				if encoding.Kind == JSONUnionUntagged {
+					envelopes = append(envelopes, toPythonName(Name(variant))+toPythonTypeParams(ExtractTypeParams(variant)))
+					continue
+				}
+
+				contents.WriteString(toPythonEnvelope(x, variant, encoding, options))
+				contents.WriteString("\n\n")
+				envelopes = append(envelopes, toPythonEnvelopeName(x, variant)+toPythonTypeParams(x.TypeParams))
+			}
+
+			var value string
+			switch {
+			case len(envelopes) == 0:
+				value = "typing.Any"
+			case len(envelopes) == 1:
+				value = envelopes[0]
+			case encoding.Kind == JSONUnionUntagged:
+				value = fmt.Sprintf("typing.Union[%s]", strings.Join(envelopes, ", "))
+			default:
+				value = fmt.Sprintf("typing.Annotated[typing.Union[%s], pydantic.Field(discriminator=\"type\")]", strings.Join(envelopes, ", "))
+			}
+
+			r.addAlias(x.PkgImportName, pythonAlias{
+				name:    toPythonName(x.Name),
+				content: fmt.Sprintf("%s = %s\n", toPythonName(x.Name), value),
+			})
+
+			for _, variant := range x.Variant {
+				r.AddShape(variant)
+			}
+		},
+	)
+
+	r.FollowRef(x)
+}
+
+func (r *PythonRenderer) FollowRef(x Shape) {
+	refs := ExtractRefs(x)
+	for _, ref := range refs {
+		if _, ok := LookupTypeMapper(ref); ok {
+			// mapped type is rendered as its wire type, and has no declaration
+			continue
+		}
+
+		log.Debugf("topython: FollowRef %s", ToGoTypeName(ref))
+		x, found := LookupShapeOnDisk(ref)
+		if found {
+			r.AddShape(x)
+		}
+	}
+}
+
+func (r *PythonRenderer) FollowImports() {
+	for _, options := range r.imports {
+		for _, imp := range options.imports {
+			log.Debugf("topython: FollowImports %s", imp)
+			shapes := LookupPkgShapeOnDisk(imp)
+			for _, shape := range shapes {
+				r.AddShape(shape)
+			}
+		}
+	}
+}
+
+func (r *PythonRenderer) initImportsFor(pkgName, pkgImportName string) *PythonOptions {
+	if _, ok := r.imports[pkgImportName]; ok {
+		return r.imports[pkgImportName]
+	}
+
+	r.imports[pkgImportName] = &PythonOptions{
+		currentPkgName:       pkgName,
+		currentPkgImportName: pkgImportName,
+		imports:              make(map[packageName]packageImportName),
+	}
+
+	return r.imports[pkgImportName]
+}
+
+func (r *PythonRenderer) initClassesFor(pkgImportName string) *strings.Builder {
+	if _, ok := r.classes[pkgImportName]; !ok {
+		r.classes[pkgImportName] = &strings.Builder{}
+	}
+
+	return r.classes[pkgImportName]
+}
+
+func (r *PythonRenderer) addAlias(pkgImportName string, alias pythonAlias) {
+	r.initClassesFor(pkgImportName)
+	r.aliases[pkgImportName] = append(r.aliases[pkgImportName], alias)
+}
+
+func (r *PythonRenderer) addTypeVars(pkgImportName string, params []TypeParam) {
+	if _, ok := r.typeVars[pkgImportName]; !ok {
+		r.typeVars[pkgImportName] = make(map[string]bool)
+	}
+
+	for _, param := range params {
+		r.typeVars[pkgImportName][param.Name] = true
+	}
+}
+
+func (r *PythonRenderer) WriteToDir(dir string) error {
+	sorted := make([]string, 0, len(r.classes))
+	for pkgImportName := range r.classes {
+		sorted = append(sorted, pkgImportName)
+	}
+	sort.Strings(sorted)
+
+	for _, pkgImportName := range sorted {
+		imports := r.imports[pkgImportName]
+		if imports == nil {
+			continue
+		}
+
+		content := &strings.Builder{}
+		content.WriteString("# generated by mkunion\n")
+		content.WriteString("from __future__ import annotations\n\n")
+		content.WriteString("import typing\n\n")
+		content.WriteString("import pydantic\n")
+
+		sortedImports := make([]string, 0, len(imports.imports))
+		for pkg := range imports.imports {
+			sortedImports = append(sortedImports, pkg)
+		}
+		sort.Strings(sortedImports)
+
+		if len(sortedImports) > 0 {
+			content.WriteString("\n")
+		}
+		for _, pkg := range sortedImports {
+			_, err := fmt.Fprintf(content, "from . import %s as %s\n", r.normaliseImport(imports.imports[pkg]), toPythonName(pkg))
+			if err != nil {
+				return fmt.Errorf("topython: WriteToDir failed to write imports: %w", err)
+			}
+		}
+
+		typeVars := make([]string, 0, len(r.typeVars[pkgImportName]))
+		for name := range r.typeVars[pkgImportName] {
+			typeVars = append(typeVars, name)
+		}
+		sort.Strings(typeVars)
+
+		if len(typeVars) > 0 {
+			content.WriteString("\n")
+		}
+		for _, name := range typeVars {
+			_, _ = fmt.Fprintf(content, "%s = typing.TypeVar(%q)\n", name, name)
+		}
+
+		content.WriteString("\n\n")
+		content.WriteString(r.classes[pkgImportName].String())
+
+		for _, alias := range sortPythonAliases(r.aliases[pkgImportName]) {
+			content.WriteString(alias.content)
+		}
+
+		err := r.writeToFile(dir, r.normaliseImport(pkgImportName), strings.TrimRight(content.String(), "\n")+"\n")
+		if err != nil {
+			return fmt.Errorf("topython: WriteToDir failed to write file %s: %w", dir, err)
+		}
+	}
+
+	err := r.writeInitFile(dir)
+	if err != nil {
+		return fmt.Errorf("topython: WriteToDir failed to write %s: %w", PythonInitFileName, err)
+	}
+
+	return nil
+}
+
+func (r *PythonRenderer) writeToFile(dir string, name string, content string) error {
+	filename := path.Join(dir, fmt.Sprintf("%s.py", name))
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filename, []byte(content), 0644)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeInitFile don't overwrite existing file, since it may re-export modules
+func (r *PythonRenderer) writeInitFile(dir string) error {
+	filename := path.Join(dir, PythonInitFileName)
+	if _, err := os.Stat(filename); err == nil {
+		return nil
+	}
+
+	return r.writeToFile(dir, strings.TrimSuffix(PythonInitFileName, ".py"), "")
+}
+
+func (r *PythonRenderer) normaliseImport(imp packageImportName) string {
+	replace := strings.NewReplacer("/", "_", ".", "_", "-", "_")
+	return replace.Replace(imp)
+}
+
+// sortPythonAliases keeps order in which aliases were added, except that alias is moved after aliases it depends on.
+func sortPythonAliases(aliases []pythonAlias) []pythonAlias {
+	byName := make(map[string]pythonAlias)
+	for _, alias := range aliases {
+		byName[alias.name] = alias
+	}
+
+	var result []pythonAlias
+	visited := make(map[string]bool)
+	var visit func(alias pythonAlias)
+	visit = func(alias pythonAlias) {
+		if visited[alias.name] {
+			return
+		}
+		visited[alias.name] = true
+
+		for _, dep := range alias.deps {
+			if depAlias, ok := byName[dep]; ok {
+				visit(depAlias)
+			}
+		}
+
+		result = append(result, alias)
+	}
+
+	for _, alias := range aliases {
+		visit(alias)
+	}
+
+	return result
+}
+
+// ToPython returns Python type annotation of a shape.
+func ToPython(x Shape, option *PythonOptions) string {
+	return MatchShapeR1(
+		x,
+		func(x *Any) string {
+			return "typing.Any"
+		},
+		func(x *RefName) string {
+			if mapper, ok := LookupTypeMapper(x); ok {
+				return ToPython(mapper.Wire, option)
+			}
+
+			return toPythonRef(x.Name, x.PkgName, x.PkgImportName, x.Indexed, option)
+		},
+		func(x *PointerLike) string {
+			return fmt.Sprintf("typing.Optional[%s]", ToPython(x.Type, option))
+		},
+		func(x *AliasLike) string {
+			return toPythonRef(x.Name, x.PkgName, x.PkgImportName, nil, option)
+		},
+		func(x *PrimitiveLike) string {
+			return MatchPrimitiveKindR1(
+				x.Kind,
+				func(x *BooleanLike) string {
+					return "bool"
+				},
+				func(x *StringLike) string {
+					return "str"
+				},
+				func(x *NumberLike) string {
+					switch x.Kind.(type) {
+					case *Float32, *Float64, nil:
+						return "float"
+					}
+					return "int"
+				},
+			)
+		},
+		func(x *ListLike) string {
+			// JSON encodes bytes as base64 string
+			if IsBinary(x) && x.ArrayLen == nil {
+				return "str"
+			}
+
+			return fmt.Sprintf("typing.List[%s]", ToPython(x.Element, option))
+		},
+		func(x *MapLike) string {
+			return fmt.Sprintf("typing.Dict[%s, %s]", ToPython(x.Key, option), ToPython(x.Val, option))
+		},
+		func(x *StructLike) string {
+			// anonymous struct doesn't have name, that model could use
+			return "typing.Dict[str, typing.Any]"
+		},
+		func(x *UnionLike) string {
+			return toPythonRef(x.Name, x.PkgName, x.PkgImportName, nil, option)
+		},
+	)
+}
+
+func toPythonRef(name, pkgName, pkgImportName string, indexed []Shape, option *PythonOptions) string {
+	if pkgName == "" {
+		// type parameter
+		return name
+	}
+
+	prefix := ""
+	if !option.IsCurrentPkgName(pkgName) {
+		prefix = toPythonName(pkgName) + "."
+		option.NeedsToImportPkgName(pkgName, pkgImportName)
+	}
+
+	if len(indexed) > 0 {
+		var names []string
+		for _, index := range indexed {
+			names = append(names, ToPython(index, option))
+		}
+		return fmt.Sprintf("%s%s[%s]", prefix, toPythonName(name), strings.Join(names, ", "))
+	}
+
+	return prefix + toPythonName(name)
+}
+
+// toPythonDeps returns names of types from the same module, that annotation refers to
+func toPythonDeps(x Shape, option *PythonOptions) []string {
+	var result []string
+	for _, ref := range ExtractRefs(x) {
+		if ref.PkgName != "" && option.IsCurrentPkgName(ref.PkgName) {
+			result = append(result, toPythonName(ref.Name))
+		}
+	}
+
+	return result
+}
+
+func toPythonTypeParams(params []TypeParam) string {
+	if len(params) == 0 {
+		return ""
+	}
+
+	var names []string
+	for _, param := range params {
+		names = append(names, param.Name)
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(names, ", "))
+}
+
+func toPythonClassHeader(name string, params []TypeParam) string {
+	if len(params) == 0 {
+		return fmt.Sprintf("class %s(pydantic.BaseModel):\n", name)
+	}
+
+	var names []string
+	for _, param := range params {
+		names = append(names, param.Name)
+	}
+
+	return fmt.Sprintf("class %s(pydantic.BaseModel, typing.Generic[%s]):\n", name, strings.Join(names, ", "))
+}
+
+func toPythonClass(x *StructLike, option *PythonOptions) string {
+	result := &strings.Builder{}
+	result.WriteString(toPythonClassHeader(toPythonName(x.Name), x.TypeParams))
+	result.WriteString("    model_config = pydantic.ConfigDict(populate_by_name=True)\n")
+
+	if len(x.Fields) > 0 {
+		result.WriteString("\n")
+	}
+
+	for _, field := range StructFields(x, LookupShapeOnDisk) {
+		jsonName := field.JSONName()
+		typ := toPythonFieldType(field.FieldLike, option)
+
+		var args []string
+		if IsPointer(field.Type) || field.MayBeOmitted() || !IsRequired(field.Guard) {
+			if !IsPointer(field.Type) {
+				typ = fmt.Sprintf("typing.Optional[%s]", typ)
+			}
+			args = append(args, "default=None")
+		}
+
+		attrName := toPythonFieldName(field.Name)
+		if attrName != jsonName {
+			args = append(args, fmt.Sprintf("alias=%s", strconv.Quote(jsonName)))
+		}
+		if field.Desc != nil {
+			args = append(args, fmt.Sprintf("description=%s", strconv.Quote(*field.Desc)))
+		}
+
+		if len(args) == 0 {
+			_, _ = fmt.Fprintf(result, "    %s: %s\n", attrName, typ)
+		} else {
+			_, _ = fmt.Fprintf(result, "    %s: %s = pydantic.Field(%s)\n", attrName, typ, strings.Join(args, ", "))
+		}
+	}
+
+	return result.String()
+}
+
+// toPythonEnvelope renders model of union variant, that has the same shape as JSON serde output,
+// which by default is {"$type": "pkg.Variant", "pkg.Variant": {...}}.
+// Internally tagged variant extends model of variant with tag field.
+func toPythonEnvelope(union *UnionLike, variant Shape, encoding *JSONUnionEncoding, option *PythonOptions) string {
+	variantName := VariantDiscriminator(variant)
+	variantType := toPythonName(Name(variant)) + toPythonTypeParams(ExtractTypeParams(variant))
+
+	result := &strings.Builder{}
+	if encoding.Kind == JSONUnionInternal {
+		result.WriteString(strings.Replace(
+			toPythonClassHeader(toPythonEnvelopeName(union, variant), union.TypeParams),
+			"pydantic.BaseModel", variantType, 1))
+	} else {
+		result.WriteString(toPythonClassHeader(toPythonEnvelopeName(union, variant), union.TypeParams))
+	}
+	result.WriteString("    model_config = pydantic.ConfigDict(populate_by_name=True)\n\n")
+	_, _ = fmt.Fprintf(result, "    type: typing.Literal[%s] = pydantic.Field(default=%s, alias=%s)\n",
+		strconv.Quote(variantName),
+		strconv.Quote(variantName),
+		strconv.Quote(encoding.Tag),
+	)
+
+	switch encoding.Kind {
+	case JSONUnionExternal:
+		_, _ = fmt.Fprintf(result, "    value: %s = pydantic.Field(alias=%s)\n", variantType, strconv.Quote(variantName))
+	case JSONUnionAdjacent:
+		_, _ = fmt.Fprintf(result, "    value: %s = pydantic.Field(alias=%s)\n", variantType, strconv.Quote(encoding.Content))
+	}
+
+	return result.String()
+}
+
+func toPythonEnvelopeName(union *UnionLike, variant Shape) string {
+	return fmt.Sprintf("%s_%s", union.Name, Name(variant))
+}
+
+// toPythonFieldType refines primitive field with enum guard to literal type,
+// and annotates field with constraints of remaining guards.
+func toPythonFieldType(field *FieldLike, option *PythonOptions) string {
+	typ := field.Type
+	if ptr, ok := typ.(*PointerLike); ok {
+		typ = ptr.Type
+	}
+
+	result := ToPython(typ, option)
+	if literal, ok := toPythonLiteral(typ, toTypeScriptEnum(field.Guard)); ok {
+		result = literal
+	}
+
+	if constraints := toPythonConstraints(field.Guard); len(constraints) > 0 {
+		result = fmt.Sprintf("typing.Annotated[%s, pydantic.Field(%s)]", result, strings.Join(constraints, ", "))
+	}
+
+	if IsPointer(field.Type) {
+		result = fmt.Sprintf("typing.Optional[%s]", result)
+	}
+
+	return result
+}
+
+func toPythonLiteral(x Shape, enum []string) (string, bool) {
+	primitive, ok := x.(*PrimitiveLike)
+	if len(enum) == 0 || !ok {
+		return "", false
+	}
+
+	var literals []string
+	for _, val := range enum {
+		switch primitive.Kind.(type) {
+		case *StringLike:
+			literals = append(literals, strconv.Quote(val))
+		case *NumberLike:
+			if _, err := strconv.ParseFloat(val, 64); err != nil {
+				return "", false
+			}
+			literals = append(literals, val)
+		default:
+			return "", false
+		}
+	}
+
+	return fmt.Sprintf("typing.Literal[%s]", strings.Join(literals, ", ")), true
+}
+
+func toPythonConstraints(guard Guard) []string {
+	if guard == nil {
+		return nil
+	}
+
+	return MatchGuardR1(
+		guard,
+		func(x *Enum) []string {
+			return nil
+		},
+		func(x *Required) []string {
+			return nil
+		},
+		func(x *Regexp) []string {
+			return []string{"pattern=" + strconv.Quote(x.Regexp)}
+		},
+		func(x *Between) []string {
+			return []string{
+				fmt.Sprintf("ge=%v", x.Min),
+				fmt.Sprintf("le=%v", x.Max),
+			}
+		},
+		func(x *MinLength) []string {
+			return []string{fmt.Sprintf("min_length=%d", x.Len)}
+		},
+		func(x *MaxLength) []string {
+			return []string{fmt.Sprintf("max_length=%d", x.Len)}
+		},
+		func(x *MinItems) []string {
+			return []string{fmt.Sprintf("min_length=%d", x.Len)}
+		},
+		func(x *MaxItems) []string {
+			return []string{fmt.Sprintf("max_length=%d", x.Len)}
+		},
+		func(x *AndGuard) []string {
+			var result []string
+			for _, guard := range x.L {
+				result = append(result, toPythonConstraints(guard)...)
+			}
+			return result
+		},
+	)
+}
+
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+	"async": true, "await": true, "break": true, "class": true, "continue": true, "def": true,
+	"del": true, "elif": true, "else": true, "except": true, "finally": true, "for": true,
+	"from": true, "global": true, "if": true, "import": true, "in": true, "is": true,
+	"lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// toPythonName escapes Go identifiers, that are keywords in Python, like None
+func toPythonName(name string) string {
+	if pythonKeywords[name] {
+		return name + "_"
+	}
+
+	return name
+}
+
+// toPythonFieldName escapes also names, that pydantic reserves for model configuration
+func toPythonFieldName(name string) string {
+	if name == "Config" {
+		return name + "_"
+	}
+
+	return toPythonName(name)
+}
@@ -0,0 +1,238 @@
+package shape
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestPythonGeneration(t *testing.T) {
+	desc := "radius in meters"
+
+	r := NewPythonRenderer()
+	r.AddShape(&UnionLike{
+		Name:          "Shape",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		Variant: []Shape{
+			&StructLike{
+				Name:          "Circle",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Fields: []*FieldLike{
+					{
+						Name:  "Radius",
+						Type:  &PrimitiveLike{Kind: &NumberLike{Kind: &Float64{}}},
+						Desc:  &desc,
+						Guard: &AndGuard{L: []Guard{&Required{}, &Between{Min: 0, Max: 100}}},
+						Tags:  map[string]Tag{"json": {Value: "radius"}},
+					},
+					{Name: "Hidden", Type: &PrimitiveLike{Kind: &BooleanLike{}}, Tags: map[string]Tag{"json": {Value: "-"}}},
+				},
+			},
+			&StructLike{
+				Name:          "Group",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Fields: []*FieldLike{
+					{Name: "Items", Type: &ListLike{Element: &RefName{Name: "Shape", PkgName: "geo", PkgImportName: "example.com/geo"}}, Guard: &MinItems{Len: 1}},
+					{Name: "Data", Type: &ListLike{Element: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt8{}}}}},
+					{Name: "Meta", Type: &MapLike{Key: &PrimitiveLike{Kind: &StringLike{}}, Val: &Any{}}},
+					{Name: "Count", Type: &PointerLike{Type: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt16{}}}}},
+					{Name: "Kind", Type: &PrimitiveLike{Kind: &StringLike{}}, Guard: &AndGuard{L: []Guard{&Required{}, &Enum{Val: []string{"a", "b"}}}}},
+					{Name: "Config", Type: &RefName{Name: "Settings", PkgName: "conf", PkgImportName: "example.com/conf"}, Guard: &Required{}},
+				},
+			},
+			&AliasLike{
+				Name:          "Tag",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Type:          &PrimitiveLike{Kind: &StringLike{}},
+			},
+		},
+	})
+	r.AddShape(&AliasLike{
+		Name:          "Tags",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		Type:          &ListLike{Element: &RefName{Name: "None", PkgName: "geo", PkgImportName: "example.com/geo"}},
+	})
+	r.AddShape(&AliasLike{
+		Name:          "None",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		Type:          &PrimitiveLike{Kind: &StringLike{}},
+	})
+	r.AddShape(&UnionLike{
+		Name:          "Result",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		TypeParams:    []TypeParam{{Name: "T", Type: &Any{}}},
+		Variant: []Shape{
+			&StructLike{
+				Name:          "Ok",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				TypeParams:    []TypeParam{{Name: "T", Type: &Any{}}},
+				Fields: []*FieldLike{
+					{Name: "Value", Type: &RefName{Name: "T"}, Guard: &Required{}},
+				},
+			},
+			&StructLike{
+				Name:          "Err",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				TypeParams:    []TypeParam{{Name: "T", Type: &Any{}}},
+			},
+		},
+	})
+
+	dir := t.TempDir()
+	err := r.WriteToDir(dir)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path.Join(dir, "example_com_geo.py"))
+	assert.NoError(t, err)
+	assert.Equal(t, `# generated by mkunion
+from __future__ import annotations
+
+import typing
+
+import pydantic
+
+from . import example_com_conf as conf
+
+T = typing.TypeVar("T")
+
+
+class Shape_Circle(pydantic.BaseModel):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+    type: typing.Literal["geo.Circle"] = pydantic.Field(default="geo.Circle", alias="$type")
+    value: Circle = pydantic.Field(alias="geo.Circle")
+
+
+class Shape_Group(pydantic.BaseModel):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+    type: typing.Literal["geo.Group"] = pydantic.Field(default="geo.Group", alias="$type")
+    value: Group = pydantic.Field(alias="geo.Group")
+
+
+class Shape_Tag(pydantic.BaseModel):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+    type: typing.Literal["geo.Tag"] = pydantic.Field(default="geo.Tag", alias="$type")
+    value: Tag = pydantic.Field(alias="geo.Tag")
+
+
+class Circle(pydantic.BaseModel):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+    Radius: typing.Annotated[float, pydantic.Field(ge=0, le=100)] = pydantic.Field(alias="radius", description="radius in meters")
+
+
+class Group(pydantic.BaseModel):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+    Items: typing.Optional[typing.Annotated[typing.List[Shape], pydantic.Field(min_length=1)]] = pydantic.Field(default=None)
+    Data: typing.Optional[str] = pydantic.Field(default=None)
+    Meta: typing.Optional[typing.Dict[str, typing.Any]] = pydantic.Field(default=None)
+    Count: typing.Optional[int] = pydantic.Field(default=None)
+    Kind: typing.Literal["a", "b"]
+    Config_: conf.Settings = pydantic.Field(alias="Config")
+
+
+class Result_Ok(pydantic.BaseModel, typing.Generic[T]):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+    type: typing.Literal["geo.Ok"] = pydantic.Field(default="geo.Ok", alias="$type")
+    value: Ok[T] = pydantic.Field(alias="geo.Ok")
+
+
+class Result_Err(pydantic.BaseModel, typing.Generic[T]):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+    type: typing.Literal["geo.Err"] = pydantic.Field(default="geo.Err", alias="$type")
+    value: Err[T] = pydantic.Field(alias="geo.Err")
+
+
+class Ok(pydantic.BaseModel, typing.Generic[T]):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+    Value: T
+
+
+class Err(pydantic.BaseModel, typing.Generic[T]):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+
+Shape = typing.Annotated[typing.Union[Shape_Circle, Shape_Group, Shape_Tag], pydantic.Field(discriminator="type")]
+Tag = str
+None_ = str
+Tags = typing.List[None_]
+Result = typing.Annotated[typing.Union[Result_Ok[T], Result_Err[T]], pydantic.Field(discriminator="type")]
+`, string(content))
+
+	_, err = os.Stat(path.Join(dir, PythonInitFileName))
+	assert.NoError(t, err)
+}
+
+func TestPythonUnionEncoding(t *testing.T) {
+	union := func(encoding Tag) *UnionLike {
+		return &UnionLike{
+			Name:          "Figure",
+			PkgName:       "app",
+			PkgImportName: "example.com/app",
+			Variant: []Shape{
+				&StructLike{Name: "Circle", PkgName: "app", PkgImportName: "example.com/app", Tags: map[string]Tag{
+					TagVariantName: {Value: "circle"},
+				}},
+				&StructLike{Name: "Square", PkgName: "app", PkgImportName: "example.com/app"},
+			},
+			Tags: map[string]Tag{
+				TagUnionName:     {Value: "Figure"},
+				TagUnionJSONName: encoding,
+			},
+		}
+	}
+
+	render := func(t *testing.T, x Shape) string {
+		r := NewPythonRenderer()
+		r.AddShape(x)
+
+		dir := t.TempDir()
+		assert.NoError(t, r.WriteToDir(dir))
+
+		content, err := os.ReadFile(path.Join(dir, "example_com_app.py"))
+		assert.NoError(t, err)
+		return string(content)
+	}
+
+	t.Run("internal", func(t *testing.T) {
+		content := render(t, union(Tag{Value: JSONUnionInternal, Options: []string{"tag=kind"}}))
+		assert.Contains(t, content, `class Figure_Circle(Circle):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+    type: typing.Literal["circle"] = pydantic.Field(default="circle", alias="kind")
+`)
+		assert.Contains(t, content, `Figure = typing.Annotated[typing.Union[Figure_Circle, Figure_Square], pydantic.Field(discriminator="type")]`)
+	})
+
+	t.Run("adjacent", func(t *testing.T) {
+		content := render(t, union(Tag{Value: JSONUnionAdjacent, Options: []string{"tag=t", "content=c"}}))
+		assert.Contains(t, content, `class Figure_Circle(pydantic.BaseModel):
+    model_config = pydantic.ConfigDict(populate_by_name=True)
+
+    type: typing.Literal["circle"] = pydantic.Field(default="circle", alias="t")
+    value: Circle = pydantic.Field(alias="c")
+`)
+	})
+
+	t.Run("untagged", func(t *testing.T) {
+		content := render(t, union(Tag{Value: JSONUnionUntagged}))
+		assert.NotContains(t, content, "Figure_Circle")
+		assert.Contains(t, content, `Figure = typing.Union[Circle, Square]`)
+	})
+}
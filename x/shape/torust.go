@@ -0,0 +1,582 @@
+package shape
+
+import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RustModFileName declares generated modules, so that output directory can be used as Rust module.
+const RustModFileName = "mod.rs"
+
+type RustOptions struct {
+	currentPkgName       string
+	currentPkgImportName packageImportName
+	imports              map[packageName]packageImportName
+}
+
+func (o *RustOptions) IsCurrentPkgName(pkgName string) bool {
+	if pkgName == "" {
+		return true
+	}
+
+	return o.currentPkgName == pkgName
+}
+
+func (o *RustOptions) NeedsToImportPkgName(pkg packageName, imp packageImportName) {
+	if o.imports == nil {
+		o.imports = make(map[packageName]packageImportName)
+	}
+
+	o.imports[pkg] = imp
+}
+
+// NewRustRenderer renders serde types, one module per Go package.
+// Unions are enums tagged on "$type" field, with variant payload under variant name,
+// the same way as generated JSON serde.
+func NewRustRenderer() *RustRenderer {
+	return &RustRenderer{
+		imports:    make(map[packageImportName]*RustOptions),
+		contents:   make(map[packageImportName]*strings.Builder),
+		shapeAdded: make(map[shapeName]bool),
+	}
+}
+
+type RustRenderer struct {
+	imports    map[packageImportName]*RustOptions
+	contents   map[packageImportName]*strings.Builder
+	shapeAdded map[shapeName]bool
+}
+
+func (r *RustRenderer) AddShape(x Shape) {
+	if x == nil {
+		return
+	}
+
+	// don't add shape twice
+	key := ToGoTypeName(x, WithPkgImportName())
+	if r.shapeAdded[key] {
+		return
+	}
+	r.shapeAdded[key] = true
+
+	MatchShapeR0(
+		x,
+		func(x *Any) {
+			log.Infof("torust: AddShape Any is not supported")
+		},
+		func(x *RefName) {
+			// reference is rendered, when followed to its definition
+		},
+		func(x *PointerLike) {
+			r.AddShape(x.Type)
+		},
+		func(x *AliasLike) {
+			options := r.initImportsFor(x.PkgName, x.PkgImportName)
+			contents := r.initContentsFor(x.PkgImportName)
+			_, _ = fmt.Fprintf(contents, "pub type %s%s = %s;\n\n",
+				toRustName(x.Name),
+				toRustTypeParams(x.TypeParams),
+				ToRust(x.Type, options),
+			)
+		},
+		func(x *PrimitiveLike) {
+			log.Infof("torust: AddShape PrimitiveLike is not supported")
+		},
+		func(x *ListLike) {
+			log.Infof("torust: AddShape ListLike is not supported")
+		},
+		func(x *MapLike) {
+			log.Infof("torust: AddShape MapLike is not supported")
+		},
+		func(x *StructLike) {
+			options := r.initImportsFor(x.PkgName, x.PkgImportName)
+			contents := r.initContentsFor(x.PkgImportName)
+			contents.WriteString(toRustStruct(x, options))
+			contents.WriteString("\n")
+		},
+		func(x *UnionLike) {
+			options := r.initImportsFor(x.PkgName, x.PkgImportName)
+			contents := r.initContentsFor(x.PkgImportName)
+			contents.WriteString(toRustEnum(x, options))
+			contents.WriteString("\n")
+
+			for _, variant := range x.Variant {
+				r.AddShape(variant)
+			}
+		},
+	)
+
+	r.FollowRef(x)
+}
+
+func (r *RustRenderer) FollowRef(x Shape) {
+	refs := ExtractRefs(x)
+	for _, ref := range refs {
+		if _, ok := LookupTypeMapper(ref); ok {
+			// mapped type is rendered as its wire type, and has no declaration
+			continue
+		}
+
+		log.Debugf("torust: FollowRef %s", ToGoTypeName(ref))
+		x, found := LookupShapeOnDisk(ref)
+		if found {
+			r.AddShape(x)
+		}
+	}
+}
+
+func (r *RustRenderer) FollowImports() {
+	for _, options := range r.imports {
+		for _, imp := range options.imports {
+			log.Debugf("torust: FollowImports %s", imp)
+			shapes := LookupPkgShapeOnDisk(imp)
+			for _, shape := range shapes {
+				r.AddShape(shape)
+			}
+		}
+	}
+}
+
+func (r *RustRenderer) initImportsFor(pkgName, pkgImportName string) *RustOptions {
+	if _, ok := r.imports[pkgImportName]; ok {
+		return r.imports[pkgImportName]
+	}
+
+	r.imports[pkgImportName] = &RustOptions{
+		currentPkgName:       pkgName,
+		currentPkgImportName: pkgImportName,
+		imports:              make(map[packageName]packageImportName),
+	}
+
+	return r.imports[pkgImportName]
+}
+
+func (r *RustRenderer) initContentsFor(pkgImportName string) *strings.Builder {
+	if _, ok := r.contents[pkgImportName]; !ok {
+		r.contents[pkgImportName] = &strings.Builder{}
+	}
+
+	return r.contents[pkgImportName]
+}
+
+func (r *RustRenderer) WriteToDir(dir string) error {
+	sorted := make([]string, 0, len(r.contents))
+	for pkgImportName := range r.contents {
+		sorted = append(sorted, pkgImportName)
+	}
+	sort.Strings(sorted)
+
+	var modules []string
+	for _, pkgImportName := range sorted {
+		imports := r.imports[pkgImportName]
+		if imports == nil {
+			continue
+		}
+
+		content := &strings.Builder{}
+		content.WriteString("// generated by mkunion\n")
+
+		sortedImports := make([]string, 0, len(imports.imports))
+		for pkg := range imports.imports {
+			sortedImports = append(sortedImports, pkg)
+		}
+		sort.Strings(sortedImports)
+
+		if len(sortedImports) > 0 {
+			content.WriteString("\n")
+		}
+		for _, pkg := range sortedImports {
+			_, err := fmt.Fprintf(content, "use super::%s as %s;\n", r.normaliseImport(imports.imports[pkg]), toRustName(pkg))
+			if err != nil {
+				return fmt.Errorf("torust: WriteToDir failed to write imports: %w", err)
+			}
+		}
+
+		content.WriteString("\n")
+		content.WriteString(r.contents[pkgImportName].String())
+
+		module := r.normaliseImport(pkgImportName)
+		err := r.writeToFile(dir, module, strings.TrimRight(content.String(), "\n")+"\n")
+		if err != nil {
+			return fmt.Errorf("torust: WriteToDir failed to write file %s: %w", dir, err)
+		}
+
+		modules = append(modules, module)
+	}
+
+	err := r.writeModFile(dir, modules)
+	if err != nil {
+		return fmt.Errorf("torust: WriteToDir failed to write %s: %w", RustModFileName, err)
+	}
+
+	return nil
+}
+
+func (r *RustRenderer) writeToFile(dir string, name string, content string) error {
+	filename := path.Join(dir, fmt.Sprintf("%s.rs", name))
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filename, []byte(content), 0644)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RustRenderer) writeModFile(dir string, modules []string) error {
+	content := &strings.Builder{}
+	content.WriteString("// generated by mkunion\n\n")
+	for _, module := range modules {
+		_, _ = fmt.Fprintf(content, "pub mod %s;\n", module)
+	}
+
+	return r.writeToFile(dir, strings.TrimSuffix(RustModFileName, ".rs"), content.String())
+}
+
+func (r *RustRenderer) normaliseImport(imp packageImportName) string {
+	replace := strings.NewReplacer("/", "_", ".", "_", "-", "_")
+	return replace.Replace(imp)
+}
+
+// ToRust returns Rust type of a shape.
+func ToRust(x Shape, option *RustOptions) string {
+	return MatchShapeR1(
+		x,
+		func(x *Any) string {
+			return "serde_json::Value"
+		},
+		func(x *RefName) string {
+			if mapper, ok := LookupTypeMapper(x); ok {
+				return ToRust(mapper.Wire, option)
+			}
+
+			return toRustRef(x.Name, x.PkgName, x.PkgImportName, x.Indexed, option)
+		},
+		func(x *PointerLike) string {
+			// pointer can refer to the type that declares it, and recursive types must be boxed
+			if _, ok := x.Type.(*RefName); ok {
+				return fmt.Sprintf("Option<Box<%s>>", ToRust(x.Type, option))
+			}
+
+			return fmt.Sprintf("Option<%s>", ToRust(x.Type, option))
+		},
+		func(x *AliasLike) string {
+			return toRustRef(x.Name, x.PkgName, x.PkgImportName, nil, option)
+		},
+		func(x *PrimitiveLike) string {
+			return MatchPrimitiveKindR1(
+				x.Kind,
+				func(x *BooleanLike) string {
+					return "bool"
+				},
+				func(x *StringLike) string {
+					return "String"
+				},
+				func(x *NumberLike) string {
+					return RustNumberType(x.Kind)
+				},
+			)
+		},
+		func(x *ListLike) string {
+			// JSON encodes bytes as base64 string
+			if IsBinary(x) && x.ArrayLen == nil {
+				return "String"
+			}
+
+			return fmt.Sprintf("Vec<%s>", ToRust(x.Element, option))
+		},
+		func(x *MapLike) string {
+			return fmt.Sprintf("std::collections::BTreeMap<%s, %s>", ToRust(x.Key, option), ToRust(x.Val, option))
+		},
+		func(x *StructLike) string {
+			// anonymous struct doesn't have name, that type could use
+			return "serde_json::Map<String, serde_json::Value>"
+		},
+		func(x *UnionLike) string {
+			return toRustRef(x.Name, x.PkgName, x.PkgImportName, nil, option)
+		},
+	)
+}
+
+func RustNumberType(x NumberKind) string {
+	if x == nil {
+		return "f64"
+	}
+
+	return MatchNumberKindR1(
+		x,
+		func(x *UInt) string {
+			return "u64"
+		},
+		func(x *UInt8) string {
+			return "u8"
+		},
+		func(x *UInt16) string {
+			return "u16"
+		},
+		func(x *UInt32) string {
+			return "u32"
+		},
+		func(x *UInt64) string {
+			return "u64"
+		},
+		func(x *Int) string {
+			return "i64"
+		},
+		func(x *Int8) string {
+			return "i8"
+		},
+		func(x *Int16) string {
+			return "i16"
+		},
+		func(x *Int32) string {
+			return "i32"
+		},
+		func(x *Int64) string {
+			return "i64"
+		},
+		func(x *Float32) string {
+			return "f32"
+		},
+		func(x *Float64) string {
+			return "f64"
+		},
+	)
+}
+
+func toRustRef(name, pkgName, pkgImportName string, indexed []Shape, option *RustOptions) string {
+	if pkgName == "" {
+		// type parameter
+		return name
+	}
+
+	prefix := ""
+	if !option.IsCurrentPkgName(pkgName) {
+		prefix = toRustName(pkgName) + "::"
+		option.NeedsToImportPkgName(pkgName, pkgImportName)
+	}
+
+	if len(indexed) > 0 {
+		var names []string
+		for _, index := range indexed {
+			names = append(names, ToRust(index, option))
+		}
+		return fmt.Sprintf("%s%s<%s>", prefix, toRustName(name), strings.Join(names, ", "))
+	}
+
+	return prefix + toRustName(name)
+}
+
+func toRustTypeParams(params []TypeParam) string {
+	if len(params) == 0 {
+		return ""
+	}
+
+	var names []string
+	for _, param := range params {
+		names = append(names, param.Name)
+	}
+
+	return fmt.Sprintf("<%s>", strings.Join(names, ", "))
+}
+
+const rustDerive = "#[derive(Debug, Clone, PartialEq, serde::Serialize, serde::Deserialize)]\n"
+
+func toRustStruct(x *StructLike, option *RustOptions) string {
+	result := &strings.Builder{}
+	result.WriteString(rustDerive)
+	_, _ = fmt.Fprintf(result, "pub struct %s%s {\n", toRustName(x.Name), toRustTypeParams(x.TypeParams))
+
+	used := make(map[string]bool)
+	for _, field := range StructFields(x, LookupShapeOnDisk) {
+		jsonName := field.JSONName()
+
+		for _, ref := range ExtractRefs(field.Type) {
+			if ref.PkgName == "" {
+				used[ref.Name] = true
+			}
+		}
+
+		if field.Desc != nil {
+			for _, line := range strings.Split(*field.Desc, "\n") {
+				_, _ = fmt.Fprintf(result, "    /// %s\n", line)
+			}
+		}
+
+		var attrs []string
+		fieldName := toRustFieldName(field.Name)
+		if fieldName != jsonName {
+			attrs = append(attrs, fmt.Sprintf("rename = %s", strconv.Quote(jsonName)))
+		}
+		typ := ToRust(field.Type, option)
+		if IsPointer(field.Type) {
+			// JSON serde omits nil pointers
+			attrs = append(attrs, "default", "skip_serializing_if = \"Option::is_none\"")
+		} else if field.EmbeddedByPointer() {
+			// JSON serde omits fields of nil embedded pointer
+			typ = fmt.Sprintf("Option<%s>", typ)
+			attrs = append(attrs, "default", "skip_serializing_if = \"Option::is_none\"")
+		} else if isRustUnion(field.Type) {
+			// JSON serde encodes nil union as null
+			typ = fmt.Sprintf("Option<%s>", typ)
+			attrs = append(attrs, "default")
+		} else if field.MayBeOmitted() {
+			// JSON serde omits empty or zero value, and only builtin types implement Default
+			switch field.Type.(type) {
+			case *PrimitiveLike, *ListLike, *MapLike:
+				attrs = append(attrs, "default")
+			default:
+				typ = fmt.Sprintf("Option<%s>", typ)
+				attrs = append(attrs, "default", "skip_serializing_if = \"Option::is_none\"")
+			}
+		}
+		if len(attrs) > 0 {
+			_, _ = fmt.Fprintf(result, "    #[serde(%s)]\n", strings.Join(attrs, ", "))
+		}
+
+		_, _ = fmt.Fprintf(result, "    pub %s: %s,\n", fieldName, typ)
+	}
+
+	// Rust doesn't allow unused type parameters
+	var unused []string
+	for _, param := range x.TypeParams {
+		if !used[param.Name] {
+			unused = append(unused, param.Name)
+		}
+	}
+	if len(unused) > 0 {
+		typ := unused[0]
+		if len(unused) > 1 {
+			typ = fmt.Sprintf("(%s)", strings.Join(unused, ", "))
+		}
+
+		result.WriteString("    #[serde(skip)]\n")
+		_, _ = fmt.Fprintf(result, "    pub _phantom: std::marker::PhantomData<%s>,\n", typ)
+	}
+
+	result.WriteString("}\n")
+
+	return result.String()
+}
+
+// isRustUnion tells whenever field holds union, that unlike struct, can be nil in Go
+func isRustUnion(x Shape) bool {
+	switch y := x.(type) {
+	case *UnionLike:
+		return true
+	case *RefName:
+		if y.PkgName == "" {
+			return false
+		}
+
+		found, ok := LookupShapeOnDisk(y)
+		if !ok {
+			return false
+		}
+
+		_, ok = found.(*UnionLike)
+		return ok
+	}
+
+	return false
+}
+
+// toRustEnum renders union as enum, that has the same shape as JSON serde output,
+// which by default is {"$type": "pkg.Variant", "pkg.Variant": {...}}, and other encodings map to serde enum representations.
+// Variant payload is boxed, since variant can refer to the union that declares it.
+func toRustEnum(x *UnionLike, option *RustOptions) string {
+	encoding, err := UnionJSONEncoding(x)
+	if err != nil {
+		panic(fmt.Errorf("shape.ToRust: %w", err))
+	}
+
+	result := &strings.Builder{}
+	result.WriteString(rustDerive)
+	switch encoding.Kind {
+	case JSONUnionExternal, JSONUnionInternal:
+		_, _ = fmt.Fprintf(result, "#[serde(tag = %s)]\n", strconv.Quote(encoding.Tag))
+	case JSONUnionAdjacent:
+		_, _ = fmt.Fprintf(result, "#[serde(tag = %s, content = %s)]\n", strconv.Quote(encoding.Tag), strconv.Quote(encoding.Content))
+	case JSONUnionUntagged:
+		result.WriteString("#[serde(untagged)]\n")
+	}
+	_, _ = fmt.Fprintf(result, "pub enum %s%s {\n", toRustName(x.Name), toRustTypeParams(x.TypeParams))
+
+	for _, variant := range x.Variant {
+		variantName := VariantDiscriminator(variant)
+		typ := toRustRef(Name(variant), PkgName(variant), ToGoPkgImportName(variant), nil, option)
+		typ += toRustTypeParams(ExtractTypeParams(variant))
+
+		if encoding.Kind != JSONUnionExternal {
+			if encoding.Kind != JSONUnionUntagged {
+				_, _ = fmt.Fprintf(result, "    #[serde(rename = %s)]\n", strconv.Quote(variantName))
+			}
+			_, _ = fmt.Fprintf(result, "    %s(Box<%s>),\n", toRustName(Name(variant)), typ)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(result, "    #[serde(rename = %s)]\n", strconv.Quote(variantName))
+		_, _ = fmt.Fprintf(result, "    %s {\n", toRustName(Name(variant)))
+		_, _ = fmt.Fprintf(result, "        #[serde(rename = %s)]\n", strconv.Quote(variantName))
+		_, _ = fmt.Fprintf(result, "        value: Box<%s>,\n", typ)
+		result.WriteString("    },\n")
+	}
+
+	result.WriteString("}\n")
+
+	return result.String()
+}
+
+var rustKeywords = map[string]bool{
+	"as": true, "async": true, "await": true, "break": true, "const": true, "continue": true,
+	"crate": true, "dyn": true, "else": true, "enum": true, "extern": true, "false": true,
+	"fn": true, "for": true, "if": true, "impl": true, "in": true, "let": true,
+	"loop": true, "match": true, "mod": true, "move": true, "mut": true, "pub": true,
+	"ref": true, "return": true, "self": true, "Self": true, "static": true, "struct": true,
+	"super": true, "trait": true, "true": true, "type": true, "unsafe": true, "use": true,
+	"where": true, "while": true, "abstract": true, "become": true, "box": true, "do": true,
+	"final": true, "gen": true, "macro": true, "override": true, "priv": true, "try": true,
+	"typeof": true, "unsized": true, "virtual": true, "yield": true,
+	// types used by generated code, that user type must not shadow
+	"Box": true, "Option": true, "String": true, "Vec": true,
+}
+
+// toRustName escapes Go identifiers, that are keywords in Rust, like type
+func toRustName(name string) string {
+	if rustKeywords[name] {
+		return name + "_"
+	}
+
+	return name
+}
+
+// toRustFieldName converts Go field name to snake case, like UserID to user_id
+func toRustFieldName(name string) string {
+	runes := []rune(name)
+	result := &strings.Builder{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				result.WriteRune('_')
+			}
+			result.WriteRune(unicode.ToLower(r))
+			continue
+		}
+
+		result.WriteRune(r)
+	}
+
+	return toRustName(result.String())
+}
@@ -0,0 +1,266 @@
+package shape
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestRustGeneration(t *testing.T) {
+	desc := "radius in meters"
+
+	r := NewRustRenderer()
+	r.AddShape(&UnionLike{
+		Name:          "Shape",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		Variant: []Shape{
+			&StructLike{
+				Name:          "Circle",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Fields: []*FieldLike{
+					{
+						Name: "Radius",
+						Type: &PrimitiveLike{Kind: &NumberLike{Kind: &Float64{}}},
+						Desc: &desc,
+						Tags: map[string]Tag{"json": {Value: "radius"}},
+					},
+					{Name: "Hidden", Type: &PrimitiveLike{Kind: &BooleanLike{}}, Tags: map[string]Tag{"json": {Value: "-"}}},
+				},
+			},
+			&StructLike{
+				Name:          "Group",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Fields: []*FieldLike{
+					{Name: "Items", Type: &ListLike{Element: &RefName{Name: "Shape", PkgName: "geo", PkgImportName: "example.com/geo"}}},
+					{Name: "Data", Type: &ListLike{Element: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt8{}}}}},
+					{Name: "Meta", Type: &MapLike{Key: &PrimitiveLike{Kind: &StringLike{}}, Val: &Any{}}},
+					{Name: "Count", Type: &PointerLike{Type: &PrimitiveLike{Kind: &NumberLike{Kind: &UInt16{}}}}},
+					{Name: "Parent", Type: &PointerLike{Type: &RefName{Name: "Group", PkgName: "geo", PkgImportName: "example.com/geo"}}},
+					{Name: "OwnerID", Type: &PrimitiveLike{Kind: &StringLike{}}},
+					{Name: "Type", Type: &PrimitiveLike{Kind: &NumberLike{Kind: &Int32{}}}},
+					{Name: "Config", Type: &RefName{Name: "Settings", PkgName: "conf", PkgImportName: "example.com/conf"}},
+					{Name: "Guard", Type: &RefName{Name: "Guard", PkgName: "shape", PkgImportName: "github.com/widmogrod/mkunion/x/shape"}},
+				},
+			},
+			&AliasLike{
+				Name:          "Tag",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				Type:          &PrimitiveLike{Kind: &StringLike{}},
+			},
+		},
+	})
+	r.AddShape(&AliasLike{
+		Name:          "Tags",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		Type:          &ListLike{Element: &RefName{Name: "Box", PkgName: "geo", PkgImportName: "example.com/geo"}},
+	})
+	r.AddShape(&AliasLike{
+		Name:          "Box",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		Type:          &PrimitiveLike{Kind: &StringLike{}},
+	})
+	r.AddShape(&UnionLike{
+		Name:          "Result",
+		PkgName:       "geo",
+		PkgImportName: "example.com/geo",
+		TypeParams:    []TypeParam{{Name: "T", Type: &Any{}}},
+		Variant: []Shape{
+			&StructLike{
+				Name:          "Ok",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				TypeParams:    []TypeParam{{Name: "T", Type: &Any{}}},
+				Fields: []*FieldLike{
+					{Name: "Value", Type: &RefName{Name: "T"}},
+				},
+			},
+			&StructLike{
+				Name:          "Err",
+				PkgName:       "geo",
+				PkgImportName: "example.com/geo",
+				TypeParams:    []TypeParam{{Name: "T", Type: &Any{}}},
+			},
+		},
+	})
+
+	dir := t.TempDir()
+	err := r.WriteToDir(dir)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path.Join(dir, "example_com_geo.rs"))
+	assert.NoError(t, err)
+	assert.Equal(t, `// generated by mkunion
+
+use super::example_com_conf as conf;
+use super::github_com_widmogrod_mkunion_x_shape as shape;
+
+#[derive(Debug, Clone, PartialEq, serde::Serialize, serde::Deserialize)]
+#[serde(tag = "$type")]
+pub enum Shape {
+    #[serde(rename = "geo.Circle")]
+    Circle {
+        #[serde(rename = "geo.Circle")]
+        value: Box<Circle>,
+    },
+    #[serde(rename = "geo.Group")]
+    Group {
+        #[serde(rename = "geo.Group")]
+        value: Box<Group>,
+    },
+    #[serde(rename = "geo.Tag")]
+    Tag {
+        #[serde(rename = "geo.Tag")]
+        value: Box<Tag>,
+    },
+}
+
+#[derive(Debug, Clone, PartialEq, serde::Serialize, serde::Deserialize)]
+pub struct Circle {
+    /// radius in meters
+    pub radius: f64,
+}
+
+#[derive(Debug, Clone, PartialEq, serde::Serialize, serde::Deserialize)]
+pub struct Group {
+    #[serde(rename = "Items")]
+    pub items: Vec<Shape>,
+    #[serde(rename = "Data")]
+    pub data: String,
+    #[serde(rename = "Meta")]
+    pub meta: std::collections::BTreeMap<String, serde_json::Value>,
+    #[serde(rename = "Count", default, skip_serializing_if = "Option::is_none")]
+    pub count: Option<u16>,
+    #[serde(rename = "Parent", default, skip_serializing_if = "Option::is_none")]
+    pub parent: Option<Box<Group>>,
+    #[serde(rename = "OwnerID")]
+    pub owner_id: String,
+    #[serde(rename = "Type")]
+    pub type_: i32,
+    #[serde(rename = "Config")]
+    pub config: conf::Settings,
+    #[serde(rename = "Guard", default)]
+    pub guard: Option<shape::Guard>,
+}
+
+pub type Tag = String;
+
+pub type Tags = Vec<Box_>;
+
+pub type Box_ = String;
+
+#[derive(Debug, Clone, PartialEq, serde::Serialize, serde::Deserialize)]
+#[serde(tag = "$type")]
+pub enum Result<T> {
+    #[serde(rename = "geo.Ok")]
+    Ok {
+        #[serde(rename = "geo.Ok")]
+        value: Box<Ok<T>>,
+    },
+    #[serde(rename = "geo.Err")]
+    Err {
+        #[serde(rename = "geo.Err")]
+        value: Box<Err<T>>,
+    },
+}
+
+#[derive(Debug, Clone, PartialEq, serde::Serialize, serde::Deserialize)]
+pub struct Ok<T> {
+    #[serde(rename = "Value")]
+    pub value: T,
+}
+
+#[derive(Debug, Clone, PartialEq, serde::Serialize, serde::Deserialize)]
+pub struct Err<T> {
+    #[serde(skip)]
+    pub _phantom: std::marker::PhantomData<T>,
+}
+`, string(content))
+
+	content, err = os.ReadFile(path.Join(dir, RustModFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, "// generated by mkunion\n\npub mod example_com_geo;\npub mod github_com_widmogrod_mkunion_x_shape;\n", string(content))
+}
+
+func TestToRustFieldName(t *testing.T) {
+	useCases := map[string]string{
+		"Name":      "name",
+		"UserID":    "user_id",
+		"ID":        "id",
+		"HTTPCode":  "http_code",
+		"Field2Foo": "field2_foo",
+		"Type":      "type_",
+		"lower":     "lower",
+	}
+	for name, expected := range useCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected, toRustFieldName(name))
+		})
+	}
+}
+
+func TestToRustEnum_UnionEncoding(t *testing.T) {
+	union := func(encoding Tag) *UnionLike {
+		return &UnionLike{
+			Name:          "Figure",
+			PkgName:       "app",
+			PkgImportName: "example.com/app",
+			Variant: []Shape{
+				&StructLike{Name: "Circle", PkgName: "app", PkgImportName: "example.com/app", Tags: map[string]Tag{
+					TagVariantName: {Value: "circle"},
+				}},
+			},
+			Tags: map[string]Tag{
+				TagUnionName:     {Value: "Figure"},
+				TagUnionJSONName: encoding,
+			},
+		}
+	}
+
+	useCases := map[string]struct {
+		encoding Tag
+		expected string
+	}{
+		"internal": {
+			encoding: Tag{Value: JSONUnionInternal, Options: []string{"tag=kind"}},
+			expected: `#[derive(Debug, Clone, PartialEq, serde::Serialize, serde::Deserialize)]
+#[serde(tag = "kind")]
+pub enum Figure {
+    #[serde(rename = "circle")]
+    Circle(Box<Circle>),
+}
+`,
+		},
+		"adjacent": {
+			encoding: Tag{Value: JSONUnionAdjacent, Options: []string{"tag=t", "content=c"}},
+			expected: `#[derive(Debug, Clone, PartialEq, serde::Serialize, serde::Deserialize)]
+#[serde(tag = "t", content = "c")]
+pub enum Figure {
+    #[serde(rename = "circle")]
+    Circle(Box<Circle>),
+}
+`,
+		},
+		"untagged": {
+			encoding: Tag{Value: JSONUnionUntagged},
+			expected: `#[derive(Debug, Clone, PartialEq, serde::Serialize, serde::Deserialize)]
+#[serde(untagged)]
+pub enum Figure {
+    Circle(Box<Circle>),
+}
+`,
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			option := &RustOptions{currentPkgName: "app", currentPkgImportName: "example.com/app", imports: map[packageName]packageImportName{}}
+			assert.Equal(t, uc.expected, toRustEnum(union(uc.encoding), option))
+		})
+	}
+}
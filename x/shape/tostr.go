@@ -2,9 +2,14 @@ package shape
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
+// ToStr returns human-readable declaration of x in Go like syntax,
+// where union lists its variants followed by their declarations,
+// and tags of types are shown as //go:tag comments.
+// Shapes without declaration, like lists or references, are shown as Go type names.
 func ToStr(x Shape) string {
 	return MatchShapeR1(
 		x,
@@ -12,43 +17,42 @@ func ToStr(x Shape) string {
 			return "any"
 		},
 		func(x *RefName) string {
-			return fmt.Sprintf("%s:%s.%s", x.PkgImportName, x.PkgName, x.Name)
+			return ToGoTypeName(x)
 		},
 		func(x *PointerLike) string {
-			return fmt.Sprintf("*%s", ToStr(x.Type))
+			return ToGoTypeName(x)
 		},
 		func(x *AliasLike) string {
+			result := &strings.Builder{}
+			result.WriteString(documentTagsToStr(x.Tags))
 			if x.IsAlias {
-				return fmt.Sprintf("%s:%s.%s = %s", x.PkgImportName, x.PkgName, x.Name, ToStr(x.Type))
+				_, _ = fmt.Fprintf(result, "type %s%s = %s", diffPath(x), typeParamsToStr(x.TypeParams), ToGoTypeName(x.Type))
 			} else {
-				return fmt.Sprintf("%s:%s.%s{%s}", x.PkgImportName, x.PkgName, x.Name, ToStr(x.Type))
+				_, _ = fmt.Fprintf(result, "type %s%s %s", diffPath(x), typeParamsToStr(x.TypeParams), ToGoTypeName(x.Type))
 			}
+
+			return result.String()
 		},
 		func(x *PrimitiveLike) string {
-			return MatchPrimitiveKindR1(
-				x.Kind,
-				func(x *BooleanLike) string {
-					return "bool"
-				},
-				func(x *StringLike) string {
-					return "string"
-				},
-				func(x *NumberLike) string {
-					return "number"
-				},
-			)
+			return ToGoTypeName(x)
 		},
 		func(x *ListLike) string {
-			return fmt.Sprintf("%s[]", ToStr(x.Element))
+			return ToGoTypeName(x)
 		},
 		func(x *MapLike) string {
-			return fmt.Sprintf("{[%s]: %s}", ToStr(x.Key), ToStr(x.Val))
+			return ToGoTypeName(x)
 		},
 		func(x *StructLike) string {
 			result := &strings.Builder{}
-			_, _ = fmt.Fprintf(result, "%s:%s.%s={\n", x.PkgImportName, x.PkgName, x.Name)
+			result.WriteString(documentTagsToStr(x.Tags))
+			if len(x.Fields) == 0 {
+				_, _ = fmt.Fprintf(result, "type %s%s struct{}", diffPath(x), typeParamsToStr(x.TypeParams))
+				return result.String()
+			}
+
+			_, _ = fmt.Fprintf(result, "type %s%s struct {\n", diffPath(x), typeParamsToStr(x.TypeParams))
 			for _, field := range x.Fields {
-				result.WriteString(fmt.Sprintf("\t%s %s\n", field.Name, ToStr(field.Type)))
+				_, _ = fmt.Fprintf(result, "\t%s\n", fieldToStr(field))
 			}
 			result.WriteString("}")
 
@@ -56,17 +60,169 @@ func ToStr(x Shape) string {
 		},
 		func(x *UnionLike) string {
 			result := &strings.Builder{}
-			_, _ = fmt.Fprintf(result, "union %s= ", x.Name)
-			for idx, variant := range x.Variant {
-				if idx > 0 {
-					result.WriteString(" | ")
-				}
-
-				_, _ = fmt.Fprintf(result, "%s", ToStr(variant))
+			result.WriteString(documentTagsToStr(x.Tags))
+			_, _ = fmt.Fprintf(result, "type %s%s union {\n", diffPath(x), typeParamsToStr(x.TypeParams))
+			for _, variant := range x.Variant {
+				_, _ = fmt.Fprintf(result, "\t%s\n", ToGoTypeName(variant))
 			}
 			result.WriteString("}")
 
+			for _, variant := range x.Variant {
+				_, _ = fmt.Fprintf(result, "\n\n%s", ToStr(variant))
+			}
+
 			return result.String()
 		},
 	)
 }
+
+// ChangeToStr returns single line description of change, like
+//
+//	example.Shape.Circle.Radius: type changed from int to float64
+func ChangeToStr(x Change) string {
+	return MatchChangeR1(
+		x,
+		func(x *TypeChanged) string {
+			return fmt.Sprintf("%s: type changed from %s to %s", x.Path, ToGoTypeName(x.From), ToGoTypeName(x.To))
+		},
+		func(x *VariantAdded) string {
+			return fmt.Sprintf("%s: variant %s added", x.Path, VariantDiscriminator(x.Variant))
+		},
+		func(x *VariantRemoved) string {
+			return fmt.Sprintf("%s: variant %s removed", x.Path, VariantDiscriminator(x.Variant))
+		},
+		func(x *VariantRenamed) string {
+			return fmt.Sprintf("%s: variant %s renamed to %s", x.Path, VariantDiscriminator(x.From), VariantDiscriminator(x.To))
+		},
+		func(x *FieldAdded) string {
+			return fmt.Sprintf("%s: field added, %s", x.Path, fieldToStr(x.Field))
+		},
+		func(x *FieldRemoved) string {
+			return fmt.Sprintf("%s: field removed, %s", x.Path, fieldToStr(x.Field))
+		},
+		func(x *TagChanged) string {
+			switch {
+			case x.From == nil:
+				return fmt.Sprintf("%s: tag %s added", x.Path, tagToStr(x.Name, *x.To))
+			case x.To == nil:
+				return fmt.Sprintf("%s: tag %s removed", x.Path, tagToStr(x.Name, *x.From))
+			}
+
+			return fmt.Sprintf("%s: tag changed from %s to %s", x.Path, tagToStr(x.Name, *x.From), tagToStr(x.Name, *x.To))
+		},
+		func(x *GuardChanged) string {
+			return fmt.Sprintf("%s: guard changed from %s to %s", x.Path, guardToStr(x.From), guardToStr(x.To))
+		},
+		func(x *TypeParamsChanged) string {
+			from, to := typeParamsToStr(x.From), typeParamsToStr(x.To)
+			if from == "" {
+				from = "none"
+			}
+			if to == "" {
+				to = "none"
+			}
+
+			return fmt.Sprintf("%s: type parameters changed from %s to %s", x.Path, from, to)
+		},
+	)
+}
+
+func fieldToStr(x *FieldLike) string {
+	result := x.Name + " " + ToGoTypeName(x.Type)
+	if x.Embedded {
+		result = ToGoTypeName(x.Type)
+	}
+
+	if len(x.Tags) == 0 {
+		return result
+	}
+
+	tags := make([]string, 0, len(x.Tags))
+	for _, name := range sortedTagNames(x.Tags) {
+		tags = append(tags, tagToStr(name, x.Tags[name]))
+	}
+
+	return fmt.Sprintf("%s `%s`", result, strings.Join(tags, " "))
+}
+
+func documentTagsToStr(x map[string]Tag) string {
+	if len(x) == 0 {
+		return ""
+	}
+
+	tags := make([]string, 0, len(x))
+	for _, name := range sortedTagNames(x) {
+		tags = append(tags, tagToStr(name, x[name]))
+	}
+
+	return fmt.Sprintf("//go:tag %s\n", strings.Join(tags, " "))
+}
+
+func tagToStr(name string, x Tag) string {
+	return fmt.Sprintf("%s:%q", name, strings.Join(append([]string{x.Value}, x.Options...), ","))
+}
+
+func sortedTagNames(x map[string]Tag) []string {
+	result := make([]string, 0, len(x))
+	for name := range x {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func typeParamsToStr(x []TypeParam) string {
+	if len(x) == 0 {
+		return ""
+	}
+
+	params := make([]string, 0, len(x))
+	for _, param := range x {
+		params = append(params, fmt.Sprintf("%s %s", param.Name, ToGoTypeName(param.Type)))
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(params, ", "))
+}
+
+func guardToStr(x Guard) string {
+	if x == nil {
+		return "none"
+	}
+
+	return MatchGuardR1(
+		x,
+		func(x *Enum) string {
+			return fmt.Sprintf("enum(%s)", strings.Join(x.Val, ", "))
+		},
+		func(x *Required) string {
+			return "required"
+		},
+		func(x *Regexp) string {
+			return fmt.Sprintf("regexp(%q)", x.Regexp)
+		},
+		func(x *Between) string {
+			return fmt.Sprintf("between(%v, %v)", x.Min, x.Max)
+		},
+		func(x *MinLength) string {
+			return fmt.Sprintf("minLength(%d)", x.Len)
+		},
+		func(x *MaxLength) string {
+			return fmt.Sprintf("maxLength(%d)", x.Len)
+		},
+		func(x *MinItems) string {
+			return fmt.Sprintf("minItems(%d)", x.Len)
+		},
+		func(x *MaxItems) string {
+			return fmt.Sprintf("maxItems(%d)", x.Len)
+		},
+		func(x *AndGuard) string {
+			guards := make([]string, 0, len(x.L))
+			for _, guard := range x.L {
+				guards = append(guards, guardToStr(guard))
+			}
+
+			return strings.Join(guards, " and ")
+		},
+	)
+}
@@ -0,0 +1,42 @@
+package shape
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToStr(t *testing.T) {
+	x := inferDiffShape(t, `package diff
+
+//go:tag mkunion:"Vehicle"
+type (
+	Car struct {
+		Wheels int32  `+"`json:\"wheels\" required:\"true\"`"+`
+		Owner  *Person
+	}
+	//go:tag alias:"Ship"
+	Boat struct{}
+)
+`, "Vehicle")
+
+	assert.Equal(t, `//go:tag mkunion:"Vehicle"
+type diff.Vehicle union {
+	diff.Car
+	diff.Boat
+}
+
+//go:tag mkunion:"Vehicle"
+type diff.Car struct {
+	Wheels int32 `+"`json:\"wheels\" required:\"true\"`"+`
+	Owner *diff.Person
+}
+
+//go:tag alias:"Ship" mkunion:"Vehicle"
+type diff.Boat struct{}`, ToStr(x))
+
+	assert.Equal(t, "map[string][]*diff.Person", ToStr(&MapLike{
+		Key: &PrimitiveLike{Kind: &StringLike{}},
+		Val: &ListLike{Element: &PointerLike{Type: &RefName{Name: "Person", PkgName: "diff", PkgImportName: "example.com/diff"}}},
+	}))
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -111,6 +112,10 @@ func ToTypeScript(x Shape, option *TypeScriptOptions) string {
 			return "any"
 		},
 		func(x *RefName) string {
+			if mapper, ok := LookupTypeMapper(x); ok {
+				return mapper.TypeScript
+			}
+
 			prefix := ""
 			if !option.IsCurrentPkgName(x.PkgName) {
 				if x.PkgName == "" {
@@ -175,11 +180,12 @@ func ToTypeScript(x Shape, option *TypeScriptOptions) string {
 			_, _ = fmt.Fprintf(result, " = {")
 			if len(x.Fields) > 0 {
 				_, _ = fmt.Fprintf(result, "\n")
-				for _, field := range x.Fields {
-					if IsPointer(field.Type) || !IsRequired(field.Guard) {
-						_, _ = fmt.Fprintf(result, "\t%s?: %s,\n", field.Name, ToTypeScript(field.Type, option))
+				for _, field := range StructFields(x, LookupShapeOnDisk) {
+					result.WriteString(toTypeScriptGuardComment(field.Guard))
+					if IsPointer(field.Type) || field.MayBeOmitted() || !IsRequired(field.Guard) {
+						_, _ = fmt.Fprintf(result, "\t%s?: %s,\n", field.Name, toTypeScriptFieldType(field.FieldLike, option))
 					} else {
-						_, _ = fmt.Fprintf(result, "\t%s: %s,\n", field.Name, ToTypeScript(field.Type, option))
+						_, _ = fmt.Fprintf(result, "\t%s: %s,\n", field.Name, toTypeScriptFieldType(field.FieldLike, option))
 					}
 				}
 			}
@@ -276,6 +282,12 @@ func (r *TypeScriptRenderer) AddShape(x Shape) {
 			res := ToTypeScript(x, options)
 			contents.WriteString(res)
 			contents.WriteString("\n")
+
+			if response, ok := ServiceResponse(x); ok {
+				contents.WriteString(ToTypeScriptService(x, response, options))
+				contents.WriteString("\n")
+				r.FollowRef(response)
+			}
 		},
 	)
 
@@ -285,6 +297,11 @@ func (r *TypeScriptRenderer) AddShape(x Shape) {
 func (r *TypeScriptRenderer) FollowRef(x Shape) {
 	refs := ExtractRefs(x)
 	for _, ref := range refs {
+		if _, ok := LookupTypeMapper(ref); ok {
+			// mapped type is rendered as its wire type, and has no declaration
+			continue
+		}
+
 		log.Debugf("totypescript: FollowRef %s", ToGoTypeName(ref))
 		x, found := LookupShapeOnDisk(ref)
 		if found {
@@ -402,6 +419,10 @@ func toTypeTypeScriptTypeName(variant Shape, option *TypeScriptOptions) string {
 			return "any"
 		},
 		func(x *RefName) string {
+			if mapper, ok := LookupTypeMapper(x); ok {
+				return mapper.TypeScript
+			}
+
 			prefix := ""
 			if !option.IsCurrentPkgName(x.PkgName) {
 				prefix = fmt.Sprintf("%s.", x.PkgName)
@@ -423,7 +444,7 @@ func toTypeTypeScriptTypeName(variant Shape, option *TypeScriptOptions) string {
 		func(x *AliasLike) string {
 			//typeName := toTypeTypeScriptTypeName(x.Type, option)
 			typeName := x.Name
-			typeNameFul := fmt.Sprintf("%s.%s", x.PkgName, x.Name)
+			typeNameFul := VariantDiscriminator(x)
 
 			result := &strings.Builder{}
 			result.WriteString("{\n")
@@ -456,7 +477,7 @@ func toTypeTypeScriptTypeName(variant Shape, option *TypeScriptOptions) string {
 		func(x *StructLike) string {
 			result := &strings.Builder{}
 			typeName := x.Name
-			typeNameFul := fmt.Sprintf("%s.%s", x.PkgName, x.Name)
+			typeNameFul := VariantDiscriminator(x)
 
 			result.WriteString("{\n")
 			_, _ = fmt.Fprintf(result, "\t"+`"$type"?: "%s",`+"\n", typeNameFul)
@@ -467,14 +488,196 @@ func toTypeTypeScriptTypeName(variant Shape, option *TypeScriptOptions) string {
 
 		},
 		func(x *UnionLike) string {
+			encoding, err := UnionJSONEncoding(x)
+			if err != nil {
+				panic(fmt.Errorf("shape.ToTypeScript: %w", err))
+			}
+
 			result := &strings.Builder{}
 			for idx, variant := range x.Variant {
 				if idx > 0 {
 					result.WriteString(" | ")
 				}
-				result.WriteString(toTypeTypeScriptTypeName(variant, option))
+				result.WriteString(toTypeScriptVariant(variant, encoding, option))
 			}
 			return result.String()
 		},
 	)
 }
+
+// toTypeScriptVariant renders variant of union in the same shape as JSON serde encodes it.
+func toTypeScriptVariant(x Shape, encoding *JSONUnionEncoding, option *TypeScriptOptions) string {
+	if encoding.Kind == JSONUnionExternal && encoding.Tag == "$type" {
+		return toTypeTypeScriptTypeName(x, option)
+	}
+
+	typeName := Name(x)
+	typeNameFul := VariantDiscriminator(x)
+
+	result := &strings.Builder{}
+	switch encoding.Kind {
+	case JSONUnionExternal:
+		result.WriteString("{\n")
+		_, _ = fmt.Fprintf(result, "\t%q?: %q,\n", encoding.Tag, typeNameFul)
+		_, _ = fmt.Fprintf(result, "\t%q: %s", typeNameFul, typeName)
+		result.WriteString("\n}")
+	case JSONUnionInternal:
+		result.WriteString("{\n")
+		_, _ = fmt.Fprintf(result, "\t%q: %q,\n", encoding.Tag, typeNameFul)
+		_, _ = fmt.Fprintf(result, "} & %s", typeName)
+	case JSONUnionAdjacent:
+		result.WriteString("{\n")
+		_, _ = fmt.Fprintf(result, "\t%q: %q,\n", encoding.Tag, typeNameFul)
+		_, _ = fmt.Fprintf(result, "\t%q: %s", encoding.Content, typeName)
+		result.WriteString("\n}")
+	case JSONUnionUntagged:
+		result.WriteString(typeName)
+	}
+
+	return result.String()
+}
+
+// toTypeScriptFieldType refines primitive field with enum guard to union of literal types.
+func toTypeScriptFieldType(field *FieldLike, option *TypeScriptOptions) string {
+	typ := field.Type
+	if ptr, ok := typ.(*PointerLike); ok {
+		typ = ptr.Type
+	}
+
+	enum := toTypeScriptEnum(field.Guard)
+	primitive, ok := typ.(*PrimitiveLike)
+	if len(enum) == 0 || !ok {
+		return ToTypeScript(field.Type, option)
+	}
+
+	var literals []string
+	for _, val := range enum {
+		switch primitive.Kind.(type) {
+		case *StringLike:
+			literals = append(literals, strconv.Quote(val))
+		case *NumberLike:
+			if _, err := strconv.ParseFloat(val, 64); err != nil {
+				return ToTypeScript(field.Type, option)
+			}
+			literals = append(literals, val)
+		default:
+			return ToTypeScript(field.Type, option)
+		}
+	}
+
+	return strings.Join(literals, " | ")
+}
+
+func toTypeScriptEnum(guard Guard) []string {
+	switch y := guard.(type) {
+	case *Enum:
+		return y.Val
+	case *AndGuard:
+		for _, guard := range y.L {
+			if result := toTypeScriptEnum(guard); len(result) > 0 {
+				return result
+			}
+		}
+	}
+
+	return nil
+}
+
+// toTypeScriptGuardComment returns JSDoc with validation annotations, that TypeScript cannot express in types,
+// annotations follow names used by JSON Schema generators for TypeScript.
+func toTypeScriptGuardComment(guard Guard) string {
+	annotations := toTypeScriptGuardAnnotations(guard)
+	if len(annotations) == 0 {
+		return ""
+	}
+
+	result := &strings.Builder{}
+	result.WriteString("\t/**\n")
+	for _, annotation := range annotations {
+		_, _ = fmt.Fprintf(result, "\t * %s\n", strings.ReplaceAll(annotation, "*/", "*\\/"))
+	}
+	result.WriteString("\t */\n")
+
+	return result.String()
+}
+
+func toTypeScriptGuardAnnotations(guard Guard) []string {
+	if guard == nil {
+		return nil
+	}
+
+	return MatchGuardR1(
+		guard,
+		func(x *Enum) []string {
+			return nil
+		},
+		func(x *Required) []string {
+			return nil
+		},
+		func(x *Regexp) []string {
+			return []string{"@pattern " + x.Regexp}
+		},
+		func(x *Between) []string {
+			return []string{
+				fmt.Sprintf("@minimum %v", x.Min),
+				fmt.Sprintf("@maximum %v", x.Max),
+			}
+		},
+		func(x *MinLength) []string {
+			return []string{fmt.Sprintf("@minLength %d", x.Len)}
+		},
+		func(x *MaxLength) []string {
+			return []string{fmt.Sprintf("@maxLength %d", x.Len)}
+		},
+		func(x *MinItems) []string {
+			return []string{fmt.Sprintf("@minItems %d", x.Len)}
+		},
+		func(x *MaxItems) []string {
+			return []string{fmt.Sprintf("@maxItems %d", x.Len)}
+		},
+		func(x *AndGuard) []string {
+			var result []string
+			for _, guard := range x.L {
+				result = append(result, toTypeScriptGuardAnnotations(guard)...)
+			}
+			return result
+		},
+	)
+}
+
+// ToTypeScriptService renders fetch client of union tagged as service,
+// that calls endpoints of router generated in Go, one function per command.
+func ToTypeScriptService(x *UnionLike, response *RefName, option *TypeScriptOptions) string {
+	responseType := ToTypeScript(response, option)
+
+	result := &strings.Builder{}
+	_, _ = fmt.Fprintf(result, "export type %sService = {\n", x.Name)
+	for _, variant := range x.Variant {
+		_, _ = fmt.Fprintf(result, "\t%s: (x: %s) => Promise<%s>,\n", Name(variant), Name(variant), responseType)
+	}
+	result.WriteString("}\n\n")
+
+	_, _ = fmt.Fprintf(result, "export function %sClient(baseURL: string, init?: RequestInit): %sService {\n", x.Name, x.Name)
+	result.WriteString("\tconst call = async (path: string, x: any): Promise<any> => {\n")
+	result.WriteString("\t\tconst headers = new Headers(init?.headers)\n")
+	result.WriteString("\t\theaders.set(\"Content-Type\", \"application/json\")\n")
+	result.WriteString("\t\tconst response = await fetch(baseURL + path, {\n")
+	result.WriteString("\t\t\t...init,\n")
+	result.WriteString("\t\t\tmethod: \"POST\",\n")
+	result.WriteString("\t\t\theaders: headers,\n")
+	result.WriteString("\t\t\tbody: JSON.stringify(x),\n")
+	result.WriteString("\t\t})\n")
+	result.WriteString("\t\tif (!response.ok) {\n")
+	result.WriteString("\t\t\tthrow new Error(`${path}: ${response.status} ${await response.text()}`)\n")
+	result.WriteString("\t\t}\n")
+	result.WriteString("\t\treturn response.json()\n")
+	result.WriteString("\t}\n\n")
+	result.WriteString("\treturn {\n")
+	for _, variant := range x.Variant {
+		_, _ = fmt.Fprintf(result, "\t\t%s: (x) => call(%q, x),\n", Name(variant), ServicePath(variant))
+	}
+	result.WriteString("\t}\n")
+	result.WriteString("}\n")
+
+	return result.String()
+}
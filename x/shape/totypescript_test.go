@@ -85,7 +85,7 @@ export type A = {
 export type B = {
 	Age?: number,
 	A?: A,
-	T?: time.Time,
+	T?: string,
 }
 
 export type C = string
@@ -110,13 +110,13 @@ export type List = {}
 
 export type M = List
 
-export type N = time.Duration
+export type N = number
 
-export type O = ListOf<time.Duration>
+export type O = ListOf<number>
 
 export type ListOf<T> = {}
 
-export type P = ListOf2<ListOf<any>, ListOf2<number, time.Duration>>
+export type P = ListOf2<ListOf<any>, ListOf2<number, number>>
 
 export type ListOf2<T1, T2> = {
 	Data?: T1,
@@ -124,8 +124,237 @@ export type ListOf2<T1, T2> = {
 }
 
 
-//eslint-disable-next-line
-import * as time from './time'
 `
 	assert.Equal(t, expected, string(contents))
 }
+
+func TestTypeScriptGuards(t *testing.T) {
+	result := ToTypeScript(&StructLike{
+		Name:          "Search",
+		PkgName:       "app",
+		PkgImportName: "example.com/app",
+		Fields: []*FieldLike{
+			{Name: "Unit", Type: &PrimitiveLike{Kind: &StringLike{}}, Guard: &AndGuard{L: []Guard{
+				&Enum{Val: []string{"c", "f"}},
+				&Required{},
+			}}},
+			{Name: "Level", Type: &PointerLike{Type: &PrimitiveLike{Kind: &NumberLike{}}}, Guard: &Enum{Val: []string{"1", "2"}}},
+			{Name: "Query", Type: &PrimitiveLike{Kind: &StringLike{}}, Guard: &AndGuard{L: []Guard{
+				&Regexp{Regexp: "^a*/$"},
+				&MinLength{Len: 3},
+			}}},
+			{Name: "Limit", Type: &PrimitiveLike{Kind: &NumberLike{}}, Guard: &Between{Min: 1, Max: 50}},
+		},
+	}, &TypeScriptOptions{currentPkgName: "app", currentPkgImportName: "example.com/app"})
+
+	expected := `export type Search = {
+	Unit: "c" | "f",
+	Level?: 1 | 2,
+	/**
+	 * @pattern ^a*\/$
+	 * @minLength 3
+	 */
+	Query?: string,
+	/**
+	 * @minimum 1
+	 * @maximum 50
+	 */
+	Limit?: number,
+}
+`
+	assert.Equal(t, expected, result)
+}
+
+func TestTypeScriptEmbeddedStruct(t *testing.T) {
+	StoreShapeOnDisk(&StructLike{
+		Name:          "Base",
+		PkgName:       "app",
+		PkgImportName: "example.com/app",
+		Fields: []*FieldLike{
+			{Name: "ID", Type: &PrimitiveLike{Kind: &StringLike{}}, Guard: &Required{}},
+			{Name: "Owner", Type: &RefName{Name: "User", PkgName: "auth", PkgImportName: "example.com/auth"}, Guard: &Required{}},
+		},
+	})
+
+	option := &TypeScriptOptions{
+		currentPkgName:       "app",
+		currentPkgImportName: "example.com/app",
+		imports:              make(map[packageName]packageImportName),
+	}
+	result := ToTypeScript(&StructLike{
+		Name:          "Order",
+		PkgName:       "app",
+		PkgImportName: "example.com/app",
+		Fields: []*FieldLike{
+			{Name: "Base", Type: &PointerLike{Type: &RefName{Name: "Base", PkgName: "app", PkgImportName: "example.com/app"}}, Embedded: true},
+			{Name: "ID", Type: &PrimitiveLike{Kind: &NumberLike{}}, Guard: &Required{}},
+		},
+	}, option)
+
+	expected := `export type Order = {
+	Owner?: auth.User,
+	ID: number,
+}
+`
+	assert.Equal(t, expected, result)
+	assert.Contains(t, option.imports, packageName("auth"), "type of promoted field is imported")
+}
+
+func TestTypeScriptOmittedFields(t *testing.T) {
+	result := ToTypeScript(&StructLike{
+		Name:          "Parcel",
+		PkgName:       "app",
+		PkgImportName: "example.com/app",
+		Fields: []*FieldLike{
+			{Name: "ID", Type: &PrimitiveLike{Kind: &StringLike{}}, Guard: &Required{}, Tags: map[string]Tag{"json": {Value: "id"}}},
+			{Name: "Label", Type: &PrimitiveLike{Kind: &StringLike{}}, Guard: &Required{}, Tags: map[string]Tag{"json": {Value: "label", Options: []string{"omitempty"}}}},
+			{Name: "Count", Type: &PrimitiveLike{Kind: &NumberLike{}}, Guard: &Required{}, Tags: map[string]Tag{"json": {Options: []string{"omitzero"}}}},
+			{Name: "Internal", Type: &PrimitiveLike{Kind: &StringLike{}}, Tags: map[string]Tag{"json": {Value: "-"}}},
+		},
+	}, &TypeScriptOptions{currentPkgName: "app", currentPkgImportName: "example.com/app"})
+
+	expected := `export type Parcel = {
+	ID: string,
+	Label?: string,
+	Count?: number,
+}
+`
+	assert.Equal(t, expected, result)
+}
+
+func TestTypeScriptTypeMapper(t *testing.T) {
+	result := ToTypeScript(&StructLike{
+		Name:          "Invoice",
+		PkgName:       "app",
+		PkgImportName: "example.com/app",
+		Fields: []*FieldLike{
+			{Name: "Issued", Type: &RefName{Name: "Time", PkgName: "time", PkgImportName: "time"}},
+			{Name: "Term", Type: &RefName{Name: "Duration", PkgName: "time", PkgImportName: "time"}},
+			{Name: "Total", Type: &PointerLike{Type: &RefName{Name: "Int", PkgName: "big", PkgImportName: "math/big"}}},
+		},
+	}, &TypeScriptOptions{currentPkgName: "app", currentPkgImportName: "example.com/app"})
+
+	expected := `export type Invoice = {
+	Issued?: string,
+	Term?: number,
+	Total?: string,
+}
+`
+	assert.Equal(t, expected, result)
+}
+
+func TestTypeScriptService(t *testing.T) {
+	union := &UnionLike{
+		Name:          "Calc",
+		PkgName:       "app",
+		PkgImportName: "example.com/app",
+		Variant: []Shape{
+			&StructLike{Name: "Add", PkgName: "app", PkgImportName: "example.com/app"},
+			&StructLike{Name: "Div", PkgName: "app", PkgImportName: "example.com/app"},
+		},
+		Tags: map[string]Tag{
+			"mkunion": {Value: "Calc"},
+			"service": {Value: "Answer"},
+		},
+	}
+
+	response, ok := ServiceResponse(union)
+	assert.True(t, ok)
+
+	result := ToTypeScriptService(union, response, &TypeScriptOptions{currentPkgName: "app", currentPkgImportName: "example.com/app"})
+	assert.Equal(t, `export type CalcService = {
+	Add: (x: Add) => Promise<Answer>,
+	Div: (x: Div) => Promise<Answer>,
+}
+
+export function CalcClient(baseURL: string, init?: RequestInit): CalcService {
+	const call = async (path: string, x: any): Promise<any> => {
+		const headers = new Headers(init?.headers)
+		headers.set("Content-Type", "application/json")
+		const response = await fetch(baseURL + path, {
+			...init,
+			method: "POST",
+			headers: headers,
+			body: JSON.stringify(x),
+		})
+		if (!response.ok) {
+			throw new Error(`+"`"+`${path}: ${response.status} ${await response.text()}`+"`"+`)
+		}
+		return response.json()
+	}
+
+	return {
+		Add: (x) => call("/Add", x),
+		Div: (x) => call("/Div", x),
+	}
+}
+`, result)
+}
+
+func TestTypeScriptUnionEncoding(t *testing.T) {
+	union := func(encoding Tag) *UnionLike {
+		return &UnionLike{
+			Name:          "Figure",
+			PkgName:       "app",
+			PkgImportName: "example.com/app",
+			Variant: []Shape{
+				&StructLike{Name: "Circle", PkgName: "app", PkgImportName: "example.com/app", Tags: map[string]Tag{
+					TagVariantName: {Value: "circle"},
+				}},
+				&StructLike{Name: "Square", PkgName: "app", PkgImportName: "example.com/app"},
+			},
+			Tags: map[string]Tag{
+				TagUnionName:     {Value: "Figure"},
+				TagUnionJSONName: encoding,
+			},
+		}
+	}
+
+	useCases := map[string]struct {
+		encoding Tag
+		expected string
+	}{
+		"external with custom tag": {
+			encoding: Tag{Value: JSONUnionExternal, Options: []string{"tag=kind"}},
+			expected: `export type Figure = {
+	"kind"?: "circle",
+	"circle": Circle
+} | {
+	"kind"?: "app.Square",
+	"app.Square": Square
+}
+`,
+		},
+		"internal": {
+			encoding: Tag{Value: JSONUnionInternal, Options: []string{"tag=kind"}},
+			expected: `export type Figure = {
+	"kind": "circle",
+} & Circle | {
+	"kind": "app.Square",
+} & Square
+`,
+		},
+		"adjacent": {
+			encoding: Tag{Value: JSONUnionAdjacent, Options: []string{"tag=t", "content=c"}},
+			expected: `export type Figure = {
+	"t": "circle",
+	"c": Circle
+} | {
+	"t": "app.Square",
+	"c": Square
+}
+`,
+		},
+		"untagged": {
+			encoding: Tag{Value: JSONUnionUntagged},
+			expected: `export type Figure = Circle | Square
+`,
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			result := ToTypeScript(union(uc.encoding), &TypeScriptOptions{currentPkgName: "app", currentPkgImportName: "example.com/app"})
+			assert.Equal(t, uc.expected, result)
+		})
+	}
+}
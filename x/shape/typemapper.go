@@ -0,0 +1,99 @@
+package shape
+
+import (
+	"sync"
+)
+
+// TypeMapper maps Go type, like time.Time, to primitive shape, that its value has on the wire,
+// so that generated serde converts it, and exporters don't refer to it as to opaque type.
+type TypeMapper struct {
+	// Type is mapped type, like time.Time
+	Type *RefName
+	// Wire is shape of value on the wire, like string for time.Time
+	Wire *PrimitiveLike
+	// ToWire is function, that converts value to Go type of Wire, with signature func(Type) (Wire, error),
+	// like shared.TimeToWire, and FromWire is function, that converts it back, with signature func(Wire) (Type, error).
+	ToWire   *RefName
+	FromWire *RefName
+	// TypeScript is type of value in TypeScript, like "string"
+	TypeScript string
+	// JSONSchema is schema of value, like {"type": "string", "format": "date-time"}
+	JSONSchema map[string]any
+}
+
+var typeMappers = sync.Map{}
+
+// RegisterTypeMapper adds mapper to registry, and replaces mapper of the same type.
+// Mappers of time.Time, time.Duration, uuid.UUID, decimal.Decimal and big.Int are registered by default.
+func RegisterTypeMapper(x *TypeMapper) {
+	typeMappers.Store(typeMapperKey(x.Type), x)
+}
+
+// LookupTypeMapper returns mapper of type x.
+func LookupTypeMapper(x *RefName) (*TypeMapper, bool) {
+	if x == nil || x.PkgImportName == "" {
+		return nil, false
+	}
+
+	if v, ok := typeMappers.Load(typeMapperKey(x)); ok {
+		return v.(*TypeMapper), true
+	}
+
+	return nil, false
+}
+
+func typeMapperKey(x *RefName) string {
+	return x.PkgImportName + "." + x.Name
+}
+
+func init() {
+	RegisterTypeMapper(&TypeMapper{
+		Type:       &RefName{Name: "Time", PkgName: "time", PkgImportName: "time"},
+		Wire:       &PrimitiveLike{Kind: &StringLike{}},
+		ToWire:     sharedFunc("TimeToWire"),
+		FromWire:   sharedFunc("TimeFromWire"),
+		TypeScript: "string",
+		JSONSchema: map[string]any{"type": "string", "format": "date-time"},
+	})
+	RegisterTypeMapper(&TypeMapper{
+		Type:       &RefName{Name: "Duration", PkgName: "time", PkgImportName: "time"},
+		Wire:       &PrimitiveLike{Kind: &NumberLike{Kind: &Int64{}}},
+		ToWire:     sharedFunc("DurationToWire"),
+		FromWire:   sharedFunc("DurationFromWire"),
+		TypeScript: "number",
+		JSONSchema: map[string]any{"type": "integer", "format": "int64", "description": "duration in nanoseconds"},
+	})
+
+	// types, that implement encoding.TextMarshaler, are strings on the wire
+	textMapper := func(x *RefName, schema map[string]any) *TypeMapper {
+		return &TypeMapper{
+			Type:       x,
+			Wire:       &PrimitiveLike{Kind: &StringLike{}},
+			ToWire:     sharedFunc("TextToWire", x),
+			FromWire:   sharedFunc("TextFromWire", x),
+			TypeScript: "string",
+			JSONSchema: schema,
+		}
+	}
+	RegisterTypeMapper(textMapper(
+		&RefName{Name: "UUID", PkgName: "uuid", PkgImportName: "github.com/google/uuid"},
+		map[string]any{"type": "string", "format": "uuid"},
+	))
+	RegisterTypeMapper(textMapper(
+		&RefName{Name: "Decimal", PkgName: "decimal", PkgImportName: "github.com/shopspring/decimal"},
+		map[string]any{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?$`},
+	))
+	RegisterTypeMapper(textMapper(
+		&RefName{Name: "Int", PkgName: "big", PkgImportName: "math/big"},
+		map[string]any{"type": "string", "pattern": `^-?[0-9]+$`},
+	))
+}
+
+func sharedFunc(name string, indexed ...Shape) *RefName {
+	return &RefName{
+		Name:          name,
+		PkgName:       "shared",
+		PkgImportName: "github.com/widmogrod/mkunion/x/shared",
+		Indexed:       indexed,
+	}
+}
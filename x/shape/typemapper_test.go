@@ -0,0 +1,36 @@
+package shape
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLookupTypeMapper(t *testing.T) {
+	mapper, ok := LookupTypeMapper(&RefName{Name: "Time", PkgName: "time", PkgImportName: "time"})
+	if assert.True(t, ok) {
+		assert.Equal(t, &PrimitiveLike{Kind: &StringLike{}}, mapper.Wire)
+		assert.Equal(t, "shared.TimeToWire", ToGoTypeName(mapper.ToWire))
+	}
+
+	_, ok = LookupTypeMapper(&RefName{Name: "Time", PkgName: "app", PkgImportName: "example.com/app"})
+	assert.False(t, ok)
+
+	_, ok = LookupTypeMapper(&RefName{Name: "Time"})
+	assert.False(t, ok)
+
+	custom := &RefName{Name: "Money", PkgName: "money", PkgImportName: "example.com/money"}
+	RegisterTypeMapper(&TypeMapper{
+		Type:       custom,
+		Wire:       &PrimitiveLike{Kind: &StringLike{}},
+		ToWire:     &RefName{Name: "ToWire", PkgName: "money", PkgImportName: "example.com/money"},
+		FromWire:   &RefName{Name: "FromWire", PkgName: "money", PkgImportName: "example.com/money"},
+		TypeScript: "string",
+		JSONSchema: map[string]any{"type": "string"},
+	})
+	defer typeMappers.Delete(typeMapperKey(custom))
+
+	mapper, ok = LookupTypeMapper(&RefName{Name: "Money", PkgName: "money", PkgImportName: "example.com/money"})
+	if assert.True(t, ok) {
+		assert.Equal(t, "string", mapper.TypeScript)
+	}
+}
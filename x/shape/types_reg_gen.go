@@ -6,6 +6,9 @@ import (
 	"github.com/sashabaranov/go-openai/jsonschema"
 	"github.com/widmogrod/mkunion/x/shared"
 	"go/ast"
+	"go/types"
+	"golang.org/x/tools/go/packages"
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -17,11 +20,16 @@ func init() {
 	shared.TypeRegistryStore[AliasLike]("github.com/widmogrod/mkunion/x/shape.AliasLike")
 	shared.TypeRegistryStore[AndGuard]("github.com/widmogrod/mkunion/x/shape.AndGuard")
 	shared.TypeRegistryStore[Any]("github.com/widmogrod/mkunion/x/shape.Any")
+	shared.TypeRegistryStore[Between]("github.com/widmogrod/mkunion/x/shape.Between")
 	shared.TypeRegistryStore[BooleanLike]("github.com/widmogrod/mkunion/x/shape.BooleanLike")
 	shared.TypeRegistryStore[Enum]("github.com/widmogrod/mkunion/x/shape.Enum")
+	shared.TypeRegistryStore[FieldAdded]("github.com/widmogrod/mkunion/x/shape.FieldAdded")
 	shared.TypeRegistryStore[FieldLike]("github.com/widmogrod/mkunion/x/shape.FieldLike")
+	shared.TypeRegistryStore[FieldRemoved]("github.com/widmogrod/mkunion/x/shape.FieldRemoved")
 	shared.TypeRegistryStore[Float32]("github.com/widmogrod/mkunion/x/shape.Float32")
 	shared.TypeRegistryStore[Float64]("github.com/widmogrod/mkunion/x/shape.Float64")
+	shared.TypeRegistryStore[GuardChanged]("github.com/widmogrod/mkunion/x/shape.GuardChanged")
+	shared.TypeRegistryStore[GuardError]("github.com/widmogrod/mkunion/x/shape.GuardError")
 	shared.TypeRegistryStore[IndexedTypeWalker]("github.com/widmogrod/mkunion/x/shape.IndexedTypeWalker")
 	shared.TypeRegistryStore[InferredInfo]("github.com/widmogrod/mkunion/x/shape.InferredInfo")
 	shared.TypeRegistryStore[Int]("github.com/widmogrod/mkunion/x/shape.Int")
@@ -29,17 +37,38 @@ func init() {
 	shared.TypeRegistryStore[Int32]("github.com/widmogrod/mkunion/x/shape.Int32")
 	shared.TypeRegistryStore[Int64]("github.com/widmogrod/mkunion/x/shape.Int64")
 	shared.TypeRegistryStore[Int8]("github.com/widmogrod/mkunion/x/shape.Int8")
+	shared.TypeRegistryStore[JSONSchemaRenderer]("github.com/widmogrod/mkunion/x/shape.JSONSchemaRenderer")
+	shared.TypeRegistryStore[JSONUnionEncoding]("github.com/widmogrod/mkunion/x/shape.JSONUnionEncoding")
 	shared.TypeRegistryStore[ListLike]("github.com/widmogrod/mkunion/x/shape.ListLike")
 	shared.TypeRegistryStore[MapLike]("github.com/widmogrod/mkunion/x/shape.MapLike")
+	shared.TypeRegistryStore[MaxItems]("github.com/widmogrod/mkunion/x/shape.MaxItems")
+	shared.TypeRegistryStore[MaxLength]("github.com/widmogrod/mkunion/x/shape.MaxLength")
+	shared.TypeRegistryStore[MinItems]("github.com/widmogrod/mkunion/x/shape.MinItems")
+	shared.TypeRegistryStore[MinLength]("github.com/widmogrod/mkunion/x/shape.MinLength")
 	shared.TypeRegistryStore[NodeAndTag]("github.com/widmogrod/mkunion/x/shape.NodeAndTag")
 	shared.TypeRegistryStore[NumberLike]("github.com/widmogrod/mkunion/x/shape.NumberLike")
+	shared.TypeRegistryStore[OpenAPIRenderer]("github.com/widmogrod/mkunion/x/shape.OpenAPIRenderer")
 	shared.TypeRegistryStore[PointerLike]("github.com/widmogrod/mkunion/x/shape.PointerLike")
 	shared.TypeRegistryStore[PrimitiveLike]("github.com/widmogrod/mkunion/x/shape.PrimitiveLike")
+	shared.TypeRegistryStore[ProtoLock]("github.com/widmogrod/mkunion/x/shape.ProtoLock")
+	shared.TypeRegistryStore[ProtoOptions]("github.com/widmogrod/mkunion/x/shape.ProtoOptions")
+	shared.TypeRegistryStore[ProtobufRenderer]("github.com/widmogrod/mkunion/x/shape.ProtobufRenderer")
+	shared.TypeRegistryStore[PythonOptions]("github.com/widmogrod/mkunion/x/shape.PythonOptions")
+	shared.TypeRegistryStore[PythonRenderer]("github.com/widmogrod/mkunion/x/shape.PythonRenderer")
 	shared.TypeRegistryStore[RefName]("github.com/widmogrod/mkunion/x/shape.RefName")
+	shared.TypeRegistryStore[Regexp]("github.com/widmogrod/mkunion/x/shape.Regexp")
 	shared.TypeRegistryStore[Required]("github.com/widmogrod/mkunion/x/shape.Required")
+	shared.TypeRegistryStore[RustOptions]("github.com/widmogrod/mkunion/x/shape.RustOptions")
+	shared.TypeRegistryStore[RustRenderer]("github.com/widmogrod/mkunion/x/shape.RustRenderer")
 	shared.TypeRegistryStore[StringLike]("github.com/widmogrod/mkunion/x/shape.StringLike")
+	shared.TypeRegistryStore[StructField]("github.com/widmogrod/mkunion/x/shape.StructField")
 	shared.TypeRegistryStore[StructLike]("github.com/widmogrod/mkunion/x/shape.StructLike")
+	shared.TypeRegistryStore[Tag]("github.com/widmogrod/mkunion/x/shape.Tag")
+	shared.TypeRegistryStore[TagChanged]("github.com/widmogrod/mkunion/x/shape.TagChanged")
+	shared.TypeRegistryStore[TypeChanged]("github.com/widmogrod/mkunion/x/shape.TypeChanged")
+	shared.TypeRegistryStore[TypeMapper]("github.com/widmogrod/mkunion/x/shape.TypeMapper")
 	shared.TypeRegistryStore[TypeParam]("github.com/widmogrod/mkunion/x/shape.TypeParam")
+	shared.TypeRegistryStore[TypeParamsChanged]("github.com/widmogrod/mkunion/x/shape.TypeParamsChanged")
 	shared.TypeRegistryStore[TypeScriptOptions]("github.com/widmogrod/mkunion/x/shape.TypeScriptOptions")
 	shared.TypeRegistryStore[TypeScriptRenderer]("github.com/widmogrod/mkunion/x/shape.TypeScriptRenderer")
 	shared.TypeRegistryStore[UInt]("github.com/widmogrod/mkunion/x/shape.UInt")
@@ -48,11 +77,22 @@ func init() {
 	shared.TypeRegistryStore[UInt64]("github.com/widmogrod/mkunion/x/shape.UInt64")
 	shared.TypeRegistryStore[UInt8]("github.com/widmogrod/mkunion/x/shape.UInt8")
 	shared.TypeRegistryStore[UnionLike]("github.com/widmogrod/mkunion/x/shape.UnionLike")
+	shared.TypeRegistryStore[VariantAdded]("github.com/widmogrod/mkunion/x/shape.VariantAdded")
+	shared.TypeRegistryStore[VariantRemoved]("github.com/widmogrod/mkunion/x/shape.VariantRemoved")
+	shared.TypeRegistryStore[VariantRenamed]("github.com/widmogrod/mkunion/x/shape.VariantRenamed")
 	shared.TypeRegistryStore[ast.CommentGroup]("go/ast.CommentGroup")
+	shared.TypeRegistryStore[ast.Field]("go/ast.Field")
 	shared.TypeRegistryStore[ast.FieldList]("go/ast.FieldList")
+	shared.TypeRegistryStore[ast.File]("go/ast.File")
+	shared.TypeRegistryStore[ast.GenDecl]("go/ast.GenDecl")
 	shared.TypeRegistryStore[ast.SelectorExpr]("go/ast.SelectorExpr")
 	shared.TypeRegistryStore[ast.TypeSpec]("go/ast.TypeSpec")
+	shared.TypeRegistryStore[types.TypeList]("go/types.TypeList")
+	shared.TypeRegistryStore[types.TypeName]("go/types.TypeName")
+	shared.TypeRegistryStore[packages.Config]("golang.org/x/tools/go/packages.Config")
+	shared.TypeRegistryStore[packages.Package]("golang.org/x/tools/go/packages.Package")
 	shared.TypeRegistryStore[int]("int")
+	shared.TypeRegistryStore[regexp.Regexp]("regexp.Regexp")
 	shared.TypeRegistryStore[string]("string")
 	shared.TypeRegistryStore[strings.Builder]("strings.Builder")
 	shared.TypeRegistryStore[testing.T]("testing.T")
@@ -0,0 +1,154 @@
+package shared
+
+import (
+	"math"
+	"reflect"
+)
+
+// HashOffset is initial value of hash, that generated Hash methods start with.
+// Hashes are computed with FNV-1a, so they are stable between processes.
+const HashOffset uint64 = 14695981039346656037
+
+const hashPrime uint64 = 1099511628211
+
+func HashString(h uint64, x string) uint64 {
+	for i := 0; i < len(x); i++ {
+		h ^= uint64(x[i])
+		h *= hashPrime
+	}
+
+	return h
+}
+
+func HashUint64(h uint64, x uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= x & 0xff
+		h *= hashPrime
+		x >>= 8
+	}
+
+	return h
+}
+
+func HashBool(h uint64, x bool) uint64 {
+	if x {
+		return HashUint64(h, 1)
+	}
+
+	return HashUint64(h, 0)
+}
+
+func HashFloat64(h uint64, x float64) uint64 {
+	if x == 0 {
+		// -0 and +0 are equal, so they must have the same hash
+		x = 0
+	}
+
+	return HashUint64(h, math.Float64bits(x))
+}
+
+// Equal compares a and b with generated Equal method, and falls back to reflect.DeepEqual.
+// Generated code uses it for values of type parameters, and types that don't have generated methods.
+func Equal[A any](a, b A) bool {
+	if x, ok := any(a).(interface{ Equal(A) bool }); ok {
+		return x.Equal(b)
+	}
+
+	// union values hold variants, which Equal method accepts variant, not union type
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.IsValid() && vb.IsValid() && va.Type() == vb.Type() {
+		method := va.MethodByName("Equal")
+		if method.IsValid() &&
+			method.Type().NumIn() == 1 &&
+			method.Type().In(0) == va.Type() &&
+			method.Type().NumOut() == 1 &&
+			method.Type().Out(0).Kind() == reflect.Bool {
+			return method.Call([]reflect.Value{vb})[0].Bool()
+		}
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// Hash adds hash of x to h, using generated Hash method or hash of primitive value.
+// Other values don't change h, which is consistent with Equal, but weaker.
+func Hash[A any](h uint64, x A) uint64 {
+	switch y := any(x).(type) {
+	case nil:
+		return h
+	case interface{ Hash() uint64 }:
+		return HashUint64(h, y.Hash())
+	case string:
+		return HashString(h, y)
+	case bool:
+		return HashBool(h, y)
+	case int:
+		return HashUint64(h, uint64(y))
+	case int8:
+		return HashUint64(h, uint64(y))
+	case int16:
+		return HashUint64(h, uint64(y))
+	case int32:
+		return HashUint64(h, uint64(y))
+	case int64:
+		return HashUint64(h, uint64(y))
+	case uint:
+		return HashUint64(h, uint64(y))
+	case uint8:
+		return HashUint64(h, uint64(y))
+	case uint16:
+		return HashUint64(h, uint64(y))
+	case uint32:
+		return HashUint64(h, uint64(y))
+	case uint64:
+		return HashUint64(h, y)
+	case float32:
+		return HashFloat64(h, float64(y))
+	case float64:
+		return HashFloat64(h, y)
+	}
+
+	return h
+}
+
+// DeepCopy returns copy of x made by generated DeepCopy method.
+// Values without generated DeepCopy method, are returned as they are.
+func DeepCopy[A any](x A) A {
+	if y, ok := any(x).(interface{ DeepCopy() A }); ok {
+		return y.DeepCopy()
+	}
+
+	if method, ok := deepCopyMethod(x); ok {
+		return method.Call(nil)[0].Interface().(A)
+	}
+
+	return x
+}
+
+// CanDeepCopy returns true, when x has generated DeepCopy method.
+func CanDeepCopy[A any](x A) bool {
+	if _, ok := any(x).(interface{ DeepCopy() A }); ok {
+		return true
+	}
+
+	_, ok := deepCopyMethod(x)
+	return ok
+}
+
+// deepCopyMethod returns DeepCopy method of variant held by union value, which returns variant, not union type.
+func deepCopyMethod[A any](x A) (reflect.Value, bool) {
+	v := reflect.ValueOf(x)
+	if !v.IsValid() {
+		return reflect.Value{}, false
+	}
+
+	method := v.MethodByName("DeepCopy")
+	if !method.IsValid() ||
+		method.Type().NumIn() != 0 ||
+		method.Type().NumOut() != 1 ||
+		method.Type().Out(0) != v.Type() {
+		return reflect.Value{}, false
+	}
+
+	return method, true
+}
@@ -0,0 +1,66 @@
+package shared
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+//go:tag shape:"-"
+type derived struct {
+	Items []int
+}
+
+func (r *derived) Equal(other *derived) bool {
+	return r == other || (r != nil && other != nil && len(r.Items) == len(other.Items))
+}
+
+func (r *derived) Hash() uint64 {
+	return HashUint64(HashOffset, uint64(len(r.Items)))
+}
+
+func (r *derived) DeepCopy() *derived {
+	return &derived{Items: append([]int(nil), r.Items...)}
+}
+
+//go:tag shape:"-"
+type derivedUnion interface {
+	isDerivedUnion()
+}
+
+func (r *derived) isDerivedUnion() {}
+
+func TestEqual(t *testing.T) {
+	a := &derived{Items: []int{1, 2}}
+	b := &derived{Items: []int{3, 4}}
+
+	assert.True(t, Equal(a, b), "uses Equal method")
+	assert.True(t, Equal[derivedUnion](a, b), "uses Equal method of variant")
+	assert.False(t, Equal[derivedUnion](a, nil))
+	assert.True(t, Equal[derivedUnion](nil, nil))
+	assert.True(t, Equal(map[string]int{"a": 1}, map[string]int{"a": 1}), "falls back to reflect.DeepEqual")
+	assert.False(t, Equal([]int{1}, []int{2}))
+}
+
+func TestHash(t *testing.T) {
+	assert.Equal(t, HashString(HashOffset, "a"), Hash(HashOffset, "a"))
+	assert.Equal(t, HashUint64(HashOffset, uint64(7)), Hash(HashOffset, int8(7)))
+	assert.Equal(t, HashFloat64(HashOffset, 0), HashFloat64(HashOffset, math.Copysign(0, -1)))
+	assert.Equal(t, HashUint64(HashOffset, (&derived{Items: []int{1}}).Hash()), Hash[derivedUnion](HashOffset, &derived{Items: []int{1}}))
+	assert.Equal(t, HashOffset, Hash[any](HashOffset, nil))
+	assert.NotEqual(t, HashString(HashOffset, "ab"), HashString(HashOffset, "ba"))
+	assert.NotEqual(t, HashBool(HashOffset, true), HashBool(HashOffset, false))
+}
+
+func TestDeepCopy(t *testing.T) {
+	var x derivedUnion = &derived{Items: []int{1, 2}}
+
+	assert.True(t, CanDeepCopy(x))
+	y := DeepCopy(x)
+	y.(*derived).Items[0] = 100
+	assert.Equal(t, []int{1, 2}, x.(*derived).Items)
+
+	assert.False(t, CanDeepCopy(map[string]int{}))
+	assert.False(t, CanDeepCopy[derivedUnion](nil))
+	assert.Equal(t, "a", DeepCopy("a"))
+}
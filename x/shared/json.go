@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"slices"
 	"sync"
 )
 
@@ -181,6 +182,156 @@ func JSONMarshal[A any](in A) ([]byte, error) {
 	return out, nil
 }
 
+// JSONVersion returns version of union stored in "$version" field by generated JSON serde,
+// or 1 when value was serialised before union declared its version.
+func JSONVersion(data []byte) (int, error) {
+	var header struct {
+		Version *int `json:"$version"`
+	}
+
+	err := json.Unmarshal(data, &header)
+	if err != nil {
+		return 0, fmt.Errorf("shared.JSONVersion: %w", err)
+	}
+
+	if header.Version == nil {
+		return 1, nil
+	}
+
+	return *header.Version, nil
+}
+
+// JSONPrependField returns JSON object with field name set to value, followed by fields of object,
+// generated JSON serde uses it to store tag of internally tagged union next to fields of variant.
+func JSONPrependField(name, value string, object []byte) ([]byte, error) {
+	object = bytes.TrimSpace(object)
+	if len(object) < 2 || object[0] != '{' || object[len(object)-1] != '}' {
+		return nil, fmt.Errorf("shared.JSONPrependField: expected JSON object, given %s", object)
+	}
+
+	key, err := json.Marshal(name)
+	if err != nil {
+		return nil, fmt.Errorf("shared.JSONPrependField: %w", err)
+	}
+
+	val, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("shared.JSONPrependField: %w", err)
+	}
+
+	rest := bytes.TrimSpace(object[1:])
+
+	result := make([]byte, 0, len(object)+len(key)+len(val)+2)
+	result = append(result, '{')
+	result = append(result, key...)
+	result = append(result, ':')
+	result = append(result, val...)
+	if rest[0] != '}' {
+		result = append(result, ',')
+	}
+	result = append(result, rest...)
+
+	return result, nil
+}
+
+// JSONQuote encodes JSON value inside JSON string, like encoding/json does for fields with `json:",string"` option.
+// Nil value is returned as is, so that omitted field stays omitted.
+func JSONQuote(data []byte) ([]byte, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	result, err := json.Marshal(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("shared.JSONQuote: %w", err)
+	}
+
+	return result, nil
+}
+
+// JSONUnquote decodes JSON value encoded inside JSON string by JSONQuote. Null is returned as is.
+func JSONUnquote(data []byte) ([]byte, error) {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return data, nil
+	}
+
+	var result string
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, fmt.Errorf("shared.JSONUnquote: expected JSON string; %w", err)
+	}
+
+	return []byte(result), nil
+}
+
+// JSONIsEmpty reports whether value is omitted by encoding/json from field with `json:",omitempty"` option,
+// because it's false, 0, nil, or empty string, array, slice or map.
+func JSONIsEmpty(x any) bool {
+	if x == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Pointer:
+		return v.IsZero()
+	}
+
+	return false
+}
+
+// JSONIsZero reports whether value is omitted by encoding/json from field with `json:",omitzero"` option,
+// because its IsZero() method returns true, or it's zero value.
+func JSONIsZero(x any) bool {
+	if x == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(x)
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return true
+	}
+
+	if z, ok := x.(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+
+	return v.IsZero()
+}
+
+// JSONObjectKeys returns keys of JSON object, or false, when x is not an object.
+func JSONObjectKeys(x []byte) ([]string, bool) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(x, &object); err != nil || object == nil {
+		return nil, false
+	}
+
+	result := make([]string, 0, len(object))
+	for key := range object {
+		result = append(result, key)
+	}
+
+	return result, true
+}
+
+// JSONKeysKnown reports whether every key is one of known,
+// which is how untagged union finds struct variants, that JSON object can be decoded into.
+func JSONKeysKnown(keys []string, known ...string) bool {
+	for _, key := range keys {
+		if !slices.Contains(known, key) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func JSONIsNativePath(x any) bool {
 	switch x.(type) {
 	case
@@ -0,0 +1,95 @@
+package shared
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestJSONPrependField(t *testing.T) {
+	useCases := map[string]struct {
+		object   string
+		expected string
+	}{
+		"empty object": {
+			object:   `{}`,
+			expected: `{"type":"circle"}`,
+		},
+		"empty object with spaces": {
+			object:   ` { } `,
+			expected: `{"type":"circle"}`,
+		},
+		"object with fields": {
+			object:   `{"radius":1}`,
+			expected: `{"type":"circle","radius":1}`,
+		},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := JSONPrependField("type", "circle", []byte(uc.object))
+			assert.NoError(t, err)
+			assert.Equal(t, uc.expected, string(result))
+		})
+	}
+}
+
+func TestJSONPrependField_NotObject(t *testing.T) {
+	_, err := JSONPrependField("type", "circle", []byte(`[1]`))
+	assert.ErrorContains(t, err, "expected JSON object, given [1]")
+}
+
+func TestJSONQuote(t *testing.T) {
+	quoted, err := JSONQuote([]byte(`"a"`))
+	assert.NoError(t, err)
+	assert.Equal(t, `"\"a\""`, string(quoted))
+
+	unquoted, err := JSONUnquote(quoted)
+	assert.NoError(t, err)
+	assert.Equal(t, `"a"`, string(unquoted))
+
+	unquoted, err = JSONUnquote([]byte(`null`))
+	assert.NoError(t, err)
+	assert.Equal(t, `null`, string(unquoted))
+
+	_, err = JSONUnquote([]byte(`1`))
+	assert.ErrorContains(t, err, "expected JSON string")
+}
+
+func TestJSONObjectKeys(t *testing.T) {
+	keys, ok := JSONObjectKeys([]byte(`{"side": 2, "color": null}`))
+	assert.True(t, ok)
+	assert.ElementsMatch(t, []string{"side", "color"}, keys)
+	assert.True(t, JSONKeysKnown(keys, "side", "color", "radius"))
+	assert.False(t, JSONKeysKnown(keys, "side"))
+
+	_, ok = JSONObjectKeys([]byte(`[1]`))
+	assert.False(t, ok)
+
+	_, ok = JSONObjectKeys([]byte(`null`))
+	assert.False(t, ok)
+}
+
+func TestJSONIsEmptyAndIsZero(t *testing.T) {
+	useCases := map[string]struct {
+		value  any
+		empty  bool
+		isZero bool
+	}{
+		"nil":              {value: nil, empty: true, isZero: true},
+		"empty string":     {value: "", empty: true, isZero: true},
+		"zero number":      {value: 0, empty: true, isZero: true},
+		"empty slice":      {value: []int{}, empty: true, isZero: false},
+		"nil map":          {value: map[string]int(nil), empty: true, isZero: true},
+		"nil pointer":      {value: (*int)(nil), empty: true, isZero: true},
+		"zero struct":      {value: struct{ A int }{}, empty: false, isZero: true},
+		"zero array":       {value: [2]int{}, empty: false, isZero: true},
+		"IsZero method":    {value: time.Time{}.In(time.FixedZone("X", 3600)), empty: false, isZero: true},
+		"not IsZero value": {value: time.Unix(0, 0), empty: false, isZero: false},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, uc.empty, JSONIsEmpty(uc.value), "JSONIsEmpty")
+			assert.Equal(t, uc.isZero, JSONIsZero(uc.value), "JSONIsZero")
+		})
+	}
+}
@@ -0,0 +1,792 @@
+package shared
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+)
+
+var registerMsgpackMarshaller = sync.Map{}
+
+type MsgpackMarshaler interface {
+	MarshalMsgpack() ([]byte, error)
+}
+
+type MsgpackUnmarshaler interface {
+	UnmarshalMsgpack([]byte) error
+}
+
+func MsgpackMarshallerRegister[A any](
+	fullName string,
+	from func([]byte) (A, error),
+	to func(A) ([]byte, error),
+) {
+	TypeRegistryStore[A](fullName)
+
+	registerMsgpackMarshaller.Store(fullName, serde[any]{
+		from: func(bytes []byte) (any, error) {
+			return from(bytes)
+		},
+		to: func(a any) ([]byte, error) {
+			if x, ok := a.(A); ok {
+				return to(x)
+			}
+
+			return nil, fmt.Errorf("shared.MsgpackMarshallerRegister: expected %T, given %+#v", new(A), a)
+		},
+	})
+}
+
+// MsgpackMarshal is a generic function to marshal type into msgpack,
+// it supports unions registered with MsgpackMarshallerRegister, types that implement MsgpackMarshaler,
+// and falls back to native values like numbers, strings, lists and maps of them.
+func MsgpackMarshal[A any](in A) ([]byte, error) {
+	x := any(in)
+	if x == nil {
+		return MsgpackAppendNil(nil), nil
+	}
+
+	key := FullTypeName(reflect.TypeOf(new(A)))
+	if fromTo, ok := registerMsgpackMarshaller.Load(key); ok {
+		out, err := fromTo.(serde[any]).to(x)
+		if err != nil {
+			return nil, fmt.Errorf("shared.MsgpackMarshal: in serde; %w", err)
+		}
+		return out, nil
+	}
+
+	if value, ok := any(&in).(MsgpackMarshaler); ok {
+		out, err := value.MarshalMsgpack()
+		if err != nil {
+			return nil, fmt.Errorf("shared.MsgpackMarshal: pointer marshaller; %w", err)
+		}
+		return out, nil
+	}
+
+	out, err := MsgpackAppendAny(nil, x)
+	if err != nil {
+		return nil, fmt.Errorf("shared.MsgpackMarshal: in fallback; %w", err)
+	}
+
+	return out, nil
+}
+
+// MsgpackUnmarshal is a generic function to unmarshal msgpack data into destination type
+func MsgpackUnmarshal[A any](data []byte) (A, error) {
+	var result A
+
+	key := FullTypeName(reflect.TypeOf(new(A)))
+	if fromTo, ok := registerMsgpackMarshaller.Load(key); ok {
+		out, err := fromTo.(serde[any]).from(data)
+		if err != nil {
+			return result, fmt.Errorf("shared.MsgpackUnmarshal: in serde; %w", err)
+		}
+		if out == nil {
+			return result, nil
+		}
+		return out.(A), nil
+	}
+
+	if value, ok := any(&result).(MsgpackUnmarshaler); ok {
+		err := value.UnmarshalMsgpack(data)
+		if err != nil {
+			return result, fmt.Errorf("shared.MsgpackUnmarshal: pointer unmarshaller; %w", err)
+		}
+		return result, nil
+	}
+
+	err := NewMsgpackReader(data).readNative(&result)
+	if err != nil {
+		return result, fmt.Errorf("shared.MsgpackUnmarshal: in fallback; %w", err)
+	}
+
+	return result, nil
+}
+
+func MsgpackAppendNil(b []byte) []byte {
+	return append(b, 0xc0)
+}
+
+func MsgpackAppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+func MsgpackAppendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+}
+
+func MsgpackAppendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return MsgpackAppendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+}
+
+func MsgpackAppendFloat32(b []byte, v float32) []byte {
+	return binary.BigEndian.AppendUint32(append(b, 0xca), math.Float32bits(v))
+}
+
+func MsgpackAppendFloat64(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
+
+func MsgpackAppendString(b []byte, v string) []byte {
+	switch n := len(v); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, v...)
+}
+
+func MsgpackAppendBytes(b []byte, v []byte) []byte {
+	if v == nil {
+		return MsgpackAppendNil(b)
+	}
+
+	switch n := len(v); {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, v...)
+}
+
+func MsgpackAppendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+}
+
+func MsgpackAppendMapHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+}
+
+// MsgpackAppendAny encodes values that don't have generated serde,
+// it's used for `any` fields, and supports the same values that encoding/json produces.
+func MsgpackAppendAny(b []byte, v any) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return MsgpackAppendNil(b), nil
+	case MsgpackMarshaler:
+		data, err := x.MarshalMsgpack()
+		if err != nil {
+			return nil, fmt.Errorf("shared.MsgpackAppendAny: %w", err)
+		}
+		return append(b, data...), nil
+	case bool:
+		return MsgpackAppendBool(b, x), nil
+	case int:
+		return MsgpackAppendInt(b, int64(x)), nil
+	case int8:
+		return MsgpackAppendInt(b, int64(x)), nil
+	case int16:
+		return MsgpackAppendInt(b, int64(x)), nil
+	case int32:
+		return MsgpackAppendInt(b, int64(x)), nil
+	case int64:
+		return MsgpackAppendInt(b, x), nil
+	case uint:
+		return MsgpackAppendUint(b, uint64(x)), nil
+	case uint8:
+		return MsgpackAppendUint(b, uint64(x)), nil
+	case uint16:
+		return MsgpackAppendUint(b, uint64(x)), nil
+	case uint32:
+		return MsgpackAppendUint(b, uint64(x)), nil
+	case uint64:
+		return MsgpackAppendUint(b, x), nil
+	case float32:
+		return MsgpackAppendFloat32(b, x), nil
+	case float64:
+		return MsgpackAppendFloat64(b, x), nil
+	case string:
+		return MsgpackAppendString(b, x), nil
+	case []byte:
+		return MsgpackAppendBytes(b, x), nil
+	case []any:
+		b = MsgpackAppendArrayHeader(b, len(x))
+		for i, item := range x {
+			var err error
+			b, err = MsgpackAppendAny(b, item)
+			if err != nil {
+				return nil, fmt.Errorf("shared.MsgpackAppendAny: at index %d; %w", i, err)
+			}
+		}
+		return b, nil
+	case map[string]any:
+		b = MsgpackAppendMapHeader(b, len(x))
+		for k, item := range x {
+			var err error
+			b = MsgpackAppendString(b, k)
+			b, err = MsgpackAppendAny(b, item)
+			if err != nil {
+				return nil, fmt.Errorf("shared.MsgpackAppendAny: at key %s; %w", k, err)
+			}
+		}
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("shared.MsgpackAppendAny: unsupported type %T, use //go:tag serde:\"msgpack\"", v)
+}
+
+// MsgpackEncodeUnion encodes union variant the same way as JSON serde does,
+// as a map with "$type" key that holds variant name, and variant name key that holds payload.
+func MsgpackEncodeUnion(kind string, payload []byte) []byte {
+	b := MsgpackAppendMapHeader(nil, 2)
+	b = MsgpackAppendString(b, "$type")
+	b = MsgpackAppendString(b, kind)
+	b = MsgpackAppendString(b, kind)
+	return append(b, payload...)
+}
+
+// MsgpackDecodeUnion decodes map produced by MsgpackEncodeUnion,
+// payloads are indexed by key and are not decoded.
+func MsgpackDecodeUnion(data []byte) (string, map[string][]byte, error) {
+	d := NewMsgpackReader(data)
+	n, err := d.ReadMapHeader()
+	if err != nil {
+		return "", nil, fmt.Errorf("shared.MsgpackDecodeUnion: %w", err)
+	}
+
+	var kind string
+	payloads := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		key, err := d.ReadString()
+		if err != nil {
+			return "", nil, fmt.Errorf("shared.MsgpackDecodeUnion: key; %w", err)
+		}
+
+		if key == "$type" {
+			kind, err = d.ReadString()
+		} else {
+			payloads[key], err = d.ReadRaw()
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("shared.MsgpackDecodeUnion: key %s; %w", key, err)
+		}
+	}
+
+	return kind, payloads, nil
+}
+
+var ErrMsgpackTruncated = errors.New("shared: msgpack data is truncated")
+
+// MsgpackReader decodes msgpack values one by one.
+//
+//go:tag shape:"-"
+type MsgpackReader struct {
+	data []byte
+	pos  int
+}
+
+func NewMsgpackReader(data []byte) *MsgpackReader {
+	return &MsgpackReader{data: data}
+}
+
+// Len returns number of not consumed bytes
+func (d *MsgpackReader) Len() int {
+	return len(d.data) - d.pos
+}
+
+func (d *MsgpackReader) peek() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, ErrMsgpackTruncated
+	}
+	return d.data[d.pos], nil
+}
+
+func (d *MsgpackReader) next(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, ErrMsgpackTruncated
+	}
+	result := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return result, nil
+}
+
+func (d *MsgpackReader) readUint(n int) (uint64, error) {
+	data, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n {
+	case 1:
+		return uint64(data[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(data)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(data)), nil
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+func (d *MsgpackReader) unexpected(expected string, code byte) error {
+	return fmt.Errorf("shared.MsgpackReader: expected %s, given 0x%02x at %d", expected, code, d.pos)
+}
+
+// IsNil returns true when next value is nil, without consuming it
+func (d *MsgpackReader) IsNil() bool {
+	code, err := d.peek()
+	return err == nil && code == 0xc0
+}
+
+func (d *MsgpackReader) ReadNil() error {
+	code, err := d.peek()
+	if err != nil {
+		return err
+	}
+	if code != 0xc0 {
+		return d.unexpected("nil", code)
+	}
+	d.pos++
+	return nil
+}
+
+func (d *MsgpackReader) ReadBool() (bool, error) {
+	code, err := d.peek()
+	if err != nil {
+		return false, err
+	}
+
+	switch code {
+	case 0xc2:
+		d.pos++
+		return false, nil
+	case 0xc3:
+		d.pos++
+		return true, nil
+	}
+
+	return false, d.unexpected("bool", code)
+}
+
+func (d *MsgpackReader) ReadInt64() (int64, error) {
+	code, err := d.peek()
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case code <= 0x7f:
+		d.pos++
+		return int64(code), nil
+	case code >= 0xe0:
+		d.pos++
+		return int64(int8(code)), nil
+	}
+
+	d.pos++
+	switch code {
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.readUint(1 << (code - 0xcc))
+		return int64(v), err
+	case 0xd0:
+		v, err := d.readUint(1)
+		return int64(int8(v)), err
+	case 0xd1:
+		v, err := d.readUint(2)
+		return int64(int16(v)), err
+	case 0xd2:
+		v, err := d.readUint(4)
+		return int64(int32(v)), err
+	case 0xd3:
+		v, err := d.readUint(8)
+		return int64(v), err
+	}
+
+	d.pos--
+	return 0, d.unexpected("integer", code)
+}
+
+func (d *MsgpackReader) ReadUint64() (uint64, error) {
+	code, err := d.peek()
+	if err != nil {
+		return 0, err
+	}
+
+	if code >= 0xcc && code <= 0xcf {
+		d.pos++
+		return d.readUint(1 << (code - 0xcc))
+	}
+
+	v, err := d.ReadInt64()
+	return uint64(v), err
+}
+
+func (d *MsgpackReader) ReadFloat64() (float64, error) {
+	code, err := d.peek()
+	if err != nil {
+		return 0, err
+	}
+
+	switch code {
+	case 0xca:
+		d.pos++
+		v, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		d.pos++
+		v, err := d.readUint(8)
+		return math.Float64frombits(v), err
+	case 0xcf:
+		v, err := d.ReadUint64()
+		return float64(v), err
+	}
+
+	v, err := d.ReadInt64()
+	return float64(v), err
+}
+
+func (d *MsgpackReader) ReadFloat32() (float32, error) {
+	v, err := d.ReadFloat64()
+	return float32(v), err
+}
+
+func (d *MsgpackReader) readLength(code byte, fix, fixMask, code8, code16, code32 byte) (int, bool, error) {
+	switch {
+	case fixMask != 0 && code&^fixMask == fix:
+		d.pos++
+		return int(code & fixMask), true, nil
+	case code == code8 && code8 != 0:
+		d.pos++
+		v, err := d.readUint(1)
+		return int(v), true, err
+	case code == code16:
+		d.pos++
+		v, err := d.readUint(2)
+		return int(v), true, err
+	case code == code32:
+		d.pos++
+		v, err := d.readUint(4)
+		return int(v), true, err
+	}
+
+	return 0, false, nil
+}
+
+func (d *MsgpackReader) ReadString() (string, error) {
+	data, err := d.ReadBytes()
+	return string(data), err
+}
+
+// ReadBytes reads bin or str value, returned slice points to underlying data
+func (d *MsgpackReader) ReadBytes() ([]byte, error) {
+	code, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	if code == 0xc0 {
+		d.pos++
+		return nil, nil
+	}
+
+	n, ok, err := d.readLength(code, 0xa0, 0x1f, 0xd9, 0xda, 0xdb)
+	if !ok {
+		n, ok, err = d.readLength(code, 0, 0, 0xc4, 0xc5, 0xc6)
+	}
+	if !ok {
+		return nil, d.unexpected("string or binary", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return d.next(n)
+}
+
+func (d *MsgpackReader) ReadArrayHeader() (int, error) {
+	code, err := d.peek()
+	if err != nil {
+		return 0, err
+	}
+
+	if code == 0xc0 {
+		d.pos++
+		return 0, nil
+	}
+
+	n, ok, err := d.readLength(code, 0x90, 0x0f, 0, 0xdc, 0xdd)
+	if !ok {
+		return 0, d.unexpected("array", code)
+	}
+	return n, err
+}
+
+func (d *MsgpackReader) ReadMapHeader() (int, error) {
+	code, err := d.peek()
+	if err != nil {
+		return 0, err
+	}
+
+	if code == 0xc0 {
+		d.pos++
+		return 0, nil
+	}
+
+	n, ok, err := d.readLength(code, 0x80, 0x0f, 0, 0xde, 0xdf)
+	if !ok {
+		return 0, d.unexpected("map", code)
+	}
+	return n, err
+}
+
+// ReadRaw returns next value without decoding it, similar to json.RawMessage
+func (d *MsgpackReader) ReadRaw() ([]byte, error) {
+	start := d.pos
+	err := d.Skip()
+	if err != nil {
+		return nil, err
+	}
+	return d.data[start:d.pos], nil
+}
+
+// Skip consumes next value, it's used to ignore unknown fields
+func (d *MsgpackReader) Skip() error {
+	code, err := d.peek()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case code <= 0x7f, code >= 0xe0, code == 0xc0, code == 0xc2, code == 0xc3:
+		d.pos++
+		return nil
+	case code&0xf0 == 0x80, code == 0xde, code == 0xdf:
+		n, err := d.ReadMapHeader()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < 2*n; i++ {
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case code&0xf0 == 0x90, code == 0xdc, code == 0xdd:
+		n, err := d.ReadArrayHeader()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case code&0xe0 == 0xa0, code >= 0xd9 && code <= 0xdb, code >= 0xc4 && code <= 0xc6:
+		_, err := d.ReadBytes()
+		return err
+	case code == 0xca, code == 0xcb:
+		_, err := d.ReadFloat64()
+		return err
+	case code >= 0xcc && code <= 0xd3:
+		_, err := d.ReadInt64()
+		return err
+	case code >= 0xd4 && code <= 0xd8:
+		// fixext 1, 2, 4, 8, 16 with type byte
+		d.pos++
+		_, err := d.next(1 + 1<<(code-0xd4))
+		return err
+	case code >= 0xc7 && code <= 0xc9:
+		d.pos++
+		n, err := d.readUint(1 << (code - 0xc7))
+		if err != nil {
+			return err
+		}
+		_, err = d.next(int(n) + 1)
+		return err
+	}
+
+	return d.unexpected("value", code)
+}
+
+// ReadAny decodes value into nil, bool, int64, uint64, float64, string, []byte, []any or map[string]any
+func (d *MsgpackReader) ReadAny() (any, error) {
+	code, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case code == 0xc0:
+		d.pos++
+		return nil, nil
+	case code == 0xc2, code == 0xc3:
+		return d.ReadBool()
+	case code == 0xcf:
+		return d.ReadUint64()
+	case code <= 0x7f, code >= 0xe0, code >= 0xcc && code <= 0xd3:
+		return d.ReadInt64()
+	case code == 0xca, code == 0xcb:
+		return d.ReadFloat64()
+	case code&0xe0 == 0xa0, code >= 0xd9 && code <= 0xdb:
+		return d.ReadString()
+	case code >= 0xc4 && code <= 0xc6:
+		data, err := d.ReadBytes()
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), data...), nil
+	case code&0xf0 == 0x90, code == 0xdc, code == 0xdd:
+		n, err := d.ReadArrayHeader()
+		if err != nil {
+			return nil, err
+		}
+		result := make([]any, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := d.ReadAny()
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+		}
+		return result, nil
+	case code&0xf0 == 0x80, code == 0xde, code == 0xdf:
+		n, err := d.ReadMapHeader()
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]any, n)
+		for i := 0; i < n; i++ {
+			key, err := d.ReadAny()
+			if err != nil {
+				return nil, err
+			}
+			item, err := d.ReadAny()
+			if err != nil {
+				return nil, err
+			}
+			result[fmt.Sprint(key)] = item
+		}
+		return result, nil
+	}
+
+	return nil, d.unexpected("value", code)
+}
+
+// readNative decodes value into pointer of native type, used when type doesn't have generated serde
+func (d *MsgpackReader) readNative(dst any) error {
+	var err error
+	switch x := dst.(type) {
+	case *bool:
+		*x, err = d.ReadBool()
+	case *string:
+		*x, err = d.ReadString()
+	case *[]byte:
+		var data []byte
+		data, err = d.ReadBytes()
+		*x = append([]byte(nil), data...)
+	case *int:
+		var v int64
+		v, err = d.ReadInt64()
+		*x = int(v)
+	case *int8:
+		var v int64
+		v, err = d.ReadInt64()
+		*x = int8(v)
+	case *int16:
+		var v int64
+		v, err = d.ReadInt64()
+		*x = int16(v)
+	case *int32:
+		var v int64
+		v, err = d.ReadInt64()
+		*x = int32(v)
+	case *int64:
+		*x, err = d.ReadInt64()
+	case *uint:
+		var v uint64
+		v, err = d.ReadUint64()
+		*x = uint(v)
+	case *uint8:
+		var v uint64
+		v, err = d.ReadUint64()
+		*x = uint8(v)
+	case *uint16:
+		var v uint64
+		v, err = d.ReadUint64()
+		*x = uint16(v)
+	case *uint32:
+		var v uint64
+		v, err = d.ReadUint64()
+		*x = uint32(v)
+	case *uint64:
+		*x, err = d.ReadUint64()
+	case *float32:
+		*x, err = d.ReadFloat32()
+	case *float64:
+		*x, err = d.ReadFloat64()
+	case *any:
+		*x, err = d.ReadAny()
+	case *[]any:
+		var v any
+		v, err = d.ReadAny()
+		if v != nil && err == nil {
+			items, ok := v.([]any)
+			if !ok {
+				return fmt.Errorf("shared.MsgpackReader: expected list, given %T", v)
+			}
+			*x = items
+		}
+	case *map[string]any:
+		var v any
+		v, err = d.ReadAny()
+		if v != nil && err == nil {
+			items, ok := v.(map[string]any)
+			if !ok {
+				return fmt.Errorf("shared.MsgpackReader: expected map, given %T", v)
+			}
+			*x = items
+		}
+	default:
+		return fmt.Errorf("shared.MsgpackReader: unsupported type %T, use //go:tag serde:\"msgpack\"", dst)
+	}
+
+	return err
+}
@@ -0,0 +1,116 @@
+package shared
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMsgpackAppend(t *testing.T) {
+	useCases := map[string]struct {
+		in       []byte
+		expected []byte
+	}{
+		"nil":             {MsgpackAppendNil(nil), []byte{0xc0}},
+		"true":            {MsgpackAppendBool(nil, true), []byte{0xc3}},
+		"positive fixint": {MsgpackAppendInt(nil, 127), []byte{0x7f}},
+		"negative fixint": {MsgpackAppendInt(nil, -32), []byte{0xe0}},
+		"int8":            {MsgpackAppendInt(nil, -33), []byte{0xd0, 0xdf}},
+		"int16":           {MsgpackAppendInt(nil, -300), []byte{0xd1, 0xfe, 0xd4}},
+		"uint8":           {MsgpackAppendUint(nil, 200), []byte{0xcc, 0xc8}},
+		"uint16":          {MsgpackAppendUint(nil, 300), []byte{0xcd, 0x01, 0x2c}},
+		"float32":         {MsgpackAppendFloat32(nil, 1), []byte{0xca, 0x3f, 0x80, 0x00, 0x00}},
+		"fixstr":          {MsgpackAppendString(nil, "ab"), []byte{0xa2, 'a', 'b'}},
+		"bin8":            {MsgpackAppendBytes(nil, []byte{1}), []byte{0xc4, 0x01, 0x01}},
+		"nil bin":         {MsgpackAppendBytes(nil, nil), []byte{0xc0}},
+		"fixarray":        {MsgpackAppendArrayHeader(nil, 3), []byte{0x93}},
+		"array16":         {MsgpackAppendArrayHeader(nil, 16), []byte{0xdc, 0x00, 0x10}},
+		"fixmap":          {MsgpackAppendMapHeader(nil, 1), []byte{0x81}},
+	}
+	for name, uc := range useCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, uc.expected, uc.in)
+		})
+	}
+}
+
+func TestMsgpackReader_Numbers(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, 127, 128, -32, -33, 255, 256, -129, 65536, -40000, math.MaxInt64, math.MinInt64} {
+		result, err := NewMsgpackReader(MsgpackAppendInt(nil, v)).ReadInt64()
+		assert.NoError(t, err)
+		assert.Equal(t, v, result)
+	}
+
+	for _, v := range []uint64{0, 255, 65535, math.MaxUint32, math.MaxUint64} {
+		result, err := NewMsgpackReader(MsgpackAppendUint(nil, v)).ReadUint64()
+		assert.NoError(t, err)
+		assert.Equal(t, v, result)
+	}
+
+	// integers can be read as floats
+	result, err := NewMsgpackReader(MsgpackAppendInt(nil, -3)).ReadFloat64()
+	assert.NoError(t, err)
+	assert.Equal(t, float64(-3), result)
+
+	_, err = NewMsgpackReader(MsgpackAppendString(nil, "x")).ReadInt64()
+	assert.ErrorContains(t, err, "expected integer")
+
+	_, err = NewMsgpackReader([]byte{0xcd, 0x01}).ReadInt64()
+	assert.ErrorIs(t, err, ErrMsgpackTruncated)
+}
+
+func TestMsgpackReader_AnyAndSkip(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	value := map[string]any{
+		"nil":   nil,
+		"bool":  false,
+		"int":   int64(-5),
+		"float": 1.5,
+		"str":   long,
+		"bin":   []byte{1, 2},
+		"list":  []any{int64(1), "a", []any{}},
+		"map":   map[string]any{"k": true},
+	}
+
+	data, err := MsgpackAppendAny(nil, value)
+	assert.NoError(t, err)
+
+	d := NewMsgpackReader(append(data, 0xc3))
+	result, err := d.ReadAny()
+	assert.NoError(t, err)
+	assert.Equal(t, any(value), result)
+	assert.Equal(t, 1, d.Len())
+
+	d = NewMsgpackReader(append(data, 0xc3))
+	err = d.Skip()
+	assert.NoError(t, err)
+	next, err := d.ReadBool()
+	assert.NoError(t, err)
+	assert.True(t, next)
+
+	_, err = MsgpackAppendAny(nil, struct{}{})
+	assert.ErrorContains(t, err, "unsupported type")
+}
+
+func TestMsgpackUnion(t *testing.T) {
+	data := MsgpackEncodeUnion("pkg.A", MsgpackAppendInt(nil, 1))
+
+	kind, payloads, err := MsgpackDecodeUnion(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "pkg.A", kind)
+	assert.Equal(t, map[string][]byte{"pkg.A": {0x01}}, payloads)
+}
+
+func TestMsgpackMarshal_Native(t *testing.T) {
+	data, err := MsgpackMarshal[uint16](300)
+	assert.NoError(t, err)
+
+	result, err := MsgpackUnmarshal[uint16](data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(300), result)
+
+	list, err := MsgpackUnmarshal[[]any](MsgpackAppendNil(nil))
+	assert.NoError(t, err)
+	assert.Nil(t, list)
+}
@@ -0,0 +1,365 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+)
+
+var registerProtoMarshaller = sync.Map{}
+
+// ProtoMarshaler is implemented by types tagged with `//go:tag serde:"proto"`.
+// MarshalProto returns body of protobuf message, without field tag and length prefix.
+type ProtoMarshaler interface {
+	MarshalProto() ([]byte, error)
+}
+
+type ProtoUnmarshaler interface {
+	UnmarshalProto([]byte) error
+}
+
+func ProtoMarshallerRegister[A any](
+	fullName string,
+	from func([]byte) (A, error),
+	to func(A) ([]byte, error),
+) {
+	TypeRegistryStore[A](fullName)
+
+	registerProtoMarshaller.Store(fullName, serde[any]{
+		from: func(bytes []byte) (any, error) {
+			return from(bytes)
+		},
+		to: func(a any) ([]byte, error) {
+			if x, ok := a.(A); ok {
+				return to(x)
+			}
+
+			return nil, fmt.Errorf("shared.ProtoMarshallerRegister: expected %T, given %+#v", new(A), a)
+		},
+	})
+}
+
+// ProtoMarshal is a generic function to marshal type into protobuf message body.
+// Unions are looked up in registry first, since their variants marshal only their own body,
+// then types that implement ProtoMarshaler are used.
+func ProtoMarshal[A any](in A) ([]byte, error) {
+	x := any(in)
+	if x == nil {
+		return nil, nil
+	}
+
+	key := FullTypeName(reflect.TypeOf(new(A)))
+	if fromTo, ok := registerProtoMarshaller.Load(key); ok {
+		out, err := fromTo.(serde[any]).to(x)
+		if err != nil {
+			return nil, fmt.Errorf("shared.ProtoMarshal: in serde; %w", err)
+		}
+		return out, nil
+	}
+
+	if value, ok := x.(ProtoMarshaler); ok {
+		out, err := value.MarshalProto()
+		if err != nil {
+			return nil, fmt.Errorf("shared.ProtoMarshal: value marshaller; %w", err)
+		}
+		return out, nil
+	}
+
+	if value, ok := any(&in).(ProtoMarshaler); ok {
+		out, err := value.MarshalProto()
+		if err != nil {
+			return nil, fmt.Errorf("shared.ProtoMarshal: pointer marshaller; %w", err)
+		}
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("shared.ProtoMarshal: no protobuf marshaller for %s, use //go:tag serde:\"proto\"", key)
+}
+
+// ProtoUnmarshal is a generic function to unmarshal protobuf message body into destination type.
+func ProtoUnmarshal[A any](data []byte) (A, error) {
+	var result A
+
+	key := FullTypeName(reflect.TypeOf(new(A)))
+	if fromTo, ok := registerProtoMarshaller.Load(key); ok {
+		out, err := fromTo.(serde[any]).from(data)
+		if err != nil {
+			return result, fmt.Errorf("shared.ProtoUnmarshal: in serde; %w", err)
+		}
+		if out == nil {
+			return result, nil
+		}
+		return out.(A), nil
+	}
+
+	if value, ok := any(&result).(ProtoUnmarshaler); ok {
+		err := value.UnmarshalProto(data)
+		if err != nil {
+			return result, fmt.Errorf("shared.ProtoUnmarshal: pointer unmarshaller; %w", err)
+		}
+		return result, nil
+	}
+
+	return result, fmt.Errorf("shared.ProtoUnmarshal: no protobuf unmarshaller for %s, use //go:tag serde:\"proto\"", key)
+}
+
+// ProtoWireType is protobuf wire type, that describes how field value is encoded
+//
+//go:tag shape:"-"
+type ProtoWireType int8
+
+const (
+	ProtoWireVarint  ProtoWireType = 0
+	ProtoWireFixed64 ProtoWireType = 1
+	ProtoWireBytes   ProtoWireType = 2
+	ProtoWireFixed32 ProtoWireType = 5
+)
+
+var ErrProtoTruncated = errors.New("shared: protobuf data is truncated")
+
+// ProtoField is single decoded protobuf field.
+// Varint holds value of varint, fixed64 and fixed32 wire types, Bytes holds length delimited value.
+//
+//go:tag shape:"-"
+type ProtoField struct {
+	Num      int
+	WireType ProtoWireType
+	Varint   uint64
+	Bytes    []byte
+}
+
+func (f ProtoField) expect(wireType ProtoWireType) error {
+	if f.WireType != wireType {
+		return fmt.Errorf("shared.ProtoField: field %d expected wire type %d, given %d", f.Num, wireType, f.WireType)
+	}
+	return nil
+}
+
+func (f ProtoField) AsBool() (bool, error) {
+	return f.Varint != 0, f.expect(ProtoWireVarint)
+}
+
+func (f ProtoField) AsInt64() (int64, error) {
+	return int64(f.Varint), f.expect(ProtoWireVarint)
+}
+
+func (f ProtoField) AsUint64() (uint64, error) {
+	return f.Varint, f.expect(ProtoWireVarint)
+}
+
+func (f ProtoField) AsFloat32() (float32, error) {
+	return math.Float32frombits(uint32(f.Varint)), f.expect(ProtoWireFixed32)
+}
+
+func (f ProtoField) AsFloat64() (float64, error) {
+	return math.Float64frombits(f.Varint), f.expect(ProtoWireFixed64)
+}
+
+func (f ProtoField) AsString() (string, error) {
+	return string(f.Bytes), f.expect(ProtoWireBytes)
+}
+
+func (f ProtoField) AsBytes() ([]byte, error) {
+	return f.Bytes, f.expect(ProtoWireBytes)
+}
+
+func ProtoAppendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func ProtoAppendFixed32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func ProtoAppendFixed64(b []byte, v uint64) []byte {
+	return append(b,
+		byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
+		byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
+}
+
+func ProtoAppendTag(b []byte, num int, wireType ProtoWireType) []byte {
+	return ProtoAppendVarint(b, uint64(num)<<3|uint64(wireType))
+}
+
+func ProtoAppendVarintField(b []byte, num int, v uint64) []byte {
+	b = ProtoAppendTag(b, num, ProtoWireVarint)
+	return ProtoAppendVarint(b, v)
+}
+
+func ProtoAppendBoolField(b []byte, num int, v bool) []byte {
+	if v {
+		return ProtoAppendVarintField(b, num, 1)
+	}
+	return ProtoAppendVarintField(b, num, 0)
+}
+
+func ProtoAppendFloat32Field(b []byte, num int, v float32) []byte {
+	b = ProtoAppendTag(b, num, ProtoWireFixed32)
+	return ProtoAppendFixed32(b, math.Float32bits(v))
+}
+
+func ProtoAppendFloat64Field(b []byte, num int, v float64) []byte {
+	b = ProtoAppendTag(b, num, ProtoWireFixed64)
+	return ProtoAppendFixed64(b, math.Float64bits(v))
+}
+
+func ProtoAppendBytesField(b []byte, num int, v []byte) []byte {
+	b = ProtoAppendTag(b, num, ProtoWireBytes)
+	b = ProtoAppendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func ProtoAppendStringField(b []byte, num int, v string) []byte {
+	b = ProtoAppendTag(b, num, ProtoWireBytes)
+	b = ProtoAppendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func ProtoConsumeVarint(data []byte) (uint64, int, error) {
+	var result uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		result |= uint64(data[i]&0x7f) << (7 * i)
+		if data[i] < 0x80 {
+			return result, i + 1, nil
+		}
+	}
+
+	return 0, 0, ErrProtoTruncated
+}
+
+// ProtoConsumeField decodes single field from data, and returns number of consumed bytes.
+func ProtoConsumeField(data []byte) (ProtoField, int, error) {
+	tag, n, err := ProtoConsumeVarint(data)
+	if err != nil {
+		return ProtoField{}, 0, fmt.Errorf("shared.ProtoConsumeField: tag; %w", err)
+	}
+
+	field := ProtoField{
+		Num:      int(tag >> 3),
+		WireType: ProtoWireType(tag & 0x7),
+	}
+	if field.Num <= 0 {
+		return field, 0, fmt.Errorf("shared.ProtoConsumeField: invalid field number %d", field.Num)
+	}
+
+	switch field.WireType {
+	case ProtoWireVarint:
+		v, m, err := ProtoConsumeVarint(data[n:])
+		if err != nil {
+			return field, 0, fmt.Errorf("shared.ProtoConsumeField: field %d varint; %w", field.Num, err)
+		}
+		field.Varint = v
+		return field, n + m, nil
+
+	case ProtoWireFixed64:
+		if len(data[n:]) < 8 {
+			return field, 0, fmt.Errorf("shared.ProtoConsumeField: field %d fixed64; %w", field.Num, ErrProtoTruncated)
+		}
+		for i := 0; i < 8; i++ {
+			field.Varint |= uint64(data[n+i]) << (8 * i)
+		}
+		return field, n + 8, nil
+
+	case ProtoWireFixed32:
+		if len(data[n:]) < 4 {
+			return field, 0, fmt.Errorf("shared.ProtoConsumeField: field %d fixed32; %w", field.Num, ErrProtoTruncated)
+		}
+		for i := 0; i < 4; i++ {
+			field.Varint |= uint64(data[n+i]) << (8 * i)
+		}
+		return field, n + 4, nil
+
+	case ProtoWireBytes:
+		length, m, err := ProtoConsumeVarint(data[n:])
+		if err != nil {
+			return field, 0, fmt.Errorf("shared.ProtoConsumeField: field %d length; %w", field.Num, err)
+		}
+		n += m
+		if uint64(len(data[n:])) < length {
+			return field, 0, fmt.Errorf("shared.ProtoConsumeField: field %d bytes; %w", field.Num, ErrProtoTruncated)
+		}
+		field.Bytes = data[n : n+int(length)]
+		return field, n + int(length), nil
+	}
+
+	return field, 0, fmt.Errorf("shared.ProtoConsumeField: field %d unsupported wire type %d", field.Num, field.WireType)
+}
+
+// ProtoDecodeFields calls f for each field in message body.
+func ProtoDecodeFields(data []byte, f func(field ProtoField) error) error {
+	for len(data) > 0 {
+		field, n, err := ProtoConsumeField(data)
+		if err != nil {
+			return err
+		}
+
+		err = f(field)
+		if err != nil {
+			return err
+		}
+
+		data = data[n:]
+	}
+
+	return nil
+}
+
+// ProtoUnpack calls f for each element of repeated scalar field.
+// Proto3 encodes repeated scalars as packed by default, but decoders must accept both forms.
+func ProtoUnpack(field ProtoField, wireType ProtoWireType, f func(item ProtoField) error) error {
+	if field.WireType != ProtoWireBytes || wireType == ProtoWireBytes {
+		return f(field)
+	}
+
+	data := field.Bytes
+	for len(data) > 0 {
+		item := ProtoField{
+			Num:      field.Num,
+			WireType: wireType,
+		}
+
+		switch wireType {
+		case ProtoWireVarint:
+			v, n, err := ProtoConsumeVarint(data)
+			if err != nil {
+				return fmt.Errorf("shared.ProtoUnpack: field %d; %w", field.Num, err)
+			}
+			item.Varint = v
+			data = data[n:]
+
+		case ProtoWireFixed64:
+			if len(data) < 8 {
+				return fmt.Errorf("shared.ProtoUnpack: field %d; %w", field.Num, ErrProtoTruncated)
+			}
+			for i := 0; i < 8; i++ {
+				item.Varint |= uint64(data[i]) << (8 * i)
+			}
+			data = data[8:]
+
+		case ProtoWireFixed32:
+			if len(data) < 4 {
+				return fmt.Errorf("shared.ProtoUnpack: field %d; %w", field.Num, ErrProtoTruncated)
+			}
+			for i := 0; i < 4; i++ {
+				item.Varint |= uint64(data[i]) << (8 * i)
+			}
+			data = data[4:]
+
+		default:
+			return fmt.Errorf("shared.ProtoUnpack: field %d unsupported wire type %d", field.Num, wireType)
+		}
+
+		err := f(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
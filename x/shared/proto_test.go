@@ -0,0 +1,84 @@
+package shared
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProtoEncoding(t *testing.T) {
+	minusOne := int64(-1)
+
+	var data []byte
+	data = ProtoAppendVarintField(data, 1, 150)
+	data = ProtoAppendStringField(data, 2, "testing")
+	data = ProtoAppendVarintField(data, 3, uint64(minusOne))
+	data = ProtoAppendFloat64Field(data, 4, 1.5)
+	data = ProtoAppendFloat32Field(data, 5, 0.5)
+
+	assert.Equal(t, []byte{
+		0x08, 0x96, 0x01,
+		0x12, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g',
+		0x18, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01,
+		0x21, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf8, 0x3f,
+		0x2d, 0x00, 0x00, 0x00, 0x3f,
+	}, data)
+
+	var fields []ProtoField
+	err := ProtoDecodeFields(data, func(field ProtoField) error {
+		fields = append(fields, field)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, fields, 5)
+
+	v1, err := fields[0].AsUint64()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(150), v1)
+
+	v2, err := fields[1].AsString()
+	assert.NoError(t, err)
+	assert.Equal(t, "testing", v2)
+
+	v3, err := fields[2].AsInt64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), v3)
+
+	v4, err := fields[3].AsFloat64()
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, v4)
+
+	v5, err := fields[4].AsFloat32()
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0.5), v5)
+
+	_, err = fields[1].AsInt64()
+	assert.Error(t, err)
+}
+
+func TestProtoUnpack(t *testing.T) {
+	packed := ProtoAppendVarint(nil, 3)
+	packed = ProtoAppendVarint(packed, 270)
+	packed = ProtoAppendVarint(packed, 86942)
+
+	var result []uint64
+	collect := func(item ProtoField) error {
+		result = append(result, item.Varint)
+		return nil
+	}
+
+	err := ProtoUnpack(ProtoField{Num: 4, WireType: ProtoWireBytes, Bytes: packed}, ProtoWireVarint, collect)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{3, 270, 86942}, result)
+
+	// decoders must accept unpacked form as well
+	err = ProtoUnpack(ProtoField{Num: 4, WireType: ProtoWireVarint, Varint: 5}, ProtoWireVarint, collect)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{3, 270, 86942, 5}, result)
+}
+
+func TestProtoDecodeFields_Truncated(t *testing.T) {
+	err := ProtoDecodeFields([]byte{0x12, 0x07, 't'}, func(field ProtoField) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrProtoTruncated)
+}
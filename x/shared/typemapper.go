@@ -0,0 +1,61 @@
+package shared
+
+import (
+	"encoding"
+	"fmt"
+	"time"
+)
+
+// TimeToWire converts time to ISO-8601 string, like "2006-01-02T15:04:05.999999999Z07:00",
+// the same as time.Time.MarshalJSON does.
+func TimeToWire(x time.Time) (string, error) {
+	return x.Format(time.RFC3339Nano), nil
+}
+
+// TimeFromWire parses ISO-8601 string created by TimeToWire.
+func TimeFromWire(x string) (time.Time, error) {
+	result, err := time.Parse(time.RFC3339Nano, x)
+	if err != nil {
+		return result, fmt.Errorf("shared.TimeFromWire: %w", err)
+	}
+
+	return result, nil
+}
+
+// DurationToWire converts duration to number of nanoseconds, the same as encoding/json does.
+func DurationToWire(x time.Duration) (int64, error) {
+	return int64(x), nil
+}
+
+// DurationFromWire converts number of nanoseconds to duration.
+func DurationFromWire(x int64) (time.Duration, error) {
+	return time.Duration(x), nil
+}
+
+// TextToWire converts value to string with its MarshalText method,
+// that can be declared on value or pointer, like for uuid.UUID or big.Int.
+func TextToWire[A any, PA interface {
+	*A
+	encoding.TextMarshaler
+}](x A) (string, error) {
+	result, err := PA(&x).MarshalText()
+	if err != nil {
+		return "", fmt.Errorf("shared.TextToWire: %w", err)
+	}
+
+	return string(result), nil
+}
+
+// TextFromWire parses string created by TextToWire with UnmarshalText method of value.
+func TextFromWire[A any, PA interface {
+	*A
+	encoding.TextUnmarshaler
+}](x string) (A, error) {
+	var result A
+	err := PA(&result).UnmarshalText([]byte(x))
+	if err != nil {
+		return result, fmt.Errorf("shared.TextFromWire: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package shared
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestTimeWire(t *testing.T) {
+	in := time.Date(2024, 1, 2, 3, 4, 5, 6, time.FixedZone("CET", 3600))
+
+	wire, err := TimeToWire(in)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-02T03:04:05.000000006+01:00", wire)
+
+	result, err := TimeFromWire(wire)
+	assert.NoError(t, err)
+	assert.True(t, in.Equal(result))
+
+	_, err = TimeFromWire("yesterday")
+	assert.ErrorContains(t, err, "shared.TimeFromWire")
+}
+
+func TestDurationWire(t *testing.T) {
+	wire, err := DurationToWire(time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000000000), wire)
+
+	result, err := DurationFromWire(wire)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, result)
+}
+
+func TestTextWire(t *testing.T) {
+	in, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	wire, err := TextToWire(*in)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012345678901234567890", wire)
+
+	result, err := TextFromWire[big.Int](wire)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, in.Cmp(&result))
+
+	_, err = TextFromWire[big.Int]("not a number")
+	assert.ErrorContains(t, err, "shared.TextFromWire")
+}
@@ -1,6 +1,11 @@
 package predicate
 
+// Optimize simplifies predicate, and predicates nested in it.
 func Optimize(p Predicate) Predicate {
+	return RewritePredicateBottomUp(p, optimize)
+}
+
+func optimize(p Predicate) Predicate {
 	return MatchPredicateR1(
 		p,
 		func(x *And) Predicate {
@@ -22,7 +27,8 @@ func Optimize(p Predicate) Predicate {
 			if ok {
 				// double negation is the same as the original
 				// !(!x) == x
-				return Optimize(y.P)
+				// nested predicates are already optimized, since rewrite is bottom-up
+				return y.P
 			}
 			return x
 		},
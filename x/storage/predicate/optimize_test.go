@@ -59,4 +59,52 @@ func TestOptimizePredicates(t *testing.T) {
 		}
 		assert.Equal(t, expected, Optimize(p))
 	})
+
+	t.Run("optimize nested predicates", func(t *testing.T) {
+		p := &And{
+			L: []Predicate{
+				&Or{
+					L: []Predicate{
+						&Not{
+							P: &Not{
+								P: &Compare{
+									Location:  "x",
+									Operation: "=",
+									BindValue: &BindValue{BindName: ":x"},
+								},
+							},
+						},
+					},
+				},
+				&Not{
+					P: &And{
+						L: []Predicate{
+							&Compare{
+								Location:  "y",
+								Operation: ">",
+								BindValue: &BindValue{BindName: ":y"},
+							},
+						},
+					},
+				},
+			},
+		}
+		expected := &And{
+			L: []Predicate{
+				&Compare{
+					Location:  "x",
+					Operation: "=",
+					BindValue: &BindValue{BindName: ":x"},
+				},
+				&Not{
+					P: &Compare{
+						Location:  "y",
+						Operation: ">",
+						BindValue: &BindValue{BindName: ":y"},
+					},
+				},
+			},
+		}
+		assert.Equal(t, expected, Optimize(p))
+	})
 }
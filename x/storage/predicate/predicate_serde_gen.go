@@ -121,3 +121,52 @@ func (r *ParamBinds) _unmarshalJSONschema_Schema(data []byte) (schema.Schema, er
 	}
 	return result, nil
 }
+func (r *ParamBinds) Equal(other *ParamBinds) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(*r) != len(*other) {
+		return false
+	}
+	for k1, v1 := range *r {
+		w1, ok := (*other)[k1]
+		if !ok {
+			return false
+		}
+		if !schema.SchemaEqual(v1, w1) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *ParamBinds) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "predicate.ParamBinds")
+	h = shared.HashUint64(h, uint64(len(*r)))
+	var s1 uint64
+	for k1, v1 := range *r {
+		e1 := shared.HashOffset
+		e1 = shared.HashString(e1, string(k1))
+		e1 = shared.HashUint64(e1, schema.SchemaHash(v1))
+		s1 += e1
+	}
+	h = shared.HashUint64(h, s1)
+	return h
+}
+
+func (r *ParamBinds) DeepCopy() *ParamBinds {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if *r != nil {
+		result = make(map[BindName]schema.Schema, len(*r))
+		for k1, v1 := range *r {
+			result[k1] = schema.SchemaDeepCopy(v1)
+		}
+	}
+	return &result
+}
@@ -100,6 +100,134 @@ func MatchBindableR0(
 		f3(v)
 	}
 }
+
+// BindableEqual returns true, when a and b are the same variant of Bindable with equal fields.
+func BindableEqual(a, b Bindable) bool {
+	switch x := a.(type) {
+	case *BindValue:
+		y, ok := b.(*BindValue)
+		return ok && x.Equal(y)
+	case *Literal:
+		y, ok := b.(*Literal)
+		return ok && x.Equal(y)
+	case *Locatable:
+		y, ok := b.(*Locatable)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// BindableHash returns hash of x, that is the same for values equal according to BindableEqual.
+func BindableHash(x Bindable) uint64 {
+	switch y := x.(type) {
+	case *BindValue:
+		return y.Hash()
+	case *Literal:
+		return y.Hash()
+	case *Locatable:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// BindableDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func BindableDeepCopy(x Bindable) Bindable {
+	switch y := x.(type) {
+	case *BindValue:
+		return y.DeepCopy()
+	case *Literal:
+		return y.DeepCopy()
+	case *Locatable:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *BindValue) Equal(other *BindValue) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.BindName != other.BindName {
+		return false
+	}
+	return true
+}
+
+func (r *BindValue) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "predicate.BindValue")
+	h = shared.HashString(h, string(r.BindName))
+	return h
+}
+
+func (r *BindValue) DeepCopy() *BindValue {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *Literal) Equal(other *Literal) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !schema.SchemaEqual(r.Value, other.Value) {
+		return false
+	}
+	return true
+}
+
+func (r *Literal) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "predicate.Literal")
+	h = shared.HashUint64(h, schema.SchemaHash(r.Value))
+	return h
+}
+
+func (r *Literal) DeepCopy() *Literal {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Value = schema.SchemaDeepCopy(r.Value)
+	return &result
+}
+
+func (r *Locatable) Equal(other *Locatable) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Location != other.Location {
+		return false
+	}
+	return true
+}
+
+func (r *Locatable) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "predicate.Locatable")
+	h = shared.HashString(h, r.Location)
+	return h
+}
+
+func (r *Locatable) DeepCopy() *Locatable {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/predicate.BindValue", BindValueFromJSON, BindValueToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/predicate.Bindable", BindableFromJSON, BindableToJSON)
@@ -118,7 +246,7 @@ func BindableFromJSON(x []byte) (Bindable, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data BindableUnionJSON
@@ -524,6 +652,257 @@ func MatchPredicateR0(
 		f4(v)
 	}
 }
+
+// RewritePredicateTopDown applies f to x, and then to each Predicate nested in result of f (pre-order).
+// When f returns nil, nested values are not visited.
+func RewritePredicateTopDown(x Predicate, f func(Predicate) Predicate) Predicate {
+	if x == nil {
+		return nil
+	}
+	return rewritePredicateChildren(f(x), func(y Predicate) Predicate {
+		return RewritePredicateTopDown(y, f)
+	})
+}
+
+// RewritePredicateBottomUp applies f to each Predicate nested in x, and then to x with already rewritten values (post-order).
+func RewritePredicateBottomUp(x Predicate, f func(Predicate) Predicate) Predicate {
+	if x == nil {
+		return nil
+	}
+	return f(rewritePredicateChildren(x, func(y Predicate) Predicate {
+		return RewritePredicateBottomUp(y, f)
+	}))
+}
+
+// rewritePredicateChildren returns shallow copy of x, where each Predicate nested in x is replaced by result of f.
+// Variants without nested Predicate are returned as they are, and x is never modified.
+func rewritePredicateChildren(x Predicate, f func(Predicate) Predicate) Predicate {
+	switch v := x.(type) {
+	case *And:
+		result := *v
+		if v.L != nil {
+			result.L = make([]Predicate, len(v.L))
+			for i1 := range v.L {
+				result.L[i1] = f(v.L[i1])
+			}
+		}
+		return &result
+	case *Or:
+		result := *v
+		if v.L != nil {
+			result.L = make([]Predicate, len(v.L))
+			for i1 := range v.L {
+				result.L[i1] = f(v.L[i1])
+			}
+		}
+		return &result
+	case *Not:
+		result := *v
+		result.P = f(v.P)
+		return &result
+	}
+
+	return x
+}
+
+// PredicateEqual returns true, when a and b are the same variant of Predicate with equal fields.
+func PredicateEqual(a, b Predicate) bool {
+	switch x := a.(type) {
+	case *And:
+		y, ok := b.(*And)
+		return ok && x.Equal(y)
+	case *Or:
+		y, ok := b.(*Or)
+		return ok && x.Equal(y)
+	case *Not:
+		y, ok := b.(*Not)
+		return ok && x.Equal(y)
+	case *Compare:
+		y, ok := b.(*Compare)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// PredicateHash returns hash of x, that is the same for values equal according to PredicateEqual.
+func PredicateHash(x Predicate) uint64 {
+	switch y := x.(type) {
+	case *And:
+		return y.Hash()
+	case *Or:
+		return y.Hash()
+	case *Not:
+		return y.Hash()
+	case *Compare:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// PredicateDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func PredicateDeepCopy(x Predicate) Predicate {
+	switch y := x.(type) {
+	case *And:
+		return y.DeepCopy()
+	case *Or:
+		return y.DeepCopy()
+	case *Not:
+		return y.DeepCopy()
+	case *Compare:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *And) Equal(other *And) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.L) != len(other.L) {
+		return false
+	}
+	for i1 := range r.L {
+		if !PredicateEqual(r.L[i1], other.L[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *And) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "predicate.And")
+	h = shared.HashUint64(h, uint64(len(r.L)))
+	for _, v1 := range r.L {
+		h = shared.HashUint64(h, PredicateHash(v1))
+	}
+	return h
+}
+
+func (r *And) DeepCopy() *And {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.L != nil {
+		result.L = make([]Predicate, len(r.L))
+		for i1 := range r.L {
+			result.L[i1] = PredicateDeepCopy(r.L[i1])
+		}
+	}
+	return &result
+}
+
+func (r *Or) Equal(other *Or) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.L) != len(other.L) {
+		return false
+	}
+	for i1 := range r.L {
+		if !PredicateEqual(r.L[i1], other.L[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Or) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "predicate.Or")
+	h = shared.HashUint64(h, uint64(len(r.L)))
+	for _, v1 := range r.L {
+		h = shared.HashUint64(h, PredicateHash(v1))
+	}
+	return h
+}
+
+func (r *Or) DeepCopy() *Or {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.L != nil {
+		result.L = make([]Predicate, len(r.L))
+		for i1 := range r.L {
+			result.L[i1] = PredicateDeepCopy(r.L[i1])
+		}
+	}
+	return &result
+}
+
+func (r *Not) Equal(other *Not) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !PredicateEqual(r.P, other.P) {
+		return false
+	}
+	return true
+}
+
+func (r *Not) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "predicate.Not")
+	h = shared.HashUint64(h, PredicateHash(r.P))
+	return h
+}
+
+func (r *Not) DeepCopy() *Not {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.P = PredicateDeepCopy(r.P)
+	return &result
+}
+
+func (r *Compare) Equal(other *Compare) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Location != other.Location {
+		return false
+	}
+	if r.Operation != other.Operation {
+		return false
+	}
+	if !BindableEqual(r.BindValue, other.BindValue) {
+		return false
+	}
+	return true
+}
+
+func (r *Compare) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "predicate.Compare")
+	h = shared.HashString(h, r.Location)
+	h = shared.HashString(h, r.Operation)
+	h = shared.HashUint64(h, BindableHash(r.BindValue))
+	return h
+}
+
+func (r *Compare) DeepCopy() *Compare {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.BindValue = BindableDeepCopy(r.BindValue)
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/predicate.And", AndFromJSON, AndToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/predicate.Compare", CompareFromJSON, CompareToJSON)
@@ -544,7 +923,7 @@ func PredicateFromJSON(x []byte) (Predicate, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data PredicateUnionJSON
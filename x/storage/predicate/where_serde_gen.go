@@ -123,3 +123,40 @@ func (r *WherePredicates) _unmarshalJSONshape_Shape(data []byte) (shape.Shape, e
 	}
 	return result, nil
 }
+func (r *WherePredicates) Equal(other *WherePredicates) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !PredicateEqual(r.Predicate, other.Predicate) {
+		return false
+	}
+	if !r.Params.Equal(&other.Params) {
+		return false
+	}
+	if !shape.ShapeEqual(r.Shape, other.Shape) {
+		return false
+	}
+	return true
+}
+
+func (r *WherePredicates) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "predicate.WherePredicates")
+	h = shared.HashUint64(h, PredicateHash(r.Predicate))
+	h = shared.HashUint64(h, r.Params.Hash())
+	h = shared.HashUint64(h, shape.ShapeHash(r.Shape))
+	return h
+}
+
+func (r *WherePredicates) DeepCopy() *WherePredicates {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Predicate = PredicateDeepCopy(r.Predicate)
+	result.Params = *r.Params.DeepCopy()
+	result.Shape = shape.ShapeDeepCopy(r.Shape)
+	return &result
+}
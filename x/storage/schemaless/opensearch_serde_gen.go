@@ -70,6 +70,33 @@ func (r *OpenSearchSearchResult[A]) _unmarshalJSONOpenSearchSearchResultHitsLb_A
 	}
 	return result, nil
 }
+func (r *OpenSearchSearchResult[A]) Equal(other *OpenSearchSearchResult[A]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !r.Hits.Equal(&other.Hits) {
+		return false
+	}
+	return true
+}
+
+func (r *OpenSearchSearchResult[A]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schemaless.OpenSearchSearchResult")
+	h = shared.HashUint64(h, r.Hits.Hash())
+	return h
+}
+
+func (r *OpenSearchSearchResult[A]) DeepCopy() *OpenSearchSearchResult[A] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Hits = *r.Hits.DeepCopy()
+	return &result
+}
 
 var (
 	_ json.Unmarshaler = (*OpenSearchSearchResultHit[any])(nil)
@@ -192,6 +219,49 @@ func (r *OpenSearchSearchResultHit[A]) _unmarshalJSONstring(data []byte) (string
 	}
 	return result, nil
 }
+func (r *OpenSearchSearchResultHit[A]) Equal(other *OpenSearchSearchResultHit[A]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Item, other.Item) {
+		return false
+	}
+	if len(r.Sort) != len(other.Sort) {
+		return false
+	}
+	for i1 := range r.Sort {
+		if r.Sort[i1] != other.Sort[i1] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *OpenSearchSearchResultHit[A]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schemaless.OpenSearchSearchResultHit")
+	h = shared.Hash(h, r.Item)
+	h = shared.HashUint64(h, uint64(len(r.Sort)))
+	for _, v1 := range r.Sort {
+		h = shared.HashString(h, v1)
+	}
+	return h
+}
+
+func (r *OpenSearchSearchResultHit[A]) DeepCopy() *OpenSearchSearchResultHit[A] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Item = shared.DeepCopy(r.Item)
+	if r.Sort != nil {
+		result.Sort = make([]string, len(r.Sort))
+		copy(result.Sort, r.Sort)
+	}
+	return &result
+}
 
 var (
 	_ json.Unmarshaler = (*OpenSearchSearchResultHits[any])(nil)
@@ -287,3 +357,43 @@ func (r *OpenSearchSearchResultHits[A]) _unmarshalJSONOpenSearchSearchResultHitL
 	}
 	return result, nil
 }
+func (r *OpenSearchSearchResultHits[A]) Equal(other *OpenSearchSearchResultHits[A]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.Hits) != len(other.Hits) {
+		return false
+	}
+	for i1 := range r.Hits {
+		if !r.Hits[i1].Equal(&other.Hits[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *OpenSearchSearchResultHits[A]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schemaless.OpenSearchSearchResultHits")
+	h = shared.HashUint64(h, uint64(len(r.Hits)))
+	for _, v1 := range r.Hits {
+		h = shared.HashUint64(h, v1.Hash())
+	}
+	return h
+}
+
+func (r *OpenSearchSearchResultHits[A]) DeepCopy() *OpenSearchSearchResultHits[A] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Hits != nil {
+		result.Hits = make([]OpenSearchSearchResultHit[A], len(r.Hits))
+		for i1 := range r.Hits {
+			result.Hits[i1] = *r.Hits[i1].DeepCopy()
+		}
+	}
+	return &result
+}
@@ -4,6 +4,7 @@ package projection
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/widmogrod/mkunion/x/shared"
 )
 
 var (
@@ -152,6 +153,56 @@ func (r *Game) _unmarshalJSONbool(data []byte) (bool, error) {
 	}
 	return result, nil
 }
+func (r *Game) Equal(other *Game) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.SessionID != other.SessionID {
+		return false
+	}
+	if len(r.Players) != len(other.Players) {
+		return false
+	}
+	for i1 := range r.Players {
+		if r.Players[i1] != other.Players[i1] {
+			return false
+		}
+	}
+	if r.Winner != other.Winner {
+		return false
+	}
+	if r.IsDraw != other.IsDraw {
+		return false
+	}
+	return true
+}
+
+func (r *Game) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.Game")
+	h = shared.HashString(h, r.SessionID)
+	h = shared.HashUint64(h, uint64(len(r.Players)))
+	for _, v1 := range r.Players {
+		h = shared.HashString(h, v1)
+	}
+	h = shared.HashString(h, r.Winner)
+	h = shared.HashBool(h, r.IsDraw)
+	return h
+}
+
+func (r *Game) DeepCopy() *Game {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Players != nil {
+		result.Players = make([]string, len(r.Players))
+		copy(result.Players, r.Players)
+	}
+	return &result
+}
 
 var (
 	_ json.Unmarshaler = (*SessionsStats)(nil)
@@ -241,3 +292,37 @@ func (r *SessionsStats) _unmarshalJSONint(data []byte) (int, error) {
 	}
 	return result, nil
 }
+func (r *SessionsStats) Equal(other *SessionsStats) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Wins != other.Wins {
+		return false
+	}
+	if r.Draws != other.Draws {
+		return false
+	}
+	if r.Loose != other.Loose {
+		return false
+	}
+	return true
+}
+
+func (r *SessionsStats) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.SessionsStats")
+	h = shared.HashUint64(h, uint64(r.Wins))
+	h = shared.HashUint64(h, uint64(r.Draws))
+	h = shared.HashUint64(h, uint64(r.Loose))
+	return h
+}
+
+func (r *SessionsStats) DeepCopy() *SessionsStats {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
@@ -197,6 +197,62 @@ func (r *Item) _unmarshalJSONItemType(data []byte) (ItemType, error) {
 	}
 	return result, nil
 }
+func (r *Item) Equal(other *Item) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Key != other.Key {
+		return false
+	}
+	if !schema.SchemaEqual(r.Data, other.Data) {
+		return false
+	}
+	if r.EventTime != other.EventTime {
+		return false
+	}
+	if (r.Window == nil) != (other.Window == nil) {
+		return false
+	}
+	if r.Window != nil {
+		if !shared.Equal(*r.Window, *other.Window) {
+			return false
+		}
+	}
+	if r.Type != other.Type {
+		return false
+	}
+	return true
+}
+
+func (r *Item) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.Item")
+	h = shared.HashString(h, r.Key)
+	h = shared.HashUint64(h, schema.SchemaHash(r.Data))
+	h = shared.HashUint64(h, uint64(r.EventTime))
+	h = shared.HashBool(h, r.Window != nil)
+	if r.Window != nil {
+		h = shared.Hash(h, *r.Window)
+	}
+	h = shared.HashUint64(h, uint64(r.Type))
+	return h
+}
+
+func (r *Item) DeepCopy() *Item {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Data = schema.SchemaDeepCopy(r.Data)
+	if r.Window != nil {
+		r1 := *r.Window
+		r1 = shared.DeepCopy(*r.Window)
+		result.Window = &r1
+	}
+	return &result
+}
 
 var (
 	_ json.Unmarshaler = (*ItemGroupedByKey)(nil)
@@ -319,6 +375,50 @@ func (r *ItemGroupedByKey) _unmarshalJSONItem(data []byte) (Item, error) {
 	}
 	return result, nil
 }
+func (r *ItemGroupedByKey) Equal(other *ItemGroupedByKey) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Key != other.Key {
+		return false
+	}
+	if len(r.Data) != len(other.Data) {
+		return false
+	}
+	for i1 := range r.Data {
+		if !r.Data[i1].Equal(&other.Data[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *ItemGroupedByKey) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.ItemGroupedByKey")
+	h = shared.HashString(h, r.Key)
+	h = shared.HashUint64(h, uint64(len(r.Data)))
+	for _, v1 := range r.Data {
+		h = shared.HashUint64(h, v1.Hash())
+	}
+	return h
+}
+
+func (r *ItemGroupedByKey) DeepCopy() *ItemGroupedByKey {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Data != nil {
+		result.Data = make([]Item, len(r.Data))
+		for i1 := range r.Data {
+			result.Data[i1] = *r.Data[i1].DeepCopy()
+		}
+	}
+	return &result
+}
 
 var (
 	_ json.Unmarshaler = (*ItemGroupedByWindow)(nil)
@@ -478,3 +578,51 @@ func (r *ItemGroupedByWindow) _unmarshalJSONWindow(data []byte) (Window, error)
 	}
 	return result, nil
 }
+func (r *ItemGroupedByWindow) Equal(other *ItemGroupedByWindow) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Key != other.Key {
+		return false
+	}
+	if !r.Data.Equal(other.Data) {
+		return false
+	}
+	if (r.Window == nil) != (other.Window == nil) {
+		return false
+	}
+	if r.Window != nil {
+		if !shared.Equal(*r.Window, *other.Window) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *ItemGroupedByWindow) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.ItemGroupedByWindow")
+	h = shared.HashString(h, r.Key)
+	h = shared.HashUint64(h, r.Data.Hash())
+	h = shared.HashBool(h, r.Window != nil)
+	if r.Window != nil {
+		h = shared.Hash(h, *r.Window)
+	}
+	return h
+}
+
+func (r *ItemGroupedByWindow) DeepCopy() *ItemGroupedByWindow {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Data = r.Data.DeepCopy()
+	if r.Window != nil {
+		r1 := *r.Window
+		r1 = shared.DeepCopy(*r.Window)
+		result.Window = &r1
+	}
+	return &result
+}
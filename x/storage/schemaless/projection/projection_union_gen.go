@@ -114,6 +114,304 @@ func MatchNodeR0(
 		f4(v)
 	}
 }
+
+// RewriteNodeTopDown applies f to x, and then to each Node nested in result of f (pre-order).
+// When f returns nil, nested values are not visited.
+func RewriteNodeTopDown(x Node, f func(Node) Node) Node {
+	if x == nil {
+		return nil
+	}
+	return rewriteNodeChildren(f(x), func(y Node) Node {
+		return RewriteNodeTopDown(y, f)
+	})
+}
+
+// RewriteNodeBottomUp applies f to each Node nested in x, and then to x with already rewritten values (post-order).
+func RewriteNodeBottomUp(x Node, f func(Node) Node) Node {
+	if x == nil {
+		return nil
+	}
+	return f(rewriteNodeChildren(x, func(y Node) Node {
+		return RewriteNodeBottomUp(y, f)
+	}))
+}
+
+// rewriteNodeChildren returns shallow copy of x, where each Node nested in x is replaced by result of f.
+// Variants without nested Node are returned as they are, and x is never modified.
+func rewriteNodeChildren(x Node, f func(Node) Node) Node {
+	switch v := x.(type) {
+	case *DoWindow:
+		result := *v
+		result.Input = f(v.Input)
+		return &result
+	case *DoMap:
+		result := *v
+		result.Input = f(v.Input)
+		return &result
+	case *DoJoin:
+		result := *v
+		if v.Input != nil {
+			result.Input = make([]Node, len(v.Input))
+			for i1 := range v.Input {
+				result.Input[i1] = f(v.Input[i1])
+			}
+		}
+		return &result
+	}
+
+	return x
+}
+
+// NodeEqual returns true, when a and b are the same variant of Node with equal fields.
+func NodeEqual(a, b Node) bool {
+	switch x := a.(type) {
+	case *DoWindow:
+		y, ok := b.(*DoWindow)
+		return ok && x.Equal(y)
+	case *DoMap:
+		y, ok := b.(*DoMap)
+		return ok && x.Equal(y)
+	case *DoLoad:
+		y, ok := b.(*DoLoad)
+		return ok && x.Equal(y)
+	case *DoJoin:
+		y, ok := b.(*DoJoin)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// NodeHash returns hash of x, that is the same for values equal according to NodeEqual.
+func NodeHash(x Node) uint64 {
+	switch y := x.(type) {
+	case *DoWindow:
+		return y.Hash()
+	case *DoMap:
+		return y.Hash()
+	case *DoLoad:
+		return y.Hash()
+	case *DoJoin:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// NodeDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func NodeDeepCopy(x Node) Node {
+	switch y := x.(type) {
+	case *DoWindow:
+		return y.DeepCopy()
+	case *DoMap:
+		return y.DeepCopy()
+	case *DoLoad:
+		return y.DeepCopy()
+	case *DoJoin:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *DoWindow) Equal(other *DoWindow) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if (r.Ctx == nil) != (other.Ctx == nil) {
+		return false
+	}
+	if r.Ctx != nil {
+		if !shared.Equal(*r.Ctx, *other.Ctx) {
+			return false
+		}
+	}
+	if !NodeEqual(r.Input, other.Input) {
+		return false
+	}
+	return true
+}
+
+func (r *DoWindow) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.DoWindow")
+	h = shared.HashBool(h, r.Ctx != nil)
+	if r.Ctx != nil {
+		h = shared.Hash(h, *r.Ctx)
+	}
+	h = shared.HashUint64(h, NodeHash(r.Input))
+	return h
+}
+
+func (r *DoWindow) DeepCopy() *DoWindow {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Ctx != nil {
+		r1 := *r.Ctx
+		r1 = shared.DeepCopy(*r.Ctx)
+		result.Ctx = &r1
+	}
+	result.Input = NodeDeepCopy(r.Input)
+	return &result
+}
+
+func (r *DoMap) Equal(other *DoMap) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if (r.Ctx == nil) != (other.Ctx == nil) {
+		return false
+	}
+	if r.Ctx != nil {
+		if !shared.Equal(*r.Ctx, *other.Ctx) {
+			return false
+		}
+	}
+	if !shared.Equal(r.OnMap, other.OnMap) {
+		return false
+	}
+	if !NodeEqual(r.Input, other.Input) {
+		return false
+	}
+	return true
+}
+
+func (r *DoMap) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.DoMap")
+	h = shared.HashBool(h, r.Ctx != nil)
+	if r.Ctx != nil {
+		h = shared.Hash(h, *r.Ctx)
+	}
+	h = shared.Hash(h, r.OnMap)
+	h = shared.HashUint64(h, NodeHash(r.Input))
+	return h
+}
+
+func (r *DoMap) DeepCopy() *DoMap {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Ctx != nil {
+		r1 := *r.Ctx
+		r1 = shared.DeepCopy(*r.Ctx)
+		result.Ctx = &r1
+	}
+	result.OnMap = shared.DeepCopy(r.OnMap)
+	result.Input = NodeDeepCopy(r.Input)
+	return &result
+}
+
+func (r *DoLoad) Equal(other *DoLoad) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if (r.Ctx == nil) != (other.Ctx == nil) {
+		return false
+	}
+	if r.Ctx != nil {
+		if !shared.Equal(*r.Ctx, *other.Ctx) {
+			return false
+		}
+	}
+	if !shared.Equal(r.OnLoad, other.OnLoad) {
+		return false
+	}
+	return true
+}
+
+func (r *DoLoad) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.DoLoad")
+	h = shared.HashBool(h, r.Ctx != nil)
+	if r.Ctx != nil {
+		h = shared.Hash(h, *r.Ctx)
+	}
+	h = shared.Hash(h, r.OnLoad)
+	return h
+}
+
+func (r *DoLoad) DeepCopy() *DoLoad {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Ctx != nil {
+		r1 := *r.Ctx
+		r1 = shared.DeepCopy(*r.Ctx)
+		result.Ctx = &r1
+	}
+	result.OnLoad = shared.DeepCopy(r.OnLoad)
+	return &result
+}
+
+func (r *DoJoin) Equal(other *DoJoin) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if (r.Ctx == nil) != (other.Ctx == nil) {
+		return false
+	}
+	if r.Ctx != nil {
+		if !shared.Equal(*r.Ctx, *other.Ctx) {
+			return false
+		}
+	}
+	if len(r.Input) != len(other.Input) {
+		return false
+	}
+	for i1 := range r.Input {
+		if !NodeEqual(r.Input[i1], other.Input[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *DoJoin) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.DoJoin")
+	h = shared.HashBool(h, r.Ctx != nil)
+	if r.Ctx != nil {
+		h = shared.Hash(h, *r.Ctx)
+	}
+	h = shared.HashUint64(h, uint64(len(r.Input)))
+	for _, v1 := range r.Input {
+		h = shared.HashUint64(h, NodeHash(v1))
+	}
+	return h
+}
+
+func (r *DoJoin) DeepCopy() *DoJoin {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Ctx != nil {
+		r1 := *r.Ctx
+		r1 = shared.DeepCopy(*r.Ctx)
+		result.Ctx = &r1
+	}
+	if r.Input != nil {
+		result.Input = make([]Node, len(r.Input))
+		for i2 := range r.Input {
+			result.Input[i2] = NodeDeepCopy(r.Input[i2])
+		}
+	}
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/schemaless/projection.DoJoin", DoJoinFromJSON, DoJoinToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/schemaless/projection.DoLoad", DoLoadFromJSON, DoLoadToJSON)
@@ -134,7 +432,7 @@ func NodeFromJSON(x []byte) (Node, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data NodeUnionJSON
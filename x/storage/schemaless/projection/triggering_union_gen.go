@@ -136,6 +136,278 @@ func MatchTriggerDescriptionR0(
 		f5(v)
 	}
 }
+
+// RewriteTriggerDescriptionTopDown applies f to x, and then to each TriggerDescription nested in result of f (pre-order).
+// When f returns nil, nested values are not visited.
+func RewriteTriggerDescriptionTopDown(x TriggerDescription, f func(TriggerDescription) TriggerDescription) TriggerDescription {
+	if x == nil {
+		return nil
+	}
+	return rewriteTriggerDescriptionChildren(f(x), func(y TriggerDescription) TriggerDescription {
+		return RewriteTriggerDescriptionTopDown(y, f)
+	})
+}
+
+// RewriteTriggerDescriptionBottomUp applies f to each TriggerDescription nested in x, and then to x with already rewritten values (post-order).
+func RewriteTriggerDescriptionBottomUp(x TriggerDescription, f func(TriggerDescription) TriggerDescription) TriggerDescription {
+	if x == nil {
+		return nil
+	}
+	return f(rewriteTriggerDescriptionChildren(x, func(y TriggerDescription) TriggerDescription {
+		return RewriteTriggerDescriptionBottomUp(y, f)
+	}))
+}
+
+// rewriteTriggerDescriptionChildren returns shallow copy of x, where each TriggerDescription nested in x is replaced by result of f.
+// Variants without nested TriggerDescription are returned as they are, and x is never modified.
+func rewriteTriggerDescriptionChildren(x TriggerDescription, f func(TriggerDescription) TriggerDescription) TriggerDescription {
+	switch v := x.(type) {
+	case *AnyOf:
+		result := *v
+		if v.Triggers != nil {
+			result.Triggers = make([]TriggerDescription, len(v.Triggers))
+			for i1 := range v.Triggers {
+				result.Triggers[i1] = f(v.Triggers[i1])
+			}
+		}
+		return &result
+	case *AllOf:
+		result := *v
+		if v.Triggers != nil {
+			result.Triggers = make([]TriggerDescription, len(v.Triggers))
+			for i1 := range v.Triggers {
+				result.Triggers[i1] = f(v.Triggers[i1])
+			}
+		}
+		return &result
+	}
+
+	return x
+}
+
+// TriggerDescriptionEqual returns true, when a and b are the same variant of TriggerDescription with equal fields.
+func TriggerDescriptionEqual(a, b TriggerDescription) bool {
+	switch x := a.(type) {
+	case *AtPeriod:
+		y, ok := b.(*AtPeriod)
+		return ok && x.Equal(y)
+	case *AtWindowItemSize:
+		y, ok := b.(*AtWindowItemSize)
+		return ok && x.Equal(y)
+	case *AtWatermark:
+		y, ok := b.(*AtWatermark)
+		return ok && x.Equal(y)
+	case *AnyOf:
+		y, ok := b.(*AnyOf)
+		return ok && x.Equal(y)
+	case *AllOf:
+		y, ok := b.(*AllOf)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// TriggerDescriptionHash returns hash of x, that is the same for values equal according to TriggerDescriptionEqual.
+func TriggerDescriptionHash(x TriggerDescription) uint64 {
+	switch y := x.(type) {
+	case *AtPeriod:
+		return y.Hash()
+	case *AtWindowItemSize:
+		return y.Hash()
+	case *AtWatermark:
+		return y.Hash()
+	case *AnyOf:
+		return y.Hash()
+	case *AllOf:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// TriggerDescriptionDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func TriggerDescriptionDeepCopy(x TriggerDescription) TriggerDescription {
+	switch y := x.(type) {
+	case *AtPeriod:
+		return y.DeepCopy()
+	case *AtWindowItemSize:
+		return y.DeepCopy()
+	case *AtWatermark:
+		return y.DeepCopy()
+	case *AnyOf:
+		return y.DeepCopy()
+	case *AllOf:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *AtPeriod) Equal(other *AtPeriod) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.Duration, other.Duration) {
+		return false
+	}
+	return true
+}
+
+func (r *AtPeriod) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.AtPeriod")
+	h = shared.Hash(h, r.Duration)
+	return h
+}
+
+func (r *AtPeriod) DeepCopy() *AtPeriod {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Duration = shared.DeepCopy(r.Duration)
+	return &result
+}
+
+func (r *AtWindowItemSize) Equal(other *AtWindowItemSize) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Number != other.Number {
+		return false
+	}
+	return true
+}
+
+func (r *AtWindowItemSize) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.AtWindowItemSize")
+	h = shared.HashUint64(h, uint64(r.Number))
+	return h
+}
+
+func (r *AtWindowItemSize) DeepCopy() *AtWindowItemSize {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *AtWatermark) Equal(other *AtWatermark) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Timestamp != other.Timestamp {
+		return false
+	}
+	return true
+}
+
+func (r *AtWatermark) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.AtWatermark")
+	h = shared.HashUint64(h, uint64(r.Timestamp))
+	return h
+}
+
+func (r *AtWatermark) DeepCopy() *AtWatermark {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *AnyOf) Equal(other *AnyOf) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.Triggers) != len(other.Triggers) {
+		return false
+	}
+	for i1 := range r.Triggers {
+		if !TriggerDescriptionEqual(r.Triggers[i1], other.Triggers[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *AnyOf) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.AnyOf")
+	h = shared.HashUint64(h, uint64(len(r.Triggers)))
+	for _, v1 := range r.Triggers {
+		h = shared.HashUint64(h, TriggerDescriptionHash(v1))
+	}
+	return h
+}
+
+func (r *AnyOf) DeepCopy() *AnyOf {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Triggers != nil {
+		result.Triggers = make([]TriggerDescription, len(r.Triggers))
+		for i1 := range r.Triggers {
+			result.Triggers[i1] = TriggerDescriptionDeepCopy(r.Triggers[i1])
+		}
+	}
+	return &result
+}
+
+func (r *AllOf) Equal(other *AllOf) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.Triggers) != len(other.Triggers) {
+		return false
+	}
+	for i1 := range r.Triggers {
+		if !TriggerDescriptionEqual(r.Triggers[i1], other.Triggers[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *AllOf) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.AllOf")
+	h = shared.HashUint64(h, uint64(len(r.Triggers)))
+	for _, v1 := range r.Triggers {
+		h = shared.HashUint64(h, TriggerDescriptionHash(v1))
+	}
+	return h
+}
+
+func (r *AllOf) DeepCopy() *AllOf {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Triggers != nil {
+		result.Triggers = make([]TriggerDescription, len(r.Triggers))
+		for i1 := range r.Triggers {
+			result.Triggers[i1] = TriggerDescriptionDeepCopy(r.Triggers[i1])
+		}
+	}
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/schemaless/projection.AllOf", AllOfFromJSON, AllOfToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/schemaless/projection.AnyOf", AnyOfFromJSON, AnyOfToJSON)
@@ -158,7 +430,7 @@ func TriggerDescriptionFromJSON(x []byte) (TriggerDescription, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data TriggerDescriptionUnionJSON
@@ -293,7 +565,11 @@ func (r *AtPeriod) _marshalJSONAtPeriod(x AtPeriod) ([]byte, error) {
 	return result, nil
 }
 func (r *AtPeriod) _marshalJSONtime_Duration(x time.Duration) ([]byte, error) {
-	result, err := shared.JSONMarshal[time.Duration](x)
+	wire, err := shared.DurationToWire(x)
+	if err != nil {
+		return nil, fmt.Errorf("projection: AtPeriod._marshalJSONtime_Duration: to wire; %w", err)
+	}
+	result, err := json.Marshal(wire)
 	if err != nil {
 		return nil, fmt.Errorf("projection: AtPeriod._marshalJSONtime_Duration:; %w", err)
 	}
@@ -323,9 +599,18 @@ func (r *AtPeriod) _unmarshalJSONAtPeriod(data []byte) (AtPeriod, error) {
 	return result, nil
 }
 func (r *AtPeriod) _unmarshalJSONtime_Duration(data []byte) (time.Duration, error) {
-	result, err := shared.JSONUnmarshal[time.Duration](data)
+	var result time.Duration
+	if string(data) == "null" {
+		return result, nil
+	}
+	var wire int64
+	err := json.Unmarshal(data, &wire)
 	if err != nil {
-		return result, fmt.Errorf("projection: AtPeriod._unmarshalJSONtime_Duration: native ref unwrap; %w", err)
+		return result, fmt.Errorf("projection: AtPeriod._unmarshalJSONtime_Duration: wire unwrap; %w", err)
+	}
+	result, err = shared.DurationFromWire(wire)
+	if err != nil {
+		return result, fmt.Errorf("projection: AtPeriod._unmarshalJSONtime_Duration: from wire; %w", err)
 	}
 	return result, nil
 }
@@ -796,6 +1081,52 @@ func MatchTriggerTypeR0(
 		f3(v)
 	}
 }
+
+// TriggerTypeEqual returns true, when a and b are the same variant of TriggerType with equal fields.
+func TriggerTypeEqual(a, b TriggerType) bool {
+	switch x := a.(type) {
+	case *AtPeriod1:
+		y, ok := b.(*AtPeriod1)
+		return ok && x.Equal(y)
+	case *AtWindowItemSize1:
+		y, ok := b.(*AtWindowItemSize1)
+		return ok && x.Equal(y)
+	case *AtWatermark1:
+		y, ok := b.(*AtWatermark1)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// TriggerTypeHash returns hash of x, that is the same for values equal according to TriggerTypeEqual.
+func TriggerTypeHash(x TriggerType) uint64 {
+	switch y := x.(type) {
+	case *AtPeriod1:
+		return y.Hash()
+	case *AtWindowItemSize1:
+		return y.Hash()
+	case *AtWatermark1:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// TriggerTypeDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func TriggerTypeDeepCopy(x TriggerType) TriggerType {
+	switch y := x.(type) {
+	case *AtPeriod1:
+		return y.DeepCopy()
+	case *AtWindowItemSize1:
+		return y.DeepCopy()
+	case *AtWatermark1:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/schemaless/projection.AtPeriod1", AtPeriod1FromJSON, AtPeriod1ToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/schemaless/projection.AtWatermark1", AtWatermark1FromJSON, AtWatermark1ToJSON)
@@ -814,7 +1145,7 @@ func TriggerTypeFromJSON(x []byte) (TriggerType, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data TriggerTypeUnionJSON
@@ -1029,6 +1360,131 @@ func MatchWindowFlushModeR0(
 		f3(v)
 	}
 }
+
+// WindowFlushModeEqual returns true, when a and b are the same variant of WindowFlushMode with equal fields.
+func WindowFlushModeEqual(a, b WindowFlushMode) bool {
+	switch x := a.(type) {
+	case *Accumulate:
+		y, ok := b.(*Accumulate)
+		return ok && x.Equal(y)
+	case *Discard:
+		y, ok := b.(*Discard)
+		return ok && x.Equal(y)
+	case *AccumulatingAndRetracting:
+		y, ok := b.(*AccumulatingAndRetracting)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// WindowFlushModeHash returns hash of x, that is the same for values equal according to WindowFlushModeEqual.
+func WindowFlushModeHash(x WindowFlushMode) uint64 {
+	switch y := x.(type) {
+	case *Accumulate:
+		return y.Hash()
+	case *Discard:
+		return y.Hash()
+	case *AccumulatingAndRetracting:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// WindowFlushModeDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func WindowFlushModeDeepCopy(x WindowFlushMode) WindowFlushMode {
+	switch y := x.(type) {
+	case *Accumulate:
+		return y.DeepCopy()
+	case *Discard:
+		return y.DeepCopy()
+	case *AccumulatingAndRetracting:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Accumulate) Equal(other *Accumulate) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.AllowLateArrival, other.AllowLateArrival) {
+		return false
+	}
+	return true
+}
+
+func (r *Accumulate) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.Accumulate")
+	h = shared.Hash(h, r.AllowLateArrival)
+	return h
+}
+
+func (r *Accumulate) DeepCopy() *Accumulate {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.AllowLateArrival = shared.DeepCopy(r.AllowLateArrival)
+	return &result
+}
+
+func (r *Discard) Equal(other *Discard) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return true
+}
+
+func (r *Discard) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.Discard")
+	return h
+}
+
+func (r *Discard) DeepCopy() *Discard {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *AccumulatingAndRetracting) Equal(other *AccumulatingAndRetracting) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !shared.Equal(r.AllowLateArrival, other.AllowLateArrival) {
+		return false
+	}
+	return true
+}
+
+func (r *AccumulatingAndRetracting) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "projection.AccumulatingAndRetracting")
+	h = shared.Hash(h, r.AllowLateArrival)
+	return h
+}
+
+func (r *AccumulatingAndRetracting) DeepCopy() *AccumulatingAndRetracting {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.AllowLateArrival = shared.DeepCopy(r.AllowLateArrival)
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/schemaless/projection.Accumulate", AccumulateFromJSON, AccumulateToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/storage/schemaless/projection.AccumulatingAndRetracting", AccumulatingAndRetractingFromJSON, AccumulatingAndRetractingToJSON)
@@ -1047,7 +1503,7 @@ func WindowFlushModeFromJSON(x []byte) (WindowFlushMode, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data WindowFlushModeUnionJSON
@@ -1154,7 +1610,11 @@ func (r *Accumulate) _marshalJSONAccumulate(x Accumulate) ([]byte, error) {
 	return result, nil
 }
 func (r *Accumulate) _marshalJSONtime_Duration(x time.Duration) ([]byte, error) {
-	result, err := shared.JSONMarshal[time.Duration](x)
+	wire, err := shared.DurationToWire(x)
+	if err != nil {
+		return nil, fmt.Errorf("projection: Accumulate._marshalJSONtime_Duration: to wire; %w", err)
+	}
+	result, err := json.Marshal(wire)
 	if err != nil {
 		return nil, fmt.Errorf("projection: Accumulate._marshalJSONtime_Duration:; %w", err)
 	}
@@ -1184,9 +1644,18 @@ func (r *Accumulate) _unmarshalJSONAccumulate(data []byte) (Accumulate, error) {
 	return result, nil
 }
 func (r *Accumulate) _unmarshalJSONtime_Duration(data []byte) (time.Duration, error) {
-	result, err := shared.JSONUnmarshal[time.Duration](data)
+	var result time.Duration
+	if string(data) == "null" {
+		return result, nil
+	}
+	var wire int64
+	err := json.Unmarshal(data, &wire)
 	if err != nil {
-		return result, fmt.Errorf("projection: Accumulate._unmarshalJSONtime_Duration: native ref unwrap; %w", err)
+		return result, fmt.Errorf("projection: Accumulate._unmarshalJSONtime_Duration: wire unwrap; %w", err)
+	}
+	result, err = shared.DurationFromWire(wire)
+	if err != nil {
+		return result, fmt.Errorf("projection: Accumulate._unmarshalJSONtime_Duration: from wire; %w", err)
 	}
 	return result, nil
 }
@@ -1282,7 +1751,11 @@ func (r *AccumulatingAndRetracting) _marshalJSONAccumulatingAndRetracting(x Accu
 	return result, nil
 }
 func (r *AccumulatingAndRetracting) _marshalJSONtime_Duration(x time.Duration) ([]byte, error) {
-	result, err := shared.JSONMarshal[time.Duration](x)
+	wire, err := shared.DurationToWire(x)
+	if err != nil {
+		return nil, fmt.Errorf("projection: AccumulatingAndRetracting._marshalJSONtime_Duration: to wire; %w", err)
+	}
+	result, err := json.Marshal(wire)
 	if err != nil {
 		return nil, fmt.Errorf("projection: AccumulatingAndRetracting._marshalJSONtime_Duration:; %w", err)
 	}
@@ -1312,9 +1785,18 @@ func (r *AccumulatingAndRetracting) _unmarshalJSONAccumulatingAndRetracting(data
 	return result, nil
 }
 func (r *AccumulatingAndRetracting) _unmarshalJSONtime_Duration(data []byte) (time.Duration, error) {
-	result, err := shared.JSONUnmarshal[time.Duration](data)
+	var result time.Duration
+	if string(data) == "null" {
+		return result, nil
+	}
+	var wire int64
+	err := json.Unmarshal(data, &wire)
+	if err != nil {
+		return result, fmt.Errorf("projection: AccumulatingAndRetracting._unmarshalJSONtime_Duration: wire unwrap; %w", err)
+	}
+	result, err = shared.DurationFromWire(wire)
 	if err != nil {
-		return result, fmt.Errorf("projection: AccumulatingAndRetracting._unmarshalJSONtime_Duration: native ref unwrap; %w", err)
+		return result, fmt.Errorf("projection: AccumulatingAndRetracting._unmarshalJSONtime_Duration: from wire; %w", err)
 	}
 	return result, nil
 }
@@ -162,6 +162,80 @@ func (r *PageResult[A]) _unmarshalJSONFindingRecordsLb_A_bL(data []byte) (Findin
 	}
 	return result, nil
 }
+func (r *PageResult[A]) Equal(other *PageResult[A]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.Items) != len(other.Items) {
+		return false
+	}
+	for i1 := range r.Items {
+		if !shared.Equal(r.Items[i1], other.Items[i1]) {
+			return false
+		}
+	}
+	if (r.Next == nil) != (other.Next == nil) {
+		return false
+	}
+	if r.Next != nil {
+		if !shared.Equal(*r.Next, *other.Next) {
+			return false
+		}
+	}
+	if (r.Prev == nil) != (other.Prev == nil) {
+		return false
+	}
+	if r.Prev != nil {
+		if !shared.Equal(*r.Prev, *other.Prev) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *PageResult[A]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schemaless.PageResult")
+	h = shared.HashUint64(h, uint64(len(r.Items)))
+	for _, v1 := range r.Items {
+		h = shared.Hash(h, v1)
+	}
+	h = shared.HashBool(h, r.Next != nil)
+	if r.Next != nil {
+		h = shared.Hash(h, *r.Next)
+	}
+	h = shared.HashBool(h, r.Prev != nil)
+	if r.Prev != nil {
+		h = shared.Hash(h, *r.Prev)
+	}
+	return h
+}
+
+func (r *PageResult[A]) DeepCopy() *PageResult[A] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Items != nil {
+		result.Items = make([]A, len(r.Items))
+		for i1 := range r.Items {
+			result.Items[i1] = shared.DeepCopy(r.Items[i1])
+		}
+	}
+	if r.Next != nil {
+		r2 := *r.Next
+		r2 = shared.DeepCopy(*r.Next)
+		result.Next = &r2
+	}
+	if r.Prev != nil {
+		r3 := *r.Prev
+		r3 = shared.DeepCopy(*r.Prev)
+		result.Prev = &r3
+	}
+	return &result
+}
 
 var (
 	_ json.Unmarshaler = (*Record[any])(nil)
@@ -292,6 +366,45 @@ func (r *Record[A]) _unmarshalJSONuint16(data []byte) (uint16, error) {
 	}
 	return result, nil
 }
+func (r *Record[A]) Equal(other *Record[A]) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.ID != other.ID {
+		return false
+	}
+	if r.Type != other.Type {
+		return false
+	}
+	if !shared.Equal(r.Data, other.Data) {
+		return false
+	}
+	if r.Version != other.Version {
+		return false
+	}
+	return true
+}
+
+func (r *Record[A]) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schemaless.Record")
+	h = shared.HashString(h, r.ID)
+	h = shared.HashString(h, r.Type)
+	h = shared.Hash(h, r.Data)
+	h = shared.HashUint64(h, uint64(r.Version))
+	return h
+}
+
+func (r *Record[A]) DeepCopy() *Record[A] {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Data = shared.DeepCopy(r.Data)
+	return &result
+}
 
 var (
 	_ json.Unmarshaler = (*SortField)(nil)
@@ -384,3 +497,33 @@ func (r *SortField) _unmarshalJSONbool(data []byte) (bool, error) {
 	}
 	return result, nil
 }
+func (r *SortField) Equal(other *SortField) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Field != other.Field {
+		return false
+	}
+	if r.Descending != other.Descending {
+		return false
+	}
+	return true
+}
+
+func (r *SortField) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schemaless.SortField")
+	h = shared.HashString(h, r.Field)
+	h = shared.HashBool(h, r.Descending)
+	return h
+}
+
+func (r *SortField) DeepCopy() *SortField {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
@@ -4,6 +4,7 @@ package schemaless
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/widmogrod/mkunion/x/shared"
 )
 
 var (
@@ -97,3 +98,33 @@ func (r *ExampleRecord) _unmarshalJSONint(data []byte) (int, error) {
 	}
 	return result, nil
 }
+func (r *ExampleRecord) Equal(other *ExampleRecord) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if r.Age != other.Age {
+		return false
+	}
+	return true
+}
+
+func (r *ExampleRecord) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "schemaless.ExampleRecord")
+	h = shared.HashString(h, r.Name)
+	h = shared.HashUint64(h, uint64(r.Age))
+	return h
+}
+
+func (r *ExampleRecord) DeepCopy() *ExampleRecord {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
@@ -4,6 +4,7 @@ package stream
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/widmogrod/mkunion/x/shared"
 )
 
 var (
@@ -52,3 +53,29 @@ func (r *Offset) _unmarshalJSONstring(data []byte) (string, error) {
 	}
 	return result, nil
 }
+func (r *Offset) Equal(other *Offset) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if *r != *other {
+		return false
+	}
+	return true
+}
+
+func (r *Offset) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "stream.Offset")
+	h = shared.HashString(h, string(*r))
+	return h
+}
+
+func (r *Offset) DeepCopy() *Offset {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
@@ -84,6 +84,104 @@ func MatchPullCMDR0(
 		f2(v)
 	}
 }
+
+// PullCMDEqual returns true, when a and b are the same variant of PullCMD with equal fields.
+func PullCMDEqual(a, b PullCMD) bool {
+	switch x := a.(type) {
+	case *FromBeginning:
+		y, ok := b.(*FromBeginning)
+		return ok && x.Equal(y)
+	case *FromOffset:
+		y, ok := b.(*FromOffset)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// PullCMDHash returns hash of x, that is the same for values equal according to PullCMDEqual.
+func PullCMDHash(x PullCMD) uint64 {
+	switch y := x.(type) {
+	case *FromBeginning:
+		return y.Hash()
+	case *FromOffset:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// PullCMDDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func PullCMDDeepCopy(x PullCMD) PullCMD {
+	switch y := x.(type) {
+	case *FromBeginning:
+		return y.DeepCopy()
+	case *FromOffset:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *FromBeginning) Equal(other *FromBeginning) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Topic != other.Topic {
+		return false
+	}
+	return true
+}
+
+func (r *FromBeginning) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "stream.FromBeginning")
+	h = shared.HashString(h, string(r.Topic))
+	return h
+}
+
+func (r *FromBeginning) DeepCopy() *FromBeginning {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *FromOffset) Equal(other *FromOffset) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Topic != other.Topic {
+		return false
+	}
+	if !r.Offset.Equal(other.Offset) {
+		return false
+	}
+	return true
+}
+
+func (r *FromOffset) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "stream.FromOffset")
+	h = shared.HashString(h, string(r.Topic))
+	h = shared.HashUint64(h, r.Offset.Hash())
+	return h
+}
+
+func (r *FromOffset) DeepCopy() *FromOffset {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Offset = r.Offset.DeepCopy()
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/stream.FromBeginning", FromBeginningFromJSON, FromBeginningToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/stream.FromOffset", FromOffsetFromJSON, FromOffsetToJSON)
@@ -100,7 +198,7 @@ func PullCMDFromJSON(x []byte) (PullCMD, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data PullCMDUnionJSON
@@ -72,6 +72,32 @@ func (r *ApplyAwaitOptions) _unmarshalJSONint64(data []byte) (int64, error) {
 	}
 	return result, nil
 }
+func (r *ApplyAwaitOptions) Equal(other *ApplyAwaitOptions) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.TimeoutSeconds != other.TimeoutSeconds {
+		return false
+	}
+	return true
+}
+
+func (r *ApplyAwaitOptions) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.ApplyAwaitOptions")
+	h = shared.HashUint64(h, uint64(r.TimeoutSeconds))
+	return h
+}
+
+func (r *ApplyAwaitOptions) DeepCopy() *ApplyAwaitOptions {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
 
 var (
 	_ json.Unmarshaler = (*BaseState)(nil)
@@ -327,6 +353,104 @@ func (r *BaseState) _unmarshalJSONRunOption(data []byte) (RunOption, error) {
 	}
 	return result, nil
 }
+func (r *BaseState) Equal(other *BaseState) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !WorkflowEqual(r.Flow, other.Flow) {
+		return false
+	}
+	if r.RunID != other.RunID {
+		return false
+	}
+	if r.StepID != other.StepID {
+		return false
+	}
+	if len(r.Variables) != len(other.Variables) {
+		return false
+	}
+	for k1, v1 := range r.Variables {
+		w1, ok := other.Variables[k1]
+		if !ok {
+			return false
+		}
+		if !schema.SchemaEqual(v1, w1) {
+			return false
+		}
+	}
+	if len(r.ExprResult) != len(other.ExprResult) {
+		return false
+	}
+	for k2, v2 := range r.ExprResult {
+		w2, ok := other.ExprResult[k2]
+		if !ok {
+			return false
+		}
+		if !schema.SchemaEqual(v2, w2) {
+			return false
+		}
+	}
+	if r.DefaultMaxRetries != other.DefaultMaxRetries {
+		return false
+	}
+	if !RunOptionEqual(r.RunOption, other.RunOption) {
+		return false
+	}
+	return true
+}
+
+func (r *BaseState) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.BaseState")
+	h = shared.HashUint64(h, WorkflowHash(r.Flow))
+	h = shared.HashString(h, string(r.RunID))
+	h = shared.HashString(h, string(r.StepID))
+	h = shared.HashUint64(h, uint64(len(r.Variables)))
+	var s1 uint64
+	for k1, v1 := range r.Variables {
+		e1 := shared.HashOffset
+		e1 = shared.HashString(e1, k1)
+		e1 = shared.HashUint64(e1, schema.SchemaHash(v1))
+		s1 += e1
+	}
+	h = shared.HashUint64(h, s1)
+	h = shared.HashUint64(h, uint64(len(r.ExprResult)))
+	var s2 uint64
+	for k2, v2 := range r.ExprResult {
+		e2 := shared.HashOffset
+		e2 = shared.HashString(e2, k2)
+		e2 = shared.HashUint64(e2, schema.SchemaHash(v2))
+		s2 += e2
+	}
+	h = shared.HashUint64(h, s2)
+	h = shared.HashUint64(h, uint64(r.DefaultMaxRetries))
+	h = shared.HashUint64(h, RunOptionHash(r.RunOption))
+	return h
+}
+
+func (r *BaseState) DeepCopy() *BaseState {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Flow = WorkflowDeepCopy(r.Flow)
+	if r.Variables != nil {
+		result.Variables = make(map[string]schema.Schema, len(r.Variables))
+		for k1, v1 := range r.Variables {
+			result.Variables[k1] = schema.SchemaDeepCopy(v1)
+		}
+	}
+	if r.ExprResult != nil {
+		result.ExprResult = make(map[string]schema.Schema, len(r.ExprResult))
+		for k2, v2 := range r.ExprResult {
+			result.ExprResult[k2] = schema.SchemaDeepCopy(v2)
+		}
+	}
+	result.RunOption = RunOptionDeepCopy(r.RunOption)
+	return &result
+}
 
 var (
 	_ json.Unmarshaler = (*ResumeOptions)(nil)
@@ -392,3 +516,29 @@ func (r *ResumeOptions) _unmarshalJSONint64(data []byte) (int64, error) {
 	}
 	return result, nil
 }
+func (r *ResumeOptions) Equal(other *ResumeOptions) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Timeout != other.Timeout {
+		return false
+	}
+	return true
+}
+
+func (r *ResumeOptions) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.ResumeOptions")
+	h = shared.HashUint64(h, uint64(r.Timeout))
+	return h
+}
+
+func (r *ResumeOptions) DeepCopy() *ResumeOptions {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
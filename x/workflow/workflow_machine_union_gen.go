@@ -145,6 +145,251 @@ func MatchCommandR0(
 		f6(v)
 	}
 }
+
+// CommandEqual returns true, when a and b are the same variant of Command with equal fields.
+func CommandEqual(a, b Command) bool {
+	switch x := a.(type) {
+	case *Run:
+		y, ok := b.(*Run)
+		return ok && x.Equal(y)
+	case *Callback:
+		y, ok := b.(*Callback)
+		return ok && x.Equal(y)
+	case *TryRecover:
+		y, ok := b.(*TryRecover)
+		return ok && x.Equal(y)
+	case *StopSchedule:
+		y, ok := b.(*StopSchedule)
+		return ok && x.Equal(y)
+	case *ResumeSchedule:
+		y, ok := b.(*ResumeSchedule)
+		return ok && x.Equal(y)
+	case *ExpireAsync:
+		y, ok := b.(*ExpireAsync)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// CommandHash returns hash of x, that is the same for values equal according to CommandEqual.
+func CommandHash(x Command) uint64 {
+	switch y := x.(type) {
+	case *Run:
+		return y.Hash()
+	case *Callback:
+		return y.Hash()
+	case *TryRecover:
+		return y.Hash()
+	case *StopSchedule:
+		return y.Hash()
+	case *ResumeSchedule:
+		return y.Hash()
+	case *ExpireAsync:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// CommandDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func CommandDeepCopy(x Command) Command {
+	switch y := x.(type) {
+	case *Run:
+		return y.DeepCopy()
+	case *Callback:
+		return y.DeepCopy()
+	case *TryRecover:
+		return y.DeepCopy()
+	case *StopSchedule:
+		return y.DeepCopy()
+	case *ResumeSchedule:
+		return y.DeepCopy()
+	case *ExpireAsync:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Run) Equal(other *Run) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !WorkflowEqual(r.Flow, other.Flow) {
+		return false
+	}
+	if !schema.SchemaEqual(r.Input, other.Input) {
+		return false
+	}
+	if !RunOptionEqual(r.RunOption, other.RunOption) {
+		return false
+	}
+	return true
+}
+
+func (r *Run) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Run")
+	h = shared.HashUint64(h, WorkflowHash(r.Flow))
+	h = shared.HashUint64(h, schema.SchemaHash(r.Input))
+	h = shared.HashUint64(h, RunOptionHash(r.RunOption))
+	return h
+}
+
+func (r *Run) DeepCopy() *Run {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Flow = WorkflowDeepCopy(r.Flow)
+	result.Input = schema.SchemaDeepCopy(r.Input)
+	result.RunOption = RunOptionDeepCopy(r.RunOption)
+	return &result
+}
+
+func (r *Callback) Equal(other *Callback) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.CallbackID != other.CallbackID {
+		return false
+	}
+	if !schema.SchemaEqual(r.Result, other.Result) {
+		return false
+	}
+	return true
+}
+
+func (r *Callback) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Callback")
+	h = shared.HashString(h, r.CallbackID)
+	h = shared.HashUint64(h, schema.SchemaHash(r.Result))
+	return h
+}
+
+func (r *Callback) DeepCopy() *Callback {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Result = schema.SchemaDeepCopy(r.Result)
+	return &result
+}
+
+func (r *TryRecover) Equal(other *TryRecover) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.RunID != other.RunID {
+		return false
+	}
+	return true
+}
+
+func (r *TryRecover) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.TryRecover")
+	h = shared.HashString(h, string(r.RunID))
+	return h
+}
+
+func (r *TryRecover) DeepCopy() *TryRecover {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *StopSchedule) Equal(other *StopSchedule) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.ParentRunID != other.ParentRunID {
+		return false
+	}
+	return true
+}
+
+func (r *StopSchedule) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.StopSchedule")
+	h = shared.HashString(h, string(r.ParentRunID))
+	return h
+}
+
+func (r *StopSchedule) DeepCopy() *StopSchedule {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *ResumeSchedule) Equal(other *ResumeSchedule) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.ParentRunID != other.ParentRunID {
+		return false
+	}
+	return true
+}
+
+func (r *ResumeSchedule) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.ResumeSchedule")
+	h = shared.HashString(h, string(r.ParentRunID))
+	return h
+}
+
+func (r *ResumeSchedule) DeepCopy() *ResumeSchedule {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *ExpireAsync) Equal(other *ExpireAsync) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.RunID != other.RunID {
+		return false
+	}
+	return true
+}
+
+func (r *ExpireAsync) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.ExpireAsync")
+	h = shared.HashString(h, string(r.RunID))
+	return h
+}
+
+func (r *ExpireAsync) DeepCopy() *ExpireAsync {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.Callback", CallbackFromJSON, CallbackToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.Command", CommandFromJSON, CommandToJSON)
@@ -169,7 +414,7 @@ func CommandFromJSON(x []byte) (Command, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data CommandUnionJSON
@@ -926,6 +1171,302 @@ func MatchExprR0(
 		f4(v)
 	}
 }
+
+// RewriteExprTopDown applies f to x, and then to each Expr nested in result of f (pre-order).
+// When f returns nil, nested values are not visited.
+func RewriteExprTopDown(x Expr, f func(Expr) Expr) Expr {
+	if x == nil {
+		return nil
+	}
+	return rewriteExprChildren(f(x), func(y Expr) Expr {
+		return RewriteExprTopDown(y, f)
+	})
+}
+
+// RewriteExprBottomUp applies f to each Expr nested in x, and then to x with already rewritten values (post-order).
+func RewriteExprBottomUp(x Expr, f func(Expr) Expr) Expr {
+	if x == nil {
+		return nil
+	}
+	return f(rewriteExprChildren(x, func(y Expr) Expr {
+		return RewriteExprBottomUp(y, f)
+	}))
+}
+
+// rewriteExprChildren returns shallow copy of x, where each Expr nested in x is replaced by result of f.
+// Variants without nested Expr are returned as they are, and x is never modified.
+func rewriteExprChildren(x Expr, f func(Expr) Expr) Expr {
+	switch v := x.(type) {
+	case *Assign:
+		result := *v
+		result.Val = f(v.Val)
+		return &result
+	case *Choose:
+		result := *v
+		if v.Then != nil {
+			result.Then = make([]Expr, len(v.Then))
+			for i1 := range v.Then {
+				result.Then[i1] = f(v.Then[i1])
+			}
+		}
+		if v.Else != nil {
+			result.Else = make([]Expr, len(v.Else))
+			for i2 := range v.Else {
+				result.Else[i2] = f(v.Else[i2])
+			}
+		}
+		return &result
+	}
+
+	return x
+}
+
+// ExprEqual returns true, when a and b are the same variant of Expr with equal fields.
+func ExprEqual(a, b Expr) bool {
+	switch x := a.(type) {
+	case *End:
+		y, ok := b.(*End)
+		return ok && x.Equal(y)
+	case *Assign:
+		y, ok := b.(*Assign)
+		return ok && x.Equal(y)
+	case *Apply:
+		y, ok := b.(*Apply)
+		return ok && x.Equal(y)
+	case *Choose:
+		y, ok := b.(*Choose)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// ExprHash returns hash of x, that is the same for values equal according to ExprEqual.
+func ExprHash(x Expr) uint64 {
+	switch y := x.(type) {
+	case *End:
+		return y.Hash()
+	case *Assign:
+		return y.Hash()
+	case *Apply:
+		return y.Hash()
+	case *Choose:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// ExprDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func ExprDeepCopy(x Expr) Expr {
+	switch y := x.(type) {
+	case *End:
+		return y.DeepCopy()
+	case *Assign:
+		return y.DeepCopy()
+	case *Apply:
+		return y.DeepCopy()
+	case *Choose:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *End) Equal(other *End) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.ID != other.ID {
+		return false
+	}
+	if !ReshaperEqual(r.Result, other.Result) {
+		return false
+	}
+	return true
+}
+
+func (r *End) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.End")
+	h = shared.HashString(h, string(r.ID))
+	h = shared.HashUint64(h, ReshaperHash(r.Result))
+	return h
+}
+
+func (r *End) DeepCopy() *End {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Result = ReshaperDeepCopy(r.Result)
+	return &result
+}
+
+func (r *Assign) Equal(other *Assign) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.ID != other.ID {
+		return false
+	}
+	if r.VarOk != other.VarOk {
+		return false
+	}
+	if r.VarErr != other.VarErr {
+		return false
+	}
+	if !ExprEqual(r.Val, other.Val) {
+		return false
+	}
+	return true
+}
+
+func (r *Assign) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Assign")
+	h = shared.HashString(h, string(r.ID))
+	h = shared.HashString(h, r.VarOk)
+	h = shared.HashString(h, r.VarErr)
+	h = shared.HashUint64(h, ExprHash(r.Val))
+	return h
+}
+
+func (r *Assign) DeepCopy() *Assign {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Val = ExprDeepCopy(r.Val)
+	return &result
+}
+
+func (r *Apply) Equal(other *Apply) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.ID != other.ID {
+		return false
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if len(r.Args) != len(other.Args) {
+		return false
+	}
+	for i1 := range r.Args {
+		if !ReshaperEqual(r.Args[i1], other.Args[i1]) {
+			return false
+		}
+	}
+	if !r.Await.Equal(other.Await) {
+		return false
+	}
+	return true
+}
+
+func (r *Apply) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Apply")
+	h = shared.HashString(h, string(r.ID))
+	h = shared.HashString(h, r.Name)
+	h = shared.HashUint64(h, uint64(len(r.Args)))
+	for _, v1 := range r.Args {
+		h = shared.HashUint64(h, ReshaperHash(v1))
+	}
+	h = shared.HashUint64(h, r.Await.Hash())
+	return h
+}
+
+func (r *Apply) DeepCopy() *Apply {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Args != nil {
+		result.Args = make([]Reshaper, len(r.Args))
+		for i1 := range r.Args {
+			result.Args[i1] = ReshaperDeepCopy(r.Args[i1])
+		}
+	}
+	result.Await = r.Await.DeepCopy()
+	return &result
+}
+
+func (r *Choose) Equal(other *Choose) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.ID != other.ID {
+		return false
+	}
+	if !PredicateEqual(r.If, other.If) {
+		return false
+	}
+	if len(r.Then) != len(other.Then) {
+		return false
+	}
+	for i1 := range r.Then {
+		if !ExprEqual(r.Then[i1], other.Then[i1]) {
+			return false
+		}
+	}
+	if len(r.Else) != len(other.Else) {
+		return false
+	}
+	for i2 := range r.Else {
+		if !ExprEqual(r.Else[i2], other.Else[i2]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Choose) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Choose")
+	h = shared.HashString(h, string(r.ID))
+	h = shared.HashUint64(h, PredicateHash(r.If))
+	h = shared.HashUint64(h, uint64(len(r.Then)))
+	for _, v1 := range r.Then {
+		h = shared.HashUint64(h, ExprHash(v1))
+	}
+	h = shared.HashUint64(h, uint64(len(r.Else)))
+	for _, v2 := range r.Else {
+		h = shared.HashUint64(h, ExprHash(v2))
+	}
+	return h
+}
+
+func (r *Choose) DeepCopy() *Choose {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.If = PredicateDeepCopy(r.If)
+	if r.Then != nil {
+		result.Then = make([]Expr, len(r.Then))
+		for i1 := range r.Then {
+			result.Then[i1] = ExprDeepCopy(r.Then[i1])
+		}
+	}
+	if r.Else != nil {
+		result.Else = make([]Expr, len(r.Else))
+		for i2 := range r.Else {
+			result.Else[i2] = ExprDeepCopy(r.Else[i2])
+		}
+	}
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.Apply", ApplyFromJSON, ApplyToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.Assign", AssignFromJSON, AssignToJSON)
@@ -946,7 +1487,7 @@ func ExprFromJSON(x []byte) (Expr, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data ExprUnionJSON
@@ -1759,6 +2300,258 @@ func MatchPredicateR0(
 		f4(v)
 	}
 }
+
+// RewritePredicateTopDown applies f to x, and then to each Predicate nested in result of f (pre-order).
+// When f returns nil, nested values are not visited.
+func RewritePredicateTopDown(x Predicate, f func(Predicate) Predicate) Predicate {
+	if x == nil {
+		return nil
+	}
+	return rewritePredicateChildren(f(x), func(y Predicate) Predicate {
+		return RewritePredicateTopDown(y, f)
+	})
+}
+
+// RewritePredicateBottomUp applies f to each Predicate nested in x, and then to x with already rewritten values (post-order).
+func RewritePredicateBottomUp(x Predicate, f func(Predicate) Predicate) Predicate {
+	if x == nil {
+		return nil
+	}
+	return f(rewritePredicateChildren(x, func(y Predicate) Predicate {
+		return RewritePredicateBottomUp(y, f)
+	}))
+}
+
+// rewritePredicateChildren returns shallow copy of x, where each Predicate nested in x is replaced by result of f.
+// Variants without nested Predicate are returned as they are, and x is never modified.
+func rewritePredicateChildren(x Predicate, f func(Predicate) Predicate) Predicate {
+	switch v := x.(type) {
+	case *And:
+		result := *v
+		if v.L != nil {
+			result.L = make([]Predicate, len(v.L))
+			for i1 := range v.L {
+				result.L[i1] = f(v.L[i1])
+			}
+		}
+		return &result
+	case *Or:
+		result := *v
+		if v.L != nil {
+			result.L = make([]Predicate, len(v.L))
+			for i1 := range v.L {
+				result.L[i1] = f(v.L[i1])
+			}
+		}
+		return &result
+	case *Not:
+		result := *v
+		result.P = f(v.P)
+		return &result
+	}
+
+	return x
+}
+
+// PredicateEqual returns true, when a and b are the same variant of Predicate with equal fields.
+func PredicateEqual(a, b Predicate) bool {
+	switch x := a.(type) {
+	case *And:
+		y, ok := b.(*And)
+		return ok && x.Equal(y)
+	case *Or:
+		y, ok := b.(*Or)
+		return ok && x.Equal(y)
+	case *Not:
+		y, ok := b.(*Not)
+		return ok && x.Equal(y)
+	case *Compare:
+		y, ok := b.(*Compare)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// PredicateHash returns hash of x, that is the same for values equal according to PredicateEqual.
+func PredicateHash(x Predicate) uint64 {
+	switch y := x.(type) {
+	case *And:
+		return y.Hash()
+	case *Or:
+		return y.Hash()
+	case *Not:
+		return y.Hash()
+	case *Compare:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// PredicateDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func PredicateDeepCopy(x Predicate) Predicate {
+	switch y := x.(type) {
+	case *And:
+		return y.DeepCopy()
+	case *Or:
+		return y.DeepCopy()
+	case *Not:
+		return y.DeepCopy()
+	case *Compare:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *And) Equal(other *And) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.L) != len(other.L) {
+		return false
+	}
+	for i1 := range r.L {
+		if !PredicateEqual(r.L[i1], other.L[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *And) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.And")
+	h = shared.HashUint64(h, uint64(len(r.L)))
+	for _, v1 := range r.L {
+		h = shared.HashUint64(h, PredicateHash(v1))
+	}
+	return h
+}
+
+func (r *And) DeepCopy() *And {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.L != nil {
+		result.L = make([]Predicate, len(r.L))
+		for i1 := range r.L {
+			result.L[i1] = PredicateDeepCopy(r.L[i1])
+		}
+	}
+	return &result
+}
+
+func (r *Or) Equal(other *Or) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.L) != len(other.L) {
+		return false
+	}
+	for i1 := range r.L {
+		if !PredicateEqual(r.L[i1], other.L[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Or) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Or")
+	h = shared.HashUint64(h, uint64(len(r.L)))
+	for _, v1 := range r.L {
+		h = shared.HashUint64(h, PredicateHash(v1))
+	}
+	return h
+}
+
+func (r *Or) DeepCopy() *Or {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.L != nil {
+		result.L = make([]Predicate, len(r.L))
+		for i1 := range r.L {
+			result.L[i1] = PredicateDeepCopy(r.L[i1])
+		}
+	}
+	return &result
+}
+
+func (r *Not) Equal(other *Not) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !PredicateEqual(r.P, other.P) {
+		return false
+	}
+	return true
+}
+
+func (r *Not) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Not")
+	h = shared.HashUint64(h, PredicateHash(r.P))
+	return h
+}
+
+func (r *Not) DeepCopy() *Not {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.P = PredicateDeepCopy(r.P)
+	return &result
+}
+
+func (r *Compare) Equal(other *Compare) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Operation != other.Operation {
+		return false
+	}
+	if !ReshaperEqual(r.Left, other.Left) {
+		return false
+	}
+	if !ReshaperEqual(r.Right, other.Right) {
+		return false
+	}
+	return true
+}
+
+func (r *Compare) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Compare")
+	h = shared.HashString(h, r.Operation)
+	h = shared.HashUint64(h, ReshaperHash(r.Left))
+	h = shared.HashUint64(h, ReshaperHash(r.Right))
+	return h
+}
+
+func (r *Compare) DeepCopy() *Compare {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Left = ReshaperDeepCopy(r.Left)
+	result.Right = ReshaperDeepCopy(r.Right)
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.And", AndFromJSON, AndToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.Compare", CompareFromJSON, CompareToJSON)
@@ -1779,7 +2572,7 @@ func PredicateFromJSON(x []byte) (Predicate, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data PredicateUnionJSON
@@ -2346,6 +3139,100 @@ func MatchReshaperR0(
 		f2(v)
 	}
 }
+
+// ReshaperEqual returns true, when a and b are the same variant of Reshaper with equal fields.
+func ReshaperEqual(a, b Reshaper) bool {
+	switch x := a.(type) {
+	case *GetValue:
+		y, ok := b.(*GetValue)
+		return ok && x.Equal(y)
+	case *SetValue:
+		y, ok := b.(*SetValue)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// ReshaperHash returns hash of x, that is the same for values equal according to ReshaperEqual.
+func ReshaperHash(x Reshaper) uint64 {
+	switch y := x.(type) {
+	case *GetValue:
+		return y.Hash()
+	case *SetValue:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// ReshaperDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func ReshaperDeepCopy(x Reshaper) Reshaper {
+	switch y := x.(type) {
+	case *GetValue:
+		return y.DeepCopy()
+	case *SetValue:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *GetValue) Equal(other *GetValue) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Path != other.Path {
+		return false
+	}
+	return true
+}
+
+func (r *GetValue) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.GetValue")
+	h = shared.HashString(h, r.Path)
+	return h
+}
+
+func (r *GetValue) DeepCopy() *GetValue {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *SetValue) Equal(other *SetValue) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !schema.SchemaEqual(r.Value, other.Value) {
+		return false
+	}
+	return true
+}
+
+func (r *SetValue) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.SetValue")
+	h = shared.HashUint64(h, schema.SchemaHash(r.Value))
+	return h
+}
+
+func (r *SetValue) DeepCopy() *SetValue {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Value = schema.SchemaDeepCopy(r.Value)
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.GetValue", GetValueFromJSON, GetValueToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.Reshaper", ReshaperFromJSON, ReshaperToJSON)
@@ -2362,7 +3249,7 @@ func ReshaperFromJSON(x []byte) (Reshaper, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data ReshaperUnionJSON
@@ -2647,6 +3534,103 @@ func MatchRunOptionR0(
 		f2(v)
 	}
 }
+
+// RunOptionEqual returns true, when a and b are the same variant of RunOption with equal fields.
+func RunOptionEqual(a, b RunOption) bool {
+	switch x := a.(type) {
+	case *ScheduleRun:
+		y, ok := b.(*ScheduleRun)
+		return ok && x.Equal(y)
+	case *DelayRun:
+		y, ok := b.(*DelayRun)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// RunOptionHash returns hash of x, that is the same for values equal according to RunOptionEqual.
+func RunOptionHash(x RunOption) uint64 {
+	switch y := x.(type) {
+	case *ScheduleRun:
+		return y.Hash()
+	case *DelayRun:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// RunOptionDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func RunOptionDeepCopy(x RunOption) RunOption {
+	switch y := x.(type) {
+	case *ScheduleRun:
+		return y.DeepCopy()
+	case *DelayRun:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *ScheduleRun) Equal(other *ScheduleRun) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Interval != other.Interval {
+		return false
+	}
+	if r.ParentRunID != other.ParentRunID {
+		return false
+	}
+	return true
+}
+
+func (r *ScheduleRun) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.ScheduleRun")
+	h = shared.HashString(h, r.Interval)
+	h = shared.HashString(h, r.ParentRunID)
+	return h
+}
+
+func (r *ScheduleRun) DeepCopy() *ScheduleRun {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+func (r *DelayRun) Equal(other *DelayRun) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.DelayBySeconds != other.DelayBySeconds {
+		return false
+	}
+	return true
+}
+
+func (r *DelayRun) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.DelayRun")
+	h = shared.HashUint64(h, uint64(r.DelayBySeconds))
+	return h
+}
+
+func (r *DelayRun) DeepCopy() *DelayRun {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.DelayRun", DelayRunFromJSON, DelayRunToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.RunOption", RunOptionFromJSON, RunOptionToJSON)
@@ -2663,7 +3647,7 @@ func RunOptionFromJSON(x []byte) (RunOption, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data RunOptionUnionJSON
@@ -3021,6 +4005,275 @@ func MatchStateR0(
 		f6(v)
 	}
 }
+
+// StateEqual returns true, when a and b are the same variant of State with equal fields.
+func StateEqual(a, b State) bool {
+	switch x := a.(type) {
+	case *NextOperation:
+		y, ok := b.(*NextOperation)
+		return ok && x.Equal(y)
+	case *Done:
+		y, ok := b.(*Done)
+		return ok && x.Equal(y)
+	case *Error:
+		y, ok := b.(*Error)
+		return ok && x.Equal(y)
+	case *Await:
+		y, ok := b.(*Await)
+		return ok && x.Equal(y)
+	case *Scheduled:
+		y, ok := b.(*Scheduled)
+		return ok && x.Equal(y)
+	case *ScheduleStopped:
+		y, ok := b.(*ScheduleStopped)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// StateHash returns hash of x, that is the same for values equal according to StateEqual.
+func StateHash(x State) uint64 {
+	switch y := x.(type) {
+	case *NextOperation:
+		return y.Hash()
+	case *Done:
+		return y.Hash()
+	case *Error:
+		return y.Hash()
+	case *Await:
+		return y.Hash()
+	case *Scheduled:
+		return y.Hash()
+	case *ScheduleStopped:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// StateDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func StateDeepCopy(x State) State {
+	switch y := x.(type) {
+	case *NextOperation:
+		return y.DeepCopy()
+	case *Done:
+		return y.DeepCopy()
+	case *Error:
+		return y.DeepCopy()
+	case *Await:
+		return y.DeepCopy()
+	case *Scheduled:
+		return y.DeepCopy()
+	case *ScheduleStopped:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *NextOperation) Equal(other *NextOperation) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !schema.SchemaEqual(r.Result, other.Result) {
+		return false
+	}
+	if !r.BaseState.Equal(&other.BaseState) {
+		return false
+	}
+	return true
+}
+
+func (r *NextOperation) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.NextOperation")
+	h = shared.HashUint64(h, schema.SchemaHash(r.Result))
+	h = shared.HashUint64(h, r.BaseState.Hash())
+	return h
+}
+
+func (r *NextOperation) DeepCopy() *NextOperation {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Result = schema.SchemaDeepCopy(r.Result)
+	result.BaseState = *r.BaseState.DeepCopy()
+	return &result
+}
+
+func (r *Done) Equal(other *Done) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !schema.SchemaEqual(r.Result, other.Result) {
+		return false
+	}
+	if !r.BaseState.Equal(&other.BaseState) {
+		return false
+	}
+	return true
+}
+
+func (r *Done) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Done")
+	h = shared.HashUint64(h, schema.SchemaHash(r.Result))
+	h = shared.HashUint64(h, r.BaseState.Hash())
+	return h
+}
+
+func (r *Done) DeepCopy() *Done {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Result = schema.SchemaDeepCopy(r.Result)
+	result.BaseState = *r.BaseState.DeepCopy()
+	return &result
+}
+
+func (r *Error) Equal(other *Error) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Code != other.Code {
+		return false
+	}
+	if r.Reason != other.Reason {
+		return false
+	}
+	if r.Retried != other.Retried {
+		return false
+	}
+	if !r.BaseState.Equal(&other.BaseState) {
+		return false
+	}
+	return true
+}
+
+func (r *Error) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Error")
+	h = shared.HashString(h, r.Code)
+	h = shared.HashString(h, r.Reason)
+	h = shared.HashUint64(h, uint64(r.Retried))
+	h = shared.HashUint64(h, r.BaseState.Hash())
+	return h
+}
+
+func (r *Error) DeepCopy() *Error {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.BaseState = *r.BaseState.DeepCopy()
+	return &result
+}
+
+func (r *Await) Equal(other *Await) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.CallbackID != other.CallbackID {
+		return false
+	}
+	if r.ExpectedTimeoutTimestamp != other.ExpectedTimeoutTimestamp {
+		return false
+	}
+	if !r.BaseState.Equal(&other.BaseState) {
+		return false
+	}
+	return true
+}
+
+func (r *Await) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Await")
+	h = shared.HashString(h, r.CallbackID)
+	h = shared.HashUint64(h, uint64(r.ExpectedTimeoutTimestamp))
+	h = shared.HashUint64(h, r.BaseState.Hash())
+	return h
+}
+
+func (r *Await) DeepCopy() *Await {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.BaseState = *r.BaseState.DeepCopy()
+	return &result
+}
+
+func (r *Scheduled) Equal(other *Scheduled) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.ExpectedRunTimestamp != other.ExpectedRunTimestamp {
+		return false
+	}
+	if !r.BaseState.Equal(&other.BaseState) {
+		return false
+	}
+	return true
+}
+
+func (r *Scheduled) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Scheduled")
+	h = shared.HashUint64(h, uint64(r.ExpectedRunTimestamp))
+	h = shared.HashUint64(h, r.BaseState.Hash())
+	return h
+}
+
+func (r *Scheduled) DeepCopy() *Scheduled {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.BaseState = *r.BaseState.DeepCopy()
+	return &result
+}
+
+func (r *ScheduleStopped) Equal(other *ScheduleStopped) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !r.BaseState.Equal(&other.BaseState) {
+		return false
+	}
+	return true
+}
+
+func (r *ScheduleStopped) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.ScheduleStopped")
+	h = shared.HashUint64(h, r.BaseState.Hash())
+	return h
+}
+
+func (r *ScheduleStopped) DeepCopy() *ScheduleStopped {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.BaseState = *r.BaseState.DeepCopy()
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.Await", AwaitFromJSON, AwaitToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.Done", DoneFromJSON, DoneToJSON)
@@ -3045,7 +4298,7 @@ func StateFromJSON(x []byte) (State, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data StateUnionJSON
@@ -3892,6 +5145,121 @@ func MatchWorkflowR0(
 		f2(v)
 	}
 }
+
+// WorkflowEqual returns true, when a and b are the same variant of Workflow with equal fields.
+func WorkflowEqual(a, b Workflow) bool {
+	switch x := a.(type) {
+	case *Flow:
+		y, ok := b.(*Flow)
+		return ok && x.Equal(y)
+	case *FlowRef:
+		y, ok := b.(*FlowRef)
+		return ok && x.Equal(y)
+	}
+
+	return a == nil && b == nil
+}
+
+// WorkflowHash returns hash of x, that is the same for values equal according to WorkflowEqual.
+func WorkflowHash(x Workflow) uint64 {
+	switch y := x.(type) {
+	case *Flow:
+		return y.Hash()
+	case *FlowRef:
+		return y.Hash()
+	}
+
+	return 0
+}
+
+// WorkflowDeepCopy returns copy of x, that doesn't share maps, slices and pointers with x.
+func WorkflowDeepCopy(x Workflow) Workflow {
+	switch y := x.(type) {
+	case *Flow:
+		return y.DeepCopy()
+	case *FlowRef:
+		return y.DeepCopy()
+	}
+
+	return nil
+}
+
+func (r *Flow) Equal(other *Flow) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if r.Arg != other.Arg {
+		return false
+	}
+	if len(r.Body) != len(other.Body) {
+		return false
+	}
+	for i1 := range r.Body {
+		if !ExprEqual(r.Body[i1], other.Body[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Flow) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.Flow")
+	h = shared.HashString(h, r.Name)
+	h = shared.HashString(h, r.Arg)
+	h = shared.HashUint64(h, uint64(len(r.Body)))
+	for _, v1 := range r.Body {
+		h = shared.HashUint64(h, ExprHash(v1))
+	}
+	return h
+}
+
+func (r *Flow) DeepCopy() *Flow {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Body != nil {
+		result.Body = make([]Expr, len(r.Body))
+		for i1 := range r.Body {
+			result.Body[i1] = ExprDeepCopy(r.Body[i1])
+		}
+	}
+	return &result
+}
+
+func (r *FlowRef) Equal(other *FlowRef) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.FlowID != other.FlowID {
+		return false
+	}
+	return true
+}
+
+func (r *FlowRef) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.FlowRef")
+	h = shared.HashString(h, r.FlowID)
+	return h
+}
+
+func (r *FlowRef) DeepCopy() *FlowRef {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
 func init() {
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.Flow", FlowFromJSON, FlowToJSON)
 	shared.JSONMarshallerRegister("github.com/widmogrod/mkunion/x/workflow.FlowRef", FlowRefFromJSON, FlowRefToJSON)
@@ -3908,7 +5276,7 @@ func WorkflowFromJSON(x []byte) (Workflow, error) {
 	if x == nil || len(x) == 0 {
 		return nil, nil
 	}
-	if string(x[:4]) == "null" {
+	if len(x) >= 4 && string(x[:4]) == "null" {
 		return nil, nil
 	}
 	var data WorkflowUnionJSON
@@ -141,6 +141,54 @@ func (r *FunctionInput) _unmarshalJSONschema_Schema(data []byte) (schema.Schema,
 	}
 	return result, nil
 }
+func (r *FunctionInput) Equal(other *FunctionInput) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Name != other.Name {
+		return false
+	}
+	if r.CallbackID != other.CallbackID {
+		return false
+	}
+	if len(r.Args) != len(other.Args) {
+		return false
+	}
+	for i1 := range r.Args {
+		if !schema.SchemaEqual(r.Args[i1], other.Args[i1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *FunctionInput) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.FunctionInput")
+	h = shared.HashString(h, r.Name)
+	h = shared.HashString(h, r.CallbackID)
+	h = shared.HashUint64(h, uint64(len(r.Args)))
+	for _, v1 := range r.Args {
+		h = shared.HashUint64(h, schema.SchemaHash(v1))
+	}
+	return h
+}
+
+func (r *FunctionInput) DeepCopy() *FunctionInput {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	if r.Args != nil {
+		result.Args = make([]schema.Schema, len(r.Args))
+		for i1 := range r.Args {
+			result.Args[i1] = schema.SchemaDeepCopy(r.Args[i1])
+		}
+	}
+	return &result
+}
 
 var (
 	_ json.Unmarshaler = (*FunctionOutput)(nil)
@@ -205,3 +253,30 @@ func (r *FunctionOutput) _unmarshalJSONschema_Schema(data []byte) (schema.Schema
 	}
 	return result, nil
 }
+func (r *FunctionOutput) Equal(other *FunctionOutput) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if !schema.SchemaEqual(r.Result, other.Result) {
+		return false
+	}
+	return true
+}
+
+func (r *FunctionOutput) Hash() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := shared.HashString(shared.HashOffset, "workflow.FunctionOutput")
+	h = shared.HashUint64(h, schema.SchemaHash(r.Result))
+	return h
+}
+
+func (r *FunctionOutput) DeepCopy() *FunctionOutput {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.Result = schema.SchemaDeepCopy(r.Result)
+	return &result
+}
@@ -720,33 +720,28 @@ func initStepID(x *Flow) *Flow {
 }
 
 func initExprStepID(x Expr, steps map[string]int) Expr {
-	return MatchExprR1(
-		x,
-		func(x *End) Expr {
-			x.ID = stepId(x.ID, "end", steps)
-			return x
-
-		},
-		func(x *Assign) Expr {
-			x.ID = stepId(x.ID, "assign", steps)
-			x.Val = initExprStepID(x.Val, steps)
-			return x
-		},
-		func(x *Apply) Expr {
-			x.ID = stepId(x.ID, "apply-"+x.Name, steps)
-			return x
-		},
-		func(x *Choose) Expr {
-			x.ID = stepId(x.ID, "choose", steps)
-			for i, expr := range x.Then {
-				x.Then[i] = initExprStepID(expr, steps)
-			}
-			for i, expr := range x.Else {
-				x.Else[i] = initExprStepID(expr, steps)
-			}
-			return x
-		},
-	)
+	// top-down rewrite assigns IDs to parent expressions before nested ones
+	return RewriteExprTopDown(x, func(x Expr) Expr {
+		return MatchExprR1(
+			x,
+			func(x *End) Expr {
+				x.ID = stepId(x.ID, "end", steps)
+				return x
+			},
+			func(x *Assign) Expr {
+				x.ID = stepId(x.ID, "assign", steps)
+				return x
+			},
+			func(x *Apply) Expr {
+				x.ID = stepId(x.ID, "apply-"+x.Name, steps)
+				return x
+			},
+			func(x *Choose) Expr {
+				x.ID = stepId(x.ID, "choose", steps)
+				return x
+			},
+		)
+	})
 }
 
 func stepId(stepID, orName string, steps map[string]int) string {